@@ -1,6 +1,13 @@
 AZURE_BASE_GROUP_NAME=az-samples-go
 AZURE_LOCATION_DEFAULT=westus2
 AZURE_SAMPLES_KEEP_RESOURCES=0
+# groups are tagged with the run ID and an expiry for tools/cleanup
+# AZURE_SAMPLES_RUN_ID=
+# AZURE_SAMPLES_RESOURCE_TTL=24h
+
+# optionally cache API versions elsewhere, or "off", and allow previews
+# AZURE_SAMPLES_API_VERSION_CACHE=
+# AZURE_SAMPLES_ALLOW_PREVIEW_API_VERSIONS=0
 
 # create with:
 # `az ad sp create-for-rbac --name 'my-sp' --output json`
@@ -15,5 +22,22 @@ AZURE_SUBSCRIPTION_ID=
 # sp must have Contributor role on subscription
 AZURE_AUTH_LOCATION=$HOME/.azure/sdk_auth.json
 
+# optionally authenticate another way:
+# clientsecret, deviceflow, msi, cli, certificate or federated
+# AZURE_AUTH_METHOD=cli
+# AZURE_CLIENT_CERTIFICATE_PATH=$HOME/.azure/sp.pem
+# AZURE_CLIENT_CERTIFICATE_PASSWORD=
+# AZURE_FEDERATED_TOKEN_FILE=
+
 AZURE_STORAGE_ACCOUNT_NAME=
 AZURE_STORAGE_ACCOUNT_GROUP_NAME=
+
+# optionally pick a named profile from a YAML or JSON profile file
+# AZURE_PROFILE=dev
+# AZURE_PROFILE_FILE=$HOME/.azure/go-samples-profiles.yaml
+
+# optionally target another cloud, by name or, for Azure Stack and other
+# custom clouds, by Resource Manager endpoint or saved metadata file
+# AZURE_ENVIRONMENT=AzureUSGovernmentCloud
+# AZURE_ARM_ENDPOINT=https://management.local.azurestack.external
+# AZURE_CLOUD_FILE=$HOME/.azure/cloud.json
@@ -2,28 +2,31 @@ package authorization
 
 import (
 	"context"
-	"fmt"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/resourceid"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/services/authorization/mgmt/2015-07-01/authorization"
 	"github.com/Azure/go-autorest/autorest/to"
 	uuid "github.com/satori/go.uuid"
 )
 
-func getRoleDefinitionsClient() (authorization.RoleDefinitionsClient, error) {
-	roleDefClient := authorization.NewRoleDefinitionsClient(config.SubscriptionID())
+func getRoleDefinitionsClient(ctx context.Context) (authorization.RoleDefinitionsClient, error) {
+	roleDefClient := authorization.NewRoleDefinitionsClient(config.ScopeFrom(ctx).SubscriptionID)
 	a, _ := iam.GetResourceManagementAuthorizer()
 	roleDefClient.Authorizer = a
 	roleDefClient.AddToUserAgent(config.UserAgent())
+	roleDefClient.Sender = recording.Sender()
 	return roleDefClient, nil
 }
 
-func getRoleAssignmentsClient() (authorization.RoleAssignmentsClient, error) {
-	roleClient := authorization.NewRoleAssignmentsClient(config.SubscriptionID())
+func getRoleAssignmentsClient(ctx context.Context) (authorization.RoleAssignmentsClient, error) {
+	roleClient := authorization.NewRoleAssignmentsClient(config.ScopeFrom(ctx).SubscriptionID)
 	a, _ := iam.GetResourceManagementAuthorizer()
 	roleClient.Authorizer = a
 	roleClient.AddToUserAgent(config.UserAgent())
+	roleClient.Sender = recording.Sender()
 	return roleClient, nil
 }
 
@@ -34,7 +37,7 @@ func ListRoleDefinitions(ctx context.Context, filter string) (list authorization
 		return
 	}
 
-	roleDefClient, _ := getRoleDefinitionsClient()
+	roleDefClient, _ := getRoleDefinitionsClient(ctx)
 	return roleDefClient.List(ctx, *rg.ID, filter)
 }
 
@@ -45,7 +48,7 @@ func AssignRole(ctx context.Context, principalID, roleDefID string) (role author
 		return
 	}
 
-	roleAssignmentsClient, _ := getRoleAssignmentsClient()
+	roleAssignmentsClient, _ := getRoleAssignmentsClient(ctx)
 	return roleAssignmentsClient.Create(
 		ctx,
 		*rg.ID,
@@ -61,9 +64,9 @@ func AssignRole(ctx context.Context, principalID, roleDefID string) (role author
 // AssignRoleWithSubscriptionScope assigns a role to the named principal at the
 // subscription scope.
 func AssignRoleWithSubscriptionScope(ctx context.Context, principalID, roleDefID string) (role authorization.RoleAssignment, err error) {
-	scope := fmt.Sprintf("/subscriptions/%s", config.SubscriptionID())
+	scope := resourceid.Subscription(config.SubscriptionID()).String()
 
-	roleAssignmentsClient, _ := getRoleAssignmentsClient()
+	roleAssignmentsClient, _ := getRoleAssignmentsClient(ctx)
 	return roleAssignmentsClient.Create(
 		ctx,
 		scope,
@@ -78,6 +81,6 @@ func AssignRoleWithSubscriptionScope(ctx context.Context, principalID, roleDefID
 
 // DeleteRoleAssignment deletes a roleassignment
 func DeleteRoleAssignment(ctx context.Context, id string) (authorization.RoleAssignment, error) {
-	roleAssignmentsClient, _ := getRoleAssignmentsClient()
+	roleAssignmentsClient, _ := getRoleAssignmentsClient(ctx)
 	return roleAssignmentsClient.DeleteByID(ctx, id)
 }
@@ -7,14 +7,15 @@ package batch
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/helper/resource"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/services/batch/2017-05-01.5.0/batch"
 	batchARM "github.com/Azure/azure-sdk-for-go/services/batch/mgmt/2017-09-01/batch"
 	"github.com/Azure/go-autorest/autorest"
@@ -26,11 +27,12 @@ const (
 	stdoutFile string = "stdout.txt"
 )
 
-func getAccountClient() batchARM.AccountClient {
-	accountClient := batchARM.NewAccountClient(config.SubscriptionID())
+func getAccountClient(ctx context.Context) batchARM.AccountClient {
+	accountClient := batchARM.NewAccountClient(config.ScopeFrom(ctx).SubscriptionID)
 	auth, _ := iam.GetResourceManagementAuthorizer()
 	accountClient.Authorizer = auth
 	accountClient.AddToUserAgent(config.UserAgent())
+	accountClient.Sender = recording.Sender()
 	return accountClient
 }
 
@@ -39,6 +41,7 @@ func getPoolClient(accountName, accountLocation string) batch.PoolClient {
 	auth, _ := iam.GetBatchAuthorizer()
 	poolClient.Authorizer = auth
 	poolClient.AddToUserAgent(config.UserAgent())
+	poolClient.Sender = recording.Sender()
 	poolClient.RequestInspector = fixContentTypeInspector()
 	return poolClient
 }
@@ -48,6 +51,7 @@ func getJobClient(accountName, accountLocation string) batch.JobClient {
 	auth, _ := iam.GetBatchAuthorizer()
 	jobClient.Authorizer = auth
 	jobClient.AddToUserAgent(config.UserAgent())
+	jobClient.Sender = recording.Sender()
 	jobClient.RequestInspector = fixContentTypeInspector()
 	return jobClient
 }
@@ -57,6 +61,7 @@ func getTaskClient(accountName, accountLocation string) batch.TaskClient {
 	auth, _ := iam.GetBatchAuthorizer()
 	taskClient.Authorizer = auth
 	taskClient.AddToUserAgent(config.UserAgent())
+	taskClient.Sender = recording.Sender()
 	taskClient.RequestInspector = fixContentTypeInspector()
 	return taskClient
 }
@@ -66,13 +71,14 @@ func getFileClient(accountName, accountLocation string) batch.FileClient {
 	auth, _ := iam.GetBatchAuthorizer()
 	fileClient.Authorizer = auth
 	fileClient.AddToUserAgent(config.UserAgent())
+	fileClient.Sender = recording.Sender()
 	fileClient.RequestInspector = fixContentTypeInspector()
 	return fileClient
 }
 
 // CreateAzureBatchAccount creates a new azure batch account
 func CreateAzureBatchAccount(ctx context.Context, accountName, location, resourceGroupName string) (a batchARM.Account, err error) {
-	accountClient := getAccountClient()
+	accountClient := getAccountClient(ctx)
 	res, err := accountClient.Create(ctx, resourceGroupName, accountName, batchARM.AccountCreateParameters{
 		Location: to.StringPtr(location),
 	})
@@ -186,29 +192,25 @@ func CreateBatchTask(ctx context.Context, accountName, accountLocation, jobID st
 // WaitForTaskResult polls the task and retreives it's stdout once it has completed
 func WaitForTaskResult(ctx context.Context, accountName, accountLocation, jobID, taskID string) (stdout string, err error) {
 	taskClient := getTaskClient(accountName, accountLocation)
-	res, err := taskClient.Get(ctx, jobID, taskID, "", "", nil, nil, nil, nil, "", "", nil, nil)
-	if err != nil {
-		return "", err
-	}
-	waitCtx, cancel := context.WithTimeout(ctx, time.Minute*4)
-	defer cancel()
-
-	if res.State != batch.TaskStateCompleted {
-		for {
-			_, ok := waitCtx.Deadline()
-			if !ok {
-				return stdout, errors.New("timedout waiting for task to execute")
-			}
-			time.Sleep(time.Second * 15)
-			res, err = taskClient.Get(ctx, jobID, taskID, "", "", nil, nil, nil, nil, "", "", nil, nil)
+	stateConf := &resource.StateChangeConf{
+		Pending: []string{
+			string(batch.TaskStateActive),
+			string(batch.TaskStatePreparing),
+			string(batch.TaskStateRunning),
+		},
+		Target: []string{string(batch.TaskStateCompleted)},
+		Refresh: func() (interface{}, string, error) {
+			res, err := taskClient.Get(ctx, jobID, taskID, "", "", nil, nil, nil, nil, "", "", nil, nil)
 			if err != nil {
-				return "", err
-			}
-			if res.State == batch.TaskStateCompleted {
-				waitCtx.Done()
-				break
+				return nil, "", err
 			}
-		}
+			return res, string(res.State), nil
+		},
+		Timeout: 4 * time.Minute,
+		Backoff: resource.FixedBackoff(recording.PollingDelay(15 * time.Second)),
+	}
+	if _, err := stateConf.WaitForStateContext(ctx); err != nil {
+		return "", fmt.Errorf("waiting for task %q to complete: %v", taskID, err)
 	}
 
 	fileClient := getFileClient(accountName, accountLocation)
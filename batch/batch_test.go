@@ -49,7 +49,16 @@ func ExampleCreateAzureBatchAccount() {
 	defer cancel()
 	defer resources.Cleanup(ctx)
 
-	_, err := resources.CreateGroup(ctx, config.GroupName())
+	requirements, err := resources.RequirementsFromPackage(".")
+	if err != nil {
+		util.LogAndPanic(err)
+	}
+	err = resources.Preflight(ctx, requirements, &resources.PreflightOptions{Register: true})
+	if err != nil {
+		util.LogAndPanic(err)
+	}
+
+	_, err = resources.CreateGroup(ctx, config.GroupName())
 	if err != nil {
 		util.LogAndPanic(err)
 	}
@@ -5,21 +5,23 @@ import (
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/services/cdn/mgmt/2017-10-12/cdn"
 	"github.com/Azure/go-autorest/autorest/to"
 )
 
-func getCDNClient() cdn.BaseClient {
-	cdnClient := cdn.New(config.SubscriptionID())
+func getCDNClient(ctx context.Context) cdn.BaseClient {
+	cdnClient := cdn.New(config.ScopeFrom(ctx).SubscriptionID)
 	auth, _ := iam.GetResourceManagementAuthorizer()
 	cdnClient.Authorizer = auth
 	cdnClient.AddToUserAgent(config.UserAgent())
+	cdnClient.Sender = recording.Sender()
 	return cdnClient
 }
 
 // CheckNameAvailability use thes CDN package to determine whether or not a given name is appropriate.
 func CheckNameAvailability(ctx context.Context, name, resourceType string) (bool, error) {
-	client := getCDNClient()
+	client := getCDNClient(ctx)
 	resp, err := client.CheckNameAvailability(ctx, cdn.CheckNameAvailabilityInput{
 		Name: to.StringPtr(name),
 		Type: to.StringPtr(resourceType),
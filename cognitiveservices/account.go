@@ -12,35 +12,37 @@ import (
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/services/cognitiveservices/mgmt/2017-04-18/cognitiveservices"
 	"github.com/Azure/go-autorest/autorest/to"
 )
 
-func getCognitiveSevicesManagementClient() cognitiveservices.AccountsClient {
-	accountClient := cognitiveservices.NewAccountsClient(config.SubscriptionID())
+func getCognitiveSevicesManagementClient(ctx context.Context) cognitiveservices.AccountsClient {
+	accountClient := cognitiveservices.NewAccountsClient(config.ScopeFrom(ctx).SubscriptionID)
 	auth, _ := iam.GetResourceManagementAuthorizer()
 	accountClient.Authorizer = auth
 	accountClient.AddToUserAgent(config.UserAgent())
+	accountClient.Sender = recording.Sender()
 	return accountClient
 }
 
-func getFirstKey(accountName string) string {
-	managementClient := getCognitiveSevicesManagementClient()
-	keys, err := managementClient.ListKeys(context.Background(), config.GroupName(), accountName)
+func getFirstKey(ctx context.Context, accountName string) string {
+	managementClient := getCognitiveSevicesManagementClient(ctx)
+	keys, err := managementClient.ListKeys(ctx, config.ScopeFrom(ctx).GroupName, accountName)
 	if err != nil {
 		log.Fatalf("failed to list keys: %v", err)
 	}
 	return *keys.Key1
 }
 
-//CreateCSAccount creates a Cognitive Services account of the specified type
-func CreateCSAccount(accountName string, accountKind string) (*cognitiveservices.Account, error) {
-	managementClient := getCognitiveSevicesManagementClient()
+// CreateCSAccount creates a Cognitive Services account of the specified type
+func CreateCSAccount(ctx context.Context, accountName string, accountKind string) (*cognitiveservices.Account, error) {
+	managementClient := getCognitiveSevicesManagementClient(ctx)
 	location := "global"
 
 	csAccount, err := managementClient.Create(
-		context.Background(),
-		config.GroupName(),
+		ctx,
+		config.ScopeFrom(ctx).GroupName,
 		accountName,
 		cognitiveservices.Account{
 			Kind: &accountKind,
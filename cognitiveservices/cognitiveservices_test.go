@@ -45,15 +45,15 @@ func Example_cognitiveServicesSearch() {
 	var groupName = config.GenerateGroupName("CognitiveServicesSearch")
 	config.SetGroupName(groupName)
 
-	ctx := context.Background()
+	ctx := config.WithScope(context.Background(), config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
-	_, err := resources.CreateGroup(ctx, config.GroupName())
+	_, err := resources.CreateGroup(ctx, groupName)
 	if err != nil {
 		util.LogAndPanic(err)
 	}
 
-	_, err = CreateCSAccount(accountName, "Bing.Search.v7")
+	_, err = CreateCSAccount(ctx, accountName, "Bing.Search.v7")
 
 	if err != nil {
 		util.LogAndPanic(err)
@@ -61,11 +61,11 @@ func Example_cognitiveServicesSearch() {
 
 	util.PrintAndLog("cognitive services search resource created")
 
-	searchWeb(accountName)
-	searchImages(accountName)
-	searchVideos(accountName)
-	searchNews(accountName)
-	searchEntities(accountName)
+	searchWeb(ctx, accountName)
+	searchImages(ctx, accountName)
+	searchVideos(ctx, accountName)
+	searchNews(ctx, accountName)
+	searchEntities(ctx, accountName)
 
 	// Output:
 	// cognitive services search resource created
@@ -88,21 +88,21 @@ func Example_cognitiveServicesSpellCheck() {
 	var groupName = config.GenerateGroupName("CognitiveServicesSpellcheck")
 	config.SetGroupName(groupName)
 
-	ctx := context.Background()
+	ctx := config.WithScope(context.Background(), config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
-	_, err := resources.CreateGroup(ctx, config.GroupName())
+	_, err := resources.CreateGroup(ctx, groupName)
 	if err != nil {
 		util.LogAndPanic(err)
 	}
 
-	_, err = CreateCSAccount(accountName, "Bing.SpellCheck.v7")
+	_, err = CreateCSAccount(ctx, accountName, "Bing.SpellCheck.v7")
 	if err != nil {
 		util.LogAndPanic(err)
 	}
 	util.PrintAndLog("cognitive services spellcheck resource created")
 
-	spellCheckResult, err := SpellCheck(accountName)
+	spellCheckResult, err := SpellCheck(ctx, accountName)
 	if err != nil {
 		util.LogAndPanic(err)
 	}
@@ -121,8 +121,8 @@ func Example_cognitiveServicesSpellCheck() {
 	// completed spell check and found corrections
 }
 
-func searchWeb(accountName string) {
-	webPages, err := SearchWeb(accountName)
+func searchWeb(ctx context.Context, accountName string) {
+	webPages, err := SearchWeb(ctx, accountName)
 	if err != nil {
 		util.LogAndPanic(err)
 	}
@@ -137,8 +137,8 @@ func searchWeb(accountName string) {
 	}
 }
 
-func searchImages(accountName string) {
-	images, err := SearchImages(accountName)
+func searchImages(ctx context.Context, accountName string) {
+	images, err := SearchImages(ctx, accountName)
 	if err != nil {
 		util.LogAndPanic(err)
 	}
@@ -179,8 +179,8 @@ func searchImages(accountName string) {
 	}
 }
 
-func searchVideos(accountName string) {
-	videos, err := SearchVideos(accountName)
+func searchVideos(ctx context.Context, accountName string) {
+	videos, err := SearchVideos(ctx, accountName)
 	if err != nil {
 		util.LogAndPanic(err)
 	}
@@ -195,7 +195,7 @@ func searchVideos(accountName string) {
 		log.Printf("First video url: %v \n", *firstVideo.ContentURL)
 	}
 
-	trendingVideos, err := TrendingVideos(accountName)
+	trendingVideos, err := TrendingVideos(ctx, accountName)
 	if err != nil {
 		util.LogAndPanic(err)
 	}
@@ -210,8 +210,8 @@ func searchVideos(accountName string) {
 	}
 }
 
-func searchNews(accountName string) {
-	news, err := SearchNews(accountName)
+func searchNews(ctx context.Context, accountName string) {
+	news, err := SearchNews(ctx, accountName)
 	if err != nil {
 		util.LogAndPanic(err)
 	}
@@ -236,8 +236,8 @@ func searchNews(accountName string) {
 	}
 }
 
-func searchEntities(accountName string) {
-	entities, err := SearchEntities(accountName)
+func searchEntities(ctx context.Context, accountName string) {
+	entities, err := SearchEntities(ctx, accountName)
 	if err != nil {
 		util.LogAndPanic(err)
 	}
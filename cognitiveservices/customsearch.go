@@ -9,42 +9,44 @@ import (
 	"context"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/services/cognitiveservices/v1.0/customsearch"
 	"github.com/Azure/go-autorest/autorest"
 )
 
-func getCustomSearchClient(accountName string) customsearch.CustomInstanceClient {
-	apiKey := getFirstKey(accountName)
+func getCustomSearchClient(ctx context.Context, accountName string) customsearch.CustomInstanceClient {
+	apiKey := getFirstKey(ctx, accountName)
 	customSearchClient := customsearch.NewCustomInstanceClient()
 	csAuthorizer := autorest.NewCognitiveServicesAuthorizer(apiKey)
 	customSearchClient.Authorizer = csAuthorizer
 	customSearchClient.AddToUserAgent(config.UserAgent())
+	customSearchClient.Sender = recording.Sender()
 	return customSearchClient
 }
 
-//CustomSearch returns answers based on a custom search instance
-func CustomSearch(accountName string) (*customsearch.WebWebAnswer, error) {
-	customSearchClient := getCustomSearchClient(accountName)
+// CustomSearch returns answers based on a custom search instance
+func CustomSearch(ctx context.Context, accountName string) (*customsearch.WebWebAnswer, error) {
+	customSearchClient := getCustomSearchClient(ctx, accountName)
 	query := "Xbox"
 	customConfig := "" // subsitute with custom config id configured at https://www.customsearch.ai
 
 	searchResponse, err := customSearchClient.Search(
-		context.Background(), // context
-		customConfig,         // custom config (see comment above)
-		query,                // query keyword
-		"",                   // Accept-Language header
-		"",                   // User-Agent header
-		"",                   // X-MSEdge-ClientID header
-		"",                   // X-MSEdge-ClientIP header
-		"",                   // X-Search-Location header
-		"",                   // country code
-		nil,                  // count
-		"",                   // market
-		nil,                  // offset
-		customsearch.Strict,  // safe search
-		"",                   // set lang
-		nil,                  // text decorations
-		customsearch.Raw,     // text format
+		ctx,                 // context
+		customConfig,        // custom config (see comment above)
+		query,               // query keyword
+		"",                  // Accept-Language header
+		"",                  // User-Agent header
+		"",                  // X-MSEdge-ClientID header
+		"",                  // X-MSEdge-ClientIP header
+		"",                  // X-Search-Location header
+		"",                  // country code
+		nil,                 // count
+		"",                  // market
+		nil,                 // offset
+		customsearch.Strict, // safe search
+		"",                  // set lang
+		nil,                 // text decorations
+		customsearch.Raw,    // text format
 	)
 	if err != nil {
 		return nil, err
@@ -9,35 +9,37 @@ import (
 	"context"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/services/cognitiveservices/v1.0/entitysearch"
 	"github.com/Azure/go-autorest/autorest"
 )
 
-func getEntitySearchClient(accountName string) entitysearch.EntitiesClient {
-	apiKey := getFirstKey(accountName)
+func getEntitySearchClient(ctx context.Context, accountName string) entitysearch.EntitiesClient {
+	apiKey := getFirstKey(ctx, accountName)
 	entitySearchClient := entitysearch.NewEntitiesClient()
 	csAuthorizer := autorest.NewCognitiveServicesAuthorizer(apiKey)
 	entitySearchClient.Authorizer = csAuthorizer
 	entitySearchClient.AddToUserAgent(config.UserAgent())
+	entitySearchClient.Sender = recording.Sender()
 	return entitySearchClient
 }
 
-//SearchEntities retunrs a list of entities
-func SearchEntities(accountName string) (*entitysearch.Entities, error) {
-	entitySearchClient := getEntitySearchClient(accountName)
+// SearchEntities retunrs a list of entities
+func SearchEntities(ctx context.Context, accountName string) (*entitysearch.Entities, error) {
+	entitySearchClient := getEntitySearchClient(ctx, accountName)
 	query := "tom cruise"
 	market := "en-us"
 	searchResponse, err := entitySearchClient.Search(
-		context.Background(), // context
-		query,                // query keyword
-		"",                   // Accept-Language header
-		"",                   // pragma header
-		"",                   // User-Agent header
-		"",                   // X-MSEdge-ClientID header
-		"",                   // X-MSEdge-ClientIP header
-		"",                   // X-Search-Location header
-		"",                   // country code
-		market,               // market
+		ctx,                             // context
+		query,                           // query keyword
+		"",                              // Accept-Language header
+		"",                              // pragma header
+		"",                              // User-Agent header
+		"",                              // X-MSEdge-ClientID header
+		"",                              // X-MSEdge-ClientIP header
+		"",                              // X-Search-Location header
+		"",                              // country code
+		market,                          // market
 		[]entitysearch.AnswerType{},     // response filter
 		[]entitysearch.ResponseFormat{}, // response format
 		entitysearch.Strict,             // safe search
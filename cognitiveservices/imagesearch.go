@@ -9,54 +9,56 @@ import (
 	"context"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/services/cognitiveservices/v1.0/imagesearch"
 	"github.com/Azure/go-autorest/autorest"
 )
 
-func getImageSearchClient(accountName string) imagesearch.ImagesClient {
-	apiKey := getFirstKey(accountName)
+func getImageSearchClient(ctx context.Context, accountName string) imagesearch.ImagesClient {
+	apiKey := getFirstKey(ctx, accountName)
 	imageSearchClient := imagesearch.NewImagesClient()
 	csAuthorizer := autorest.NewCognitiveServicesAuthorizer(apiKey)
 	imageSearchClient.Authorizer = csAuthorizer
 	imageSearchClient.AddToUserAgent(config.UserAgent())
+	imageSearchClient.Sender = recording.Sender()
 	return imageSearchClient
 }
 
-//SearchImages returns a list of images
-func SearchImages(accountName string) (imagesearch.Images, error) {
-	imageSearchClient := getImageSearchClient(accountName)
+// SearchImages returns a list of images
+func SearchImages(ctx context.Context, accountName string) (imagesearch.Images, error) {
+	imageSearchClient := getImageSearchClient(ctx, accountName)
 	query := "canadian rockies"
 
 	images, err := imageSearchClient.Search(
-		context.Background(),  // context
-		query,                 // query keyword
-		"",                    // Accept-Language header
-		"",                    // User-Agent header
-		"",                    // X-MSEdge-ClientID header
-		"",                    // X-MSEdge-ClientIP header
-		"",                    // X-Search-Location header
-		imagesearch.Square,    // image aspect
-		imagesearch.ColorOnly, // image color
-		"",                // country code
-		nil,               // count
-		imagesearch.Month, // freshness
-		nil,               // height
-		"",                // ID
+		ctx,                          // context
+		query,                        // query keyword
+		"",                           // Accept-Language header
+		"",                           // User-Agent header
+		"",                           // X-MSEdge-ClientID header
+		"",                           // X-MSEdge-ClientIP header
+		"",                           // X-Search-Location header
+		imagesearch.Square,           // image aspect
+		imagesearch.ColorOnly,        // image color
+		"",                           // country code
+		nil,                          // count
+		imagesearch.Month,            // freshness
+		nil,                          // height
+		"",                           // ID
 		imagesearch.ImageContent(""), // image content
 		imagesearch.Photo,            // image type
 		imagesearch.ImageLicenseAll,  // image license
-		"",                       // market
-		nil,                      // max file size
-		nil,                      // max height
-		nil,                      // max width
-		nil,                      // min file size
-		nil,                      // min height
-		nil,                      // min width
-		nil,                      // offset
-		imagesearch.Strict,       // safe search
-		imagesearch.ImageSizeAll, // image size
-		"",  // set lang
-		nil, // width
+		"",                           // market
+		nil,                          // max file size
+		nil,                          // max height
+		nil,                          // max width
+		nil,                          // min file size
+		nil,                          // min height
+		nil,                          // min width
+		nil,                          // offset
+		imagesearch.Strict,           // safe search
+		imagesearch.ImageSizeAll,     // image size
+		"",                           // set lang
+		nil,                          // width
 	)
 
 	return images, err
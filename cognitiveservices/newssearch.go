@@ -9,43 +9,45 @@ import (
 	"context"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/services/cognitiveservices/v1.0/newssearch"
 	"github.com/Azure/go-autorest/autorest"
 )
 
-func getNewsSearchClient(accountName string) newssearch.NewsClient {
-	apiKey := getFirstKey(accountName)
+func getNewsSearchClient(ctx context.Context, accountName string) newssearch.NewsClient {
+	apiKey := getFirstKey(ctx, accountName)
 	newsSearchClient := newssearch.NewNewsClient()
 	csAuthorizer := autorest.NewCognitiveServicesAuthorizer(apiKey)
 	newsSearchClient.Authorizer = csAuthorizer
 	newsSearchClient.AddToUserAgent(config.UserAgent())
+	newsSearchClient.Sender = recording.Sender()
 	return newsSearchClient
 }
 
-//SearchNews returns a list of news
-func SearchNews(accountName string) (newssearch.News, error) {
-	newsSearchClient := getNewsSearchClient(accountName)
+// SearchNews returns a list of news
+func SearchNews(ctx context.Context, accountName string) (newssearch.News, error) {
+	newsSearchClient := getNewsSearchClient(ctx, accountName)
 	query := "Quantum Computing"
 
 	news, err := newsSearchClient.Search(
-		context.Background(), // context
-		query,                // query keyword
-		"",                   // Accept-Language header
-		"",                   // User-Agent header
-		"",                   // X-MSEdge-ClientID header
-		"",                   // X-MSEdge-ClientIP header
-		"",                   // X-Search-Location header
-		"",                   // country code
-		nil,                  // count
-		newssearch.Month,     // freshness
-		"",                   // market
-		nil,                  // offset
-		nil,                  // original image
-		newssearch.Strict,    // safe search
-		"",                   // set lang
-		"",                   // sort by
-		nil,                  // text decorations
-		newssearch.Raw,       // text format
+		ctx,               // context
+		query,             // query keyword
+		"",                // Accept-Language header
+		"",                // User-Agent header
+		"",                // X-MSEdge-ClientID header
+		"",                // X-MSEdge-ClientIP header
+		"",                // X-Search-Location header
+		"",                // country code
+		nil,               // count
+		newssearch.Month,  // freshness
+		"",                // market
+		nil,               // offset
+		nil,               // original image
+		newssearch.Strict, // safe search
+		"",                // set lang
+		"",                // sort by
+		nil,               // text decorations
+		newssearch.Raw,    // text format
 	)
 
 	return news, err
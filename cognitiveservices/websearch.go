@@ -9,38 +9,40 @@ import (
 	"context"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/services/cognitiveservices/v1.0/websearch"
 	"github.com/Azure/go-autorest/autorest"
 )
 
-func getWebSearchClient(accountName string) websearch.WebClient {
-	apiKey := getFirstKey(accountName)
+func getWebSearchClient(ctx context.Context, accountName string) websearch.WebClient {
+	apiKey := getFirstKey(ctx, accountName)
 	webSearchClient := websearch.NewWebClient()
 	csAuthorizer := autorest.NewCognitiveServicesAuthorizer(apiKey)
 	webSearchClient.Authorizer = csAuthorizer
 	webSearchClient.AddToUserAgent(config.UserAgent())
+	webSearchClient.Sender = recording.Sender()
 	return webSearchClient
 }
 
-//SearchWeb returns a web answer contains a list of web pages
-func SearchWeb(accountName string) (*websearch.WebWebAnswer, error) {
-	webSearchClient := getWebSearchClient(accountName)
+// SearchWeb returns a web answer contains a list of web pages
+func SearchWeb(ctx context.Context, accountName string) (*websearch.WebWebAnswer, error) {
+	webSearchClient := getWebSearchClient(ctx, accountName)
 	query := "tom cruise"
 	searchResponse, err := webSearchClient.Search(
-		context.Background(), // context
-		query,                // query keyword
-		"",                   // Accept-Language header
-		"",                   // Pragma header
-		"",                   // User-Agent header
-		"",                   // X-MSEdge-ClientID header
-		"",                   // X-MSEdge-ClientIP header
-		"",                   // X-Search-Location header
-		nil,                  // answer count
-		"",                   // country code
-		nil,                  // count
-		websearch.Week,       // freshness
-		"",                   // market
-		nil,                  // offset
+		ctx,                      // context
+		query,                    // query keyword
+		"",                       // Accept-Language header
+		"",                       // Pragma header
+		"",                       // User-Agent header
+		"",                       // X-MSEdge-ClientID header
+		"",                       // X-MSEdge-ClientIP header
+		"",                       // X-Search-Location header
+		nil,                      // answer count
+		"",                       // country code
+		nil,                      // count
+		websearch.Week,           // freshness
+		"",                       // market
+		nil,                      // offset
 		[]websearch.AnswerType{}, // promote
 		[]websearch.AnswerType{}, // response filter
 		websearch.Strict,         // safe search
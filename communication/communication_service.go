@@ -4,16 +4,18 @@ import (
 	"context"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/services/preview/communication/mgmt/2020-08-20-preview/communication"
 	"github.com/Azure/go-autorest/autorest/to"
 )
 
-//Create a CommunicationServiceManagementClient object using a Subscription ID
+// Create a CommunicationServiceManagementClient object using a Subscription ID
 func GetManagementServiceClient() communication.ServiceClient {
 	serviceClient := communication.NewServiceClient(config.SubscriptionID())
 	a, _ := iam.GetResourceManagementAuthorizer()
 	serviceClient.Authorizer = a
 	serviceClient.AddToUserAgent(config.UserAgent())
+	serviceClient.Sender = recording.Sender()
 	return serviceClient
 }
 
@@ -22,10 +24,11 @@ func GetOperationsStatusesClient() communication.OperationStatusesClient {
 	a, _ := iam.GetResourceManagementAuthorizer()
 	operationsClient.Authorizer = a
 	operationsClient.AddToUserAgent(config.UserAgent())
+	operationsClient.Sender = recording.Sender()
 	return operationsClient
 }
 
-//Create a ACS instance
+// Create a ACS instance
 func CreateCommunicationService(ctx context.Context, resourceGroupName string, serviceName string) (service communication.ServiceResource, err error) {
 	client := GetManagementServiceClient()
 	var serviceResource = communication.ServiceResource{
@@ -44,7 +47,7 @@ func CreateCommunicationService(ctx context.Context, resourceGroupName string, s
 	return future.Result(client)
 }
 
-//Delete an ACS instance
+// Delete an ACS instance
 func DeleteCommunicationServices(ctx context.Context, resourceGroupName string, resourceName string) error {
 	client := GetManagementServiceClient()
 	future, err := client.Delete(ctx, resourceGroupName, resourceName)
@@ -57,19 +60,19 @@ func DeleteCommunicationServices(ctx context.Context, resourceGroupName string,
 	return nil
 }
 
-//List all ACS instances
+// List all ACS instances
 func ListCommunicationServices(ctx context.Context) (communication.ServiceResourceListIterator, error) {
 	client := GetManagementServiceClient()
 	return client.ListBySubscriptionComplete(ctx)
 }
 
-//Get status of all operation
+// Get status of all operation
 func GetOperationStatus(ctx context.Context, location string, operationID string) (communication.OperationStatus, error) {
 	operationsClient := GetOperationsStatusesClient()
 	return operationsClient.Get(ctx, location, operationID)
 }
 
-//Regenerate key of ACS instance
+// Regenerate key of ACS instance
 func RegenerateKeys(ctx context.Context, resourceGroupName string, communicationServiceName string) (communication.ServiceKeys, error) {
 	client := GetManagementServiceClient()
 	communicationKey := communication.RegenerateKeyParameters{
@@ -78,19 +81,19 @@ func RegenerateKeys(ctx context.Context, resourceGroupName string, communication
 	return client.RegenerateKey(ctx, resourceGroupName, communicationServiceName, &communicationKey)
 }
 
-//List keys of ACS instance
+// List keys of ACS instance
 func ListKeys(ctx context.Context, resourceGroupName string, communicationServiceName string) (communication.ServiceKeys, error) {
 	client := GetManagementServiceClient()
 	return client.ListKeys(ctx, resourceGroupName, communicationServiceName)
 }
 
-//Get resources
+// Get resources
 func GetCommunicationService(ctx context.Context, resourceGroupName string, resourceName string) (communication.ServiceResource, error) {
 	client := GetManagementServiceClient()
 	return client.Get(ctx, resourceGroupName, resourceName)
 }
 
-//Update ACS instance tag
+// Update ACS instance tag
 func UpdateCommunicationService(ctx context.Context, resourceGroupName string, communicationServiceName string, tags map[string]*string) (communication.ServiceResource, error) {
 	client := GetManagementServiceClient()
 	taggedResource := communication.TaggedResource{
@@ -99,7 +102,7 @@ func UpdateCommunicationService(ctx context.Context, resourceGroupName string, c
 	return client.Update(ctx, resourceGroupName, communicationServiceName, &taggedResource)
 }
 
-//List all communication services in resource group
+// List all communication services in resource group
 func ListCommunicationServicesByResourceGroupName(ctx context.Context, resourceGroupName string) (communication.ServiceResourceListIterator, error) {
 	serviceClient := GetManagementServiceClient()
 	return serviceClient.ListByResourceGroupComplete(ctx, resourceGroupName)
@@ -10,15 +10,17 @@ import (
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/services/containerservice/mgmt/2017-09-30/containerservice"
 	"github.com/Azure/go-autorest/autorest/to"
 )
 
-func getAKSClient() (containerservice.ManagedClustersClient, error) {
-	aksClient := containerservice.NewManagedClustersClient(config.SubscriptionID())
+func getAKSClient(ctx context.Context) (containerservice.ManagedClustersClient, error) {
+	aksClient := containerservice.NewManagedClustersClient(config.ScopeFrom(ctx).SubscriptionID)
 	auth, _ := iam.GetResourceManagementAuthorizer()
 	aksClient.Authorizer = auth
 	aksClient.AddToUserAgent(config.UserAgent())
+	aksClient.Sender = recording.Sender()
 	aksClient.PollingDuration = time.Hour * 1
 	return aksClient, nil
 }
@@ -36,7 +38,7 @@ func CreateAKS(ctx context.Context, resourceName, location, resourceGroupName, u
 		sshKeyData = fakepubkey
 	}
 
-	aksClient, err := getAKSClient()
+	aksClient, err := getAKSClient(ctx)
 	if err != nil {
 		return c, fmt.Errorf("cannot get AKS client: %v", err)
 	}
@@ -88,7 +90,7 @@ func CreateAKS(ctx context.Context, resourceName, location, resourceGroupName, u
 
 // GetAKS returns an existing AKS cluster given a resource group name and resource name
 func GetAKS(ctx context.Context, resourceGroupName, resourceName string) (c containerservice.ManagedCluster, err error) {
-	aksClient, err := getAKSClient()
+	aksClient, err := getAKSClient(ctx)
 	if err != nil {
 		return c, fmt.Errorf("cannot get AKS client: %v", err)
 	}
@@ -103,7 +105,7 @@ func GetAKS(ctx context.Context, resourceGroupName, resourceName string) (c cont
 
 // DeleteAKS deletes an existing AKS cluster
 func DeleteAKS(ctx context.Context, resourceGroupName, resourceName string) (c containerservice.ManagedClustersDeleteFuture, err error) {
-	aksClient, err := getAKSClient()
+	aksClient, err := getAKSClient(ctx)
 	if err != nil {
 		return c, fmt.Errorf("cannot get AKS client: %v", err)
 	}
@@ -12,21 +12,23 @@ import (
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/services/containerinstance/mgmt/2018-10-01/containerinstance"
 	"github.com/Azure/go-autorest/autorest/to"
 )
 
-func getContainerGroupsClient() (containerinstance.ContainerGroupsClient, error) {
-	containerGroupsClient := containerinstance.NewContainerGroupsClient(config.SubscriptionID())
+func getContainerGroupsClient(ctx context.Context) (containerinstance.ContainerGroupsClient, error) {
+	containerGroupsClient := containerinstance.NewContainerGroupsClient(config.ScopeFrom(ctx).SubscriptionID)
 	auth, _ := iam.GetResourceManagementAuthorizer()
 	containerGroupsClient.Authorizer = auth
 	containerGroupsClient.AddToUserAgent(config.UserAgent())
+	containerGroupsClient.Sender = recording.Sender()
 	return containerGroupsClient, nil
 }
 
 // CreateContainerGroup creates a new container group given a container group name, location and resoruce group
 func CreateContainerGroup(ctx context.Context, containerGroupName, location, resourceGroupName string) (c containerinstance.ContainerGroup, err error) {
-	containerGroupsClient, err := getContainerGroupsClient()
+	containerGroupsClient, err := getContainerGroupsClient(ctx)
 	if err != nil {
 		return c, fmt.Errorf("cannot get container group client: %v", err)
 	}
@@ -88,7 +90,7 @@ func CreateContainerGroup(ctx context.Context, containerGroupName, location, res
 
 // GetContainerGroup returns an existing container group given a resource group name and container group name
 func GetContainerGroup(ctx context.Context, resourceGroupName, containerGroupName string) (c containerinstance.ContainerGroup, err error) {
-	containerGroupsClient, err := getContainerGroupsClient()
+	containerGroupsClient, err := getContainerGroupsClient(ctx)
 	if err != nil {
 		return c, fmt.Errorf("cannot get container group client: %v", err)
 	}
@@ -104,7 +106,7 @@ func GetContainerGroup(ctx context.Context, resourceGroupName, containerGroupNam
 // UpdateContainerGroup updates the image of the first container of an existing container group
 // given a resource group name and container group name
 func UpdateContainerGroup(ctx context.Context, resourceGroupName, containerGroupName string) (c containerinstance.ContainerGroup, err error) {
-	containerGroupsClient, err := getContainerGroupsClient()
+	containerGroupsClient, err := getContainerGroupsClient(ctx)
 	if err != nil {
 		return c, fmt.Errorf("cannot get container group client: %v", err)
 	}
@@ -132,7 +134,7 @@ func UpdateContainerGroup(ctx context.Context, resourceGroupName, containerGroup
 
 // DeleteContainerGroup deletes an existing container group given a resource group name and container group name
 func DeleteContainerGroup(ctx context.Context, resourceGroupName, containerGroupName string) (c containerinstance.ContainerGroup, err error) {
-	containerGroupsClient, err := getContainerGroupsClient()
+	containerGroupsClient, err := getContainerGroupsClient(ctx)
 	if err != nil {
 		return c, fmt.Errorf("cannot get container group client: %v", err)
 	}
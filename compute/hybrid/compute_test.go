@@ -23,6 +23,7 @@ import (
 var (
 	vmName           = randname.GenerateWithPrefix("az-samples-go-", 10)
 	nicName          = "nic1"
+	diskName         = "disk1"
 	username         = "az-samples-go-user"
 	password         = "NoSoupForYou1!"
 	sshPublicKeyPath = os.Getenv("HOME") + "/.ssh/id_rsa.pub"
@@ -99,3 +100,91 @@ func ExampleCreateVM() {
 	// created storage account
 	// created VM
 }
+
+// Example_managedDiskVM creates a VM with a managed OS disk, attaches a data
+// disk to it and takes it through its power states.
+func Example_managedDiskVM() {
+	var groupName = config.GenerateGroupName("HybridVMManagedDisk")
+	config.SetGroupName(groupName)
+
+	ctx := context.Background()
+	defer hybridresources.Cleanup(ctx)
+	_, err := hybridresources.CreateGroup(ctx)
+	if err != nil {
+		util.LogAndPanic(err)
+	}
+	_, err = hybridnetwork.CreateVirtualNetworkAndSubnets(ctx, virtualNetworkName, subnetName)
+	if err != nil {
+		util.LogAndPanic(err)
+	}
+	_, err = hybridnetwork.CreateNetworkSecurityGroup(ctx, nsgName)
+	if err != nil {
+		util.LogAndPanic(err)
+	}
+	_, err = hybridnetwork.CreatePublicIP(ctx, ipName)
+	if err != nil {
+		util.LogAndPanic(err)
+	}
+	_, err = hybridnetwork.CreateNetworkInterface(ctx, nicName, nsgName, virtualNetworkName, subnetName, ipName)
+	if err != nil {
+		util.LogAndPanic(err)
+	}
+	util.PrintAndLog("created network resources")
+
+	// no storage account, so the OS disk is a managed disk
+	_, err = CreateVM(ctx, vmName, nicName, username, password, "", sshPublicKeyPath)
+	if err != nil {
+		util.LogAndPanic(err)
+	}
+	util.PrintAndLog("created VM")
+
+	_, err = CreateDisk(ctx, diskName, 10)
+	if err != nil {
+		util.LogAndPanic(err)
+	}
+	_, err = AttachDataDisk(ctx, vmName, diskName)
+	if err != nil {
+		util.LogAndPanic(err)
+	}
+	util.PrintAndLog("attached data disk")
+
+	_, err = DeallocateVM(ctx, vmName)
+	if err != nil {
+		util.LogAndPanic(err)
+	}
+	util.PrintAndLog("deallocated VM")
+
+	_, err = StartVM(ctx, vmName)
+	if err != nil {
+		util.LogAndPanic(err)
+	}
+	util.PrintAndLog("started VM")
+
+	_, err = RestartVM(ctx, vmName)
+	if err != nil {
+		util.LogAndPanic(err)
+	}
+	util.PrintAndLog("restarted VM")
+
+	_, err = StopVM(ctx, vmName)
+	if err != nil {
+		util.LogAndPanic(err)
+	}
+	util.PrintAndLog("stopped VM")
+
+	_, err = DeleteVM(ctx, vmName)
+	if err != nil {
+		util.LogAndPanic(err)
+	}
+	util.PrintAndLog("deleted VM")
+
+	// Output:
+	// created network resources
+	// created VM
+	// attached data disk
+	// deallocated VM
+	// started VM
+	// restarted VM
+	// stopped VM
+	// deleted VM
+}
@@ -0,0 +1,248 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package compute
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/hybrid"
+	hybridnetwork "github.com/Azure-Samples/azure-sdk-for-go-samples/network/hybrid"
+	hybridcompute "github.com/Azure/azure-sdk-for-go/profiles/2019-03-01/compute/mgmt/compute"
+	"github.com/Azure/go-autorest/autorest/to"
+)
+
+func getVMClient(ctx context.Context) hybridcompute.VirtualMachinesClient {
+	vmClient := hybridcompute.NewVirtualMachinesClientWithBaseURI(hybrid.Endpoint(ctx))
+	hybrid.Configure(&vmClient.Client)
+	return vmClient
+}
+
+// CreateVM creates a new virtual machine with the specified name using the specified network interface and storage account.
+// Username, password, and sshPublicKeyPath determine logon credentials.
+// If storageAccountName is empty the OS disk is a managed disk rather than
+// a VHD in the storage account.
+func CreateVM(ctx context.Context, vmName, nicName, username, password, storageAccountName, sshPublicKeyPath string) (vm hybridcompute.VirtualMachine, err error) {
+	nic, _ := hybridnetwork.GetNic(ctx, nicName)
+
+	vmClient := getVMClient(ctx)
+	hardwareProfile := &hybridcompute.HardwareProfile{
+		VMSize: hybridcompute.StandardA1,
+	}
+	storageProfile := &hybridcompute.StorageProfile{
+		ImageReference: &hybridcompute.ImageReference{
+			Publisher: to.StringPtr(publisher),
+			Offer:     to.StringPtr(offer),
+			Sku:       to.StringPtr(sku),
+			Version:   to.StringPtr("latest"),
+		},
+		OsDisk: &hybridcompute.OSDisk{
+			Name:         to.StringPtr("osDisk"),
+			CreateOption: hybridcompute.DiskCreateOptionTypesFromImage,
+		},
+	}
+	if storageAccountName != "" {
+		environment, err := config.Environment()
+		if err != nil {
+			return vm, fmt.Errorf(errorPrefix, err)
+		}
+		vhdURItemplate := "https://%s.blob." + environment.StorageEndpointSuffix + "/vhds/%s.vhd"
+		storageProfile.OsDisk.Vhd = &hybridcompute.VirtualHardDisk{
+			URI: to.StringPtr(fmt.Sprintf(vhdURItemplate, storageAccountName, vmName)),
+		}
+	} else {
+		storageProfile.OsDisk.Name = to.StringPtr(vmName + "-osDisk")
+		storageProfile.OsDisk.ManagedDisk = &hybridcompute.ManagedDiskParameters{
+			StorageAccountType: hybridcompute.StandardLRS,
+		}
+	}
+	osProfile := &hybridcompute.OSProfile{
+		ComputerName:  to.StringPtr(vmName),
+		AdminUsername: to.StringPtr(username),
+		AdminPassword: to.StringPtr(password),
+	}
+
+	_, err = os.Stat(sshPublicKeyPath)
+	if err == nil {
+		sshBytes, err := ioutil.ReadFile(sshPublicKeyPath)
+		if err != nil {
+			log.Fatalf(fmt.Sprintf(errorPrefix, fmt.Sprintf("failed to read SSH key data: %v", err)))
+		}
+
+		// if a key is available at the specified path then populate LinuxConfiguration
+		osProfile.LinuxConfiguration = &hybridcompute.LinuxConfiguration{
+			SSH: &hybridcompute.SSHConfiguration{
+				PublicKeys: &[]hybridcompute.SSHPublicKey{
+					{
+						Path:    to.StringPtr(fmt.Sprintf("/home/%s/.ssh/authorized_keys", username)),
+						KeyData: to.StringPtr(string(sshBytes)),
+					},
+				},
+			},
+		}
+	}
+
+	networkProfile := &hybridcompute.NetworkProfile{
+		NetworkInterfaces: &[]hybridcompute.NetworkInterfaceReference{
+			{
+				ID: nic.ID,
+				NetworkInterfaceReferenceProperties: &hybridcompute.NetworkInterfaceReferenceProperties{
+					Primary: to.BoolPtr(true),
+				},
+			},
+		},
+	}
+	virtualMachine := hybridcompute.VirtualMachine{
+		Location: to.StringPtr(config.ScopeFrom(ctx).Location),
+		VirtualMachineProperties: &hybridcompute.VirtualMachineProperties{
+			HardwareProfile: hardwareProfile,
+			StorageProfile:  storageProfile,
+			OsProfile:       osProfile,
+			NetworkProfile:  networkProfile,
+		},
+	}
+	future, err := vmClient.CreateOrUpdate(
+		ctx,
+		config.ScopeFrom(ctx).GroupName,
+		vmName,
+		virtualMachine,
+	)
+	if err != nil {
+		return vm, fmt.Errorf(fmt.Sprintf(errorPrefix, err))
+	}
+	err = future.WaitForCompletionRef(ctx, vmClient.Client)
+	if err != nil {
+		return vm, fmt.Errorf(fmt.Sprintf(errorPrefix, err))
+	}
+	return future.Result(vmClient)
+}
+
+// GetVM gets the specified VM info, including its instance view
+func GetVM(ctx context.Context, vmName string) (hybridcompute.VirtualMachine, error) {
+	vmClient := getVMClient(ctx)
+	return vmClient.Get(ctx, config.ScopeFrom(ctx).GroupName, vmName, hybridcompute.InstanceView)
+}
+
+// ListVMs gets an iterator over the VMs in the resource group
+func ListVMs(ctx context.Context) (hybridcompute.VirtualMachineListResultIterator, error) {
+	vmClient := getVMClient(ctx)
+	return vmClient.ListComplete(ctx, config.ScopeFrom(ctx).GroupName)
+}
+
+// UpdateVM modifies the VM resource by getting it, updating it locally, and
+// putting it back to the server.
+func UpdateVM(ctx context.Context, vmName string, tags map[string]*string) (vm hybridcompute.VirtualMachine, err error) {
+
+	// get the VM resource
+	vm, err = GetVM(ctx, vmName)
+	if err != nil {
+		return
+	}
+
+	// update it
+	vm.Tags = tags
+	// the instance view is read-only
+	vm.InstanceView = nil
+
+	// PUT it back
+	vmClient := getVMClient(ctx)
+	future, err := vmClient.CreateOrUpdate(ctx, config.ScopeFrom(ctx).GroupName, vmName, vm)
+	if err != nil {
+		return vm, fmt.Errorf("cannot update vm: %v", err)
+	}
+
+	err = future.WaitForCompletionRef(ctx, vmClient.Client)
+	if err != nil {
+		return vm, fmt.Errorf("cannot get the vm create or update future response: %v", err)
+	}
+
+	return future.Result(vmClient)
+}
+
+// DeallocateVM deallocates the selected VM
+func DeallocateVM(ctx context.Context, vmName string) (osr hybridcompute.OperationStatusResponse, err error) {
+	vmClient := getVMClient(ctx)
+	future, err := vmClient.Deallocate(ctx, config.ScopeFrom(ctx).GroupName, vmName)
+	if err != nil {
+		return osr, fmt.Errorf("cannot deallocate vm: %v", err)
+	}
+
+	err = future.WaitForCompletionRef(ctx, vmClient.Client)
+	if err != nil {
+		return osr, fmt.Errorf("cannot get the vm deallocate future response: %v", err)
+	}
+
+	return future.Result(vmClient)
+}
+
+// StartVM starts the selected VM
+func StartVM(ctx context.Context, vmName string) (osr hybridcompute.OperationStatusResponse, err error) {
+	vmClient := getVMClient(ctx)
+	future, err := vmClient.Start(ctx, config.ScopeFrom(ctx).GroupName, vmName)
+	if err != nil {
+		return osr, fmt.Errorf("cannot start vm: %v", err)
+	}
+
+	err = future.WaitForCompletionRef(ctx, vmClient.Client)
+	if err != nil {
+		return osr, fmt.Errorf("cannot get the vm start future response: %v", err)
+	}
+
+	return future.Result(vmClient)
+}
+
+// RestartVM restarts the selected VM
+func RestartVM(ctx context.Context, vmName string) (osr hybridcompute.OperationStatusResponse, err error) {
+	vmClient := getVMClient(ctx)
+	future, err := vmClient.Restart(ctx, config.ScopeFrom(ctx).GroupName, vmName)
+	if err != nil {
+		return osr, fmt.Errorf("cannot restart vm: %v", err)
+	}
+
+	err = future.WaitForCompletionRef(ctx, vmClient.Client)
+	if err != nil {
+		return osr, fmt.Errorf("cannot get the vm restart future response: %v", err)
+	}
+
+	return future.Result(vmClient)
+}
+
+// StopVM stops the selected VM. It's still billed until it's deallocated.
+func StopVM(ctx context.Context, vmName string) (osr hybridcompute.OperationStatusResponse, err error) {
+	vmClient := getVMClient(ctx)
+	future, err := vmClient.PowerOff(ctx, config.ScopeFrom(ctx).GroupName, vmName)
+	if err != nil {
+		return osr, fmt.Errorf("cannot power off vm: %v", err)
+	}
+
+	err = future.WaitForCompletionRef(ctx, vmClient.Client)
+	if err != nil {
+		return osr, fmt.Errorf("cannot get the vm power off future response: %v", err)
+	}
+
+	return future.Result(vmClient)
+}
+
+// DeleteVM deletes the selected VM. Its disks and network interface are
+// left behind.
+func DeleteVM(ctx context.Context, vmName string) (osr hybridcompute.OperationStatusResponse, err error) {
+	vmClient := getVMClient(ctx)
+	future, err := vmClient.Delete(ctx, config.ScopeFrom(ctx).GroupName, vmName)
+	if err != nil {
+		return osr, fmt.Errorf("cannot delete vm: %v", err)
+	}
+
+	err = future.WaitForCompletionRef(ctx, vmClient.Client)
+	if err != nil {
+		return osr, fmt.Errorf("cannot get the vm delete future response: %v", err)
+	}
+
+	return future.Result(vmClient)
+}
@@ -0,0 +1,183 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package compute
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/hybrid"
+	hybridcompute "github.com/Azure/azure-sdk-for-go/profiles/2019-03-01/compute/mgmt/compute"
+	"github.com/Azure/go-autorest/autorest/to"
+)
+
+func getDisksClient(ctx context.Context) hybridcompute.DisksClient {
+	disksClient := hybridcompute.NewDisksClientWithBaseURI(hybrid.Endpoint(ctx))
+	hybrid.Configure(&disksClient.Client)
+	return disksClient
+}
+
+// CreateDisk creates an empty managed disk of sizeGB which can be attached
+// to a VM.
+func CreateDisk(ctx context.Context, diskName string, sizeGB int32) (disk hybridcompute.Disk, err error) {
+	disksClient := getDisksClient(ctx)
+	future, err := disksClient.CreateOrUpdate(
+		ctx,
+		config.ScopeFrom(ctx).GroupName,
+		diskName,
+		hybridcompute.Disk{
+			Location: to.StringPtr(config.ScopeFrom(ctx).Location),
+			Sku: &hybridcompute.DiskSku{
+				Name: hybridcompute.StandardLRS,
+			},
+			DiskProperties: &hybridcompute.DiskProperties{
+				CreationData: &hybridcompute.CreationData{
+					CreateOption: hybridcompute.Empty,
+				},
+				DiskSizeGB: to.Int32Ptr(sizeGB),
+			},
+		})
+	if err != nil {
+		return disk, fmt.Errorf("cannot create disk: %v", err)
+	}
+
+	err = future.WaitForCompletionRef(ctx, disksClient.Client)
+	if err != nil {
+		return disk, fmt.Errorf("cannot get the disk create or update future response: %v", err)
+	}
+
+	return future.Result(disksClient)
+}
+
+// GetDisk gets the specified managed disk
+func GetDisk(ctx context.Context, diskName string) (hybridcompute.Disk, error) {
+	disksClient := getDisksClient(ctx)
+	return disksClient.Get(ctx, config.ScopeFrom(ctx).GroupName, diskName)
+}
+
+// ListDisks gets an iterator over the managed disks in the resource group
+func ListDisks(ctx context.Context) (hybridcompute.DiskListIterator, error) {
+	disksClient := getDisksClient(ctx)
+	return disksClient.ListByResourceGroupComplete(ctx, config.ScopeFrom(ctx).GroupName)
+}
+
+// ResizeDisk grows a managed disk to sizeGB. The disk must be detached, or
+// its VM deallocated.
+func ResizeDisk(ctx context.Context, diskName string, sizeGB int32) (disk hybridcompute.Disk, err error) {
+	disksClient := getDisksClient(ctx)
+	future, err := disksClient.Update(
+		ctx,
+		config.ScopeFrom(ctx).GroupName,
+		diskName,
+		hybridcompute.DiskUpdate{
+			DiskUpdateProperties: &hybridcompute.DiskUpdateProperties{
+				DiskSizeGB: to.Int32Ptr(sizeGB),
+			},
+		})
+	if err != nil {
+		return disk, fmt.Errorf("cannot update disk: %v", err)
+	}
+
+	err = future.WaitForCompletionRef(ctx, disksClient.Client)
+	if err != nil {
+		return disk, fmt.Errorf("cannot get the disk update future response: %v", err)
+	}
+
+	return future.Result(disksClient)
+}
+
+// DeleteDisk deletes a managed disk, which mustn't be attached to a VM.
+func DeleteDisk(ctx context.Context, diskName string) (osr hybridcompute.OperationStatusResponse, err error) {
+	disksClient := getDisksClient(ctx)
+	future, err := disksClient.Delete(ctx, config.ScopeFrom(ctx).GroupName, diskName)
+	if err != nil {
+		return osr, fmt.Errorf("cannot delete disk: %v", err)
+	}
+
+	err = future.WaitForCompletionRef(ctx, disksClient.Client)
+	if err != nil {
+		return osr, fmt.Errorf("cannot get the disk delete future response: %v", err)
+	}
+
+	return future.Result(disksClient)
+}
+
+// AttachDataDisk attaches an existing managed disk to the specified VM, at
+// the first free LUN.
+func AttachDataDisk(ctx context.Context, vmName, diskName string) (vm hybridcompute.VirtualMachine, err error) {
+	disk, err := GetDisk(ctx, diskName)
+	if err != nil {
+		return vm, fmt.Errorf("cannot get disk: %v", err)
+	}
+
+	// first GET the VM object
+	vm, err = GetVM(ctx, vmName)
+	if err != nil {
+		return vm, fmt.Errorf("cannot get vm: %v", err)
+	}
+
+	// then update it
+	var dataDisks []hybridcompute.DataDisk
+	if vm.StorageProfile.DataDisks != nil {
+		dataDisks = *vm.StorageProfile.DataDisks
+	}
+	lun := int32(0)
+	for _, d := range dataDisks {
+		if d.Lun != nil && *d.Lun >= lun {
+			lun = *d.Lun + 1
+		}
+	}
+	dataDisks = append(dataDisks, hybridcompute.DataDisk{
+		Lun:          to.Int32Ptr(lun),
+		Name:         disk.Name,
+		CreateOption: hybridcompute.DiskCreateOptionTypesAttach,
+		ManagedDisk: &hybridcompute.ManagedDiskParameters{
+			ID: disk.ID,
+		},
+	})
+	vm.StorageProfile.DataDisks = &dataDisks
+	vm.InstanceView = nil
+
+	// then PUT it back
+	vmClient := getVMClient(ctx)
+	future, err := vmClient.CreateOrUpdate(ctx, config.ScopeFrom(ctx).GroupName, vmName, vm)
+	if err != nil {
+		return vm, fmt.Errorf("cannot update vm: %v", err)
+	}
+
+	err = future.WaitForCompletionRef(ctx, vmClient.Client)
+	if err != nil {
+		return vm, fmt.Errorf("cannot get the vm create or update future response: %v", err)
+	}
+
+	return future.Result(vmClient)
+}
+
+// DetachDataDisks detaches all data disks from the selected VM. The disks
+// themselves are kept.
+func DetachDataDisks(ctx context.Context, vmName string) (vm hybridcompute.VirtualMachine, err error) {
+	vm, err = GetVM(ctx, vmName)
+	if err != nil {
+		return vm, fmt.Errorf("cannot get vm: %v", err)
+	}
+
+	vm.StorageProfile.DataDisks = &[]hybridcompute.DataDisk{}
+	vm.InstanceView = nil
+
+	vmClient := getVMClient(ctx)
+	future, err := vmClient.CreateOrUpdate(ctx, config.ScopeFrom(ctx).GroupName, vmName, vm)
+	if err != nil {
+		return vm, fmt.Errorf("cannot update vm: %v", err)
+	}
+
+	err = future.WaitForCompletionRef(ctx, vmClient.Client)
+	if err != nil {
+		return vm, fmt.Errorf("cannot get the vm create or update future response: %v", err)
+	}
+
+	return future.Result(vmClient)
+}
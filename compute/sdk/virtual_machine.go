@@ -11,26 +11,27 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/resourceid"
 	"github.com/Azure/azure-sdk-for-go/sdk/compute/armcompute"
 )
 
-func getVirtualMachinesClient() armcompute.VirtualMachinesClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getVirtualMachinesClient(ctx context.Context) armcompute.VirtualMachinesClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armcompute.NewVirtualMachinesClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armcompute.NewVirtualMachinesClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // The operation to create or update a virtual machine. Please note some properties can be set only during virtual machine creation.
 func CreateVirtualMachine(ctx context.Context, virtualMachineName string, virtualMachineParameters armcompute.VirtualMachine) (string, error) {
-	client := getVirtualMachinesClient()
+	client := getVirtualMachinesClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		virtualMachineName,
 		virtualMachineParameters,
 		nil,
@@ -40,125 +41,126 @@ func CreateVirtualMachine(ctx context.Context, virtualMachineName string, virtua
 		return "", err
 	}
 
-	resp, err := poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return "", err
 	}
 
-	// As a workaround due to issue https://github.com/Azure/azure-sdk-for-go/issues/14730
+	// the response may lack the ID, see https://github.com/Azure/azure-sdk-for-go/issues/14730
 	if resp.VirtualMachine.ID == nil {
-		return poller.RawResponse.Request.URL.Path, nil
+		scope := config.ScopeFrom(ctx)
+		return resourceid.ResourceGroup(scope.SubscriptionID, scope.GroupName).
+			Resource("Microsoft.Compute", "virtualMachines", virtualMachineName).String(), nil
 	}
 	return *resp.VirtualMachine.ID, nil
 }
 
 // Retrieves information about the run-time state of a virtual machine.
-func InstanceVirtualMachineView(ctx context.Context, virtualMachineName string) error {
-	client := getVirtualMachinesClient()
-	_, err := client.InstanceView(ctx, config.GroupName(), virtualMachineName, nil)
+func InstanceVirtualMachineView(ctx context.Context, virtualMachineName string) (*armcompute.VirtualMachineInstanceView, error) {
+	client := getVirtualMachinesClient(ctx)
+	resp, err := client.InstanceView(ctx, config.ScopeFrom(ctx).GroupName, virtualMachineName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VirtualMachineInstanceView, nil
 }
 
 // Lists all available virtual machine sizes to which the specified virtual machine can be resized.
-func ListVirtualMachineAvailableSizes(ctx context.Context, virtualMachineName string) error {
-	client := getVirtualMachinesClient()
-	_, err := client.ListAvailableSizes(ctx, config.GroupName(), virtualMachineName, nil)
+func ListVirtualMachineAvailableSizes(ctx context.Context, virtualMachineName string) (*armcompute.VirtualMachineSizeListResult, error) {
+	client := getVirtualMachinesClient(ctx)
+	resp, err := client.ListAvailableSizes(ctx, config.ScopeFrom(ctx).GroupName, virtualMachineName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VirtualMachineSizeListResult, nil
 }
 
 // Retrieves information about the model view or the instance view of a virtual machine.
-func GetVirtualMachine(ctx context.Context, virtualMachineName string) error {
-	client := getVirtualMachinesClient()
-	_, err := client.Get(ctx, config.GroupName(), virtualMachineName, nil)
+func GetVirtualMachine(ctx context.Context, virtualMachineName string) (*armcompute.VirtualMachine, error) {
+	client := getVirtualMachinesClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, virtualMachineName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VirtualMachine, nil
 }
 
 // Lists all of the virtual machines in the specified resource group. Use the nextLink property in the response to get the next page of virtual machines.
-func ListVirtualMachine(ctx context.Context) error {
-	client := getVirtualMachinesClient()
-	pager := client.List(config.GroupName(), nil)
+func ListVirtualMachine(ctx context.Context) ([]*armcompute.VirtualMachine, error) {
+	client := getVirtualMachinesClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).GroupName, nil)
 
+	var results []*armcompute.VirtualMachine
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().VirtualMachineListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Lists all of the virtual machines in the specified subscription. Use the nextLink property in the response to get the next page of virtual
 // machines.
-func ListAllVirtualMachine(ctx context.Context) error {
-	client := getVirtualMachinesClient()
+func ListAllVirtualMachine(ctx context.Context) ([]*armcompute.VirtualMachine, error) {
+	client := getVirtualMachinesClient(ctx)
 	pager := client.ListAll(nil)
+
+	var results []*armcompute.VirtualMachine
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().VirtualMachineListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Gets all the virtual machines under the specified subscription for the specified location.
-func ListVirtualMachineByLocation(ctx context.Context) error {
-	client := getVirtualMachinesClient()
-	pager := client.ListByLocation(config.Location(), nil)
+func ListVirtualMachineByLocation(ctx context.Context) ([]*armcompute.VirtualMachine, error) {
+	client := getVirtualMachinesClient(ctx)
+	pager := client.ListByLocation(config.ScopeFrom(ctx).Location, nil)
+
+	var results []*armcompute.VirtualMachine
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().VirtualMachineListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
-//  Run command on the VM.
-func RunCommandOnVirtualMachine(ctx context.Context, virtualMachineName string, runCommandInputParameters armcompute.RunCommandInput) error {
-	client := getVirtualMachinesClient()
+// Run command on the VM.
+func RunCommandOnVirtualMachine(ctx context.Context, virtualMachineName string, runCommandInputParameters armcompute.RunCommandInput) (*armcompute.RunCommandResult, error) {
+	client := getVirtualMachinesClient(ctx)
 	poller, err := client.BeginRunCommand(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		virtualMachineName,
 		runCommandInputParameters,
 		nil)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.RunCommandResult, nil
 }
 
 // The operation to restart a virtual machine.
 func RestartVirtualMachine(ctx context.Context, virtualMachineName string) error {
-	client := getVirtualMachinesClient()
+	client := getVirtualMachinesClient(ctx)
 	poller, err := client.BeginRestart(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		virtualMachineName,
 		nil)
 
@@ -166,7 +168,7 @@ func RestartVirtualMachine(ctx context.Context, virtualMachineName string) error
 		return err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	_, err = poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -176,10 +178,10 @@ func RestartVirtualMachine(ctx context.Context, virtualMachineName string) error
 // The operation to power off (stop) a virtual machine. The virtual machine can be restarted with the same provisioned resources. You are
 // still charged for this virtual machine.
 func VirtualMachinePowerOff(ctx context.Context, virtualMachineName string) error {
-	client := getVirtualMachinesClient()
+	client := getVirtualMachinesClient(ctx)
 	poller, err := client.BeginPowerOff(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		virtualMachineName,
 		nil)
 
@@ -187,7 +189,7 @@ func VirtualMachinePowerOff(ctx context.Context, virtualMachineName string) erro
 		return err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	_, err = poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -196,10 +198,10 @@ func VirtualMachinePowerOff(ctx context.Context, virtualMachineName string) erro
 
 // The operation to start a virtual machine.
 func StartVirtualMachine(ctx context.Context, virtualMachineName string) error {
-	client := getVirtualMachinesClient()
+	client := getVirtualMachinesClient(ctx)
 	poller, err := client.BeginStart(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		virtualMachineName,
 		nil)
 
@@ -207,19 +209,19 @@ func StartVirtualMachine(ctx context.Context, virtualMachineName string) error {
 		return err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	_, err = poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-//  The operation to reapply a virtual machine's state.
+// The operation to reapply a virtual machine's state.
 func ReapplyVirtualMachine(ctx context.Context, virtualMachineName string) error {
-	client := getVirtualMachinesClient()
+	client := getVirtualMachinesClient(ctx)
 	poller, err := client.BeginReapply(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		virtualMachineName,
 		nil)
 
@@ -227,7 +229,7 @@ func ReapplyVirtualMachine(ctx context.Context, virtualMachineName string) error
 		return err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	_, err = poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -236,10 +238,10 @@ func ReapplyVirtualMachine(ctx context.Context, virtualMachineName string) error
 
 // Shuts down the virtual machine, moves it to a new node, and powers it back on.
 func RedeployVirtualMachine(ctx context.Context, virtualMachineName string) error {
-	client := getVirtualMachinesClient()
+	client := getVirtualMachinesClient(ctx)
 	poller, err := client.BeginRedeploy(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		virtualMachineName,
 		nil)
 
@@ -247,7 +249,7 @@ func RedeployVirtualMachine(ctx context.Context, virtualMachineName string) erro
 		return err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	_, err = poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -255,34 +257,34 @@ func RedeployVirtualMachine(ctx context.Context, virtualMachineName string) erro
 }
 
 // The operation to update a virtual machine.
-func UpdateVirtualMachineTags(ctx context.Context, virtualMachineName string, virtualMachineUpdateParameters armcompute.VirtualMachineUpdate) error {
-	client := getVirtualMachinesClient()
+func UpdateVirtualMachineTags(ctx context.Context, virtualMachineName string, virtualMachineUpdateParameters armcompute.VirtualMachineUpdate) (*armcompute.VirtualMachine, error) {
+	client := getVirtualMachinesClient(ctx)
 	poller, err := client.BeginUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		virtualMachineName,
 		virtualMachineUpdateParameters,
 		nil,
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VirtualMachine, nil
 }
 
 // Sets the OS state of the virtual machine to generalized. It is recommended to sysprep the virtual machine before performing this operation.
 // For Windows, please refer to Create a managed image of a generalized VM in Azure [https://docs.microsoft.com/en-us/azure/virtual-machines/windows/capture-image-resource].
 // For Linux, please refer to How to create an image of a virtual machine or VHD [https://docs.microsoft.com/en-us/azure/virtual-machines/linux/capture-image].
 func GenerializeVirtualMachine(ctx context.Context, virtualMachineName string) error {
-	client := getVirtualMachinesClient()
+	client := getVirtualMachinesClient(ctx)
 	_, err := client.Generalize(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		virtualMachineName,
 		nil)
 
@@ -296,10 +298,10 @@ func GenerializeVirtualMachine(ctx context.Context, virtualMachineName string) e
 // Shuts down the virtual machine and releases the compute resources. You are not billed for the compute resources that this virtual machine
 // uses.
 func DeallocateVirtualMachine(ctx context.Context, virtualMachineName string) error {
-	client := getVirtualMachinesClient()
+	client := getVirtualMachinesClient(ctx)
 	poller, err := client.BeginDeallocate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		virtualMachineName,
 		nil)
 
@@ -307,7 +309,7 @@ func DeallocateVirtualMachine(ctx context.Context, virtualMachineName string) er
 		return err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	_, err = poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -316,10 +318,10 @@ func DeallocateVirtualMachine(ctx context.Context, virtualMachineName string) er
 
 // The operation to simulate the eviction of spot virtual machine.
 func SimulateEvictionVirtualMachine(ctx context.Context, virtualMachineName string) error {
-	client := getVirtualMachinesClient()
+	client := getVirtualMachinesClient(ctx)
 	_, err := client.SimulateEviction(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		virtualMachineName,
 		nil)
 
@@ -332,10 +334,10 @@ func SimulateEvictionVirtualMachine(ctx context.Context, virtualMachineName stri
 
 // The operation to perform maintenance on a virtual machine.
 func PerformMaintenanceVirtualMachine(ctx context.Context, virtualMachineName string) error {
-	client := getVirtualMachinesClient()
+	client := getVirtualMachinesClient(ctx)
 	poller, err := client.BeginPerformMaintenance(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		virtualMachineName,
 		nil)
 
@@ -343,7 +345,7 @@ func PerformMaintenanceVirtualMachine(ctx context.Context, virtualMachineName st
 		return err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	_, err = poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -353,10 +355,10 @@ func PerformMaintenanceVirtualMachine(ctx context.Context, virtualMachineName st
 // Converts virtual machine disks from blob-based to managed disks. Virtual machine must be stop-deallocated before invoking
 // this operation.
 func ConvertVirtualMachineToManagedDisk(ctx context.Context, virtualMachineName string) error {
-	client := getVirtualMachinesClient()
+	client := getVirtualMachinesClient(ctx)
 	poller, err := client.BeginConvertToManagedDisks(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		virtualMachineName,
 		nil)
 
@@ -364,7 +366,7 @@ func ConvertVirtualMachineToManagedDisk(ctx context.Context, virtualMachineName
 		return err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	_, err = poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -373,10 +375,10 @@ func ConvertVirtualMachineToManagedDisk(ctx context.Context, virtualMachineName
 
 // Reimages the virtual machine which has an ephemeral OS disk back to its initial state.
 func ReimageVirtualMachine(ctx context.Context, virtualMachineName string) error {
-	client := getVirtualMachinesClient()
+	client := getVirtualMachinesClient(ctx)
 	poller, err := client.BeginReimage(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		virtualMachineName,
 		nil)
 
@@ -384,7 +386,7 @@ func ReimageVirtualMachine(ctx context.Context, virtualMachineName string) error
 		return err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	_, err = poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -393,12 +395,12 @@ func ReimageVirtualMachine(ctx context.Context, virtualMachineName string) error
 
 // The operation to delete a virtual machine.
 func DeleteVirtualMachine(ctx context.Context, virtualMachineName string) error {
-	client := getVirtualMachinesClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), virtualMachineName, nil)
+	client := getVirtualMachinesClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, virtualMachineName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -11,26 +11,26 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/compute/armcompute"
 )
 
-func getVirtualMachineExtensionsClient() armcompute.VirtualMachineExtensionsClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getVirtualMachineExtensionsClient(ctx context.Context) armcompute.VirtualMachineExtensionsClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armcompute.NewVirtualMachineExtensionsClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armcompute.NewVirtualMachineExtensionsClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Create VirtualMachineExtensions
-func CreateVirtualMachineExtension(ctx context.Context, vmName string, vmExtensionName string, extensionParameters armcompute.VirtualMachineExtension) error {
-	client := getVirtualMachineExtensionsClient()
+func CreateVirtualMachineExtension(ctx context.Context, vmName string, vmExtensionName string, extensionParameters armcompute.VirtualMachineExtension) (*armcompute.VirtualMachineExtension, error) {
+	client := getVirtualMachineExtensionsClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		vmName,
 		vmExtensionName,
 		extensionParameters,
@@ -38,66 +38,66 @@ func CreateVirtualMachineExtension(ctx context.Context, vmName string, vmExtensi
 	)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VirtualMachineExtension, nil
 }
 
 // Gets the specified virtual machine extension in a specified resource group.
-func GetVirtualMachineExtension(ctx context.Context, vmName string, vmExtensionName string) error {
-	client := getVirtualMachineExtensionsClient()
-	_, err := client.Get(ctx, config.GroupName(), vmName, vmExtensionName, nil)
+func GetVirtualMachineExtension(ctx context.Context, vmName string, vmExtensionName string) (*armcompute.VirtualMachineExtension, error) {
+	client := getVirtualMachineExtensionsClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, vmName, vmExtensionName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VirtualMachineExtension, nil
 }
 
 // Gets all the virtual machine extension in a subscription.
-func ListVirtualMachineExtension(ctx context.Context, vmName string) error {
-	client := getVirtualMachineExtensionsClient()
-	_, err := client.List(ctx, config.GroupName(), vmName, nil)
+func ListVirtualMachineExtension(ctx context.Context, vmName string) (*armcompute.VirtualMachineExtensionsListResult, error) {
+	client := getVirtualMachineExtensionsClient(ctx)
+	resp, err := client.List(ctx, config.ScopeFrom(ctx).GroupName, vmName, nil)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VirtualMachineExtensionsListResult, nil
 }
 
 // Updates virtual machine extension tags.
-func UpdateVirtualMachineExtensionTags(ctx context.Context, vmName string, vmExtensionName string, extensionParameters armcompute.VirtualMachineExtensionUpdate) error {
-	client := getVirtualMachineExtensionsClient()
+func UpdateVirtualMachineExtensionTags(ctx context.Context, vmName string, vmExtensionName string, extensionParameters armcompute.VirtualMachineExtensionUpdate) (*armcompute.VirtualMachineExtension, error) {
+	client := getVirtualMachineExtensionsClient(ctx)
 	poller, err := client.BeginUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		vmName,
 		vmExtensionName,
 		extensionParameters,
 		nil,
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VirtualMachineExtension, nil
 }
 
 // Deletes the specified virtual machine extension.
 func DeleteVirtualMachineExtension(ctx context.Context, vmName string, vmExtensionName string) error {
-	client := getVirtualMachineExtensionsClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), vmName, vmExtensionName, nil)
+	client := getVirtualMachineExtensionsClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, vmName, vmExtensionName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
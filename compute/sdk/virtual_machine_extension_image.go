@@ -10,47 +10,46 @@ import (
 	"log"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
 	"github.com/Azure/azure-sdk-for-go/sdk/compute/armcompute"
 )
 
-func getVirtualMachineExtensionImagesClient() armcompute.VirtualMachineExtensionImagesClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getVirtualMachineExtensionImagesClient(ctx context.Context) armcompute.VirtualMachineExtensionImagesClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armcompute.NewVirtualMachineExtensionImagesClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armcompute.NewVirtualMachineExtensionImagesClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
-//  Gets a virtual machine extension image.
-func GetVirtualMachineExtensionImage(ctx context.Context, publisherName string, typeParameter string, version string) error {
-	client := getVirtualMachineExtensionImagesClient()
-	_, err := client.Get(ctx, config.Location(), publisherName, typeParameter, version, nil)
+// Gets a virtual machine extension image.
+func GetVirtualMachineExtensionImage(ctx context.Context, publisherName string, typeParameter string, version string) (*armcompute.VirtualMachineExtensionImage, error) {
+	client := getVirtualMachineExtensionImagesClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).Location, publisherName, typeParameter, version, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VirtualMachineExtensionImage, nil
 }
 
 // Gets a list of virtual machine extension image types.
-func ListVirtualMachineExtensionImageType(ctx context.Context, publisherName string) error {
-	client := getVirtualMachineExtensionImagesClient()
-	_, err := client.ListTypes(ctx, config.Location(), publisherName, nil)
+func ListVirtualMachineExtensionImageType(ctx context.Context, publisherName string) ([]*armcompute.VirtualMachineExtensionImage, error) {
+	client := getVirtualMachineExtensionImagesClient(ctx)
+	resp, err := client.ListTypes(ctx, config.ScopeFrom(ctx).Location, publisherName, nil)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VirtualMachineExtensionImageArray, nil
 }
 
 // Gets a list of virtual machine extension image versions.
-func ListVirtualMachineExtensionImageVersion(ctx context.Context, publisherName string, typeParameter string) error {
-	client := getVirtualMachineExtensionImagesClient()
-	_, err := client.ListVersions(ctx, config.Location(), publisherName, typeParameter, nil)
+func ListVirtualMachineExtensionImageVersion(ctx context.Context, publisherName string, typeParameter string) ([]*armcompute.VirtualMachineExtensionImage, error) {
+	client := getVirtualMachineExtensionImagesClient(ctx)
+	resp, err := client.ListVersions(ctx, config.ScopeFrom(ctx).Location, publisherName, typeParameter, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VirtualMachineExtensionImageArray, nil
 }
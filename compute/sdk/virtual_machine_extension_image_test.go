@@ -11,19 +11,21 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 )
 
 func TestVirtualMachineExtensionImage(t *testing.T) {
-	groupName := config.GenerateGroupName("compute")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("compute")
 	extensionPublisherName := "Microsoft.Compute"
 	extensionImageType := "VMAccessAgent"
 	extensionImageVersion := "1.0.2"
 
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -31,19 +33,19 @@ func TestVirtualMachineExtensionImage(t *testing.T) {
 		t.Fatalf("failed to create group: %+v", err)
 	}
 
-	err = GetVirtualMachineExtensionImage(ctx, extensionPublisherName, extensionImageType, extensionImageVersion)
+	_, err = GetVirtualMachineExtensionImage(ctx, extensionPublisherName, extensionImageType, extensionImageVersion)
 	if err != nil {
 		t.Fatalf("failed to get virtual machine extension image: %+v", err)
 	}
 	t.Logf("got virtual machine extension image")
 
-	err = ListVirtualMachineExtensionImageType(ctx, extensionPublisherName)
+	_, err = ListVirtualMachineExtensionImageType(ctx, extensionPublisherName)
 	if err != nil {
 		t.Fatalf("failed to list virtual machine extension image type: %+v", err)
 	}
 	t.Logf("listed virtual machine extension image type")
 
-	err = ListVirtualMachineExtensionImageVersion(ctx, extensionPublisherName, extensionImageType)
+	_, err = ListVirtualMachineExtensionImageVersion(ctx, extensionPublisherName, extensionImageType)
 	if err != nil {
 		t.Fatalf("failed to list virtual machine extension image version: %+v", err)
 	}
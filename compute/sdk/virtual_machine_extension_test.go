@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	network "github.com/Azure-Samples/azure-sdk-for-go-samples/network/sdk"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/compute/armcompute"
@@ -19,9 +20,9 @@ import (
 )
 
 func TestVirtualMachineExtension(t *testing.T) {
-	groupName := config.GenerateGroupName("compute")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("compute")
 	virtualMachineExtensionName := config.AppendRandomSuffix("virtualmachineextension")
 	networkInterfaceName := config.AppendRandomSuffix("networkinterface")
 	virtualNetworkName := config.AppendRandomSuffix("virtualnetwork")
@@ -32,6 +33,7 @@ func TestVirtualMachineExtension(t *testing.T) {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 1000*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -176,19 +178,19 @@ func TestVirtualMachineExtension(t *testing.T) {
 		},
 	}
 
-	err = CreateVirtualMachineExtension(ctx, virtualMachineName, virtualMachineExtensionName, extensionParameters)
+	_, err = CreateVirtualMachineExtension(ctx, virtualMachineName, virtualMachineExtensionName, extensionParameters)
 	if err != nil {
 		t.Fatalf("failed to create virtual machine extension: % +v", err)
 	}
 	t.Logf("created virtual machine extension")
 
-	err = GetVirtualMachineExtension(ctx, virtualMachineName, virtualMachineExtensionName)
+	_, err = GetVirtualMachineExtension(ctx, virtualMachineName, virtualMachineExtensionName)
 	if err != nil {
 		t.Fatalf("failed to get virtual machine extension: %+v", err)
 	}
 	t.Logf("got virtual machine extension")
 
-	err = ListVirtualMachineExtension(ctx, virtualMachineName)
+	_, err = ListVirtualMachineExtension(ctx, virtualMachineName)
 	if err != nil {
 		t.Fatalf("failed to list virtual machine extension: %+v", err)
 	}
@@ -200,7 +202,7 @@ func TestVirtualMachineExtension(t *testing.T) {
 			Settings:                "{\"commandToExecute\": \"powershell.exe -c \"Get-Process | Where-Object { $_.CPU -lt 100 }\"}",
 		},
 	}
-	err = UpdateVirtualMachineExtensionTags(ctx, virtualMachineName, virtualMachineExtensionName, virtualMachineExtensionUpdateParameters)
+	_, err = UpdateVirtualMachineExtensionTags(ctx, virtualMachineName, virtualMachineExtensionName, virtualMachineExtensionUpdateParameters)
 	if err != nil {
 		t.Fatalf("failed to update virtual machine extension: %+v", err)
 	}
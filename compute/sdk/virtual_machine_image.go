@@ -10,67 +10,66 @@ import (
 	"log"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
 	"github.com/Azure/azure-sdk-for-go/sdk/compute/armcompute"
 )
 
-func getVirtualMachineImagesClient() armcompute.VirtualMachineImagesClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getVirtualMachineImagesClient(ctx context.Context) armcompute.VirtualMachineImagesClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armcompute.NewVirtualMachineImagesClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armcompute.NewVirtualMachineImagesClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Gets a virtual machine image.
-func GetVirtualMachineImage(ctx context.Context, publisherName string, offer string, skus string, version string) error {
-	client := getVirtualMachineImagesClient()
-	_, err := client.Get(ctx, config.Location(), publisherName, offer, skus, version, nil)
+func GetVirtualMachineImage(ctx context.Context, publisherName string, offer string, skus string, version string) (*armcompute.VirtualMachineImage, error) {
+	client := getVirtualMachineImagesClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).Location, publisherName, offer, skus, version, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VirtualMachineImage, nil
 }
 
 // Gets a list of all virtual machine image versions for the specified location, publisher, offer, and SKU.
-func ListVirtualMachineImage(ctx context.Context, publisherName string, offer string, skus string) error {
-	client := getVirtualMachineImagesClient()
-	_, err := client.List(ctx, config.Location(), publisherName, offer, skus, nil)
+func ListVirtualMachineImage(ctx context.Context, publisherName string, offer string, skus string) ([]*armcompute.VirtualMachineImageResource, error) {
+	client := getVirtualMachineImagesClient(ctx)
+	resp, err := client.List(ctx, config.ScopeFrom(ctx).Location, publisherName, offer, skus, nil)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VirtualMachineImageResourceArray, nil
 }
 
 // Gets a list of virtual machine image offers for the specified location and publisher.
-func ListVirtualMachineImageOffer(ctx context.Context, publisherName string) error {
-	client := getVirtualMachineImagesClient()
-	_, err := client.ListOffers(ctx, config.Location(), publisherName, nil)
+func ListVirtualMachineImageOffer(ctx context.Context, publisherName string) ([]*armcompute.VirtualMachineImageResource, error) {
+	client := getVirtualMachineImagesClient(ctx)
+	resp, err := client.ListOffers(ctx, config.ScopeFrom(ctx).Location, publisherName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VirtualMachineImageResourceArray, nil
 }
 
 // Gets a list of virtual machine image publishers for the specified Azure location.
-func LisVirtualMachineImagePublisher(ctx context.Context) error {
-	client := getVirtualMachineImagesClient()
-	_, err := client.ListPublishers(ctx, config.Location(), nil)
+func LisVirtualMachineImagePublisher(ctx context.Context) ([]*armcompute.VirtualMachineImageResource, error) {
+	client := getVirtualMachineImagesClient(ctx)
+	resp, err := client.ListPublishers(ctx, config.ScopeFrom(ctx).Location, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VirtualMachineImageResourceArray, nil
 }
 
 // Gets a list of virtual machine image SKUs for the specified location, publisher, and offer.
-func ListVirtualMachineImageSKU(ctx context.Context, publisherName string, offer string) error {
-	client := getVirtualMachineImagesClient()
-	_, err := client.ListSKUs(ctx, config.Location(), publisherName, offer, nil)
+func ListVirtualMachineImageSKU(ctx context.Context, publisherName string, offer string) ([]*armcompute.VirtualMachineImageResource, error) {
+	client := getVirtualMachineImagesClient(ctx)
+	resp, err := client.ListSKUs(ctx, config.ScopeFrom(ctx).Location, publisherName, offer, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VirtualMachineImageResourceArray, nil
 }
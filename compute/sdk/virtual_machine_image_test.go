@@ -11,15 +11,17 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 )
 
 func TestVirtualMachineImage(t *testing.T) {
-	groupName := config.GenerateGroupName("compute")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("compute")
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	publisherName := "MicrosoftWindowsServer"
@@ -32,31 +34,31 @@ func TestVirtualMachineImage(t *testing.T) {
 		t.Fatalf("failed to create group: %+v", err)
 	}
 
-	err = GetVirtualMachineImage(ctx, publisherName, offer, skus, version)
+	_, err = GetVirtualMachineImage(ctx, publisherName, offer, skus, version)
 	if err != nil {
 		t.Fatalf("failed to get virtual machine image: %+v", err)
 	}
 	t.Logf("got virtual machine image")
 
-	err = ListVirtualMachineImage(ctx, publisherName, offer, skus)
+	_, err = ListVirtualMachineImage(ctx, publisherName, offer, skus)
 	if err != nil {
 		t.Fatalf("failed to list virtual machine image: %+v", err)
 	}
 	t.Logf("listed virtual machine image")
 
-	err = ListVirtualMachineImageOffer(ctx, publisherName)
+	_, err = ListVirtualMachineImageOffer(ctx, publisherName)
 	if err != nil {
 		t.Fatalf("failed to list virtual machine image offer: %+v", err)
 	}
 	t.Logf("listed virtual machine image offer")
 
-	err = LisVirtualMachineImagePublisher(ctx)
+	_, err = LisVirtualMachineImagePublisher(ctx)
 	if err != nil {
 		t.Fatalf("failed to list virtual machine image publisher: %+v", err)
 	}
 	t.Logf("listed virtual machine image publisher")
 
-	err = ListVirtualMachineImageSKU(ctx, publisherName, offer)
+	_, err = ListVirtualMachineImageSKU(ctx, publisherName, offer)
 	if err != nil {
 		t.Fatalf("failed to list virtual machine image SKU: %+v", err)
 	}
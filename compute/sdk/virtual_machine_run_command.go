@@ -10,43 +10,41 @@ import (
 	"log"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
 	"github.com/Azure/azure-sdk-for-go/sdk/compute/armcompute"
 )
 
-func getVirtualMachineRunCommandsClient() armcompute.VirtualMachineRunCommandsClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getVirtualMachineRunCommandsClient(ctx context.Context) armcompute.VirtualMachineRunCommandsClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armcompute.NewVirtualMachineRunCommandsClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armcompute.NewVirtualMachineRunCommandsClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Gets specific run command for a subscription in a location.
-func GetVirtualMachineRunCommand(ctx context.Context, commandId string) error {
-	client := getVirtualMachineRunCommandsClient()
-	_, err := client.Get(ctx, config.Location(), commandId, nil)
+func GetVirtualMachineRunCommand(ctx context.Context, commandId string) (*armcompute.RunCommandDocument, error) {
+	client := getVirtualMachineRunCommandsClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).Location, commandId, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.RunCommandDocument, nil
 }
 
 // Lists all available run commands for a subscription in a location.
-func ListVirtualMachineRunCommand(ctx context.Context) error {
-	client := getVirtualMachineRunCommandsClient()
-	pager := client.List(config.Location(), nil)
+func ListVirtualMachineRunCommand(ctx context.Context) ([]*armcompute.RunCommandDocumentBase, error) {
+	client := getVirtualMachineRunCommandsClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).Location, nil)
 
+	var results []*armcompute.RunCommandDocumentBase
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().RunCommandListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
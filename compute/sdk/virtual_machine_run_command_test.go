@@ -11,26 +11,28 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 )
 
 func TestVirtualMachineRunCommand(t *testing.T) {
-	groupName := config.GenerateGroupName("compute")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("compute")
 	runCommandName := "RunPowerShellScript"
 
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
-	err := GetVirtualMachineRunCommand(ctx, runCommandName)
+	_, err := GetVirtualMachineRunCommand(ctx, runCommandName)
 	if err != nil {
 		t.Fatalf("failed to get virtual machine run command: %+v", err)
 	}
 	t.Logf("got virtual machine run command")
 
-	err = ListVirtualMachineRunCommand(ctx)
+	_, err = ListVirtualMachineRunCommand(ctx)
 	if err != nil {
 		t.Fatalf("failed to list virtual machine run command: %+v", err)
 	}
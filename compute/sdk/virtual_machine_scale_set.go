@@ -11,50 +11,50 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/compute/armcompute"
 )
 
-func getVirtualMachineScaleSetsClient() armcompute.VirtualMachineScaleSetsClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getVirtualMachineScaleSetsClient(ctx context.Context) armcompute.VirtualMachineScaleSetsClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armcompute.NewVirtualMachineScaleSetsClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armcompute.NewVirtualMachineScaleSetsClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Create or update a VM scale set.
-func CreateVirtualMachineScaleSet(ctx context.Context, vmScaleSetName string, virtualMachineScaleSetParameters armcompute.VirtualMachineScaleSet) error {
-	client := getVirtualMachineScaleSetsClient()
+func CreateVirtualMachineScaleSet(ctx context.Context, vmScaleSetName string, virtualMachineScaleSetParameters armcompute.VirtualMachineScaleSet) (*armcompute.VirtualMachineScaleSet, error) {
+	client := getVirtualMachineScaleSetsClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		vmScaleSetName,
 		virtualMachineScaleSetParameters,
 		nil,
 	)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VirtualMachineScaleSet, nil
 }
 
 // Deletes a VM scale set.
 func DeleteVirtualMachineScaleSet(ctx context.Context, virtualMachineScaleSetName string) error {
-	client := getVirtualMachineScaleSetsClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), virtualMachineScaleSetName, nil)
+	client := getVirtualMachineScaleSetsClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, virtualMachineScaleSetName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -63,10 +63,10 @@ func DeleteVirtualMachineScaleSet(ctx context.Context, virtualMachineScaleSetNam
 
 // Upgrades one or more virtual machines to the latest SKU set in the VM scale set model.
 func UpdateVirtualMachineScaleSetInstance(ctx context.Context, virtualMachineScaleSetName string, vmInstanceIDs armcompute.VirtualMachineScaleSetVMInstanceRequiredIDs) error {
-	client := getVirtualMachineScaleSetsClient()
+	client := getVirtualMachineScaleSetsClient(ctx)
 	_, err := client.BeginUpdateInstances(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		virtualMachineScaleSetName,
 		vmInstanceIDs,
 		nil,
@@ -79,10 +79,10 @@ func UpdateVirtualMachineScaleSetInstance(ctx context.Context, virtualMachineSca
 
 // Deletes virtual machines in a VM scale set.
 func DeleteVirtualMachineScaleSetInstance(ctx context.Context, virtualMachineScaleSetName string, vmInstanceIDs armcompute.VirtualMachineScaleSetVMInstanceRequiredIDs) error {
-	client := getVirtualMachineScaleSetsClient()
+	client := getVirtualMachineScaleSetsClient(ctx)
 	_, err := client.BeginDeleteInstances(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		virtualMachineScaleSetName,
 		vmInstanceIDs,
 		nil,
@@ -94,103 +94,101 @@ func DeleteVirtualMachineScaleSetInstance(ctx context.Context, virtualMachineSca
 }
 
 // Get - Display information about a virtual machine scale set.
-func GetVirtualMachineScaleSet(ctx context.Context, virtualMachineScaleSetName string) error {
-	client := getVirtualMachineScaleSetsClient()
-	_, err := client.Get(ctx, config.GroupName(), virtualMachineScaleSetName, nil)
+func GetVirtualMachineScaleSet(ctx context.Context, virtualMachineScaleSetName string) (*armcompute.VirtualMachineScaleSet, error) {
+	client := getVirtualMachineScaleSetsClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, virtualMachineScaleSetName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VirtualMachineScaleSet, nil
 }
 
 // Gets list of OS upgrades on a VM scale set instance.
-func GetVirtualMachineScaleSetOSUpgradeHistory(ctx context.Context, virtualMachineScaleSetName string) error {
-	client := getVirtualMachineScaleSetsClient()
-	pager := client.GetOSUpgradeHistory(config.GroupName(), virtualMachineScaleSetName, nil)
+func GetVirtualMachineScaleSetOSUpgradeHistory(ctx context.Context, virtualMachineScaleSetName string) ([]*armcompute.UpgradeOperationHistoricalStatusInfo, error) {
+	client := getVirtualMachineScaleSetsClient(ctx)
+	pager := client.GetOSUpgradeHistory(config.ScopeFrom(ctx).GroupName, virtualMachineScaleSetName, nil)
+
+	var results []*armcompute.UpgradeOperationHistoricalStatusInfo
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().VirtualMachineScaleSetListOSUpgradeHistory.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Gets the status of a VM scale set instance.
-func GetVirtualMachineScaleSetInstanceView(ctx context.Context, virtualMachineScaleSetName string) error {
-	client := getVirtualMachineScaleSetsClient()
-	_, err := client.GetInstanceView(ctx, config.GroupName(), virtualMachineScaleSetName, nil)
+func GetVirtualMachineScaleSetInstanceView(ctx context.Context, virtualMachineScaleSetName string) (*armcompute.VirtualMachineScaleSetInstanceView, error) {
+	client := getVirtualMachineScaleSetsClient(ctx)
+	resp, err := client.GetInstanceView(ctx, config.ScopeFrom(ctx).GroupName, virtualMachineScaleSetName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VirtualMachineScaleSetInstanceView, nil
 }
 
 // Gets a list of all VM scale sets under a resource group.
-func ListVirtualMachineScaleSet(ctx context.Context) error {
-	client := getVirtualMachineScaleSetsClient()
-	pager := client.List(config.GroupName(), nil)
+func ListVirtualMachineScaleSet(ctx context.Context) ([]*armcompute.VirtualMachineScaleSet, error) {
+	client := getVirtualMachineScaleSetsClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).GroupName, nil)
 
+	var results []*armcompute.VirtualMachineScaleSet
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().VirtualMachineScaleSetListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Gets a list of all VM Scale Sets in the subscription, regardless of the associated resource group. Use nextLink property in the response to
 // get the next page of VM Scale Sets. Do this till nextLink is
 // null to fetch all the VM Scale Sets.
-func ListAllVirtualMachineScaleSet(ctx context.Context) error {
-	client := getVirtualMachineScaleSetsClient()
+func ListAllVirtualMachineScaleSet(ctx context.Context) ([]*armcompute.VirtualMachineScaleSet, error) {
+	client := getVirtualMachineScaleSetsClient(ctx)
 	pager := client.ListAll(nil)
+
+	var results []*armcompute.VirtualMachineScaleSet
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().VirtualMachineScaleSetListWithLinkResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Gets a list of SKUs available for your VM scale set, including the minimum and maximum VM instances allowed for each SKU.
-func ListVirtualMachineScaleSetSKU(ctx context.Context, virtualMachineScaleSetName string) error {
-	client := getVirtualMachineScaleSetsClient()
-	pager := client.ListSKUs(config.GroupName(), virtualMachineScaleSetName, nil)
+func ListVirtualMachineScaleSetSKU(ctx context.Context, virtualMachineScaleSetName string) ([]*armcompute.VirtualMachineScaleSetSKU, error) {
+	client := getVirtualMachineScaleSetsClient(ctx)
+	pager := client.ListSKUs(config.ScopeFrom(ctx).GroupName, virtualMachineScaleSetName, nil)
 
+	var results []*armcompute.VirtualMachineScaleSetSKU
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().VirtualMachineScaleSetListSKUsResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Restarts one or more virtual machines in a VM scale set.
 func RestartVirtualMachineScaleSet(ctx context.Context, virtualMachineScaleSetName string) error {
-	client := getVirtualMachineScaleSetsClient()
-	poller, err := client.BeginRestart(ctx, config.GroupName(), virtualMachineScaleSetName, nil)
+	client := getVirtualMachineScaleSetsClient(ctx)
+	poller, err := client.BeginRestart(ctx, config.ScopeFrom(ctx).GroupName, virtualMachineScaleSetName, nil)
 
 	if err != nil {
 		return err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	_, err = poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -201,14 +199,14 @@ func RestartVirtualMachineScaleSet(ctx context.Context, virtualMachineScaleSetNa
 // the resources. Instead, use deallocate to release resources and
 // avoid charges.
 func VirtualMachineScaleSetPowerOff(ctx context.Context, virtualMachineScaleSetName string) error {
-	client := getVirtualMachineScaleSetsClient()
-	poller, err := client.BeginPowerOff(ctx, config.GroupName(), virtualMachineScaleSetName, nil)
+	client := getVirtualMachineScaleSetsClient(ctx)
+	poller, err := client.BeginPowerOff(ctx, config.ScopeFrom(ctx).GroupName, virtualMachineScaleSetName, nil)
 
 	if err != nil {
 		return err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	_, err = poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -217,14 +215,14 @@ func VirtualMachineScaleSetPowerOff(ctx context.Context, virtualMachineScaleSetN
 
 // Starts one or more virtual machines in a VM scale set.
 func StartVirtualMachineScaleSet(ctx context.Context, virtualMachineScaleSetName string) error {
-	client := getVirtualMachineScaleSetsClient()
-	poller, err := client.BeginStart(ctx, config.GroupName(), virtualMachineScaleSetName, nil)
+	client := getVirtualMachineScaleSetsClient(ctx)
+	poller, err := client.BeginStart(ctx, config.ScopeFrom(ctx).GroupName, virtualMachineScaleSetName, nil)
 
 	if err != nil {
 		return err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	_, err = poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -233,14 +231,14 @@ func StartVirtualMachineScaleSet(ctx context.Context, virtualMachineScaleSetName
 
 // Shuts down all the virtual machines in the virtual machine scale set, moves them to a new node, and powers them back on.
 func RedeployVirtualMachineScaleSet(ctx context.Context, virtualMachineScaleSetName string) error {
-	client := getVirtualMachineScaleSetsClient()
-	poller, err := client.BeginRedeploy(ctx, config.GroupName(), virtualMachineScaleSetName, nil)
+	client := getVirtualMachineScaleSetsClient(ctx)
+	poller, err := client.BeginRedeploy(ctx, config.ScopeFrom(ctx).GroupName, virtualMachineScaleSetName, nil)
 
 	if err != nil {
 		return err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	_, err = poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -251,14 +249,14 @@ func RedeployVirtualMachineScaleSet(ctx context.Context, virtualMachineScaleSetN
 // not billed for the compute resources that this virtual machine
 // scale set deallocates.
 func DeallocateVirtualMachineScaleSet(ctx context.Context, virtualMachineScaleSetName string) error {
-	client := getVirtualMachineScaleSetsClient()
-	poller, err := client.BeginDeallocate(ctx, config.GroupName(), virtualMachineScaleSetName, nil)
+	client := getVirtualMachineScaleSetsClient(ctx)
+	poller, err := client.BeginDeallocate(ctx, config.ScopeFrom(ctx).GroupName, virtualMachineScaleSetName, nil)
 
 	if err != nil {
 		return err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	_, err = poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -268,15 +266,15 @@ func DeallocateVirtualMachineScaleSet(ctx context.Context, virtualMachineScaleSe
 // Changes ServiceState property for a given service
 func SetVirtualMachineScaleSetOrchestrationServiceState(ctx context.Context, virtualMachineScaleSetName string,
 	orchestrationServiceStateInputParameters armcompute.OrchestrationServiceStateInput) error {
-	client := getVirtualMachineScaleSetsClient()
-	poller, err := client.BeginSetOrchestrationServiceState(ctx, config.GroupName(),
+	client := getVirtualMachineScaleSetsClient(ctx)
+	poller, err := client.BeginSetOrchestrationServiceState(ctx, config.ScopeFrom(ctx).GroupName,
 		virtualMachineScaleSetName, orchestrationServiceStateInputParameters, nil)
 
 	if err != nil {
 		return err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	_, err = poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -287,14 +285,14 @@ func SetVirtualMachineScaleSetOrchestrationServiceState(ctx context.Context, vir
 // machines who have a ephemeral OS disk the virtual machine is
 // reset to initial state.
 func VirtualMachineScaleSetReimage(ctx context.Context, virtualMachineScaleSetName string) error {
-	client := getVirtualMachineScaleSetsClient()
-	poller, err := client.BeginReimage(ctx, config.GroupName(), virtualMachineScaleSetName, nil)
+	client := getVirtualMachineScaleSetsClient(ctx)
+	poller, err := client.BeginReimage(ctx, config.ScopeFrom(ctx).GroupName, virtualMachineScaleSetName, nil)
 
 	if err != nil {
 		return err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	_, err = poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -304,15 +302,15 @@ func VirtualMachineScaleSetReimage(ctx context.Context, virtualMachineScaleSetNa
 // Reimages all the disks ( including data disks ) in the virtual machines in a VM scale set. This operation is only supported for managed
 // disks.
 func ReimageAllVirtualMachineScaleSet(ctx context.Context, virtualMachineScaleSetName string) error {
-	client := getVirtualMachineScaleSetsClient()
-	poller, err := client.BeginReimageAll(ctx, config.GroupName(),
+	client := getVirtualMachineScaleSetsClient(ctx)
+	poller, err := client.BeginReimageAll(ctx, config.ScopeFrom(ctx).GroupName,
 		virtualMachineScaleSetName, nil)
 
 	if err != nil {
 		return err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	_, err = poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -323,15 +321,15 @@ func ReimageAllVirtualMachineScaleSet(ctx context.Context, virtualMachineScaleSe
 // maintenance will be failed. Please refer to best practices for more
 // details: https://docs.microsoft.com/en-us/azure/virtual-machine-scale-sets/virtual-machine-scale-sets-maintenance-notifications
 func VirtualMachineScaleSetPerformMaintenance(ctx context.Context, virtualMachineScaleSetName string) error {
-	client := getVirtualMachineScaleSetsClient()
-	poller, err := client.BeginPerformMaintenance(ctx, config.GroupName(),
+	client := getVirtualMachineScaleSetsClient(ctx)
+	poller, err := client.BeginPerformMaintenance(ctx, config.ScopeFrom(ctx).GroupName,
 		virtualMachineScaleSetName, nil)
 
 	if err != nil {
 		return err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	_, err = poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -339,17 +337,21 @@ func VirtualMachineScaleSetPerformMaintenance(ctx context.Context, virtualMachin
 }
 
 // Update a VM scale set.
-func UpdateVirtualMachineScaleSet(ctx context.Context, virtualMachineScaleSetName string, virtualMachineScaleSetUpdateParameters armcompute.VirtualMachineScaleSetUpdate) error {
-	client := getVirtualMachineScaleSetsClient()
-	_, err := client.BeginUpdate(
+func UpdateVirtualMachineScaleSet(ctx context.Context, virtualMachineScaleSetName string, virtualMachineScaleSetUpdateParameters armcompute.VirtualMachineScaleSetUpdate) (*armcompute.VirtualMachineScaleSet, error) {
+	client := getVirtualMachineScaleSetsClient(ctx)
+	poller, err := client.BeginUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		virtualMachineScaleSetName,
 		virtualMachineScaleSetUpdateParameters,
 		nil,
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
+	if err != nil {
+		return nil, err
+	}
+	return resp.VirtualMachineScaleSet, nil
 }
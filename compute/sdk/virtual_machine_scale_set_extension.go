@@ -11,27 +11,27 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/compute/armcompute"
 )
 
-func getVirtualMachineScaleSetExtensionsClient() armcompute.VirtualMachineScaleSetExtensionsClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getVirtualMachineScaleSetExtensionsClient(ctx context.Context) armcompute.VirtualMachineScaleSetExtensionsClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armcompute.NewVirtualMachineScaleSetExtensionsClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armcompute.NewVirtualMachineScaleSetExtensionsClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // The operation to create or update an extension.
 func CreateVirtualMachineScaleSetExtension(ctx context.Context, vmScaleSetName string, vmssExtensionName string,
-	extensionParameters armcompute.VirtualMachineScaleSetExtension) error {
-	client := getVirtualMachineScaleSetExtensionsClient()
+	extensionParameters armcompute.VirtualMachineScaleSetExtension) (*armcompute.VirtualMachineScaleSetExtension, error) {
+	client := getVirtualMachineScaleSetExtensionsClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		vmScaleSetName,
 		vmssExtensionName,
 		extensionParameters,
@@ -39,73 +39,72 @@ func CreateVirtualMachineScaleSetExtension(ctx context.Context, vmScaleSetName s
 	)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VirtualMachineScaleSetExtension, nil
 }
 
 // The operation to get the extension
-func GetVirtualMachineScaleSetExtension(ctx context.Context, vmScaleSetName string, vmssExtensionName string) error {
-	client := getVirtualMachineScaleSetExtensionsClient()
-	_, err := client.Get(ctx, config.GroupName(), vmScaleSetName, vmssExtensionName, nil)
+func GetVirtualMachineScaleSetExtension(ctx context.Context, vmScaleSetName string, vmssExtensionName string) (*armcompute.VirtualMachineScaleSetExtension, error) {
+	client := getVirtualMachineScaleSetExtensionsClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, vmScaleSetName, vmssExtensionName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VirtualMachineScaleSetExtension, nil
 }
 
 // Gets a list of all extensions in a VM scale set.
-func ListVirtualMachineScaleSetExtension(ctx context.Context, vmScaleSetName string) error {
-	client := getVirtualMachineScaleSetExtensionsClient()
-	pager := client.List(config.GroupName(), vmScaleSetName, nil)
+func ListVirtualMachineScaleSetExtension(ctx context.Context, vmScaleSetName string) ([]*armcompute.VirtualMachineScaleSetExtension, error) {
+	client := getVirtualMachineScaleSetExtensionsClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).GroupName, vmScaleSetName, nil)
 
+	var results []*armcompute.VirtualMachineScaleSetExtension
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().VirtualMachineScaleSetExtensionListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // The operation to update an extension.
 func UpdateVirtualMachineScaleSetExtensionTags(ctx context.Context, vmScaleSetName string, vmssExtensionName string,
-	extensionParameters armcompute.VirtualMachineScaleSetExtensionUpdate) error {
-	client := getVirtualMachineScaleSetExtensionsClient()
+	extensionParameters armcompute.VirtualMachineScaleSetExtensionUpdate) (*armcompute.VirtualMachineScaleSetExtension, error) {
+	client := getVirtualMachineScaleSetExtensionsClient(ctx)
 	poller, err := client.BeginUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		vmScaleSetName,
 		vmssExtensionName,
 		extensionParameters,
 		nil,
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VirtualMachineScaleSetExtension, nil
 }
 
 // The operation to delete the extension.
 func DeleteVirtualMachineScaleSetExtension(ctx context.Context, vmScaleSetName string, vmssExtensionName string) error {
-	client := getVirtualMachineScaleSetExtensionsClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), vmScaleSetName, vmssExtensionName, nil)
+	client := getVirtualMachineScaleSetExtensionsClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, vmScaleSetName, vmssExtensionName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
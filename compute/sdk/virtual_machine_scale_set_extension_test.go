@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	network "github.com/Azure-Samples/azure-sdk-for-go-samples/network/sdk"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/compute/armcompute"
@@ -19,9 +20,9 @@ import (
 )
 
 func TestVirtualMachineScaleSetExtension(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	virtualMachineScaleSetExtensionName := config.AppendRandomSuffix("virtualmachinescalesetextension")
 	virtualMachineScaleSetName := config.AppendRandomSuffix("virtualmachinescaleset")
 	virtualNetworkName := config.AppendRandomSuffix("virtualnetwork")
@@ -29,6 +30,7 @@ func TestVirtualMachineScaleSetExtension(t *testing.T) {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 1000*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -119,7 +121,7 @@ func TestVirtualMachineScaleSetExtension(t *testing.T) {
 			Tier:     to.StringPtr("Standard"),
 		},
 	}
-	err = CreateVirtualMachineScaleSet(ctx, virtualMachineScaleSetName, virtualMachineScaleSetParameters)
+	_, err = CreateVirtualMachineScaleSet(ctx, virtualMachineScaleSetName, virtualMachineScaleSetParameters)
 	if err != nil {
 		t.Fatalf("failed to create virtual machine scale set: % +v", err)
 	}
@@ -132,20 +134,20 @@ func TestVirtualMachineScaleSetExtension(t *testing.T) {
 			TypeHandlerVersion:      to.StringPtr("1.4"),
 		},
 	}
-	err = CreateVirtualMachineScaleSetExtension(ctx, virtualMachineScaleSetName,
+	_, err = CreateVirtualMachineScaleSetExtension(ctx, virtualMachineScaleSetName,
 		virtualMachineScaleSetExtensionName, extensionParameters)
 	if err != nil {
 		t.Fatalf("failed to create virtual machine scale set extension: % +v", err)
 	}
 	t.Logf("created virtual machine scale set extension")
 
-	err = GetVirtualMachineScaleSetExtension(ctx, virtualMachineScaleSetName, virtualMachineScaleSetExtensionName)
+	_, err = GetVirtualMachineScaleSetExtension(ctx, virtualMachineScaleSetName, virtualMachineScaleSetExtensionName)
 	if err != nil {
 		t.Fatalf("failed to get virtual machine scale set extension: %+v", err)
 	}
 	t.Logf("got virtual machine scale set extension")
 
-	err = ListVirtualMachineScaleSetExtension(ctx, virtualMachineScaleSetName)
+	_, err = ListVirtualMachineScaleSetExtension(ctx, virtualMachineScaleSetName)
 	if err != nil {
 		t.Fatalf("failed to list virtual machine scale set extension: %+v", err)
 	}
@@ -156,7 +158,7 @@ func TestVirtualMachineScaleSetExtension(t *testing.T) {
 			AutoUpgradeMinorVersion: to.BoolPtr(true),
 		},
 	}
-	err = UpdateVirtualMachineScaleSetExtensionTags(ctx, virtualMachineScaleSetName,
+	_, err = UpdateVirtualMachineScaleSetExtensionTags(ctx, virtualMachineScaleSetName,
 		virtualMachineScaleSetExtensionName, virtualMachineScaleSetExtensionUpdateParameters)
 	if err != nil {
 		t.Fatalf("failed to update tags for virtual machine scale set extension: %+v", err)
@@ -11,17 +11,17 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/compute/armcompute"
 )
 
-func getVirtualMachineScaleSetRollingUpgradesClient() armcompute.VirtualMachineScaleSetRollingUpgradesClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getVirtualMachineScaleSetRollingUpgradesClient(ctx context.Context) armcompute.VirtualMachineScaleSetRollingUpgradesClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armcompute.NewVirtualMachineScaleSetRollingUpgradesClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armcompute.NewVirtualMachineScaleSetRollingUpgradesClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
@@ -29,14 +29,14 @@ func getVirtualMachineScaleSetRollingUpgradesClient() armcompute.VirtualMachineS
 // version. Instances which are already running the latest extension versions
 // are not affected.
 func StartRollingExtensionUpgrade(ctx context.Context, vmScaleSetName string) error {
-	client := getVirtualMachineScaleSetRollingUpgradesClient()
-	poller, err := client.BeginStartExtensionUpgrade(ctx, config.GroupName(), vmScaleSetName, nil)
+	client := getVirtualMachineScaleSetRollingUpgradesClient(ctx)
+	poller, err := client.BeginStartExtensionUpgrade(ctx, config.ScopeFrom(ctx).GroupName, vmScaleSetName, nil)
 
 	if err != nil {
 		return err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	_, err = poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -47,8 +47,8 @@ func StartRollingExtensionUpgrade(ctx context.Context, vmScaleSetName string) er
 // which are already running the latest available OS version are not
 // affected.
 func StartRollingUpgradeOSUpgrade(ctx context.Context, vmScaleSetName string, polluntilDown bool) error {
-	client := getVirtualMachineScaleSetRollingUpgradesClient()
-	poller, err := client.BeginStartOSUpgrade(ctx, config.GroupName(), vmScaleSetName, nil)
+	client := getVirtualMachineScaleSetRollingUpgradesClient(ctx)
+	poller, err := client.BeginStartOSUpgrade(ctx, config.ScopeFrom(ctx).GroupName, vmScaleSetName, nil)
 
 	if err != nil {
 		return err
@@ -56,7 +56,7 @@ func StartRollingUpgradeOSUpgrade(ctx context.Context, vmScaleSetName string, po
 
 	// do not call PollUntilDone function for cancel test
 	if polluntilDown {
-		_, err = poller.PollUntilDone(ctx, 30*time.Second)
+		_, err = poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 		if err != nil {
 			return err
 		}
@@ -65,26 +65,26 @@ func StartRollingUpgradeOSUpgrade(ctx context.Context, vmScaleSetName string, po
 }
 
 // Gets the status of the latest virtual machine scale set rolling upgrade.
-func GetLatestVirtualMachineScaleSetRollingUpgrade(ctx context.Context, vmScaleSetName string) error {
-	client := getVirtualMachineScaleSetRollingUpgradesClient()
-	_, err := client.GetLatest(ctx, config.GroupName(), vmScaleSetName, nil)
+func GetLatestVirtualMachineScaleSetRollingUpgrade(ctx context.Context, vmScaleSetName string) (*armcompute.RollingUpgradeStatusInfo, error) {
+	client := getVirtualMachineScaleSetRollingUpgradesClient(ctx)
+	resp, err := client.GetLatest(ctx, config.ScopeFrom(ctx).GroupName, vmScaleSetName, nil)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.RollingUpgradeStatusInfo, nil
 }
 
 // Cancels the current virtual machine scale set rolling upgrade
 func CancelScaleSetRollingUpgrade(ctx context.Context, vmScaleSetName string) error {
-	client := getVirtualMachineScaleSetRollingUpgradesClient()
-	poller, err := client.BeginCancel(ctx, config.GroupName(), vmScaleSetName, nil)
+	client := getVirtualMachineScaleSetRollingUpgradesClient(ctx)
+	poller, err := client.BeginCancel(ctx, config.ScopeFrom(ctx).GroupName, vmScaleSetName, nil)
 
 	if err != nil {
 		return err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	_, err = poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
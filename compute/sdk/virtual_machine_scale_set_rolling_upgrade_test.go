@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	network "github.com/Azure-Samples/azure-sdk-for-go-samples/network/sdk"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/compute/armcompute"
@@ -19,15 +20,16 @@ import (
 )
 
 func TestVirtualMachineScaleSetRollingUpgrade(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	vmScaleSetName := config.AppendRandomSuffix("virtualmachinescaleset")
 	virtualNetworkName := config.AppendRandomSuffix("virtualnetwork")
 	subNetName := config.AppendRandomSuffix("subnet")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3000*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -122,7 +124,7 @@ func TestVirtualMachineScaleSetRollingUpgrade(t *testing.T) {
 			Tier:     to.StringPtr("Standard"),
 		},
 	}
-	err = CreateVirtualMachineScaleSet(ctx, vmScaleSetName, virtualMachineScaleSetParameters)
+	_, err = CreateVirtualMachineScaleSet(ctx, vmScaleSetName, virtualMachineScaleSetParameters)
 	if err != nil {
 		t.Fatalf("failed to create virtual machine scale set: % +v", err)
 	}
@@ -139,7 +141,7 @@ func TestVirtualMachineScaleSetRollingUpgrade(t *testing.T) {
 	}
 	t.Logf("started a rolling upgrade to move all virtual machine scale set instances to the latest available Platform Image OS version")
 
-	err = GetLatestVirtualMachineScaleSetRollingUpgrade(ctx, vmScaleSetName)
+	_, err = GetLatestVirtualMachineScaleSetRollingUpgrade(ctx, vmScaleSetName)
 	if err != nil {
 		t.Fatalf("failed to get the status of the latest virtual machine scale set rolling upgrade: %+v", err)
 	}
@@ -12,6 +12,8 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/resourceid"
 	network "github.com/Azure-Samples/azure-sdk-for-go-samples/network/sdk"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/compute/armcompute"
@@ -20,9 +22,9 @@ import (
 )
 
 func TestVirtualMachineScaleSet(t *testing.T) {
-	groupName := config.GenerateGroupName("compute")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("compute")
 	virtualMachineScaleSetName := config.AppendRandomSuffix("virtualmachinescaleset")
 	virtualNetworkName := config.AppendRandomSuffix("virtualnetwork")
 	subNetName := config.AppendRandomSuffix("subnet")
@@ -36,6 +38,7 @@ func TestVirtualMachineScaleSet(t *testing.T) {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 8000*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -88,7 +91,7 @@ func TestVirtualMachineScaleSet(t *testing.T) {
 		t.Fatalf("failed to create public ip address: %+v", err)
 	}
 
-	loadBalancerUrl := "/subscriptions/" + config.SubscriptionID() + "/resourceGroups/" + config.GroupName() + "/providers/Microsoft.Network/loadBalancers/" + loadBalancerName
+	loadBalancerID := resourceid.ResourceGroup(config.SubscriptionID(), groupName).Resource("Microsoft.Network", "loadBalancers", loadBalancerName)
 	loadBalancerParameters := armnetwork.LoadBalancer{
 		Resource: armnetwork.Resource{
 			Location: to.StringPtr(config.Location()),
@@ -116,20 +119,20 @@ func TestVirtualMachineScaleSet(t *testing.T) {
 					Name: &loadBalancingRuleName,
 					Properties: &armnetwork.LoadBalancingRulePropertiesFormat{
 						BackendAddressPool: &armnetwork.SubResource{
-							ID: to.StringPtr(loadBalancerUrl + "/backendAddressPools/" + backendAddressPoolName),
+							ID: to.StringPtr(loadBalancerID.Child("backendAddressPools", backendAddressPoolName).String()),
 						},
 						BackendPort:         to.Int32Ptr(80),
 						DisableOutboundSnat: to.BoolPtr(true),
 						EnableFloatingIP:    to.BoolPtr(true),
 						EnableTCPReset:      new(bool),
 						FrontendIPConfiguration: &armnetwork.SubResource{
-							ID: to.StringPtr(loadBalancerUrl + "/frontendIPConfigurations/" + frontendIpConfigurationName),
+							ID: to.StringPtr(loadBalancerID.Child("frontendIPConfigurations", frontendIpConfigurationName).String()),
 						},
 						FrontendPort:         to.Int32Ptr(80),
 						IdleTimeoutInMinutes: to.Int32Ptr(15),
 						LoadDistribution:     armnetwork.LoadDistributionDefault.ToPtr(),
 						Probe: &armnetwork.SubResource{
-							ID: to.StringPtr(loadBalancerUrl + "/probes/" + probeName),
+							ID: to.StringPtr(loadBalancerID.Child("probes", probeName).String()),
 						},
 						Protocol: armnetwork.TransportProtocolTCP.ToPtr(),
 					},
@@ -140,11 +143,11 @@ func TestVirtualMachineScaleSet(t *testing.T) {
 					Name: &outBoundRuleName,
 					Properties: &armnetwork.OutboundRulePropertiesFormat{
 						BackendAddressPool: &armnetwork.SubResource{
-							ID: to.StringPtr(loadBalancerUrl + "/backendAddressPools/" + backendAddressPoolName),
+							ID: to.StringPtr(loadBalancerID.Child("backendAddressPools", backendAddressPoolName).String()),
 						},
 						FrontendIPConfigurations: []*armnetwork.SubResource{
 							{
-								ID: to.StringPtr(loadBalancerUrl + "/frontendIPConfigurations/" + frontendIpConfigurationName),
+								ID: to.StringPtr(loadBalancerID.Child("frontendIPConfigurations", frontendIpConfigurationName).String()),
 							},
 						},
 						Protocol: armnetwork.LoadBalancerOutboundRuleProtocolAll.ToPtr(),
@@ -242,7 +245,7 @@ func TestVirtualMachineScaleSet(t *testing.T) {
 		},
 	}
 
-	err = CreateVirtualMachineScaleSet(ctx, virtualMachineScaleSetName, virtualMachineScaleSetParameters)
+	_, err = CreateVirtualMachineScaleSet(ctx, virtualMachineScaleSetName, virtualMachineScaleSetParameters)
 	if err != nil {
 		t.Fatalf("failed to create virtual machine scale set: % +v", err)
 	}
@@ -281,7 +284,7 @@ func TestVirtualMachineScaleSet(t *testing.T) {
 	instanceId := 0
 	for i := 0; i < 4; i++ {
 		instanceId = i
-		err = GetVirtualMachineScaleSetVmInstanceView(ctx, virtualMachineScaleSetName, strconv.Itoa(instanceId))
+		_, err = GetVirtualMachineScaleSetVmInstanceView(ctx, virtualMachineScaleSetName, strconv.Itoa(instanceId))
 		if err != nil {
 			if instanceId >= 3 {
 				t.Fatalf("failed to redeploy a virtual machines in a VM scale set: %+v", err)
@@ -310,37 +313,37 @@ func TestVirtualMachineScaleSet(t *testing.T) {
 	}
 	t.Logf("updated virtual machine scale instance")
 
-	err = GetVirtualMachineScaleSet(ctx, virtualMachineScaleSetName)
+	_, err = GetVirtualMachineScaleSet(ctx, virtualMachineScaleSetName)
 	if err != nil {
 		t.Fatalf("failed to get virtual machine scale set: %+v", err)
 	}
 	t.Logf("got virtual machine scale set")
 
-	err = GetVirtualMachineScaleSetOSUpgradeHistory(ctx, virtualMachineScaleSetName)
+	_, err = GetVirtualMachineScaleSetOSUpgradeHistory(ctx, virtualMachineScaleSetName)
 	if err != nil {
 		t.Fatalf("failed to get list of OS upgrades on a VM scale set instance: %+v", err)
 	}
 	t.Logf("got list of OS upgrades on a VM scale set instance")
 
-	err = GetVirtualMachineScaleSetInstanceView(ctx, virtualMachineScaleSetName)
+	_, err = GetVirtualMachineScaleSetInstanceView(ctx, virtualMachineScaleSetName)
 	if err != nil {
 		t.Fatalf("failed to get the status of a VM scale set instance: %+v", err)
 	}
 	t.Logf("got the status of a VM scale set instance")
 
-	err = ListVirtualMachineScaleSet(ctx)
+	_, err = ListVirtualMachineScaleSet(ctx)
 	if err != nil {
 		t.Fatalf("failed to list virtual machine scale set: %+v", err)
 	}
 	t.Logf("listed virtual machine scale set")
 
-	err = ListAllVirtualMachineScaleSet(ctx)
+	_, err = ListAllVirtualMachineScaleSet(ctx)
 	if err != nil {
 		t.Fatalf("failed to list all virtual machine scale set: %+v", err)
 	}
 	t.Logf("listed all virtual machine scale set")
 
-	err = ListVirtualMachineScaleSetSKU(ctx, virtualMachineScaleSetName)
+	_, err = ListVirtualMachineScaleSetSKU(ctx, virtualMachineScaleSetName)
 	if err != nil {
 		t.Fatalf("failed to list SKUs available for VM scale set: %+v", err)
 	}
@@ -358,7 +361,7 @@ func TestVirtualMachineScaleSet(t *testing.T) {
 			Tier:     to.StringPtr("Standard"),
 		},
 	}
-	err = UpdateVirtualMachineScaleSet(ctx, virtualMachineScaleSetName, virtualMachineScaleSetUpdateParameters)
+	_, err = UpdateVirtualMachineScaleSet(ctx, virtualMachineScaleSetName, virtualMachineScaleSetUpdateParameters)
 	if err != nil {
 		t.Fatalf("failed to update VM scale set: %+v", err)
 	}
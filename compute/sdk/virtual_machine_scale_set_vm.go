@@ -11,93 +11,92 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/compute/armcompute"
 )
 
-func getVirtualMachineScaleSetVmsClient() armcompute.VirtualMachineScaleSetVMsClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getVirtualMachineScaleSetVmsClient(ctx context.Context) armcompute.VirtualMachineScaleSetVMsClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armcompute.NewVirtualMachineScaleSetVMsClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armcompute.NewVirtualMachineScaleSetVMsClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Gets the status of a virtual machine from a VM scale set.
-func GetVirtualMachineScaleSetVmInstanceView(ctx context.Context, vmScaleSetName string, instanceId string) error {
-	client := getVirtualMachineScaleSetVmsClient()
-	_, err := client.GetInstanceView(ctx, config.GroupName(), vmScaleSetName, instanceId, nil)
+func GetVirtualMachineScaleSetVmInstanceView(ctx context.Context, vmScaleSetName string, instanceId string) (*armcompute.VirtualMachineScaleSetVMInstanceView, error) {
+	client := getVirtualMachineScaleSetVmsClient(ctx)
+	resp, err := client.GetInstanceView(ctx, config.ScopeFrom(ctx).GroupName, vmScaleSetName, instanceId, nil)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VirtualMachineScaleSetVMInstanceView, nil
 }
 
 // Gets the status of a virtual machine from a VM scale set.
-func ListVirtualMachineScaleSetVm(ctx context.Context, vmScaleSetName string) error {
-	client := getVirtualMachineScaleSetVmsClient()
-	pager := client.List(config.GroupName(), vmScaleSetName, nil)
+func ListVirtualMachineScaleSetVm(ctx context.Context, vmScaleSetName string) ([]*armcompute.VirtualMachineScaleSetVM, error) {
+	client := getVirtualMachineScaleSetVmsClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).GroupName, vmScaleSetName, nil)
 
+	var results []*armcompute.VirtualMachineScaleSetVM
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().VirtualMachineScaleSetVMListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Gets a virtual machine from a VM scale set
-func GetVirtualMachineScaleSetVm(ctx context.Context, vmScaleSetName string, instanceId string) error {
-	client := getVirtualMachineScaleSetVmsClient()
-	_, err := client.Get(ctx, config.GroupName(), vmScaleSetName, instanceId, nil)
+func GetVirtualMachineScaleSetVm(ctx context.Context, vmScaleSetName string, instanceId string) (*armcompute.VirtualMachineScaleSetVM, error) {
+	client := getVirtualMachineScaleSetVmsClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, vmScaleSetName, instanceId, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VirtualMachineScaleSetVM, nil
 }
 
 // Updates a virtual machine of a VM scale set.
 func UpdateVirtualMachineScaleSetVm(ctx context.Context, vmScaleSetName string, instanceId string,
-	virtualMachineScaleSetVMParameters armcompute.VirtualMachineScaleSetVM) error {
-	client := getVirtualMachineScaleSetVmsClient()
+	virtualMachineScaleSetVMParameters armcompute.VirtualMachineScaleSetVM) (*armcompute.VirtualMachineScaleSetVM, error) {
+	client := getVirtualMachineScaleSetVmsClient(ctx)
 	poller, err := client.BeginUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		vmScaleSetName,
 		instanceId,
 		virtualMachineScaleSetVMParameters,
 		nil,
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VirtualMachineScaleSetVM, nil
 }
 
 // Power off (stop) a virtual machine in a VM scale set. Note that resources are still attached and you are getting charged for the resources.
 // Instead, use deallocate to release resources and avoid
 // charges.
 func VirtualMachineScaleSetVmPowerOff(ctx context.Context, vmScaleSetName string, instanceId string) error {
-	client := getVirtualMachineScaleSetVmsClient()
-	poller, err := client.BeginPowerOff(ctx, config.GroupName(), vmScaleSetName, instanceId, nil)
+	client := getVirtualMachineScaleSetVmsClient(ctx)
+	poller, err := client.BeginPowerOff(ctx, config.ScopeFrom(ctx).GroupName, vmScaleSetName, instanceId, nil)
 
 	if err != nil {
 		return err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	_, err = poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -106,14 +105,14 @@ func VirtualMachineScaleSetVmPowerOff(ctx context.Context, vmScaleSetName string
 
 // Starts a virtual machine in a VM scale set
 func StartVirtualMachineScaleSetVm(ctx context.Context, virtualMachineScaleSetName string, instanceId string) error {
-	client := getVirtualMachineScaleSetVmsClient()
-	poller, err := client.BeginStart(ctx, config.GroupName(), virtualMachineScaleSetName, instanceId, nil)
+	client := getVirtualMachineScaleSetVmsClient(ctx)
+	poller, err := client.BeginStart(ctx, config.ScopeFrom(ctx).GroupName, virtualMachineScaleSetName, instanceId, nil)
 
 	if err != nil {
 		return err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	_, err = poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -122,14 +121,14 @@ func StartVirtualMachineScaleSetVm(ctx context.Context, virtualMachineScaleSetNa
 
 // Restarts a virtual machine in a VM scale set
 func RestartVirtualMachineScaleSetVm(ctx context.Context, virtualMachineScaleSetName string, instanceId string) error {
-	client := getVirtualMachineScaleSetVmsClient()
-	poller, err := client.BeginRestart(ctx, config.GroupName(), virtualMachineScaleSetName, instanceId, nil)
+	client := getVirtualMachineScaleSetVmsClient(ctx)
+	poller, err := client.BeginRestart(ctx, config.ScopeFrom(ctx).GroupName, virtualMachineScaleSetName, instanceId, nil)
 
 	if err != nil {
 		return err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	_, err = poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -137,39 +136,39 @@ func RestartVirtualMachineScaleSetVm(ctx context.Context, virtualMachineScaleSet
 }
 
 // Run command on a virtual machine in a VM scale set
-func RunCommandOnVirtualMachineScaleSetVm(ctx context.Context, virtualMachineName string, instanceId string, runCommandInputParameters armcompute.RunCommandInput) error {
-	client := getVirtualMachineScaleSetVmsClient()
+func RunCommandOnVirtualMachineScaleSetVm(ctx context.Context, virtualMachineName string, instanceId string, runCommandInputParameters armcompute.RunCommandInput) (*armcompute.RunCommandResult, error) {
+	client := getVirtualMachineScaleSetVmsClient(ctx)
 	poller, err := client.BeginRunCommand(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		virtualMachineName,
 		instanceId,
 		runCommandInputParameters,
 		nil)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.RunCommandResult, nil
 }
 
 // Deallocates a specific virtual machine in a VM scale set. Shuts down the virtual machine and releases the compute resources it uses.
 // You are not billed for the compute resources of this virtual
 // machine once it is deallocated
 func DeallocateVirtualMachineScaleSetVm(ctx context.Context, virtualMachineScaleSetName string, instanceId string) error {
-	client := getVirtualMachineScaleSetVmsClient()
-	poller, err := client.BeginDeallocate(ctx, config.GroupName(), virtualMachineScaleSetName, instanceId, nil)
+	client := getVirtualMachineScaleSetVmsClient(ctx)
+	poller, err := client.BeginDeallocate(ctx, config.ScopeFrom(ctx).GroupName, virtualMachineScaleSetName, instanceId, nil)
 
 	if err != nil {
 		return err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	_, err = poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -178,14 +177,14 @@ func DeallocateVirtualMachineScaleSetVm(ctx context.Context, virtualMachineScale
 
 // huts down the virtual machine in the virtual machine scale set, moves it to a new node, and powers it back on.
 func RedeployVirtualMachineScaleSetVm(ctx context.Context, virtualMachineScaleSetName string, instanceId string) error {
-	client := getVirtualMachineScaleSetVmsClient()
-	poller, err := client.BeginRedeploy(ctx, config.GroupName(), virtualMachineScaleSetName, instanceId, nil)
+	client := getVirtualMachineScaleSetVmsClient(ctx)
+	poller, err := client.BeginRedeploy(ctx, config.ScopeFrom(ctx).GroupName, virtualMachineScaleSetName, instanceId, nil)
 
 	if err != nil {
 		return err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	_, err = poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -194,14 +193,14 @@ func RedeployVirtualMachineScaleSetVm(ctx context.Context, virtualMachineScaleSe
 
 // Reimages (upgrade the operating system) a specific virtual machine in a VM scale set.
 func VirtualMachineScaleSetVmReimage(ctx context.Context, virtualMachineScaleSetName string, instanceId string) error {
-	client := getVirtualMachineScaleSetVmsClient()
-	poller, err := client.BeginReimage(ctx, config.GroupName(), virtualMachineScaleSetName, instanceId, nil)
+	client := getVirtualMachineScaleSetVmsClient(ctx)
+	poller, err := client.BeginReimage(ctx, config.ScopeFrom(ctx).GroupName, virtualMachineScaleSetName, instanceId, nil)
 
 	if err != nil {
 		return err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	_, err = poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -211,15 +210,15 @@ func VirtualMachineScaleSetVmReimage(ctx context.Context, virtualMachineScaleSet
 // Allows you to re-image all the disks ( including data disks ) in the a VM scale set instance. This operation is only supported for
 // managed disks.
 func ReimageAllVirtualMachineScaleSetVm(ctx context.Context, virtualMachineScaleSetName string, instanceId string) error {
-	client := getVirtualMachineScaleSetVmsClient()
-	poller, err := client.BeginReimageAll(ctx, config.GroupName(),
+	client := getVirtualMachineScaleSetVmsClient(ctx)
+	poller, err := client.BeginReimageAll(ctx, config.ScopeFrom(ctx).GroupName,
 		virtualMachineScaleSetName, instanceId, nil)
 
 	if err != nil {
 		return err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	_, err = poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -228,29 +227,29 @@ func ReimageAllVirtualMachineScaleSetVm(ctx context.Context, virtualMachineScale
 
 // maintenance on a virtual machine in a VM scale set
 func VirtualMachineScaleSetVmPerformMaintenance(ctx context.Context, virtualMachineScaleSetName string, instanceId string) error {
-	client := getVirtualMachineScaleSetVmsClient()
-	poller, err := client.BeginPerformMaintenance(ctx, config.GroupName(),
+	client := getVirtualMachineScaleSetVmsClient(ctx)
+	poller, err := client.BeginPerformMaintenance(ctx, config.ScopeFrom(ctx).GroupName,
 		virtualMachineScaleSetName, instanceId, nil)
 
 	if err != nil {
 		return err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	_, err = poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-//  Deletes a virtual machine from a VM scale set.
+// Deletes a virtual machine from a VM scale set.
 func DeleteVirtualMachineScaleSetVm(ctx context.Context, vmScaleSetName string, instanceId string) error {
-	client := getVirtualMachineScaleSetVmsClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), vmScaleSetName, instanceId, nil)
+	client := getVirtualMachineScaleSetVmsClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, vmScaleSetName, instanceId, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -11,26 +11,26 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/compute/armcompute"
 )
 
-func getVirtualMachineScaleSetVmExtensionsClient() armcompute.VirtualMachineExtensionsClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getVirtualMachineScaleSetVmExtensionsClient(ctx context.Context) armcompute.VirtualMachineExtensionsClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armcompute.NewVirtualMachineExtensionsClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armcompute.NewVirtualMachineExtensionsClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // The operation to create or update the extension
-func CreateVirtualMachineScaleSetVmExtension(ctx context.Context, vmName string, vmExtensionName string, extensionParameters armcompute.VirtualMachineExtension) error {
-	client := getVirtualMachineScaleSetVmExtensionsClient()
+func CreateVirtualMachineScaleSetVmExtension(ctx context.Context, vmName string, vmExtensionName string, extensionParameters armcompute.VirtualMachineExtension) (*armcompute.VirtualMachineExtension, error) {
+	client := getVirtualMachineScaleSetVmExtensionsClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		vmName,
 		vmExtensionName,
 		extensionParameters,
@@ -38,62 +38,66 @@ func CreateVirtualMachineScaleSetVmExtension(ctx context.Context, vmName string,
 	)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VirtualMachineExtension, nil
 }
 
 // get the extension
-func GetVirtualMachineScaleSetVmExtension(ctx context.Context, vmName string, vmExtensionName string) error {
-	client := getVirtualMachineScaleSetVmExtensionsClient()
-	_, err := client.Get(ctx, config.GroupName(), vmName, vmExtensionName, nil)
+func GetVirtualMachineScaleSetVmExtension(ctx context.Context, vmName string, vmExtensionName string) (*armcompute.VirtualMachineExtension, error) {
+	client := getVirtualMachineScaleSetVmExtensionsClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, vmName, vmExtensionName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VirtualMachineExtension, nil
 }
 
 // The operation to get all extensions of a Virtual Machine
-func ListVirtualMachineScaleSetVmExtension(ctx context.Context, vmName string) error {
-	client := getVirtualMachineScaleSetVmExtensionsClient()
-	_, err := client.List(ctx, config.GroupName(), vmName, nil)
+func ListVirtualMachineScaleSetVmExtension(ctx context.Context, vmName string) (*armcompute.VirtualMachineExtensionsListResult, error) {
+	client := getVirtualMachineScaleSetVmExtensionsClient(ctx)
+	resp, err := client.List(ctx, config.ScopeFrom(ctx).GroupName, vmName, nil)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VirtualMachineExtensionsListResult, nil
 }
 
 // The operation to update the extension.
-func UpdateVirtualMachineScaleSetVmExtension(ctx context.Context, vmName string, vmExtensionName string, extensionParameters armcompute.VirtualMachineExtensionUpdate) error {
-	client := getVirtualMachineScaleSetVmExtensionsClient()
-	_, err := client.BeginUpdate(
+func UpdateVirtualMachineScaleSetVmExtension(ctx context.Context, vmName string, vmExtensionName string, extensionParameters armcompute.VirtualMachineExtensionUpdate) (*armcompute.VirtualMachineExtension, error) {
+	client := getVirtualMachineScaleSetVmExtensionsClient(ctx)
+	poller, err := client.BeginUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		vmName,
 		vmExtensionName,
 		extensionParameters,
 		nil,
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
+	if err != nil {
+		return nil, err
+	}
+	return resp.VirtualMachineExtension, nil
 }
 
-//  The operation to delete the extension.
+// The operation to delete the extension.
 func DeleteVirtualMachineScaleSetVmExtension(ctx context.Context, vmName string, vmExtensionName string) error {
-	client := getVirtualMachineScaleSetVmExtensionsClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), vmName, vmExtensionName, nil)
+	client := getVirtualMachineScaleSetVmExtensionsClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, vmName, vmExtensionName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
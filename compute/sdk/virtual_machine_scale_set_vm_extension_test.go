@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	network "github.com/Azure-Samples/azure-sdk-for-go-samples/network/sdk"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/compute/armcompute"
@@ -19,9 +20,9 @@ import (
 )
 
 func TestVirtualMachineScaleSetVmExtension(t *testing.T) {
-	groupName := config.GenerateGroupName("compute")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("compute")
 	virtualMachineScaleSetVmExtensionName := config.AppendRandomSuffix("virtualmachinescalesetvmextension")
 	networkInterfaceName := config.AppendRandomSuffix("networkinterface")
 	virtualNetworkName := config.AppendRandomSuffix("virtualnetwork")
@@ -32,6 +33,7 @@ func TestVirtualMachineScaleSetVmExtension(t *testing.T) {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -165,19 +167,19 @@ func TestVirtualMachineScaleSetVmExtension(t *testing.T) {
 			TypeHandlerVersion:      to.StringPtr("1.4"),
 		},
 	}
-	err = CreateVirtualMachineScaleSetVmExtension(ctx, virtualMachineName, virtualMachineScaleSetVmExtensionName, extensionParameters)
+	_, err = CreateVirtualMachineScaleSetVmExtension(ctx, virtualMachineName, virtualMachineScaleSetVmExtensionName, extensionParameters)
 	if err != nil {
 		t.Fatalf("failed to create virtual machine scale set extension: % +v", err)
 	}
 	t.Logf("created virtual machine scale sets extension")
 
-	err = GetVirtualMachineScaleSetVmExtension(ctx, virtualMachineName, virtualMachineScaleSetVmExtensionName)
+	_, err = GetVirtualMachineScaleSetVmExtension(ctx, virtualMachineName, virtualMachineScaleSetVmExtensionName)
 	if err != nil {
 		t.Fatalf("failed to get virtual machine scale set vm extension: %+v", err)
 	}
 	t.Logf("got virtual machine scale set vm extension")
 
-	err = ListVirtualMachineScaleSetVmExtension(ctx, virtualMachineName)
+	_, err = ListVirtualMachineScaleSetVmExtension(ctx, virtualMachineName)
 	if err != nil {
 		t.Fatalf("failed to list virtual machine scale set vm extension: %+v", err)
 	}
@@ -189,7 +191,7 @@ func TestVirtualMachineScaleSetVmExtension(t *testing.T) {
 			Publisher:               to.StringPtr("Microsoft.Azure.NetworkWatcher"),
 			TypeHandlerVersion:      to.StringPtr("1.4")},
 	}
-	err = UpdateVirtualMachineScaleSetVmExtension(ctx, virtualMachineName, virtualMachineScaleSetVmExtensionName, virtualMachineExtensionUpdateParameters)
+	_, err = UpdateVirtualMachineScaleSetVmExtension(ctx, virtualMachineName, virtualMachineScaleSetVmExtensionName, virtualMachineExtensionUpdateParameters)
 	if err != nil {
 		t.Fatalf("failed to update for virtual machine scale set vm extension: %+v", err)
 	}
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	network "github.com/Azure-Samples/azure-sdk-for-go-samples/network/sdk"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/compute/armcompute"
@@ -20,15 +21,16 @@ import (
 )
 
 func TestVirtualMachineScaleSetVm(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	virtualMachineScaleSetName := config.AppendRandomSuffix("virtualmachinescaleset")
 	virtualNetworkName := config.AppendRandomSuffix("virtualnetwork")
 	subNetName := config.AppendRandomSuffix("subnet")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 6000*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -121,7 +123,7 @@ func TestVirtualMachineScaleSetVm(t *testing.T) {
 			Tier:     to.StringPtr("Standard"),
 		},
 	}
-	err = CreateVirtualMachineScaleSet(ctx, virtualMachineScaleSetName, virtualMachineScaleSetParameters)
+	_, err = CreateVirtualMachineScaleSet(ctx, virtualMachineScaleSetName, virtualMachineScaleSetParameters)
 	if err != nil {
 		t.Fatalf("failed to create virtual machine scale set: % +v", err)
 	}
@@ -129,7 +131,7 @@ func TestVirtualMachineScaleSetVm(t *testing.T) {
 	instanceId := 0
 	for i := 0; i < 4; i++ {
 		instanceId = i
-		err = GetVirtualMachineScaleSetVmInstanceView(ctx, virtualMachineScaleSetName, strconv.Itoa(instanceId))
+		_, err = GetVirtualMachineScaleSetVmInstanceView(ctx, virtualMachineScaleSetName, strconv.Itoa(instanceId))
 		if err != nil {
 			if instanceId >= 3 {
 				t.Fatalf("failed to redeploy a virtual machines in a VM scale set: %+v", err)
@@ -166,13 +168,13 @@ func TestVirtualMachineScaleSetVm(t *testing.T) {
 	}
 	t.Logf("reimaged all the disks ( including data disks ) in a VM scale set instance")
 
-	err = ListVirtualMachineScaleSetVm(ctx, virtualMachineScaleSetName)
+	_, err = ListVirtualMachineScaleSetVm(ctx, virtualMachineScaleSetName)
 	if err != nil {
 		t.Fatalf("failed to list all virtual machine scale set vm: %+v", err)
 	}
 	t.Logf("listed all virtual machine scale set vm")
 
-	err = GetVirtualMachineScaleSetVm(ctx, virtualMachineScaleSetName, strconv.Itoa(instanceId))
+	_, err = GetVirtualMachineScaleSetVm(ctx, virtualMachineScaleSetName, strconv.Itoa(instanceId))
 	if err != nil {
 		t.Fatalf("failed to get a virtual machine from a VM scale set: %+v", err)
 	}
@@ -183,7 +185,7 @@ func TestVirtualMachineScaleSetVm(t *testing.T) {
 			Tags: map[string]*string{"department": to.StringPtr("HR")},
 		},
 	}
-	err = UpdateVirtualMachineScaleSetVm(ctx, virtualMachineScaleSetName, strconv.Itoa(instanceId), virtualMachineScaleSetVMParameters)
+	_, err = UpdateVirtualMachineScaleSetVm(ctx, virtualMachineScaleSetName, strconv.Itoa(instanceId), virtualMachineScaleSetVMParameters)
 	if err != nil {
 		t.Fatalf("failed to update a virtual machine of a VM scale set: %+v", err)
 	}
@@ -210,7 +212,7 @@ func TestVirtualMachineScaleSetVm(t *testing.T) {
 	runCommandInputParameters := armcompute.RunCommandInput{
 		CommandID: to.StringPtr("RunPowerShellScript"),
 	}
-	err = RunCommandOnVirtualMachineScaleSetVm(ctx, virtualMachineScaleSetName, strconv.Itoa(instanceId), runCommandInputParameters)
+	_, err = RunCommandOnVirtualMachineScaleSetVm(ctx, virtualMachineScaleSetName, strconv.Itoa(instanceId), runCommandInputParameters)
 	if err != nil {
 		t.Fatalf("failed to run command on a virtual machine in a VM scale set: %+v", err)
 	}
@@ -10,27 +10,26 @@ import (
 	"log"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
 	"github.com/Azure/azure-sdk-for-go/sdk/compute/armcompute"
 )
 
-func getVirtualMachineSizesClient() armcompute.VirtualMachineSizesClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getVirtualMachineSizesClient(ctx context.Context) armcompute.VirtualMachineSizesClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armcompute.NewVirtualMachineSizesClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armcompute.NewVirtualMachineSizesClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Gets the virtual machine size in a location.
-func ListVirtualMachineSize(ctx context.Context) error {
-	client := getVirtualMachineSizesClient()
-	_, err := client.List(ctx, config.Location(), nil)
+func ListVirtualMachineSize(ctx context.Context) (*armcompute.VirtualMachineSizeListResult, error) {
+	client := getVirtualMachineSizesClient(ctx)
+	resp, err := client.List(ctx, config.ScopeFrom(ctx).Location, nil)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VirtualMachineSizeListResult, nil
 }
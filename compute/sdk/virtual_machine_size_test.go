@@ -11,18 +11,20 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 )
 
 func TestVirtualMachineSize(t *testing.T) {
-	groupName := config.GenerateGroupName("compute")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("compute")
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
-	err := ListVirtualMachineSize(ctx)
+	_, err := ListVirtualMachineSize(ctx)
 	if err != nil {
 		t.Fatalf("failed to list virtual machine size: %+v", err)
 	}
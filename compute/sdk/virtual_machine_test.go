@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	network "github.com/Azure-Samples/azure-sdk-for-go-samples/network/sdk"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	storage "github.com/Azure-Samples/azure-sdk-for-go-samples/storage/sdk"
@@ -18,25 +19,25 @@ import (
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/armstorage"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
-	"github.com/marstr/randname"
 )
 
 func TestVirtualMachine(t *testing.T) {
-	groupName := config.GenerateGroupName("compute")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("compute")
 	networkInterfaceName := config.AppendRandomSuffix("networkinterface")
 	virtualNetworkName := config.AppendRandomSuffix("virtualnetwork")
 	publicIpAddressName := config.AppendRandomSuffix("pipaddress")
 	subnetName := config.AppendRandomSuffix("subnet")
 	virtualMachineName := config.AppendRandomSuffix("vm")
 	ipConfigurationName := config.AppendRandomSuffix("ipconfiguration")
-	storageAccountName := randname.Prefixed{Prefix: "storageaccount", Acceptable: randname.LowercaseAlphabet, Len: 5}.Generate()
-	containerName := randname.Prefixed{Prefix: "blobcontainer", Acceptable: randname.LowercaseAlphabet, Len: 5}.Generate()
+	storageAccountName := config.AppendRandomLowercaseSuffix("storageaccount")
+	containerName := config.AppendRandomLowercaseSuffix("blobcontainer")
 	diskName := config.AppendRandomSuffix("disk")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 1000*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -198,37 +199,40 @@ func TestVirtualMachine(t *testing.T) {
 	// }
 	// t.Logf("reimaged the virtual machine")
 
-	err = InstanceVirtualMachineView(ctx, virtualMachineName)
+	_, err = InstanceVirtualMachineView(ctx, virtualMachineName)
 	if err != nil {
 		t.Fatalf("failed to retrieve information about the run-time state of a virtual machine: %+v", err)
 	}
 	t.Logf("retrieved information about the run-time state of a virtual machine")
 
-	err = ListVirtualMachineAvailableSizes(ctx, virtualMachineName)
+	_, err = ListVirtualMachineAvailableSizes(ctx, virtualMachineName)
 	if err != nil {
 		t.Fatalf("failed to list all available virtual machine sizes: %+v", err)
 	}
 	t.Logf("listed all available virtual machine sizes")
 
-	err = GetVirtualMachine(ctx, virtualMachineName)
+	virtualMachine, err := GetVirtualMachine(ctx, virtualMachineName)
 	if err != nil {
 		t.Fatalf("failed to get virtual machine: %+v", err)
 	}
+	if *virtualMachine.Name != virtualMachineName {
+		t.Fatalf("expected virtual machine %s, got %s", virtualMachineName, *virtualMachine.Name)
+	}
 	t.Logf("got virtual machine")
 
-	err = ListVirtualMachine(ctx)
+	_, err = ListVirtualMachine(ctx)
 	if err != nil {
 		t.Fatalf("failed to list virtual machine: %+v", err)
 	}
 	t.Logf("listed virtual machine")
 
-	err = ListAllVirtualMachine(ctx)
+	_, err = ListAllVirtualMachine(ctx)
 	if err != nil {
 		t.Fatalf("failed to list all virtual machine: %+v", err)
 	}
 	t.Logf("listed all virtual machine")
 
-	err = ListVirtualMachineByLocation(ctx)
+	_, err = ListVirtualMachineByLocation(ctx)
 	if err != nil {
 		t.Fatalf("failed to list virtual machine by location: %+v", err)
 	}
@@ -237,7 +241,7 @@ func TestVirtualMachine(t *testing.T) {
 	runCommandInputParameters := armcompute.RunCommandInput{
 		CommandID: to.StringPtr("RunPowerShellScript"),
 	}
-	err = RunCommandOnVirtualMachine(ctx, virtualMachineName, runCommandInputParameters)
+	_, err = RunCommandOnVirtualMachine(ctx, virtualMachineName, runCommandInputParameters)
 	if err != nil {
 		t.Fatalf("failed to run command on vm: %+v", err)
 	}
@@ -292,7 +296,7 @@ func TestVirtualMachine(t *testing.T) {
 			},
 		},
 	}
-	err = UpdateVirtualMachineTags(ctx, virtualMachineName, virtualMachineUpdateParameters)
+	_, err = UpdateVirtualMachineTags(ctx, virtualMachineName, virtualMachineUpdateParameters)
 	if err != nil {
 		t.Fatalf("failed to update tags for virtual machine: %+v", err)
 	}
@@ -11,28 +11,34 @@ import (
 	"io/ioutil"
 	"log"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/helper/resource"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/network"
 	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-07-01/compute"
 	"github.com/Azure/go-autorest/autorest"
 	"github.com/Azure/go-autorest/autorest/to"
 )
 
-func getVMClient() compute.VirtualMachinesClient {
-	vmClient := compute.NewVirtualMachinesClient(config.SubscriptionID())
+func getVMClient(ctx context.Context) compute.VirtualMachinesClient {
+	vmClient := compute.NewVirtualMachinesClient(config.ScopeFrom(ctx).SubscriptionID)
 	a, _ := iam.GetResourceManagementAuthorizer()
 	vmClient.Authorizer = a
 	vmClient.AddToUserAgent(config.UserAgent())
+	vmClient.Sender = recording.Sender()
 	return vmClient
 }
 
-func getVMExtensionsClient() compute.VirtualMachineExtensionsClient {
-	extClient := compute.NewVirtualMachineExtensionsClient(config.SubscriptionID())
+func getVMExtensionsClient(ctx context.Context) compute.VirtualMachineExtensionsClient {
+	extClient := compute.NewVirtualMachineExtensionsClient(config.ScopeFrom(ctx).SubscriptionID)
 	a, _ := iam.GetResourceManagementAuthorizer()
 	extClient.Authorizer = a
 	extClient.AddToUserAgent(config.UserAgent())
+	extClient.Sender = recording.Sender()
 	return extClient
 }
 
@@ -53,13 +59,13 @@ func CreateVM(ctx context.Context, vmName, nicName, username, password, sshPubli
 		sshKeyData = fakepubkey
 	}
 
-	vmClient := getVMClient()
+	vmClient := getVMClient(ctx)
 	future, err := vmClient.CreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		vmName,
 		compute.VirtualMachine{
-			Location: to.StringPtr(config.Location()),
+			Location: to.StringPtr(config.ScopeFrom(ctx).Location),
 			VirtualMachineProperties: &compute.VirtualMachineProperties{
 				HardwareProfile: &compute.HardwareProfile{
 					VMSize: compute.VirtualMachineSizeTypesBasicA0,
@@ -116,8 +122,8 @@ func CreateVM(ctx context.Context, vmName, nicName, username, password, sshPubli
 
 // GetVM gets the specified VM info
 func GetVM(ctx context.Context, vmName string) (compute.VirtualMachine, error) {
-	vmClient := getVMClient()
-	return vmClient.Get(ctx, config.GroupName(), vmName, compute.InstanceView)
+	vmClient := getVMClient(ctx)
+	return vmClient.Get(ctx, config.ScopeFrom(ctx).GroupName, vmName, compute.InstanceView)
 }
 
 // UpdateVM modifies the VM resource by getting it, updating it locally, and
@@ -134,8 +140,8 @@ func UpdateVM(ctx context.Context, vmName string, tags map[string]*string) (vm c
 	vm.Tags = tags
 
 	// PUT it back
-	vmClient := getVMClient()
-	future, err := vmClient.CreateOrUpdate(ctx, config.GroupName(), vmName, vm)
+	vmClient := getVMClient(ctx)
+	future, err := vmClient.CreateOrUpdate(ctx, config.ScopeFrom(ctx).GroupName, vmName, vm)
 	if err != nil {
 		return vm, fmt.Errorf("cannot update vm: %v", err)
 	}
@@ -150,8 +156,8 @@ func UpdateVM(ctx context.Context, vmName string, tags map[string]*string) (vm c
 
 // DeallocateVM deallocates the selected VM
 func DeallocateVM(ctx context.Context, vmName string) (osr autorest.Response, err error) {
-	vmClient := getVMClient()
-	future, err := vmClient.Deallocate(ctx, config.GroupName(), vmName)
+	vmClient := getVMClient(ctx)
+	future, err := vmClient.Deallocate(ctx, config.ScopeFrom(ctx).GroupName, vmName)
 	if err != nil {
 		return osr, fmt.Errorf("cannot deallocate vm: %v", err)
 	}
@@ -166,8 +172,8 @@ func DeallocateVM(ctx context.Context, vmName string) (osr autorest.Response, er
 
 // StartVM starts the selected VM
 func StartVM(ctx context.Context, vmName string) (osr autorest.Response, err error) {
-	vmClient := getVMClient()
-	future, err := vmClient.Start(ctx, config.GroupName(), vmName)
+	vmClient := getVMClient(ctx)
+	future, err := vmClient.Start(ctx, config.ScopeFrom(ctx).GroupName, vmName)
 	if err != nil {
 		return osr, fmt.Errorf("cannot start vm: %v", err)
 	}
@@ -182,8 +188,8 @@ func StartVM(ctx context.Context, vmName string) (osr autorest.Response, err err
 
 // RestartVM restarts the selected VM
 func RestartVM(ctx context.Context, vmName string) (osr autorest.Response, err error) {
-	vmClient := getVMClient()
-	future, err := vmClient.Restart(ctx, config.GroupName(), vmName)
+	vmClient := getVMClient(ctx)
+	future, err := vmClient.Restart(ctx, config.ScopeFrom(ctx).GroupName, vmName)
 	if err != nil {
 		return osr, fmt.Errorf("cannot restart vm: %v", err)
 	}
@@ -198,9 +204,9 @@ func RestartVM(ctx context.Context, vmName string) (osr autorest.Response, err e
 
 // StopVM stops the selected VM
 func StopVM(ctx context.Context, vmName string) (osr autorest.Response, err error) {
-	vmClient := getVMClient()
+	vmClient := getVMClient(ctx)
 	// skipShutdown parameter is optional, we are taking its default value here
-	future, err := vmClient.PowerOff(ctx, config.GroupName(), vmName, nil)
+	future, err := vmClient.PowerOff(ctx, config.ScopeFrom(ctx).GroupName, vmName, nil)
 	if err != nil {
 		return osr, fmt.Errorf("cannot power off vm: %v", err)
 	}
@@ -212,3 +218,49 @@ func StopVM(ctx context.Context, vmName string) (osr autorest.Response, err erro
 
 	return future.Result(vmClient)
 }
+
+// WaitForVMPowerState waits until the selected VM reports the power state,
+// e.g. "running" or "deallocated", in its instance view.
+func WaitForVMPowerState(ctx context.Context, vmName, powerState string) (vm compute.VirtualMachine, err error) {
+	return waitForVMStatus(ctx, vmName, "PowerState/", powerState)
+}
+
+// WaitForVMProvisioningState waits until the selected VM reports the
+// provisioning state, e.g. "succeeded", in its instance view.
+func WaitForVMProvisioningState(ctx context.Context, vmName, provisioningState string) (vm compute.VirtualMachine, err error) {
+	return waitForVMStatus(ctx, vmName, "ProvisioningState/", provisioningState)
+}
+
+// waitForVMStatus polls the instance view of the VM until the status whose
+// code starts with prefix has the target value. Failed provisioning ends
+// the wait early.
+func waitForVMStatus(ctx context.Context, vmName, prefix, target string) (vm compute.VirtualMachine, err error) {
+	stateConf := &resource.StateChangeConf{
+		Target: []string{target},
+		Refresh: func() (interface{}, string, error) {
+			vm, err := GetVM(ctx, vmName)
+			if err != nil {
+				return nil, "", err
+			}
+			if vm.InstanceView == nil || vm.InstanceView.Statuses == nil {
+				return vm, "", nil
+			}
+			for _, status := range *vm.InstanceView.Statuses {
+				code := to.String(status.Code)
+				if code == "ProvisioningState/failed" && prefix+target != code {
+					return nil, "", fmt.Errorf("vm provisioning failed: %s", to.String(status.Message))
+				}
+				if strings.HasPrefix(code, prefix) {
+					return vm, strings.TrimPrefix(code, prefix), nil
+				}
+			}
+			return vm, "", nil
+		},
+		Backoff: resource.ExponentialBackoff(recording.PollingDelay(5*time.Second), recording.PollingDelay(30*time.Second), 0.2),
+	}
+	res, err := stateConf.WaitForStateContext(ctx)
+	if err != nil {
+		return vm, fmt.Errorf("cannot wait for vm %s%s: %v", prefix, target, err)
+	}
+	return res.(compute.VirtualMachine), nil
+}
@@ -11,23 +11,25 @@ import (
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/network"
 	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-07-01/compute"
 	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/satori/go.uuid"
 )
 
-func getDisksClient() compute.DisksClient {
-	disksClient := compute.NewDisksClient(config.SubscriptionID())
+func getDisksClient(ctx context.Context) compute.DisksClient {
+	disksClient := compute.NewDisksClient(config.ScopeFrom(ctx).SubscriptionID)
 	a, _ := iam.GetResourceManagementAuthorizer()
 	disksClient.Authorizer = a
 	disksClient.AddToUserAgent(config.UserAgent())
+	disksClient.Sender = recording.Sender()
 	return disksClient
 }
 
 func getDisk(ctx context.Context, diskName string) (disk compute.Disk, err error) {
-	disksClient := getDisksClient()
-	return disksClient.Get(ctx, config.GroupName(), diskName)
+	disksClient := getDisksClient(ctx)
+	return disksClient.Get(ctx, config.ScopeFrom(ctx).GroupName, diskName)
 }
 
 // AttachDataDisk attaches a 1GB data disk to the specified VM.
@@ -47,8 +49,8 @@ func AttachDataDisk(ctx context.Context, vmName string) (vm compute.VirtualMachi
 	}}
 
 	// then PUT it back
-	vmClient := getVMClient()
-	future, err := vmClient.CreateOrUpdate(ctx, config.GroupName(), vmName, vm)
+	vmClient := getVMClient(ctx)
+	future, err := vmClient.CreateOrUpdate(ctx, config.ScopeFrom(ctx).GroupName, vmName, vm)
 	if err != nil {
 		return vm, fmt.Errorf("cannot update vm: %v", err)
 	}
@@ -70,8 +72,8 @@ func DetachDataDisks(ctx context.Context, vmName string) (vm compute.VirtualMach
 
 	vm.StorageProfile.DataDisks = &[]compute.DataDisk{}
 
-	vmClient := getVMClient()
-	future, err := vmClient.CreateOrUpdate(ctx, config.GroupName(), vmName, vm)
+	vmClient := getVMClient(ctx)
+	future, err := vmClient.CreateOrUpdate(ctx, config.ScopeFrom(ctx).GroupName, vmName, vm)
 	if err != nil {
 		return vm, fmt.Errorf("cannot update vm: %v", err)
 	}
@@ -105,9 +107,9 @@ func UpdateOSDiskSize(ctx context.Context, vmName string) (d compute.Disk, err e
 		return d, fmt.Errorf("cannot deallocate vm: %v", err)
 	}
 
-	disksClient := getDisksClient()
+	disksClient := getDisksClient(ctx)
 	future, err := disksClient.Update(ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		*vm.StorageProfile.OsDisk.Name,
 		compute.DiskUpdate{
 			DiskUpdateProperties: &compute.DiskUpdateProperties{
@@ -128,13 +130,13 @@ func UpdateOSDiskSize(ctx context.Context, vmName string) (d compute.Disk, err e
 
 // CreateDisk creates an empty 64GB disk which can be attached to a VM.
 func CreateDisk(ctx context.Context, diskName string) (disk compute.Disk, err error) {
-	disksClient := getDisksClient()
+	disksClient := getDisksClient(ctx)
 	future, err := disksClient.CreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		diskName,
 		compute.Disk{
-			Location: to.StringPtr(config.Location()),
+			Location: to.StringPtr(config.ScopeFrom(ctx).Location),
 			DiskProperties: &compute.DiskProperties{
 				CreationData: &compute.CreationData{
 					CreateOption: compute.Empty,
@@ -160,12 +162,12 @@ func CreateVMWithDisk(ctx context.Context, nicName, diskName, vmName, username,
 	nic, _ := network.GetNic(ctx, nicName)
 	disk, _ := getDisk(ctx, diskName)
 
-	vmClient := getVMClient()
+	vmClient := getVMClient(ctx)
 	future, err := vmClient.CreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		vmName, compute.VirtualMachine{
-			Location: to.StringPtr(config.Location()),
+			Location: to.StringPtr(config.ScopeFrom(ctx).Location),
 			VirtualMachineProperties: &compute.VirtualMachineProperties{
 				HardwareProfile: &compute.HardwareProfile{
 					VMSize: compute.VirtualMachineSizeTypesBasicA0,
@@ -226,14 +228,18 @@ func CreateVMWithDisk(ctx context.Context, nicName, diskName, vmName, username,
 // AddDiskEncryptionToVM adds an extension to a VM to enable use of encryption
 // keys from Key Vault to decrypt disks.
 func AddDiskEncryptionToVM(ctx context.Context, vmName, vaultName, keyID string) (ext compute.VirtualMachineExtension, err error) {
-	extensionsClient := getVMExtensionsClient()
+	env, err := config.Environment()
+	if err != nil {
+		return ext, err
+	}
+	extensionsClient := getVMExtensionsClient(ctx)
 	future, err := extensionsClient.CreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		vmName,
 		"AzureDiskEncryptionForLinux",
 		compute.VirtualMachineExtension{
-			Location: to.StringPtr(config.Location()),
+			Location: to.StringPtr(config.ScopeFrom(ctx).Location),
 			VirtualMachineExtensionProperties: &compute.VirtualMachineExtensionProperties{
 				AutoUpgradeMinorVersion: to.BoolPtr(true),
 				ProtectedSettings: &map[string]interface{}{
@@ -246,7 +252,7 @@ func AddDiskEncryptionToVM(ctx context.Context, vmName, vaultName, keyID string)
 					"EncryptionOperation":       "EnableEncryption",
 					"KeyEncryptionAlgorithm":    "RSA-OAEP",
 					"KeyEncryptionKeyAlgorithm": keyID,
-					"KeyVaultURL":               fmt.Sprintf("https://%s.%s/", vaultName, config.Environment().KeyVaultDNSSuffix),
+					"KeyVaultURL":               fmt.Sprintf("https://%s.%s/", vaultName, env.KeyVaultDNSSuffix),
 					"SequenceVersion":           uuid.NewV4().String(),
 					"VolumeType":                "ALL",
 				},
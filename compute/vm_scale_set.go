@@ -14,6 +14,7 @@ import (
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/network"
 	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-07-01/compute"
 	"github.com/Azure/go-autorest/autorest"
@@ -25,6 +26,7 @@ func GetVMSSClient() compute.VirtualMachineScaleSetsClient {
 	a, _ := iam.GetResourceManagementAuthorizer()
 	vmssClient.Authorizer = a
 	vmssClient.AddToUserAgent(config.UserAgent())
+	vmssClient.Sender = recording.Sender()
 	return vmssClient
 }
 
@@ -33,6 +35,7 @@ func GetVMSSExtensionsClient() compute.VirtualMachineScaleSetExtensionsClient {
 	a, _ := iam.GetResourceManagementAuthorizer()
 	extClient.Authorizer = a
 	extClient.AddToUserAgent(config.UserAgent())
+	extClient.Sender = recording.Sender()
 	return extClient
 }
 
@@ -56,7 +59,7 @@ func CreateVMSS(ctx context.Context, vmssName, vnetName, subnetName, username, p
 	vmssClient := GetVMSSClient()
 	future, err := vmssClient.CreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		vmssName,
 		compute.VirtualMachineScaleSet{
 			Location: to.StringPtr(config.DefaultLocation()),
@@ -139,7 +142,7 @@ func CreateVMSS(ctx context.Context, vmssName, vnetName, subnetName, username, p
 // GetVMSS gets the specified VMSS info
 func GetVMSS(ctx context.Context, vmssName string) (compute.VirtualMachineScaleSet, error) {
 	vmssClient := GetVMSSClient()
-	return vmssClient.Get(ctx, config.GroupName(), vmssName)
+	return vmssClient.Get(ctx, config.ScopeFrom(ctx).GroupName, vmssName)
 }
 
 // UpdateVMSS modifies the VMSS resource by getting it, updating it locally, and
@@ -156,7 +159,7 @@ func UpdateVMSS(ctx context.Context, vmssName string, tags map[string]*string) (
 
 	// PUT it back
 	vmssClient := GetVMSSClient()
-	future, err := vmssClient.CreateOrUpdate(ctx, config.GroupName(), vmssName, vmss)
+	future, err := vmssClient.CreateOrUpdate(ctx, config.ScopeFrom(ctx).GroupName, vmssName, vmss)
 	if err != nil {
 		return vmss, fmt.Errorf("cannot update vmss: %v", err)
 	}
@@ -173,7 +176,7 @@ func UpdateVMSS(ctx context.Context, vmssName string, tags map[string]*string) (
 func DeallocateVMSS(ctx context.Context, vmssName string) (osr autorest.Response, err error) {
 	vmssClient := GetVMSSClient()
 	// passing nil instance ids will deallocate all VMs in the VMSS
-	future, err := vmssClient.Deallocate(ctx, config.GroupName(), vmssName, nil)
+	future, err := vmssClient.Deallocate(ctx, config.ScopeFrom(ctx).GroupName, vmssName, nil)
 	if err != nil {
 		return osr, fmt.Errorf("cannot deallocate vmss: %v", err)
 	}
@@ -190,7 +193,7 @@ func DeallocateVMSS(ctx context.Context, vmssName string) (osr autorest.Response
 func StartVMSS(ctx context.Context, vmssName string) (osr autorest.Response, err error) {
 	vmssClient := GetVMSSClient()
 	// passing nil instance ids will start all VMs in the VMSS
-	future, err := vmssClient.Start(ctx, config.GroupName(), vmssName, nil)
+	future, err := vmssClient.Start(ctx, config.ScopeFrom(ctx).GroupName, vmssName, nil)
 	if err != nil {
 		return osr, fmt.Errorf("cannot start vmss: %v", err)
 	}
@@ -207,7 +210,7 @@ func StartVMSS(ctx context.Context, vmssName string) (osr autorest.Response, err
 func RestartVMSS(ctx context.Context, vmssName string) (osr autorest.Response, err error) {
 	vmssClient := GetVMSSClient()
 	// passing nil instance ids will restart all VMs in the VMSS
-	future, err := vmssClient.Restart(ctx, config.GroupName(), vmssName, nil)
+	future, err := vmssClient.Restart(ctx, config.ScopeFrom(ctx).GroupName, vmssName, nil)
 	if err != nil {
 		return osr, fmt.Errorf("cannot restart vm: %v", err)
 	}
@@ -224,7 +227,7 @@ func RestartVMSS(ctx context.Context, vmssName string) (osr autorest.Response, e
 func StopVMSS(ctx context.Context, vmssName string) (osr autorest.Response, err error) {
 	vmssClient := GetVMSSClient()
 	// passing nil instance ids will stop all VMs in the VMSS
-	future, err := vmssClient.PowerOff(ctx, config.GroupName(), vmssName, nil, nil)
+	future, err := vmssClient.PowerOff(ctx, config.ScopeFrom(ctx).GroupName, vmssName, nil, nil)
 	if err != nil {
 		return osr, fmt.Errorf("cannot power off vmss: %v", err)
 	}
@@ -84,6 +84,10 @@ func Example_createVM() {
 	if err != nil {
 		util.LogAndPanic(err)
 	}
+	_, err = WaitForVMPowerState(ctx, vmName, "running")
+	if err != nil {
+		util.LogAndPanic(err)
+	}
 	util.PrintAndLog("started VM")
 
 	_, err = RestartVM(ctx, vmName)
@@ -96,6 +100,10 @@ func Example_createVM() {
 	if err != nil {
 		util.LogAndPanic(err)
 	}
+	_, err = WaitForVMPowerState(ctx, vmName, "stopped")
+	if err != nil {
+		util.LogAndPanic(err)
+	}
 	util.PrintAndLog("stopped VM")
 
 	// Output:
@@ -495,8 +503,9 @@ func Example_createVMWithUserAssignedIdentity() {
 func Example_list() {
 	// list the VMs we've created in our resource group by page.
 	// uses the default page size returned by the service.
-	vmClient := getVMClient()
-	for page, err := vmClient.List(context.Background(), config.GroupName()); page.NotDone(); err = page.Next() {
+	ctx := context.Background()
+	vmClient := getVMClient(ctx)
+	for page, err := vmClient.List(ctx, config.GroupName()); page.NotDone(); err = page.Next() {
 		if err != nil {
 			util.LogAndPanic(err)
 		}
@@ -508,9 +517,10 @@ func Example_list() {
 }
 
 func Example_listComplete() {
+	ctx := context.Background()
 	// list the VMs we've created in our resource group using an iterator.
-	vmClient := getVMClient()
-	for iter, err := vmClient.ListComplete(context.Background(), config.GroupName()); iter.NotDone(); err = iter.Next() {
+	vmClient := getVMClient(ctx)
+	for iter, err := vmClient.ListComplete(ctx, config.GroupName()); iter.NotDone(); err = iter.Next() {
 		if err != nil {
 			util.LogAndPanic(err)
 		}
@@ -519,9 +529,10 @@ func Example_listComplete() {
 }
 
 func Example_get() {
+	ctx := context.Background()
 	// retrieve information about a specific VM
-	vmClient := getVMClient()
-	vm, err := vmClient.Get(context.Background(), config.GroupName(), vmName, compute.InstanceView)
+	vmClient := getVMClient(ctx)
+	vm, err := vmClient.Get(ctx, config.GroupName(), vmName, compute.InstanceView)
 	if err != nil {
 		util.LogAndPanic(err)
 	}
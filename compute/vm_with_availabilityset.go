@@ -11,27 +11,29 @@ import (
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/network"
 	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-07-01/compute"
 	"github.com/Azure/go-autorest/autorest/to"
 )
 
-func getAvailabilitySetsClient() compute.AvailabilitySetsClient {
-	asClient := compute.NewAvailabilitySetsClient(config.SubscriptionID())
+func getAvailabilitySetsClient(ctx context.Context) compute.AvailabilitySetsClient {
+	asClient := compute.NewAvailabilitySetsClient(config.ScopeFrom(ctx).SubscriptionID)
 	a, _ := iam.GetResourceManagementAuthorizer()
 	asClient.Authorizer = a
 	asClient.AddToUserAgent(config.UserAgent())
+	asClient.Sender = recording.Sender()
 	return asClient
 }
 
 // CreateAvailabilitySet creates an availability set
 func CreateAvailabilitySet(ctx context.Context, asName string) (compute.AvailabilitySet, error) {
-	asClient := getAvailabilitySetsClient()
+	asClient := getAvailabilitySetsClient(ctx)
 	return asClient.CreateOrUpdate(ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		asName,
 		compute.AvailabilitySet{
-			Location: to.StringPtr(config.Location()),
+			Location: to.StringPtr(config.ScopeFrom(ctx).Location),
 			AvailabilitySetProperties: &compute.AvailabilitySetProperties{
 				PlatformFaultDomainCount:  to.Int32Ptr(1),
 				PlatformUpdateDomainCount: to.Int32Ptr(1),
@@ -44,8 +46,8 @@ func CreateAvailabilitySet(ctx context.Context, asName string) (compute.Availabi
 
 // GetAvailabilitySet gets info on an availability set
 func GetAvailabilitySet(ctx context.Context, asName string) (compute.AvailabilitySet, error) {
-	asClient := getAvailabilitySetsClient()
-	return asClient.Get(ctx, config.GroupName(), asName)
+	asClient := getAvailabilitySetsClient(ctx)
+	return asClient.Get(ctx, config.ScopeFrom(ctx).GroupName, asName)
 }
 
 // CreateVMWithLoadBalancer creates a new VM in an availability set. It also
@@ -68,13 +70,13 @@ func CreateVMWithLoadBalancer(ctx context.Context, vmName, lbName, vnetName, sub
 		return
 	}
 
-	vmClient := getVMClient()
+	vmClient := getVMClient(ctx)
 	future, err := vmClient.CreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		vmName,
 		compute.VirtualMachine{
-			Location: to.StringPtr(config.Location()),
+			Location: to.StringPtr(config.ScopeFrom(ctx).Location),
 			VirtualMachineProperties: &compute.VirtualMachineProperties{
 				HardwareProfile: &compute.HardwareProfile{
 					VMSize: compute.VirtualMachineSizeTypesStandardA0,
@@ -21,13 +21,13 @@ import (
 func CreateVMWithMSI(ctx context.Context, vmName, nicName, username, password string) (vm compute.VirtualMachine, err error) {
 	nic, _ := network.GetNic(ctx, nicName)
 
-	vmClient := getVMClient()
+	vmClient := getVMClient(ctx)
 	future, err := vmClient.CreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		vmName,
 		compute.VirtualMachine{
-			Location: to.StringPtr(config.Location()),
+			Location: to.StringPtr(config.ScopeFrom(ctx).Location),
 			Identity: &compute.VirtualMachineIdentity{
 				Type: compute.ResourceIdentityTypeSystemAssigned,
 			},
@@ -76,15 +76,15 @@ func CreateVMWithMSI(ctx context.Context, vmName, nicName, username, password st
 // AddIdentityToVM adds a managed identity to an existing VM by activating the
 // corresponding VM extension.
 func AddIdentityToVM(ctx context.Context, vmName string) (ext compute.VirtualMachineExtension, err error) {
-	extensionsClient := getVMExtensionsClient()
+	extensionsClient := getVMExtensionsClient(ctx)
 
 	future, err := extensionsClient.CreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		vmName,
 		"msiextension",
 		compute.VirtualMachineExtension{
-			Location: to.StringPtr(config.Location()),
+			Location: to.StringPtr(config.ScopeFrom(ctx).Location),
 			VirtualMachineExtensionProperties: &compute.VirtualMachineExtensionProperties{
 				Publisher:               to.StringPtr("Microsoft.ManagedIdentity"),
 				Type:                    to.StringPtr("ManagedIdentityExtensionForLinux"),
@@ -110,13 +110,13 @@ func AddIdentityToVM(ctx context.Context, vmName string) (ext compute.VirtualMac
 // CreateVMWithUserAssignedID creates a virtual machine with a user-assigned identity.
 func CreateVMWithUserAssignedID(ctx context.Context, vmName, nicName, username, password string, id msi.Identity) (vm compute.VirtualMachine, err error) {
 	nic, _ := network.GetNic(ctx, nicName)
-	vmClient := getVMClient()
+	vmClient := getVMClient(ctx)
 	future, err := vmClient.CreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		vmName,
 		compute.VirtualMachine{
-			Location: to.StringPtr(config.Location()),
+			Location: to.StringPtr(config.ScopeFrom(ctx).Location),
 			Identity: &compute.VirtualMachineIdentity{
 				Type: compute.ResourceIdentityTypeUserAssigned,
 				UserAssignedIdentities: map[string]*compute.VirtualMachineIdentityUserAssignedIdentitiesValue{
@@ -165,10 +165,10 @@ func CreateVMWithUserAssignedID(ctx context.Context, vmName, nicName, username,
 
 // AddUserAssignedIDToVM adds the specified user-assigned identity to the specified pre-existing VM.
 func AddUserAssignedIDToVM(ctx context.Context, vmName string, id msi.Identity) (*compute.VirtualMachine, error) {
-	vmClient := getVMClient()
+	vmClient := getVMClient(ctx)
 	future, err := vmClient.Update(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		vmName,
 		compute.VirtualMachineUpdate{
 			Identity: &compute.VirtualMachineIdentity{
@@ -192,10 +192,10 @@ func AddUserAssignedIDToVM(ctx context.Context, vmName string, id msi.Identity)
 
 // RemoveUserAssignedIDFromVM removes the specified user-assigned identity from the specified pre-existing VM.
 func RemoveUserAssignedIDFromVM(ctx context.Context, vmName string, id msi.Identity) (*compute.VirtualMachine, error) {
-	vmClient := getVMClient()
+	vmClient := getVMClient(ctx)
 	future, err := vmClient.Update(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		vmName,
 		compute.VirtualMachineUpdate{
 			Identity: &compute.VirtualMachineIdentity{
@@ -11,34 +11,36 @@ import (
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/services/cosmos-db/mgmt/2015-04-08/documentdb"
 	"github.com/Azure/go-autorest/autorest/to"
 )
 
-func getDatabaseAccountClient() documentdb.DatabaseAccountsClient {
-	dbAccountClient := documentdb.NewDatabaseAccountsClient(config.SubscriptionID())
+func getDatabaseAccountClient(ctx context.Context) documentdb.DatabaseAccountsClient {
+	dbAccountClient := documentdb.NewDatabaseAccountsClient(config.ScopeFrom(ctx).SubscriptionID)
 	auth, _ := iam.GetResourceManagementAuthorizer()
 	dbAccountClient.Authorizer = auth
 	dbAccountClient.AddToUserAgent(config.UserAgent())
+	dbAccountClient.Sender = recording.Sender()
 	return dbAccountClient
 }
 
 // CreateDatabaseAccount creates or updates an Azure Cosmos DB database account.
 func CreateDatabaseAccount(ctx context.Context, accountName string) (dba documentdb.DatabaseAccount, err error) {
-	dbAccountClient := getDatabaseAccountClient()
+	dbAccountClient := getDatabaseAccountClient(ctx)
 	future, err := dbAccountClient.CreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		accountName,
 		documentdb.DatabaseAccountCreateUpdateParameters{
-			Location: to.StringPtr(config.Location()),
+			Location: to.StringPtr(config.ScopeFrom(ctx).Location),
 			Kind:     documentdb.GlobalDocumentDB,
 			DatabaseAccountCreateUpdateProperties: &documentdb.DatabaseAccountCreateUpdateProperties{
 				DatabaseAccountOfferType: to.StringPtr("Standard"),
 				Locations: &[]documentdb.Location{
 					{
 						FailoverPriority: to.Int32Ptr(0),
-						LocationName:     to.StringPtr(config.Location()),
+						LocationName:     to.StringPtr(config.ScopeFrom(ctx).Location),
 					},
 				},
 			},
@@ -57,6 +59,6 @@ func CreateDatabaseAccount(ctx context.Context, accountName string) (dba documen
 
 // ListKeys gets the keys for a Azure Cosmos DB database account.
 func ListKeys(ctx context.Context, accountName string) (documentdb.DatabaseAccountListKeysResult, error) {
-	dbAccountClient := getDatabaseAccountClient()
-	return dbAccountClient.ListKeys(ctx, config.GroupName(), accountName)
+	dbAccountClient := getDatabaseAccountClient(ctx)
+	return dbAccountClient.ListKeys(ctx, config.ScopeFrom(ctx).GroupName, accountName)
 }
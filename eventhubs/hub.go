@@ -10,24 +10,26 @@ import (
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/services/eventhub/mgmt/2017-04-01/eventhub"
 	"github.com/Azure/go-autorest/autorest/to"
 )
 
-func getHubsClient() eventhub.EventHubsClient {
-	hubClient := eventhub.NewEventHubsClient(config.SubscriptionID())
+func getHubsClient(ctx context.Context) eventhub.EventHubsClient {
+	hubClient := eventhub.NewEventHubsClient(config.ScopeFrom(ctx).SubscriptionID)
 	auth, _ := iam.GetResourceManagementAuthorizer()
 	hubClient.Authorizer = auth
 	hubClient.AddToUserAgent(config.UserAgent())
+	hubClient.Sender = recording.Sender()
 	return hubClient
 }
 
 // CreateHub creates an Event Hubs hub in a namespace
 func CreateHub(ctx context.Context, nsName string, hubName string) (eventhub.Model, error) {
-	hubClient := getHubsClient()
+	hubClient := getHubsClient(ctx)
 	return hubClient.CreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		nsName,
 		hubName,
 		eventhub.Model{
@@ -10,27 +10,29 @@ import (
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/services/eventhub/mgmt/2017-04-01/eventhub"
 	"github.com/Azure/go-autorest/autorest/to"
 )
 
-func getNamespacesClient() eventhub.NamespacesClient {
-	nsClient := eventhub.NewNamespacesClient(config.SubscriptionID())
+func getNamespacesClient(ctx context.Context) eventhub.NamespacesClient {
+	nsClient := eventhub.NewNamespacesClient(config.ScopeFrom(ctx).SubscriptionID)
 	auth, _ := iam.GetResourceManagementAuthorizer()
 	nsClient.Authorizer = auth
 	nsClient.AddToUserAgent(config.UserAgent())
+	nsClient.Sender = recording.Sender()
 	return nsClient
 }
 
 // CreateNamespace creates an Event Hubs namespace
 func CreateNamespace(ctx context.Context, nsName string) (*eventhub.EHNamespace, error) {
-	nsClient := getNamespacesClient()
+	nsClient := getNamespacesClient(ctx)
 	future, err := nsClient.CreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		nsName,
 		eventhub.EHNamespace{
-			Location: to.StringPtr(config.Location()),
+			Location: to.StringPtr(config.ScopeFrom(ctx).Location),
 		},
 	)
 	if err != nil {
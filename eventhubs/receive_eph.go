@@ -27,12 +27,12 @@ func ReceiveViaEPH(ctx context.Context, nsName, hubName, storageAccountName, sto
 
 	// create a storage account and container to maintain dictionary of leases
 	// and checkpoints
-	_, err = storage.CreateStorageAccount(ctx, storageAccountName, config.GroupName())
+	_, err = storage.CreateStorageAccount(ctx, storageAccountName, config.ScopeFrom(ctx).GroupName)
 	if err != nil {
 		log.Fatalf("could not create storage account: %s\n", err)
 	}
 	log.Printf("creating storage container\n")
-	_, err = storage.CreateContainer(ctx, storageAccountName, config.GroupName(), storageContainerName)
+	_, err = storage.CreateContainer(ctx, storageAccountName, config.ScopeFrom(ctx).GroupName, storageContainerName)
 	if err != nil {
 		log.Fatalf("could not create storage container: %s\n", err)
 	}
@@ -40,7 +40,7 @@ func ReceiveViaEPH(ctx context.Context, nsName, hubName, storageAccountName, sto
 	// use helper method to exchange AAD credentials for SAS token
 	cred, err := eventhubsstorage.NewAADSASCredential(
 		config.SubscriptionID(),
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		storageAccountName,
 		storageContainerName,
 		eventhubsstorage.AADSASCredentialWithEnvironmentVars())
@@ -6,9 +6,10 @@ require (
 	cloud.google.com/go v0.39.0 // indirect
 	github.com/Azure/azure-amqp-common-go v1.1.4
 	github.com/Azure/azure-event-hubs-go v1.3.0
-	github.com/Azure/azure-pipeline-go v0.1.9 // indirect
+	github.com/Azure/azure-pipeline-go v0.1.9
 	github.com/Azure/azure-sdk-for-go v54.3.0+incompatible
 	github.com/Azure/azure-sdk-for-go/sdk/armcore v0.7.1
+	github.com/Azure/azure-sdk-for-go/sdk/azcore v0.16.1
 	github.com/Azure/azure-sdk-for-go/sdk/azidentity v0.9.1
 	github.com/Azure/azure-sdk-for-go/sdk/compute/armcompute v0.1.0
 	github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork v0.1.0
@@ -29,4 +30,5 @@ require (
 	github.com/marstr/randname v0.0.0-20181206212954-d5b0f288ab8c
 	github.com/pkg/errors v0.8.1
 	github.com/satori/go.uuid v1.2.0
+	gopkg.in/yaml.v2 v2.4.0
 )
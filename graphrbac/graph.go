@@ -7,6 +7,7 @@ import (
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/services/graphrbac/1.6/graphrbac"
 	"github.com/Azure/go-autorest/autorest"
 	"github.com/Azure/go-autorest/autorest/date"
@@ -19,6 +20,7 @@ func getServicePrincipalsClient() graphrbac.ServicePrincipalsClient {
 	a, _ := iam.GetGraphAuthorizer()
 	spClient.Authorizer = a
 	spClient.AddToUserAgent(config.UserAgent())
+	spClient.Sender = recording.Sender()
 	return spClient
 }
 
@@ -27,6 +29,7 @@ func getApplicationsClient() graphrbac.ApplicationsClient {
 	a, _ := iam.GetGraphAuthorizer()
 	appClient.Authorizer = a
 	appClient.AddToUserAgent(config.UserAgent())
+	appClient.Sender = recording.Sender()
 	return appClient
 }
 
@@ -36,6 +39,7 @@ func getADGroupsClient() graphrbac.GroupsClient {
 	a, _ := iam.GetGraphAuthorizer()
 	groupsClient.Authorizer = a
 	groupsClient.AddToUserAgent(config.UserAgent())
+	groupsClient.Sender = recording.Sender()
 	return groupsClient
 }
 
@@ -89,6 +93,7 @@ func getSignedInUserClient() graphrbac.SignedInUserClient {
 	a, _ := iam.GetGraphAuthorizer()
 	signedInUserClient.Authorizer = a
 	signedInUserClient.AddToUserAgent(config.UserAgent())
+	signedInUserClient.Sender = recording.Sender()
 	return signedInUserClient
 }
 
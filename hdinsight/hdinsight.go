@@ -11,6 +11,7 @@ import (
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/util"
 	"github.com/Azure/azure-sdk-for-go/services/preview/hdinsight/mgmt/2015-03-01-preview/hdinsight"
 	"github.com/Azure/go-autorest/autorest/to"
@@ -25,6 +26,7 @@ func getClustersClient() (*hdinsight.ClustersClient, error) {
 	client := hdinsight.NewClustersClient(config.SubscriptionID())
 	client.Authorizer = a
 	client.AddToUserAgent(config.UserAgent())
+	client.Sender = recording.Sender()
 	return &client, nil
 }
 
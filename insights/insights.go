@@ -13,6 +13,7 @@ import (
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/services/preview/monitor/mgmt/2018-03-01/insights"
 )
 
@@ -25,6 +26,7 @@ func ListMetricDefinitions(resourceURI string) ([]string, error) {
 	metricsDefClient := insights.NewMetricDefinitionsClient(config.SubscriptionID())
 	metricsDefClient.Authorizer = a
 	metricsDefClient.AddToUserAgent(config.UserAgent())
+	metricsDefClient.Sender = recording.Sender()
 	result, err := metricsDefClient.List(context.Background(), resourceURI, "")
 	if err != nil {
 		return nil, err
@@ -45,6 +47,7 @@ func GetMetricsData(ctx context.Context, resourceID string, metrics []string) ([
 	metricsClient := insights.NewMetricsClient(config.SubscriptionID())
 	metricsClient.Authorizer = a
 	metricsClient.AddToUserAgent(config.UserAgent())
+	metricsClient.Sender = recording.Sender()
 
 	endTime := time.Now().UTC()
 	startTime := endTime.Add(time.Duration(-5) * time.Minute)
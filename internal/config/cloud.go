@@ -0,0 +1,248 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest/azure"
+)
+
+var (
+	// environmentMu guards environment, which is resolved from the cloud
+	// settings on first use.
+	environmentMu sync.Mutex
+
+	// metadataClient fetches cloud metadata, it isn't recorded because the
+	// endpoints are needed before any test starts.
+	metadataClient = &http.Client{Timeout: 30 * time.Second}
+)
+
+// Environment returns the endpoints of the configured cloud. It's resolved
+// once, in order of preference, from the JSON file at AZURE_CLOUD_FILE
+// (`-cloudFile`), from the metadata endpoint of the Resource Manager at
+// AZURE_ARM_ENDPOINT (`-armEndpoint`), or from the well-known cloud named by
+// AZURE_ENVIRONMENT (`-cloud`), the public cloud by default.
+func Environment() (*azure.Environment, error) {
+	environmentMu.Lock()
+	defer environmentMu.Unlock()
+
+	if environment != nil {
+		return environment, nil
+	}
+	env, err := loadEnvironment(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	environment = &env
+	return environment, nil
+}
+
+// SetEnvironment replaces the cloud returned by Environment, e.g. with one
+// built by the caller. nil resolves the cloud from the settings again.
+func SetEnvironment(env *azure.Environment) {
+	environmentMu.Lock()
+	defer environmentMu.Unlock()
+	environment = env
+}
+
+func loadEnvironment(ctx context.Context) (azure.Environment, error) {
+	switch {
+	case cloudFile != "":
+		return EnvironmentFromFile(cloudFile, armEndpoint)
+	case armEndpoint != "":
+		return EnvironmentFromMetadata(ctx, armEndpoint)
+	default:
+		env, err := azure.EnvironmentFromName(cloudName)
+		if err != nil {
+			return env, fmt.Errorf("unknown cloud %q, set AZURE_ARM_ENDPOINT or AZURE_CLOUD_FILE for other clouds: %v", cloudName, err)
+		}
+		return env, nil
+	}
+}
+
+// cloudMetadata is the response of a Resource Manager's metadata endpoint.
+// Azure Stack answers with api-version 1.0, which uses the *Endpoint names,
+// while newer api-versions also list the resource manager and DNS suffixes.
+type cloudMetadata struct {
+	Name            string `json:"name"`
+	ResourceManager string `json:"resourceManager"`
+	Portal          string `json:"portal"`
+	PortalEndpoint  string `json:"portalEndpoint"`
+	Gallery         string `json:"gallery"`
+	GalleryEndpoint string `json:"galleryEndpoint"`
+	Graph           string `json:"graph"`
+	GraphEndpoint   string `json:"graphEndpoint"`
+	Batch           string `json:"batch"`
+	Authentication  struct {
+		LoginEndpoint string   `json:"loginEndpoint"`
+		Audiences     []string `json:"audiences"`
+	} `json:"authentication"`
+	Suffixes struct {
+		Storage           string `json:"storage"`
+		KeyVaultDNS       string `json:"keyVaultDns"`
+		SQLServerHostname string `json:"sqlServerHostname"`
+		AcrLoginServer    string `json:"acrLoginServer"`
+	} `json:"suffixes"`
+}
+
+// EnvironmentFromMetadata loads the endpoints of a cloud, such as Azure Stack
+// or a sovereign cloud, from the `/metadata/endpoints` resource of its
+// Resource Manager endpoint.
+func EnvironmentFromMetadata(ctx context.Context, resourceManagerEndpoint string) (azure.Environment, error) {
+	u, err := url.Parse(resourceManagerEndpoint)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return azure.Environment{}, fmt.Errorf("invalid resource manager endpoint %q", resourceManagerEndpoint)
+	}
+
+	var lastErr error
+	for _, apiVersion := range []string{"2019-05-01", "1.0"} {
+		metadataURL := strings.TrimSuffix(resourceManagerEndpoint, "/") + "/metadata/endpoints?api-version=" + apiVersion
+		data, err := fetchMetadata(ctx, metadataURL)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		metadata, err := parseMetadata(data, resourceManagerEndpoint)
+		if err != nil {
+			lastErr = fmt.Errorf("cannot parse cloud metadata from %s: %v", metadataURL, err)
+			continue
+		}
+		return metadata.environment(resourceManagerEndpoint)
+	}
+	return azure.Environment{}, lastErr
+}
+
+// parseMetadata reads a `/metadata/endpoints` response. Version 1.0 returns
+// the cloud's own metadata, while public and sovereign clouds answer
+// 2019-05-01 with an array of every cloud, of which the one with
+// resourceManagerEndpoint is picked.
+func parseMetadata(data []byte, resourceManagerEndpoint string) (cloudMetadata, error) {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) == 0 || trimmed[0] != '[' {
+		var metadata cloudMetadata
+		err := json.Unmarshal(data, &metadata)
+		return metadata, err
+	}
+
+	var clouds []cloudMetadata
+	if err := json.Unmarshal(data, &clouds); err != nil {
+		return cloudMetadata{}, err
+	}
+	for _, cloud := range clouds {
+		if resourceManagerEndpoint != "" && strings.EqualFold(withSlash(cloud.ResourceManager), withSlash(resourceManagerEndpoint)) {
+			return cloud, nil
+		}
+	}
+	return cloudMetadata{}, fmt.Errorf("no cloud with resource manager endpoint %q among %d clouds", resourceManagerEndpoint, len(clouds))
+}
+
+func fetchMetadata(ctx context.Context, metadataURL string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, metadataURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := metadataClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("cannot get cloud metadata: %v", err)
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("cannot get cloud metadata from %s: %s", metadataURL, resp.Status)
+	}
+	return data, nil
+}
+
+// EnvironmentFromFile loads the endpoints of a cloud from a JSON file, for
+// clouds whose metadata endpoint can't be reached. The file holds either a
+// saved `/metadata/endpoints` response, in which case resourceManagerEndpoint
+// is used when the response doesn't name it, or an `azure.Environment`.
+func EnvironmentFromFile(path, resourceManagerEndpoint string) (azure.Environment, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return azure.Environment{}, err
+	}
+	metadata, err := parseMetadata(data, resourceManagerEndpoint)
+	if err != nil {
+		return azure.Environment{}, fmt.Errorf("cannot parse cloud file %s: %v", path, err)
+	}
+	if metadata.Authentication.LoginEndpoint != "" {
+		return metadata.environment(resourceManagerEndpoint)
+	}
+
+	var env azure.Environment
+	if err := json.Unmarshal(data, &env); err != nil {
+		return env, fmt.Errorf("cannot parse cloud file %s: %v", path, err)
+	}
+	if env.ResourceManagerEndpoint == "" || env.ActiveDirectoryEndpoint == "" {
+		return env, fmt.Errorf("cloud file %s must set at least resourceManagerEndpoint and activeDirectoryEndpoint", path)
+	}
+	if env.TokenAudience == "" {
+		env.TokenAudience = env.ResourceManagerEndpoint
+	}
+	return env, nil
+}
+
+func (m cloudMetadata) environment(resourceManagerEndpoint string) (azure.Environment, error) {
+	armEndpoint := withSlash(firstOf(m.ResourceManager, resourceManagerEndpoint))
+	if armEndpoint == "" {
+		return azure.Environment{}, fmt.Errorf("cloud metadata doesn't name the resource manager endpoint")
+	}
+	if m.Authentication.LoginEndpoint == "" || len(m.Authentication.Audiences) == 0 {
+		return azure.Environment{}, fmt.Errorf("cloud metadata for %s has no authentication endpoints", armEndpoint)
+	}
+
+	// Azure Stack doesn't list its DNS suffixes, they follow the domain of
+	// the resource manager, e.g. management.local.azurestack.external
+	u, err := url.Parse(armEndpoint)
+	if err != nil {
+		return azure.Environment{}, err
+	}
+	stampSuffix := u.Hostname()
+	if i := strings.Index(stampSuffix, "."); i >= 0 {
+		stampSuffix = stampSuffix[i+1:]
+	}
+	keyVaultSuffix := strings.TrimPrefix(firstOf(m.Suffixes.KeyVaultDNS, "vault."+stampSuffix), ".")
+
+	return azure.Environment{
+		Name:                       firstOf(m.Name, "HybridEnvironment"),
+		ManagementPortalURL:        withSlash(firstOf(m.Portal, m.PortalEndpoint)),
+		ResourceManagerEndpoint:    armEndpoint,
+		ActiveDirectoryEndpoint:    withSlash(m.Authentication.LoginEndpoint),
+		GalleryEndpoint:            withSlash(firstOf(m.Gallery, m.GalleryEndpoint)),
+		GraphEndpoint:              withSlash(firstOf(m.Graph, m.GraphEndpoint)),
+		BatchManagementEndpoint:    withSlash(m.Batch),
+		KeyVaultEndpoint:           "https://" + keyVaultSuffix + "/",
+		KeyVaultDNSSuffix:          keyVaultSuffix,
+		StorageEndpointSuffix:      firstOf(m.Suffixes.Storage, stampSuffix),
+		SQLDatabaseDNSSuffix:       strings.TrimPrefix(m.Suffixes.SQLServerHostname, "."),
+		ContainerRegistryDNSSuffix: strings.TrimPrefix(m.Suffixes.AcrLoginServer, "."),
+		TokenAudience:              m.Authentication.Audiences[0],
+	}, nil
+}
+
+func firstOf(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func withSlash(endpoint string) string {
+	if endpoint == "" || strings.HasSuffix(endpoint, "/") {
+		return endpoint
+	}
+	return endpoint + "/"
+}
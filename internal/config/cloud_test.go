@@ -0,0 +1,165 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const stackMetadata = `{
+	"galleryEndpoint": "https://adminportal.local.azurestack.external:30015/",
+	"graphEndpoint": "https://graph.windows.net/",
+	"portalEndpoint": "https://portal.local.azurestack.external/",
+	"authentication": {
+		"loginEndpoint": "https://login.microsoftonline.com/",
+		"audiences": ["https://management.contoso.onmicrosoft.com/4de154de-f8a8-4017-af41-df619da68155"]
+	}
+}`
+
+func TestEnvironmentFromMetadata(t *testing.T) {
+	var apiVersions []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiVersion := r.URL.Query().Get("api-version")
+		apiVersions = append(apiVersions, apiVersion)
+		if r.URL.Path != "/metadata/endpoints" || apiVersion != "1.0" {
+			http.Error(w, "unsupported api-version", http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(stackMetadata))
+	}))
+	defer server.Close()
+
+	env, err := EnvironmentFromMetadata(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("failed to load metadata: %+v", err)
+	}
+	if len(apiVersions) != 2 || apiVersions[0] != "2019-05-01" {
+		t.Errorf("expected to fall back to api-version 1.0, got %v", apiVersions)
+	}
+	if env.ResourceManagerEndpoint != server.URL+"/" {
+		t.Errorf("expected resource manager %q, got %q", server.URL+"/", env.ResourceManagerEndpoint)
+	}
+	if env.TokenAudience != "https://management.contoso.onmicrosoft.com/4de154de-f8a8-4017-af41-df619da68155" {
+		t.Errorf("expected the first audience, got %q", env.TokenAudience)
+	}
+	if env.ManagementPortalURL != "https://portal.local.azurestack.external/" {
+		t.Errorf("unexpected portal %q", env.ManagementPortalURL)
+	}
+}
+
+// publicMetadata is the shape of api-version 2019-05-01 responses from the
+// public and sovereign clouds, which list every cloud. %s is replaced with
+// the test server's URL.
+const publicMetadata = `[
+	{
+		"name": "AzureCloud",
+		"resourceManager": "https://management.azure.com/",
+		"authentication": {
+			"loginEndpoint": "https://login.microsoftonline.com",
+			"audiences": ["https://management.core.windows.net/", "https://management.azure.com/"]
+		}
+	},
+	{
+		"name": "AzureChinaCloud",
+		"resourceManager": "%s",
+		"portal": "https://portal.azure.cn",
+		"authentication": {
+			"loginEndpoint": "https://login.chinacloudapi.cn",
+			"audiences": ["https://management.core.chinacloudapi.cn/", "https://management.chinacloudapi.cn"]
+		},
+		"suffixes": {"keyVaultDns": "vault.azure.cn", "storage": "core.chinacloudapi.cn"}
+	}
+]`
+
+func TestEnvironmentFromMetadataArray(t *testing.T) {
+	var apiVersions []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiVersions = append(apiVersions, r.URL.Query().Get("api-version"))
+		fmt.Fprintf(w, publicMetadata, "http://"+r.Host)
+	}))
+	defer server.Close()
+
+	env, err := EnvironmentFromMetadata(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("failed to load metadata: %+v", err)
+	}
+	if len(apiVersions) != 1 || apiVersions[0] != "2019-05-01" {
+		t.Errorf("expected the 2019-05-01 response to be used, got %v", apiVersions)
+	}
+	if env.Name != "AzureChinaCloud" || env.ActiveDirectoryEndpoint != "https://login.chinacloudapi.cn/" {
+		t.Errorf("expected the cloud of the endpoint, got %s with login %s", env.Name, env.ActiveDirectoryEndpoint)
+	}
+	if env.KeyVaultEndpoint != "https://vault.azure.cn/" {
+		t.Errorf("unexpected key vault endpoint %q", env.KeyVaultEndpoint)
+	}
+}
+
+func TestEnvironmentFromMetadataFallsBack(t *testing.T) {
+	var apiVersions []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiVersion := r.URL.Query().Get("api-version")
+		apiVersions = append(apiVersions, apiVersion)
+		if apiVersion == "1.0" {
+			w.Write([]byte(stackMetadata))
+			return
+		}
+		// a list of clouds without this one
+		fmt.Fprintf(w, publicMetadata, "https://management.chinacloudapi.cn/")
+	}))
+	defer server.Close()
+
+	env, err := EnvironmentFromMetadata(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("failed to load metadata: %+v", err)
+	}
+	if len(apiVersions) != 2 || apiVersions[1] != "1.0" {
+		t.Errorf("expected to fall back to api-version 1.0, got %v", apiVersions)
+	}
+	if env.ManagementPortalURL != "https://portal.local.azurestack.external/" {
+		t.Errorf("expected the 1.0 metadata, got portal %q", env.ManagementPortalURL)
+	}
+}
+
+func TestEnvironmentFromFile(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	metadataPath := writeFile(t, dir, "metadata.json", stackMetadata)
+	env, err := EnvironmentFromFile(metadataPath, "https://management.local.azurestack.external")
+	if err != nil {
+		t.Fatalf("failed to read metadata file: %+v", err)
+	}
+	if env.ResourceManagerEndpoint != "https://management.local.azurestack.external/" {
+		t.Errorf("unexpected resource manager %q", env.ResourceManagerEndpoint)
+	}
+	if env.KeyVaultDNSSuffix != "vault.local.azurestack.external" || env.StorageEndpointSuffix != "local.azurestack.external" {
+		t.Errorf("expected suffixes from the stamp domain, got %q and %q", env.KeyVaultDNSSuffix, env.StorageEndpointSuffix)
+	}
+	if _, err := EnvironmentFromFile(metadataPath, ""); err == nil {
+		t.Errorf("expected an error without a resource manager endpoint")
+	}
+
+	envPath := writeFile(t, dir, "environment.json", `{
+	"name": "AirGapped",
+	"resourceManagerEndpoint": "https://management.airgapped.example/",
+	"activeDirectoryEndpoint": "https://login.airgapped.example/"
+}`)
+	env, err = EnvironmentFromFile(envPath, "")
+	if err != nil {
+		t.Fatalf("failed to read environment file: %+v", err)
+	}
+	if env.Name != "AirGapped" || env.TokenAudience != env.ResourceManagerEndpoint {
+		t.Errorf("unexpected environment %+v", env)
+	}
+}
+
+func TestEnvironmentUnknownCloud(t *testing.T) {
+	defer Load()
+
+	Load(MapSource{SourceName: "profile", Values: map[string]string{"AZURE_ENVIRONMENT": "MarsCloud"}})
+	if _, err := Environment(); err == nil {
+		t.Errorf("expected an error for an unknown cloud")
+	}
+}
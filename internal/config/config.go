@@ -3,7 +3,8 @@ package config
 
 import (
 	"bytes"
-	"fmt"
+	"strings"
+	"time"
 
 	"github.com/Azure/go-autorest/autorest/azure"
 	"github.com/marstr/randname"
@@ -14,20 +15,42 @@ var (
 	// each has corresponding public accessors below.
 	// if anything requires a `Set` accessor, that indicates it perhaps
 	// shouldn't be set here, because mutable vars shouldn't be global.
-	clientID               string
-	clientSecret           string
-	tenantID               string
-	subscriptionID         string
-	locationDefault        string
-	location               string
-	authorizationServerURL string
-	cloudName              string = "AzurePublicCloud"
-	useDeviceFlow          bool
-	keepResources          bool
-	groupName              string // deprecated, use baseGroupName instead
-	baseGroupName          string
-	userAgent              string
-	environment            *azure.Environment
+	clientID                string
+	clientSecret            string
+	tenantID                string
+	subscriptionID          string
+	locationDefault         string
+	location                string
+	authorizationServerURL  string
+	cloudName               string = "AzurePublicCloud"
+	armEndpoint             string
+	cloudFile               string
+	useDeviceFlow           bool
+	authMethod              string
+	certificatePath         string
+	certificatePassword     string
+	federatedTokenFile      string
+	keepResources           bool
+	resourceTTL             time.Duration
+	runID                   string
+	apiVersionCache         string
+	allowPreviewAPIVersions bool
+	groupName               string // deprecated, use baseGroupName instead
+	baseGroupName           string
+	userAgent               string
+	environment             *azure.Environment
+
+	// origins records which source each setting was loaded from, and
+	// malformed lists the values which couldn't be parsed, for Validate.
+	origins   map[string]Source
+	malformed []string
+
+	// nameGenerator appends a random suffix made of the acceptable runes to
+	// a name prefix. It's replaced when replaying recorded tests so that
+	// generated names are reproducible.
+	nameGenerator = func(prefix string, acceptable []rune) string {
+		return randname.Prefixed{Prefix: prefix, Acceptable: acceptable, Len: 5}.Generate()
+	}
 )
 
 // ClientID is the OAuth client ID.
@@ -50,6 +73,12 @@ func SubscriptionID() string {
 	return subscriptionID
 }
 
+// deprecated: only used to supply a placeholder subscription when replaying
+// recorded tests, don't use it to switch subscriptions at runtime.
+func SetSubscriptionID(id string) {
+	subscriptionID = id
+}
+
 // deprecated: use DefaultLocation() instead
 // Location returns the Azure location to be utilized.
 func Location() string {
@@ -84,6 +113,65 @@ func UseDeviceFlow() bool {
 	return useDeviceFlow
 }
 
+// Authentication methods which may be selected with AZURE_AUTH_METHOD or
+// the `-authMethod` flag.
+const (
+	// AuthMethodClientSecret authenticates a service principal with
+	// AZURE_CLIENT_SECRET.
+	AuthMethodClientSecret = "clientsecret"
+	// AuthMethodDeviceFlow signs in a user interactively.
+	AuthMethodDeviceFlow = "deviceflow"
+	// AuthMethodManagedIdentity uses the managed identity of the VM or
+	// service the samples run on, the user-assigned one with AZURE_CLIENT_ID
+	// if it's set.
+	AuthMethodManagedIdentity = "msi"
+	// AuthMethodCLI uses the account signed in with `az login`.
+	AuthMethodCLI = "cli"
+	// AuthMethodCertificate authenticates a service principal with the PEM or
+	// PFX certificate at AZURE_CLIENT_CERTIFICATE_PATH.
+	AuthMethodCertificate = "certificate"
+	// AuthMethodFederated authenticates a service principal with the JWT in
+	// AZURE_FEDERATED_TOKEN_FILE, e.g. one issued for a Kubernetes workload
+	// identity.
+	AuthMethodFederated = "federated"
+)
+
+// AuthMethod returns how to authenticate, one of the AuthMethod constants.
+// Unless one is chosen explicitly it's inferred from the other settings,
+// falling back to a client secret.
+func AuthMethod() string {
+	switch {
+	case authMethod != "":
+		return strings.ToLower(authMethod)
+	case useDeviceFlow:
+		return AuthMethodDeviceFlow
+	case certificatePath != "":
+		return AuthMethodCertificate
+	case federatedTokenFile != "":
+		return AuthMethodFederated
+	default:
+		return AuthMethodClientSecret
+	}
+}
+
+// CertificatePath is the PEM or PFX file holding the client certificate and
+// its private key.
+func CertificatePath() string {
+	return certificatePath
+}
+
+// CertificatePassword decrypts the PFX file at CertificatePath, if needed.
+func CertificatePassword() string {
+	return certificatePassword
+}
+
+// FederatedTokenFile holds the JWT a service principal trusts as a client
+// assertion. It's read again each time a token is refreshed because the
+// JWT is rotated.
+func FederatedTokenFile() string {
+	return federatedTokenFile
+}
+
 // deprecated: do not use global group names
 // utilize `BaseGroupName()` for a shared prefix
 func GroupName() string {
@@ -106,6 +194,32 @@ func KeepResources() bool {
 	return keepResources
 }
 
+// ResourceTTL() is how long resource groups created by samples should live.
+// Groups are tagged with the time they expire, for `tools/cleanup`.
+func ResourceTTL() time.Duration {
+	return resourceTTL
+}
+
+// RunID() identifies this run of the samples, e.g. a CI build, in the tags
+// of the resource groups it creates. It's random unless AZURE_SAMPLES_RUN_ID
+// is set.
+func RunID() string {
+	return runID
+}
+
+// APIVersionCache() is the file caching the API versions of resource types,
+// or "off" to keep them in memory only. By default it's
+// `azure-sdk-for-go-samples/api-versions.json` in the user's cache directory.
+func APIVersionCache() string {
+	return apiVersionCache
+}
+
+// AllowPreviewAPIVersions() specifies whether resources whose types have no
+// stable API version may be used with a preview version.
+func AllowPreviewAPIVersions() bool {
+	return allowPreviewAPIVersions
+}
+
 // UserAgent() specifies a string to append to the agent identifier.
 func UserAgent() string {
 	if len(userAgent) > 0 {
@@ -114,21 +228,6 @@ func UserAgent() string {
 	return "sdk-samples"
 }
 
-// Environment() returns an `azure.Environment{...}` for the current cloud.
-func Environment() *azure.Environment {
-	if environment != nil {
-		return environment
-	}
-	env, err := azure.EnvironmentFromName(cloudName)
-	if err != nil {
-		// TODO: move to initialization of var
-		panic(fmt.Sprintf(
-			"invalid cloud name '%s' specified, cannot continue\n", cloudName))
-	}
-	environment = &env
-	return environment
-}
-
 // GenerateGroupName leverages BaseGroupName() to return a more detailed name,
 // helping to avoid collisions.  It appends each of the `affixes` to
 // BaseGroupName() separated by dashes, and adds a 5-character random string.
@@ -143,10 +242,26 @@ func GenerateGroupName(affixes ...string) string {
 		b.WriteString(affix)
 		b.WriteRune('-')
 	}
-	return randname.GenerateWithPrefix(b.String(), 5)
+	return nameGenerator(b.String(), randname.PrefixedDefaultAcceptable)
 }
 
 // AppendRandomSuffix will append a suffix of five random characters to the specified prefix.
 func AppendRandomSuffix(prefix string) string {
-	return randname.GenerateWithPrefix(prefix, 5)
+	return nameGenerator(prefix, randname.PrefixedDefaultAcceptable)
+}
+
+// AppendRandomLowercaseSuffix will append a suffix of five random lowercase
+// letters to the specified prefix, for resources such as storage accounts
+// whose names can't contain uppercase letters.
+func AppendRandomLowercaseSuffix(prefix string) string {
+	return nameGenerator(prefix, randname.LowercaseAlphabet)
+}
+
+// SetNameGenerator replaces the function used by GenerateGroupName and
+// AppendRandomSuffix to randomize names, and returns the previous one so it
+// can be restored.
+func SetNameGenerator(generator func(prefix string, acceptable []rune) string) func(prefix string, acceptable []rune) string {
+	previous := nameGenerator
+	nameGenerator = generator
+	return previous
 }
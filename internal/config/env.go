@@ -1,46 +1,110 @@
 package config
 
 import (
+	"fmt"
 	"log"
-	"os"
 	"strconv"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest/azure"
+	"github.com/marstr/randname"
 )
 
-// ParseEnvironment loads a sibling `.env` file then looks through all environment
-// variables to set global configuration.
+// ParseEnvironment loads a `.env` file, profile and SDK auth file, then looks
+// through all environment variables to set global configuration. See
+// `DefaultSources` for where each is found and which takes precedence.
 func ParseEnvironment() error {
+	sources, err := DefaultSources()
+	if err != nil {
+		return err
+	}
+	return Load(sources...)
+}
+
+// defaultResourceTTL is how long resource groups live unless
+// AZURE_SAMPLES_RESOURCE_TTL says otherwise.
+const defaultResourceTTL = 24 * time.Hour
+
+// Load sets global configuration from sources. Where several sources set
+// the same setting the last one wins. Malformed values are logged and
+// reported again by `Validate`.
+func Load(sources ...Source) error {
+	origins = map[string]Source{}
+	malformed = nil
+	lookup := func(key string) string {
+		v, source := resolve(sources, key)
+		if source != nil {
+			origins[key] = source
+		}
+		return v
+	}
+	parseBool := func(key string) bool {
+		v := lookup(key)
+		if v == "" {
+			return false
+		}
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			log.Printf("invalid value specified for %s, disabling\n", key)
+			malformed = append(malformed, fmt.Sprintf("%s must be true or false, got %q%s", key, v, origin(key)))
+		}
+		return b
+	}
 
 	// AZURE_GROUP_NAME and `config.GroupName()` are deprecated.
 	// Use AZURE_BASE_GROUP_NAME and `config.GenerateGroupName()` instead.
-	groupName = os.Getenv("AZURE_GROUP_NAME")
-	baseGroupName = os.Getenv("AZURE_BASE_GROUP_NAME")
+	groupName = lookup("AZURE_GROUP_NAME")
+	baseGroupName = lookup("AZURE_BASE_GROUP_NAME")
 
-	locationDefault = os.Getenv("AZURE_LOCATION_DEFAULT")
+	locationDefault = lookup("AZURE_LOCATION_DEFAULT")
 
-	var err error
-	useDeviceFlow, err = strconv.ParseBool(os.Getenv("AZURE_USE_DEVICEFLOW"))
-	if err != nil {
-		log.Printf("invalid value specified for AZURE_USE_DEVICEFLOW, disabling\n")
-		useDeviceFlow = false
+	useDeviceFlow = parseBool("AZURE_USE_DEVICEFLOW")
+	keepResources = parseBool("AZURE_SAMPLES_KEEP_RESOURCES")
+
+	resourceTTL = defaultResourceTTL
+	if v := lookup("AZURE_SAMPLES_RESOURCE_TTL"); v != "" {
+		ttl, err := time.ParseDuration(v)
+		if err != nil || ttl <= 0 {
+			log.Printf("invalid value specified for AZURE_SAMPLES_RESOURCE_TTL, using %v\n", defaultResourceTTL)
+			malformed = append(malformed, fmt.Sprintf("AZURE_SAMPLES_RESOURCE_TTL must be a positive duration such as 24h, got %q%s", v, origin("AZURE_SAMPLES_RESOURCE_TTL")))
+		} else {
+			resourceTTL = ttl
+		}
 	}
-	keepResources, err = strconv.ParseBool(os.Getenv("AZURE_SAMPLES_KEEP_RESOURCES"))
-	if err != nil {
-		log.Printf("invalid value specified for AZURE_SAMPLES_KEEP_RESOURCES, discarding\n")
-		keepResources = false
+	apiVersionCache = lookup("AZURE_SAMPLES_API_VERSION_CACHE")
+	allowPreviewAPIVersions = parseBool("AZURE_SAMPLES_ALLOW_PREVIEW_API_VERSIONS")
+	runID = lookup("AZURE_SAMPLES_RUN_ID")
+	if runID == "" {
+		runID = nameGenerator("run-", randname.LowercaseAlphabet)
 	}
 
 	// these must be provided by environment
 	// clientID
-	clientID = os.Getenv("AZURE_CLIENT_ID")
+	clientID = lookup("AZURE_CLIENT_ID")
 
 	// clientSecret
-	clientSecret = os.Getenv("AZURE_CLIENT_SECRET")
+	clientSecret = lookup("AZURE_CLIENT_SECRET")
 
 	// tenantID (AAD)
-	tenantID = os.Getenv("AZURE_TENANT_ID")
+	tenantID = lookup("AZURE_TENANT_ID")
+
+	// how to authenticate, see `AuthMethod()`
+	authMethod = lookup("AZURE_AUTH_METHOD")
+	certificatePath = lookup("AZURE_CLIENT_CERTIFICATE_PATH")
+	certificatePassword = lookup("AZURE_CLIENT_CERTIFICATE_PASSWORD")
+	federatedTokenFile = lookup("AZURE_FEDERATED_TOKEN_FILE")
 
 	// subscriptionID (ARM)
-	subscriptionID = os.Getenv("AZURE_SUBSCRIPTION_ID")
+	subscriptionID = lookup("AZURE_SUBSCRIPTION_ID")
+
+	// the cloud, see `Environment()`
+	cloudName = lookup("AZURE_ENVIRONMENT")
+	if cloudName == "" {
+		cloudName = azure.PublicCloud.Name
+	}
+	armEndpoint = lookup("AZURE_ARM_ENDPOINT")
+	cloudFile = lookup("AZURE_CLOUD_FILE")
+	SetEnvironment(nil)
 
 	return nil
 }
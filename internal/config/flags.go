@@ -4,16 +4,28 @@ import (
 	"flag"
 )
 
-// AddFlags adds flags applicable to all services.
+// AddFlags adds flags applicable to all services to `flag.CommandLine`.
 // Remember to call `flag.Parse()` in your main or TestMain.
 func AddFlags() error {
-	flag.StringVar(&subscriptionID, "subscription", subscriptionID, "Subscription for tests.")
-	flag.StringVar(&locationDefault, "location", locationDefault, "Default location for tests.")
-	flag.StringVar(&cloudName, "cloud", cloudName, "Name of Azure cloud.")
-	flag.StringVar(&baseGroupName, "baseGroupName", BaseGroupName(), "Specify prefix name of resource group for sample resources.")
+	return AddFlagsTo(flag.CommandLine)
+}
+
+// AddFlagsTo adds flags applicable to all services to fs, so that tools with
+// their own flag set can share them. Flags override every other source.
+func AddFlagsTo(fs *flag.FlagSet) error {
+	fs.StringVar(&subscriptionID, "subscription", subscriptionID, "Subscription for tests.")
+	fs.StringVar(&locationDefault, "location", locationDefault, "Default location for tests.")
+	fs.StringVar(&cloudName, "cloud", cloudName, "Name of Azure cloud.")
+	fs.StringVar(&armEndpoint, "armEndpoint", armEndpoint, "Resource Manager endpoint of a cloud to load from its metadata endpoint, e.g. Azure Stack.")
+	fs.StringVar(&cloudFile, "cloudFile", cloudFile, "JSON file describing the endpoints of the cloud.")
+	fs.StringVar(&baseGroupName, "baseGroupName", BaseGroupName(), "Specify prefix name of resource group for sample resources.")
 
-	flag.BoolVar(&useDeviceFlow, "useDeviceFlow", useDeviceFlow, "Use device-flow grant type rather than client credentials.")
-	flag.BoolVar(&keepResources, "keepResources", keepResources, "Keep resources created by samples.")
+	fs.StringVar(&authMethod, "authMethod", authMethod, "How to authenticate: clientsecret, deviceflow, msi, cli, certificate or federated.")
+	fs.BoolVar(&useDeviceFlow, "useDeviceFlow", useDeviceFlow, "Use device-flow grant type rather than client credentials.")
+	fs.BoolVar(&keepResources, "keepResources", keepResources, "Keep resources created by samples.")
+	fs.DurationVar(&resourceTTL, "resourceTTL", resourceTTL, "How long resource groups created by samples should live before tools/cleanup deletes them.")
+	fs.StringVar(&apiVersionCache, "apiVersionCache", apiVersionCache, "File caching the API versions of resource types, or off.")
+	fs.BoolVar(&allowPreviewAPIVersions, "allowPreviewAPIVersions", allowPreviewAPIVersions, "Use preview API versions for resource types without a stable one.")
 
 	return nil
 }
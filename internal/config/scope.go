@@ -0,0 +1,48 @@
+package config
+
+import (
+	"context"
+)
+
+// Scope identifies where a sample's resources live. Attach it to the
+// context passed to helpers with `WithScope` rather than setting the
+// deprecated global group name, so tests using different resource groups
+// can run in parallel.
+type Scope struct {
+	SubscriptionID string
+	GroupName      string
+	Location       string
+}
+
+type scopeKey struct{}
+
+// NewScope returns a scope for the named resource group in the configured
+// subscription and location.
+func NewScope(groupName string) Scope {
+	return Scope{
+		SubscriptionID: SubscriptionID(),
+		GroupName:      groupName,
+		Location:       Location(),
+	}
+}
+
+// WithScope returns a copy of ctx carrying scope.
+func WithScope(ctx context.Context, scope Scope) context.Context {
+	return context.WithValue(ctx, scopeKey{}, scope)
+}
+
+// ScopeFrom returns the scope attached to ctx. Fields which aren't set fall
+// back to the global configuration, including the deprecated `GroupName()`.
+func ScopeFrom(ctx context.Context) Scope {
+	scope, _ := ctx.Value(scopeKey{}).(Scope)
+	if scope.SubscriptionID == "" {
+		scope.SubscriptionID = SubscriptionID()
+	}
+	if scope.GroupName == "" {
+		scope.GroupName = GroupName()
+	}
+	if scope.Location == "" {
+		scope.Location = Location()
+	}
+	return scope
+}
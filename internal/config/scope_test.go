@@ -0,0 +1,25 @@
+package config
+
+import (
+	"context"
+	"testing"
+)
+
+func TestScopeFrom(t *testing.T) {
+	defer SetGroupName(GroupName())
+	SetGroupName("global-group")
+
+	scope := ScopeFrom(context.Background())
+	if scope.GroupName != "global-group" {
+		t.Errorf("expected fallback to the global group, got %q", scope.GroupName)
+	}
+
+	ctx := WithScope(context.Background(), Scope{GroupName: "scoped-group", Location: "westus2"})
+	scope = ScopeFrom(ctx)
+	if scope.GroupName != "scoped-group" || scope.Location != "westus2" {
+		t.Errorf("expected the attached scope, got %+v", scope)
+	}
+	if scope.SubscriptionID != SubscriptionID() {
+		t.Errorf("expected unset subscription to fall back to %q, got %q", SubscriptionID(), scope.SubscriptionID)
+	}
+}
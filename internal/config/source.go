@@ -0,0 +1,260 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Source supplies settings by their environment variable name, e.g.
+// `AZURE_SUBSCRIPTION_ID`, whatever the underlying format is.
+type Source interface {
+	// Name describes the source in error messages.
+	Name() string
+	// Lookup returns the value of a setting and whether it was found.
+	Lookup(key string) (string, bool)
+}
+
+// MapSource is a Source backed by a map, e.g. for tests.
+type MapSource struct {
+	SourceName string
+	Values     map[string]string
+}
+
+// Name describes the source.
+func (s MapSource) Name() string {
+	return s.SourceName
+}
+
+// Lookup returns the value stored for key.
+func (s MapSource) Lookup(key string) (string, bool) {
+	v, ok := s.Values[key]
+	return v, ok
+}
+
+type envSource struct{}
+
+// EnvSource reads settings from environment variables.
+func EnvSource() Source {
+	return envSource{}
+}
+
+func (envSource) Name() string                     { return "environment" }
+func (envSource) Lookup(key string) (string, bool) { return os.LookupEnv(key) }
+
+// DotEnvSource reads settings from a `.env` file of `KEY=value` lines. Blank
+// lines and lines starting with `#` are ignored, values may be quoted and
+// `$VAR` references are expanded from earlier lines or the environment.
+func DotEnvSource(path string) (Source, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	values := map[string]string{}
+	expand := func(name string) string {
+		if v, ok := values[name]; ok {
+			return v
+		}
+		return os.Getenv(name)
+	}
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		text = strings.TrimPrefix(text, "export ")
+		i := strings.Index(text, "=")
+		if i < 1 {
+			return nil, fmt.Errorf("%s:%d: expected KEY=value", path, line)
+		}
+		key, value := strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+1:])
+		if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+			values[key] = value[1 : len(value)-1]
+			continue
+		}
+		if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+			value = value[1 : len(value)-1]
+		}
+		values[key] = os.Expand(value, expand)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return MapSource{SourceName: path, Values: values}, nil
+}
+
+// profileFile is the layout of a profile file, in YAML or JSON:
+//
+//	default: dev
+//	profiles:
+//	  dev:
+//	    AZURE_SUBSCRIPTION_ID: 00000000-0000-0000-0000-000000000000
+//	    AZURE_LOCATION_DEFAULT: westus2
+//	  prod:
+//	    ...
+type profileFile struct {
+	Default  string                       `json:"default" yaml:"default"`
+	Profiles map[string]map[string]string `json:"profiles" yaml:"profiles"`
+}
+
+// ProfileSource reads the settings of the named profile from a YAML or JSON
+// profile file. An empty name selects the file's default profile, if any.
+func ProfileSource(path, name string) (Source, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file profileFile
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.Unmarshal(data, &file)
+	} else {
+		err = yaml.Unmarshal(data, &file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse profile file %s: %v", path, err)
+	}
+
+	if name == "" {
+		name = file.Default
+	}
+	if name == "" {
+		return MapSource{SourceName: path}, nil
+	}
+	values, ok := file.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("profile %q not found in %s", name, path)
+	}
+	return MapSource{SourceName: fmt.Sprintf("%s (profile %s)", path, name), Values: values}, nil
+}
+
+// AuthFileSource reads the service principal from an SDK auth file, as
+// created by `az ad sp create-for-rbac --sdk-auth`.
+func AuthFileSource(path string) (Source, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		ClientID       string `json:"clientId"`
+		ClientSecret   string `json:"clientSecret"`
+		SubscriptionID string `json:"subscriptionId"`
+		TenantID       string `json:"tenantId"`
+	}
+	if err := json.Unmarshal(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")), &file); err != nil {
+		return nil, fmt.Errorf("cannot parse auth file %s: %v", path, err)
+	}
+	values := map[string]string{
+		"AZURE_CLIENT_ID":       file.ClientID,
+		"AZURE_CLIENT_SECRET":   file.ClientSecret,
+		"AZURE_SUBSCRIPTION_ID": file.SubscriptionID,
+		"AZURE_TENANT_ID":       file.TenantID,
+	}
+	for k, v := range values {
+		if v == "" {
+			delete(values, k)
+		}
+	}
+	return MapSource{SourceName: path, Values: values}, nil
+}
+
+// DefaultSources returns the sources ParseEnvironment reads, in increasing
+// order of precedence:
+//
+//  1. the nearest `.env` file in the working directory or its parents
+//  2. the profile named by AZURE_PROFILE in the file at AZURE_PROFILE_FILE,
+//     `~/.azure/go-samples-profiles.yaml` by default
+//  3. the SDK auth file at AZURE_AUTH_LOCATION
+//  4. environment variables
+//
+// Command-line flags registered with AddFlags are parsed last and override
+// all of these. AZURE_PROFILE, AZURE_PROFILE_FILE and AZURE_AUTH_LOCATION
+// may themselves be set in the `.env` file.
+func DefaultSources() ([]Source, error) {
+	sources := []Source{}
+	if path, ok := findDotEnv(); ok {
+		dotEnv, err := DotEnvSource(path)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, dotEnv)
+	}
+	sources = append(sources, EnvSource())
+	lookup := func(key string) string {
+		v, _ := resolve(sources, key)
+		return v
+	}
+
+	var files []Source
+	profilePath, profile := lookup("AZURE_PROFILE_FILE"), lookup("AZURE_PROFILE")
+	explicit := profilePath != ""
+	if !explicit {
+		if home, err := os.UserHomeDir(); err == nil {
+			profilePath = filepath.Join(home, ".azure", "go-samples-profiles.yaml")
+		}
+	}
+	if _, err := os.Stat(profilePath); err == nil || explicit || profile != "" {
+		profileSource, err := ProfileSource(profilePath, profile)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, profileSource)
+	}
+	// `.env.tpl` points AZURE_AUTH_LOCATION at a file which needn't exist
+	// when the service principal is given some other way
+	if path := lookup("AZURE_AUTH_LOCATION"); path != "" {
+		authFile, err := AuthFileSource(path)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		if err == nil {
+			files = append(files, authFile)
+		}
+	}
+
+	// files go between the .env file and the environment
+	n := len(sources) - 1
+	return append(append(sources[:n:n], files...), sources[n]), nil
+}
+
+// findDotEnv looks for a `.env` file in the working directory and each of
+// its parents, so tests run from a package directory find the one at the
+// repository root.
+func findDotEnv() (string, bool) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", false
+	}
+	for {
+		path := filepath.Join(dir, ".env")
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// resolve returns the value of key from the source with the highest
+// precedence, the last one, which sets it to a non-empty value, along with
+// that source.
+func resolve(sources []Source, key string) (string, Source) {
+	for i := len(sources) - 1; i >= 0; i-- {
+		if v, ok := sources[i].Lookup(key); ok && v != "" {
+			return v, sources[i]
+		}
+	}
+	return "", nil
+}
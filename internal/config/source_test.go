@@ -0,0 +1,249 @@
+package config
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	devSubscription  = "11111111-1111-1111-1111-111111111111"
+	prodSubscription = "22222222-2222-2222-2222-222222222222"
+)
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func tempDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+func TestDotEnvSource(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	os.Setenv("CONFIG_TEST_HOME", "/home/gopher")
+	defer os.Unsetenv("CONFIG_TEST_HOME")
+
+	path := writeFile(t, dir, ".env", `
+# comment
+AZURE_BASE_GROUP_NAME=az-samples-go
+export AZURE_LOCATION_DEFAULT="westus2"
+AZURE_CLIENT_SECRET='$not-expanded'
+AZURE_AUTH_LOCATION=$CONFIG_TEST_HOME/.azure/sdk_auth.json
+AZURE_GROUP_NAME=${AZURE_BASE_GROUP_NAME}-legacy
+AZURE_TENANT_ID=
+`)
+	source, err := DotEnvSource(path)
+	if err != nil {
+		t.Fatalf("failed to read .env: %+v", err)
+	}
+	expected := map[string]string{
+		"AZURE_BASE_GROUP_NAME":  "az-samples-go",
+		"AZURE_LOCATION_DEFAULT": "westus2",
+		"AZURE_CLIENT_SECRET":    "$not-expanded",
+		"AZURE_AUTH_LOCATION":    "/home/gopher/.azure/sdk_auth.json",
+		"AZURE_GROUP_NAME":       "az-samples-go-legacy",
+		"AZURE_TENANT_ID":        "",
+	}
+	for key, want := range expected {
+		if got, ok := source.Lookup(key); !ok || got != want {
+			t.Errorf("%s: expected %q, got %q", key, want, got)
+		}
+	}
+
+	bad := writeFile(t, dir, "bad.env", "NOT A SETTING\n")
+	if _, err := DotEnvSource(bad); err == nil || !strings.Contains(err.Error(), "bad.env:1") {
+		t.Errorf("expected a parse error with the line number, got %v", err)
+	}
+}
+
+func TestProfileSource(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	yamlPath := writeFile(t, dir, "profiles.yaml", `
+default: dev
+profiles:
+  dev:
+    AZURE_SUBSCRIPTION_ID: `+devSubscription+`
+  prod:
+    AZURE_SUBSCRIPTION_ID: `+prodSubscription+`
+`)
+	jsonPath := writeFile(t, dir, "profiles.json", `{
+	"profiles": {"prod": {"AZURE_SUBSCRIPTION_ID": "`+prodSubscription+`"}}
+}`)
+
+	cases := []struct {
+		path, profile, want string
+	}{
+		{yamlPath, "", devSubscription},
+		{yamlPath, "prod", prodSubscription},
+		{jsonPath, "prod", prodSubscription},
+		{jsonPath, "", ""},
+	}
+	for _, c := range cases {
+		source, err := ProfileSource(c.path, c.profile)
+		if err != nil {
+			t.Fatalf("failed to read %s: %+v", c.path, err)
+		}
+		if got, _ := source.Lookup("AZURE_SUBSCRIPTION_ID"); got != c.want {
+			t.Errorf("%s profile %q: expected %q, got %q", filepath.Base(c.path), c.profile, c.want, got)
+		}
+	}
+
+	if _, err := ProfileSource(yamlPath, "staging"); err == nil {
+		t.Errorf("expected an error for a missing profile")
+	}
+}
+
+func TestAuthFileSource(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	path := writeFile(t, dir, "sdk_auth.json", "\xef\xbb\xbf"+`{
+	"clientId": "33333333-3333-3333-3333-333333333333",
+	"clientSecret": "secret",
+	"subscriptionId": "`+devSubscription+`",
+	"tenantId": "44444444-4444-4444-4444-444444444444",
+	"resourceManagerEndpointUrl": "https://management.azure.com/"
+}`)
+	source, err := AuthFileSource(path)
+	if err != nil {
+		t.Fatalf("failed to read auth file: %+v", err)
+	}
+	if got, _ := source.Lookup("AZURE_SUBSCRIPTION_ID"); got != devSubscription {
+		t.Errorf("expected subscription %q, got %q", devSubscription, got)
+	}
+	if got, _ := source.Lookup("AZURE_CLIENT_SECRET"); got != "secret" {
+		t.Errorf("expected the client secret, got %q", got)
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	defer Load()
+
+	err := Load(
+		MapSource{SourceName: ".env", Values: map[string]string{
+			"AZURE_SUBSCRIPTION_ID":  devSubscription,
+			"AZURE_LOCATION_DEFAULT": "westus2",
+			"AZURE_BASE_GROUP_NAME":  "from-dotenv",
+		}},
+		MapSource{SourceName: "profile", Values: map[string]string{
+			"AZURE_SUBSCRIPTION_ID": prodSubscription,
+			"AZURE_BASE_GROUP_NAME": "from-profile",
+		}},
+		MapSource{SourceName: "environment", Values: map[string]string{
+			"AZURE_BASE_GROUP_NAME":  "",
+			"AZURE_LOCATION_DEFAULT": "eastus",
+		}},
+	)
+	if err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	if SubscriptionID() != prodSubscription {
+		t.Errorf("expected the profile to override .env, got %q", SubscriptionID())
+	}
+	if DefaultLocation() != "eastus" {
+		t.Errorf("expected the environment to override .env, got %q", DefaultLocation())
+	}
+	if BaseGroupName() != "from-profile" {
+		t.Errorf("expected empty values to be ignored, got %q", BaseGroupName())
+	}
+}
+
+func TestValidate(t *testing.T) {
+	defer Load()
+
+	Load(MapSource{SourceName: "profile", Values: map[string]string{
+		"AZURE_SUBSCRIPTION_ID":        "not-a-guid",
+		"AZURE_CLIENT_ID":              "33333333-3333-3333-3333-333333333333",
+		"AZURE_SAMPLES_KEEP_RESOURCES": "maybe",
+	}})
+	err := Validate()
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected a ValidationError, got %v", err)
+	}
+	for _, want := range []string{
+		`AZURE_SAMPLES_KEEP_RESOURCES must be true or false, got "maybe" (from profile)`,
+		`AZURE_SUBSCRIPTION_ID must be a GUID, got "not-a-guid" (from profile)`,
+		"AZURE_TENANT_ID is required",
+		"AZURE_CLIENT_SECRET is required",
+		"AZURE_LOCATION_DEFAULT is required (or the -location flag)",
+		"AZURE_BASE_GROUP_NAME is required (or the -baseGroupName flag)",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q to be reported, got:\n%v", want, err)
+		}
+	}
+	if len(validationErr.Problems) != 6 {
+		t.Errorf("expected 6 problems, got %d", len(validationErr.Problems))
+	}
+
+	Load(MapSource{SourceName: "profile", Values: map[string]string{
+		"AZURE_SUBSCRIPTION_ID":  devSubscription,
+		"AZURE_TENANT_ID":        "44444444-4444-4444-4444-444444444444",
+		"AZURE_CLIENT_ID":        "33333333-3333-3333-3333-333333333333",
+		"AZURE_USE_DEVICEFLOW":   "true",
+		"AZURE_LOCATION_DEFAULT": "westus2",
+		"AZURE_BASE_GROUP_NAME":  "az-samples-go",
+	}})
+	if err := Validate(); err != nil {
+		t.Errorf("expected a valid configuration, got %v", err)
+	}
+}
+
+func TestAuthMethod(t *testing.T) {
+	defer Load()
+
+	cases := []struct {
+		values map[string]string
+		want   string
+	}{
+		{map[string]string{}, AuthMethodClientSecret},
+		{map[string]string{"AZURE_USE_DEVICEFLOW": "true"}, AuthMethodDeviceFlow},
+		{map[string]string{"AZURE_CLIENT_CERTIFICATE_PATH": "sp.pem"}, AuthMethodCertificate},
+		{map[string]string{"AZURE_FEDERATED_TOKEN_FILE": "/var/run/token"}, AuthMethodFederated},
+		{map[string]string{"AZURE_AUTH_METHOD": "MSI", "AZURE_CLIENT_CERTIFICATE_PATH": "sp.pem"}, AuthMethodManagedIdentity},
+	}
+	for _, c := range cases {
+		Load(MapSource{SourceName: "profile", Values: c.values})
+		if got := AuthMethod(); got != c.want {
+			t.Errorf("%v: expected %q, got %q", c.values, c.want, got)
+		}
+	}
+
+	Load(MapSource{SourceName: "profile", Values: map[string]string{
+		"AZURE_AUTH_METHOD":      "cli",
+		"AZURE_SUBSCRIPTION_ID":  devSubscription,
+		"AZURE_LOCATION_DEFAULT": "westus2",
+		"AZURE_BASE_GROUP_NAME":  "az-samples-go",
+	}})
+	if err := Validate(); err != nil {
+		t.Errorf("expected the CLI not to need a service principal, got %v", err)
+	}
+
+	Load(MapSource{SourceName: "profile", Values: map[string]string{
+		"AZURE_AUTH_METHOD":      "certificate",
+		"AZURE_SUBSCRIPTION_ID":  devSubscription,
+		"AZURE_TENANT_ID":        "44444444-4444-4444-4444-444444444444",
+		"AZURE_CLIENT_ID":        "33333333-3333-3333-3333-333333333333",
+		"AZURE_LOCATION_DEFAULT": "westus2",
+		"AZURE_BASE_GROUP_NAME":  "az-samples-go",
+	}})
+	if err := Validate(); err == nil || !strings.Contains(err.Error(), "AZURE_CLIENT_CERTIFICATE_PATH is required") {
+		t.Errorf("expected the certificate path to be required, got %v", err)
+	}
+}
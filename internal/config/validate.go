@@ -0,0 +1,93 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// ValidationError lists every problem `Validate` found with the
+// configuration.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid configuration:\n  %s", strings.Join(e.Problems, "\n  "))
+}
+
+// Validate checks the loaded configuration and reports every missing or
+// malformed setting at once, as a *ValidationError. Call it after
+// `ParseEnvironment` and `flag.Parse()` to fail fast rather than deep inside
+// a sample.
+func Validate() error {
+	problems := append([]string(nil), malformed...)
+	required := func(value, key, flagName string) bool {
+		if value != "" {
+			return true
+		}
+		msg := fmt.Sprintf("%s is required", key)
+		if flagName != "" {
+			msg += fmt.Sprintf(" (or the -%s flag)", flagName)
+		}
+		problems = append(problems, msg)
+		return false
+	}
+	uuid := func(value, key string) {
+		if !uuidPattern.MatchString(value) {
+			problems = append(problems, fmt.Sprintf("%s must be a GUID, got %q%s", key, value, origin(key)))
+		}
+	}
+
+	if required(subscriptionID, "AZURE_SUBSCRIPTION_ID", "subscription") {
+		uuid(subscriptionID, "AZURE_SUBSCRIPTION_ID")
+	}
+	switch method := AuthMethod(); method {
+	case AuthMethodManagedIdentity:
+		// a user-assigned identity is optional
+		if clientID != "" {
+			uuid(clientID, "AZURE_CLIENT_ID")
+		}
+	case AuthMethodCLI:
+		// `az login` picked the account already
+	case AuthMethodClientSecret, AuthMethodDeviceFlow, AuthMethodCertificate, AuthMethodFederated:
+		if required(tenantID, "AZURE_TENANT_ID", "") {
+			uuid(tenantID, "AZURE_TENANT_ID")
+		}
+		if required(clientID, "AZURE_CLIENT_ID", "") {
+			uuid(clientID, "AZURE_CLIENT_ID")
+		}
+		switch method {
+		case AuthMethodClientSecret:
+			required(clientSecret, "AZURE_CLIENT_SECRET", "")
+		case AuthMethodCertificate:
+			required(certificatePath, "AZURE_CLIENT_CERTIFICATE_PATH", "")
+		case AuthMethodFederated:
+			required(federatedTokenFile, "AZURE_FEDERATED_TOKEN_FILE", "")
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("AZURE_AUTH_METHOD must be one of %s, got %q%s",
+			strings.Join([]string{AuthMethodClientSecret, AuthMethodDeviceFlow, AuthMethodManagedIdentity,
+				AuthMethodCLI, AuthMethodCertificate, AuthMethodFederated}, ", "), method, origin("AZURE_AUTH_METHOD")))
+	}
+	required(locationDefault, "AZURE_LOCATION_DEFAULT", "location")
+	required(baseGroupName, "AZURE_BASE_GROUP_NAME", "baseGroupName")
+	if _, err := Environment(); err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// origin describes where a setting was loaded from, for error messages.
+func origin(key string) string {
+	if source, ok := origins[key]; ok {
+		return fmt.Sprintf(" (from %s)", source.Name())
+	}
+	return ""
+}
@@ -0,0 +1,104 @@
+package resource
+
+import (
+	"math/rand"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+)
+
+// Backoff decides how long WaitForStateContext waits before refreshing
+// again. attempt is the number of refreshes made so far, starting at 1, and
+// result is the value returned by the latest refresh.
+type Backoff interface {
+	Next(attempt int, result interface{}) time.Duration
+}
+
+// BackoffFunc adapts a function to the Backoff interface.
+type BackoffFunc func(attempt int, result interface{}) time.Duration
+
+// Next calls f(attempt, result).
+func (f BackoffFunc) Next(attempt int, result interface{}) time.Duration {
+	return f(attempt, result)
+}
+
+// FixedBackoff waits the same interval between every refresh.
+func FixedBackoff(interval time.Duration) Backoff {
+	return BackoffFunc(func(int, interface{}) time.Duration {
+		return interval
+	})
+}
+
+// ExponentialBackoff waits min after the first refresh and doubles the wait
+// after each further refresh, up to max. Each wait is then randomly moved by
+// up to the jitter fraction of itself, e.g. a jitter of 0.2 spreads a 10s
+// wait between 8s and 12s, so that many waiters don't poll in lockstep.
+func ExponentialBackoff(min, max time.Duration, jitter float64) Backoff {
+	return BackoffFunc(func(attempt int, _ interface{}) time.Duration {
+		wait := min
+		for i := 1; i < attempt && wait < max; i++ {
+			wait *= 2
+		}
+		if wait > max {
+			wait = max
+		}
+		if jitter > 0 {
+			wait += time.Duration(jitter * float64(wait) * (2*rand.Float64() - 1))
+		}
+		return wait
+	})
+}
+
+// RetryAfterBackoff waits as long as the Retry-After header of the latest
+// response asks, and falls back to fallback when there's no such header.
+// The response is found when the refresh result is an *http.Response, or a
+// struct with a RawResponse or Response field holding or embedding one,
+// which covers the track 1 and track 2 SDK response types.
+func RetryAfterBackoff(fallback Backoff) Backoff {
+	return BackoffFunc(func(attempt int, result interface{}) time.Duration {
+		if resp := httpResponse(result); resp != nil {
+			if wait := azcore.RetryAfter(resp); wait > 0 {
+				return wait
+			}
+		}
+		return fallback.Next(attempt, result)
+	})
+}
+
+var httpResponseType = reflect.TypeOf(&http.Response{})
+
+func httpResponse(result interface{}) *http.Response {
+	if resp, ok := result.(*http.Response); ok {
+		return resp
+	}
+	v := reflect.ValueOf(result)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	for _, name := range []string{"RawResponse", "Response"} {
+		f := v.FieldByName(name)
+		if !f.IsValid() {
+			continue
+		}
+		if f.Type() == httpResponseType {
+			if f.IsNil() {
+				return nil
+			}
+			return f.Interface().(*http.Response)
+		}
+		// track 1 models embed autorest.Response, which in turn embeds
+		// the *http.Response
+		if f.Kind() == reflect.Struct {
+			return httpResponse(f.Interface())
+		}
+	}
+	return nil
+}
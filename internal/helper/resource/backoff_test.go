@@ -0,0 +1,115 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+)
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := ExponentialBackoff(time.Second, 5*time.Second, 0)
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, want := range expected {
+		if got := backoff.Next(i+1, nil); got != want {
+			t.Errorf("attempt %d: expected %s, got %s", i+1, want, got)
+		}
+	}
+
+	jittered := ExponentialBackoff(10*time.Second, time.Minute, 0.2)
+	for i := 0; i < 100; i++ {
+		if got := jittered.Next(1, nil); got < 8*time.Second || got > 12*time.Second {
+			t.Fatalf("expected a jittered wait between 8s and 12s, got %s", got)
+		}
+	}
+}
+
+func TestRetryAfterBackoff(t *testing.T) {
+	resp := &http.Response{Header: http.Header{"Retry-After": []string{"7"}}}
+	type trackTwoResponse struct{ RawResponse *http.Response }
+	type trackOneModel struct{ autorest.Response }
+
+	backoff := RetryAfterBackoff(FixedBackoff(time.Second))
+	for _, result := range []interface{}{
+		resp,
+		trackTwoResponse{RawResponse: resp},
+		&trackTwoResponse{RawResponse: resp},
+		trackOneModel{Response: autorest.Response{Response: resp}},
+	} {
+		if got := backoff.Next(1, result); got != 7*time.Second {
+			t.Errorf("%T: expected the Retry-After wait, got %s", result, got)
+		}
+	}
+	for _, result := range []interface{}{nil, "ready", trackTwoResponse{}, &http.Response{}} {
+		if got := backoff.Next(1, result); got != time.Second {
+			t.Errorf("%T: expected the fallback wait, got %s", result, got)
+		}
+	}
+}
+
+func TestWaitForStateContextUsesBackoffAndProgress(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	states := []string{"Creating", "Creating", "Succeeded"}
+	var progress []Progress
+	conf := &StateChangeConf{
+		Pending: []string{"Creating"},
+		Target:  []string{"Succeeded"},
+		Refresh: func() (interface{}, string, error) {
+			state := states[0]
+			states = states[1:]
+			return state, state, nil
+		},
+		Timeout:  time.Minute,
+		Backoff:  ExponentialBackoff(time.Second, time.Minute, 0),
+		Clock:    clock,
+		Progress: func(p Progress) { progress = append(progress, p) },
+	}
+
+	res, err := conf.WaitForStateContext(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if res != "Succeeded" {
+		t.Errorf("expected the target result, got %v", res)
+	}
+	waits := clock.Waits()
+	if len(waits) != 2 || waits[0] != time.Second || waits[1] != 2*time.Second {
+		t.Errorf("expected waits of 1s and 2s, got %v", waits)
+	}
+	if len(progress) != 2 || progress[1].Attempt != 2 || progress[1].Elapsed != time.Second || progress[1].NextWait != 2*time.Second {
+		t.Errorf("unexpected progress reports: %+v", progress)
+	}
+}
+
+func TestWaitForStateContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	conf := &StateChangeConf{
+		Pending: []string{"Creating"},
+		Target:  []string{"Succeeded"},
+		Refresh: func() (interface{}, string, error) {
+			cancel()
+			return "Creating", "Creating", nil
+		},
+		Backoff: FixedBackoff(time.Hour),
+	}
+	if _, err := conf.WaitForStateContext(ctx); err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	conf.Refresh = func() (interface{}, string, error) {
+		return "Creating", "Creating", nil
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	_, err := conf.WaitForStateContext(ctx)
+	var timeoutErr *TimeoutError
+	if !errors.As(err, &timeoutErr) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected a TimeoutError wrapping the deadline, got %v", err)
+	}
+	if timeoutErr != nil && timeoutErr.LastState != "Creating" {
+		t.Errorf("expected the last state to be reported, got %q", timeoutErr.LastState)
+	}
+}
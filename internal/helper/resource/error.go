@@ -26,6 +26,10 @@ func (e *NotFoundError) Error() string {
 	return "couldn't find resource"
 }
 
+func (e *NotFoundError) Unwrap() error {
+	return e.LastError
+}
+
 // UnexpectedStateError is returned when Refresh returns a state that's neither in Target nor Pending
 type UnexpectedStateError struct {
 	LastError     error
@@ -42,6 +46,10 @@ func (e *UnexpectedStateError) Error() string {
 	)
 }
 
+func (e *UnexpectedStateError) Unwrap() error {
+	return e.LastError
+}
+
 // TimeoutError is returned when WaitForState times out
 type TimeoutError struct {
 	LastError     error
@@ -77,3 +85,9 @@ func (e *TimeoutError) Error() string {
 	return fmt.Sprintf("timeout while waiting for %s%s",
 		expectedState, suffix)
 }
+
+// Unwrap returns the last error seen, which is the context's error when
+// the context deadline ended the wait.
+func (e *TimeoutError) Unwrap() error {
+	return e.LastError
+}
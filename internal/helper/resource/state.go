@@ -1,12 +1,10 @@
 package resource
 
 import (
-	"log"
+	"context"
 	"time"
 )
 
-var refreshGracePeriod = 30 * time.Second
-
 // StateRefreshFunc is a function type used for StateChangeConf that is
 // responsible for refreshing the item being watched for a state change.
 //
@@ -32,108 +30,148 @@ type StateChangeConf struct {
 
 	// This is to work around inconsistent APIs
 	ContinuousTargetOccurence int // Number of times the Target state has to occur continuously
+
+	Backoff  Backoff        // Override MinTimeout/PollInterval and wait this long between refreshes
+	Clock    Clock          // Clock used for every wait, the real clock by default
+	Progress func(Progress) // Called after each refresh which doesn't finish the wait
+}
+
+// Progress describes a refresh which didn't reach the target state, and is
+// passed to StateChangeConf.Progress.
+type Progress struct {
+	Attempt  int           // Number of refreshes made so far, starting at 1
+	Result   interface{}   // Result of the latest refresh
+	State    string        // State of the latest refresh
+	Elapsed  time.Duration // Time since the wait started
+	NextWait time.Duration // Time until the next refresh
 }
 
 // WaitForState watches an object and waits for it to achieve the state
 // specified in the configuration using the specified Refresh() func,
 // waiting the number of seconds specified in the timeout configuration.
 //
+// It's WaitForStateContext without a context, see that for details.
+func (conf *StateChangeConf) WaitForState() (interface{}, error) {
+	return conf.WaitForStateContext(context.Background())
+}
+
+// WaitForStateContext watches an object and waits for it to achieve the
+// state specified in the configuration using the specified Refresh() func.
+//
 // If the Refresh function returns an error, exit immediately with that error.
 //
 // If the Refresh function returns a state other than the Target state or one
-// listed in Pending, return immediately with an error.
+// listed in Pending, return immediately with an *UnexpectedStateError.
+//
+// If the Refresh function returns a nil result more than NotFoundChecks
+// times in a row while waiting for a Target state, return a *NotFoundError.
 //
-// If the Timeout is exceeded before reaching the Target state, return an
-// error.
+// If the Timeout or the deadline of ctx is exceeded before reaching the
+// Target state, return a *TimeoutError. If ctx is cancelled, return
+// ctx.Err(). A zero Timeout leaves the wait bounded only by ctx.
 //
 // Otherwise, the result is the result of the first call to the Refresh function to
 // reach the target state.
-func (conf *StateChangeConf) WaitForState() (interface{}, error) {
-	log.Printf("[DEBUG] Waiting for state to become: %s", conf.Target)
-
-	notfoundTick := 0
-	targetOccurence := 0
+//
+// Between refreshes it waits as long as the Backoff says. Without one it
+// backs off exponentially from 200ms up to 10s, bounded below by MinTimeout,
+// or waits PollInterval when that's set. conf isn't modified, so the same
+// configuration may be waited on concurrently.
+func (conf *StateChangeConf) WaitForStateContext(ctx context.Context) (interface{}, error) {
+	clock := conf.Clock
+	if clock == nil {
+		clock = realClock{}
+	}
 
 	// Set a default for times to check for not found
-	if conf.NotFoundChecks == 0 {
-		conf.NotFoundChecks = 20
+	notFoundChecks := conf.NotFoundChecks
+	if notFoundChecks == 0 {
+		notFoundChecks = 20
 	}
 
-	if conf.ContinuousTargetOccurence == 0 {
-		conf.ContinuousTargetOccurence = 1
+	continuousTargetOccurence := conf.ContinuousTargetOccurence
+	if continuousTargetOccurence == 0 {
+		continuousTargetOccurence = 1
 	}
 
-	type Result struct {
-		Result interface{}
-		State  string
-		Error  error
-		Done   bool
+	start := clock.Now()
+	var deadline time.Time
+	if conf.Timeout > 0 {
+		deadline = start.Add(conf.Timeout)
 	}
 
-	// Read every result from the refresh loop, waiting for a positive result.Done.
-	resCh := make(chan Result, 1)
-	// cancellation channel for the refresh loop
-	cancelCh := make(chan struct{})
-
-	result := Result{}
-
-	go func() {
-		defer close(resCh)
-
-		time.Sleep(conf.Delay)
-
-		// start with 0 delay for the first loop
-		var wait time.Duration
-
-		for {
-			// store the last result
-			resCh <- result
+	var lastState string
+	timeoutError := func(err error) error {
+		return &TimeoutError{
+			LastError:     err,
+			LastState:     lastState,
+			Timeout:       conf.Timeout,
+			ExpectedState: conf.Target,
+		}
+	}
 
-			// wait and watch for cancellation
+	// sleep waits for d on the clock, returning early with an error when
+	// the timeout or ctx ends the wait first.
+	sleep := func(d time.Duration) error {
+		timedOut := false
+		if !deadline.IsZero() {
+			if remaining := deadline.Sub(clock.Now()); remaining <= d {
+				d, timedOut = remaining, true
+			}
+		}
+		if d > 0 {
 			select {
-			case <-cancelCh:
-				return
-			case <-time.After(wait):
-				// first round had no wait
-				if wait == 0 {
-					wait = 100 * time.Millisecond
+			case <-ctx.Done():
+				if ctx.Err() == context.DeadlineExceeded {
+					return timeoutError(ctx.Err())
 				}
+				return ctx.Err()
+			case <-clock.After(d):
 			}
+		}
+		if timedOut {
+			return timeoutError(nil)
+		}
+		return nil
+	}
 
-			res, currentState, err := conf.Refresh()
-			result = Result{
-				Result: res,
-				State:  currentState,
-				Error:  err,
-			}
+	if err := sleep(conf.Delay); err != nil {
+		return nil, err
+	}
+
+	notfoundTick := 0
+	targetOccurence := 0
+	wait := 100 * time.Millisecond
 
-			if err != nil {
-				resCh <- result
-				return
+	for attempt := 1; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			if err == context.DeadlineExceeded {
+				return nil, timeoutError(err)
 			}
+			return nil, err
+		}
+
+		res, currentState, err := conf.Refresh()
+		if err != nil {
+			return res, err
+		}
+		lastState = currentState
 
+		if res == nil && len(conf.Target) == 0 {
 			// If we're waiting for the absence of a thing, then return
-			if res == nil && len(conf.Target) == 0 {
-				targetOccurence++
-				if conf.ContinuousTargetOccurence == targetOccurence {
-					result.Done = true
-					resCh <- result
-					return
-				}
-				continue
+			targetOccurence++
+			if continuousTargetOccurence == targetOccurence {
+				return res, nil
 			}
-
+		} else {
 			if res == nil {
 				// If we didn't find the resource, check if we have been
 				// not finding it for awhile, and if so, report an error.
 				notfoundTick++
-				if notfoundTick > conf.NotFoundChecks {
-					result.Error = &NotFoundError{
-						LastError: err,
-						Retries:   notfoundTick,
+				if notfoundTick > notFoundChecks {
+					return nil, &NotFoundError{
+						Retries: notfoundTick,
 					}
-					resCh <- result
-					return
 				}
 			} else {
 				// Reset the counter for when a resource isn't found
@@ -144,10 +182,8 @@ func (conf *StateChangeConf) WaitForState() (interface{}, error) {
 					if currentState == allowed {
 						found = true
 						targetOccurence++
-						if conf.ContinuousTargetOccurence == targetOccurence {
-							result.Done = true
-							resCh <- result
-							return
+						if continuousTargetOccurence == targetOccurence {
+							return res, nil
 						}
 						continue
 					}
@@ -162,13 +198,10 @@ func (conf *StateChangeConf) WaitForState() (interface{}, error) {
 				}
 
 				if !found && len(conf.Pending) > 0 {
-					result.Error = &UnexpectedStateError{
-						LastError:     err,
-						State:         result.State,
+					return res, &UnexpectedStateError{
+						State:         currentState,
 						ExpectedState: conf.Target,
 					}
-					resCh <- result
-					return
 				}
 			}
 
@@ -189,71 +222,24 @@ func (conf *StateChangeConf) WaitForState() (interface{}, error) {
 					wait = 10 * time.Second
 				}
 			}
-
-			log.Printf("[TRACE] Waiting %s before next try", wait)
 		}
-	}()
-
-	// store the last value result from the refresh loop
-	lastResult := Result{}
 
-	timeout := time.After(conf.Timeout)
-	for {
-		select {
-		case r, ok := <-resCh:
-			// channel closed, so return the last result
-			if !ok {
-				return lastResult.Result, lastResult.Error
-			}
-
-			// we reached the intended state
-			if r.Done {
-				return r.Result, r.Error
-			}
-
-			// still waiting, store the last result
-			lastResult = r
-
-		case <-timeout:
-			log.Printf("[WARN] WaitForState timeout after %s", conf.Timeout)
-			log.Printf("[WARN] WaitForState starting %s refresh grace period", refreshGracePeriod)
-
-			// cancel the goroutine and start our grace period timer
-			close(cancelCh)
-			timeout := time.After(refreshGracePeriod)
-
-			// we need a for loop and a label to break on, because we may have
-			// an extra response value to read, but still want to wait for the
-			// channel to close.
-		forSelect:
-			for {
-				select {
-				case r, ok := <-resCh:
-					if r.Done {
-						// the last refresh loop reached the desired state
-						return r.Result, r.Error
-					}
-
-					if !ok {
-						// the goroutine returned
-						break forSelect
-					}
-
-					// target state not reached, save the result for the
-					// TimeoutError and wait for the channel to close
-					lastResult = r
-				case <-timeout:
-					log.Println("[ERROR] WaitForState exceeded refresh grace period")
-					break forSelect
-				}
-			}
+		next := wait
+		if conf.Backoff != nil {
+			next = conf.Backoff.Next(attempt, res)
+		}
+		if conf.Progress != nil {
+			conf.Progress(Progress{
+				Attempt:  attempt,
+				Result:   res,
+				State:    currentState,
+				Elapsed:  clock.Now().Sub(start),
+				NextWait: next,
+			})
+		}
 
-			return nil, &TimeoutError{
-				LastError:     lastResult.Error,
-				LastState:     lastResult.State,
-				Timeout:       conf.Timeout,
-				ExpectedState: conf.Target,
-			}
+		if err := sleep(next); err != nil {
+			return nil, err
 		}
 	}
 }
@@ -0,0 +1,311 @@
+package resource
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// refreshStep is one scripted answer of a StateRefreshFunc.
+type refreshStep struct {
+	result interface{}
+	state  string
+	err    error
+}
+
+func found(state string) refreshStep { return refreshStep{result: state, state: state} }
+
+var gone = refreshStep{}
+
+// scriptedRefresh answers with steps in order and repeats the last one once
+// they've run out. calls counts the refreshes made.
+func scriptedRefresh(calls *int, steps ...refreshStep) StateRefreshFunc {
+	return func() (interface{}, string, error) {
+		step := steps[len(steps)-1]
+		if *calls < len(steps) {
+			step = steps[*calls]
+		}
+		*calls++
+		return step.result, step.state, step.err
+	}
+}
+
+func TestWaitForState(t *testing.T) {
+	refreshErr := errors.New("refresh failed")
+
+	cases := []struct {
+		name   string
+		conf   StateChangeConf
+		steps  []refreshStep
+		result interface{}
+		calls  int
+		check  func(t *testing.T, err error)
+	}{
+		{
+			name:   "target reached immediately",
+			conf:   StateChangeConf{Pending: []string{"Creating"}, Target: []string{"Succeeded"}},
+			steps:  []refreshStep{found("Succeeded")},
+			result: "Succeeded",
+			calls:  1,
+		},
+		{
+			name:   "pending until target",
+			conf:   StateChangeConf{Pending: []string{"Creating", "Updating"}, Target: []string{"Succeeded"}},
+			steps:  []refreshStep{found("Creating"), found("Updating"), found("Creating"), found("Succeeded")},
+			result: "Succeeded",
+			calls:  4,
+		},
+		{
+			name:   "unknown state without pending states keeps waiting",
+			conf:   StateChangeConf{Target: []string{"Succeeded"}},
+			steps:  []refreshStep{found("Whatever"), found("Succeeded")},
+			result: "Succeeded",
+			calls:  2,
+		},
+		{
+			name:   "unexpected state",
+			conf:   StateChangeConf{Pending: []string{"Creating"}, Target: []string{"Succeeded"}},
+			steps:  []refreshStep{found("Creating"), found("Failed")},
+			result: "Failed",
+			calls:  2,
+			check: func(t *testing.T, err error) {
+				var unexpected *UnexpectedStateError
+				if !errors.As(err, &unexpected) {
+					t.Fatalf("expected an UnexpectedStateError, got %v", err)
+				}
+				if unexpected.State != "Failed" || len(unexpected.ExpectedState) != 1 || unexpected.ExpectedState[0] != "Succeeded" {
+					t.Errorf("unexpected error details: %+v", unexpected)
+				}
+			},
+		},
+		{
+			name:   "refresh error is returned immediately",
+			conf:   StateChangeConf{Pending: []string{"Creating"}, Target: []string{"Succeeded"}},
+			steps:  []refreshStep{found("Creating"), {result: "partial", err: refreshErr}},
+			result: "partial",
+			calls:  2,
+			check: func(t *testing.T, err error) {
+				if err != refreshErr {
+					t.Errorf("expected the refresh error, got %v", err)
+				}
+			},
+		},
+		{
+			name:  "not found exhausted",
+			conf:  StateChangeConf{Pending: []string{"Creating"}, Target: []string{"Succeeded"}, NotFoundChecks: 3},
+			steps: []refreshStep{gone},
+			calls: 4,
+			check: func(t *testing.T, err error) {
+				var notFound *NotFoundError
+				if !errors.As(err, &notFound) {
+					t.Fatalf("expected a NotFoundError, got %v", err)
+				}
+				if notFound.Retries != 4 {
+					t.Errorf("expected 4 retries, got %d", notFound.Retries)
+				}
+			},
+		},
+		{
+			name:   "not found counter resets when the resource appears",
+			conf:   StateChangeConf{Pending: []string{"Creating"}, Target: []string{"Succeeded"}, NotFoundChecks: 2},
+			steps:  []refreshStep{gone, gone, found("Creating"), gone, gone, found("Succeeded")},
+			result: "Succeeded",
+			calls:  6,
+		},
+		{
+			name:  "default not found checks",
+			conf:  StateChangeConf{Target: []string{"Succeeded"}},
+			steps: []refreshStep{gone},
+			calls: 21,
+			check: func(t *testing.T, err error) {
+				var notFound *NotFoundError
+				if !errors.As(err, &notFound) {
+					t.Fatalf("expected a NotFoundError, got %v", err)
+				}
+			},
+		},
+		{
+			name:  "waiting for absence",
+			conf:  StateChangeConf{Pending: []string{"Deleting"}},
+			steps: []refreshStep{found("Deleting"), found("Deleting"), gone},
+			calls: 3,
+		},
+		{
+			name:  "continuous absence",
+			conf:  StateChangeConf{ContinuousTargetOccurence: 3},
+			steps: []refreshStep{gone},
+			calls: 3,
+		},
+		{
+			name: "continuous target",
+			conf: StateChangeConf{Pending: []string{"Provisioning"}, Target: []string{"Provisioned"}, ContinuousTargetOccurence: 3},
+			steps: []refreshStep{
+				found("Provisioned"), found("Provisioned"), found("Provisioning"),
+				found("Provisioned"), found("Provisioned"), found("Provisioned"),
+			},
+			result: "Provisioned",
+			calls:  6,
+		},
+		{
+			name:  "timeout with last state",
+			conf:  StateChangeConf{Pending: []string{"Creating"}, Target: []string{"Succeeded"}, Timeout: 5 * time.Minute},
+			steps: []refreshStep{found("Creating")},
+			calls: 35,
+			check: func(t *testing.T, err error) {
+				var timeout *TimeoutError
+				if !errors.As(err, &timeout) {
+					t.Fatalf("expected a TimeoutError, got %v", err)
+				}
+				if timeout.LastState != "Creating" || timeout.Timeout != 5*time.Minute || timeout.LastError != nil {
+					t.Errorf("unexpected error details: %+v", timeout)
+				}
+			},
+		},
+		{
+			name:  "timeout while not found",
+			conf:  StateChangeConf{Target: []string{"Succeeded"}, Timeout: 10 * time.Second, PollInterval: time.Second, NotFoundChecks: 100},
+			steps: []refreshStep{gone},
+			calls: 10,
+			check: func(t *testing.T, err error) {
+				var timeout *TimeoutError
+				if !errors.As(err, &timeout) {
+					t.Fatalf("expected a TimeoutError, got %v", err)
+				}
+				if want := "timeout while waiting for state to become 'Succeeded' (timeout: 10s)"; err.Error() != want {
+					t.Errorf("expected %q, got %q", want, err.Error())
+				}
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			calls := 0
+			conf := c.conf
+			conf.Refresh = scriptedRefresh(&calls, c.steps...)
+			conf.Clock = NewFakeClock(time.Now())
+
+			result, err := conf.WaitForState()
+			if c.check != nil {
+				c.check(t, err)
+			} else if err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
+			if result != c.result {
+				t.Errorf("expected result %v, got %v", c.result, result)
+			}
+			if calls != c.calls {
+				t.Errorf("expected %d refreshes, got %d", c.calls, calls)
+			}
+		})
+	}
+}
+
+func TestWaitForStateBackoff(t *testing.T) {
+	cases := []struct {
+		name  string
+		conf  StateChangeConf
+		steps []refreshStep
+		waits []time.Duration
+	}{
+		{
+			name:  "exponential up to 10s",
+			conf:  StateChangeConf{Pending: []string{"Creating"}, Target: []string{"Succeeded"}},
+			steps: append(repeat(found("Creating"), 8), found("Succeeded")),
+			waits: durations("200ms", "400ms", "800ms", "1.6s", "3.2s", "6.4s", "10s", "10s"),
+		},
+		{
+			name:  "delay before the first refresh",
+			conf:  StateChangeConf{Pending: []string{"Creating"}, Target: []string{"Succeeded"}, Delay: 3 * time.Second},
+			steps: []refreshStep{found("Creating"), found("Succeeded")},
+			waits: durations("3s", "200ms"),
+		},
+		{
+			name:  "min timeout",
+			conf:  StateChangeConf{Pending: []string{"Creating"}, Target: []string{"Succeeded"}, MinTimeout: time.Second},
+			steps: append(repeat(found("Creating"), 5), found("Succeeded")),
+			waits: durations("1s", "2s", "4s", "8s", "10s"),
+		},
+		{
+			name:  "poll interval overrides backoff",
+			conf:  StateChangeConf{Pending: []string{"Creating"}, Target: []string{"Succeeded"}, PollInterval: 15 * time.Second, MinTimeout: time.Second},
+			steps: append(repeat(found("Creating"), 3), found("Succeeded")),
+			waits: durations("15s", "15s", "15s"),
+		},
+		{
+			name:  "poll interval of 3 minutes or more is ignored",
+			conf:  StateChangeConf{Pending: []string{"Creating"}, Target: []string{"Succeeded"}, PollInterval: 3 * time.Minute},
+			steps: append(repeat(found("Creating"), 3), found("Succeeded")),
+			waits: durations("200ms", "400ms", "800ms"),
+		},
+		{
+			name:  "no doubling while the target reoccurs",
+			conf:  StateChangeConf{Pending: []string{"Creating"}, Target: []string{"Succeeded"}, ContinuousTargetOccurence: 3},
+			steps: []refreshStep{found("Creating"), found("Succeeded"), found("Succeeded"), found("Succeeded")},
+			waits: durations("200ms", "200ms", "200ms"),
+		},
+		{
+			name:  "no backoff while waiting for absence",
+			conf:  StateChangeConf{ContinuousTargetOccurence: 3},
+			steps: []refreshStep{gone},
+			waits: durations("100ms", "100ms"),
+		},
+		{
+			name:  "timeout cuts the last wait short",
+			conf:  StateChangeConf{Pending: []string{"Creating"}, Target: []string{"Succeeded"}, PollInterval: 4 * time.Second, Timeout: 10 * time.Second},
+			steps: []refreshStep{found("Creating")},
+			waits: durations("4s", "4s", "2s"),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			calls := 0
+			clock := NewFakeClock(time.Now())
+			conf := c.conf
+			conf.Refresh = scriptedRefresh(&calls, c.steps...)
+			conf.Clock = clock
+
+			conf.WaitForState()
+			if got := clock.Waits(); fmt.Sprint(got) != fmt.Sprint(c.waits) {
+				t.Errorf("expected waits %v, got %v", c.waits, got)
+			}
+		})
+	}
+}
+
+func TestWaitForStateDoesNotModifyConf(t *testing.T) {
+	calls := 0
+	conf := &StateChangeConf{
+		Target:  []string{"Succeeded"},
+		Refresh: scriptedRefresh(&calls, found("Succeeded")),
+		Clock:   NewFakeClock(time.Now()),
+	}
+	if _, err := conf.WaitForState(); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if conf.NotFoundChecks != 0 || conf.ContinuousTargetOccurence != 0 {
+		t.Errorf("expected defaults not to be written back, got %+v", conf)
+	}
+}
+
+func repeat(step refreshStep, n int) []refreshStep {
+	steps := make([]refreshStep, n)
+	for i := range steps {
+		steps[i] = step
+	}
+	return steps
+}
+
+func durations(values ...string) []time.Duration {
+	result := make([]time.Duration, len(values))
+	for i, v := range values {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			panic(err)
+		}
+		result[i] = d
+	}
+	return result
+}
@@ -0,0 +1,67 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// Package hybrid sets up the clients of the hybrid samples, which use the
+// SDK's API profile for Azure Stack Hub so they run against it as well as
+// against public Azure. Clients are created from the endpoints of the
+// configured cloud, see `config.Environment()`:
+//
+//	vmClient := compute.NewVirtualMachinesClientWithBaseURI(hybrid.Endpoint(ctx))
+//	hybrid.Configure(&vmClient.Client)
+package hybrid
+
+import (
+	"context"
+	"log"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
+	"github.com/Azure/go-autorest/autorest"
+)
+
+// Profile is the API profile the hybrid samples import their SDK packages
+// from, `github.com/Azure/azure-sdk-for-go/profiles/<Profile>`. It's
+// supported by Azure Stack Hub 1904 and later.
+const Profile = "2019-03-01"
+
+// Endpoint returns the Resource Manager endpoint of the configured cloud
+// and the subscription of ctx, the arguments of the profile's
+// `New...ClientWithBaseURI` functions. The sample exits if the cloud can't
+// be loaded.
+func Endpoint(ctx context.Context) (baseURI, subscriptionID string) {
+	env, err := config.Environment()
+	if err != nil {
+		log.Fatalf("failed to get the cloud environment: %v\n", err)
+	}
+	return env.ResourceManagerEndpoint, config.ScopeFrom(ctx).SubscriptionID
+}
+
+// Configure sets the authorizer, user agent and sender of a Resource
+// Manager client. The sample exits if no token can be had.
+func Configure(client *autorest.Client) {
+	a, err := iam.GetResourceManagementAuthorizer()
+	if err != nil {
+		log.Fatalf("failed to initialize authorizer: %v\n", err)
+	}
+	configure(client, a)
+}
+
+// ConfigureKeyvault sets the authorizer, user agent and sender of a Key
+// Vault keys and secrets client. Its tokens are issued for each vault's
+// own resource, as Azure Stack Hub's differs from public Azure's.
+func ConfigureKeyvault(client *autorest.Client) {
+	a, err := iam.GetKeyvaultAuthorizer()
+	if err != nil {
+		log.Fatalf("failed to initialize authorizer: %v\n", err)
+	}
+	configure(client, a)
+}
+
+func configure(client *autorest.Client, a autorest.Authorizer) {
+	client.Authorizer = a
+	client.AddToUserAgent(config.UserAgent())
+	client.Sender = recording.Sender()
+}
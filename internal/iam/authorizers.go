@@ -7,13 +7,13 @@ package iam
 
 import (
 	"fmt"
-	"net/url"
-	"strings"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 
 	"github.com/Azure/go-autorest/autorest"
 	"github.com/Azure/go-autorest/autorest/adal"
+	"github.com/Azure/go-autorest/autorest/azure"
 	"github.com/Azure/go-autorest/autorest/azure/auth"
 )
 
@@ -22,6 +22,7 @@ var (
 	batchAuthorizer    autorest.Authorizer
 	graphAuthorizer    autorest.Authorizer
 	keyvaultAuthorizer autorest.Authorizer
+	storageAuthorizer  autorest.Authorizer
 )
 
 // OAuthGrantType specifies which grant type to use.
@@ -32,14 +33,32 @@ const (
 	OAuthGrantTypeServicePrincipal OAuthGrantType = iota
 	// OAuthGrantTypeDeviceFlow for device flow
 	OAuthGrantTypeDeviceFlow
+	// OAuthGrantTypeManagedIdentity for a system or user-assigned managed identity
+	OAuthGrantTypeManagedIdentity
+	// OAuthGrantTypeCLI for the Azure CLI token cache
+	OAuthGrantTypeCLI
+	// OAuthGrantTypeCertificate for client credentials with a certificate
+	OAuthGrantTypeCertificate
+	// OAuthGrantTypeFederated for client credentials with a federated JWT assertion
+	OAuthGrantTypeFederated
 )
 
+// grantTypes maps each `config.AuthMethod()` to its grant type.
+var grantTypes = map[string]OAuthGrantType{
+	config.AuthMethodClientSecret:    OAuthGrantTypeServicePrincipal,
+	config.AuthMethodDeviceFlow:      OAuthGrantTypeDeviceFlow,
+	config.AuthMethodManagedIdentity: OAuthGrantTypeManagedIdentity,
+	config.AuthMethodCLI:             OAuthGrantTypeCLI,
+	config.AuthMethodCertificate:     OAuthGrantTypeCertificate,
+	config.AuthMethodFederated:       OAuthGrantTypeFederated,
+}
+
 // GrantType returns what grant type has been configured.
 func grantType() OAuthGrantType {
-	if config.UseDeviceFlow() {
-		return OAuthGrantTypeDeviceFlow
+	if grantType, ok := grantTypes[config.AuthMethod()]; ok {
+		return grantType
 	}
-	return OAuthGrantTypeServicePrincipal
+	return -1
 }
 
 // GetResourceManagementAuthorizer gets an OAuthTokenAuthorizer for Azure Resource Manager
@@ -48,11 +67,13 @@ func GetResourceManagementAuthorizer() (autorest.Authorizer, error) {
 		return armAuthorizer, nil
 	}
 
-	var a autorest.Authorizer
-	var err error
+	env, err := config.Environment()
+	if err != nil {
+		return nil, err
+	}
 
-	a, err = getAuthorizerForResource(
-		grantType(), config.Environment().ResourceManagerEndpoint)
+	// Azure Stack expects tokens for its audience rather than its endpoint
+	a, err := getAuthorizerForResource(grantType(), env.TokenAudience)
 
 	if err == nil {
 		// cache
@@ -70,11 +91,12 @@ func GetBatchAuthorizer() (autorest.Authorizer, error) {
 		return batchAuthorizer, nil
 	}
 
-	var a autorest.Authorizer
-	var err error
+	env, err := config.Environment()
+	if err != nil {
+		return nil, err
+	}
 
-	a, err = getAuthorizerForResource(
-		grantType(), config.Environment().BatchManagementEndpoint)
+	a, err := getAuthorizerForResource(grantType(), env.BatchManagementEndpoint)
 
 	if err == nil {
 		// cache
@@ -93,10 +115,12 @@ func GetGraphAuthorizer() (autorest.Authorizer, error) {
 		return graphAuthorizer, nil
 	}
 
-	var a autorest.Authorizer
-	var err error
+	env, err := config.Environment()
+	if err != nil {
+		return nil, err
+	}
 
-	a, err = getAuthorizerForResource(grantType(), config.Environment().GraphEndpoint)
+	a, err := getAuthorizerForResource(grantType(), env.GraphEndpoint)
 
 	if err == nil {
 		// cache
@@ -108,59 +132,76 @@ func GetGraphAuthorizer() (autorest.Authorizer, error) {
 	return graphAuthorizer, err
 }
 
-// GetKeyvaultAuthorizer gets an OAuthTokenAuthorizer for use with Key Vault
-// keys and secrets. Note that Key Vault *Vaults* are managed by Azure Resource
-// Manager.
+// GetKeyvaultAuthorizer gets an authorizer for use with Key Vault keys and
+// secrets. It learns the tenant and resource of each vault from the vault's
+// bearer challenge, so it works for vaults in any cloud. Note that Key Vault
+// *Vaults* are managed by Azure Resource Manager.
 func GetKeyvaultAuthorizer() (autorest.Authorizer, error) {
 	if keyvaultAuthorizer != nil {
 		return keyvaultAuthorizer, nil
 	}
 
-	// BUG: default value for KeyVaultEndpoint is wrong
-	vaultEndpoint := strings.TrimSuffix(config.Environment().KeyVaultEndpoint, "/")
-	// BUG: alternateEndpoint replaces other endpoints in the configs below
-	alternateEndpoint, _ := url.Parse(
-		"https://login.windows.net/" + config.TenantID() + "/oauth2/token")
-
-	var a autorest.Authorizer
-	var err error
+	// recorded responses don't need a token
+	if recording.GetMode() == recording.Playback {
+		return autorest.NullAuthorizer{}, nil
+	}
 
-	switch grantType() {
-	case OAuthGrantTypeServicePrincipal:
-		oauthconfig, err := adal.NewOAuthConfig(
-			config.Environment().ActiveDirectoryEndpoint, config.TenantID())
-		if err != nil {
-			return a, err
-		}
-		oauthconfig.AuthorizeEndpoint = *alternateEndpoint
+	grantType := grantType()
+	keyvaultAuthorizer = NewKeyvaultAuthorizer(
+		func(activeDirectoryEndpoint, tenantID, resource string) (autorest.Authorizer, error) {
+			return getAuthorizerForTenant(grantType, activeDirectoryEndpoint, tenantID, resource)
+		})
+	return keyvaultAuthorizer, nil
+}
 
-		token, err := adal.NewServicePrincipalToken(
-			*oauthconfig, config.ClientID(), config.ClientSecret(), vaultEndpoint)
-		if err != nil {
-			return a, err
-		}
+// GetStorageAuthorizer gets an OAuthTokenAuthorizer for the data plane of
+// Azure Storage, such as blobs. The identity needs a data role on the
+// account, e.g. Storage Blob Data Contributor, which management roles don't
+// grant.
+func GetStorageAuthorizer() (autorest.Authorizer, error) {
+	if storageAuthorizer != nil {
+		return storageAuthorizer, nil
+	}
 
-		a = autorest.NewBearerAuthorizer(token)
+	env, err := config.Environment()
+	if err != nil {
+		return nil, err
+	}
 
-	case OAuthGrantTypeDeviceFlow:
-		deviceConfig := auth.NewDeviceFlowConfig(config.ClientID(), config.TenantID())
-		deviceConfig.Resource = vaultEndpoint
-		deviceConfig.AADEndpoint = alternateEndpoint.String()
-		a, err = deviceConfig.Authorizer()
-	default:
-		return a, fmt.Errorf("invalid grant type specified")
+	// clouds loaded from metadata endpoints may not name the resource
+	resource := env.ResourceIdentifiers.Storage
+	if resource == "" || resource == azure.NotAvailable {
+		resource = azure.PublicCloud.ResourceIdentifiers.Storage
 	}
+	a, err := getAuthorizerForResource(grantType(), resource)
 
 	if err == nil {
-		keyvaultAuthorizer = a
+		// cache
+		storageAuthorizer = a
 	} else {
-		keyvaultAuthorizer = nil
+		storageAuthorizer = nil
 	}
 
-	return keyvaultAuthorizer, err
+	return storageAuthorizer, err
 }
 
 func getAuthorizerForResource(grantType OAuthGrantType, resource string) (autorest.Authorizer, error) {
+	// recorded responses don't need a token
+	if recording.GetMode() == recording.Playback {
+		return autorest.NullAuthorizer{}, nil
+	}
+
+	env, err := config.Environment()
+	if err != nil {
+		return nil, err
+	}
+	return getAuthorizerForTenant(grantType, env.ActiveDirectoryEndpoint, config.TenantID(), resource)
+}
+
+// getAuthorizerForTenant gets an authorizer for tokens for resource issued by
+// the tenant of an Active Directory endpoint, which needn't be the
+// configured one.
+func getAuthorizerForTenant(grantType OAuthGrantType, activeDirectoryEndpoint, tenantID, resource string) (autorest.Authorizer, error) {
 	var a autorest.Authorizer
 	var err error
 
@@ -168,7 +209,7 @@ func getAuthorizerForResource(grantType OAuthGrantType, resource string) (autore
 
 	case OAuthGrantTypeServicePrincipal:
 		oauthConfig, err := adal.NewOAuthConfig(
-			config.Environment().ActiveDirectoryEndpoint, config.TenantID())
+			activeDirectoryEndpoint, tenantID)
 		if err != nil {
 			return nil, err
 		}
@@ -181,32 +222,63 @@ func getAuthorizerForResource(grantType OAuthGrantType, resource string) (autore
 		a = autorest.NewBearerAuthorizer(token)
 
 	case OAuthGrantTypeDeviceFlow:
-		deviceconfig := auth.NewDeviceFlowConfig(config.ClientID(), config.TenantID())
+		deviceconfig := auth.NewDeviceFlowConfig(config.ClientID(), tenantID)
 		deviceconfig.Resource = resource
+		deviceconfig.AADEndpoint = activeDirectoryEndpoint
 		a, err = deviceconfig.Authorizer()
 		if err != nil {
 			return nil, err
 		}
 
+	case OAuthGrantTypeManagedIdentity:
+		// the identity's tenant is implied
+		token, err := adal.NewServicePrincipalTokenFromManagedIdentity(
+			resource, &adal.ManagedIdentityOptions{ClientID: config.ClientID()})
+		if err != nil {
+			return nil, err
+		}
+		a = autorest.NewBearerAuthorizer(token)
+
+	case OAuthGrantTypeCLI:
+		// the CLI gets tokens from the tenant of the signed-in account
+		a, err = auth.NewAuthorizerFromCLIWithResource(resource)
+		if err != nil {
+			return nil, err
+		}
+
+	case OAuthGrantTypeCertificate:
+		oauthConfig, err := adal.NewOAuthConfig(activeDirectoryEndpoint, tenantID)
+		if err != nil {
+			return nil, err
+		}
+		certificate, key, err := loadCertificate(config.CertificatePath(), config.CertificatePassword())
+		if err != nil {
+			return nil, err
+		}
+
+		token, err := adal.NewServicePrincipalTokenFromCertificate(
+			*oauthConfig, config.ClientID(), certificate, key, resource)
+		if err != nil {
+			return nil, err
+		}
+		a = autorest.NewBearerAuthorizer(token)
+
+	case OAuthGrantTypeFederated:
+		oauthConfig, err := adal.NewOAuthConfig(activeDirectoryEndpoint, tenantID)
+		if err != nil {
+			return nil, err
+		}
+
+		token, err := adal.NewServicePrincipalTokenWithSecret(
+			*oauthConfig, config.ClientID(), resource, &federatedSecret{config.FederatedTokenFile()})
+		if err != nil {
+			return nil, err
+		}
+		a = autorest.NewBearerAuthorizer(token)
+
 	default:
 		return a, fmt.Errorf("invalid grant type specified")
 	}
 
 	return a, err
 }
-
-// GetResourceManagementTokenHybrid retrieves auth token for hybrid environment
-func GetResourceManagementTokenHybrid(activeDirectoryEndpoint, tokenAudience string) (adal.OAuthTokenProvider, error) {
-	oauthConfig, err := adal.NewOAuthConfig(activeDirectoryEndpoint, config.TenantID())
-	if err != nil {
-		return nil, err
-	}
-
-	tokenProvider, err := adal.NewServicePrincipalToken(
-		*oauthConfig,
-		config.ClientID(),
-		config.ClientSecret(),
-		tokenAudience)
-
-	return tokenProvider, err
-}
@@ -0,0 +1,158 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package iam
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
+	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+)
+
+var (
+	// the credential and connection shared by all track 2 clients, guarded
+	// by connectionMu. Both are created on first use.
+	connectionMu      sync.Mutex
+	credential        azcore.TokenCredential
+	connectionOptions armcore.ConnectionOptions
+	connection        *armcore.Connection
+)
+
+// SetCredential replaces the credential used by GetConnection, e.g. with a
+// client secret or managed identity credential. Connections created after
+// this call use the new credential.
+func SetCredential(cred azcore.TokenCredential) {
+	connectionMu.Lock()
+	defer connectionMu.Unlock()
+	credential = cred
+	connection = nil
+}
+
+// SetConnectionOptions replaces the pipeline options, such as retry, logging
+// and telemetry, used by GetConnection. When options.HTTPClient is nil
+// requests are sent through the recording transport.
+func SetConnectionOptions(options armcore.ConnectionOptions) {
+	connectionMu.Lock()
+	defer connectionMu.Unlock()
+	connectionOptions = options
+	connection = nil
+}
+
+// GetCredential returns the shared credential, creating one for the configured
+// grant type the first time it's called.
+func GetCredential() (azcore.TokenCredential, error) {
+	connectionMu.Lock()
+	defer connectionMu.Unlock()
+	return getCredential()
+}
+
+func getCredential() (azcore.TokenCredential, error) {
+	if credential != nil {
+		return credential, nil
+	}
+	cred, err := newCredential()
+	if err != nil {
+		return nil, err
+	}
+	credential = cred
+	return credential, nil
+}
+
+// GetConnection returns the Azure Resource Manager connection shared by all
+// track 2 clients. It's created once and is safe for concurrent use.
+func GetConnection() (*armcore.Connection, error) {
+	connectionMu.Lock()
+	defer connectionMu.Unlock()
+
+	if connection != nil {
+		return connection, nil
+	}
+	env, err := config.Environment()
+	if err != nil {
+		return nil, err
+	}
+	cred, err := getCredential()
+	if err != nil {
+		return nil, err
+	}
+
+	options := connectionOptions
+	if options.HTTPClient == nil {
+		options.HTTPClient = recording.Transport()
+	}
+	if options.Telemetry.Value == "" {
+		options.Telemetry.Value = config.UserAgent()
+	}
+	connection = armcore.NewConnection(env.ResourceManagerEndpoint, audienceCredential{cred, env.TokenAudience}, &options)
+	return connection, nil
+}
+
+// audienceCredential requests tokens for the audience of the cloud, which
+// on Azure Stack differs from the Resource Manager endpoint that armcore
+// derives the token scope from.
+type audienceCredential struct {
+	azcore.TokenCredential
+	audience string
+}
+
+func (c audienceCredential) AuthenticationPolicy(options azcore.AuthenticationPolicyOptions) azcore.Policy {
+	options.Options.Scopes = []string{strings.TrimSuffix(c.audience, "/") + "/.default"}
+	return c.TokenCredential.AuthenticationPolicy(options)
+}
+
+// newCredential returns a credential for the configured grant type, which
+// authenticates against the Active Directory endpoint of the cloud. Without
+// a known grant type it returns the default credential.
+func newCredential() (azcore.TokenCredential, error) {
+	if recording.GetMode() == recording.Playback {
+		return recording.Credential()
+	}
+	env, err := config.Environment()
+	if err != nil {
+		return nil, err
+	}
+
+	switch grantType() {
+	case OAuthGrantTypeServicePrincipal:
+		if config.ClientSecret() == "" {
+			return recording.Credential()
+		}
+		return azidentity.NewClientSecretCredential(config.TenantID(), config.ClientID(), config.ClientSecret(),
+			&azidentity.ClientSecretCredentialOptions{AuthorityHost: env.ActiveDirectoryEndpoint})
+
+	case OAuthGrantTypeDeviceFlow:
+		return azidentity.NewDeviceCodeCredential(&azidentity.DeviceCodeCredentialOptions{
+			TenantID:      config.TenantID(),
+			ClientID:      config.ClientID(),
+			AuthorityHost: env.ActiveDirectoryEndpoint,
+		})
+
+	case OAuthGrantTypeManagedIdentity:
+		// the identity's tenant is implied
+		return azidentity.NewManagedIdentityCredential(config.ClientID(), nil)
+
+	case OAuthGrantTypeCLI:
+		// the CLI gets tokens from the tenant of the signed-in account
+		return azidentity.NewAzureCLICredential(nil)
+
+	case OAuthGrantTypeCertificate:
+		return azidentity.NewClientCertificateCredential(config.TenantID(), config.ClientID(), config.CertificatePath(),
+			&azidentity.ClientCertificateCredentialOptions{
+				Password:      config.CertificatePassword(),
+				AuthorityHost: env.ActiveDirectoryEndpoint,
+			})
+
+	case OAuthGrantTypeFederated:
+		return newFederatedCredential(env.ActiveDirectoryEndpoint, config.TenantID(), config.ClientID(), config.FederatedTokenFile())
+
+	default:
+		return recording.Credential()
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package iam
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+)
+
+type fakeCredential struct{}
+
+func (fakeCredential) GetToken(ctx context.Context, options azcore.TokenRequestOptions) (*azcore.AccessToken, error) {
+	return &azcore.AccessToken{Token: "fake"}, nil
+}
+
+func (fakeCredential) AuthenticationPolicy(options azcore.AuthenticationPolicyOptions) azcore.Policy {
+	return azcore.PolicyFunc(func(req *azcore.Request) (*azcore.Response, error) {
+		return req.Next()
+	})
+}
+
+func TestGetConnectionIsShared(t *testing.T) {
+	SetCredential(fakeCredential{})
+	defer SetCredential(nil)
+
+	var wg sync.WaitGroup
+	connections := make([]*armcore.Connection, 10)
+	for i := range connections {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			con, err := GetConnection()
+			if err != nil {
+				t.Errorf("failed to get connection: %+v", err)
+			}
+			connections[i] = con
+		}(i)
+	}
+	wg.Wait()
+
+	for _, con := range connections[1:] {
+		if con != connections[0] {
+			t.Fatalf("expected all callers to share one connection")
+		}
+	}
+
+	cred, err := GetCredential()
+	if err != nil {
+		t.Fatalf("failed to get credential: %+v", err)
+	}
+	if _, ok := cred.(fakeCredential); !ok {
+		t.Errorf("expected the injected credential, got %T", cred)
+	}
+}
+
+func TestSetConnectionOptionsResetsConnection(t *testing.T) {
+	SetCredential(fakeCredential{})
+	defer SetCredential(nil)
+
+	first, err := GetConnection()
+	if err != nil {
+		t.Fatalf("failed to get connection: %+v", err)
+	}
+	SetConnectionOptions(armcore.ConnectionOptions{Retry: azcore.RetryOptions{MaxRetries: 1}})
+	defer SetConnectionOptions(armcore.ConnectionOptions{})
+
+	second, err := GetConnection()
+	if err != nil {
+		t.Fatalf("failed to get connection: %+v", err)
+	}
+	if first == second {
+		t.Errorf("expected a new connection after changing options")
+	}
+}
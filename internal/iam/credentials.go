@@ -0,0 +1,151 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package iam
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/go-autorest/autorest/adal"
+)
+
+// loadCertificate reads a client certificate and its RSA private key from a
+// PEM file, or from a PFX file decrypted with password.
+func loadCertificate(path, password string) (*x509.Certificate, *rsa.PrivateKey, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot read certificate: %v", err)
+	}
+	if !bytes.Contains(data, []byte("-----BEGIN")) {
+		certificate, key, err := adal.DecodePfxCertificateData(data, password)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot decode PFX certificate %s: %v", path, err)
+		}
+		return certificate, key, nil
+	}
+
+	var certificate *x509.Certificate
+	var key *rsa.PrivateKey
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		switch block.Type {
+		case "CERTIFICATE":
+			// the first certificate is the client's, the rest its chain
+			if certificate == nil {
+				certificate, err = x509.ParseCertificate(block.Bytes)
+			}
+		case "RSA PRIVATE KEY":
+			key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+		case "PRIVATE KEY":
+			var parsed interface{}
+			parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+			if rsaKey, ok := parsed.(*rsa.PrivateKey); ok {
+				key = rsaKey
+			} else if err == nil {
+				err = fmt.Errorf("expected an RSA private key, got %T", parsed)
+			}
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot decode PEM certificate %s: %v", path, err)
+		}
+	}
+	if certificate == nil || key == nil {
+		return nil, nil, fmt.Errorf("PEM certificate %s must hold a certificate and its private key", path)
+	}
+	return certificate, key, nil
+}
+
+// federatedSecret authenticates a service principal with a JWT issued by a
+// trusted identity provider. The file is read for each token because the
+// provider rotates the JWT.
+type federatedSecret struct {
+	path string
+}
+
+// SetAuthenticationValues adds the JWT as the client assertion.
+func (s *federatedSecret) SetAuthenticationValues(spt *adal.ServicePrincipalToken, v *url.Values) error {
+	jwt, err := ioutil.ReadFile(s.path)
+	if err != nil {
+		return fmt.Errorf("cannot read federated token: %v", err)
+	}
+	v.Set("client_assertion", strings.TrimSpace(string(jwt)))
+	v.Set("client_assertion_type", "urn:ietf:params:oauth:client-assertion-type:jwt-bearer")
+	return nil
+}
+
+// federatedCredential is a track 2 credential for a federated JWT, which
+// azidentity can't use as a client assertion. It keeps an adal token per
+// resource, requested with federatedSecret.
+type federatedCredential struct {
+	oauthConfig adal.OAuthConfig
+	clientID    string
+	secret      *federatedSecret
+
+	mu     sync.Mutex
+	tokens map[string]*adal.ServicePrincipalToken
+}
+
+func newFederatedCredential(activeDirectoryEndpoint, tenantID, clientID, path string) (*federatedCredential, error) {
+	oauthConfig, err := adal.NewOAuthConfig(activeDirectoryEndpoint, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return &federatedCredential{
+		oauthConfig: *oauthConfig,
+		clientID:    clientID,
+		secret:      &federatedSecret{path},
+		tokens:      map[string]*adal.ServicePrincipalToken{},
+	}, nil
+}
+
+// GetToken returns a token for the resource of the first scope.
+func (c *federatedCredential) GetToken(ctx context.Context, options azcore.TokenRequestOptions) (*azcore.AccessToken, error) {
+	if len(options.Scopes) == 0 {
+		return nil, fmt.Errorf("no scope to request a federated token for")
+	}
+	resource := strings.TrimSuffix(options.Scopes[0], "/.default")
+
+	c.mu.Lock()
+	token, ok := c.tokens[resource]
+	if !ok {
+		var err error
+		token, err = adal.NewServicePrincipalTokenWithSecret(c.oauthConfig, c.clientID, resource, c.secret)
+		if err != nil {
+			c.mu.Unlock()
+			return nil, err
+		}
+		c.tokens[resource] = token
+	}
+	c.mu.Unlock()
+
+	// the token refreshes under its own lock
+	if err := token.EnsureFreshWithContext(ctx); err != nil {
+		return nil, err
+	}
+	t := token.Token()
+	return &azcore.AccessToken{Token: t.AccessToken, ExpiresOn: t.Expires()}, nil
+}
+
+// AuthenticationPolicy returns a policy adding a bearer token for the
+// options' scopes to each request.
+func (c *federatedCredential) AuthenticationPolicy(options azcore.AuthenticationPolicyOptions) azcore.Policy {
+	return azcore.PolicyFunc(func(req *azcore.Request) (*azcore.Response, error) {
+		token, err := c.GetToken(req.Context(), options.Options)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set(azcore.HeaderAuthorization, "Bearer "+token.Token)
+		return req.Next()
+	})
+}
@@ -0,0 +1,146 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package iam
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+)
+
+func TestLoadCertificatePEM(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iam")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "go-samples"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, keyBlock := range map[string]*pem.Block{
+		"pkcs1.pem": {Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)},
+		"pkcs8.pem": {Type: "PRIVATE KEY", Bytes: pkcs8},
+	} {
+		path := filepath.Join(dir, name)
+		data := append(pem.EncodeToMemory(keyBlock), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+		if err := ioutil.WriteFile(path, data, 0600); err != nil {
+			t.Fatal(err)
+		}
+		certificate, loaded, err := loadCertificate(path, "")
+		if err != nil {
+			t.Fatalf("%s: failed to load certificate: %+v", name, err)
+		}
+		if certificate.Subject.CommonName != "go-samples" || loaded.N.Cmp(key.N) != 0 {
+			t.Errorf("%s: loaded the wrong certificate or key", name)
+		}
+	}
+
+	path := filepath.Join(dir, "cert-only.pem")
+	if err := ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := loadCertificate(path, ""); err == nil {
+		t.Errorf("expected an error without a private key")
+	}
+}
+
+func TestFederatedSecret(t *testing.T) {
+	f, err := ioutil.TempFile("", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	f.WriteString("header.payload.signature\n")
+	f.Close()
+
+	v := url.Values{}
+	if err := (&federatedSecret{f.Name()}).SetAuthenticationValues(nil, &v); err != nil {
+		t.Fatalf("failed to set values: %+v", err)
+	}
+	if v.Get("client_assertion") != "header.payload.signature" {
+		t.Errorf("expected the JWT as client assertion, got %q", v.Get("client_assertion"))
+	}
+	if v.Get("client_assertion_type") != "urn:ietf:params:oauth:client-assertion-type:jwt-bearer" {
+		t.Errorf("unexpected assertion type %q", v.Get("client_assertion_type"))
+	}
+}
+
+func TestFederatedCredential(t *testing.T) {
+	f, err := ioutil.TempFile("", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	f.WriteString("header.payload.signature\n")
+	f.Close()
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/tenant/oauth2/token" {
+			t.Errorf("unexpected token path %s", r.URL.Path)
+		}
+		if got := r.PostFormValue("client_assertion"); got != "header.payload.signature" {
+			t.Errorf("expected the JWT as client assertion, got %q", got)
+		}
+		if got := r.PostFormValue("resource"); got != "https://management.azure.com" {
+			t.Errorf("expected the resource of the scope, got %q", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"token","expires_in":"3600","expires_on":"` +
+			strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10) + `","token_type":"Bearer"}`))
+	}))
+	defer server.Close()
+
+	cred, err := newFederatedCredential(server.URL, "tenant", "client", f.Name())
+	if err != nil {
+		t.Fatalf("failed to create credential: %+v", err)
+	}
+	for i := 0; i < 2; i++ {
+		token, err := cred.GetToken(context.Background(), azcore.TokenRequestOptions{Scopes: []string{"https://management.azure.com/.default"}})
+		if err != nil {
+			t.Fatalf("failed to get token: %+v", err)
+		}
+		if token.Token != "token" || time.Until(token.ExpiresOn) < 50*time.Minute {
+			t.Errorf("unexpected token %+v", token)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("expected the token to be reused, got %d requests", requests)
+	}
+}
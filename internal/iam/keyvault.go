@@ -0,0 +1,197 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package iam
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
+	"github.com/Azure/go-autorest/autorest"
+)
+
+// TokenFunc returns an authorizer for tokens issued by the tenant of an
+// Active Directory endpoint for a resource. Credentials which can't choose
+// their authority, such as managed identities or the Azure CLI, may ignore
+// activeDirectoryEndpoint and tenantID.
+type TokenFunc func(activeDirectoryEndpoint, tenantID, resource string) (autorest.Authorizer, error)
+
+// challenge is the authority and resource a vault asks for in the
+// `WWW-Authenticate` header of a 401 response, e.g.
+//
+//	Bearer authorization="https://login.microsoftonline.us/<tenant>", resource="https://vault.usgovcloudapi.net"
+type challenge struct {
+	activeDirectoryEndpoint string
+	tenantID                string
+	resource                string
+}
+
+// parseChallenge reads a bearer challenge. Newer vaults send
+// `authorization_uri` and `scope` instead of `authorization` and `resource`.
+func parseChallenge(header string) (challenge, error) {
+	var c challenge
+	header = strings.TrimSpace(header)
+	if len(header) < 7 || !strings.EqualFold(header[:7], "Bearer ") {
+		return c, fmt.Errorf("expected a bearer challenge, got %q", header)
+	}
+
+	var authority string
+	for _, pair := range strings.Split(header[7:], ",") {
+		i := strings.Index(pair, "=")
+		if i < 0 {
+			continue
+		}
+		key := strings.TrimSpace(pair[:i])
+		value := strings.Trim(strings.TrimSpace(pair[i+1:]), `"`)
+		switch key {
+		case "authorization", "authorization_uri":
+			authority = value
+		case "resource":
+			c.resource = value
+		case "scope":
+			if c.resource == "" {
+				c.resource = strings.TrimSuffix(value, "/.default")
+			}
+		}
+	}
+
+	u, err := url.Parse(authority)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return c, fmt.Errorf("bearer challenge %q doesn't name an authority", header)
+	}
+	if c.resource == "" {
+		return c, fmt.Errorf("bearer challenge %q doesn't name a resource", header)
+	}
+	c.activeDirectoryEndpoint = u.Scheme + "://" + u.Host + "/"
+	c.tenantID = strings.Trim(u.Path, "/")
+	return c, nil
+}
+
+// verify checks that the challenge's resource is the vault host or one of
+// its parent domains, like vault.azure.net for myvault.vault.azure.net, so
+// a host can't get a token for another resource such as Resource Manager.
+func (c challenge) verify(vaultHost string) error {
+	u, err := url.Parse(c.resource)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("bearer challenge resource %q isn't a URL", c.resource)
+	}
+	host, resourceHost := strings.ToLower(vaultHost), strings.ToLower(u.Hostname())
+	if host != resourceHost && !strings.HasSuffix(host, "."+resourceHost) {
+		return fmt.Errorf("bearer challenge resource %s doesn't match vault %s", c.resource, vaultHost)
+	}
+	return nil
+}
+
+// challengeAuthorizer authorizes requests to any vault, in any cloud. The
+// first request to each vault is sent without a token to learn from the
+// challenge which authority and resource the vault trusts.
+type challengeAuthorizer struct {
+	newAuthorizer TokenFunc
+	sender        autorest.Sender
+
+	mu sync.Mutex
+	// vaults maps each vault host to the authorizer for its challenge and
+	// tokens shares those authorizers between vaults with the same one.
+	vaults map[string]autorest.Authorizer
+	tokens map[challenge]autorest.Authorizer
+}
+
+// NewKeyvaultAuthorizer returns an authorizer for Key Vault keys and secrets
+// which discovers the tenant and resource of each vault from its bearer
+// challenge and then requests tokens with newAuthorizer. Tokens are cached
+// per vault.
+func NewKeyvaultAuthorizer(newAuthorizer TokenFunc) autorest.Authorizer {
+	return &challengeAuthorizer{
+		newAuthorizer: newAuthorizer,
+		sender:        recording.Sender(),
+		vaults:        map[string]autorest.Authorizer{},
+		tokens:        map[challenge]autorest.Authorizer{},
+	}
+}
+
+// WithAuthorization returns a PrepareDecorator which adds a token for the
+// vault the request is sent to.
+func (a *challengeAuthorizer) WithAuthorization() autorest.PrepareDecorator {
+	return func(p autorest.Preparer) autorest.Preparer {
+		return autorest.PreparerFunc(func(r *http.Request) (*http.Request, error) {
+			r, err := p.Prepare(r)
+			if err != nil {
+				return r, err
+			}
+			vault, err := a.vaultAuthorizer(r)
+			if err != nil {
+				return r, err
+			}
+			return autorest.Prepare(r, vault.WithAuthorization())
+		})
+	}
+}
+
+func (a *challengeAuthorizer) vaultAuthorizer(r *http.Request) (autorest.Authorizer, error) {
+	a.mu.Lock()
+	vault, ok := a.vaults[r.URL.Host]
+	a.mu.Unlock()
+	if ok {
+		return vault, nil
+	}
+
+	// discover the vault without holding the lock, so requests to other
+	// vaults aren't held up by the probe and token request
+	c, err := a.challenge(r)
+	if err != nil {
+		return nil, err
+	}
+	a.mu.Lock()
+	vault, ok = a.tokens[c]
+	a.mu.Unlock()
+	if !ok {
+		vault, err = a.newAuthorizer(c.activeDirectoryEndpoint, c.tenantID, c.resource)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	// another request may have discovered the same vault or challenge
+	// meanwhile, keep the first authorizer so they share its token
+	if shared, ok := a.tokens[c]; ok {
+		vault = shared
+	} else {
+		a.tokens[c] = vault
+	}
+	if existing, ok := a.vaults[r.URL.Host]; ok {
+		return existing, nil
+	}
+	a.vaults[r.URL.Host] = vault
+	return vault, nil
+}
+
+// challenge sends a copy of r without its body, which the vault rejects
+// before reading it, to get the vault's bearer challenge. The challenge
+// must be for the vault's own host.
+func (a *challengeAuthorizer) challenge(r *http.Request) (challenge, error) {
+	probe := r.Clone(r.Context())
+	probe.Body, probe.GetBody, probe.ContentLength = nil, nil, 0
+	probe.Header.Del("Authorization")
+
+	resp, err := a.sender.Do(probe)
+	if err != nil {
+		return challenge{}, fmt.Errorf("cannot get the bearer challenge of %s: %v", r.URL.Host, err)
+	}
+	autorest.DrainResponseBody(resp)
+	if resp.StatusCode != http.StatusUnauthorized {
+		return challenge{}, fmt.Errorf("expected a bearer challenge from %s, got %s", r.URL.Host, resp.Status)
+	}
+	c, err := parseChallenge(resp.Header.Get("WWW-Authenticate"))
+	if err != nil {
+		return c, err
+	}
+	return c, c.verify(r.URL.Hostname())
+}
@@ -0,0 +1,189 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package iam
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Azure/go-autorest/autorest"
+)
+
+func TestParseChallenge(t *testing.T) {
+	cases := []struct {
+		header string
+		want   challenge
+	}{
+		{
+			header: `Bearer authorization="https://login.windows.net/72f988bf-86f1-41af-91ab-2d7cd011db47", resource="https://vault.azure.net"`,
+			want:   challenge{"https://login.windows.net/", "72f988bf-86f1-41af-91ab-2d7cd011db47", "https://vault.azure.net"},
+		},
+		{
+			header: `Bearer authorization_uri="https://login.microsoftonline.us/tenant/", scope="https://vault.usgovcloudapi.net/.default"`,
+			want:   challenge{"https://login.microsoftonline.us/", "tenant", "https://vault.usgovcloudapi.net"},
+		},
+	}
+	for _, c := range cases {
+		got, err := parseChallenge(c.header)
+		if err != nil {
+			t.Errorf("failed to parse %q: %+v", c.header, err)
+		} else if got != c.want {
+			t.Errorf("expected %+v, got %+v", c.want, got)
+		}
+	}
+
+	for _, header := range []string{
+		`Basic realm="vault"`,
+		`Bearer resource="https://vault.azure.net"`,
+		`Bearer authorization="https://login.windows.net/tenant"`,
+	} {
+		if _, err := parseChallenge(header); err == nil {
+			t.Errorf("expected an error for %q", header)
+		}
+	}
+}
+
+// newVault serves a vault which challenges requests without a token for
+// resource.
+func newVault(t *testing.T, challenges *int, resource string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			*challenges++
+			w.Header().Set("WWW-Authenticate", `Bearer authorization="https://login.chinacloudapi.cn/tenant", resource="`+resource+`"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer https://login.chinacloudapi.cn/ tenant "+resource {
+			t.Errorf("unexpected authorization %q", got)
+		}
+	}))
+}
+
+// testVaultResource is the resource vaults served on 127.0.0.1 challenge
+// for, which the vault host matches.
+const testVaultResource = "https://127.0.0.1"
+
+type fakeToken string
+
+func (token fakeToken) WithAuthorization() autorest.PrepareDecorator {
+	return autorest.WithHeader("Authorization", "Bearer "+string(token))
+}
+
+func TestKeyvaultAuthorizer(t *testing.T) {
+	var challenges, tokens int
+	first, second := newVault(t, &challenges, testVaultResource), newVault(t, &challenges, testVaultResource)
+	defer first.Close()
+	defer second.Close()
+
+	authorizer := NewKeyvaultAuthorizer(func(activeDirectoryEndpoint, tenantID, resource string) (autorest.Authorizer, error) {
+		tokens++
+		return fakeToken(strings.Join([]string{activeDirectoryEndpoint, tenantID, resource}, " ")), nil
+	})
+	client := autorest.NewClientWithUserAgent("test")
+	client.Authorizer = authorizer
+
+	for _, vault := range []string{first.URL, first.URL, second.URL} {
+		req, err := autorest.Prepare(&http.Request{},
+			autorest.AsPost(),
+			autorest.WithBaseURL(vault),
+			autorest.WithPath("/keys/key/create"),
+			autorest.WithJSON(map[string]string{"kty": "RSA"}))
+		if err != nil {
+			t.Fatalf("failed to prepare request: %+v", err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("failed to send request: %+v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("expected the vault to accept the token, got %s", resp.Status)
+		}
+	}
+	if challenges != 2 {
+		t.Errorf("expected one challenge per vault, got %d", challenges)
+	}
+	if tokens != 1 {
+		t.Errorf("expected vaults with the same challenge to share a token, got %d", tokens)
+	}
+}
+
+func TestKeyvaultAuthorizerDoesNotBlockOtherVaults(t *testing.T) {
+	var challenges int
+	probing, release := make(chan struct{}), make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(probing)
+		<-release
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer slow.Close()
+	fast := newVault(t, &challenges, testVaultResource)
+	defer fast.Close()
+
+	authorizer := NewKeyvaultAuthorizer(func(activeDirectoryEndpoint, tenantID, resource string) (autorest.Authorizer, error) {
+		return fakeToken(strings.Join([]string{activeDirectoryEndpoint, tenantID, resource}, " ")), nil
+	})
+	prepare := func(vault string) error {
+		_, err := autorest.Prepare(&http.Request{},
+			autorest.AsGet(),
+			autorest.WithBaseURL(vault),
+			autorest.WithPath("/secrets/secret"),
+			authorizer.WithAuthorization())
+		return err
+	}
+
+	slowDone := make(chan error)
+	go func() { slowDone <- prepare(slow.URL) }()
+	// the slow vault is being probed, which mustn't hold up the fast one
+	<-probing
+	if err := prepare(fast.URL); err != nil {
+		t.Errorf("failed to authorize the fast vault: %v", err)
+	}
+	close(release)
+	if err := <-slowDone; err == nil {
+		t.Error("expected an error from the vault without a challenge")
+	}
+}
+
+func TestChallengeVerify(t *testing.T) {
+	for _, test := range []struct {
+		host, resource string
+		ok             bool
+	}{
+		{"myvault.vault.azure.net", "https://vault.azure.net", true},
+		{"myvault.vault.usgovcloudapi.net", "https://vault.usgovcloudapi.net/", true},
+		{"MyVault.Vault.Azure.Net", "https://vault.azure.net", true},
+		{"myvault.vault.azure.net", "https://management.azure.com", false},
+		{"myvault.vault.azure.net", "https://azure.net.vault", false},
+		{"evilvault.azure.net", "https://vault.azure.net", false},
+		{"myvault.vault.azure.net", "vault.azure.net", false},
+	} {
+		err := challenge{resource: test.resource}.verify(test.host)
+		if (err == nil) != test.ok {
+			t.Errorf("%s for %s: got error %v", test.resource, test.host, err)
+		}
+	}
+}
+
+func TestKeyvaultAuthorizerRejectsOtherResources(t *testing.T) {
+	var challenges int
+	vault := newVault(t, &challenges, "https://management.azure.com")
+	defer vault.Close()
+
+	authorizer := NewKeyvaultAuthorizer(func(activeDirectoryEndpoint, tenantID, resource string) (autorest.Authorizer, error) {
+		t.Errorf("requested a token for %s", resource)
+		return fakeToken(resource), nil
+	})
+	_, err := autorest.Prepare(&http.Request{},
+		autorest.AsGet(),
+		autorest.WithBaseURL(vault.URL),
+		autorest.WithPath("/secrets/secret"),
+		authorizer.WithAuthorization())
+	if err == nil || !strings.Contains(err.Error(), "doesn't match vault") {
+		t.Errorf("expected the challenge to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+package parameters
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/resourceid"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/keyvault"
+)
+
+// Resolve replaces each Key Vault reference with the secret it names,
+// fetched with the samples' keyvault package. ARM resolves references
+// itself, but only from vaults enabled for template deployment; resolving
+// them here also lets Validate check the secrets. Secrets are converted to
+// the type the template declares for their parameter, like Override does.
+func (p Parameters) Resolve(ctx context.Context, t Template) error {
+	for _, name := range p.Names() {
+		ref := p[name].Reference
+		if ref == nil {
+			continue
+		}
+		vaultName, err := vaultName(ref.KeyVault.ID)
+		if err != nil {
+			return fmt.Errorf("parameter %s: %v", name, err)
+		}
+		vaultURL, err := keyvault.VaultURL(vaultName)
+		if err != nil {
+			return err
+		}
+		secret, err := keyvault.GetSecret(ctx, vaultURL, ref.SecretName, ref.SecretVersion)
+		if err != nil {
+			return fmt.Errorf("parameter %s: cannot get secret %s from vault %s: %v", name, ref.SecretName, vaultName, err)
+		}
+		if secret.Value == nil {
+			return fmt.Errorf("parameter %s: secret %s in vault %s has no value", name, ref.SecretName, vaultName)
+		}
+		var value interface{} = *secret.Value
+		if def, ok := t.lookup(name); ok {
+			if value, err = convert(def.Type, *secret.Value); err != nil {
+				return fmt.Errorf("parameter %s: secret %s: %v", name, ref.SecretName, err)
+			}
+		}
+		p[name] = Parameter{Value: value}
+	}
+	return nil
+}
+
+// vaultName returns the name of the vault with the given resource ID, e.g.
+// `/subscriptions/<id>/resourceGroups/<group>/providers/Microsoft.KeyVault/vaults/<name>`.
+func vaultName(id string) (string, error) {
+	vault, err := resourceid.Parse(id)
+	if err != nil || !strings.EqualFold(vault.ResourceType.String(), "Microsoft.KeyVault/vaults") {
+		return "", fmt.Errorf("%q isn't the resource ID of a vault", id)
+	}
+	return vault.Name, nil
+}
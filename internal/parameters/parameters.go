@@ -0,0 +1,173 @@
+// Package parameters reads, merges and validates the parameters of ARM
+// template deployments.
+package parameters
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Parameter is the value of one template parameter, or a reference to a
+// Key Vault secret holding it.
+type Parameter struct {
+	Value     interface{}        `json:"value,omitempty"`
+	Reference *KeyVaultReference `json:"reference,omitempty"`
+}
+
+// KeyVaultReference names a Key Vault secret to use as a parameter value,
+// as in a `deploymentParameters.json` file:
+//
+//	"reference": {
+//	  "keyVault": {"id": "/subscriptions/.../providers/Microsoft.KeyVault/vaults/<name>"},
+//	  "secretName": "vmPassword"
+//	}
+type KeyVaultReference struct {
+	KeyVault struct {
+		ID string `json:"id"`
+	} `json:"keyVault"`
+	SecretName    string `json:"secretName"`
+	SecretVersion string `json:"secretVersion,omitempty"`
+}
+
+// Parameters holds the parameters of a deployment by name.
+type Parameters map[string]Parameter
+
+// parametersFile is the layout of a `deploymentParameters.json` file.
+type parametersFile struct {
+	Schema         string     `json:"$schema"`
+	ContentVersion string     `json:"contentVersion"`
+	Parameters     Parameters `json:"parameters"`
+}
+
+// ReadFile reads a parameters file, either a `deploymentParameters.json`
+// schema file or just its `parameters` object.
+func ReadFile(path string) (Parameters, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	params, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse parameters file %s: %v", path, err)
+	}
+	return params, nil
+}
+
+// Parse parses the contents of a parameters file, see ReadFile.
+func Parse(data []byte) (Parameters, error) {
+	var probe map[string]json.RawMessage
+	if err := unmarshal(data, &probe); err != nil {
+		return nil, err
+	}
+	if _, ok := probe["$schema"]; ok {
+		var file parametersFile
+		if err := unmarshal(data, &file); err != nil {
+			return nil, err
+		}
+		if file.Parameters == nil {
+			file.Parameters = Parameters{}
+		}
+		return file.Parameters, nil
+	}
+	params := Parameters{}
+	if err := unmarshal(data, &params); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
+// unmarshal decodes JSON keeping numbers exact, so large integers survive.
+func unmarshal(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// Merge returns the parameters with those of each override applied in turn,
+// later overrides winning.
+func (p Parameters) Merge(overrides ...Parameters) Parameters {
+	merged := Parameters{}
+	for _, params := range append([]Parameters{p}, overrides...) {
+		for name, param := range params {
+			merged[name] = param
+		}
+	}
+	return merged
+}
+
+// Override sets parameters from `name=value` assignments, e.g. given on the
+// command line. Each value is converted to the type the template declares
+// for it: ints and bools are parsed, arrays and objects are read as JSON.
+func (p Parameters) Override(t Template, assignments ...string) error {
+	for _, assignment := range assignments {
+		i := strings.Index(assignment, "=")
+		if i < 1 {
+			return fmt.Errorf("expected name=value, got %q", assignment)
+		}
+		name, raw := assignment[:i], assignment[i+1:]
+		def, ok := t.lookup(name)
+		if !ok {
+			return fmt.Errorf("template has no parameter %q", name)
+		}
+		value, err := convert(def.Type, raw)
+		if err != nil {
+			return fmt.Errorf("parameter %s: %v", name, err)
+		}
+		p[def.name] = Parameter{Value: value}
+	}
+	return nil
+}
+
+func convert(paramType, raw string) (interface{}, error) {
+	switch strings.ToLower(paramType) {
+	case "int":
+		if _, err := strconv.ParseInt(raw, 10, 64); err != nil {
+			return nil, fmt.Errorf("expected an int, got %q", raw)
+		}
+		return json.Number(raw), nil
+	case "bool":
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("expected a bool, got %q", raw)
+		}
+		return b, nil
+	case "array", "object", "secureobject":
+		var v interface{}
+		if err := unmarshal([]byte(raw), &v); err != nil {
+			return nil, fmt.Errorf("expected JSON, got %q: %v", raw, err)
+		}
+		return v, nil
+	default:
+		return raw, nil
+	}
+}
+
+// Names returns the names of the parameters, sorted.
+func (p Parameters) Names() []string {
+	names := make([]string, 0, len(p))
+	for name := range p {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Map returns the parameters in the form deployments take them, e.g. for
+// `resources.CreateDeployment`. Unresolved Key Vault references are passed
+// on for ARM to resolve.
+func (p Parameters) Map() *map[string]interface{} {
+	m := map[string]interface{}{}
+	for name, param := range p {
+		if param.Reference != nil {
+			m[name] = map[string]interface{}{"reference": param.Reference}
+		} else {
+			m[name] = map[string]interface{}{"value": param.Value}
+		}
+	}
+	return &m
+}
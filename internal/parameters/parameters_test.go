@@ -0,0 +1,179 @@
+package parameters
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const testTemplate = `{
+	"$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+	"contentVersion": "1.0.0.0",
+	"parameters": {
+		"vmName": {"type": "string", "minLength": 3, "maxLength": 15},
+		"vmSize": {"type": "string", "defaultValue": "Standard_B1s", "allowedValues": ["Standard_B1s", "Standard_B2s"]},
+		"adminPassword": {"type": "securestring"},
+		"diskCount": {"type": "int", "defaultValue": 1, "minValue": 1, "maxValue": 4},
+		"acceleratedNetworking": {"type": "bool", "defaultValue": false},
+		"zones": {"type": "array", "defaultValue": [], "allowedValues": ["1", "2", "3"]},
+		"tags": {"type": "object", "defaultValue": {}}
+	},
+	"resources": []
+}`
+
+func parseTemplate(t *testing.T) Template {
+	template, err := ParseTemplate([]byte(testTemplate))
+	if err != nil {
+		t.Fatalf("failed to parse template: %+v", err)
+	}
+	return template
+}
+
+func TestParse(t *testing.T) {
+	schemaFile := `{
+	"$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentParameters.json#",
+	"contentVersion": "1.0.0.0",
+	"parameters": {
+		"vmName": {"value": "samplevm"},
+		"adminPassword": {
+			"reference": {
+				"keyVault": {"id": "/subscriptions/sub/resourceGroups/group/providers/Microsoft.KeyVault/vaults/samplevault"},
+				"secretName": "vmPassword"
+			}
+		}
+	}
+}`
+	bareFile := `{"vmName": {"value": "samplevm"}, "diskCount": {"value": 2}}`
+
+	params, err := Parse([]byte(schemaFile))
+	if err != nil {
+		t.Fatalf("failed to parse schema file: %+v", err)
+	}
+	if params["vmName"].Value != "samplevm" {
+		t.Errorf("expected vmName to be set, got %v", params["vmName"].Value)
+	}
+	if ref := params["adminPassword"].Reference; ref == nil || ref.SecretName != "vmPassword" {
+		t.Errorf("expected a Key Vault reference, got %+v", ref)
+	}
+
+	params, err = Parse([]byte(bareFile))
+	if err != nil {
+		t.Fatalf("failed to parse bare file: %+v", err)
+	}
+	if params["diskCount"].Value != json.Number("2") {
+		t.Errorf("expected diskCount to be the number 2, got %#v", params["diskCount"].Value)
+	}
+}
+
+func TestMergeAndOverride(t *testing.T) {
+	template := parseTemplate(t)
+	base := Parameters{"vmName": {Value: "base"}, "vmSize": {Value: "Standard_B1s"}}
+	env := Parameters{"vmName": {Value: "prod"}}
+
+	params := base.Merge(env)
+	if base["vmName"].Value != "base" {
+		t.Errorf("expected Merge not to modify the receiver")
+	}
+	err := params.Override(template,
+		"vmsize=Standard_B2s",
+		"diskCount=3",
+		"acceleratedNetworking=true",
+		`zones=["1","2"]`,
+		"adminPassword=p@ss=word")
+	if err != nil {
+		t.Fatalf("failed to override: %+v", err)
+	}
+
+	expected := map[string]interface{}{
+		"vmName":                "prod",
+		"vmSize":                "Standard_B2s",
+		"diskCount":             json.Number("3"),
+		"acceleratedNetworking": true,
+		"adminPassword":         "p@ss=word",
+	}
+	for name, want := range expected {
+		if got := params[name].Value; got != want {
+			t.Errorf("%s: expected %#v, got %#v", name, want, got)
+		}
+	}
+	if zones, ok := params["zones"].Value.([]interface{}); !ok || len(zones) != 2 {
+		t.Errorf("expected zones to be an array, got %#v", params["zones"].Value)
+	}
+
+	for _, assignment := range []string{"diskCount=two", "unknown=1", "novalue"} {
+		if err := params.Override(template, assignment); err == nil {
+			t.Errorf("expected an error for %q", assignment)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	template := parseTemplate(t)
+
+	params := Parameters{
+		"vmName":        {Value: "vm"},
+		"vmSize":        {Value: "Standard_D64s"},
+		"diskCount":     {Value: json.Number("8")},
+		"zones":         {Value: []interface{}{"1", "4"}},
+		"tags":          {Value: "env=prod"},
+		"adminPassword": {Reference: &KeyVaultReference{SecretName: "vmPassword"}},
+		"extra":         {Value: true},
+	}
+	err := params.Validate(template)
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected a ValidationError, got %v", err)
+	}
+	for _, want := range []string{
+		"extra isn't a parameter of the template",
+		"adminPassword must reference a vault ID and secret name",
+		"diskCount must be at most 4, got 8",
+		"tags must be an object, got \"env=prod\"",
+		"vmName must have a length of at least 3, got 2",
+		`vmSize must be one of "Standard_B1s", "Standard_B2s", got "Standard_D64s"`,
+		`zones must only contain "1", "2", "3", got "4"`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q to be reported, got:\n%v", want, err)
+		}
+	}
+	if len(validationErr.Problems) != 7 {
+		t.Errorf("expected 7 problems, got %d:\n%v", len(validationErr.Problems), err)
+	}
+
+	params = Parameters{"vmName": {Value: "samplevm"}}
+	if err := params.Validate(template); err == nil || !strings.Contains(err.Error(), "adminPassword is required") {
+		t.Errorf("expected adminPassword to be required, got %v", err)
+	}
+
+	params["adminPassword"] = Parameter{Value: "secret"}
+	if err := params.Validate(template); err != nil {
+		t.Errorf("expected valid parameters, got %v", err)
+	}
+}
+
+func TestMap(t *testing.T) {
+	params := Parameters{
+		"vmName":        {Value: "samplevm"},
+		"adminPassword": {Reference: &KeyVaultReference{SecretName: "vmPassword"}},
+	}
+	data, err := json.Marshal(params.Map())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"adminPassword":{"reference":{"keyVault":{"id":""},"secretName":"vmPassword"}},"vmName":{"value":"samplevm"}}`
+	if string(data) != want {
+		t.Errorf("expected %s, got %s", want, data)
+	}
+}
+
+func TestVaultName(t *testing.T) {
+	name, err := vaultName("/subscriptions/sub/resourceGroups/group/providers/Microsoft.KeyVault/vaults/samplevault")
+	if err != nil || name != "samplevault" {
+		t.Errorf("expected samplevault, got %q, %v", name, err)
+	}
+	if _, err := vaultName("/subscriptions/sub/resourceGroups/group"); err == nil {
+		t.Errorf("expected an error for a group ID")
+	}
+}
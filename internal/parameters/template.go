@@ -0,0 +1,83 @@
+package parameters
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+// Definition declares a template parameter in the template's `parameters`
+// section.
+type Definition struct {
+	Type          string        `json:"type"`
+	DefaultValue  interface{}   `json:"defaultValue"`
+	AllowedValues []interface{} `json:"allowedValues"`
+	MinValue      *json.Number  `json:"minValue"`
+	MaxValue      *json.Number  `json:"maxValue"`
+	MinLength     *int          `json:"minLength"`
+	MaxLength     *int          `json:"maxLength"`
+
+	// name is the parameter's name as the template spells it
+	name string
+}
+
+// HasDefault reports whether the parameter may be left out. ARM treats an
+// explicit null default like a missing one.
+func (d Definition) HasDefault() bool {
+	return d.DefaultValue != nil
+}
+
+// Template is a deployment template along with its parameter definitions.
+type Template struct {
+	// Content is the whole template, to deploy.
+	Content *map[string]interface{}
+	// Parameters are the template's parameter definitions by name.
+	Parameters map[string]Definition
+}
+
+// ReadTemplate reads a template file.
+func ReadTemplate(path string) (Template, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Template{}, err
+	}
+	t, err := ParseTemplate(data)
+	if err != nil {
+		return t, fmt.Errorf("cannot parse template %s: %v", path, err)
+	}
+	return t, nil
+}
+
+// ParseTemplate parses the contents of a template file.
+func ParseTemplate(data []byte) (Template, error) {
+	content := map[string]interface{}{}
+	if err := unmarshal(data, &content); err != nil {
+		return Template{}, err
+	}
+	var declared struct {
+		Parameters map[string]Definition `json:"parameters"`
+	}
+	if err := unmarshal(data, &declared); err != nil {
+		return Template{}, err
+	}
+	t := Template{Content: &content, Parameters: map[string]Definition{}}
+	for name, def := range declared.Parameters {
+		def.name = name
+		t.Parameters[name] = def
+	}
+	return t, nil
+}
+
+// lookup finds a definition by name, ignoring case like ARM does.
+func (t Template) lookup(name string) (Definition, bool) {
+	if def, ok := t.Parameters[name]; ok {
+		return def, true
+	}
+	for declared, def := range t.Parameters {
+		if strings.EqualFold(declared, name) {
+			return def, true
+		}
+	}
+	return Definition{}, false
+}
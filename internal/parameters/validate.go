@@ -0,0 +1,196 @@
+package parameters
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// ValidationError lists every problem Validate found with the parameters.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid parameters:\n  %s", strings.Join(e.Problems, "\n  "))
+}
+
+// Validate checks the parameters against the template's definitions before
+// they're sent to ARM: every parameter without a default must be set, none
+// may be unknown, and each value must have the declared type and respect
+// the allowedValues, minValue, maxValue, minLength and maxLength
+// constraints. All problems are reported at once, as a *ValidationError.
+// Key Vault references are only checked for being complete, their secrets
+// aren't fetched.
+func (p Parameters) Validate(t Template) error {
+	var problems []string
+	for _, name := range p.Names() {
+		if _, ok := t.lookup(name); !ok {
+			problems = append(problems, fmt.Sprintf("%s isn't a parameter of the template", name))
+		}
+	}
+
+	for _, name := range t.names() {
+		def := t.Parameters[name]
+		param, ok := p.lookup(name)
+		if !ok {
+			if !def.HasDefault() {
+				problems = append(problems, fmt.Sprintf("%s is required", name))
+			}
+			continue
+		}
+		if ref := param.Reference; ref != nil {
+			if ref.KeyVault.ID == "" || ref.SecretName == "" {
+				problems = append(problems, fmt.Sprintf("%s must reference a vault ID and secret name", name))
+			}
+			continue
+		}
+		for _, problem := range def.check(param.Value) {
+			problems = append(problems, fmt.Sprintf("%s %s", name, problem))
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// check returns the problems with a value of the parameter.
+func (d Definition) check(value interface{}) []string {
+	var problems []string
+	switch strings.ToLower(d.Type) {
+	case "string", "securestring":
+		s, ok := value.(string)
+		if !ok {
+			return []string{fmt.Sprintf("must be a string, got %s", describe(value))}
+		}
+		problems = append(problems, d.checkLength(utf8.RuneCountInString(s))...)
+	case "int":
+		n, ok := value.(json.Number)
+		if _, err := n.Int64(); !ok || err != nil {
+			return []string{fmt.Sprintf("must be an int, got %s", describe(value))}
+		}
+		if d.MinValue != nil && compare(n, *d.MinValue) < 0 {
+			problems = append(problems, fmt.Sprintf("must be at least %s, got %s", *d.MinValue, n))
+		}
+		if d.MaxValue != nil && compare(n, *d.MaxValue) > 0 {
+			problems = append(problems, fmt.Sprintf("must be at most %s, got %s", *d.MaxValue, n))
+		}
+	case "bool":
+		if _, ok := value.(bool); !ok {
+			return []string{fmt.Sprintf("must be a bool, got %s", describe(value))}
+		}
+	case "array":
+		a, ok := value.([]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("must be an array, got %s", describe(value))}
+		}
+		problems = append(problems, d.checkLength(len(a))...)
+		// every element of an array must be allowed
+		if len(d.AllowedValues) > 0 {
+			for _, element := range a {
+				if !d.allows(element) {
+					problems = append(problems, fmt.Sprintf("must only contain %s, got %s", describeAll(d.AllowedValues), describe(element)))
+				}
+			}
+		}
+		return problems
+	case "object", "secureobject":
+		if _, ok := value.(map[string]interface{}); !ok {
+			return []string{fmt.Sprintf("must be an object, got %s", describe(value))}
+		}
+	default:
+		return []string{fmt.Sprintf("has unknown type %q", d.Type)}
+	}
+
+	if len(d.AllowedValues) > 0 && !d.allows(value) {
+		problems = append(problems, fmt.Sprintf("must be one of %s, got %s", describeAll(d.AllowedValues), describe(value)))
+	}
+	return problems
+}
+
+func (d Definition) checkLength(n int) []string {
+	var problems []string
+	if d.MinLength != nil && n < *d.MinLength {
+		problems = append(problems, fmt.Sprintf("must have a length of at least %d, got %d", *d.MinLength, n))
+	}
+	if d.MaxLength != nil && n > *d.MaxLength {
+		problems = append(problems, fmt.Sprintf("must have a length of at most %d, got %d", *d.MaxLength, n))
+	}
+	return problems
+}
+
+func (d Definition) allows(value interface{}) bool {
+	for _, allowed := range d.AllowedValues {
+		if n, ok := value.(json.Number); ok {
+			if m, ok := allowed.(json.Number); ok && compare(n, m) == 0 {
+				return true
+			}
+			continue
+		}
+		if reflect.DeepEqual(value, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// compare compares two JSON numbers by value, so 1 and 1.0 are equal.
+func compare(a, b json.Number) int {
+	x, _ := a.Float64()
+	y, _ := b.Float64()
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func describe(value interface{}) string {
+	if value == nil {
+		return "null"
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+func describeAll(values []interface{}) string {
+	described := make([]string, len(values))
+	for i, v := range values {
+		described[i] = describe(v)
+	}
+	return strings.Join(described, ", ")
+}
+
+// lookup finds a parameter by name, ignoring case like ARM does.
+func (p Parameters) lookup(name string) (Parameter, bool) {
+	if param, ok := p[name]; ok {
+		return param, true
+	}
+	for given, param := range p {
+		if strings.EqualFold(given, name) {
+			return param, true
+		}
+	}
+	return Parameter{}, false
+}
+
+// names returns the names of the template's parameters, sorted.
+func (t Template) names() []string {
+	names := make([]string, 0, len(t.Parameters))
+	for name := range t.Parameters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
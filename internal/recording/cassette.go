@@ -0,0 +1,114 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package recording
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// Cassette holds the interactions recorded for a single test.
+type Cassette struct {
+	// Names are the random names generated by the test, in order.
+	Names []string `json:"names,omitempty"`
+	// Variables are config values the test depended on when recorded.
+	Variables    map[string]string `json:"variables,omitempty"`
+	Interactions []Interaction     `json:"interactions"`
+}
+
+// Interaction is a recorded request and the response it got.
+type Interaction struct {
+	Request  RequestRecord  `json:"request"`
+	Response ResponseRecord `json:"response"`
+}
+
+// RequestRecord is the sanitized form of a recorded request.
+type RequestRecord struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+}
+
+// ResponseRecord is the sanitized form of a recorded response.
+type ResponseRecord struct {
+	StatusCode int         `json:"statusCode"`
+	Status     string      `json:"status"`
+	Headers    http.Header `json:"headers,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// LoadCassette reads a cassette from path.
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c := &Cassette{}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, err
+	}
+	if c.Variables == nil {
+		c.Variables = map[string]string{}
+	}
+	return c, nil
+}
+
+// Save writes the cassette to path, creating its directory if needed.
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// toHTTP builds the response to a replayed request. Retry-After is zeroed
+// so long-running operations poll without waiting.
+func (r ResponseRecord) toHTTP(req *http.Request) *http.Response {
+	header := http.Header{}
+	for k, v := range r.Headers {
+		header[k] = append([]string(nil), v...)
+	}
+	if header.Get("Retry-After") != "" {
+		header.Set("Retry-After", "0")
+	}
+	header.Set("Content-Length", strconv.Itoa(len(r.Body)))
+	return &http.Response{
+		StatusCode:    r.StatusCode,
+		Status:        r.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewBufferString(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}
+}
+
+// readBody reads and replaces *body so it can still be consumed by the caller.
+func readBody(body *io.ReadCloser) (string, error) {
+	if *body == nil || *body == http.NoBody {
+		return "", nil
+	}
+	data, err := ioutil.ReadAll(*body)
+	(*body).Close()
+	if err != nil {
+		return "", err
+	}
+	*body = ioutil.NopCloser(bytes.NewReader(data))
+	return string(data), nil
+}
@@ -0,0 +1,269 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// Package recording records the HTTP traffic of live tests into per-test
+// cassette files and replays it, so tests can run without a subscription.
+//
+// The mode is chosen with the AZURE_RECORD_MODE environment variable:
+// `live` (the default) talks to Azure, `record` talks to Azure and saves each
+// request/response pair under the package's `testdata/recordings` directory,
+// and `playback` serves responses from those files without touching the network.
+package recording
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+)
+
+// Mode specifies whether HTTP traffic is sent live, recorded or replayed.
+type Mode string
+
+const (
+	// Live sends requests to Azure and records nothing.
+	Live Mode = "live"
+	// Record sends requests to Azure and saves them to a cassette.
+	Record Mode = "record"
+	// Playback serves responses from a cassette without network access.
+	Playback Mode = "playback"
+)
+
+// ModeEnvVar names the environment variable used to select the mode.
+const ModeEnvVar = "AZURE_RECORD_MODE"
+
+// placeholderSubscriptionID replaces the real subscription in cassettes.
+const placeholderSubscriptionID = "00000000-0000-0000-0000-000000000000"
+
+var (
+	// current is the recorder started by the running test, if any.
+	current   *Recorder
+	currentMu sync.Mutex
+)
+
+// GetMode returns the mode configured by AZURE_RECORD_MODE.
+func GetMode() Mode {
+	switch Mode(strings.ToLower(os.Getenv(ModeEnvVar))) {
+	case Record:
+		return Record
+	case Playback:
+		return Playback
+	default:
+		return Live
+	}
+}
+
+// Recorder records or replays the interactions of a single test.
+type Recorder struct {
+	mode      Mode
+	path      string
+	cassette  *Cassette
+	transport http.RoundTripper
+
+	mu      sync.Mutex
+	used    []bool
+	names   int
+	restore func(string, []rune) string
+}
+
+// TestingT is the subset of `testing.T` used by Start.
+type TestingT interface {
+	Name() string
+	Errorf(format string, args ...interface{})
+	Fatalf(format string, args ...interface{})
+	Skipf(format string, args ...interface{})
+}
+
+// Start begins recording or replaying the test t, and returns a func that
+// stops the recorder which should be deferred. In playback mode a test
+// without a cassette is skipped.
+func Start(t TestingT) func() {
+	r, err := NewRecorder(GetMode(), CassettePath(t.Name()))
+	if os.IsNotExist(err) {
+		t.Skipf("no recording for %s, skipping in playback mode", t.Name())
+		return func() {}
+	}
+	if err != nil {
+		t.Fatalf("failed to start recorder: %+v", err)
+		return func() {}
+	}
+	r.Activate()
+	return func() {
+		if err := r.Stop(); err != nil {
+			t.Errorf("failed to save recording: %+v", err)
+		}
+	}
+}
+
+// CassettePath returns the cassette file used for the named test, relative
+// to the package directory the test is run from.
+func CassettePath(testName string) string {
+	return filepath.Join("testdata", "recordings", filepath.FromSlash(testName)+".json")
+}
+
+// NewRecorder creates a recorder for the cassette at path. In playback mode
+// the cassette is loaded, and an error satisfying os.IsNotExist is returned
+// if it doesn't exist.
+func NewRecorder(mode Mode, path string) (*Recorder, error) {
+	r := &Recorder{
+		mode:      mode,
+		path:      path,
+		cassette:  &Cassette{Variables: map[string]string{}},
+		transport: http.DefaultTransport,
+	}
+	if mode == Playback {
+		c, err := LoadCassette(path)
+		if err != nil {
+			return nil, err
+		}
+		r.cassette = c
+		r.used = make([]bool, len(c.Interactions))
+	}
+	return r, nil
+}
+
+// Mode returns the recorder's mode.
+func (r *Recorder) Mode() Mode {
+	return r.mode
+}
+
+// Activate makes r the recorder used by Transport, Sender and Credential,
+// and makes generated names and config values reproducible.
+func (r *Recorder) Activate() {
+	currentMu.Lock()
+	current = r
+	currentMu.Unlock()
+
+	switch r.mode {
+	case Record:
+		r.cassette.Variables["location"] = config.Location()
+		var generate func(string, []rune) string
+		generate = config.SetNameGenerator(func(prefix string, acceptable []rune) string {
+			name := generate(prefix, acceptable)
+			r.mu.Lock()
+			r.cassette.Names = append(r.cassette.Names, name)
+			r.mu.Unlock()
+			return name
+		})
+		r.restore = generate
+	case Playback:
+		if config.SubscriptionID() == "" {
+			config.SetSubscriptionID(placeholderSubscriptionID)
+		}
+		if location := r.cassette.Variables["location"]; location != "" {
+			config.SetLocation(location)
+		}
+		var generate func(string, []rune) string
+		generate = config.SetNameGenerator(func(prefix string, acceptable []rune) string {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			if r.names >= len(r.cassette.Names) {
+				// the test generated more names than were recorded, the
+				// requests using it won't be found in the cassette.
+				return generate(prefix, acceptable)
+			}
+			name := r.cassette.Names[r.names]
+			r.names++
+			return name
+		})
+		r.restore = generate
+	}
+}
+
+// Stop deactivates the recorder and, in record mode, saves the cassette.
+func (r *Recorder) Stop() error {
+	currentMu.Lock()
+	if current == r {
+		current = nil
+	}
+	currentMu.Unlock()
+
+	if r.restore != nil {
+		config.SetNameGenerator(r.restore)
+		r.restore = nil
+	}
+	if r.mode != Record {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cassette.Save(r.path)
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch r.mode {
+	case Record:
+		return r.record(req)
+	case Playback:
+		return r.replay(req)
+	default:
+		return r.transport.RoundTrip(req)
+	}
+}
+
+func (r *Recorder) record(req *http.Request) (*http.Response, error) {
+	reqBody, err := readBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := readBody(&resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Request: RequestRecord{
+			Method:  req.Method,
+			URL:     sanitizeURL(req.URL.String()),
+			Headers: sanitizeHeaders(req.Header),
+			Body:    sanitizeBody(reqBody),
+		},
+		Response: ResponseRecord{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Headers:    sanitizeHeaders(resp.Header),
+			Body:       sanitizeBody(respBody),
+		},
+	})
+	return resp, nil
+}
+
+// replay serves the first unused interaction matching the request's method
+// and URL, so repeated polls of the same URL are replayed in order.
+func (r *Recorder) replay(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	url := sanitizeURL(req.URL.String())
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, in := range r.cassette.Interactions {
+		if r.used[i] || in.Request.Method != req.Method || in.Request.URL != url {
+			continue
+		}
+		r.used[i] = true
+		return in.Response.toHTTP(req), nil
+	}
+	return nil, fmt.Errorf("recording: no interaction for %s %s in %s", req.Method, url, r.path)
+}
+
+// active returns the recorder started by the running test, or nil.
+func active() *Recorder {
+	currentMu.Lock()
+	defer currentMu.Unlock()
+	return current
+}
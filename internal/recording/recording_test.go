@@ -0,0 +1,127 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package recording
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+)
+
+func TestRecordAndPlayback(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		w.Header().Set("Retry-After", "30")
+		w.Header().Set("Set-Cookie", "session=secret")
+		if n == 1 {
+			w.WriteHeader(http.StatusAccepted)
+			w.Write([]byte(`{"status":"InProgress"}`))
+			return
+		}
+		w.Write([]byte(`{"status":"Succeeded","properties":{"adminPassword":"hunter2"}}`))
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "recording")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "testdata", "recordings", "TestSample.json")
+	url := server.URL + "/subscriptions/1234/resourceGroups/rg?b=2&a=1"
+
+	r, err := NewRecorder(Record, path)
+	if err != nil {
+		t.Fatalf("failed to create recorder: %+v", err)
+	}
+	r.Activate()
+	name := config.AppendRandomSuffix("group")
+	for i := 0; i < 2; i++ {
+		get(t, url)
+	}
+	if err := r.Stop(); err != nil {
+		t.Fatalf("failed to save cassette: %+v", err)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read cassette: %+v", err)
+	}
+	for _, secret := range []string{"hunter2", "session=secret", "/subscriptions/1234"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("cassette contains %q", secret)
+		}
+	}
+
+	server.Close()
+	r, err = NewRecorder(Playback, path)
+	if err != nil {
+		t.Fatalf("failed to load cassette: %+v", err)
+	}
+	r.Activate()
+	defer r.Stop()
+
+	if replayed := config.AppendRandomSuffix("group"); replayed != name {
+		t.Errorf("expected replayed name %q, got %q", name, replayed)
+	}
+	first := get(t, url)
+	if first.StatusCode != http.StatusAccepted || first.Header.Get("Retry-After") != "0" {
+		t.Errorf("unexpected first response: %d, Retry-After %q", first.StatusCode, first.Header.Get("Retry-After"))
+	}
+	second := get(t, url)
+	body, _ := ioutil.ReadAll(second.Body)
+	if !strings.Contains(string(body), `"Succeeded"`) {
+		t.Errorf("expected the second recorded response, got %s", body)
+	}
+	if _, err := Sender().Do(newRequest(t, url)); err == nil {
+		t.Errorf("expected an error once the cassette is exhausted")
+	}
+}
+
+func TestStartSkipsWithoutCassette(t *testing.T) {
+	os.Setenv(ModeEnvVar, string(Playback))
+	defer os.Unsetenv(ModeEnvVar)
+
+	ft := &fakeT{name: "TestDoesNotExist"}
+	Start(ft)()
+	if !ft.skipped {
+		t.Errorf("expected the test to be skipped")
+	}
+}
+
+func get(t *testing.T, url string) *http.Response {
+	resp, err := Transport().Do(newRequest(t, url))
+	if err != nil {
+		t.Fatalf("request failed: %+v", err)
+	}
+	return resp
+}
+
+func newRequest(t *testing.T, url string) *http.Request {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
+
+type fakeT struct {
+	name    string
+	skipped bool
+}
+
+func (f *fakeT) Name() string                              { return f.name }
+func (f *fakeT) Errorf(format string, args ...interface{}) {}
+func (f *fakeT) Fatalf(format string, args ...interface{}) {}
+func (f *fakeT) Skipf(format string, args ...interface{})  { f.skipped = true }
@@ -0,0 +1,83 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package recording
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+)
+
+const redacted = "REDACTED"
+
+var (
+	subscriptionPattern = regexp.MustCompile(`(?i)/subscriptions/[^/?"]*`)
+
+	// secretPattern matches JSON string properties which carry credentials.
+	secretPattern = regexp.MustCompile(`(?i)"(password|adminPassword|primaryKey|secondaryKey|sharedKey|authorizationKey|connectionString|primaryConnectionString|secondaryConnectionString|clientSecret|client_secret|secret|access_token|refresh_token|accessToken)"(\s*:\s*)"[^"]*"`)
+
+	// listKeysPattern matches the key values returned by listKeys operations.
+	listKeysPattern = regexp.MustCompile(`("keyName"\s*:\s*"[^"]*"\s*,\s*"value"\s*:\s*)"[^"]*"`)
+
+	// secretHeaders are dropped from cassettes altogether.
+	secretHeaders = []string{
+		"Authorization",
+		"Cookie",
+		"Set-Cookie",
+		"X-Ms-Authorization-Auxiliary",
+	}
+)
+
+// sanitizeURL replaces the subscription in u with a placeholder and sorts
+// the query so URLs compare equal regardless of parameter order.
+func sanitizeURL(u string) string {
+	u = subscriptionPattern.ReplaceAllString(u, "/subscriptions/"+placeholderSubscriptionID)
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return u
+	}
+	parsed.Host = strings.ToLower(parsed.Host)
+	parsed.RawQuery = parsed.Query().Encode()
+	return parsed.String()
+}
+
+func sanitizeHeaders(h http.Header) http.Header {
+	clean := http.Header{}
+	for k, v := range h {
+		clean[k] = append([]string(nil), v...)
+	}
+	for _, k := range secretHeaders {
+		clean.Del(k)
+	}
+	for _, k := range []string{"Location", "Azure-Asyncoperation"} {
+		if v := clean.Get(k); v != "" {
+			clean.Set(k, sanitizeURL(v))
+		}
+	}
+	return clean
+}
+
+// sanitizeBody scrubs the configured identities, subscription paths and
+// any credential-like JSON properties from a request or response body.
+func sanitizeBody(body string) string {
+	if body == "" {
+		return body
+	}
+	for _, secret := range []string{config.ClientSecret(), config.ClientID(), config.TenantID()} {
+		if secret != "" {
+			body = strings.Replace(body, secret, redacted, -1)
+		}
+	}
+	if id := config.SubscriptionID(); id != "" {
+		body = strings.Replace(body, id, placeholderSubscriptionID, -1)
+	}
+	body = subscriptionPattern.ReplaceAllString(body, "/subscriptions/"+placeholderSubscriptionID)
+	body = listKeysPattern.ReplaceAllString(body, `$1"`+redacted+`"`)
+	return secretPattern.ReplaceAllString(body, `"$1"$2"`+redacted+`"`)
+}
@@ -0,0 +1,78 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package recording
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/go-autorest/autorest"
+)
+
+var defaultClient *http.Client
+
+func init() {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	defaultClient = &http.Client{Transport: transport}
+}
+
+// Transport returns the transport to use for `armcore.ConnectionOptions`.
+// Requests go through the recorder of the running test, or straight to
+// Azure when no test is being recorded.
+func Transport() azcore.Transport {
+	return azcore.TransportFunc(do)
+}
+
+// Sender returns the sender to use for `autorest.Client`. Like Transport it
+// goes through the recorder of the running test if there is one.
+func Sender() autorest.Sender {
+	return autorest.SenderFunc(do)
+}
+
+func do(req *http.Request) (*http.Response, error) {
+	if r := active(); r != nil {
+		return r.RoundTrip(req)
+	}
+	return defaultClient.Do(req)
+}
+
+// Credential returns the credential for track-2 clients: a fake one in
+// playback mode and the default Azure credential otherwise.
+func Credential() (azcore.TokenCredential, error) {
+	if GetMode() == Playback {
+		return playbackCredential{}, nil
+	}
+	return azidentity.NewDefaultAzureCredential(nil)
+}
+
+// PollingDelay returns the delay to use between polls of a long-running
+// operation, which is zero in playback mode.
+func PollingDelay(d time.Duration) time.Duration {
+	if GetMode() == Playback {
+		return 0
+	}
+	return d
+}
+
+// playbackCredential authorizes requests with a dummy token, the recorded
+// responses don't need a real one.
+type playbackCredential struct{}
+
+func (playbackCredential) GetToken(ctx context.Context, options azcore.TokenRequestOptions) (*azcore.AccessToken, error) {
+	return &azcore.AccessToken{Token: redacted, ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+func (playbackCredential) AuthenticationPolicy(options azcore.AuthenticationPolicyOptions) azcore.Policy {
+	return azcore.PolicyFunc(func(req *azcore.Request) (*azcore.Response, error) {
+		req.Request.Header.Set("Authorization", "Bearer "+redacted)
+		return req.Next()
+	})
+}
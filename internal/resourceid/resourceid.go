@@ -0,0 +1,196 @@
+// Package resourceid parses and builds Azure Resource Manager resource IDs,
+// such as
+//
+//	/subscriptions/<sub>/resourceGroups/<group>/providers/Microsoft.Network/loadBalancers/<lb>/probes/<probe>
+//
+// including child resources nested in other resources and extension
+// resources of one provider scoped to a resource of another.
+package resourceid
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	subscriptionsKey  = "subscriptions"
+	resourceGroupsKey = "resourceGroups"
+	providersKey      = "providers"
+
+	// resourcesNamespace is the namespace of subscriptions and groups.
+	resourcesNamespace = "Microsoft.Resources"
+)
+
+// ResourceType is the type of a resource, e.g. `Microsoft.Network/loadBalancers/probes`
+// is Namespace `Microsoft.Network` and Types `loadBalancers`, `probes`.
+type ResourceType struct {
+	Namespace string
+	Types     []string
+}
+
+// String returns the type in the form providers list it, e.g.
+// `Microsoft.Network/loadBalancers/probes`.
+func (t ResourceType) String() string {
+	return strings.Join(append([]string{t.Namespace}, t.Types...), "/")
+}
+
+// child returns the type of a resource nested in a resource of this type.
+func (t ResourceType) child(childType string) ResourceType {
+	return ResourceType{Namespace: t.Namespace, Types: append(append([]string(nil), t.Types...), childType)}
+}
+
+// ResourceID identifies a subscription, a resource group or a resource.
+type ResourceID struct {
+	// Parent is the subscription of a group, the group or other scope of a
+	// resource, or the resource a child resource is nested in. It's nil for
+	// subscriptions and tenant-level resources.
+	Parent *ResourceID
+	// SubscriptionID and ResourceGroupName are those the resource is in, if
+	// any.
+	SubscriptionID    string
+	ResourceGroupName string
+	ResourceType      ResourceType
+	Name              string
+
+	// isChild is set for resources nested in their parent, as opposed to
+	// resources scoped to it, which have their own `providers` segment.
+	isChild bool
+}
+
+// Subscription returns the ID of a subscription.
+func Subscription(subscriptionID string) *ResourceID {
+	return &ResourceID{
+		SubscriptionID: subscriptionID,
+		ResourceType:   ResourceType{Namespace: resourcesNamespace, Types: []string{subscriptionsKey}},
+		Name:           subscriptionID,
+	}
+}
+
+// ResourceGroup returns the ID of a resource group.
+func ResourceGroup(subscriptionID, groupName string) *ResourceID {
+	return &ResourceID{
+		Parent:            Subscription(subscriptionID),
+		SubscriptionID:    subscriptionID,
+		ResourceGroupName: groupName,
+		ResourceType:      ResourceType{Namespace: resourcesNamespace, Types: []string{resourceGroupsKey}},
+		Name:              groupName,
+	}
+}
+
+// Resource returns the ID of a resource of a provider, e.g.
+// `Microsoft.Network` and `loadBalancers`, scoped to id. Resources are
+// usually scoped to groups, extension resources to other resources. A nil
+// id scopes the resource to the tenant, e.g. management groups.
+func (id *ResourceID) Resource(namespace, resourceType, name string) *ResourceID {
+	r := &ResourceID{
+		Parent:       id,
+		ResourceType: ResourceType{Namespace: namespace, Types: []string{resourceType}},
+		Name:         name,
+	}
+	if id != nil {
+		r.SubscriptionID = id.SubscriptionID
+		r.ResourceGroupName = id.ResourceGroupName
+	}
+	return r
+}
+
+// Child returns the ID of a resource nested in id, e.g. a load balancer's
+// `probes`.
+func (id *ResourceID) Child(childType, name string) *ResourceID {
+	return &ResourceID{
+		Parent:            id,
+		SubscriptionID:    id.SubscriptionID,
+		ResourceGroupName: id.ResourceGroupName,
+		ResourceType:      id.ResourceType.child(childType),
+		Name:              name,
+		isChild:           true,
+	}
+}
+
+// IsChild reports whether the resource is nested in its parent.
+func (id *ResourceID) IsChild() bool {
+	return id.isChild
+}
+
+// String returns the ID in the form Resource Manager uses.
+func (id *ResourceID) String() string {
+	var b strings.Builder
+	id.write(&b)
+	return b.String()
+}
+
+func (id *ResourceID) write(b *strings.Builder) {
+	if id.Parent != nil {
+		id.Parent.write(b)
+	}
+	switch {
+	case id.isChild:
+		fmt.Fprintf(b, "/%s/%s", id.ResourceType.Types[len(id.ResourceType.Types)-1], id.Name)
+	case id.isSubscription():
+		fmt.Fprintf(b, "/%s/%s", subscriptionsKey, id.Name)
+	case id.isResourceGroup():
+		fmt.Fprintf(b, "/%s/%s", resourceGroupsKey, id.Name)
+	default:
+		fmt.Fprintf(b, "/%s/%s/%s/%s", providersKey, id.ResourceType.Namespace, id.ResourceType.Types[0], id.Name)
+	}
+}
+
+func (id *ResourceID) isSubscription() bool {
+	return id.Parent == nil && id.ResourceType.String() == resourcesNamespace+"/"+subscriptionsKey
+}
+
+func (id *ResourceID) isResourceGroup() bool {
+	return id.Parent != nil && id.Parent.isSubscription() && id.ResourceType.String() == resourcesNamespace+"/"+resourceGroupsKey
+}
+
+// Parse parses a resource ID. The `subscriptions`, `resourceGroups` and
+// `providers` keywords are matched ignoring case, like Resource Manager
+// does; names and types keep their case.
+func Parse(s string) (*ResourceID, error) {
+	if !strings.HasPrefix(s, "/") {
+		return nil, fmt.Errorf("invalid resource ID %q: must start with /", s)
+	}
+	parts := strings.Split(strings.TrimSuffix(s[1:], "/"), "/")
+	for _, part := range parts {
+		if part == "" {
+			return nil, fmt.Errorf("invalid resource ID %q: empty segment", s)
+		}
+	}
+
+	var id *ResourceID
+	for i := 0; i < len(parts); {
+		key := parts[i]
+		switch {
+		case id == nil && strings.EqualFold(key, subscriptionsKey):
+			if i+1 >= len(parts) {
+				return nil, fmt.Errorf("invalid resource ID %q: missing subscription ID", s)
+			}
+			id = Subscription(parts[i+1])
+			i += 2
+		case id != nil && id.isSubscription() && strings.EqualFold(key, resourceGroupsKey):
+			if i+1 >= len(parts) {
+				return nil, fmt.Errorf("invalid resource ID %q: missing resource group name", s)
+			}
+			id = ResourceGroup(id.SubscriptionID, parts[i+1])
+			i += 2
+		case strings.EqualFold(key, providersKey):
+			if i+3 >= len(parts) {
+				return nil, fmt.Errorf("invalid resource ID %q: expected providers/<namespace>/<type>/<name>", s)
+			}
+			id = id.Resource(parts[i+1], parts[i+2], parts[i+3])
+			i += 4
+		case id != nil && !id.isSubscription() && !id.isResourceGroup():
+			if i+1 >= len(parts) {
+				return nil, fmt.Errorf("invalid resource ID %q: missing name of %s", s, key)
+			}
+			id = id.Child(key, parts[i+1])
+			i += 2
+		default:
+			return nil, fmt.Errorf("invalid resource ID %q: unexpected segment %q", s, key)
+		}
+	}
+	if id == nil {
+		return nil, fmt.Errorf("invalid resource ID %q", s)
+	}
+	return id, nil
+}
@@ -0,0 +1,138 @@
+package resourceid
+
+import (
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		id           string
+		subscription string
+		group        string
+		resourceType string
+		name         string
+		parent       string
+	}{
+		{
+			id:           "/subscriptions/sub",
+			subscription: "sub",
+			resourceType: "Microsoft.Resources/subscriptions",
+			name:         "sub",
+		},
+		{
+			id:           "/subscriptions/sub/resourceGroups/group",
+			subscription: "sub",
+			group:        "group",
+			resourceType: "Microsoft.Resources/resourceGroups",
+			name:         "group",
+			parent:       "/subscriptions/sub",
+		},
+		{
+			id:           "/subscriptions/sub/resourceGroups/group/providers/Microsoft.Network/loadBalancers/lb/probes/probe",
+			subscription: "sub",
+			group:        "group",
+			resourceType: "Microsoft.Network/loadBalancers/probes",
+			name:         "probe",
+			parent:       "/subscriptions/sub/resourceGroups/group/providers/Microsoft.Network/loadBalancers/lb",
+		},
+		{
+			id:           "/subscriptions/sub/resourceGroups/group/providers/Microsoft.Storage/storageAccounts/sa/blobServices/default/containers/c",
+			subscription: "sub",
+			group:        "group",
+			resourceType: "Microsoft.Storage/storageAccounts/blobServices/containers",
+			name:         "c",
+			parent:       "/subscriptions/sub/resourceGroups/group/providers/Microsoft.Storage/storageAccounts/sa/blobServices/default",
+		},
+		{
+			id:           "/subscriptions/sub/resourceGroups/group/providers/Microsoft.Compute/virtualMachines/vm/providers/Microsoft.Insights/diagnosticSettings/logs",
+			subscription: "sub",
+			group:        "group",
+			resourceType: "Microsoft.Insights/diagnosticSettings",
+			name:         "logs",
+			parent:       "/subscriptions/sub/resourceGroups/group/providers/Microsoft.Compute/virtualMachines/vm",
+		},
+		{
+			id:           "/subscriptions/sub/providers/Microsoft.Authorization/roleAssignments/ra",
+			subscription: "sub",
+			resourceType: "Microsoft.Authorization/roleAssignments",
+			name:         "ra",
+			parent:       "/subscriptions/sub",
+		},
+		{
+			id:           "/providers/Microsoft.Management/managementGroups/mg",
+			resourceType: "Microsoft.Management/managementGroups",
+			name:         "mg",
+		},
+	}
+	for _, test := range tests {
+		id, err := Parse(test.id)
+		if err != nil {
+			t.Errorf("%s: %v", test.id, err)
+			continue
+		}
+		if id.SubscriptionID != test.subscription || id.ResourceGroupName != test.group ||
+			id.ResourceType.String() != test.resourceType || id.Name != test.name {
+			t.Errorf("%s: parsed as %s %s/%s %s", test.id, id.ResourceType, id.SubscriptionID, id.ResourceGroupName, id.Name)
+		}
+		parent := ""
+		if id.Parent != nil {
+			parent = id.Parent.String()
+		}
+		if parent != test.parent {
+			t.Errorf("%s: expected parent %q, got %q", test.id, test.parent, parent)
+		}
+		if got := id.String(); got != test.id {
+			t.Errorf("expected %s to round-trip, got %s", test.id, got)
+		}
+	}
+}
+
+func TestParseCase(t *testing.T) {
+	id, err := Parse("/SUBSCRIPTIONS/sub/resourcegroups/Group/PROVIDERS/Microsoft.Network/publicIPAddresses/IP/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "/subscriptions/sub/resourceGroups/Group/providers/Microsoft.Network/publicIPAddresses/IP"
+	if id.String() != want {
+		t.Errorf("expected %s, got %s", want, id)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"subscriptions/sub",
+		"/subscriptions",
+		"/subscriptions/sub/resourceGroups",
+		"/subscriptions/sub/resourceGroups/group/providers/Microsoft.Network",
+		"/subscriptions/sub/resourceGroups/group/providers/Microsoft.Network/loadBalancers",
+		"/subscriptions/sub/resourceGroups/group/providers/Microsoft.Network/loadBalancers/lb/probes",
+		"/subscriptions/sub/resourceGroups/group/loadBalancers/lb",
+		"/subscriptions//resourceGroups/group",
+		"/resourceGroups/group",
+	} {
+		if id, err := Parse(s); err == nil {
+			t.Errorf("expected an error for %q, got %s", s, id)
+		}
+	}
+}
+
+func TestBuild(t *testing.T) {
+	lb := ResourceGroup("sub", "group").Resource("Microsoft.Network", "loadBalancers", "lb")
+	probe := lb.Child("probes", "probe")
+	want := "/subscriptions/sub/resourceGroups/group/providers/Microsoft.Network/loadBalancers/lb/probes/probe"
+	if probe.String() != want {
+		t.Errorf("expected %s, got %s", want, probe)
+	}
+	if !probe.IsChild() || lb.IsChild() {
+		t.Errorf("expected only the probe to be a child")
+	}
+	if probe.ResourceType.String() != "Microsoft.Network/loadBalancers/probes" || probe.ResourceGroupName != "group" {
+		t.Errorf("unexpected probe %+v", probe)
+	}
+	// building a child doesn't change the parent's type
+	lb.Child("backendAddressPools", "pool")
+	if lb.ResourceType.String() != "Microsoft.Network/loadBalancers" || probe.ResourceType.String() != "Microsoft.Network/loadBalancers/probes" {
+		t.Errorf("expected types to be independent, got %s and %s", lb.ResourceType, probe.ResourceType)
+	}
+}
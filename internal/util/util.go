@@ -28,11 +28,12 @@ func Contains(array []string, element string) bool {
 }
 
 // ReadJSON reads a json file, and unmashals it.
-// Very useful for template deployments.
+// For template deployments see the `internal/parameters` package, which
+// also checks parameters against the template.
 func ReadJSON(path string) (*map[string]interface{}, error) {
 	data, err := ioutil.ReadFile(path)
 	if err != nil {
-		log.Fatalf("failed to read template file: %v\n", err)
+		return nil, fmt.Errorf("failed to read template file: %v", err)
 	}
 	contents := make(map[string]interface{})
 	if err := json.Unmarshal(data, &contents); err != nil {
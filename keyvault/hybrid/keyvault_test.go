@@ -0,0 +1,74 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package keyvault
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/util"
+	hybridresources "github.com/Azure-Samples/azure-sdk-for-go-samples/resources/hybrid"
+	"github.com/marstr/randname"
+)
+
+var (
+	vaultName  = randname.GenerateWithPrefix("vault-sample-go-", 5)
+	secretName = "secret1"
+)
+
+func TestMain(m *testing.M) {
+	if err := config.ParseEnvironment(); err != nil {
+		log.Fatalf("failed to parse env: %+v", err)
+	}
+	if err := config.AddFlags(); err != nil {
+		log.Fatalf("failed to add flags: %+v", err)
+	}
+	flag.Parse()
+	os.Exit(m.Run())
+}
+
+func ExampleCreateVault() {
+	var groupName = config.GenerateGroupName("HybridKeyVault")
+	config.SetGroupName(groupName)
+
+	ctx := context.Background()
+	defer hybridresources.Cleanup(ctx)
+	_, err := hybridresources.CreateGroup(ctx)
+	if err != nil {
+		util.LogAndPanic(err)
+	}
+
+	vault, err := CreateVault(ctx, vaultName, os.Getenv("AZURE_SP_OBJECT_ID"))
+	if err != nil {
+		util.LogAndPanic(err)
+	}
+	util.PrintAndLog("created vault")
+
+	_, err = SetSecret(ctx, *vault.Properties.VaultURI, secretName, "sample value")
+	if err != nil {
+		util.LogAndPanic(err)
+	}
+	secret, err := GetSecret(ctx, *vault.Properties.VaultURI, secretName, "")
+	if err != nil {
+		util.LogAndPanic(err)
+	}
+	util.PrintAndLog("got secret: " + *secret.Value)
+
+	_, err = DeleteVault(ctx, vaultName)
+	if err != nil {
+		util.LogAndPanic(err)
+	}
+	util.PrintAndLog("deleted vault")
+
+	// Output:
+	// created vault
+	// got secret: sample value
+	// deleted vault
+}
@@ -0,0 +1,43 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package keyvault
+
+import (
+	"context"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/hybrid"
+	"github.com/Azure/azure-sdk-for-go/profiles/2019-03-01/keyvault/keyvault"
+	"github.com/Azure/go-autorest/autorest/to"
+)
+
+// Secrets are read and written at the vault's URL, `Properties.VaultURI` of
+// the vault returned by GetVault.
+
+func getSecretsClient() keyvault.BaseClient {
+	secretsClient := keyvault.New()
+	hybrid.ConfigureKeyvault(&secretsClient.Client)
+	return secretsClient
+}
+
+// SetSecret sets the value of a secret, adding a new version if it exists.
+func SetSecret(ctx context.Context, vaultURL, secretName, value string) (keyvault.SecretBundle, error) {
+	secretsClient := getSecretsClient()
+	return secretsClient.SetSecret(ctx, vaultURL, secretName, keyvault.SecretSetParameters{
+		Value: to.StringPtr(value),
+	})
+}
+
+// GetSecret gets a secret, its latest version if secretVersion is empty.
+func GetSecret(ctx context.Context, vaultURL, secretName, secretVersion string) (keyvault.SecretBundle, error) {
+	secretsClient := getSecretsClient()
+	return secretsClient.GetSecret(ctx, vaultURL, secretName, secretVersion)
+}
+
+// DeleteSecret deletes all versions of a secret.
+func DeleteSecret(ctx context.Context, vaultURL, secretName string) (keyvault.DeletedSecretBundle, error) {
+	secretsClient := getSecretsClient()
+	return secretsClient.DeleteSecret(ctx, vaultURL, secretName)
+}
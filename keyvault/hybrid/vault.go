@@ -0,0 +1,84 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package keyvault
+
+import (
+	"context"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/hybrid"
+	"github.com/Azure/azure-sdk-for-go/profiles/2019-03-01/keyvault/mgmt/keyvault"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
+	uuid "github.com/gofrs/uuid"
+)
+
+func getVaultsClient(ctx context.Context) keyvault.VaultsClient {
+	vaultsClient := keyvault.NewVaultsClientWithBaseURI(hybrid.Endpoint(ctx))
+	hybrid.Configure(&vaultsClient.Client)
+	return vaultsClient
+}
+
+// CreateVault creates a new vault. If objectID is set, the principal with
+// that object ID is allowed to manage the vault's secrets.
+func CreateVault(ctx context.Context, vaultName, objectID string) (keyvault.Vault, error) {
+	vaultsClient := getVaultsClient(ctx)
+	tenantID, err := uuid.FromString(config.TenantID())
+	if err != nil {
+		return keyvault.Vault{}, err
+	}
+
+	accessPolicies := []keyvault.AccessPolicyEntry{}
+	if objectID != "" {
+		accessPolicies = append(accessPolicies, keyvault.AccessPolicyEntry{
+			ObjectID: to.StringPtr(objectID),
+			TenantID: &tenantID,
+			Permissions: &keyvault.Permissions{
+				Secrets: &[]keyvault.SecretPermissions{
+					keyvault.SecretPermissionsGet,
+					keyvault.SecretPermissionsList,
+					keyvault.SecretPermissionsSet,
+					keyvault.SecretPermissionsDelete,
+				},
+			},
+		})
+	}
+
+	return vaultsClient.CreateOrUpdate(
+		ctx,
+		config.ScopeFrom(ctx).GroupName,
+		vaultName,
+		keyvault.VaultCreateOrUpdateParameters{
+			Location: to.StringPtr(config.ScopeFrom(ctx).Location),
+			Properties: &keyvault.VaultProperties{
+				TenantID: &tenantID,
+				Sku: &keyvault.Sku{
+					Family: to.StringPtr("A"),
+					Name:   keyvault.Standard,
+				},
+				AccessPolicies: &accessPolicies,
+			},
+		},
+	)
+}
+
+// GetVault returns an existing vault.
+func GetVault(ctx context.Context, vaultName string) (keyvault.Vault, error) {
+	vaultsClient := getVaultsClient(ctx)
+	return vaultsClient.Get(ctx, config.ScopeFrom(ctx).GroupName, vaultName)
+}
+
+// ListVaults lists the vaults in the resource group.
+func ListVaults(ctx context.Context) (keyvault.VaultListResultIterator, error) {
+	vaultsClient := getVaultsClient(ctx)
+	return vaultsClient.ListByResourceGroupComplete(ctx, config.ScopeFrom(ctx).GroupName, nil)
+}
+
+// DeleteVault deletes an existing vault.
+func DeleteVault(ctx context.Context, vaultName string) (autorest.Response, error) {
+	vaultsClient := getVaultsClient(ctx)
+	return vaultsClient.Delete(ctx, config.ScopeFrom(ctx).GroupName, vaultName)
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/services/keyvault/2016-10-01/keyvault"
 	"github.com/Azure/go-autorest/autorest/to"
 )
@@ -14,13 +15,14 @@ func getKeysClient() keyvault.BaseClient {
 	a, _ := iam.GetKeyvaultAuthorizer()
 	keyClient.Authorizer = a
 	keyClient.AddToUserAgent(config.UserAgent())
+	keyClient.Sender = recording.Sender()
 	return keyClient
 }
 
 // CreateKeyBundle creates a key in the specified keyvault
 func CreateKey(ctx context.Context, vaultName, keyName string) (key keyvault.KeyBundle, err error) {
-	vaultsClient := getVaultsClient()
-	vault, err := vaultsClient.Get(ctx, config.GroupName(), vaultName)
+	vaultsClient := getVaultsClient(ctx)
+	vault, err := vaultsClient.Get(ctx, config.ScopeFrom(ctx).GroupName, vaultName)
 	if err != nil {
 		return
 	}
@@ -0,0 +1,30 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package keyvault
+
+import (
+	"context"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure/azure-sdk-for-go/services/keyvault/2016-10-01/keyvault"
+)
+
+// VaultURL returns the URL of the keys and secrets of a vault in the
+// configured cloud.
+func VaultURL(vaultName string) (string, error) {
+	env, err := config.Environment()
+	if err != nil {
+		return "", err
+	}
+	return "https://" + vaultName + "." + env.KeyVaultDNSSuffix, nil
+}
+
+// GetSecret gets a secret from the vault at vaultURL, its latest version if
+// secretVersion is empty.
+func GetSecret(ctx context.Context, vaultURL, secretName, secretVersion string) (keyvault.SecretBundle, error) {
+	secretsClient := getKeysClient()
+	return secretsClient.GetSecret(ctx, vaultURL, secretName, secretVersion)
+}
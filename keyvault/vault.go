@@ -15,22 +15,24 @@ import (
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/graphrbac"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/go-autorest/autorest"
 	"github.com/Azure/go-autorest/autorest/to"
 	uuid "github.com/gofrs/uuid"
 )
 
-func getVaultsClient() keyvault.VaultsClient {
-	vaultsClient := keyvault.NewVaultsClient(config.SubscriptionID())
+func getVaultsClient(ctx context.Context) keyvault.VaultsClient {
+	vaultsClient := keyvault.NewVaultsClient(config.ScopeFrom(ctx).SubscriptionID)
 	a, _ := iam.GetResourceManagementAuthorizer()
 	vaultsClient.Authorizer = a
 	vaultsClient.AddToUserAgent(config.UserAgent())
+	vaultsClient.Sender = recording.Sender()
 	return vaultsClient
 }
 
 // CreateVault creates a new vault
 func CreateVault(ctx context.Context, vaultName string) (keyvault.Vault, error) {
-	vaultsClient := getVaultsClient()
+	vaultsClient := getVaultsClient(ctx)
 	tenantID, err := uuid.FromString(config.TenantID())
 	if err != nil {
 		return keyvault.Vault{}, err
@@ -38,10 +40,10 @@ func CreateVault(ctx context.Context, vaultName string) (keyvault.Vault, error)
 
 	return vaultsClient.CreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		vaultName,
 		keyvault.VaultCreateOrUpdateParameters{
-			Location: to.StringPtr(config.Location()),
+			Location: to.StringPtr(config.ScopeFrom(ctx).Location),
 			Properties: &keyvault.VaultProperties{
 				TenantID: &tenantID,
 				Sku: &keyvault.Sku{
@@ -56,13 +58,13 @@ func CreateVault(ctx context.Context, vaultName string) (keyvault.Vault, error)
 
 // GetVault returns an existing vault
 func GetVault(ctx context.Context, vaultName string) (keyvault.Vault, error) {
-	vaultsClient := getVaultsClient()
-	return vaultsClient.Get(ctx, config.GroupName(), vaultName)
+	vaultsClient := getVaultsClient(ctx)
+	return vaultsClient.Get(ctx, config.ScopeFrom(ctx).GroupName, vaultName)
 }
 
 // CreateVaultWithPolicies creates a new Vault with policies granting access to the specified user.
 func CreateVaultWithPolicies(ctx context.Context, vaultName, userID string) (vault keyvault.Vault, err error) {
-	vaultsClient := getVaultsClient()
+	vaultsClient := getVaultsClient(ctx)
 
 	tenantID, err := uuid.FromString(config.TenantID())
 	if err != nil {
@@ -88,10 +90,10 @@ func CreateVaultWithPolicies(ctx context.Context, vaultName, userID string) (vau
 
 	return vaultsClient.CreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		vaultName,
 		keyvault.VaultCreateOrUpdateParameters{
-			Location: to.StringPtr(config.Location()),
+			Location: to.StringPtr(config.ScopeFrom(ctx).Location),
 			Properties: &keyvault.VaultProperties{
 				AccessPolicies:           &apList,
 				EnabledForDiskEncryption: to.BoolPtr(true),
@@ -107,7 +109,7 @@ func CreateVaultWithPolicies(ctx context.Context, vaultName, userID string) (vau
 
 // SetVaultPermissions adds an access policy permitting this app's Client ID to manage keys and secrets.
 func SetVaultPermissions(ctx context.Context, vaultName string) (keyvault.Vault, error) {
-	vaultsClient := getVaultsClient()
+	vaultsClient := getVaultsClient(ctx)
 
 	tenantID, err := uuid.FromString(config.TenantID())
 	if err != nil {
@@ -121,10 +123,10 @@ func SetVaultPermissions(ctx context.Context, vaultName string) (keyvault.Vault,
 
 	return vaultsClient.CreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		vaultName,
 		keyvault.VaultCreateOrUpdateParameters{
-			Location: to.StringPtr(config.Location()),
+			Location: to.StringPtr(config.ScopeFrom(ctx).Location),
 			Properties: &keyvault.VaultProperties{
 				TenantID: &tenantID,
 				Sku: &keyvault.Sku{
@@ -155,7 +157,7 @@ func SetVaultPermissions(ctx context.Context, vaultName string) (keyvault.Vault,
 
 // SetVaultPermissionsForDeployment updates a key vault to enable deployments and add permissions to the application
 func SetVaultPermissionsForDeployment(ctx context.Context, vaultName string) (keyvault.Vault, error) {
-	vaultsClient := getVaultsClient()
+	vaultsClient := getVaultsClient(ctx)
 	tenantID, err := uuid.FromString(config.TenantID())
 	if err != nil {
 		return keyvault.Vault{}, err
@@ -164,10 +166,10 @@ func SetVaultPermissionsForDeployment(ctx context.Context, vaultName string) (ke
 
 	return vaultsClient.CreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		vaultName,
 		keyvault.VaultCreateOrUpdateParameters{
-			Location: to.StringPtr(config.Location()),
+			Location: to.StringPtr(config.ScopeFrom(ctx).Location),
 			Properties: &keyvault.VaultProperties{
 				TenantID:                     &tenantID,
 				EnabledForDeployment:         to.BoolPtr(true),
@@ -201,10 +203,11 @@ func SetVaultPermissionsForDeployment(ctx context.Context, vaultName string) (ke
 
 // GetVaults lists all key vaults in a subscription
 func GetVaults() {
-	vaultsClient := getVaultsClient()
+	ctx := context.Background()
+	vaultsClient := getVaultsClient(ctx)
 
 	fmt.Println("Getting all vaults in subscription")
-	for subList, err := vaultsClient.ListComplete(context.Background(), nil); subList.NotDone(); err = subList.Next() {
+	for subList, err := vaultsClient.ListComplete(ctx, nil); subList.NotDone(); err = subList.Next() {
 		if err != nil {
 			log.Printf("failed to get list of vaults: %v", err)
 		}
@@ -212,7 +215,7 @@ func GetVaults() {
 	}
 
 	fmt.Println("Getting all vaults in resource group")
-	for rgList, err := vaultsClient.ListByResourceGroupComplete(context.Background(), config.GroupName(), nil); rgList.NotDone(); err = rgList.Next() {
+	for rgList, err := vaultsClient.ListByResourceGroupComplete(ctx, config.ScopeFrom(ctx).GroupName, nil); rgList.NotDone(); err = rgList.Next() {
 		if err != nil {
 			log.Printf("failed to get list of vaults: %v", err)
 		}
@@ -222,6 +225,6 @@ func GetVaults() {
 
 // DeleteVault deletes an existing vault
 func DeleteVault(ctx context.Context, vaultName string) (autorest.Response, error) {
-	vaultsClient := getVaultsClient()
-	return vaultsClient.Delete(ctx, config.GroupName(), vaultName)
+	vaultsClient := getVaultsClient(ctx)
+	return vaultsClient.Delete(ctx, config.ScopeFrom(ctx).GroupName, vaultName)
 }
@@ -10,6 +10,7 @@ import (
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/services/msi/mgmt/2018-11-30/msi"
 	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/pkg/errors"
@@ -23,6 +24,7 @@ func getMSIUserAssignedIDClient() (*msi.UserAssignedIdentitiesClient, error) {
 	msiClient := msi.NewUserAssignedIdentitiesClient(config.SubscriptionID())
 	msiClient.Authorizer = a
 	msiClient.AddToUserAgent(config.UserAgent())
+	msiClient.Sender = recording.Sender()
 	return &msiClient, nil
 }
 
@@ -6,31 +6,33 @@ import (
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	mysql "github.com/Azure/azure-sdk-for-go/services/preview/mysql/mgmt/2020-07-01-preview/mysqlflexibleservers"
 	"github.com/Azure/go-autorest/autorest"
 	"github.com/Azure/go-autorest/autorest/to"
 )
 
 // GetServersClient returns
-func getServersClient() mysql.ServersClient {
-	serversClient := mysql.NewServersClient(config.SubscriptionID())
+func getServersClient(ctx context.Context) mysql.ServersClient {
+	serversClient := mysql.NewServersClient(config.ScopeFrom(ctx).SubscriptionID)
 	a, _ := iam.GetResourceManagementAuthorizer()
 	serversClient.Authorizer = a
 	serversClient.AddToUserAgent(config.UserAgent())
+	serversClient.Sender = recording.Sender()
 	return serversClient
 }
 
 // CreateServer creates a new MySQL Server
 func CreateServer(ctx context.Context, serverName, dbLogin, dbPassword string) (server mysql.Server, err error) {
-	serversClient := getServersClient()
+	serversClient := getServersClient(ctx)
 
 	// Create the server
 	future, err := serversClient.Create(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		serverName,
 		mysql.Server{
-			Location: to.StringPtr(config.Location()),
+			Location: to.StringPtr(config.ScopeFrom(ctx).Location),
 			Sku: &mysql.Sku{
 				Name: to.StringPtr("Standard_D16ds_v4"),
 				Tier: "GeneralPurpose",
@@ -59,11 +61,11 @@ func CreateServer(ctx context.Context, serverName, dbLogin, dbPassword string) (
 
 // UpdateServerStorageCapacity given the server name and the new storage capacity it updates the server's storage capacity.
 func UpdateServerStorageCapacity(ctx context.Context, serverName string, storageCapacity int32) (server mysql.Server, err error) {
-	serversClient := getServersClient()
+	serversClient := getServersClient(ctx)
 
 	future, err := serversClient.Update(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		serverName,
 		mysql.ServerForUpdate{
 			ServerPropertiesForUpdate: &mysql.ServerPropertiesForUpdate{
@@ -87,9 +89,9 @@ func UpdateServerStorageCapacity(ctx context.Context, serverName string, storage
 
 // DeleteServer deletes the MySQL server.
 func DeleteServer(ctx context.Context, serverName string) (resp autorest.Response, err error) {
-	serversClient := getServersClient()
+	serversClient := getServersClient(ctx)
 
-	future, err := serversClient.Delete(ctx, config.GroupName(), serverName)
+	future, err := serversClient.Delete(ctx, config.ScopeFrom(ctx).GroupName, serverName)
 	if err != nil {
 		return resp, fmt.Errorf("cannot delete the mysql server: %v", err)
 	}
@@ -103,21 +105,22 @@ func DeleteServer(ctx context.Context, serverName string) (resp autorest.Respons
 }
 
 // GetFwRulesClient returns the FirewallClient
-func getFwRulesClient() mysql.FirewallRulesClient {
-	fwrClient := mysql.NewFirewallRulesClient(config.SubscriptionID())
+func getFwRulesClient(ctx context.Context) mysql.FirewallRulesClient {
+	fwrClient := mysql.NewFirewallRulesClient(config.ScopeFrom(ctx).SubscriptionID)
 	a, _ := iam.GetResourceManagementAuthorizer()
 	fwrClient.Authorizer = a
 	fwrClient.AddToUserAgent(config.UserAgent())
+	fwrClient.Sender = recording.Sender()
 	return fwrClient
 }
 
 // CreateOrUpdateFirewallRule given the firewallname and new properties it updates the firewall rule.
 func CreateOrUpdateFirewallRule(ctx context.Context, serverName, firewallRuleName, startIPAddr, endIPAddr string) error {
-	fwrClient := getFwRulesClient()
+	fwrClient := getFwRulesClient(ctx)
 
 	_, err := fwrClient.CreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		serverName,
 		firewallRuleName,
 		mysql.FirewallRule{
@@ -132,20 +135,21 @@ func CreateOrUpdateFirewallRule(ctx context.Context, serverName, firewallRuleNam
 }
 
 // GetConfigurationsClient creates and returns the configuration client for the server.
-func getConfigurationsClient() mysql.ConfigurationsClient {
-	configClient := mysql.NewConfigurationsClient(config.SubscriptionID())
+func getConfigurationsClient(ctx context.Context) mysql.ConfigurationsClient {
+	configClient := mysql.NewConfigurationsClient(config.ScopeFrom(ctx).SubscriptionID)
 	a, _ := iam.GetResourceManagementAuthorizer()
 	configClient.Authorizer = a
 	configClient.AddToUserAgent(config.UserAgent())
+	configClient.Sender = recording.Sender()
 	return configClient
 }
 
 // GetConfiguration given the server name and configuration name it returns the configuration.
 func GetConfiguration(ctx context.Context, serverName, configurationName string) (mysql.Configuration, error) {
-	configClient := getConfigurationsClient()
+	configClient := getConfigurationsClient(ctx)
 
 	// Get the configuration.
-	configuration, err := configClient.Get(ctx, config.GroupName(), serverName, configurationName)
+	configuration, err := configClient.Get(ctx, config.ScopeFrom(ctx).GroupName, serverName, configurationName)
 
 	if err != nil {
 		return configuration, fmt.Errorf("cannot get the configuration with name %s", configurationName)
@@ -156,9 +160,9 @@ func GetConfiguration(ctx context.Context, serverName, configurationName string)
 
 // UpdateConfiguration given the name of the configuation and the configuration object it updates the configuration for the given server.
 func UpdateConfiguration(ctx context.Context, serverName string, configurationName string, configuration mysql.Configuration) (updatedConfig mysql.Configuration, err error) {
-	configClient := getConfigurationsClient()
+	configClient := getConfigurationsClient(ctx)
 
-	future, err := configClient.Update(ctx, config.GroupName(), serverName, configurationName, configuration)
+	future, err := configClient.Update(ctx, config.ScopeFrom(ctx).GroupName, serverName, configurationName, configuration)
 
 	if err != nil {
 		return updatedConfig, fmt.Errorf("cannot update the configuration with name %s", configurationName)
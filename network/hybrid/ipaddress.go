@@ -0,0 +1,95 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package network
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/hybrid"
+	"github.com/Azure/azure-sdk-for-go/profiles/2019-03-01/network/mgmt/network"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
+)
+
+func getIPClient(ctx context.Context) network.PublicIPAddressesClient {
+	ipClient := network.NewPublicIPAddressesClientWithBaseURI(hybrid.Endpoint(ctx))
+	hybrid.Configure(&ipClient.Client)
+	return ipClient
+}
+
+// CreatePublicIP creates a new public IP
+func CreatePublicIP(ctx context.Context, ipName string) (ip network.PublicIPAddress, err error) {
+	resourceName := "public IP"
+	ipClient := getIPClient(ctx)
+	future, err := ipClient.CreateOrUpdate(
+		ctx,
+		config.ScopeFrom(ctx).GroupName,
+		ipName,
+		network.PublicIPAddress{
+			Name:     to.StringPtr(ipName),
+			Location: to.StringPtr(config.ScopeFrom(ctx).Location),
+			PublicIPAddressPropertiesFormat: &network.PublicIPAddressPropertiesFormat{
+				PublicIPAllocationMethod: network.Static,
+			},
+		},
+	)
+
+	if err != nil {
+		return ip, fmt.Errorf(fmt.Sprintf(errorPrefix, resourceName, err))
+	}
+
+	err = future.WaitForCompletionRef(ctx, ipClient.Client)
+	if err != nil {
+		return ip, fmt.Errorf(fmt.Sprintf(errorPrefix, resourceName, fmt.Sprintf("cannot get public ip address create or update future response: %v", err)))
+	}
+	return future.Result(ipClient)
+}
+
+// GetPublicIP retrieves a public IP by its name
+func GetPublicIP(ctx context.Context, ipName string) (network.PublicIPAddress, error) {
+	ipClient := getIPClient(ctx)
+	return ipClient.Get(ctx, config.ScopeFrom(ctx).GroupName, ipName, "")
+}
+
+// ListPublicIPs gets an iterator over the public IPs in the resource group
+func ListPublicIPs(ctx context.Context) (network.PublicIPAddressListResultIterator, error) {
+	ipClient := getIPClient(ctx)
+	return ipClient.ListComplete(ctx, config.ScopeFrom(ctx).GroupName)
+}
+
+// UpdatePublicIPTags replaces the tags of a public IP
+func UpdatePublicIPTags(ctx context.Context, ipName string, tags map[string]*string) (ip network.PublicIPAddress, err error) {
+	ipClient := getIPClient(ctx)
+	future, err := ipClient.UpdateTags(ctx, config.ScopeFrom(ctx).GroupName, ipName, network.TagsObject{Tags: tags})
+	if err != nil {
+		return ip, fmt.Errorf("cannot update public ip tags: %v", err)
+	}
+
+	err = future.WaitForCompletionRef(ctx, ipClient.Client)
+	if err != nil {
+		return ip, fmt.Errorf("cannot get the public ip update tags future response: %v", err)
+	}
+
+	return future.Result(ipClient)
+}
+
+// DeletePublicIP deletes a public IP
+func DeletePublicIP(ctx context.Context, ipName string) (ar autorest.Response, err error) {
+	ipClient := getIPClient(ctx)
+	future, err := ipClient.Delete(ctx, config.ScopeFrom(ctx).GroupName, ipName)
+	if err != nil {
+		return ar, fmt.Errorf("cannot delete public IP: %v", err)
+	}
+
+	err = future.WaitForCompletionRef(ctx, ipClient.Client)
+	if err != nil {
+		return ar, fmt.Errorf("cannot get the public IP delete future response: %v", err)
+	}
+
+	return future.Result(ipClient)
+}
@@ -0,0 +1,181 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package network
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/hybrid"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/resourceid"
+	"github.com/Azure/azure-sdk-for-go/profiles/2019-03-01/network/mgmt/network"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
+)
+
+func getLBClient(ctx context.Context) network.LoadBalancersClient {
+	lbClient := network.NewLoadBalancersClientWithBaseURI(hybrid.Endpoint(ctx))
+	hybrid.Configure(&lbClient.Client)
+	return lbClient
+}
+
+// GetLoadBalancer gets info on a loadbalancer
+func GetLoadBalancer(ctx context.Context, lbName string) (network.LoadBalancer, error) {
+	lbClient := getLBClient(ctx)
+	return lbClient.Get(ctx, config.ScopeFrom(ctx).GroupName, lbName, "")
+}
+
+// CreateLoadBalancer creates a load balancer with 2 inbound NAT rules, in
+// front of the public IP pipName.
+func CreateLoadBalancer(ctx context.Context, lbName, pipName string) (lb network.LoadBalancer, err error) {
+	probeName := "probe"
+	frontEndIPConfigName := "fip"
+	backEndAddressPoolName := "backEndPool"
+	scope := config.ScopeFrom(ctx)
+	lbID := resourceid.ResourceGroup(scope.SubscriptionID, scope.GroupName).Resource("Microsoft.Network", "loadBalancers", lbName)
+
+	pip, err := GetPublicIP(ctx, pipName)
+	if err != nil {
+		return
+	}
+
+	lbClient := getLBClient(ctx)
+	future, err := lbClient.CreateOrUpdate(ctx,
+		config.ScopeFrom(ctx).GroupName,
+		lbName,
+		network.LoadBalancer{
+			Location: to.StringPtr(config.ScopeFrom(ctx).Location),
+			LoadBalancerPropertiesFormat: &network.LoadBalancerPropertiesFormat{
+				FrontendIPConfigurations: &[]network.FrontendIPConfiguration{
+					{
+						Name: &frontEndIPConfigName,
+						FrontendIPConfigurationPropertiesFormat: &network.FrontendIPConfigurationPropertiesFormat{
+							PrivateIPAllocationMethod: network.Dynamic,
+							PublicIPAddress:           &pip,
+						},
+					},
+				},
+				BackendAddressPools: &[]network.BackendAddressPool{
+					{
+						Name: &backEndAddressPoolName,
+					},
+				},
+				Probes: &[]network.Probe{
+					{
+						Name: &probeName,
+						ProbePropertiesFormat: &network.ProbePropertiesFormat{
+							Protocol:          network.ProbeProtocolHTTP,
+							Port:              to.Int32Ptr(80),
+							IntervalInSeconds: to.Int32Ptr(15),
+							NumberOfProbes:    to.Int32Ptr(4),
+							RequestPath:       to.StringPtr("healthprobe.aspx"),
+						},
+					},
+				},
+				LoadBalancingRules: &[]network.LoadBalancingRule{
+					{
+						Name: to.StringPtr("lbRule"),
+						LoadBalancingRulePropertiesFormat: &network.LoadBalancingRulePropertiesFormat{
+							Protocol:             network.TransportProtocolTCP,
+							FrontendPort:         to.Int32Ptr(80),
+							BackendPort:          to.Int32Ptr(80),
+							IdleTimeoutInMinutes: to.Int32Ptr(4),
+							EnableFloatingIP:     to.BoolPtr(false),
+							LoadDistribution:     network.Default,
+							FrontendIPConfiguration: &network.SubResource{
+								ID: to.StringPtr(lbID.Child("frontendIPConfigurations", frontEndIPConfigName).String()),
+							},
+							BackendAddressPool: &network.SubResource{
+								ID: to.StringPtr(lbID.Child("backendAddressPools", backEndAddressPoolName).String()),
+							},
+							Probe: &network.SubResource{
+								ID: to.StringPtr(lbID.Child("probes", probeName).String()),
+							},
+						},
+					},
+				},
+				InboundNatRules: &[]network.InboundNatRule{
+					{
+						Name: to.StringPtr("natRule1"),
+						InboundNatRulePropertiesFormat: &network.InboundNatRulePropertiesFormat{
+							Protocol:             network.TransportProtocolTCP,
+							FrontendPort:         to.Int32Ptr(21),
+							BackendPort:          to.Int32Ptr(22),
+							EnableFloatingIP:     to.BoolPtr(false),
+							IdleTimeoutInMinutes: to.Int32Ptr(4),
+							FrontendIPConfiguration: &network.SubResource{
+								ID: to.StringPtr(lbID.Child("frontendIPConfigurations", frontEndIPConfigName).String()),
+							},
+						},
+					},
+					{
+						Name: to.StringPtr("natRule2"),
+						InboundNatRulePropertiesFormat: &network.InboundNatRulePropertiesFormat{
+							Protocol:             network.TransportProtocolTCP,
+							FrontendPort:         to.Int32Ptr(23),
+							BackendPort:          to.Int32Ptr(22),
+							EnableFloatingIP:     to.BoolPtr(false),
+							IdleTimeoutInMinutes: to.Int32Ptr(4),
+							FrontendIPConfiguration: &network.SubResource{
+								ID: to.StringPtr(lbID.Child("frontendIPConfigurations", frontEndIPConfigName).String()),
+							},
+						},
+					},
+				},
+			},
+		})
+
+	if err != nil {
+		return lb, fmt.Errorf("cannot create load balancer: %v", err)
+	}
+
+	err = future.WaitForCompletionRef(ctx, lbClient.Client)
+	if err != nil {
+		return lb, fmt.Errorf("cannot get load balancer create or update future response: %v", err)
+	}
+
+	return future.Result(lbClient)
+}
+
+// ListLoadBalancers gets an iterator over the load balancers in the resource
+// group
+func ListLoadBalancers(ctx context.Context) (network.LoadBalancerListResultIterator, error) {
+	lbClient := getLBClient(ctx)
+	return lbClient.ListComplete(ctx, config.ScopeFrom(ctx).GroupName)
+}
+
+// UpdateLoadBalancerTags replaces the tags of a load balancer
+func UpdateLoadBalancerTags(ctx context.Context, lbName string, tags map[string]*string) (lb network.LoadBalancer, err error) {
+	lbClient := getLBClient(ctx)
+	future, err := lbClient.UpdateTags(ctx, config.ScopeFrom(ctx).GroupName, lbName, network.TagsObject{Tags: tags})
+	if err != nil {
+		return lb, fmt.Errorf("cannot update load balancer tags: %v", err)
+	}
+
+	err = future.WaitForCompletionRef(ctx, lbClient.Client)
+	if err != nil {
+		return lb, fmt.Errorf("cannot get the load balancer update tags future response: %v", err)
+	}
+
+	return future.Result(lbClient)
+}
+
+// DeleteLoadBalancer deletes a load balancer
+func DeleteLoadBalancer(ctx context.Context, lbName string) (ar autorest.Response, err error) {
+	lbClient := getLBClient(ctx)
+	future, err := lbClient.Delete(ctx, config.ScopeFrom(ctx).GroupName, lbName)
+	if err != nil {
+		return ar, fmt.Errorf("cannot delete load balancer: %v", err)
+	}
+
+	err = future.WaitForCompletionRef(ctx, lbClient.Client)
+	if err != nil {
+		return ar, fmt.Errorf("cannot get the load balancer delete future response: %v", err)
+	}
+
+	return future.Result(lbClient)
+}
@@ -138,3 +138,36 @@ func ExampleCreateNetworkInterface() {
 	// Output:
 	// Network interface created
 }
+
+func ExampleCreateLoadBalancer() {
+	groupName := config.GenerateGroupName("HybridLoadBalancer")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
+	defer hybridresources.Cleanup(ctx)
+
+	_, err := hybridresources.CreateGroup(ctx)
+	if err != nil {
+		util.LogAndPanic(err)
+	}
+	_, err = CreatePublicIP(ctx, ipName)
+	if err != nil {
+		util.LogAndPanic(fmt.Errorf("cannot create public IP. Error details: %+v", err))
+	}
+	_, err = CreateLoadBalancer(ctx, "lb1", ipName)
+	if err != nil {
+		util.LogAndPanic(fmt.Errorf("cannot create load balancer. Error details: %+v", err))
+	}
+	fmt.Println("Load balancer created")
+
+	_, err = DeleteLoadBalancer(ctx, "lb1")
+	if err != nil {
+		util.LogAndPanic(fmt.Errorf("cannot delete load balancer. Error details: %+v", err))
+	}
+	fmt.Println("Load balancer deleted")
+
+	// Output:
+	// Load balancer created
+	// Load balancer deleted
+}
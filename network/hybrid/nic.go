@@ -0,0 +1,171 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package network
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/hybrid"
+	"github.com/Azure/azure-sdk-for-go/profiles/2019-03-01/network/mgmt/network"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
+)
+
+func getNicClient(ctx context.Context) network.InterfacesClient {
+	nicClient := network.NewInterfacesClientWithBaseURI(hybrid.Endpoint(ctx))
+	hybrid.Configure(&nicClient.Client)
+	return nicClient
+}
+
+// CreateNetworkInterface creates a new network interface
+func CreateNetworkInterface(ctx context.Context, netInterfaceName, nsgName, vnetName, subnetName, ipName string) (nic network.Interface, err error) {
+	resourceName := "network interface"
+	nsg, err := GetNetworkSecurityGroup(ctx, nsgName)
+	if err != nil {
+		return nic, fmt.Errorf(fmt.Sprintf(errorPrefix, resourceName, fmt.Sprintf("failed to get netwrok security group: %v", err)))
+	}
+	subnet, err := GetVirtualNetworkSubnet(ctx, vnetName, subnetName)
+	if err != nil {
+		return nic, fmt.Errorf(fmt.Sprintf(errorPrefix, resourceName, fmt.Sprintf("failed to get subnet: %v", err)))
+	}
+	ip, err := GetPublicIP(ctx, ipName)
+	if err != nil {
+		return nic, fmt.Errorf(fmt.Sprintf(errorPrefix, resourceName, fmt.Sprintf("failed to get ip address: %v", err)))
+	}
+	nicClient := getNicClient(ctx)
+	future, err := nicClient.CreateOrUpdate(
+		ctx,
+		config.ScopeFrom(ctx).GroupName,
+		netInterfaceName,
+		network.Interface{
+			Name:     to.StringPtr(netInterfaceName),
+			Location: to.StringPtr(config.ScopeFrom(ctx).Location),
+			InterfacePropertiesFormat: &network.InterfacePropertiesFormat{
+				NetworkSecurityGroup: &nsg,
+				IPConfigurations: &[]network.InterfaceIPConfiguration{
+					{
+						Name: to.StringPtr("ipConfig1"),
+						InterfaceIPConfigurationPropertiesFormat: &network.InterfaceIPConfigurationPropertiesFormat{
+							Subnet:                    &subnet,
+							PrivateIPAllocationMethod: network.Dynamic,
+							PublicIPAddress:           &ip,
+						},
+					},
+				},
+			},
+		},
+	)
+	if err != nil {
+		return nic, fmt.Errorf(fmt.Sprintf(errorPrefix, resourceName, err))
+	}
+	err = future.WaitForCompletionRef(ctx, nicClient.Client)
+	if err != nil {
+		return nic, fmt.Errorf(fmt.Sprintf(errorPrefix, resourceName, fmt.Sprintf("cannot get nic create or update future response: %v", err)))
+	}
+	return future.Result(nicClient)
+}
+
+// CreateNetworkInterfaceWithLoadBalancer creates a network interface in the
+// backend pool of a load balancer created by CreateLoadBalancer, reachable
+// through one of its inbound NAT rules, 0 or 1.
+func CreateNetworkInterfaceWithLoadBalancer(ctx context.Context, netInterfaceName, lbName, vnetName, subnetName string, natRule int) (nic network.Interface, err error) {
+	resourceName := "network interface"
+	subnet, err := GetVirtualNetworkSubnet(ctx, vnetName, subnetName)
+	if err != nil {
+		return nic, fmt.Errorf(fmt.Sprintf(errorPrefix, resourceName, fmt.Sprintf("failed to get subnet: %v", err)))
+	}
+	lb, err := GetLoadBalancer(ctx, lbName)
+	if err != nil {
+		return nic, fmt.Errorf(fmt.Sprintf(errorPrefix, resourceName, fmt.Sprintf("failed to get load balancer: %v", err)))
+	}
+	nicClient := getNicClient(ctx)
+	future, err := nicClient.CreateOrUpdate(
+		ctx,
+		config.ScopeFrom(ctx).GroupName,
+		netInterfaceName,
+		network.Interface{
+			Location: to.StringPtr(config.ScopeFrom(ctx).Location),
+			InterfacePropertiesFormat: &network.InterfacePropertiesFormat{
+				IPConfigurations: &[]network.InterfaceIPConfiguration{
+					{
+						Name: to.StringPtr("ipConfig1"),
+						InterfaceIPConfigurationPropertiesFormat: &network.InterfaceIPConfigurationPropertiesFormat{
+							Subnet: &network.Subnet{
+								ID: subnet.ID,
+							},
+							PrivateIPAllocationMethod: network.Dynamic,
+							LoadBalancerBackendAddressPools: &[]network.BackendAddressPool{
+								{
+									ID: (*lb.BackendAddressPools)[0].ID,
+								},
+							},
+							LoadBalancerInboundNatRules: &[]network.InboundNatRule{
+								{
+									ID: (*lb.InboundNatRules)[natRule].ID,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	)
+	if err != nil {
+		return nic, fmt.Errorf(fmt.Sprintf(errorPrefix, resourceName, err))
+	}
+	err = future.WaitForCompletionRef(ctx, nicClient.Client)
+	if err != nil {
+		return nic, fmt.Errorf(fmt.Sprintf(errorPrefix, resourceName, fmt.Sprintf("cannot get nic create or update future response: %v", err)))
+	}
+	return future.Result(nicClient)
+}
+
+// GetNic retrieves a network interface by its name
+func GetNic(ctx context.Context, nicName string) (network.Interface, error) {
+	nicClient := getNicClient(ctx)
+	return nicClient.Get(ctx, config.ScopeFrom(ctx).GroupName, nicName, "")
+}
+
+// ListNics gets an iterator over the network interfaces in the resource
+// group
+func ListNics(ctx context.Context) (network.InterfaceListResultIterator, error) {
+	nicClient := getNicClient(ctx)
+	return nicClient.ListComplete(ctx, config.ScopeFrom(ctx).GroupName)
+}
+
+// UpdateNicTags replaces the tags of a network interface
+func UpdateNicTags(ctx context.Context, nicName string, tags map[string]*string) (nic network.Interface, err error) {
+	nicClient := getNicClient(ctx)
+	future, err := nicClient.UpdateTags(ctx, config.ScopeFrom(ctx).GroupName, nicName, network.TagsObject{Tags: tags})
+	if err != nil {
+		return nic, fmt.Errorf("cannot update nic tags: %v", err)
+	}
+
+	err = future.WaitForCompletionRef(ctx, nicClient.Client)
+	if err != nil {
+		return nic, fmt.Errorf("cannot get the nic update tags future response: %v", err)
+	}
+
+	return future.Result(nicClient)
+}
+
+// DeleteNic deletes a network interface
+func DeleteNic(ctx context.Context, nicName string) (ar autorest.Response, err error) {
+	nicClient := getNicClient(ctx)
+	future, err := nicClient.Delete(ctx, config.ScopeFrom(ctx).GroupName, nicName)
+	if err != nil {
+		return ar, fmt.Errorf("cannot delete nic: %v", err)
+	}
+
+	err = future.WaitForCompletionRef(ctx, nicClient.Client)
+	if err != nil {
+		return ar, fmt.Errorf("cannot get the nic delete future response: %v", err)
+	}
+
+	return future.Result(nicClient)
+}
@@ -0,0 +1,124 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package network
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/hybrid"
+	"github.com/Azure/azure-sdk-for-go/profiles/2019-03-01/network/mgmt/network"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
+)
+
+func getNsgClient(ctx context.Context) network.SecurityGroupsClient {
+	nsgClient := network.NewSecurityGroupsClientWithBaseURI(hybrid.Endpoint(ctx))
+	hybrid.Configure(&nsgClient.Client)
+	return nsgClient
+}
+
+// CreateNetworkSecurityGroup creates a new network security group
+func CreateNetworkSecurityGroup(ctx context.Context, nsgName string) (nsg network.SecurityGroup, err error) {
+	resourceName := "security group"
+	nsgClient := getNsgClient(ctx)
+	future, err := nsgClient.CreateOrUpdate(
+		ctx,
+		config.ScopeFrom(ctx).GroupName,
+		nsgName,
+		network.SecurityGroup{
+			Location: to.StringPtr(config.ScopeFrom(ctx).Location),
+			SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+				SecurityRules: &[]network.SecurityRule{
+					{
+						Name: to.StringPtr("allow_ssh"),
+						SecurityRulePropertiesFormat: &network.SecurityRulePropertiesFormat{
+							Protocol:                 network.SecurityRuleProtocolTCP,
+							SourceAddressPrefix:      to.StringPtr("0.0.0.0/0"),
+							SourcePortRange:          to.StringPtr("1-65535"),
+							DestinationAddressPrefix: to.StringPtr("0.0.0.0/0"),
+							DestinationPortRange:     to.StringPtr("22"),
+							Access:                   network.SecurityRuleAccessAllow,
+							Direction:                network.SecurityRuleDirectionInbound,
+							Priority:                 to.Int32Ptr(100),
+						},
+					},
+					{
+						Name: to.StringPtr("allow_https"),
+						SecurityRulePropertiesFormat: &network.SecurityRulePropertiesFormat{
+							Protocol:                 network.SecurityRuleProtocolTCP,
+							SourceAddressPrefix:      to.StringPtr("0.0.0.0/0"),
+							SourcePortRange:          to.StringPtr("1-65535"),
+							DestinationAddressPrefix: to.StringPtr("0.0.0.0/0"),
+							DestinationPortRange:     to.StringPtr("443"),
+							Access:                   network.SecurityRuleAccessAllow,
+							Direction:                network.SecurityRuleDirectionInbound,
+							Priority:                 to.Int32Ptr(200),
+						},
+					},
+				},
+			},
+		},
+	)
+
+	if err != nil {
+		return nsg, fmt.Errorf(fmt.Sprintf(errorPrefix, resourceName, err))
+	}
+
+	err = future.WaitForCompletionRef(ctx, nsgClient.Client)
+	if err != nil {
+		return nsg, fmt.Errorf(fmt.Sprintf(errorPrefix, resourceName, fmt.Sprintf("cannot get nsg create or update future response: %v", err)))
+	}
+
+	return future.Result(nsgClient)
+}
+
+// GetNetworkSecurityGroup retrieves a netwrok resource group by its name
+func GetNetworkSecurityGroup(ctx context.Context, nsgName string) (network.SecurityGroup, error) {
+	nsgClient := getNsgClient(ctx)
+	return nsgClient.Get(ctx, config.ScopeFrom(ctx).GroupName, nsgName, "")
+}
+
+// ListNetworkSecurityGroups gets an iterator over the network security
+// groups in the resource group
+func ListNetworkSecurityGroups(ctx context.Context) (network.SecurityGroupListResultIterator, error) {
+	nsgClient := getNsgClient(ctx)
+	return nsgClient.ListComplete(ctx, config.ScopeFrom(ctx).GroupName)
+}
+
+// UpdateNetworkSecurityGroupTags replaces the tags of a network security
+// group
+func UpdateNetworkSecurityGroupTags(ctx context.Context, nsgName string, tags map[string]*string) (nsg network.SecurityGroup, err error) {
+	nsgClient := getNsgClient(ctx)
+	future, err := nsgClient.UpdateTags(ctx, config.ScopeFrom(ctx).GroupName, nsgName, network.TagsObject{Tags: tags})
+	if err != nil {
+		return nsg, fmt.Errorf("cannot update nsg tags: %v", err)
+	}
+
+	err = future.WaitForCompletionRef(ctx, nsgClient.Client)
+	if err != nil {
+		return nsg, fmt.Errorf("cannot get the nsg update tags future response: %v", err)
+	}
+
+	return future.Result(nsgClient)
+}
+
+// DeleteNetworkSecurityGroup deletes a network security group
+func DeleteNetworkSecurityGroup(ctx context.Context, nsgName string) (ar autorest.Response, err error) {
+	nsgClient := getNsgClient(ctx)
+	future, err := nsgClient.Delete(ctx, config.ScopeFrom(ctx).GroupName, nsgName)
+	if err != nil {
+		return ar, fmt.Errorf("cannot delete nsg: %v", err)
+	}
+
+	err = future.WaitForCompletionRef(ctx, nsgClient.Client)
+	if err != nil {
+		return ar, fmt.Errorf("cannot get the nsg delete future response: %v", err)
+	}
+
+	return future.Result(nsgClient)
+}
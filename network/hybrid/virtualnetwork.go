@@ -0,0 +1,117 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package network
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/hybrid"
+	"github.com/Azure/azure-sdk-for-go/profiles/2019-03-01/network/mgmt/network"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
+)
+
+func getVnetClient(ctx context.Context) network.VirtualNetworksClient {
+	vnetClient := network.NewVirtualNetworksClientWithBaseURI(hybrid.Endpoint(ctx))
+	hybrid.Configure(&vnetClient.Client)
+	return vnetClient
+}
+
+func getSubnetClient(ctx context.Context) network.SubnetsClient {
+	subnetsClient := network.NewSubnetsClientWithBaseURI(hybrid.Endpoint(ctx))
+	hybrid.Configure(&subnetsClient.Client)
+	return subnetsClient
+}
+
+// CreateVirtualNetworkAndSubnets creates a virtual network with one subnet
+func CreateVirtualNetworkAndSubnets(ctx context.Context, vnetName, subnetName string) (vnet network.VirtualNetwork, err error) {
+	resourceName := "virtual network and subnet"
+	vnetClient := getVnetClient(ctx)
+	future, err := vnetClient.CreateOrUpdate(
+		ctx,
+		config.ScopeFrom(ctx).GroupName,
+		vnetName,
+		network.VirtualNetwork{
+			Location: to.StringPtr(config.ScopeFrom(ctx).Location),
+			VirtualNetworkPropertiesFormat: &network.VirtualNetworkPropertiesFormat{
+				AddressSpace: &network.AddressSpace{
+					AddressPrefixes: &[]string{"10.0.0.0/8"},
+				},
+				Subnets: &[]network.Subnet{
+					{
+						Name: to.StringPtr(subnetName),
+						SubnetPropertiesFormat: &network.SubnetPropertiesFormat{
+							AddressPrefix: to.StringPtr("10.0.0.0/16"),
+						},
+					},
+				},
+			},
+		})
+
+	if err != nil {
+		return vnet, fmt.Errorf(fmt.Sprintf(errorPrefix, resourceName, err))
+	}
+
+	err = future.WaitForCompletionRef(ctx, vnetClient.Client)
+	if err != nil {
+		return vnet, fmt.Errorf(fmt.Sprintf(errorPrefix, resourceName, fmt.Sprintf("cannot get the vnet create or update future response: %v", err)))
+	}
+
+	return future.Result(vnetClient)
+}
+
+// GetVirtualNetwork retrieves a virtual network by its name
+func GetVirtualNetwork(ctx context.Context, vnetName string) (network.VirtualNetwork, error) {
+	vnetClient := getVnetClient(ctx)
+	return vnetClient.Get(ctx, config.ScopeFrom(ctx).GroupName, vnetName, "")
+}
+
+// ListVirtualNetworks gets an iterator over the virtual networks in the
+// resource group
+func ListVirtualNetworks(ctx context.Context) (network.VirtualNetworkListResultIterator, error) {
+	vnetClient := getVnetClient(ctx)
+	return vnetClient.ListComplete(ctx, config.ScopeFrom(ctx).GroupName)
+}
+
+// UpdateVirtualNetworkTags replaces the tags of a virtual network
+func UpdateVirtualNetworkTags(ctx context.Context, vnetName string, tags map[string]*string) (vnet network.VirtualNetwork, err error) {
+	vnetClient := getVnetClient(ctx)
+	future, err := vnetClient.UpdateTags(ctx, config.ScopeFrom(ctx).GroupName, vnetName, network.TagsObject{Tags: tags})
+	if err != nil {
+		return vnet, fmt.Errorf("cannot update vnet tags: %v", err)
+	}
+
+	err = future.WaitForCompletionRef(ctx, vnetClient.Client)
+	if err != nil {
+		return vnet, fmt.Errorf("cannot get the vnet update tags future response: %v", err)
+	}
+
+	return future.Result(vnetClient)
+}
+
+// DeleteVirtualNetwork deletes a virtual network
+func DeleteVirtualNetwork(ctx context.Context, vnetName string) (ar autorest.Response, err error) {
+	vnetClient := getVnetClient(ctx)
+	future, err := vnetClient.Delete(ctx, config.ScopeFrom(ctx).GroupName, vnetName)
+	if err != nil {
+		return ar, fmt.Errorf("cannot delete virtual network: %v", err)
+	}
+
+	err = future.WaitForCompletionRef(ctx, vnetClient.Client)
+	if err != nil {
+		return ar, fmt.Errorf("cannot get the virtual network delete future response: %v", err)
+	}
+
+	return future.Result(vnetClient)
+}
+
+// GetVirtualNetworkSubnet retrieves a virtual netwrok subnet by its name
+func GetVirtualNetworkSubnet(ctx context.Context, vnetName string, subnetName string) (network.Subnet, error) {
+	subnetsClient := getSubnetClient(ctx)
+	return subnetsClient.Get(ctx, config.ScopeFrom(ctx).GroupName, vnetName, subnetName, "")
+}
@@ -11,28 +11,30 @@ import (
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2019-11-01/network"
 	"github.com/Azure/go-autorest/autorest/to"
 )
 
-func getIPClient() network.PublicIPAddressesClient {
-	ipClient := network.NewPublicIPAddressesClient(config.SubscriptionID())
+func getIPClient(ctx context.Context) network.PublicIPAddressesClient {
+	ipClient := network.NewPublicIPAddressesClient(config.ScopeFrom(ctx).SubscriptionID)
 	auth, _ := iam.GetResourceManagementAuthorizer()
 	ipClient.Authorizer = auth
 	ipClient.AddToUserAgent(config.UserAgent())
+	ipClient.Sender = recording.Sender()
 	return ipClient
 }
 
 // CreatePublicIP creates a new public IP
 func CreatePublicIP(ctx context.Context, ipName string) (ip network.PublicIPAddress, err error) {
-	ipClient := getIPClient()
+	ipClient := getIPClient(ctx)
 	future, err := ipClient.CreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		ipName,
 		network.PublicIPAddress{
 			Name:     to.StringPtr(ipName),
-			Location: to.StringPtr(config.Location()),
+			Location: to.StringPtr(config.ScopeFrom(ctx).Location),
 			PublicIPAddressPropertiesFormat: &network.PublicIPAddressPropertiesFormat{
 				PublicIPAddressVersion:   network.IPv4,
 				PublicIPAllocationMethod: network.Static,
@@ -54,12 +56,12 @@ func CreatePublicIP(ctx context.Context, ipName string) (ip network.PublicIPAddr
 
 // GetPublicIP returns an existing public IP
 func GetPublicIP(ctx context.Context, ipName string) (network.PublicIPAddress, error) {
-	ipClient := getIPClient()
-	return ipClient.Get(ctx, config.GroupName(), ipName, "")
+	ipClient := getIPClient(ctx)
+	return ipClient.Get(ctx, config.ScopeFrom(ctx).GroupName, ipName, "")
 }
 
 // DeletePublicIP deletes an existing public IP
 func DeletePublicIP(ctx context.Context, ipName string) (result network.PublicIPAddressesDeleteFuture, err error) {
-	ipClient := getIPClient()
-	return ipClient.Delete(ctx, config.GroupName(), ipName)
+	ipClient := getIPClient(ctx)
+	return ipClient.Delete(ctx, config.ScopeFrom(ctx).GroupName, ipName)
 }
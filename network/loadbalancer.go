@@ -11,22 +11,25 @@ import (
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/resourceid"
 	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2019-11-01/network"
 	"github.com/Azure/go-autorest/autorest/to"
 )
 
-func getLBClient() network.LoadBalancersClient {
-	lbClient := network.NewLoadBalancersClient(config.SubscriptionID())
+func getLBClient(ctx context.Context) network.LoadBalancersClient {
+	lbClient := network.NewLoadBalancersClient(config.ScopeFrom(ctx).SubscriptionID)
 	auth, _ := iam.GetResourceManagementAuthorizer()
 	lbClient.Authorizer = auth
 	lbClient.AddToUserAgent(config.UserAgent())
+	lbClient.Sender = recording.Sender()
 	return lbClient
 }
 
 // GetLoadBalancer gets info on a loadbalancer
 func GetLoadBalancer(ctx context.Context, lbName string) (network.LoadBalancer, error) {
-	lbClient := getLBClient()
-	return lbClient.Get(ctx,config.GroupName(), lbName, "")
+	lbClient := getLBClient(ctx)
+	return lbClient.Get(ctx, config.ScopeFrom(ctx).GroupName, lbName, "")
 }
 
 // CreateLoadBalancer creates a load balancer with 2 inbound NAT rules.
@@ -34,19 +37,20 @@ func CreateLoadBalancer(ctx context.Context, lbName, pipName string) (lb network
 	probeName := "probe"
 	frontEndIPConfigName := "fip"
 	backEndAddressPoolName := "backEndPool"
-	idPrefix := fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Network/loadBalancers", config.SubscriptionID(), config.GroupName())
+	scope := config.ScopeFrom(ctx)
+	lbID := resourceid.ResourceGroup(scope.SubscriptionID, scope.GroupName).Resource("Microsoft.Network", "loadBalancers", lbName)
 
 	pip, err := GetPublicIP(ctx, pipName)
 	if err != nil {
 		return
 	}
 
-	lbClient := getLBClient()
+	lbClient := getLBClient(ctx)
 	future, err := lbClient.CreateOrUpdate(ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		lbName,
 		network.LoadBalancer{
-			Location: to.StringPtr(config.Location()),
+			Location: to.StringPtr(config.ScopeFrom(ctx).Location),
 			LoadBalancerPropertiesFormat: &network.LoadBalancerPropertiesFormat{
 				FrontendIPConfigurations: &[]network.FrontendIPConfiguration{
 					{
@@ -85,13 +89,13 @@ func CreateLoadBalancer(ctx context.Context, lbName, pipName string) (lb network
 							EnableFloatingIP:     to.BoolPtr(false),
 							LoadDistribution:     network.LoadDistributionDefault,
 							FrontendIPConfiguration: &network.SubResource{
-								ID: to.StringPtr(fmt.Sprintf("/%s/%s/frontendIPConfigurations/%s", idPrefix, lbName, frontEndIPConfigName)),
+								ID: to.StringPtr(lbID.Child("frontendIPConfigurations", frontEndIPConfigName).String()),
 							},
 							BackendAddressPool: &network.SubResource{
-								ID: to.StringPtr(fmt.Sprintf("/%s/%s/backendAddressPools/%s", idPrefix, lbName, backEndAddressPoolName)),
+								ID: to.StringPtr(lbID.Child("backendAddressPools", backEndAddressPoolName).String()),
 							},
 							Probe: &network.SubResource{
-								ID: to.StringPtr(fmt.Sprintf("/%s/%s/probes/%s", idPrefix, lbName, probeName)),
+								ID: to.StringPtr(lbID.Child("probes", probeName).String()),
 							},
 						},
 					},
@@ -106,7 +110,7 @@ func CreateLoadBalancer(ctx context.Context, lbName, pipName string) (lb network
 							EnableFloatingIP:     to.BoolPtr(false),
 							IdleTimeoutInMinutes: to.Int32Ptr(4),
 							FrontendIPConfiguration: &network.SubResource{
-								ID: to.StringPtr(fmt.Sprintf("/%s/%s/frontendIPConfigurations/%s", idPrefix, lbName, frontEndIPConfigName)),
+								ID: to.StringPtr(lbID.Child("frontendIPConfigurations", frontEndIPConfigName).String()),
 							},
 						},
 					},
@@ -119,7 +123,7 @@ func CreateLoadBalancer(ctx context.Context, lbName, pipName string) (lb network
 							EnableFloatingIP:     to.BoolPtr(false),
 							IdleTimeoutInMinutes: to.Int32Ptr(4),
 							FrontendIPConfiguration: &network.SubResource{
-								ID: to.StringPtr(fmt.Sprintf("/%s/%s/frontendIPConfigurations/%s", idPrefix, lbName, frontEndIPConfigName)),
+								ID: to.StringPtr(lbID.Child("frontendIPConfigurations", frontEndIPConfigName).String()),
 							},
 						},
 					},
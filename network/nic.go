@@ -12,15 +12,17 @@ import (
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2019-11-01/network"
 	"github.com/Azure/go-autorest/autorest/to"
 )
 
-func getNicClient() network.InterfacesClient {
-	nicClient := network.NewInterfacesClient(config.SubscriptionID())
+func getNicClient(ctx context.Context) network.InterfacesClient {
+	nicClient := network.NewInterfacesClient(config.ScopeFrom(ctx).SubscriptionID)
 	auth, _ := iam.GetResourceManagementAuthorizer()
 	nicClient.Authorizer = auth
 	nicClient.AddToUserAgent(config.UserAgent())
+	nicClient.Sender = recording.Sender()
 	return nicClient
 }
 
@@ -38,13 +40,13 @@ func CreateNIC(ctx context.Context, vnetName, subnetName, nsgName, ipName, nicNa
 
 	nicParams := network.Interface{
 		Name:     to.StringPtr(nicName),
-		Location: to.StringPtr(config.Location()),
+		Location: to.StringPtr(config.ScopeFrom(ctx).Location),
 		InterfacePropertiesFormat: &network.InterfacePropertiesFormat{
 			IPConfigurations: &[]network.InterfaceIPConfiguration{
 				{
 					Name: to.StringPtr("ipConfig1"),
 					InterfaceIPConfigurationPropertiesFormat: &network.InterfaceIPConfigurationPropertiesFormat{
-						Subnet: &subnet,
+						Subnet:                    &subnet,
 						PrivateIPAllocationMethod: network.Dynamic,
 						PublicIPAddress:           &ip,
 					},
@@ -61,8 +63,8 @@ func CreateNIC(ctx context.Context, vnetName, subnetName, nsgName, ipName, nicNa
 		nicParams.NetworkSecurityGroup = &nsg
 	}
 
-	nicClient := getNicClient()
-	future, err := nicClient.CreateOrUpdate(ctx, config.GroupName(), nicName, nicParams)
+	nicClient := getNicClient(ctx)
+	future, err := nicClient.CreateOrUpdate(ctx, config.ScopeFrom(ctx).GroupName, nicName, nicParams)
 	if err != nil {
 		return nic, fmt.Errorf("cannot create nic: %v", err)
 	}
@@ -87,12 +89,12 @@ func CreateNICWithLoadBalancer(ctx context.Context, lbName, vnetName, subnetName
 		return
 	}
 
-	nicClient := getNicClient()
+	nicClient := getNicClient(ctx)
 	future, err := nicClient.CreateOrUpdate(ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		nicName,
 		network.Interface{
-			Location: to.StringPtr(config.Location()),
+			Location: to.StringPtr(config.ScopeFrom(ctx).Location),
 			InterfacePropertiesFormat: &network.InterfacePropertiesFormat{
 				IPConfigurations: &[]network.InterfaceIPConfiguration{
 					{
@@ -130,12 +132,12 @@ func CreateNICWithLoadBalancer(ctx context.Context, lbName, vnetName, subnetName
 
 // GetNic returns an existing network interface
 func GetNic(ctx context.Context, nicName string) (network.Interface, error) {
-	nicClient := getNicClient()
-	return nicClient.Get(ctx, config.GroupName(), nicName, "")
+	nicClient := getNicClient(ctx)
+	return nicClient.Get(ctx, config.ScopeFrom(ctx).GroupName, nicName, "")
 }
 
 // DeleteNic deletes an existing network interface
 func DeleteNic(ctx context.Context, nic string) (result network.InterfacesDeleteFuture, err error) {
-	nicClient := getNicClient()
-	return nicClient.Delete(ctx, config.GroupName(), nic)
+	nicClient := getNicClient(ctx)
+	return nicClient.Delete(ctx, config.ScopeFrom(ctx).GroupName, nic)
 }
@@ -11,210 +11,208 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getApplicationGatewaysClient() armnetwork.ApplicationGatewaysClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getApplicationGatewaysClient(ctx context.Context) armnetwork.ApplicationGatewaysClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewApplicationGatewaysClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewApplicationGatewaysClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Creates or updates the specified application gateway
-func CreateApplicationGateway(ctx context.Context, applicationGatewayName string, applicationGatewayParameters armnetwork.ApplicationGateway) error {
-	client := getApplicationGatewaysClient()
+func CreateApplicationGateway(ctx context.Context, applicationGatewayName string, applicationGatewayParameters armnetwork.ApplicationGateway) (*armnetwork.ApplicationGateway, error) {
+	client := getApplicationGatewaysClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		applicationGatewayName,
 		applicationGatewayParameters,
 		nil,
 	)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.ApplicationGateway, nil
 }
 
 // Gets Ssl predefined policy with the specified policy name.
-func GetApplicationGatewaySSLPredefinedPolicy(ctx context.Context, predefinedPolicyName string) error {
-	client := getApplicationGatewaysClient()
-	_, err := client.GetSSLPredefinedPolicy(ctx, predefinedPolicyName, nil)
+func GetApplicationGatewaySSLPredefinedPolicy(ctx context.Context, predefinedPolicyName string) (*armnetwork.ApplicationGatewaySSLPredefinedPolicy, error) {
+	client := getApplicationGatewaysClient(ctx)
+	resp, err := client.GetSSLPredefinedPolicy(ctx, predefinedPolicyName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.ApplicationGatewaySSLPredefinedPolicy, nil
 }
 
 // Lists all SSL predefined policies for configuring Ssl policy.
-func ListApplicationGatewayAvailableSSLPredefinedPolicie(ctx context.Context) error {
-	client := getApplicationGatewaysClient()
+func ListApplicationGatewayAvailableSSLPredefinedPolicie(ctx context.Context) ([]*armnetwork.ApplicationGatewaySSLPredefinedPolicy, error) {
+	client := getApplicationGatewaysClient(ctx)
 	pager := client.ListAvailableSSLPredefinedPolicies(nil)
 
+	var results []*armnetwork.ApplicationGatewaySSLPredefinedPolicy
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().ApplicationGatewayAvailableSSLPredefinedPolicies.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Lists available Ssl options for configuring Ssl policy
-func ListApplicationGatewayAvailableSSLOptions(ctx context.Context) error {
-	client := getApplicationGatewaysClient()
-	_, err := client.ListAvailableSSLOptions(ctx, nil)
+func ListApplicationGatewayAvailableSSLOptions(ctx context.Context) (*armnetwork.ApplicationGatewayAvailableSSLOptions, error) {
+	client := getApplicationGatewaysClient(ctx)
+	resp, err := client.ListAvailableSSLOptions(ctx, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.ApplicationGatewayAvailableSSLOptions, nil
 }
 
 // Gets the specified application gateway.
-func GetApplicationGateway(ctx context.Context, applicationGatewayName string) error {
-	client := getApplicationGatewaysClient()
-	_, err := client.Get(ctx, config.GroupName(), applicationGatewayName, nil)
+func GetApplicationGateway(ctx context.Context, applicationGatewayName string) (*armnetwork.ApplicationGateway, error) {
+	client := getApplicationGatewaysClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, applicationGatewayName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.ApplicationGateway, nil
 }
 
-//  Lists all application gateways in a resource group.
-func ListApplicationGateway(ctx context.Context) error {
-	client := getApplicationGatewaysClient()
-	pager := client.List(config.GroupName(), nil)
+// Lists all application gateways in a resource group.
+func ListApplicationGateway(ctx context.Context) ([]*armnetwork.ApplicationGateway, error) {
+	client := getApplicationGatewaysClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).GroupName, nil)
 
+	var results []*armnetwork.ApplicationGateway
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().ApplicationGatewayListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Lists all available server variables
-func ListApplicationGatewayAvailableServerVariables(ctx context.Context) error {
-	client := getApplicationGatewaysClient()
-	_, err := client.ListAvailableServerVariables(ctx, nil)
+func ListApplicationGatewayAvailableServerVariables(ctx context.Context) ([]*string, error) {
+	client := getApplicationGatewaysClient(ctx)
+	resp, err := client.ListAvailableServerVariables(ctx, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.StringArray, nil
 }
 
 // Lists all available response headers
-func ListApplicationGatewayAvailableResponseHeaders(ctx context.Context) error {
-	client := getApplicationGatewaysClient()
-	_, err := client.ListAvailableResponseHeaders(ctx, nil)
+func ListApplicationGatewayAvailableResponseHeaders(ctx context.Context) ([]*string, error) {
+	client := getApplicationGatewaysClient(ctx)
+	resp, err := client.ListAvailableResponseHeaders(ctx, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.StringArray, nil
 }
 
 // Lists all available request headers
-func ListApplicationGatewayAvailableRequestHeaders(ctx context.Context) error {
-	client := getApplicationGatewaysClient()
-	_, err := client.ListAvailableRequestHeaders(ctx, nil)
+func ListApplicationGatewayAvailableRequestHeaders(ctx context.Context) ([]*string, error) {
+	client := getApplicationGatewaysClient(ctx)
+	resp, err := client.ListAvailableRequestHeaders(ctx, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.StringArray, nil
 }
 
 // Lists all available web application firewall rule sets
-func ListApplicationGatewayAvailableWafRuleSets(ctx context.Context) error {
-	client := getApplicationGatewaysClient()
-	_, err := client.ListAvailableWafRuleSets(ctx, nil)
+func ListApplicationGatewayAvailableWafRuleSets(ctx context.Context) (*armnetwork.ApplicationGatewayAvailableWafRuleSetsResult, error) {
+	client := getApplicationGatewaysClient(ctx)
+	resp, err := client.ListAvailableWafRuleSets(ctx, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.ApplicationGatewayAvailableWafRuleSetsResult, nil
 }
 
 // Gets all the application gateways in a subscription.
-func ListAllApplicationGateway(ctx context.Context) error {
-	client := getApplicationGatewaysClient()
+func ListAllApplicationGateway(ctx context.Context) ([]*armnetwork.ApplicationGateway, error) {
+	client := getApplicationGatewaysClient(ctx)
 	pager := client.ListAll(nil)
+
+	var results []*armnetwork.ApplicationGateway
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().ApplicationGatewayListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Gets the backend health for given combination of backend pool and http setting of the specified application gateway in a
 // resource group
-func GetApplicationGatewayBackendHealthOnDemand(ctx context.Context, applicationGatewayName string, probeRequestParameters armnetwork.ApplicationGatewayOnDemandProbe) error {
-	client := getApplicationGatewaysClient()
+func GetApplicationGatewayBackendHealthOnDemand(ctx context.Context, applicationGatewayName string, probeRequestParameters armnetwork.ApplicationGatewayOnDemandProbe) (*armnetwork.ApplicationGatewayBackendHealthOnDemand, error) {
+	client := getApplicationGatewaysClient(ctx)
 	poller, err := client.BeginBackendHealthOnDemand(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		applicationGatewayName,
 		probeRequestParameters,
 		nil,
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.ApplicationGatewayBackendHealthOnDemand, nil
 }
 
 // Gets the backend health of the specified application gateway in a resource group
-func GetApplicationGatewayBackendHealth(ctx context.Context, applicationGatewayName string) error {
-	client := getApplicationGatewaysClient()
+func GetApplicationGatewayBackendHealth(ctx context.Context, applicationGatewayName string) (*armnetwork.ApplicationGatewayBackendHealth, error) {
+	client := getApplicationGatewaysClient(ctx)
 	poller, err := client.BeginBackendHealth(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		applicationGatewayName,
 		nil,
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.ApplicationGatewayBackendHealth, nil
 }
 
 // Starts the specified application gateway
 func StartApplicationGateway(ctx context.Context, applicationGatewayName string) error {
-	client := getApplicationGatewaysClient()
+	client := getApplicationGatewaysClient(ctx)
 	poller, err := client.BeginStart(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		applicationGatewayName,
 		nil,
 	)
@@ -222,7 +220,7 @@ func StartApplicationGateway(ctx context.Context, applicationGatewayName string)
 		return err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	_, err = poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -231,10 +229,10 @@ func StartApplicationGateway(ctx context.Context, applicationGatewayName string)
 
 // Stops the specified application gateway in a resource group
 func StopApplicationGateway(ctx context.Context, applicationGatewayName string) error {
-	client := getApplicationGatewaysClient()
+	client := getApplicationGatewaysClient(ctx)
 	poller, err := client.BeginStop(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		applicationGatewayName,
 		nil,
 	)
@@ -242,7 +240,7 @@ func StopApplicationGateway(ctx context.Context, applicationGatewayName string)
 		return err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	_, err = poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -250,29 +248,29 @@ func StopApplicationGateway(ctx context.Context, applicationGatewayName string)
 }
 
 // Updates the specified application gateway tags.
-func UpdateApplicationGatewayTags(ctx context.Context, applicationGatewayName string, tagsObjectParameters armnetwork.TagsObject) error {
-	client := getApplicationGatewaysClient()
-	_, err := client.UpdateTags(
+func UpdateApplicationGatewayTags(ctx context.Context, applicationGatewayName string, tagsObjectParameters armnetwork.TagsObject) (*armnetwork.ApplicationGateway, error) {
+	client := getApplicationGatewaysClient(ctx)
+	resp, err := client.UpdateTags(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		applicationGatewayName,
 		tagsObjectParameters,
 		nil,
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.ApplicationGateway, nil
 }
 
 // Deletes the specified application gateway.
 func DeleteApplicationGateway(ctx context.Context, applicationGatewayName string) error {
-	client := getApplicationGatewaysClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), applicationGatewayName, nil)
+	client := getApplicationGatewaysClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, applicationGatewayName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
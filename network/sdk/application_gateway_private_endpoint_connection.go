@@ -11,27 +11,27 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getApplicationGatewayPrivateEndpointConnectionsClient() armnetwork.ApplicationGatewayPrivateEndpointConnectionsClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getApplicationGatewayPrivateEndpointConnectionsClient(ctx context.Context) armnetwork.ApplicationGatewayPrivateEndpointConnectionsClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewApplicationGatewayPrivateEndpointConnectionsClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewApplicationGatewayPrivateEndpointConnectionsClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Updates the specified private endpoint connection on application gateway
 func UpdateApplicationGatewayPrivateEndpointConnection(ctx context.Context, applicationGatewayName string, connectionName string,
-	parameters armnetwork.ApplicationGatewayPrivateEndpointConnection) error {
-	client := getApplicationGatewayPrivateEndpointConnectionsClient()
+	parameters armnetwork.ApplicationGatewayPrivateEndpointConnection) (*armnetwork.ApplicationGatewayPrivateEndpointConnection, error) {
+	client := getApplicationGatewayPrivateEndpointConnectionsClient(ctx)
 	poller, err := client.BeginUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		applicationGatewayName,
 		connectionName,
 		parameters,
@@ -39,51 +39,50 @@ func UpdateApplicationGatewayPrivateEndpointConnection(ctx context.Context, appl
 	)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.ApplicationGatewayPrivateEndpointConnection, nil
 }
 
 // Gets the specified private endpoint connection on application gateway.
-func GetApplicationGatewayPrivateEndpointConnection(ctx context.Context, applicationGatewayName string, connectionName string) error {
-	client := getApplicationGatewayPrivateEndpointConnectionsClient()
-	_, err := client.Get(ctx, config.GroupName(), applicationGatewayName, connectionName, nil)
+func GetApplicationGatewayPrivateEndpointConnection(ctx context.Context, applicationGatewayName string, connectionName string) (*armnetwork.ApplicationGatewayPrivateEndpointConnection, error) {
+	client := getApplicationGatewayPrivateEndpointConnectionsClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, applicationGatewayName, connectionName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.ApplicationGatewayPrivateEndpointConnection, nil
 }
 
-//  Lists all private endpoint connections on an application gateway.
-func ListApplicationGatewayPrivateEndpointConnection(ctx context.Context, applicationGatewayName string) error {
-	client := getApplicationGatewayPrivateEndpointConnectionsClient()
-	pager := client.List(config.GroupName(), applicationGatewayName, nil)
+// Lists all private endpoint connections on an application gateway.
+func ListApplicationGatewayPrivateEndpointConnection(ctx context.Context, applicationGatewayName string) ([]*armnetwork.ApplicationGatewayPrivateEndpointConnection, error) {
+	client := getApplicationGatewayPrivateEndpointConnectionsClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).GroupName, applicationGatewayName, nil)
 
+	var results []*armnetwork.ApplicationGatewayPrivateEndpointConnection
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().ApplicationGatewayPrivateEndpointConnectionListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Deletes the specified private endpoint connection on application gateway.
 func DeleteApplicationGatewayPrivateEndpointConnection(ctx context.Context, applicationGatewayName string, connectionName string) error {
-	client := getApplicationGatewayPrivateEndpointConnectionsClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), applicationGatewayName, connectionName, nil)
+	client := getApplicationGatewayPrivateEndpointConnectionsClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, applicationGatewayName, connectionName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -13,14 +13,17 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/resourceid"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
 )
 
 func TestApplicationGatewayPrivateEndpointConnection(t *testing.T) {
+	defer recording.Start(t)()
+
 	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
 	//connectionName := config.AppendRandomSuffix("agpeconnection")
 	virtualNetworkName := config.AppendRandomSuffix("virtualnetwork")
 	subnetAppgwName := config.AppendRandomSuffix("subnetappgw")
@@ -48,6 +51,7 @@ func TestApplicationGatewayPrivateEndpointConnection(t *testing.T) {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5000*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -243,7 +247,7 @@ func TestApplicationGatewayPrivateEndpointConnection(t *testing.T) {
 	}
 	certB64 := base64.StdEncoding.EncodeToString(certPfx)
 
-	applicationGatewayUrl := "/subscriptions/" + config.SubscriptionID() + "/resourceGroups/" + config.GroupName() + "/providers/Microsoft.Network/applicationGateways/" + applicationGatewayName
+	applicationGatewayID := resourceid.ResourceGroup(config.SubscriptionID(), groupName).Resource("Microsoft.Network", "applicationGateways", applicationGatewayName)
 
 	applicationGatewayParameters := armnetwork.ApplicationGateway{
 		Resource: armnetwork.Resource{
@@ -333,28 +337,28 @@ func TestApplicationGatewayPrivateEndpointConnection(t *testing.T) {
 				Name: &httpListenerName1,
 				Properties: &armnetwork.ApplicationGatewayHTTPListenerPropertiesFormat{
 					FrontendIPConfiguration: &armnetwork.SubResource{
-						ID: to.StringPtr(applicationGatewayUrl + "/frontendIPConfigurations/" + frontendIpConfigurationName),
+						ID: to.StringPtr(applicationGatewayID.Child("frontendIPConfigurations", frontendIpConfigurationName).String()),
 					},
 					FrontendPort: &armnetwork.SubResource{
-						ID: to.StringPtr(applicationGatewayUrl + "/frontendPorts/" + frontendPortName),
+						ID: to.StringPtr(applicationGatewayID.Child("frontendPorts", frontendPortName).String()),
 					},
 					Protocol:                    armnetwork.ApplicationGatewayProtocolHTTPS.ToPtr(),
 					RequireServerNameIndication: to.BoolPtr(false),
 					SSLCertificate: &armnetwork.SubResource{
-						ID: to.StringPtr(applicationGatewayUrl + "/sslCertificates/" + sslCertificateName1),
+						ID: to.StringPtr(applicationGatewayID.Child("sslCertificates", sslCertificateName1).String()),
 					},
 					SSLProfile: &armnetwork.SubResource{
-						ID: to.StringPtr(applicationGatewayUrl + "/sslProfiles/" + sslProfileName),
+						ID: to.StringPtr(applicationGatewayID.Child("sslProfiles", sslProfileName).String()),
 					},
 				},
 			}, {
 				Name: &httpListenerName2,
 				Properties: &armnetwork.ApplicationGatewayHTTPListenerPropertiesFormat{
 					FrontendIPConfiguration: &armnetwork.SubResource{
-						ID: to.StringPtr(applicationGatewayUrl + "/frontendIPConfigurations/" + frontendIpConfigurationName),
+						ID: to.StringPtr(applicationGatewayID.Child("frontendIPConfigurations", frontendIpConfigurationName).String()),
 					},
 					FrontendPort: &armnetwork.SubResource{
-						ID: to.StringPtr(applicationGatewayUrl + "/frontendPorts/" + frontendPortName2),
+						ID: to.StringPtr(applicationGatewayID.Child("frontendPorts", frontendPortName2).String()),
 					},
 					Protocol: armnetwork.ApplicationGatewayProtocolHTTP.ToPtr(),
 				},
@@ -363,26 +367,26 @@ func TestApplicationGatewayPrivateEndpointConnection(t *testing.T) {
 				Name: &urlPathMapName,
 				Properties: &armnetwork.ApplicationGatewayURLPathMapPropertiesFormat{
 					DefaultBackendAddressPool: &armnetwork.SubResource{
-						ID: to.StringPtr(applicationGatewayUrl + "/backendAddressPools/" + backendAddressPoolName),
+						ID: to.StringPtr(applicationGatewayID.Child("backendAddressPools", backendAddressPoolName).String()),
 					},
 					DefaultBackendHTTPSettings: &armnetwork.SubResource{
-						ID: to.StringPtr(applicationGatewayUrl + "/backendHttpSettingsCollection/" + backendHttpSettingsCollectionName),
+						ID: to.StringPtr(applicationGatewayID.Child("backendHttpSettingsCollection", backendHttpSettingsCollectionName).String()),
 					},
 					DefaultRewriteRuleSet: &armnetwork.SubResource{
-						ID: to.StringPtr(applicationGatewayUrl + "/rewriteRuleSets/" + rewriteRuleSetName),
+						ID: to.StringPtr(applicationGatewayID.Child("rewriteRuleSets", rewriteRuleSetName).String()),
 					},
 					PathRules: []*armnetwork.ApplicationGatewayPathRule{{
 						Name: to.StringPtr("apiPaths"),
 						Properties: &armnetwork.ApplicationGatewayPathRulePropertiesFormat{
 							BackendAddressPool: &armnetwork.SubResource{
-								ID: to.StringPtr(applicationGatewayUrl + "/backendAddressPools/" + backendAddressPoolName),
+								ID: to.StringPtr(applicationGatewayID.Child("backendAddressPools", backendAddressPoolName).String()),
 							},
 							BackendHTTPSettings: &armnetwork.SubResource{
-								ID: to.StringPtr(applicationGatewayUrl + "/backendHttpSettingsCollection/" + backendHttpSettingsCollectionName),
+								ID: to.StringPtr(applicationGatewayID.Child("backendHttpSettingsCollection", backendHttpSettingsCollectionName).String()),
 							},
 							Paths: []*string{to.StringPtr("/api"), to.StringPtr("/v1/api")},
 							RewriteRuleSet: &armnetwork.SubResource{
-								ID: to.StringPtr(applicationGatewayUrl + "/rewriteRuleSets/" + rewriteRuleSetName),
+								ID: to.StringPtr(applicationGatewayID.Child("rewriteRuleSets", rewriteRuleSetName).String()),
 							},
 						},
 					}},
@@ -392,17 +396,17 @@ func TestApplicationGatewayPrivateEndpointConnection(t *testing.T) {
 				Name: to.StringPtr("appgwrule"),
 				Properties: &armnetwork.ApplicationGatewayRequestRoutingRulePropertiesFormat{
 					BackendAddressPool: &armnetwork.SubResource{
-						ID: to.StringPtr(applicationGatewayUrl + "/backendAddressPools/" + backendAddressPoolName),
+						ID: to.StringPtr(applicationGatewayID.Child("backendAddressPools", backendAddressPoolName).String()),
 					},
 					BackendHTTPSettings: &armnetwork.SubResource{
-						ID: to.StringPtr(applicationGatewayUrl + "/backendHttpSettingsCollection/" + backendHttpSettingsCollectionName),
+						ID: to.StringPtr(applicationGatewayID.Child("backendHttpSettingsCollection", backendHttpSettingsCollectionName).String()),
 					},
 					HTTPListener: &armnetwork.SubResource{
-						ID: to.StringPtr(applicationGatewayUrl + "/httpListeners/" + httpListenerName1),
+						ID: to.StringPtr(applicationGatewayID.Child("httpListeners", httpListenerName1).String()),
 					},
 					Priority: to.Int32Ptr(10),
 					RewriteRuleSet: &armnetwork.SubResource{
-						ID: to.StringPtr(applicationGatewayUrl + "/rewriteRuleSets/" + rewriteRuleSetName),
+						ID: to.StringPtr(applicationGatewayID.Child("rewriteRuleSets", rewriteRuleSetName).String()),
 					},
 					RuleType: armnetwork.ApplicationGatewayRequestRoutingRuleTypeBasic.ToPtr(),
 				},
@@ -410,12 +414,12 @@ func TestApplicationGatewayPrivateEndpointConnection(t *testing.T) {
 				Name: to.StringPtr("appgwPathBasedRule"),
 				Properties: &armnetwork.ApplicationGatewayRequestRoutingRulePropertiesFormat{
 					HTTPListener: &armnetwork.SubResource{
-						ID: to.StringPtr(applicationGatewayUrl + "/httpListeners/" + httpListenerName2),
+						ID: to.StringPtr(applicationGatewayID.Child("httpListeners", httpListenerName2).String()),
 					},
 					Priority: to.Int32Ptr(20),
 					RuleType: armnetwork.ApplicationGatewayRequestRoutingRuleTypePathBasedRouting.ToPtr(),
 					URLPathMap: &armnetwork.SubResource{
-						ID: to.StringPtr(applicationGatewayUrl + "/urlPathMaps/" + urlPathMapName),
+						ID: to.StringPtr(applicationGatewayID.Child("urlPathMaps", urlPathMapName).String()),
 					},
 				},
 			}},
@@ -449,7 +453,7 @@ func TestApplicationGatewayPrivateEndpointConnection(t *testing.T) {
 			}},
 		},
 	}
-	err = CreateApplicationGateway(ctx, applicationGatewayName, applicationGatewayParameters)
+	_, err = CreateApplicationGateway(ctx, applicationGatewayName, applicationGatewayParameters)
 	if err != nil {
 		t.Fatalf("failed to create application gateway: % +v", err)
 	}
@@ -10,33 +10,31 @@ import (
 	"log"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getApplicationGatewayPrivateLinkResourcesClient() armnetwork.ApplicationGatewayPrivateLinkResourcesClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getApplicationGatewayPrivateLinkResourcesClient(ctx context.Context) armnetwork.ApplicationGatewayPrivateLinkResourcesClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewApplicationGatewayPrivateLinkResourcesClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewApplicationGatewayPrivateLinkResourcesClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Lists all private link resources on an application gateway
-func ListApplicationGatewayPrivateLinkResource(ctx context.Context, applicationGatewayName string) error {
-	client := getApplicationGatewayPrivateLinkResourcesClient()
-	pager := client.List(config.GroupName(), applicationGatewayName, nil)
+func ListApplicationGatewayPrivateLinkResource(ctx context.Context, applicationGatewayName string) ([]*armnetwork.ApplicationGatewayPrivateLinkResource, error) {
+	client := getApplicationGatewayPrivateLinkResourcesClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).GroupName, applicationGatewayName, nil)
 
+	var results []*armnetwork.ApplicationGatewayPrivateLinkResource
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().ApplicationGatewayPrivateLinkResourceListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
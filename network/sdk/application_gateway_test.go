@@ -13,15 +13,17 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/resourceid"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
 )
 
 func TestApplicationGateway(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	applicationGatewayName := config.AppendRandomSuffix("applicationgateway")
 	publicIpAddressName := config.AppendRandomSuffix("pipaddress")
 	virtualNetworkName := config.AppendRandomSuffix("virtualnetwork")
@@ -41,6 +43,7 @@ func TestApplicationGateway(t *testing.T) {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5000*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -92,7 +95,7 @@ func TestApplicationGateway(t *testing.T) {
 	}
 	certB64 := base64.StdEncoding.EncodeToString(certPfx)
 
-	applicationGatewayUrl := "/subscriptions/" + config.SubscriptionID() + "/resourceGroups/" + config.GroupName() + "/providers/Microsoft.Network/applicationGateways/" + applicationGatewayName
+	applicationGatewayID := resourceid.ResourceGroup(config.SubscriptionID(), groupName).Resource("Microsoft.Network", "applicationGateways", applicationGatewayName)
 
 	applicationGatewayParameters := armnetwork.ApplicationGateway{
 		Resource: armnetwork.Resource{
@@ -182,28 +185,28 @@ func TestApplicationGateway(t *testing.T) {
 				Name: &httpListenerName1,
 				Properties: &armnetwork.ApplicationGatewayHTTPListenerPropertiesFormat{
 					FrontendIPConfiguration: &armnetwork.SubResource{
-						ID: to.StringPtr(applicationGatewayUrl + "/frontendIPConfigurations/" + frontendIpConfigurationName),
+						ID: to.StringPtr(applicationGatewayID.Child("frontendIPConfigurations", frontendIpConfigurationName).String()),
 					},
 					FrontendPort: &armnetwork.SubResource{
-						ID: to.StringPtr(applicationGatewayUrl + "/frontendPorts/" + frontendPortName),
+						ID: to.StringPtr(applicationGatewayID.Child("frontendPorts", frontendPortName).String()),
 					},
 					Protocol:                    armnetwork.ApplicationGatewayProtocolHTTPS.ToPtr(),
 					RequireServerNameIndication: to.BoolPtr(false),
 					SSLCertificate: &armnetwork.SubResource{
-						ID: to.StringPtr(applicationGatewayUrl + "/sslCertificates/" + sslCertificateName1),
+						ID: to.StringPtr(applicationGatewayID.Child("sslCertificates", sslCertificateName1).String()),
 					},
 					SSLProfile: &armnetwork.SubResource{
-						ID: to.StringPtr(applicationGatewayUrl + "/sslProfiles/" + sslProfileName),
+						ID: to.StringPtr(applicationGatewayID.Child("sslProfiles", sslProfileName).String()),
 					},
 				},
 			}, {
 				Name: &httpListenerName2,
 				Properties: &armnetwork.ApplicationGatewayHTTPListenerPropertiesFormat{
 					FrontendIPConfiguration: &armnetwork.SubResource{
-						ID: to.StringPtr(applicationGatewayUrl + "/frontendIPConfigurations/" + frontendIpConfigurationName),
+						ID: to.StringPtr(applicationGatewayID.Child("frontendIPConfigurations", frontendIpConfigurationName).String()),
 					},
 					FrontendPort: &armnetwork.SubResource{
-						ID: to.StringPtr(applicationGatewayUrl + "/frontendPorts/" + frontendPortName2),
+						ID: to.StringPtr(applicationGatewayID.Child("frontendPorts", frontendPortName2).String()),
 					},
 					Protocol: armnetwork.ApplicationGatewayProtocolHTTP.ToPtr(),
 				},
@@ -212,26 +215,26 @@ func TestApplicationGateway(t *testing.T) {
 				Name: &urlPathMapName,
 				Properties: &armnetwork.ApplicationGatewayURLPathMapPropertiesFormat{
 					DefaultBackendAddressPool: &armnetwork.SubResource{
-						ID: to.StringPtr(applicationGatewayUrl + "/backendAddressPools/" + backendAddressPoolName),
+						ID: to.StringPtr(applicationGatewayID.Child("backendAddressPools", backendAddressPoolName).String()),
 					},
 					DefaultBackendHTTPSettings: &armnetwork.SubResource{
-						ID: to.StringPtr(applicationGatewayUrl + "/backendHttpSettingsCollection/" + backendHttpSettingsCollectionName),
+						ID: to.StringPtr(applicationGatewayID.Child("backendHttpSettingsCollection", backendHttpSettingsCollectionName).String()),
 					},
 					DefaultRewriteRuleSet: &armnetwork.SubResource{
-						ID: to.StringPtr(applicationGatewayUrl + "/rewriteRuleSets/" + rewriteRuleSetName),
+						ID: to.StringPtr(applicationGatewayID.Child("rewriteRuleSets", rewriteRuleSetName).String()),
 					},
 					PathRules: []*armnetwork.ApplicationGatewayPathRule{{
 						Name: to.StringPtr("apiPaths"),
 						Properties: &armnetwork.ApplicationGatewayPathRulePropertiesFormat{
 							BackendAddressPool: &armnetwork.SubResource{
-								ID: to.StringPtr(applicationGatewayUrl + "/backendAddressPools/" + backendAddressPoolName),
+								ID: to.StringPtr(applicationGatewayID.Child("backendAddressPools", backendAddressPoolName).String()),
 							},
 							BackendHTTPSettings: &armnetwork.SubResource{
-								ID: to.StringPtr(applicationGatewayUrl + "/backendHttpSettingsCollection/" + backendHttpSettingsCollectionName),
+								ID: to.StringPtr(applicationGatewayID.Child("backendHttpSettingsCollection", backendHttpSettingsCollectionName).String()),
 							},
 							Paths: []*string{to.StringPtr("/api"), to.StringPtr("/v1/api")},
 							RewriteRuleSet: &armnetwork.SubResource{
-								ID: to.StringPtr(applicationGatewayUrl + "/rewriteRuleSets/" + rewriteRuleSetName),
+								ID: to.StringPtr(applicationGatewayID.Child("rewriteRuleSets", rewriteRuleSetName).String()),
 							},
 						},
 					}},
@@ -241,17 +244,17 @@ func TestApplicationGateway(t *testing.T) {
 				Name: to.StringPtr("appgwrule"),
 				Properties: &armnetwork.ApplicationGatewayRequestRoutingRulePropertiesFormat{
 					BackendAddressPool: &armnetwork.SubResource{
-						ID: to.StringPtr(applicationGatewayUrl + "/backendAddressPools/" + backendAddressPoolName),
+						ID: to.StringPtr(applicationGatewayID.Child("backendAddressPools", backendAddressPoolName).String()),
 					},
 					BackendHTTPSettings: &armnetwork.SubResource{
-						ID: to.StringPtr(applicationGatewayUrl + "/backendHttpSettingsCollection/" + backendHttpSettingsCollectionName),
+						ID: to.StringPtr(applicationGatewayID.Child("backendHttpSettingsCollection", backendHttpSettingsCollectionName).String()),
 					},
 					HTTPListener: &armnetwork.SubResource{
-						ID: to.StringPtr(applicationGatewayUrl + "/httpListeners/" + httpListenerName1),
+						ID: to.StringPtr(applicationGatewayID.Child("httpListeners", httpListenerName1).String()),
 					},
 					Priority: to.Int32Ptr(10),
 					RewriteRuleSet: &armnetwork.SubResource{
-						ID: to.StringPtr(applicationGatewayUrl + "/rewriteRuleSets/" + rewriteRuleSetName),
+						ID: to.StringPtr(applicationGatewayID.Child("rewriteRuleSets", rewriteRuleSetName).String()),
 					},
 					RuleType: armnetwork.ApplicationGatewayRequestRoutingRuleTypeBasic.ToPtr(),
 				},
@@ -259,12 +262,12 @@ func TestApplicationGateway(t *testing.T) {
 				Name: to.StringPtr("appgwPathBasedRule"),
 				Properties: &armnetwork.ApplicationGatewayRequestRoutingRulePropertiesFormat{
 					HTTPListener: &armnetwork.SubResource{
-						ID: to.StringPtr(applicationGatewayUrl + "/httpListeners/" + httpListenerName2),
+						ID: to.StringPtr(applicationGatewayID.Child("httpListeners", httpListenerName2).String()),
 					},
 					Priority: to.Int32Ptr(20),
 					RuleType: armnetwork.ApplicationGatewayRequestRoutingRuleTypePathBasedRouting.ToPtr(),
 					URLPathMap: &armnetwork.SubResource{
-						ID: to.StringPtr(applicationGatewayUrl + "/urlPathMaps/" + urlPathMapName),
+						ID: to.StringPtr(applicationGatewayID.Child("urlPathMaps", urlPathMapName).String()),
 					},
 				},
 			}},
@@ -298,61 +301,61 @@ func TestApplicationGateway(t *testing.T) {
 			}},
 		},
 	}
-	err = CreateApplicationGateway(ctx, applicationGatewayName, applicationGatewayParameters)
+	_, err = CreateApplicationGateway(ctx, applicationGatewayName, applicationGatewayParameters)
 	if err != nil {
 		t.Fatalf("failed to create application gateway: % +v", err)
 	}
 	t.Logf("created application gateway")
 
-	err = GetApplicationGatewaySSLPredefinedPolicy(ctx, string(armnetwork.ApplicationGatewaySSLPolicyNameAppGwSSLPolicy20170401))
+	_, err = GetApplicationGatewaySSLPredefinedPolicy(ctx, string(armnetwork.ApplicationGatewaySSLPolicyNameAppGwSSLPolicy20170401))
 	if err != nil {
 		t.Fatalf("failed to get application gateway ssl predefined policy: %+v", err)
 	}
 	t.Logf("got application gateway ssl predefined policy")
 
-	err = ListApplicationGatewayAvailableSSLPredefinedPolicie(ctx)
+	_, err = ListApplicationGatewayAvailableSSLPredefinedPolicie(ctx)
 	if err != nil {
 		t.Fatalf("failed to list all SSL predefined policies for configuring Ssl policy: %+v", err)
 	}
 	t.Logf("listed all SSL predefined policies for configuring Ssl policy")
 
-	err = ListApplicationGatewayAvailableSSLOptions(ctx)
+	_, err = ListApplicationGatewayAvailableSSLOptions(ctx)
 	if err != nil {
 		t.Fatalf("failed to list available Ssl options for configuring Ssl policy: %+v", err)
 	}
 	t.Logf("listed available Ssl options for configuring Ssl policy")
 
-	err = GetApplicationGateway(ctx, applicationGatewayName)
+	_, err = GetApplicationGateway(ctx, applicationGatewayName)
 	if err != nil {
 		t.Fatalf("failed to get application gateway: %+v", err)
 	}
 	t.Logf("got application gateway")
 
-	err = ListApplicationGateway(ctx)
+	_, err = ListApplicationGateway(ctx)
 	if err != nil {
 		t.Fatalf("failed to list application gateway: %+v", err)
 	}
 	t.Logf("listed application gateway")
 
-	err = ListApplicationGatewayAvailableServerVariables(ctx)
+	_, err = ListApplicationGatewayAvailableServerVariables(ctx)
 	if err != nil {
 		t.Fatalf("failed to list all available server variables: %+v", err)
 	}
 	t.Logf("listed all available server variables")
 
-	err = ListApplicationGatewayAvailableResponseHeaders(ctx)
+	_, err = ListApplicationGatewayAvailableResponseHeaders(ctx)
 	if err != nil {
 		t.Fatalf("failed to list all available response headers: %+v", err)
 	}
 	t.Logf("listed all available response headers")
 
-	err = ListApplicationGatewayAvailableWafRuleSets(ctx)
+	_, err = ListApplicationGatewayAvailableWafRuleSets(ctx)
 	if err != nil {
 		t.Fatalf("failed to list all available web application firewall rule sets: %+v", err)
 	}
 	t.Logf("listed all available web application firewall rule sets")
 
-	err = ListAllApplicationGateway(ctx)
+	_, err = ListAllApplicationGateway(ctx)
 	if err != nil {
 		t.Fatalf("failed to list all application gateway: %+v", err)
 	}
@@ -360,23 +363,23 @@ func TestApplicationGateway(t *testing.T) {
 
 	probeRequestParameters := armnetwork.ApplicationGatewayOnDemandProbe{
 		BackendAddressPool: &armnetwork.SubResource{
-			ID: to.StringPtr(applicationGatewayUrl + "/backendaddressPools/" + backendAddressPoolName),
+			ID: to.StringPtr(applicationGatewayID.Child("backendaddressPools", backendAddressPoolName).String()),
 		},
 		BackendHTTPSettings: &armnetwork.SubResource{
-			ID: to.StringPtr(applicationGatewayUrl + "/backendHttpSettingsCollection/" + backendHttpSettingsCollectionName),
+			ID: to.StringPtr(applicationGatewayID.Child("backendHttpSettingsCollection", backendHttpSettingsCollectionName).String()),
 		},
 		Path:                                to.StringPtr("/"),
 		PickHostNameFromBackendHTTPSettings: to.BoolPtr(true),
 		Protocol:                            armnetwork.ApplicationGatewayProtocolHTTP.ToPtr(),
 		Timeout:                             to.Int32Ptr(30),
 	}
-	err = GetApplicationGatewayBackendHealthOnDemand(ctx, applicationGatewayName, probeRequestParameters)
+	_, err = GetApplicationGatewayBackendHealthOnDemand(ctx, applicationGatewayName, probeRequestParameters)
 	if err != nil {
 		t.Fatalf("failed to get the backend health for given combination of backend pool and http setting of the specified application gateway: %+v", err)
 	}
 	t.Logf("got the backend health for given combination of backend pool and http setting of the specified application gateway")
 
-	err = GetApplicationGatewayBackendHealth(ctx, applicationGatewayName)
+	_, err = GetApplicationGatewayBackendHealth(ctx, applicationGatewayName)
 	if err != nil {
 		t.Fatalf("failed to get the backend health of the specified application gateway: %+v", err)
 	}
@@ -397,7 +400,7 @@ func TestApplicationGateway(t *testing.T) {
 	tagsObjectParameters := armnetwork.TagsObject{
 		Tags: map[string]*string{"tag1": to.StringPtr("value1"), "tag2": to.StringPtr("value2")},
 	}
-	err = UpdateApplicationGatewayTags(ctx, applicationGatewayName, tagsObjectParameters)
+	_, err = UpdateApplicationGatewayTags(ctx, applicationGatewayName, tagsObjectParameters)
 	if err != nil {
 		t.Fatalf("failed to update tags for application gateway: %+v", err)
 	}
@@ -11,109 +11,108 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getApplicationSecurityGroupsClient() armnetwork.ApplicationSecurityGroupsClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getApplicationSecurityGroupsClient(ctx context.Context) armnetwork.ApplicationSecurityGroupsClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewApplicationSecurityGroupsClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewApplicationSecurityGroupsClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Creates or updates an application security group.
-func CreateApplicationSecurityGroup(ctx context.Context, applicationSecurityGroupName string, applicationSecurityGroupParameters armnetwork.ApplicationSecurityGroup) error {
-	client := getApplicationSecurityGroupsClient()
+func CreateApplicationSecurityGroup(ctx context.Context, applicationSecurityGroupName string, applicationSecurityGroupParameters armnetwork.ApplicationSecurityGroup) (*armnetwork.ApplicationSecurityGroup, error) {
+	client := getApplicationSecurityGroupsClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		applicationSecurityGroupName,
 		applicationSecurityGroupParameters,
 		nil,
 	)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.ApplicationSecurityGroup, nil
 }
 
 // Gets information about the specified application security group.
-func GetApplicationSecurityGroup(ctx context.Context, applicationSecurityGroupName string) error {
-	client := getApplicationSecurityGroupsClient()
-	_, err := client.Get(ctx, config.GroupName(), applicationSecurityGroupName, nil)
+func GetApplicationSecurityGroup(ctx context.Context, applicationSecurityGroupName string) (*armnetwork.ApplicationSecurityGroup, error) {
+	client := getApplicationSecurityGroupsClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, applicationSecurityGroupName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.ApplicationSecurityGroup, nil
 }
 
 // Gets all the application security groups in a resource group.
-func ListApplicationSecurityGroup(ctx context.Context) error {
-	client := getApplicationSecurityGroupsClient()
-	pager := client.List(config.GroupName(), nil)
+func ListApplicationSecurityGroup(ctx context.Context) ([]*armnetwork.ApplicationSecurityGroup, error) {
+	client := getApplicationSecurityGroupsClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).GroupName, nil)
 
+	var results []*armnetwork.ApplicationSecurityGroup
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().ApplicationSecurityGroupListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Gets all application security groups in a subscription.
-func ListAllApplicationSecurityGroup(ctx context.Context) error {
-	client := getApplicationSecurityGroupsClient()
+func ListAllApplicationSecurityGroup(ctx context.Context) ([]*armnetwork.ApplicationSecurityGroup, error) {
+	client := getApplicationSecurityGroupsClient(ctx)
 	pager := client.ListAll(nil)
+
+	var results []*armnetwork.ApplicationSecurityGroup
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().ApplicationSecurityGroupListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Updates an application security group's tags.
-func UpdateApplicationSecurityGroupTags(ctx context.Context, applicationSecurityGroupName string, tagsObjectParameters armnetwork.TagsObject) error {
-	client := getApplicationSecurityGroupsClient()
-	_, err := client.UpdateTags(
+func UpdateApplicationSecurityGroupTags(ctx context.Context, applicationSecurityGroupName string, tagsObjectParameters armnetwork.TagsObject) (*armnetwork.ApplicationSecurityGroup, error) {
+	client := getApplicationSecurityGroupsClient(ctx)
+	resp, err := client.UpdateTags(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		applicationSecurityGroupName,
 		tagsObjectParameters,
 		nil,
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.ApplicationSecurityGroup, nil
 }
 
 // Deletes the specified application security group.
 func DeleteApplicationSecurityGroup(ctx context.Context, applicationSecurityGroupName string) error {
-	client := getApplicationSecurityGroupsClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), applicationSecurityGroupName, nil)
+	client := getApplicationSecurityGroupsClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, applicationSecurityGroupName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -11,19 +11,21 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
 )
 
 func TestApplicationSecurityGroup(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	applicationSecurityGroupName := config.AppendRandomSuffix("applicationsecuritygroup")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -36,25 +38,25 @@ func TestApplicationSecurityGroup(t *testing.T) {
 			Location: to.StringPtr(config.Location()),
 		},
 	}
-	err = CreateApplicationSecurityGroup(ctx, applicationSecurityGroupName, applicationSecurityGroupParameters)
+	_, err = CreateApplicationSecurityGroup(ctx, applicationSecurityGroupName, applicationSecurityGroupParameters)
 	if err != nil {
 		t.Fatalf("failed to create application security group: % +v", err)
 	}
 	t.Logf("created application security group")
 
-	err = GetApplicationSecurityGroup(ctx, applicationSecurityGroupName)
+	_, err = GetApplicationSecurityGroup(ctx, applicationSecurityGroupName)
 	if err != nil {
 		t.Fatalf("failed to get application security group: %+v", err)
 	}
 	t.Logf("got application security group")
 
-	err = ListApplicationSecurityGroup(ctx)
+	_, err = ListApplicationSecurityGroup(ctx)
 	if err != nil {
 		t.Fatalf("failed to list application security group: %+v", err)
 	}
 	t.Logf("listed application security group")
 
-	err = ListAllApplicationSecurityGroup(ctx)
+	_, err = ListAllApplicationSecurityGroup(ctx)
 	if err != nil {
 		t.Fatalf("failed to list all application security group: %+v", err)
 	}
@@ -63,7 +65,7 @@ func TestApplicationSecurityGroup(t *testing.T) {
 	tagsObjectParameters := armnetwork.TagsObject{
 		Tags: map[string]*string{"tag1": to.StringPtr("value1"), "tag2": to.StringPtr("value2")},
 	}
-	err = UpdateApplicationSecurityGroupTags(ctx, applicationSecurityGroupName, tagsObjectParameters)
+	_, err = UpdateApplicationSecurityGroupTags(ctx, applicationSecurityGroupName, tagsObjectParameters)
 	if err != nil {
 		t.Fatalf("failed to update tags for application security group: %+v", err)
 	}
@@ -10,33 +10,31 @@ import (
 	"log"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getAvailableDelegationsClient() armnetwork.AvailableDelegationsClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getAvailableDelegationsClient(ctx context.Context) armnetwork.AvailableDelegationsClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewAvailableDelegationsClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewAvailableDelegationsClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Gets all of the available subnet delegations for this subscription in this region.
-func ListAvailableDelegation(ctx context.Context) error {
-	client := getAvailableDelegationsClient()
-	pager := client.List(config.Location(), nil)
+func ListAvailableDelegation(ctx context.Context) ([]*armnetwork.AvailableDelegation, error) {
+	client := getAvailableDelegationsClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).Location, nil)
 
+	var results []*armnetwork.AvailableDelegation
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().AvailableDelegationsResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
@@ -11,15 +11,17 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 )
 
 func TestAvailableDelegation(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -27,7 +29,7 @@ func TestAvailableDelegation(t *testing.T) {
 		t.Fatalf("failed to create group: %+v", err)
 	}
 
-	err = ListAvailableDelegation(ctx)
+	_, err = ListAvailableDelegation(ctx)
 	if err != nil {
 		t.Fatalf("failed to list the available subnet delegationsn: %+v", err)
 	}
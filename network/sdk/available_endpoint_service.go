@@ -10,33 +10,31 @@ import (
 	"log"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getAvailableEndpointServicesClient() armnetwork.AvailableEndpointServicesClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getAvailableEndpointServicesClient(ctx context.Context) armnetwork.AvailableEndpointServicesClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewAvailableEndpointServicesClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewAvailableEndpointServicesClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Gets all the available endpoint service in a subscription.
-func ListAvailableEndpointService(ctx context.Context) error {
-	client := getAvailableEndpointServicesClient()
-	pager := client.List(config.Location(), nil)
+func ListAvailableEndpointService(ctx context.Context) ([]*armnetwork.EndpointServiceResult, error) {
+	client := getAvailableEndpointServicesClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).Location, nil)
 
+	var results []*armnetwork.EndpointServiceResult
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().EndpointServicesListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
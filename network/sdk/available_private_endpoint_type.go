@@ -10,49 +10,47 @@ import (
 	"log"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getAvailablePrivateEndpointTypesClient() armnetwork.AvailablePrivateEndpointTypesClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getAvailablePrivateEndpointTypesClient(ctx context.Context) armnetwork.AvailablePrivateEndpointTypesClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewAvailablePrivateEndpointTypesClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewAvailablePrivateEndpointTypesClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Gets all the available private endpoint type in a subscription.
-func ListAvailablePrivateEndpointType(ctx context.Context) error {
-	client := getAvailablePrivateEndpointTypesClient()
-	pager := client.List(config.Location(), nil)
+func ListAvailablePrivateEndpointType(ctx context.Context) ([]*armnetwork.AvailablePrivateEndpointType, error) {
+	client := getAvailablePrivateEndpointTypesClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).Location, nil)
 
+	var results []*armnetwork.AvailablePrivateEndpointType
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().AvailablePrivateEndpointTypesResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Gets all available private endpoint type in a resource group.
-func ListAvailablePrivateEndpointTypeByResourceGroup(ctx context.Context) error {
-	client := getAvailablePrivateEndpointTypesClient()
-	pager := client.ListByResourceGroup(config.Location(), config.GroupName(), nil)
+func ListAvailablePrivateEndpointTypeByResourceGroup(ctx context.Context) ([]*armnetwork.AvailablePrivateEndpointType, error) {
+	client := getAvailablePrivateEndpointTypesClient(ctx)
+	pager := client.ListByResourceGroup(config.ScopeFrom(ctx).Location, config.ScopeFrom(ctx).GroupName, nil)
+
+	var results []*armnetwork.AvailablePrivateEndpointType
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().AvailablePrivateEndpointTypesResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
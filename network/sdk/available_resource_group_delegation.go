@@ -10,33 +10,31 @@ import (
 	"log"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getAvailableResourceGroupDelegationsClient() armnetwork.AvailableResourceGroupDelegationsClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getAvailableResourceGroupDelegationsClient(ctx context.Context) armnetwork.AvailableResourceGroupDelegationsClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewAvailableResourceGroupDelegationsClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewAvailableResourceGroupDelegationsClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Gets all the available resource group delegation in a subscription.
-func ListAvailableResourceGroupDelegation(ctx context.Context) error {
-	client := getAvailableResourceGroupDelegationsClient()
-	pager := client.List(config.Location(), config.GroupName(), nil)
+func ListAvailableResourceGroupDelegation(ctx context.Context) ([]*armnetwork.AvailableDelegation, error) {
+	client := getAvailableResourceGroupDelegationsClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).Location, config.ScopeFrom(ctx).GroupName, nil)
 
+	var results []*armnetwork.AvailableDelegation
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().AvailableDelegationsResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
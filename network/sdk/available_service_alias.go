@@ -10,49 +10,47 @@ import (
 	"log"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getAvailableServiceAliasesClient() armnetwork.AvailableServiceAliasesClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getAvailableServiceAliasesClient(ctx context.Context) armnetwork.AvailableServiceAliasesClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewAvailableServiceAliasesClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewAvailableServiceAliasesClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Gets all available service aliases for this subscription in this region.
-func ListAvailableServiceAlias(ctx context.Context) error {
-	client := getAvailableServiceAliasesClient()
-	pager := client.List(config.Location(), nil)
+func ListAvailableServiceAlias(ctx context.Context) ([]*armnetwork.AvailableServiceAlias, error) {
+	client := getAvailableServiceAliasesClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).Location, nil)
 
+	var results []*armnetwork.AvailableServiceAlias
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().AvailableServiceAliasesResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Gets all available service aliases for this resource group in this region.
-func ListAvailableServiceAliasByResourceGroup(ctx context.Context) error {
-	client := getAvailableServiceAliasesClient()
-	pager := client.ListByResourceGroup(config.GroupName(), config.Location(), nil)
+func ListAvailableServiceAliasByResourceGroup(ctx context.Context) ([]*armnetwork.AvailableServiceAlias, error) {
+	client := getAvailableServiceAliasesClient(ctx)
+	pager := client.ListByResourceGroup(config.ScopeFrom(ctx).GroupName, config.ScopeFrom(ctx).Location, nil)
+
+	var results []*armnetwork.AvailableServiceAlias
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().AvailableServiceAliasesResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
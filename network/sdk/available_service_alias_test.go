@@ -11,24 +11,26 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 )
 
 func TestAvailableServiceAlias(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
-	err := ListAvailableServiceAlias(ctx)
+	_, err := ListAvailableServiceAlias(ctx)
 	if err != nil {
 		t.Fatalf("failed to list available service alias: %+v", err)
 	}
 	t.Logf("listed available service alias")
 
-	err = ListAvailableServiceAliasByResourceGroup(ctx)
+	_, err = ListAvailableServiceAliasByResourceGroup(ctx)
 	if err != nil {
 		t.Fatalf("failed to listavailable service alias by resource group: %+v", err)
 	}
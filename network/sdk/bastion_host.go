@@ -11,77 +11,76 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getBastionHostsClient() armnetwork.BastionHostsClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getBastionHostsClient(ctx context.Context) armnetwork.BastionHostsClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewBastionHostsClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewBastionHostsClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Create BastionHosts
-func CreateBastionHost(ctx context.Context, bastionHostName string, bastionHostParameters armnetwork.BastionHost) error {
-	client := getBastionHostsClient()
+func CreateBastionHost(ctx context.Context, bastionHostName string, bastionHostParameters armnetwork.BastionHost) (*armnetwork.BastionHost, error) {
+	client := getBastionHostsClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		bastionHostName,
 		bastionHostParameters,
 		nil,
 	)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.BastionHost, nil
 }
 
 // Gets the specified Bastion Host.
-func GetBastionHost(ctx context.Context, bastionHostName string) error {
-	client := getBastionHostsClient()
-	_, err := client.Get(ctx, config.GroupName(), bastionHostName, nil)
+func GetBastionHost(ctx context.Context, bastionHostName string) (*armnetwork.BastionHost, error) {
+	client := getBastionHostsClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, bastionHostName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.BastionHost, nil
 }
 
 // Lists all Bastion Hosts in a subscription.
-func ListBastionHost(ctx context.Context) error {
-	client := getBastionHostsClient()
+func ListBastionHost(ctx context.Context) ([]*armnetwork.BastionHost, error) {
+	client := getBastionHostsClient(ctx)
 	pager := client.List(nil)
 
+	var results []*armnetwork.BastionHost
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().BastionHostListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Deletes the specified Bastion Host.
 func DeleteBastionHost(ctx context.Context, bastionHostName string) error {
-	client := getBastionHostsClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), bastionHostName, nil)
+	client := getBastionHostsClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, bastionHostName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -89,17 +88,17 @@ func DeleteBastionHost(ctx context.Context, bastionHostName string) error {
 }
 
 // Gets all bastion host in a resource group.
-func ListBastionHostByResourceGroup(ctx context.Context) error {
-	client := getBastionHostsClient()
-	pager := client.ListByResourceGroup(config.GroupName(), nil)
+func ListBastionHostByResourceGroup(ctx context.Context) ([]*armnetwork.BastionHost, error) {
+	client := getBastionHostsClient(ctx)
+	pager := client.ListByResourceGroup(config.ScopeFrom(ctx).GroupName, nil)
+
+	var results []*armnetwork.BastionHost
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().BastionHostListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
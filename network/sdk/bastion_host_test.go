@@ -12,6 +12,7 @@ import (
 
 	compute "github.com/Azure-Samples/azure-sdk-for-go-samples/compute/sdk"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/compute/armcompute"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
@@ -19,9 +20,9 @@ import (
 )
 
 func TestBastionHost(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	bastionHostName := config.AppendRandomSuffix("bastionhost")
 	virtualNetworkName := config.AppendRandomSuffix("virtualnetwork")
 	subnetName := config.AppendRandomSuffix("subnet")
@@ -35,6 +36,7 @@ func TestBastionHost(t *testing.T) {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 1000*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -213,25 +215,25 @@ func TestBastionHost(t *testing.T) {
 			}},
 		},
 	}
-	err = CreateBastionHost(ctx, bastionHostName, bastionHostParameters)
+	_, err = CreateBastionHost(ctx, bastionHostName, bastionHostParameters)
 	if err != nil {
 		t.Fatalf("failed to create bastion host: % +v", err)
 	}
 	t.Logf("created bastion host")
 
-	err = GetBastionHost(ctx, bastionHostName)
+	_, err = GetBastionHost(ctx, bastionHostName)
 	if err != nil {
 		t.Fatalf("failed to get bastion host: %+v", err)
 	}
 	t.Logf("got bastion host")
 
-	err = ListBastionHost(ctx)
+	_, err = ListBastionHost(ctx)
 	if err != nil {
 		t.Fatalf("failed to list bastion host: %+v", err)
 	}
 	t.Logf("listed bastion host")
 
-	err = ListBastionHostByResourceGroup(ctx)
+	_, err = ListBastionHostByResourceGroup(ctx)
 	if err != nil {
 		t.Fatalf("failed to list bastion host by resource group: %+v", err)
 	}
@@ -10,32 +10,31 @@ import (
 	"log"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getBGPServiceCommunitiesClient() armnetwork.BgpServiceCommunitiesClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getBGPServiceCommunitiesClient(ctx context.Context) armnetwork.BgpServiceCommunitiesClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewBgpServiceCommunitiesClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewBgpServiceCommunitiesClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
-//Gets all the available bgp service community.
-func ListBGPServiceCommunities(ctx context.Context) error {
-	client := getBGPServiceCommunitiesClient()
+// Gets all the available bgp service community.
+func ListBGPServiceCommunities(ctx context.Context) ([]*armnetwork.BgpServiceCommunity, error) {
+	client := getBGPServiceCommunitiesClient(ctx)
 	pager := client.List(nil)
+
+	var results []*armnetwork.BgpServiceCommunity
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().BgpServiceCommunityListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
@@ -10,26 +10,25 @@ import (
 	"log"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getCheckDnsNameAvailabilitysClient() armnetwork.NetworkManagementClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getCheckDnsNameAvailabilitysClient(ctx context.Context) armnetwork.NetworkManagementClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewNetworkManagementClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewNetworkManagementClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Gets the specified check dns name availability in a specified resource group.
-func GetCheckDnsNameAvailability(ctx context.Context, checkDnsNameAvailabilityName string) error {
-	client := getCheckDnsNameAvailabilitysClient()
-	_, err := client.CheckDNSNameAvailability(ctx, config.Location(), checkDnsNameAvailabilityName, nil)
+func GetCheckDnsNameAvailability(ctx context.Context, checkDnsNameAvailabilityName string) (*armnetwork.DNSNameAvailabilityResult, error) {
+	client := getCheckDnsNameAvailabilitysClient(ctx)
+	resp, err := client.CheckDNSNameAvailability(ctx, config.ScopeFrom(ctx).Location, checkDnsNameAvailabilityName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.DNSNameAvailabilityResult, nil
 }
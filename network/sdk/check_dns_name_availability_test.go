@@ -11,18 +11,20 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 )
 
 func TestCheckDnsNameAvailability(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	domainNameLabel := "testdns"
 
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 
-	err := GetCheckDnsNameAvailability(ctx, domainNameLabel)
+	_, err := GetCheckDnsNameAvailability(ctx, domainNameLabel)
 	if err != nil {
 		t.Fatalf("failed to check dns name availability: %+v", err)
 	}
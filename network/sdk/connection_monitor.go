@@ -11,26 +11,26 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getConnectionMonitorsClient() armnetwork.ConnectionMonitorsClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getConnectionMonitorsClient(ctx context.Context) armnetwork.ConnectionMonitorsClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewConnectionMonitorsClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewConnectionMonitorsClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Create ConnectionMonitors
-func CreateConnectionMonitor(ctx context.Context, networkWatcherName string, connectionMonitorName string, connectionMonitorParameters armnetwork.ConnectionMonitor) error {
-	client := getConnectionMonitorsClient()
+func CreateConnectionMonitor(ctx context.Context, networkWatcherName string, connectionMonitorName string, connectionMonitorParameters armnetwork.ConnectionMonitor) (*armnetwork.ConnectionMonitorResult, error) {
+	client := getConnectionMonitorsClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		networkWatcherName,
 		connectionMonitorName,
 		connectionMonitorParameters,
@@ -38,62 +38,62 @@ func CreateConnectionMonitor(ctx context.Context, networkWatcherName string, con
 	)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.ConnectionMonitorResult, nil
 }
 
-//  Gets a connection monitor by name.
-func GetConnectionMonitor(ctx context.Context, networkWatcherName string, connectionMonitorName string) error {
-	client := getConnectionMonitorsClient()
-	_, err := client.Get(ctx, config.GroupName(), networkWatcherName, connectionMonitorName, nil)
+// Gets a connection monitor by name.
+func GetConnectionMonitor(ctx context.Context, networkWatcherName string, connectionMonitorName string) (*armnetwork.ConnectionMonitorResult, error) {
+	client := getConnectionMonitorsClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, networkWatcherName, connectionMonitorName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.ConnectionMonitorResult, nil
 }
 
 // Lists all connection monitors for the specified Network Watcher.
-func ListConnectionMonitor(ctx context.Context, networkWatcherName string) error {
-	client := getConnectionMonitorsClient()
-	_, err := client.List(ctx, config.GroupName(), networkWatcherName, nil)
+func ListConnectionMonitor(ctx context.Context, networkWatcherName string) (*armnetwork.ConnectionMonitorListResult, error) {
+	client := getConnectionMonitorsClient(ctx)
+	resp, err := client.List(ctx, config.ScopeFrom(ctx).GroupName, networkWatcherName, nil)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.ConnectionMonitorListResult, nil
 }
 
 // Update tags of the specified connection monitor.
-func UpdateConnectionMonitorTags(ctx context.Context, networkWatcherName string, connectionMonitorName string, tagsObjectParameters armnetwork.TagsObject) error {
-	client := getConnectionMonitorsClient()
-	_, err := client.UpdateTags(
+func UpdateConnectionMonitorTags(ctx context.Context, networkWatcherName string, connectionMonitorName string, tagsObjectParameters armnetwork.TagsObject) (*armnetwork.ConnectionMonitorResult, error) {
+	client := getConnectionMonitorsClient(ctx)
+	resp, err := client.UpdateTags(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		networkWatcherName,
 		connectionMonitorName,
 		tagsObjectParameters,
 		nil,
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.ConnectionMonitorResult, nil
 }
 
 // Deletes the specified connection monitor.
 func DeleteConnectionMonitor(ctx context.Context, networkWatcherName string, connectionMonitorName string) error {
-	client := getConnectionMonitorsClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), networkWatcherName, connectionMonitorName, nil)
+	client := getConnectionMonitorsClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, networkWatcherName, connectionMonitorName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
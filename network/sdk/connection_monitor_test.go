@@ -12,6 +12,7 @@ import (
 
 	compute "github.com/Azure-Samples/azure-sdk-for-go-samples/compute/sdk"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/compute/armcompute"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
@@ -19,9 +20,9 @@ import (
 )
 
 func TestConnectionMonitor(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	connectionMonitorName := config.AppendRandomSuffix("connectionmonitor")
 	networkWatcherName := config.AppendRandomSuffix("networkwatcher")
 	virtualMachineName := config.AppendRandomSuffix("virtualmachine")
@@ -32,6 +33,7 @@ func TestConnectionMonitor(t *testing.T) {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -39,7 +41,7 @@ func TestConnectionMonitor(t *testing.T) {
 		t.Fatalf("failed to create group: %+v", err)
 	}
 
-	err = CreateNetworkWatcher(ctx, networkWatcherName)
+	_, err = CreateNetworkWatcher(ctx, networkWatcherName)
 	if err != nil {
 		t.Fatalf("failed to create network watcher: % +v", err)
 	}
@@ -187,19 +189,19 @@ func TestConnectionMonitor(t *testing.T) {
 			}},
 		},
 	}
-	err = CreateConnectionMonitor(ctx, networkWatcherName, connectionMonitorName, connectionMonitorParameters)
+	_, err = CreateConnectionMonitor(ctx, networkWatcherName, connectionMonitorName, connectionMonitorParameters)
 	if err != nil {
 		t.Fatalf("failed to create connection monitor: % +v", err)
 	}
 	t.Logf("created connection monitor")
 
-	err = GetConnectionMonitor(ctx, networkWatcherName, connectionMonitorName)
+	_, err = GetConnectionMonitor(ctx, networkWatcherName, connectionMonitorName)
 	if err != nil {
 		t.Fatalf("failed to get connection monitor: %+v", err)
 	}
 	t.Logf("got connection monitor")
 
-	err = ListConnectionMonitor(ctx, networkWatcherName)
+	_, err = ListConnectionMonitor(ctx, networkWatcherName)
 	if err != nil {
 		t.Fatalf("failed to list connection monitor: %+v", err)
 	}
@@ -208,7 +210,7 @@ func TestConnectionMonitor(t *testing.T) {
 	tagsObjectParameters := armnetwork.TagsObject{
 		Tags: map[string]*string{"tag1": to.StringPtr("value1"), "tag2": to.StringPtr("value2")},
 	}
-	err = UpdateConnectionMonitorTags(ctx, networkWatcherName, connectionMonitorName, tagsObjectParameters)
+	_, err = UpdateConnectionMonitorTags(ctx, networkWatcherName, connectionMonitorName, tagsObjectParameters)
 	if err != nil {
 		t.Fatalf("failed to update tags for connection monitor: %+v", err)
 	}
@@ -11,109 +11,108 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getCustomIpPrefixesClient() armnetwork.CustomIPPrefixesClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getCustomIpPrefixesClient(ctx context.Context) armnetwork.CustomIPPrefixesClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewCustomIPPrefixesClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewCustomIPPrefixesClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Creates or updates a custom IP prefix.
-func CreateCustomIpPrefix(ctx context.Context, customIpPrefixName string, customIPPrefixParameters armnetwork.CustomIPPrefix) error {
-	client := getCustomIpPrefixesClient()
+func CreateCustomIpPrefix(ctx context.Context, customIpPrefixName string, customIPPrefixParameters armnetwork.CustomIPPrefix) (*armnetwork.CustomIPPrefix, error) {
+	client := getCustomIpPrefixesClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		customIpPrefixName,
 		customIPPrefixParameters,
 		nil,
 	)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.CustomIPPrefix, nil
 }
 
 // Gets the specified custom IP prefix in a specified resource group.
-func GetCustomIpPrefix(ctx context.Context, customIpPrefixName string) error {
-	client := getCustomIpPrefixesClient()
-	_, err := client.Get(ctx, config.GroupName(), customIpPrefixName, nil)
+func GetCustomIpPrefix(ctx context.Context, customIpPrefixName string) (*armnetwork.CustomIPPrefix, error) {
+	client := getCustomIpPrefixesClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, customIpPrefixName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.CustomIPPrefix, nil
 }
 
 // Gets all custom IP prefixes in a resource group.
-func ListCustomIpPrefix(ctx context.Context) error {
-	client := getCustomIpPrefixesClient()
-	pager := client.List(config.GroupName(), nil)
+func ListCustomIpPrefix(ctx context.Context) ([]*armnetwork.CustomIPPrefix, error) {
+	client := getCustomIpPrefixesClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).GroupName, nil)
 
+	var results []*armnetwork.CustomIPPrefix
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().CustomIPPrefixListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Gets all the custom IP prefixes in a subscription.
-func ListAllCustomIpPrefix(ctx context.Context) error {
-	client := getCustomIpPrefixesClient()
+func ListAllCustomIpPrefix(ctx context.Context) ([]*armnetwork.CustomIPPrefix, error) {
+	client := getCustomIpPrefixesClient(ctx)
 	pager := client.ListAll(nil)
+
+	var results []*armnetwork.CustomIPPrefix
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().CustomIPPrefixListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Updates custom ip prefix tags.
-func UpdateCustomIpPrefixTags(ctx context.Context, customIpPrefixName string, tagsObjectParameters armnetwork.TagsObject) error {
-	client := getCustomIpPrefixesClient()
-	_, err := client.UpdateTags(
+func UpdateCustomIpPrefixTags(ctx context.Context, customIpPrefixName string, tagsObjectParameters armnetwork.TagsObject) (*armnetwork.CustomIPPrefix, error) {
+	client := getCustomIpPrefixesClient(ctx)
+	resp, err := client.UpdateTags(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		customIpPrefixName,
 		tagsObjectParameters,
 		nil,
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.CustomIPPrefix, nil
 }
 
 // Deletes the specified custom ip prefix.
 func DeleteCustomIpPrefix(ctx context.Context, customIpPrefixName string) error {
-	client := getCustomIpPrefixesClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), customIpPrefixName, nil)
+	client := getCustomIpPrefixesClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, customIpPrefixName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -7,19 +7,22 @@ package network
 
 import (
 	"testing"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 )
 
 func TestCustomIpPrefix(t *testing.T) {
+	defer recording.Start(t)()
+
 	/*Seems it’s the permission issue. After tried to register this feature “az feature register --namespace Microsoft.Network --name AllowBringYourOwnIpAddressForThirdParties”,
 	seems it requires additional action to onboard the service from service team. So disable it with the reason for now.
 
 	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
-
 	customIpPrefixName := config.AppendRandomSuffix("customipprefix")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -35,25 +38,25 @@ func TestCustomIpPrefix(t *testing.T) {
 			Cidr: to.StringPtr("0.0.0.0/24"),
 		},
 	}
-	err = CreateCustomIpPrefix(ctx, customIpPrefixName, customIPPrefixParameters)
+	_, err = CreateCustomIpPrefix(ctx, customIpPrefixName, customIPPrefixParameters)
 	if err != nil {
 		t.Fatalf("failed to create custom ip prefix: % +v", err)
 	}
 	t.Logf("created custom ip prefix")
 
-	err = GetCustomIpPrefix(ctx, customIpPrefixName)
+	_, err = GetCustomIpPrefix(ctx, customIpPrefixName)
 	if err != nil {
 		t.Fatalf("failed to get custom ip prefix: %+v", err)
 	}
 	t.Logf("got custom ip prefix")
 
-	err = ListCustomIpPrefix(ctx)
+	_, err = ListCustomIpPrefix(ctx)
 	if err != nil {
 		t.Fatalf("failed to list custom ip prefix: %+v", err)
 	}
 	t.Logf("listed custom ip prefix")
 
-	err = ListAllCustomIpPrefix(ctx)
+	_, err = ListAllCustomIpPrefix(ctx)
 	if err != nil {
 		t.Fatalf("failed to list all custom ip prefix: %+v", err)
 	}
@@ -62,7 +65,7 @@ func TestCustomIpPrefix(t *testing.T) {
 	tagsObjectParameters := armnetwork.TagsObject{
 		Tags: &map[string]*string{"tag1": to.StringPtr("value1"), "tag2": to.StringPtr("value2")},
 	}
-	err = UpdateCustomIpPrefixTags(ctx, customIpPrefixName, tagsObjectParameters)
+	_, err = UpdateCustomIpPrefixTags(ctx, customIpPrefixName, tagsObjectParameters)
 	if err != nil {
 		t.Fatalf("failed to update tags for custom ip prefix: %+v", err)
 	}
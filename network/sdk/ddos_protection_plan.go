@@ -11,96 +11,95 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
 )
 
-func getDdosProtectionPlansClient() armnetwork.DdosProtectionPlansClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getDdosProtectionPlansClient(ctx context.Context) armnetwork.DdosProtectionPlansClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewDdosProtectionPlansClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewDdosProtectionPlansClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Create DdosProtectionPlans
-func CreateDdosProtectionPlan(ctx context.Context, ddosProtectionPlanName string) error {
-	client := getDdosProtectionPlansClient()
+func CreateDdosProtectionPlan(ctx context.Context, ddosProtectionPlanName string) (*armnetwork.DdosProtectionPlan, error) {
+	client := getDdosProtectionPlansClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		ddosProtectionPlanName,
 		armnetwork.DdosProtectionPlan{
-			Location: to.StringPtr(config.Location()),
+			Location: to.StringPtr(config.ScopeFrom(ctx).Location),
 		},
 		nil,
 	)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.DdosProtectionPlan, nil
 }
 
 // Gets the specified ddos protection plan in a specified resource group.
-func GetDdosProtectionPlan(ctx context.Context, ddosProtectionPlanName string) error {
-	client := getDdosProtectionPlansClient()
-	_, err := client.Get(ctx, config.GroupName(), ddosProtectionPlanName, nil)
+func GetDdosProtectionPlan(ctx context.Context, ddosProtectionPlanName string) (*armnetwork.DdosProtectionPlan, error) {
+	client := getDdosProtectionPlansClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, ddosProtectionPlanName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.DdosProtectionPlan, nil
 }
 
 // Gets all the ddos protection plan in a subscription.
-func ListDdosProtectionPlan(ctx context.Context) error {
-	client := getDdosProtectionPlansClient()
+func ListDdosProtectionPlan(ctx context.Context) ([]*armnetwork.DdosProtectionPlan, error) {
+	client := getDdosProtectionPlansClient(ctx)
 	pager := client.List(nil)
 
+	var results []*armnetwork.DdosProtectionPlan
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().DdosProtectionPlanListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Updates ddos protection plan tags.
-func UpdateDdosProtectionPlanTags(ctx context.Context, ddosProtectionPlanName string, tagsObjectParameters armnetwork.TagsObject) error {
-	client := getDdosProtectionPlansClient()
-	_, err := client.UpdateTags(
+func UpdateDdosProtectionPlanTags(ctx context.Context, ddosProtectionPlanName string, tagsObjectParameters armnetwork.TagsObject) (*armnetwork.DdosProtectionPlan, error) {
+	client := getDdosProtectionPlansClient(ctx)
+	resp, err := client.UpdateTags(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		ddosProtectionPlanName,
 		tagsObjectParameters,
 		nil,
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.DdosProtectionPlan, nil
 }
 
 // Deletes the specified ddos protection plan.
 func DeleteDdosProtectionPlan(ctx context.Context, ddosProtectionPlanName string) error {
-	client := getDdosProtectionPlansClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), ddosProtectionPlanName, nil)
+	client := getDdosProtectionPlansClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, ddosProtectionPlanName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -108,17 +107,17 @@ func DeleteDdosProtectionPlan(ctx context.Context, ddosProtectionPlanName string
 }
 
 // Gets all ddos protection plan in a resource group.
-func ListDdosProtectionPlanByResourceGroup(ctx context.Context) error {
-	client := getDdosProtectionPlansClient()
-	pager := client.ListByResourceGroup(config.GroupName(), nil)
+func ListDdosProtectionPlanByResourceGroup(ctx context.Context) ([]*armnetwork.DdosProtectionPlan, error) {
+	client := getDdosProtectionPlansClient(ctx)
+	pager := client.ListByResourceGroup(config.ScopeFrom(ctx).GroupName, nil)
+
+	var results []*armnetwork.DdosProtectionPlan
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().DdosProtectionPlanListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
@@ -11,19 +11,21 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
 )
 
 func TestDdosProtectionPlan(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	ddosProtectionPlanName := config.AppendRandomSuffix("ddosprotectionplan")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -31,25 +33,25 @@ func TestDdosProtectionPlan(t *testing.T) {
 		t.Fatalf("failed to create group: %+v", err)
 	}
 
-	err = CreateDdosProtectionPlan(ctx, ddosProtectionPlanName)
+	_, err = CreateDdosProtectionPlan(ctx, ddosProtectionPlanName)
 	if err != nil {
 		t.Fatalf("failed to create ddos protection plan: % +v", err)
 	}
 	t.Logf("created ddos protection plan")
 
-	err = GetDdosProtectionPlan(ctx, ddosProtectionPlanName)
+	_, err = GetDdosProtectionPlan(ctx, ddosProtectionPlanName)
 	if err != nil {
 		t.Fatalf("failed to get ddos protection plan: %+v", err)
 	}
 	t.Logf("got ddos protection plan")
 
-	err = ListDdosProtectionPlan(ctx)
+	_, err = ListDdosProtectionPlan(ctx)
 	if err != nil {
 		t.Fatalf("failed to list ddos protection plan: %+v", err)
 	}
 	t.Logf("listed ddos protection plan")
 
-	err = ListDdosProtectionPlanByResourceGroup(ctx)
+	_, err = ListDdosProtectionPlanByResourceGroup(ctx)
 	if err != nil {
 		t.Fatalf("failed to listddos protection plan by resource group: %+v", err)
 	}
@@ -58,7 +60,7 @@ func TestDdosProtectionPlan(t *testing.T) {
 	tagsObjectParameters := armnetwork.TagsObject{
 		Tags: map[string]*string{"tag1": to.StringPtr("value1"), "tag2": to.StringPtr("value2")},
 	}
-	err = UpdateDdosProtectionPlanTags(ctx, ddosProtectionPlanName, tagsObjectParameters)
+	_, err = UpdateDdosProtectionPlanTags(ctx, ddosProtectionPlanName, tagsObjectParameters)
 	if err != nil {
 		t.Fatalf("failed to update tags for ddos protection plan: %+v", err)
 	}
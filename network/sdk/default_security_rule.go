@@ -10,43 +10,41 @@ import (
 	"log"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getDefaultSecurityRulesClient() armnetwork.DefaultSecurityRulesClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getDefaultSecurityRulesClient(ctx context.Context) armnetwork.DefaultSecurityRulesClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewDefaultSecurityRulesClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewDefaultSecurityRulesClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Get the specified default network security rule.
-func GetDefaultSecurityRule(ctx context.Context, networkSecurityGroupName string, defaultSecurityRuleName string) error {
-	client := getDefaultSecurityRulesClient()
-	_, err := client.Get(ctx, config.GroupName(), networkSecurityGroupName, defaultSecurityRuleName, nil)
+func GetDefaultSecurityRule(ctx context.Context, networkSecurityGroupName string, defaultSecurityRuleName string) (*armnetwork.SecurityRule, error) {
+	client := getDefaultSecurityRulesClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, networkSecurityGroupName, defaultSecurityRuleName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.SecurityRule, nil
 }
 
 // Gets all default security rules in a network security group.
-func ListDefaultSecurityRule(ctx context.Context, networkSecurityGroupName string) error {
-	client := getDefaultSecurityRulesClient()
-	pager := client.List(config.GroupName(), networkSecurityGroupName, nil)
+func ListDefaultSecurityRule(ctx context.Context, networkSecurityGroupName string) ([]*armnetwork.SecurityRule, error) {
+	client := getDefaultSecurityRulesClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).GroupName, networkSecurityGroupName, nil)
 
+	var results []*armnetwork.SecurityRule
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().SecurityRuleListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
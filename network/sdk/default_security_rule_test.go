@@ -11,20 +11,22 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
 )
 
 func TestDefaultSecurityRule(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	networkSecurityGroupName := config.AppendRandomSuffix("networksecuritygroup")
 	defaultSecurityRuleName := "AllowVnetInBound"
 
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -42,13 +44,13 @@ func TestDefaultSecurityRule(t *testing.T) {
 		t.Fatalf("failed to create network security group: % +v", err)
 	}
 
-	err = GetDefaultSecurityRule(ctx, networkSecurityGroupName, defaultSecurityRuleName)
+	_, err = GetDefaultSecurityRule(ctx, networkSecurityGroupName, defaultSecurityRuleName)
 	if err != nil {
 		t.Fatalf("failed to get default security rule: %+v", err)
 	}
 	t.Logf("got default security rule")
 
-	err = ListDefaultSecurityRule(ctx, networkSecurityGroupName)
+	_, err = ListDefaultSecurityRule(ctx, networkSecurityGroupName)
 	if err != nil {
 		t.Fatalf("failed to list default security rule: %+v", err)
 	}
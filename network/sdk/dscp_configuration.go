@@ -11,76 +11,76 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getDscpConfigurationClient() armnetwork.DscpConfigurationClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getDscpConfigurationClient(ctx context.Context) armnetwork.DscpConfigurationClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewDscpConfigurationClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewDscpConfigurationClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Creates or updates a DSCP Configuration
-func CreateDscpConfiguration(ctx context.Context, dscpConfigurationName string, dscpConfigurationParameters armnetwork.DscpConfiguration) error {
-	client := getDscpConfigurationClient()
+func CreateDscpConfiguration(ctx context.Context, dscpConfigurationName string, dscpConfigurationParameters armnetwork.DscpConfiguration) (*armnetwork.DscpConfiguration, error) {
+	client := getDscpConfigurationClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		dscpConfigurationName,
 		dscpConfigurationParameters,
 		nil,
 	)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.DscpConfiguration, nil
 }
 
 // Gets a DSCP Configuration.
-func GetDscpConfiguration(ctx context.Context, dscpConfigurationName string) error {
-	client := getDscpConfigurationClient()
-	_, err := client.Get(ctx, config.GroupName(), dscpConfigurationName, nil)
+func GetDscpConfiguration(ctx context.Context, dscpConfigurationName string) (*armnetwork.DscpConfiguration, error) {
+	client := getDscpConfigurationClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, dscpConfigurationName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.DscpConfiguration, nil
 }
 
 // Gets all dscp configurations in a subscription.
-func ListAllDscpConfiguration(ctx context.Context) error {
-	client := getDscpConfigurationClient()
+func ListAllDscpConfiguration(ctx context.Context) ([]*armnetwork.DscpConfiguration, error) {
+	client := getDscpConfigurationClient(ctx)
 	pager := client.ListAll(nil)
+
+	var results []*armnetwork.DscpConfiguration
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().DscpConfigurationListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Deletes a DSCP Configuration.
 func DeleteDscpConfiguration(ctx context.Context, dscpConfigurationName string) error {
-	client := getDscpConfigurationClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), dscpConfigurationName, nil)
+	client := getDscpConfigurationClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, dscpConfigurationName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
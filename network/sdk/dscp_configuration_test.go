@@ -7,19 +7,22 @@ package network
 
 import (
 	"testing"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 )
 
 func TestDscpConfiguration(t *testing.T) {
+	defer recording.Start(t)()
+
 	/* Error Message: DSCP Configuration is currently not supported
 	seems it’s API limitation,disable it with the reason for now
 
 	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
-
 	dscpConfigurationName := config.AppendRandomSuffix("dscpconfiguration")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -76,19 +79,19 @@ func TestDscpConfiguration(t *testing.T) {
 			},
 		},
 	}
-	err = CreateDscpConfiguration(ctx, dscpConfigurationName, dscpConfigurationParameters)
+	_, err = CreateDscpConfiguration(ctx, dscpConfigurationName, dscpConfigurationParameters)
 	if err != nil {
 		t.Fatalf("failed to create dscp configuration: % +v", err)
 	}
 	t.Logf("created dscp configuration")
 
-	err = GetDscpConfiguration(ctx, dscpConfigurationName)
+	_, err = GetDscpConfiguration(ctx, dscpConfigurationName)
 	if err != nil {
 		t.Fatalf("failed to get dscp configuration: %+v", err)
 	}
 	t.Logf("got dscp configuration")
 
-	err = ListAllDscpConfiguration(ctx)
+	_, err = ListAllDscpConfiguration(ctx)
 	if err != nil {
 		t.Fatalf("failed to list all dscp configuration: %+v", err)
 	}
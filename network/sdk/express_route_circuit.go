@@ -11,26 +11,26 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getExpressRouteCircuitsClient() armnetwork.ExpressRouteCircuitsClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getExpressRouteCircuitsClient(ctx context.Context) armnetwork.ExpressRouteCircuitsClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewExpressRouteCircuitsClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewExpressRouteCircuitsClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Create ExpressRouteCircuits
 func CreateExpressRouteCircuit(ctx context.Context, expressRouteCircuitName string, expressRouteCircuitParameters armnetwork.ExpressRouteCircuit) (string, error) {
-	client := getExpressRouteCircuitsClient()
+	client := getExpressRouteCircuitsClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		expressRouteCircuitName,
 		expressRouteCircuitParameters,
 		nil,
@@ -40,7 +40,7 @@ func CreateExpressRouteCircuit(ctx context.Context, expressRouteCircuitName stri
 		return "", err
 	}
 
-	resp, err := poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return "", err
 	}
@@ -49,76 +49,75 @@ func CreateExpressRouteCircuit(ctx context.Context, expressRouteCircuitName stri
 }
 
 // Gets all stats from an express route circuit in a resource group.
-func GetExpressRouteCircuitPeeringStats(ctx context.Context, expressRouteCircuitName string, peeringName string) error {
-	client := getExpressRouteCircuitsClient()
-	_, err := client.GetPeeringStats(ctx, config.GroupName(), expressRouteCircuitName, peeringName, nil)
+func GetExpressRouteCircuitPeeringStats(ctx context.Context, expressRouteCircuitName string, peeringName string) (*armnetwork.ExpressRouteCircuitStats, error) {
+	client := getExpressRouteCircuitsClient(ctx)
+	resp, err := client.GetPeeringStats(ctx, config.ScopeFrom(ctx).GroupName, expressRouteCircuitName, peeringName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.ExpressRouteCircuitStats, nil
 }
 
 // Gets all the stats from an express route circuit in a resource group.
-func GetExpressRouteCircuiStats(ctx context.Context, expressRouteCircuitName string) error {
-	client := getExpressRouteCircuitsClient()
-	_, err := client.GetStats(ctx, config.GroupName(), expressRouteCircuitName, nil)
+func GetExpressRouteCircuiStats(ctx context.Context, expressRouteCircuitName string) (*armnetwork.ExpressRouteCircuitStats, error) {
+	client := getExpressRouteCircuitsClient(ctx)
+	resp, err := client.GetStats(ctx, config.ScopeFrom(ctx).GroupName, expressRouteCircuitName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.ExpressRouteCircuitStats, nil
 }
 
 // Gets the specified express route circuit in a specified resource group.
-func GetExpressRouteCircuit(ctx context.Context, expressRouteCircuitName string) error {
-	client := getExpressRouteCircuitsClient()
-	_, err := client.Get(ctx, config.GroupName(), expressRouteCircuitName, nil)
+func GetExpressRouteCircuit(ctx context.Context, expressRouteCircuitName string) (*armnetwork.ExpressRouteCircuit, error) {
+	client := getExpressRouteCircuitsClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, expressRouteCircuitName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.ExpressRouteCircuit, nil
 }
 
 // Gets all the express route circuit in a subscription.
-func ListExpressRouteCircuit(ctx context.Context) error {
-	client := getExpressRouteCircuitsClient()
-	pager := client.List(config.GroupName(), nil)
+func ListExpressRouteCircuit(ctx context.Context) ([]*armnetwork.ExpressRouteCircuit, error) {
+	client := getExpressRouteCircuitsClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).GroupName, nil)
 
+	var results []*armnetwork.ExpressRouteCircuit
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().ExpressRouteCircuitListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Gets all the express route circuit in a subscription.
-func ListAllExpressRouteCircuit(ctx context.Context) error {
-	client := getExpressRouteCircuitsClient()
+func ListAllExpressRouteCircuit(ctx context.Context) ([]*armnetwork.ExpressRouteCircuit, error) {
+	client := getExpressRouteCircuitsClient(ctx)
 	pager := client.ListAll(nil)
+
+	var results []*armnetwork.ExpressRouteCircuit
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().ExpressRouteCircuitListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Deletes the specified express route circuit.
 func DeleteExpressRouteCircuit(ctx context.Context, expressRouteCircuitName string) error {
-	client := getExpressRouteCircuitsClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), expressRouteCircuitName, nil)
+	client := getExpressRouteCircuitsClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, expressRouteCircuitName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
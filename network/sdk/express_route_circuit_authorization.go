@@ -11,26 +11,26 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getExpressRouteCircuitAuthorizationsClient() armnetwork.ExpressRouteCircuitAuthorizationsClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getExpressRouteCircuitAuthorizationsClient(ctx context.Context) armnetwork.ExpressRouteCircuitAuthorizationsClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewExpressRouteCircuitAuthorizationsClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewExpressRouteCircuitAuthorizationsClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Creates or updates an authorization in the specified express route circuit.
-func CreateExpressRouteCircuitAuthorization(ctx context.Context, circuitName string, expressRouteCircuitAuthorizationName string) error {
-	client := getExpressRouteCircuitAuthorizationsClient()
+func CreateExpressRouteCircuitAuthorization(ctx context.Context, circuitName string, expressRouteCircuitAuthorizationName string) (*armnetwork.ExpressRouteCircuitAuthorization, error) {
+	client := getExpressRouteCircuitAuthorizationsClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		circuitName,
 		expressRouteCircuitAuthorizationName,
 		armnetwork.ExpressRouteCircuitAuthorization{},
@@ -38,51 +38,50 @@ func CreateExpressRouteCircuitAuthorization(ctx context.Context, circuitName str
 	)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.ExpressRouteCircuitAuthorization, nil
 }
 
 // Gets the specified authorization from the specified express route circuit.
-func GetExpressRouteCircuitAuthorization(ctx context.Context, circuitName string, expressRouteCircuitAuthorizationName string) error {
-	client := getExpressRouteCircuitAuthorizationsClient()
-	_, err := client.Get(ctx, config.GroupName(), circuitName, expressRouteCircuitAuthorizationName, nil)
+func GetExpressRouteCircuitAuthorization(ctx context.Context, circuitName string, expressRouteCircuitAuthorizationName string) (*armnetwork.ExpressRouteCircuitAuthorization, error) {
+	client := getExpressRouteCircuitAuthorizationsClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, circuitName, expressRouteCircuitAuthorizationName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.ExpressRouteCircuitAuthorization, nil
 }
 
 // Gets all authorizations in an express route circuit.
-func ListExpressRouteCircuitAuthorization(ctx context.Context, circuitName string) error {
-	client := getExpressRouteCircuitAuthorizationsClient()
-	pager := client.List(config.GroupName(), circuitName, nil)
+func ListExpressRouteCircuitAuthorization(ctx context.Context, circuitName string) ([]*armnetwork.ExpressRouteCircuitAuthorization, error) {
+	client := getExpressRouteCircuitAuthorizationsClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).GroupName, circuitName, nil)
 
+	var results []*armnetwork.ExpressRouteCircuitAuthorization
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().AuthorizationListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Deletes the specified authorization from the specified express route circuit.
 func DeleteExpressRouteCircuitAuthorization(ctx context.Context, circuitName string, expressRouteCircuitAuthorizationName string) error {
-	client := getExpressRouteCircuitAuthorizationsClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), circuitName, expressRouteCircuitAuthorizationName, nil)
+	client := getExpressRouteCircuitAuthorizationsClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, circuitName, expressRouteCircuitAuthorizationName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
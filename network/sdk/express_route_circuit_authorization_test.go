@@ -11,20 +11,22 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
 )
 
 func TestExpressRouteCircuitAuthorization(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	expressRouteCircuitAuthorizationName := config.AppendRandomSuffix("expressroutecircuitauthorization")
 	expressRouteCircuitName := config.AppendRandomSuffix("expressroutecircuit")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -55,19 +57,19 @@ func TestExpressRouteCircuitAuthorization(t *testing.T) {
 		t.Fatalf("failed to create express route circuit: % +v", err)
 	}
 
-	err = CreateExpressRouteCircuitAuthorization(ctx, expressRouteCircuitName, expressRouteCircuitAuthorizationName)
+	_, err = CreateExpressRouteCircuitAuthorization(ctx, expressRouteCircuitName, expressRouteCircuitAuthorizationName)
 	if err != nil {
 		t.Fatalf("failed to create express route circuit authorization: % +v", err)
 	}
 	t.Logf("created express route circuit authorization")
 
-	err = GetExpressRouteCircuitAuthorization(ctx, expressRouteCircuitName, expressRouteCircuitAuthorizationName)
+	_, err = GetExpressRouteCircuitAuthorization(ctx, expressRouteCircuitName, expressRouteCircuitAuthorizationName)
 	if err != nil {
 		t.Fatalf("failed to get express route circuit authorization: %+v", err)
 	}
 	t.Logf("got express route circuit authorization")
 
-	err = ListExpressRouteCircuitAuthorization(ctx, expressRouteCircuitName)
+	_, err = ListExpressRouteCircuitAuthorization(ctx, expressRouteCircuitName)
 	if err != nil {
 		t.Fatalf("failed to list express route circuit authorization: %+v", err)
 	}
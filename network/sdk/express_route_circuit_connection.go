@@ -11,26 +11,26 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getExpressRouteCircuitConnectionsClient() armnetwork.ExpressRouteCircuitConnectionsClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getExpressRouteCircuitConnectionsClient(ctx context.Context) armnetwork.ExpressRouteCircuitConnectionsClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewExpressRouteCircuitConnectionsClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewExpressRouteCircuitConnectionsClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Creates or updates a Express Route Circuit Connection in the specified express route circuits.
-func CreateExpressRouteCircuitConnection(ctx context.Context, circuitName string, peeringName string, connectionName string, parameters armnetwork.ExpressRouteCircuitConnection) error {
-	client := getExpressRouteCircuitConnectionsClient()
+func CreateExpressRouteCircuitConnection(ctx context.Context, circuitName string, peeringName string, connectionName string, parameters armnetwork.ExpressRouteCircuitConnection) (*armnetwork.ExpressRouteCircuitConnection, error) {
+	client := getExpressRouteCircuitConnectionsClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		circuitName,
 		peeringName,
 		connectionName,
@@ -39,51 +39,50 @@ func CreateExpressRouteCircuitConnection(ctx context.Context, circuitName string
 	)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.ExpressRouteCircuitConnection, nil
 }
 
 // Gets the specified Express Route Circuit Connection from the specified express route circuit.
-func GetExpressRouteCircuitConnection(ctx context.Context, circuitName string, peeringName string, connectionName string) error {
-	client := getExpressRouteCircuitConnectionsClient()
-	_, err := client.Get(ctx, config.GroupName(), circuitName, peeringName, connectionName, nil)
+func GetExpressRouteCircuitConnection(ctx context.Context, circuitName string, peeringName string, connectionName string) (*armnetwork.ExpressRouteCircuitConnection, error) {
+	client := getExpressRouteCircuitConnectionsClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, circuitName, peeringName, connectionName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.ExpressRouteCircuitConnection, nil
 }
 
 // Gets all global reach connections associated with a private peering in an express route circuit.
-func ListExpressRouteCircuitConnection(ctx context.Context, circuitName string, peeringName string) error {
-	client := getExpressRouteCircuitConnectionsClient()
-	pager := client.List(config.GroupName(), circuitName, peeringName, nil)
+func ListExpressRouteCircuitConnection(ctx context.Context, circuitName string, peeringName string) ([]*armnetwork.ExpressRouteCircuitConnection, error) {
+	client := getExpressRouteCircuitConnectionsClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).GroupName, circuitName, peeringName, nil)
 
+	var results []*armnetwork.ExpressRouteCircuitConnection
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().ExpressRouteCircuitConnectionListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Deletes the specified Express Route Circuit Connection from the specified express route circuit.
 func DeleteExpressRouteCircuitConnection(ctx context.Context, circuitName string, peeringName string, connectionName string) error {
-	client := getExpressRouteCircuitConnectionsClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), circuitName, peeringName, connectionName, nil)
+	client := getExpressRouteCircuitConnectionsClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, circuitName, peeringName, connectionName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
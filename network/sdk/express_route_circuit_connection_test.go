@@ -11,15 +11,16 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
 )
 
 func TestExpressRouteCircuitConnection(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	// expressRouteCircuitConnectionName := config.AppendRandomSuffix("expressroutecircuitconnection")
 	expressRouteCircuitName := config.AppendRandomSuffix("expressroutecircuit")
 	expressRouteCircuitName2 := config.AppendRandomSuffix("expressroutecircuit2")
@@ -27,6 +28,7 @@ func TestExpressRouteCircuitConnection(t *testing.T) {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
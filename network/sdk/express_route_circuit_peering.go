@@ -11,26 +11,26 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getExpressRouteCircuitPeeringsClient() armnetwork.ExpressRouteCircuitPeeringsClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getExpressRouteCircuitPeeringsClient(ctx context.Context) armnetwork.ExpressRouteCircuitPeeringsClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewExpressRouteCircuitPeeringsClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewExpressRouteCircuitPeeringsClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Create ExpressRouteCircuitPeerings
 func CreateExpressRouteCircuitPeering(ctx context.Context, circuitName string, expressRouteCircuitPeeringName string, expressRouteCircuitPeeringParameters armnetwork.ExpressRouteCircuitPeering) (string, error) {
-	client := getExpressRouteCircuitPeeringsClient()
+	client := getExpressRouteCircuitPeeringsClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		circuitName,
 		expressRouteCircuitPeeringName,
 		expressRouteCircuitPeeringParameters,
@@ -41,7 +41,7 @@ func CreateExpressRouteCircuitPeering(ctx context.Context, circuitName string, e
 		return "", err
 	}
 
-	resp, err := poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return "", err
 	}
@@ -50,40 +50,39 @@ func CreateExpressRouteCircuitPeering(ctx context.Context, circuitName string, e
 }
 
 // Gets the specified peering for the express route circuit.
-func GetExpressRouteCircuitPeering(ctx context.Context, circuitName string, expressRouteCircuitPeeringName string) error {
-	client := getExpressRouteCircuitPeeringsClient()
-	_, err := client.Get(ctx, config.GroupName(), circuitName, expressRouteCircuitPeeringName, nil)
+func GetExpressRouteCircuitPeering(ctx context.Context, circuitName string, expressRouteCircuitPeeringName string) (*armnetwork.ExpressRouteCircuitPeering, error) {
+	client := getExpressRouteCircuitPeeringsClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, circuitName, expressRouteCircuitPeeringName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.ExpressRouteCircuitPeering, nil
 }
 
 // Gets all peerings in a specified express route circuit.
-func ListExpressRouteCircuitPeering(ctx context.Context, circuitName string) error {
-	client := getExpressRouteCircuitPeeringsClient()
-	pager := client.List(config.GroupName(), circuitName, nil)
+func ListExpressRouteCircuitPeering(ctx context.Context, circuitName string) ([]*armnetwork.ExpressRouteCircuitPeering, error) {
+	client := getExpressRouteCircuitPeeringsClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).GroupName, circuitName, nil)
 
+	var results []*armnetwork.ExpressRouteCircuitPeering
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().ExpressRouteCircuitPeeringListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Deletes the specified peering from the specified express route circuit.
 func DeleteExpressRouteCircuitPeering(ctx context.Context, circuitName string, expressRouteCircuitPeeringName string) error {
-	client := getExpressRouteCircuitPeeringsClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), circuitName, expressRouteCircuitPeeringName, nil)
+	client := getExpressRouteCircuitPeeringsClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, circuitName, expressRouteCircuitPeeringName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
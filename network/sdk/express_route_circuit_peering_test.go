@@ -11,20 +11,22 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
 )
 
 func TestExpressRouteCircuitPeering(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	expressRouteCircuitPeeringName := "AzurePrivatePeering"
 	expressRouteCircuitName := config.AppendRandomSuffix("expressroutecircuit")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -69,13 +71,13 @@ func TestExpressRouteCircuitPeering(t *testing.T) {
 	}
 	t.Logf("created express route circuit peering")
 
-	err = GetExpressRouteCircuitPeering(ctx, expressRouteCircuitName, expressRouteCircuitPeeringName)
+	_, err = GetExpressRouteCircuitPeering(ctx, expressRouteCircuitName, expressRouteCircuitPeeringName)
 	if err != nil {
 		t.Fatalf("failed to get express route circuit peering: %+v", err)
 	}
 	t.Logf("got express route circuit peering")
 
-	err = ListExpressRouteCircuitPeering(ctx, expressRouteCircuitName)
+	_, err = ListExpressRouteCircuitPeering(ctx, expressRouteCircuitName)
 	if err != nil {
 		t.Fatalf("failed to list express route circuit peering: %+v", err)
 	}
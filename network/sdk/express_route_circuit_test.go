@@ -11,20 +11,22 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
 )
 
 func TestExpressRouteCircuit(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	expressRouteCircuitName := config.AppendRandomSuffix("expressroutecircuit")
 	expressRouteCircuitPeeringName := "AzurePrivatePeering"
 
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -69,31 +71,31 @@ func TestExpressRouteCircuit(t *testing.T) {
 		t.Fatalf("failed to create express route circuit peering: % +v", err)
 	}
 
-	err = GetExpressRouteCircuitPeeringStats(ctx, expressRouteCircuitName, expressRouteCircuitPeeringName)
+	_, err = GetExpressRouteCircuitPeeringStats(ctx, expressRouteCircuitName, expressRouteCircuitPeeringName)
 	if err != nil {
 		t.Fatalf("failed to get express route circuit peering stats: %+v", err)
 	}
 	t.Logf("got express route circuit peering stats")
 
-	err = GetExpressRouteCircuiStats(ctx, expressRouteCircuitName)
+	_, err = GetExpressRouteCircuiStats(ctx, expressRouteCircuitName)
 	if err != nil {
 		t.Fatalf("failed to get express route circuit stats: %+v", err)
 	}
 	t.Logf("got express route circuit stats")
 
-	err = GetExpressRouteCircuit(ctx, expressRouteCircuitName)
+	_, err = GetExpressRouteCircuit(ctx, expressRouteCircuitName)
 	if err != nil {
 		t.Fatalf("failed to get express route circuit: %+v", err)
 	}
 	t.Logf("got express route circuit")
 
-	err = ListExpressRouteCircuit(ctx)
+	_, err = ListExpressRouteCircuit(ctx)
 	if err != nil {
 		t.Fatalf("failed to list express route circuit: %+v", err)
 	}
 	t.Logf("listed express route circuit")
 
-	err = ListAllExpressRouteCircuit(ctx)
+	_, err = ListAllExpressRouteCircuit(ctx)
 	if err != nil {
 		t.Fatalf("failed to list all express route circuit: %+v", err)
 	}
@@ -10,33 +10,31 @@ import (
 	"log"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getExpressRouteServiceProvidersClient() armnetwork.ExpressRouteServiceProvidersClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getExpressRouteServiceProvidersClient(ctx context.Context) armnetwork.ExpressRouteServiceProvidersClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewExpressRouteServiceProvidersClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewExpressRouteServiceProvidersClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Gets all the available express route service providers.
-func ListExpressRouteServiceProvider(ctx context.Context) error {
-	client := getExpressRouteServiceProvidersClient()
+func ListExpressRouteServiceProvider(ctx context.Context) ([]*armnetwork.ExpressRouteServiceProvider, error) {
+	client := getExpressRouteServiceProvidersClient(ctx)
 	pager := client.List(nil)
 
+	var results []*armnetwork.ExpressRouteServiceProvider
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().ExpressRouteServiceProviderListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
@@ -11,26 +11,26 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getFirewallsClient() armnetwork.AzureFirewallsClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getFirewallsClient(ctx context.Context) armnetwork.AzureFirewallsClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewAzureFirewallsClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewAzureFirewallsClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Create Firewalls
 func CreateFirewall(ctx context.Context, firewallName string, azureFirewallParameters armnetwork.AzureFirewall) (string, error) {
-	client := getFirewallsClient()
+	client := getFirewallsClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		firewallName,
 		azureFirewallParameters,
 		nil,
@@ -40,7 +40,7 @@ func CreateFirewall(ctx context.Context, firewallName string, azureFirewallParam
 		return "", err
 	}
 
-	resp, err := poller.PollUntilDone(ctx, 120*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(120*time.Second))
 	if err != nil {
 		return "", err
 	}
@@ -49,104 +49,103 @@ func CreateFirewall(ctx context.Context, firewallName string, azureFirewallParam
 }
 
 // Gets the specified firewall in a specified resource group.
-func GetFirewall(ctx context.Context, firewallName string) error {
-	client := getFirewallsClient()
-	_, err := client.Get(ctx, config.GroupName(), firewallName, nil)
+func GetFirewall(ctx context.Context, firewallName string) (*armnetwork.AzureFirewall, error) {
+	client := getFirewallsClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, firewallName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.AzureFirewall, nil
 }
 
 // Gets all the firewall in a subscription.
-func ListFirewall(ctx context.Context) error {
-	client := getFirewallsClient()
-	pager := client.List(config.GroupName(), nil)
+func ListFirewall(ctx context.Context) ([]*armnetwork.AzureFirewall, error) {
+	client := getFirewallsClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).GroupName, nil)
 
+	var results []*armnetwork.AzureFirewall
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().AzureFirewallListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Gets all the firewall in a subscription.
-func ListAllFirewall(ctx context.Context) error {
-	client := getFirewallsClient()
+func ListAllFirewall(ctx context.Context) ([]*armnetwork.AzureFirewall, error) {
+	client := getFirewallsClient(ctx)
 	pager := client.ListAll(nil)
+
+	var results []*armnetwork.AzureFirewall
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().AzureFirewallListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Updates firewall tags.
-func UpdateFirewallTags(ctx context.Context, firewallName string, tagsObjectParameters armnetwork.TagsObject) error {
-	client := getFirewallsClient()
+func UpdateFirewallTags(ctx context.Context, firewallName string, tagsObjectParameters armnetwork.TagsObject) (*armnetwork.AzureFirewall, error) {
+	client := getFirewallsClient(ctx)
 	poller, err := client.BeginUpdateTags(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		firewallName,
 		tagsObjectParameters,
 		nil,
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.AzureFirewall, nil
 }
 
 // Deletes the specified firewall.
 func DeleteFirewall(ctx context.Context, firewallName string) error {
-	client := getFirewallsClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), firewallName, nil)
+	client := getFirewallsClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, firewallName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func getAzureFirewallFqdnTagsClient() armnetwork.AzureFirewallFqdnTagsClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getAzureFirewallFqdnTagsClient(ctx context.Context) armnetwork.AzureFirewallFqdnTagsClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewAzureFirewallFqdnTagsClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewAzureFirewallFqdnTagsClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Gets all the azure firewall fqdn tag in a subscription.
-func ListAllAzureFirewallFqdnTag(ctx context.Context) error {
-	client := getAzureFirewallFqdnTagsClient()
+func ListAllAzureFirewallFqdnTag(ctx context.Context) ([]*armnetwork.AzureFirewallFqdnTag, error) {
+	client := getAzureFirewallFqdnTagsClient(ctx)
 	pager := client.ListAll(nil)
+
+	var results []*armnetwork.AzureFirewallFqdnTag
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().AzureFirewallFqdnTagListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
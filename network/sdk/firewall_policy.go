@@ -11,26 +11,26 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getFirewallPolicysClient() armnetwork.FirewallPoliciesClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getFirewallPolicysClient(ctx context.Context) armnetwork.FirewallPoliciesClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewFirewallPoliciesClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewFirewallPoliciesClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
-//  Creates or updates the specified Firewall Policy.
+// Creates or updates the specified Firewall Policy.
 func CreateFirewallPolicy(ctx context.Context, firewallPolicyName string, firewallPolicyParameters armnetwork.FirewallPolicy) (string, error) {
-	client := getFirewallPolicysClient()
+	client := getFirewallPolicysClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		firewallPolicyName,
 		firewallPolicyParameters,
 		nil,
@@ -40,7 +40,7 @@ func CreateFirewallPolicy(ctx context.Context, firewallPolicyName string, firewa
 		return "", err
 	}
 
-	resp, err := poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return "", err
 	}
@@ -49,56 +49,55 @@ func CreateFirewallPolicy(ctx context.Context, firewallPolicyName string, firewa
 }
 
 // Gets the specified firewall policy in a specified resource group.
-func GetFirewallPolicy(ctx context.Context, firewallPolicyName string) error {
-	client := getFirewallPolicysClient()
-	_, err := client.Get(ctx, config.GroupName(), firewallPolicyName, nil)
+func GetFirewallPolicy(ctx context.Context, firewallPolicyName string) (*armnetwork.FirewallPolicy, error) {
+	client := getFirewallPolicysClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, firewallPolicyName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.FirewallPolicy, nil
 }
 
 // Gets all the firewall policy in a subscription.
-func ListFirewallPolicy(ctx context.Context) error {
-	client := getFirewallPolicysClient()
-	pager := client.List(config.GroupName(), nil)
+func ListFirewallPolicy(ctx context.Context) ([]*armnetwork.FirewallPolicy, error) {
+	client := getFirewallPolicysClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).GroupName, nil)
 
+	var results []*armnetwork.FirewallPolicy
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().FirewallPolicyListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Gets all the firewall policy in a subscription.
-func ListAllFirewallPolicy(ctx context.Context) error {
-	client := getFirewallPolicysClient()
+func ListAllFirewallPolicy(ctx context.Context) ([]*armnetwork.FirewallPolicy, error) {
+	client := getFirewallPolicysClient(ctx)
 	pager := client.ListAll(nil)
+
+	var results []*armnetwork.FirewallPolicy
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().FirewallPolicyListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Deletes the specified firewall policy.
 func DeleteFirewallPolicy(ctx context.Context, firewallPolicyName string) error {
-	client := getFirewallPolicysClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), firewallPolicyName, nil)
+	client := getFirewallPolicysClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, firewallPolicyName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -11,29 +11,29 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getFirewallPolicyRuleCollectionGroupsClient() armnetwork.FirewallPolicyRuleCollectionGroupsClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getFirewallPolicyRuleCollectionGroupsClient(ctx context.Context) armnetwork.FirewallPolicyRuleCollectionGroupsClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewFirewallPolicyRuleCollectionGroupsClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewFirewallPolicyRuleCollectionGroupsClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Creates or updates the specified FirewallPolicyRuleCollectionGroup.
-func CreateFirewallPolicyRuleCollectionGroup(ctx context.Context, firewallPolicyName string, firewallPolicyRuleCollectionGroupName string, body string) error {
-	client := getFirewallPolicyRuleCollectionGroupsClient()
+func CreateFirewallPolicyRuleCollectionGroup(ctx context.Context, firewallPolicyName string, firewallPolicyRuleCollectionGroupName string, body string) (*armnetwork.FirewallPolicyRuleCollectionGroup, error) {
+	client := getFirewallPolicyRuleCollectionGroupsClient(ctx)
 	parameter := armnetwork.FirewallPolicyRuleCollectionGroupProperties{}
 	parameter.UnmarshalJSON([]byte(body))
 
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		firewallPolicyName,
 		firewallPolicyRuleCollectionGroupName,
 		armnetwork.FirewallPolicyRuleCollectionGroup{
@@ -43,51 +43,50 @@ func CreateFirewallPolicyRuleCollectionGroup(ctx context.Context, firewallPolicy
 	)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.FirewallPolicyRuleCollectionGroup, nil
 }
 
 // Gets the specified FirewallPolicyRuleCollectionGroup.
-func GetFirewallPolicyRuleCollectionGroup(ctx context.Context, firewallPolicyName string, firewallPolicyRuleCollectionGroupName string) error {
-	client := getFirewallPolicyRuleCollectionGroupsClient()
-	_, err := client.Get(ctx, config.GroupName(), firewallPolicyName, firewallPolicyRuleCollectionGroupName, nil)
+func GetFirewallPolicyRuleCollectionGroup(ctx context.Context, firewallPolicyName string, firewallPolicyRuleCollectionGroupName string) (*armnetwork.FirewallPolicyRuleCollectionGroup, error) {
+	client := getFirewallPolicyRuleCollectionGroupsClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, firewallPolicyName, firewallPolicyRuleCollectionGroupName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.FirewallPolicyRuleCollectionGroup, nil
 }
 
 // Lists all FirewallPolicyRuleCollectionGroups in a FirewallPolicy resource.
-func ListFirewallPolicyRuleCollectionGroup(ctx context.Context, firewallPolicyName string) error {
-	client := getFirewallPolicyRuleCollectionGroupsClient()
-	pager := client.List(config.GroupName(), firewallPolicyName, nil)
+func ListFirewallPolicyRuleCollectionGroup(ctx context.Context, firewallPolicyName string) ([]*armnetwork.FirewallPolicyRuleCollectionGroup, error) {
+	client := getFirewallPolicyRuleCollectionGroupsClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).GroupName, firewallPolicyName, nil)
 
+	var results []*armnetwork.FirewallPolicyRuleCollectionGroup
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().FirewallPolicyRuleCollectionGroupListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Deletes the specified FirewallPolicyRuleCollectionGroup.
 func DeleteFirewallPolicyRuleCollectionGroup(ctx context.Context, firewallPolicyName string, firewallPolicyRuleCollectionGroupName string) error {
-	client := getFirewallPolicyRuleCollectionGroupsClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), firewallPolicyName, firewallPolicyRuleCollectionGroupName, nil)
+	client := getFirewallPolicyRuleCollectionGroupsClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, firewallPolicyName, firewallPolicyRuleCollectionGroupName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
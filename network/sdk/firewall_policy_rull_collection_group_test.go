@@ -11,20 +11,22 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
 )
 
 func TestFirewallPolicyRullCollectionGroup(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	firewallPolicyName := config.AppendRandomSuffix("firewallpolicy")
 	firewallPolicyRuleCollectionGroupName := config.AppendRandomSuffix("firewallpolicyRuleCollectionGroup")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -82,19 +84,19 @@ func TestFirewallPolicyRullCollectionGroup(t *testing.T) {
 		}
 		]
 		}`
-	err = CreateFirewallPolicyRuleCollectionGroup(ctx, firewallPolicyName, firewallPolicyRuleCollectionGroupName, body)
+	_, err = CreateFirewallPolicyRuleCollectionGroup(ctx, firewallPolicyName, firewallPolicyRuleCollectionGroupName, body)
 	if err != nil {
 		t.Fatalf("failed to create specified firewall policy rule collection group: % +v", err)
 	}
 	t.Logf("created specified firewall policy rule collection group")
 
-	err = GetFirewallPolicyRuleCollectionGroup(ctx, firewallPolicyName, firewallPolicyRuleCollectionGroupName)
+	_, err = GetFirewallPolicyRuleCollectionGroup(ctx, firewallPolicyName, firewallPolicyRuleCollectionGroupName)
 	if err != nil {
 		t.Fatalf("failed to get firewall policy rule collection group: %+v", err)
 	}
 	t.Logf("got firewall policy rule collection group")
 
-	err = ListFirewallPolicyRuleCollectionGroup(ctx, firewallPolicyName)
+	_, err = ListFirewallPolicyRuleCollectionGroup(ctx, firewallPolicyName)
 	if err != nil {
 		t.Fatalf("failed to list firewall policy rule collection group: %+v", err)
 	}
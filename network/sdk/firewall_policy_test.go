@@ -11,19 +11,21 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
 )
 
 func TestFirewallPolicy(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	firewallPolicyName := config.AppendRandomSuffix("firewallpolicy")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 1000*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -46,19 +48,19 @@ func TestFirewallPolicy(t *testing.T) {
 	}
 	t.Logf("created firewall policy")
 
-	err = GetFirewallPolicy(ctx, firewallPolicyName)
+	_, err = GetFirewallPolicy(ctx, firewallPolicyName)
 	if err != nil {
 		t.Fatalf("failed to get firewall policy: %+v", err)
 	}
 	t.Logf("got firewall policy")
 
-	err = ListFirewallPolicy(ctx)
+	_, err = ListFirewallPolicy(ctx)
 	if err != nil {
 		t.Fatalf("failed to list firewall policy: %+v", err)
 	}
 	t.Logf("listed firewall policy")
 
-	err = ListAllFirewallPolicy(ctx)
+	_, err = ListAllFirewallPolicy(ctx)
 	if err != nil {
 		t.Fatalf("failed to list all firewall policy: %+v", err)
 	}
@@ -11,14 +11,16 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
 )
 
 func TestFirewall(t *testing.T) {
+	defer recording.Start(t)()
+
 	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
 	firewallName := config.AppendRandomSuffix("firewall")
 	virtualWanName := config.AppendRandomSuffix("virtualwan")
 	virtualHubName := config.AppendRandomSuffix("virtualhub")
@@ -26,6 +28,7 @@ func TestFirewall(t *testing.T) {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3000*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -112,25 +115,25 @@ func TestFirewall(t *testing.T) {
 	}
 	t.Logf("created firewall")
 
-	err = GetFirewall(ctx, firewallName)
+	_, err = GetFirewall(ctx, firewallName)
 	if err != nil {
 		t.Fatalf("failed to get firewall: %+v", err)
 	}
 	t.Logf("got firewall")
 
-	err = ListFirewall(ctx)
+	_, err = ListFirewall(ctx)
 	if err != nil {
 		t.Fatalf("failed to list firewall: %+v", err)
 	}
 	t.Logf("listed firewall")
 
-	err = ListAllAzureFirewallFqdnTag(ctx)
+	_, err = ListAllAzureFirewallFqdnTag(ctx)
 	if err != nil {
 		t.Fatalf("failed to list all azure firewall fqdn tag: %+v", err)
 	}
 	t.Logf("listed all azure firewall fqdn tag")
 
-	err = ListAllFirewall(ctx)
+	_, err = ListAllFirewall(ctx)
 	if err != nil {
 		t.Fatalf("failed to list all firewall: %+v", err)
 	}
@@ -139,7 +142,7 @@ func TestFirewall(t *testing.T) {
 	tagsObjectParameters := armnetwork.TagsObject{
 		Tags: map[string]*string{"tag1": to.StringPtr("value1"), "tag2": to.StringPtr("value2")},
 	}
-	err = UpdateFirewallTags(ctx, firewallName, tagsObjectParameters)
+	_, err = UpdateFirewallTags(ctx, firewallName, tagsObjectParameters)
 	if err != nil {
 		t.Fatalf("failed to update tags for firewall: %+v", err)
 	}
@@ -11,26 +11,26 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getFlowLogsClient() armnetwork.FlowLogsClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getFlowLogsClient(ctx context.Context) armnetwork.FlowLogsClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewFlowLogsClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewFlowLogsClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Create FlowLogs
-func CreateFlowLog(ctx context.Context, networkWatcherName string, flowLogName string, flowLogParameters armnetwork.FlowLog) error {
-	client := getFlowLogsClient()
+func CreateFlowLog(ctx context.Context, networkWatcherName string, flowLogName string, flowLogParameters armnetwork.FlowLog) (*armnetwork.FlowLog, error) {
+	client := getFlowLogsClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		networkWatcherName,
 		flowLogName,
 		flowLogParameters,
@@ -38,34 +38,34 @@ func CreateFlowLog(ctx context.Context, networkWatcherName string, flowLogName s
 	)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.FlowLog, nil
 }
 
 // Gets the specified flow log in a specified resource group.
-func GetFlowLog(ctx context.Context, networkWatcherName string, flowLogName string) error {
-	client := getFlowLogsClient()
-	_, err := client.Get(ctx, config.GroupName(), networkWatcherName, flowLogName, nil)
+func GetFlowLog(ctx context.Context, networkWatcherName string, flowLogName string) (*armnetwork.FlowLog, error) {
+	client := getFlowLogsClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, networkWatcherName, flowLogName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.FlowLog, nil
 }
 
 // Deletes the specified flow log.
 func DeleteFlowLog(ctx context.Context, networkWatcherName string, flowLogName string) error {
-	client := getFlowLogsClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), networkWatcherName, flowLogName, nil)
+	client := getFlowLogsClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, networkWatcherName, flowLogName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -11,25 +11,26 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	storage "github.com/Azure-Samples/azure-sdk-for-go-samples/storage/sdk"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/armstorage"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
-	"github.com/marstr/randname"
 )
 
 func TestFlowLog(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	flowLogName := config.AppendRandomSuffix("flowlog")
 	networkWatcherName := config.AppendRandomSuffix("networkwatcher")
 	networkSecurityGroupName := config.AppendRandomSuffix("networksecuritygroup")
-	storageAccountName := randname.Prefixed{Prefix: "storageaccount", Acceptable: randname.LowercaseAlphabet, Len: 5}.Generate()
+	storageAccountName := config.AppendRandomLowercaseSuffix("storageaccount")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -37,7 +38,7 @@ func TestFlowLog(t *testing.T) {
 		t.Fatalf("failed to create group: %+v", err)
 	}
 
-	err = CreateNetworkWatcher(ctx, networkWatcherName)
+	_, err = CreateNetworkWatcher(ctx, networkWatcherName)
 	if err != nil {
 		t.Fatalf("failed to create network watcher: % +v", err)
 	}
@@ -76,13 +77,13 @@ func TestFlowLog(t *testing.T) {
 			TargetResourceID: &networkSecurityGroupId,
 		},
 	}
-	err = CreateFlowLog(ctx, networkWatcherName, flowLogName, flowLogParameters)
+	_, err = CreateFlowLog(ctx, networkWatcherName, flowLogName, flowLogParameters)
 	if err != nil {
 		t.Fatalf("failed to create flow log: % +v", err)
 	}
 	t.Logf("created flow log")
 
-	err = GetFlowLog(ctx, networkWatcherName, flowLogName)
+	_, err = GetFlowLog(ctx, networkWatcherName, flowLogName)
 	if err != nil {
 		t.Fatalf("failed to get flow log: %+v", err)
 	}
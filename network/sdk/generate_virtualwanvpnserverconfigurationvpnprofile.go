@@ -11,37 +11,37 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getBeginGeneratevirtualwanvpnserverconfigurationvpnprofilesClient() armnetwork.NetworkManagementClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getBeginGeneratevirtualwanvpnserverconfigurationvpnprofilesClient(ctx context.Context) armnetwork.NetworkManagementClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewNetworkManagementClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewNetworkManagementClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Generates a unique VPN profile for P2S clients for VirtualWan and associated VpnServerConfiguration
 // combination in the specified resource group.
-func Generatevirtualwanvpnserverconfigurationvpnprofile(ctx context.Context, virtualWANName string, vpnClientParams armnetwork.VirtualWanVPNProfileParameters) error {
-	client := getBeginGeneratevirtualwanvpnserverconfigurationvpnprofilesClient()
+func Generatevirtualwanvpnserverconfigurationvpnprofile(ctx context.Context, virtualWANName string, vpnClientParams armnetwork.VirtualWanVPNProfileParameters) (*armnetwork.VPNProfileResponse, error) {
+	client := getBeginGeneratevirtualwanvpnserverconfigurationvpnprofilesClient(ctx)
 	poller, err := client.BeginGeneratevirtualwanvpnserverconfigurationvpnprofile(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		virtualWANName,
 		vpnClientParams,
 		nil)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VPNProfileResponse, nil
 }
@@ -11,26 +11,26 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getHubRouteTablesClient() armnetwork.HubRouteTablesClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getHubRouteTablesClient(ctx context.Context) armnetwork.HubRouteTablesClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewHubRouteTablesClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewHubRouteTablesClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Creates a RouteTable resource if it doesn't exist else updates the existing RouteTable
-func CreateHubRouteTable(ctx context.Context, virtualHubName string, routeTableName string, routeTableParameters armnetwork.HubRouteTable) error {
-	client := getHubRouteTablesClient()
+func CreateHubRouteTable(ctx context.Context, virtualHubName string, routeTableName string, routeTableParameters armnetwork.HubRouteTable) (*armnetwork.HubRouteTable, error) {
+	client := getHubRouteTablesClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		virtualHubName,
 		routeTableName,
 		routeTableParameters,
@@ -38,51 +38,50 @@ func CreateHubRouteTable(ctx context.Context, virtualHubName string, routeTableN
 	)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.HubRouteTable, nil
 }
 
 // Retrieves the details of a RouteTable
-func GetHubRouteTable(ctx context.Context, virtualHubName string, routeTableName string) error {
-	client := getHubRouteTablesClient()
-	_, err := client.Get(ctx, config.GroupName(), virtualHubName, routeTableName, nil)
+func GetHubRouteTable(ctx context.Context, virtualHubName string, routeTableName string) (*armnetwork.HubRouteTable, error) {
+	client := getHubRouteTablesClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, virtualHubName, routeTableName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.HubRouteTable, nil
 }
 
 // Retrieves the details of all RouteTables.
-func ListHubRouteTable(ctx context.Context, virtualHubName string) error {
-	client := getHubRouteTablesClient()
-	pager := client.List(config.GroupName(), virtualHubName, nil)
+func ListHubRouteTable(ctx context.Context, virtualHubName string) ([]*armnetwork.HubRouteTable, error) {
+	client := getHubRouteTablesClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).GroupName, virtualHubName, nil)
 
+	var results []*armnetwork.HubRouteTable
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().ListHubRouteTablesResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Deletes a RouteTable.
 func DeleteHubRouteTable(ctx context.Context, virtualHubName string, routeTableName string) error {
-	client := getHubRouteTablesClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), virtualHubName, routeTableName, nil)
+	client := getHubRouteTablesClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, virtualHubName, routeTableName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
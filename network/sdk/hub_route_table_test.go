@@ -11,15 +11,16 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
 )
 
 func TestHubRouteTable(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	hubRouteTableName := config.AppendRandomSuffix("hubroutetable")
 	firewallName := config.AppendRandomSuffix("firewall")
 	virtualWanName := config.AppendRandomSuffix("virtualwan")
@@ -28,6 +29,7 @@ func TestHubRouteTable(t *testing.T) {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 2000*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -126,19 +128,19 @@ func TestHubRouteTable(t *testing.T) {
 			}},
 		},
 	}
-	err = CreateHubRouteTable(ctx, virtualHubName, hubRouteTableName, routeTableParameters)
+	_, err = CreateHubRouteTable(ctx, virtualHubName, hubRouteTableName, routeTableParameters)
 	if err != nil {
 		t.Fatalf("failed to create hub route table: % +v", err)
 	}
 	t.Logf("created hub route table")
 
-	err = GetHubRouteTable(ctx, virtualHubName, hubRouteTableName)
+	_, err = GetHubRouteTable(ctx, virtualHubName, hubRouteTableName)
 	if err != nil {
 		t.Fatalf("failed to get hub route table: %+v", err)
 	}
 	t.Logf("got hub route table")
 
-	err = ListHubRouteTable(ctx, virtualHubName)
+	_, err = ListHubRouteTable(ctx, virtualHubName)
 	if err != nil {
 		t.Fatalf("failed to list hub route table: %+v", err)
 	}
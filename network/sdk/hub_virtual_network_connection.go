@@ -10,33 +10,31 @@ import (
 	"log"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getHubVirtualNetworkConnectionsClient() armnetwork.HubVirtualNetworkConnectionsClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getHubVirtualNetworkConnectionsClient(ctx context.Context) armnetwork.HubVirtualNetworkConnectionsClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewHubVirtualNetworkConnectionsClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewHubVirtualNetworkConnectionsClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Retrieves the details of all HubVirtualNetworkConnections.
-func ListHubVirtualNetworkConnection(ctx context.Context, virtualHubName string) error {
-	client := getHubVirtualNetworkConnectionsClient()
-	pager := client.List(config.GroupName(), virtualHubName, nil)
+func ListHubVirtualNetworkConnection(ctx context.Context, virtualHubName string) ([]*armnetwork.HubVirtualNetworkConnection, error) {
+	client := getHubVirtualNetworkConnectionsClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).GroupName, virtualHubName, nil)
 
+	var results []*armnetwork.HubVirtualNetworkConnection
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().ListHubVirtualNetworkConnectionsResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
@@ -11,26 +11,26 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getInboundNatRulesClient() armnetwork.InboundNatRulesClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getInboundNatRulesClient(ctx context.Context) armnetwork.InboundNatRulesClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewInboundNatRulesClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewInboundNatRulesClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Creates or updates a load balancer inbound nat rule.
-func CreateInboundNatRule(ctx context.Context, loadBalancerName string, inboundNatRuleName string, inboundNatRuleParameters armnetwork.InboundNatRule) error {
-	client := getInboundNatRulesClient()
+func CreateInboundNatRule(ctx context.Context, loadBalancerName string, inboundNatRuleName string, inboundNatRuleParameters armnetwork.InboundNatRule) (*armnetwork.InboundNatRule, error) {
+	client := getInboundNatRulesClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		loadBalancerName,
 		inboundNatRuleName,
 		inboundNatRuleParameters,
@@ -38,51 +38,50 @@ func CreateInboundNatRule(ctx context.Context, loadBalancerName string, inboundN
 	)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.InboundNatRule, nil
 }
 
 // Gets the specified load balancer inbound nat rule.
-func GetInboundNatRule(ctx context.Context, loadBalancerName string, inboundNatRuleName string) error {
-	client := getInboundNatRulesClient()
-	_, err := client.Get(ctx, config.GroupName(), loadBalancerName, inboundNatRuleName, nil)
+func GetInboundNatRule(ctx context.Context, loadBalancerName string, inboundNatRuleName string) (*armnetwork.InboundNatRule, error) {
+	client := getInboundNatRulesClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, loadBalancerName, inboundNatRuleName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.InboundNatRule, nil
 }
 
 // Gets all the inbound nat rules in a load balancer.
-func ListInboundNatRule(ctx context.Context, loadBalancerName string) error {
-	client := getInboundNatRulesClient()
-	pager := client.List(config.GroupName(), loadBalancerName, nil)
+func ListInboundNatRule(ctx context.Context, loadBalancerName string) ([]*armnetwork.InboundNatRule, error) {
+	client := getInboundNatRulesClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).GroupName, loadBalancerName, nil)
 
+	var results []*armnetwork.InboundNatRule
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().InboundNatRuleListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Deletes the specified load balancer inbound nat rule.
 func DeleteInboundNatRule(ctx context.Context, loadBalancerName string, inboundNatRuleName string) error {
-	client := getInboundNatRulesClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), loadBalancerName, inboundNatRuleName, nil)
+	client := getInboundNatRulesClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, loadBalancerName, inboundNatRuleName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -11,15 +11,17 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/resourceid"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
 )
 
 func TestInboundNatRule(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	loadBalancerName := config.AppendRandomSuffix("loadbalancer")
 	publicIpAddressName := config.AppendRandomSuffix("pipaddress")
 	inboundNatRuleName := config.AppendRandomSuffix("inboundnatrule")
@@ -31,6 +33,7 @@ func TestInboundNatRule(t *testing.T) {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -57,7 +60,7 @@ func TestInboundNatRule(t *testing.T) {
 		t.Fatalf("failed to create public ip address: %+v", err)
 	}
 
-	loadBalancerUrl := "/subscriptions/" + config.SubscriptionID() + "/resourceGroups/" + config.GroupName() + "/providers/Microsoft.Network/loadBalancers/" + loadBalancerName
+	loadBalancerID := resourceid.ResourceGroup(config.SubscriptionID(), groupName).Resource("Microsoft.Network", "loadBalancers", loadBalancerName)
 	loadBalancerParameters := armnetwork.LoadBalancer{
 		Resource: armnetwork.Resource{
 			Location: to.StringPtr(config.Location()),
@@ -85,20 +88,20 @@ func TestInboundNatRule(t *testing.T) {
 					Name: &loadBalancingRuleName,
 					Properties: &armnetwork.LoadBalancingRulePropertiesFormat{
 						BackendAddressPool: &armnetwork.SubResource{
-							ID: to.StringPtr(loadBalancerUrl + "/backendAddressPools/" + backendAddressPoolName),
+							ID: to.StringPtr(loadBalancerID.Child("backendAddressPools", backendAddressPoolName).String()),
 						},
 						BackendPort:         to.Int32Ptr(80),
 						DisableOutboundSnat: to.BoolPtr(true),
 						EnableFloatingIP:    to.BoolPtr(true),
 						EnableTCPReset:      new(bool),
 						FrontendIPConfiguration: &armnetwork.SubResource{
-							ID: to.StringPtr(loadBalancerUrl + "/frontendIPConfigurations/" + frontendIpConfigurationName),
+							ID: to.StringPtr(loadBalancerID.Child("frontendIPConfigurations", frontendIpConfigurationName).String()),
 						},
 						FrontendPort:         to.Int32Ptr(80),
 						IdleTimeoutInMinutes: to.Int32Ptr(15),
 						LoadDistribution:     armnetwork.LoadDistributionDefault.ToPtr(),
 						Probe: &armnetwork.SubResource{
-							ID: to.StringPtr(loadBalancerUrl + "/probes/" + probeName),
+							ID: to.StringPtr(loadBalancerID.Child("probes", probeName).String()),
 						},
 						Protocol: armnetwork.TransportProtocolTCP.ToPtr(),
 					},
@@ -109,11 +112,11 @@ func TestInboundNatRule(t *testing.T) {
 					Name: &outBoundRuleName,
 					Properties: &armnetwork.OutboundRulePropertiesFormat{
 						BackendAddressPool: &armnetwork.SubResource{
-							ID: to.StringPtr(loadBalancerUrl + "/backendAddressPools/" + backendAddressPoolName),
+							ID: to.StringPtr(loadBalancerID.Child("backendAddressPools", backendAddressPoolName).String()),
 						},
 						FrontendIPConfigurations: []*armnetwork.SubResource{
 							{
-								ID: to.StringPtr(loadBalancerUrl + "/frontendIPConfigurations/" + frontendIpConfigurationName),
+								ID: to.StringPtr(loadBalancerID.Child("frontendIPConfigurations", frontendIpConfigurationName).String()),
 							},
 						},
 						Protocol: armnetwork.LoadBalancerOutboundRuleProtocolAll.ToPtr(),
@@ -157,18 +160,18 @@ func TestInboundNatRule(t *testing.T) {
 			Protocol:             armnetwork.TransportProtocolTCP.ToPtr(),
 		},
 	}
-	err = CreateInboundNatRule(ctx, loadBalancerName, inboundNatRuleName, inboundNatRuleParameters)
+	_, err = CreateInboundNatRule(ctx, loadBalancerName, inboundNatRuleName, inboundNatRuleParameters)
 	if err != nil {
 		t.Fatalf("failed to get load balancer inbound nat rule: %+v", err)
 	}
 
-	err = GetInboundNatRule(ctx, loadBalancerName, inboundNatRuleName)
+	_, err = GetInboundNatRule(ctx, loadBalancerName, inboundNatRuleName)
 	if err != nil {
 		t.Fatalf("failed to list the specified load balancer inbound nat rule: %+v", err)
 	}
 	t.Logf("listed the specified load balancer inbound nat rule")
 
-	err = ListInboundNatRule(ctx, loadBalancerName)
+	_, err = ListInboundNatRule(ctx, loadBalancerName)
 	if err != nil {
 		t.Fatalf("failed to list all the inbound nat rules in a load balancer: %+v", err)
 	}
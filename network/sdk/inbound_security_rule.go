@@ -11,26 +11,26 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getInboundSecurityRulesClient() armnetwork.InboundSecurityRuleClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getInboundSecurityRulesClient(ctx context.Context) armnetwork.InboundSecurityRuleClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewInboundSecurityRuleClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewInboundSecurityRuleClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Creates or updates the specified Network Virtual Appliance Inbound Security Rules
-func CreateInboundSecurityRule(ctx context.Context, networkVirtualApplianceName string, ruleCollectionName string, inboundSecurityRuleParameters armnetwork.InboundSecurityRule) error {
-	client := getInboundSecurityRulesClient()
+func CreateInboundSecurityRule(ctx context.Context, networkVirtualApplianceName string, ruleCollectionName string, inboundSecurityRuleParameters armnetwork.InboundSecurityRule) (*armnetwork.InboundSecurityRule, error) {
+	client := getInboundSecurityRulesClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		networkVirtualApplianceName,
 		ruleCollectionName,
 		inboundSecurityRuleParameters,
@@ -38,12 +38,12 @@ func CreateInboundSecurityRule(ctx context.Context, networkVirtualApplianceName
 	)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.InboundSecurityRule, nil
 }
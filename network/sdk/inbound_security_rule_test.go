@@ -11,15 +11,16 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
 )
 
 func TestInboundSecurityRule(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	networkVirtualApplianceName := config.AppendRandomSuffix("networkvirtualappliance")
 	ruleCollectionName := config.AppendRandomSuffix("rulecollection")
 
@@ -28,6 +29,7 @@ func TestInboundSecurityRule(t *testing.T) {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 2000*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -87,7 +89,7 @@ func TestInboundSecurityRule(t *testing.T) {
 		},
 	}
 
-	err = CreateNetworkVirtualAppliance(ctx, networkVirtualApplianceName, parametersNetworkVirtualAppliance)
+	_, err = CreateNetworkVirtualAppliance(ctx, networkVirtualApplianceName, parametersNetworkVirtualAppliance)
 	if err != nil {
 		t.Fatalf("failed to create network virtual appliance: % +v", err)
 	}
@@ -101,7 +103,7 @@ func TestInboundSecurityRule(t *testing.T) {
 			}},
 		},
 	}
-	err = CreateInboundSecurityRule(ctx, networkVirtualApplianceName, ruleCollectionName, inboundSecurityRuleParameters)
+	_, err = CreateInboundSecurityRule(ctx, networkVirtualApplianceName, ruleCollectionName, inboundSecurityRuleParameters)
 	if err != nil {
 		t.Fatalf("failed to create inbound security rule: % +v", err)
 	}
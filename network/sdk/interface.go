@@ -11,26 +11,26 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getNetworkInterfacesClient() armnetwork.NetworkInterfacesClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getNetworkInterfacesClient(ctx context.Context) armnetwork.NetworkInterfacesClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewNetworkInterfacesClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewNetworkInterfacesClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Create NetworkInterfaces
 func CreateNetworkInterface(ctx context.Context, networkInterfaceName string, networkInterfaceParameters armnetwork.NetworkInterface) (string, *armnetwork.NetworkInterfaceIPConfigurationPropertiesFormat, error) {
-	client := getNetworkInterfacesClient()
+	client := getNetworkInterfacesClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		networkInterfaceName,
 		networkInterfaceParameters,
 		nil,
@@ -40,7 +40,7 @@ func CreateNetworkInterface(ctx context.Context, networkInterfaceName string, ne
 		return "", nil, err
 	}
 
-	resp, err := poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return "", nil, err
 	}
@@ -55,72 +55,71 @@ func CreateNetworkInterface(ctx context.Context, networkInterfaceName string, ne
 }
 
 // Gets the specified network interface in a specified resource group.
-func GetNetworkInterface(ctx context.Context, networkInterfaceName string) error {
-	client := getNetworkInterfacesClient()
-	_, err := client.Get(ctx, config.GroupName(), networkInterfaceName, nil)
+func GetNetworkInterface(ctx context.Context, networkInterfaceName string) (*armnetwork.NetworkInterface, error) {
+	client := getNetworkInterfacesClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, networkInterfaceName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.NetworkInterface, nil
 }
 
 // Gets all the network interface in a subscription.
-func ListNetworkInterface(ctx context.Context) error {
-	client := getNetworkInterfacesClient()
-	pager := client.List(config.GroupName(), nil)
+func ListNetworkInterface(ctx context.Context) ([]*armnetwork.NetworkInterface, error) {
+	client := getNetworkInterfacesClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).GroupName, nil)
 
+	var results []*armnetwork.NetworkInterface
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().NetworkInterfaceListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Gets all the network interface in a subscription.
-func ListAllNetworkInterface(ctx context.Context) error {
-	client := getNetworkInterfacesClient()
+func ListAllNetworkInterface(ctx context.Context) ([]*armnetwork.NetworkInterface, error) {
+	client := getNetworkInterfacesClient(ctx)
 	pager := client.ListAll(nil)
+
+	var results []*armnetwork.NetworkInterface
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().NetworkInterfaceListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Updates network interface tags.
-func UpdateNetworkInterfaceTags(ctx context.Context, networkInterfaceName string, tagsObjectParameters armnetwork.TagsObject) error {
-	client := getNetworkInterfacesClient()
-	_, err := client.UpdateTags(
+func UpdateNetworkInterfaceTags(ctx context.Context, networkInterfaceName string, tagsObjectParameters armnetwork.TagsObject) (*armnetwork.NetworkInterface, error) {
+	client := getNetworkInterfacesClient(ctx)
+	resp, err := client.UpdateTags(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		networkInterfaceName,
 		tagsObjectParameters,
 		nil,
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.NetworkInterface, nil
 }
 
 // Deletes the specified network interface.
 func DeleteNetworkInterface(ctx context.Context, networkInterfaceName string) error {
-	client := getNetworkInterfacesClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), networkInterfaceName, nil)
+	client := getNetworkInterfacesClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, networkInterfaceName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -128,83 +127,81 @@ func DeleteNetworkInterface(ctx context.Context, networkInterfaceName string) er
 }
 
 // Gets all route tables applied to a network interface
-func BeginGetEffectiveRouteTable(ctx context.Context, networkInterfaceName string) error {
-	client := getNetworkInterfacesClient()
-	poller, err := client.BeginGetEffectiveRouteTable(ctx, config.GroupName(), networkInterfaceName, nil)
+func BeginGetEffectiveRouteTable(ctx context.Context, networkInterfaceName string) (*armnetwork.EffectiveRouteListResult, error) {
+	client := getNetworkInterfacesClient(ctx)
+	poller, err := client.BeginGetEffectiveRouteTable(ctx, config.ScopeFrom(ctx).GroupName, networkInterfaceName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.EffectiveRouteListResult, nil
 }
 
 // Gets all network security groups applied to a network interface.
-func BeginListEffectiveRouteTable(ctx context.Context, networkInterfaceName string) error {
-	client := getNetworkInterfacesClient()
-	poller, err := client.BeginListEffectiveNetworkSecurityGroups(ctx, config.GroupName(), networkInterfaceName, nil)
+func BeginListEffectiveRouteTable(ctx context.Context, networkInterfaceName string) (*armnetwork.EffectiveNetworkSecurityGroupListResult, error) {
+	client := getNetworkInterfacesClient(ctx)
+	poller, err := client.BeginListEffectiveNetworkSecurityGroups(ctx, config.ScopeFrom(ctx).GroupName, networkInterfaceName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.EffectiveNetworkSecurityGroupListResult, nil
 }
 
-func getNetworkInterfaceIPConfigurationsClient() armnetwork.NetworkInterfaceIPConfigurationsClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getNetworkInterfaceIPConfigurationsClient(ctx context.Context) armnetwork.NetworkInterfaceIPConfigurationsClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewNetworkInterfaceIPConfigurationsClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewNetworkInterfaceIPConfigurationsClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Get all ip configurations in a network interface.
-func ListNetworkInterfaceIpConfiguration(ctx context.Context, networkInterfaceName string) error {
-	client := getNetworkInterfaceIPConfigurationsClient()
-	pager := client.List(config.GroupName(), networkInterfaceName, nil)
+func ListNetworkInterfaceIpConfiguration(ctx context.Context, networkInterfaceName string) ([]*armnetwork.NetworkInterfaceIPConfiguration, error) {
+	client := getNetworkInterfaceIPConfigurationsClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).GroupName, networkInterfaceName, nil)
 
+	var results []*armnetwork.NetworkInterfaceIPConfiguration
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().NetworkInterfaceIPConfigurationListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
-func getNetworkInterfaceLoadBalancersClient() armnetwork.NetworkInterfaceLoadBalancersClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getNetworkInterfaceLoadBalancersClient(ctx context.Context) armnetwork.NetworkInterfaceLoadBalancersClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewNetworkInterfaceLoadBalancersClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewNetworkInterfaceLoadBalancersClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // List all load balancers in a network interface.
-func ListNetworkInterfaceLoadBalancer(ctx context.Context, networkInterfaceName string) error {
-	client := getNetworkInterfaceLoadBalancersClient()
-	pager := client.List(config.GroupName(), networkInterfaceName, nil)
+func ListNetworkInterfaceLoadBalancer(ctx context.Context, networkInterfaceName string) ([]*armnetwork.LoadBalancer, error) {
+	client := getNetworkInterfaceLoadBalancersClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).GroupName, networkInterfaceName, nil)
 
+	var results []*armnetwork.LoadBalancer
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().NetworkInterfaceLoadBalancerListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
@@ -12,6 +12,7 @@ import (
 
 	compute "github.com/Azure-Samples/azure-sdk-for-go-samples/compute/sdk"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/compute/armcompute"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
@@ -19,9 +20,9 @@ import (
 )
 
 func TestInterface(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	networkInterfaceName := config.AppendRandomSuffix("networkinterface")
 	virtualNetworkName := config.AppendRandomSuffix("virtualnetwork")
 	publicIpAddressName := config.AppendRandomSuffix("pipaddress")
@@ -31,6 +32,7 @@ func TestInterface(t *testing.T) {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 1000*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -165,43 +167,43 @@ func TestInterface(t *testing.T) {
 		t.Fatalf("failed to create virtual machine: % +v", err)
 	}
 
-	err = ListNetworkInterfaceIpConfiguration(ctx, networkInterfaceName)
+	_, err = ListNetworkInterfaceIpConfiguration(ctx, networkInterfaceName)
 	if err != nil {
 		t.Fatalf("failed to list network interface ip configuration: %+v", err)
 	}
 	t.Logf("listed network interface ip configuration")
 
-	err = ListNetworkInterfaceLoadBalancer(ctx, networkInterfaceName)
+	_, err = ListNetworkInterfaceLoadBalancer(ctx, networkInterfaceName)
 	if err != nil {
 		t.Fatalf("failed to list network interface load balancer: %+v", err)
 	}
 	t.Logf("listed network interface load balancer")
 
-	err = GetNetworkInterface(ctx, networkInterfaceName)
+	_, err = GetNetworkInterface(ctx, networkInterfaceName)
 	if err != nil {
 		t.Fatalf("failed to get network interface: %+v", err)
 	}
 	t.Logf("got network interface")
 
-	err = ListNetworkInterface(ctx)
+	_, err = ListNetworkInterface(ctx)
 	if err != nil {
 		t.Fatalf("failed to list network interface: %+v", err)
 	}
 	t.Logf("listed network interface")
 
-	err = ListAllNetworkInterface(ctx)
+	_, err = ListAllNetworkInterface(ctx)
 	if err != nil {
 		t.Fatalf("failed to list all network interface: %+v", err)
 	}
 	t.Logf("listed all network interface")
 
-	err = BeginListEffectiveRouteTable(ctx, networkInterfaceName)
+	_, err = BeginListEffectiveRouteTable(ctx, networkInterfaceName)
 	if err != nil {
 		t.Fatalf("failed to list all network security groups applied to a network interface: %+v", err)
 	}
 	t.Logf("listed all network security groups applied to a network interface")
 
-	err = BeginGetEffectiveRouteTable(ctx, networkInterfaceName)
+	_, err = BeginGetEffectiveRouteTable(ctx, networkInterfaceName)
 	if err != nil {
 		t.Fatalf("failed to get all route tables applied to a network interface: %+v", err)
 	}
@@ -210,7 +212,7 @@ func TestInterface(t *testing.T) {
 	tagsObjectParameters := armnetwork.TagsObject{
 		Tags: map[string]*string{"tag1": to.StringPtr("value1"), "tag2": to.StringPtr("value2")},
 	}
-	err = UpdateNetworkInterfaceTags(ctx, networkInterfaceName, tagsObjectParameters)
+	_, err = UpdateNetworkInterfaceTags(ctx, networkInterfaceName, tagsObjectParameters)
 	if err != nil {
 		t.Fatalf("failed to update tags for network interface: %+v", err)
 	}
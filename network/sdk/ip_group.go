@@ -11,93 +11,92 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getIPGroupClient() armnetwork.IPGroupsClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getIPGroupClient(ctx context.Context) armnetwork.IPGroupsClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewIPGroupsClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewIPGroupsClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Creates or updates an ipGroups in a specified resource group.
-func CreateIPGroup(ctx context.Context, ipGroupName string, ipGroupParameters armnetwork.IPGroup) error {
-	client := getIPGroupClient()
+func CreateIPGroup(ctx context.Context, ipGroupName string, ipGroupParameters armnetwork.IPGroup) (*armnetwork.IPGroup, error) {
+	client := getIPGroupClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		ipGroupName,
 		ipGroupParameters,
 		nil,
 	)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.IPGroup, nil
 }
 
 // Gets the specified ipGroups.
-func GetIPGroup(ctx context.Context, ipGroupName string) error {
-	client := getIPGroupClient()
-	_, err := client.Get(ctx, config.GroupName(), ipGroupName, nil)
+func GetIPGroup(ctx context.Context, ipGroupName string) (*armnetwork.IPGroup, error) {
+	client := getIPGroupClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, ipGroupName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.IPGroup, nil
 }
 
 // Gets all IpGroups in a subscription.
-func ListIPGroup(ctx context.Context) error {
-	client := getIPGroupClient()
+func ListIPGroup(ctx context.Context) ([]*armnetwork.IPGroup, error) {
+	client := getIPGroupClient(ctx)
 	pager := client.List(nil)
 
+	var results []*armnetwork.IPGroup
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().IPGroupListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Gets all IpGroups in a resource group.
-func ListIPGroupByResourceGroup(ctx context.Context) error {
-	client := getIPGroupClient()
-	pager := client.ListByResourceGroup(config.GroupName(), nil)
+func ListIPGroupByResourceGroup(ctx context.Context) ([]*armnetwork.IPGroup, error) {
+	client := getIPGroupClient(ctx)
+	pager := client.ListByResourceGroup(config.ScopeFrom(ctx).GroupName, nil)
+
+	var results []*armnetwork.IPGroup
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().IPGroupListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Deletes the specified ipGroups.
 func DeleteIPGroup(ctx context.Context, ipGroupName string) error {
-	client := getIPGroupClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), ipGroupName, nil)
+	client := getIPGroupClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, ipGroupName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -11,19 +11,21 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
 )
 
 func TestIPGroup(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	ipGroupName := config.AppendRandomSuffix("ipgroup")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -40,25 +42,25 @@ func TestIPGroup(t *testing.T) {
 			IPAddresses: []*string{to.StringPtr("13.64.39.16/32"), to.StringPtr("40.74.146.80/31"), to.StringPtr("40.74.147.32/28")},
 		},
 	}
-	err = CreateIPGroup(ctx, ipGroupName, ipGroupParameters)
+	_, err = CreateIPGroup(ctx, ipGroupName, ipGroupParameters)
 	if err != nil {
 		t.Fatalf("failed to create ip group: %+v", err)
 	}
 	t.Logf("created ip group")
 
-	err = GetIPGroup(ctx, ipGroupName)
+	_, err = GetIPGroup(ctx, ipGroupName)
 	if err != nil {
 		t.Fatalf("failed to get ip group: %+v", err)
 	}
 	t.Logf("got ip group")
 
-	err = ListIPGroup(ctx)
+	_, err = ListIPGroup(ctx)
 	if err != nil {
 		t.Fatalf("failed to list ip group: %+v", err)
 	}
 	t.Logf("listed ip group")
 
-	err = ListIPGroupByResourceGroup(ctx)
+	_, err = ListIPGroupByResourceGroup(ctx)
 	if err != nil {
 		t.Fatalf("failed to list ip group by resource group: %+v", err)
 	}
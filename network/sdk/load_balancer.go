@@ -11,26 +11,26 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getLoadBalancersClient() armnetwork.LoadBalancersClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getLoadBalancersClient(ctx context.Context) armnetwork.LoadBalancersClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewLoadBalancersClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewLoadBalancersClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Create LoadBalancers
 func CreateLoadBalancer(ctx context.Context, loadBalancerName string, loadBalancerParameters armnetwork.LoadBalancer) (string, error) {
-	client := getLoadBalancersClient()
+	client := getLoadBalancersClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		loadBalancerName,
 		loadBalancerParameters,
 		nil,
@@ -40,7 +40,7 @@ func CreateLoadBalancer(ctx context.Context, loadBalancerName string, loadBalanc
 		return "", err
 	}
 
-	resp, err := poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return "", err
 	}
@@ -49,280 +49,273 @@ func CreateLoadBalancer(ctx context.Context, loadBalancerName string, loadBalanc
 }
 
 // Gets the specified load balancer in a specified resource group.
-func GetLoadBalancer(ctx context.Context, loadBalancerName string) error {
-	client := getLoadBalancersClient()
-	_, err := client.Get(ctx, config.GroupName(), loadBalancerName, nil)
+func GetLoadBalancer(ctx context.Context, loadBalancerName string) (*armnetwork.LoadBalancer, error) {
+	client := getLoadBalancersClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, loadBalancerName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.LoadBalancer, nil
 }
 
 // Gets all the load balancers in a resource group
-func ListLoadBalancer(ctx context.Context) error {
-	client := getLoadBalancersClient()
-	pager := client.List(config.GroupName(), nil)
+func ListLoadBalancer(ctx context.Context) ([]*armnetwork.LoadBalancer, error) {
+	client := getLoadBalancersClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).GroupName, nil)
 
+	var results []*armnetwork.LoadBalancer
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().LoadBalancerListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Gets all the load balancer in a subscription.
-func ListAllLoadBalancer(ctx context.Context) error {
-	client := getLoadBalancersClient()
+func ListAllLoadBalancer(ctx context.Context) ([]*armnetwork.LoadBalancer, error) {
+	client := getLoadBalancersClient(ctx)
 	pager := client.ListAll(nil)
+
+	var results []*armnetwork.LoadBalancer
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().LoadBalancerListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Updates a load balancer tags.
-func UpdateLoadBalancerTags(ctx context.Context, loadBalancerName string, tagsObjectParameters armnetwork.TagsObject) error {
-	client := getLoadBalancersClient()
-	_, err := client.UpdateTags(
+func UpdateLoadBalancerTags(ctx context.Context, loadBalancerName string, tagsObjectParameters armnetwork.TagsObject) (*armnetwork.LoadBalancer, error) {
+	client := getLoadBalancersClient(ctx)
+	resp, err := client.UpdateTags(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		loadBalancerName,
 		tagsObjectParameters,
 		nil,
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.LoadBalancer, nil
 }
 
 // Deletes the specified load balancer.
 func DeleteLoadBalancer(ctx context.Context, loadBalancerName string) error {
-	client := getLoadBalancersClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), loadBalancerName, nil)
+	client := getLoadBalancersClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, loadBalancerName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func getLoadBalancerFrontendIPConfigurationsClient() armnetwork.LoadBalancerFrontendIPConfigurationsClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getLoadBalancerFrontendIPConfigurationsClient(ctx context.Context) armnetwork.LoadBalancerFrontendIPConfigurationsClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewLoadBalancerFrontendIPConfigurationsClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewLoadBalancerFrontendIPConfigurationsClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Gets load balancer frontend IP configuration.
-func GetLoadBalancerFrontendIPConfiguration(ctx context.Context, loadBalancerName string, loadBalancerFrontendIPConfigurationName string) error {
-	client := getLoadBalancerFrontendIPConfigurationsClient()
-	_, err := client.Get(ctx, config.GroupName(), loadBalancerName, loadBalancerFrontendIPConfigurationName, nil)
+func GetLoadBalancerFrontendIPConfiguration(ctx context.Context, loadBalancerName string, loadBalancerFrontendIPConfigurationName string) (*armnetwork.FrontendIPConfiguration, error) {
+	client := getLoadBalancerFrontendIPConfigurationsClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, loadBalancerName, loadBalancerFrontendIPConfigurationName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.FrontendIPConfiguration, nil
 }
 
 // Gets all the load balancer frontend IP configurations.
-func ListLoadBalancerFrontendIPConfiguration(ctx context.Context, loadBalancerName string) error {
-	client := getLoadBalancerFrontendIPConfigurationsClient()
-	pager := client.List(config.GroupName(), loadBalancerName, nil)
+func ListLoadBalancerFrontendIPConfiguration(ctx context.Context, loadBalancerName string) ([]*armnetwork.FrontendIPConfiguration, error) {
+	client := getLoadBalancerFrontendIPConfigurationsClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).GroupName, loadBalancerName, nil)
 
+	var results []*armnetwork.FrontendIPConfiguration
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().LoadBalancerFrontendIPConfigurationListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
-func getLoadBalancerBackendAddressPoolsClient() armnetwork.LoadBalancerBackendAddressPoolsClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getLoadBalancerBackendAddressPoolsClient(ctx context.Context) armnetwork.LoadBalancerBackendAddressPoolsClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewLoadBalancerBackendAddressPoolsClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewLoadBalancerBackendAddressPoolsClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Gets load balancer backend address pool.
-func GetLoadBalancerBackendAddressPool(ctx context.Context, loadBalancerBackendAddressPoolName string, backendAddressPoolName string) error {
-	client := getLoadBalancerBackendAddressPoolsClient()
-	_, err := client.Get(ctx, config.GroupName(), loadBalancerBackendAddressPoolName, backendAddressPoolName, nil)
+func GetLoadBalancerBackendAddressPool(ctx context.Context, loadBalancerBackendAddressPoolName string, backendAddressPoolName string) (*armnetwork.BackendAddressPool, error) {
+	client := getLoadBalancerBackendAddressPoolsClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, loadBalancerBackendAddressPoolName, backendAddressPoolName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.BackendAddressPool, nil
 }
 
 // Gets all the load balancer backed address pools.
-func ListLoadBalancerBackendAddressPool(ctx context.Context, loadBalancerName string) error {
-	client := getLoadBalancerBackendAddressPoolsClient()
-	pager := client.List(config.GroupName(), loadBalancerName, nil)
+func ListLoadBalancerBackendAddressPool(ctx context.Context, loadBalancerName string) ([]*armnetwork.BackendAddressPool, error) {
+	client := getLoadBalancerBackendAddressPoolsClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).GroupName, loadBalancerName, nil)
 
+	var results []*armnetwork.BackendAddressPool
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().LoadBalancerBackendAddressPoolListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
-func getLoadBalancerLoadBalancingRulesClient() armnetwork.LoadBalancerLoadBalancingRulesClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getLoadBalancerLoadBalancingRulesClient(ctx context.Context) armnetwork.LoadBalancerLoadBalancingRulesClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewLoadBalancerLoadBalancingRulesClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewLoadBalancerLoadBalancingRulesClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Gets the specified load balancer load balancing rule.
-func GetLoadBalancerLoadBalancingRule(ctx context.Context, loadBalancerName string, loadBalancingRuleName string) error {
-	client := getLoadBalancerLoadBalancingRulesClient()
-	_, err := client.Get(ctx, config.GroupName(), loadBalancerName, loadBalancingRuleName, nil)
+func GetLoadBalancerLoadBalancingRule(ctx context.Context, loadBalancerName string, loadBalancingRuleName string) (*armnetwork.LoadBalancingRule, error) {
+	client := getLoadBalancerLoadBalancingRulesClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, loadBalancerName, loadBalancingRuleName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.LoadBalancingRule, nil
 }
 
 // Gets all the load balancing rules in a load balancer.
-func ListLoadBalancerLoadBalancingRule(ctx context.Context, loadBalancerName string) error {
-	client := getLoadBalancerLoadBalancingRulesClient()
-	pager := client.List(config.GroupName(), loadBalancerName, nil)
+func ListLoadBalancerLoadBalancingRule(ctx context.Context, loadBalancerName string) ([]*armnetwork.LoadBalancingRule, error) {
+	client := getLoadBalancerLoadBalancingRulesClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).GroupName, loadBalancerName, nil)
 
+	var results []*armnetwork.LoadBalancingRule
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().LoadBalancerLoadBalancingRuleListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
-func getLoadBalancerOutboundRulesClient() armnetwork.LoadBalancerOutboundRulesClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getLoadBalancerOutboundRulesClient(ctx context.Context) armnetwork.LoadBalancerOutboundRulesClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewLoadBalancerOutboundRulesClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewLoadBalancerOutboundRulesClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Gets the specified load balancer outbound rule.
-func GetLoadBalancerOutboundRule(ctx context.Context, loadBalancerName string, outBoundRuleName string) error {
-	client := getLoadBalancerOutboundRulesClient()
-	_, err := client.Get(ctx, config.GroupName(), loadBalancerName, outBoundRuleName, nil)
+func GetLoadBalancerOutboundRule(ctx context.Context, loadBalancerName string, outBoundRuleName string) (*armnetwork.OutboundRule, error) {
+	client := getLoadBalancerOutboundRulesClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, loadBalancerName, outBoundRuleName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.OutboundRule, nil
 }
 
 // Gets all the outbound rules in a load balancer.
-func ListLoadBalancerOutboundRule(ctx context.Context, loadBalancerName string) error {
-	client := getLoadBalancerOutboundRulesClient()
-	pager := client.List(config.GroupName(), loadBalancerName, nil)
+func ListLoadBalancerOutboundRule(ctx context.Context, loadBalancerName string) ([]*armnetwork.OutboundRule, error) {
+	client := getLoadBalancerOutboundRulesClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).GroupName, loadBalancerName, nil)
 
+	var results []*armnetwork.OutboundRule
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().LoadBalancerOutboundRuleListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
-func getLoadBalancerProbesClient() armnetwork.LoadBalancerProbesClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getLoadBalancerProbesClient(ctx context.Context) armnetwork.LoadBalancerProbesClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewLoadBalancerProbesClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewLoadBalancerProbesClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Gets load balancer probe.
-func GetLoadBalancerProbe(ctx context.Context, loadBalancerName string, probeName string) error {
-	client := getLoadBalancerProbesClient()
-	_, err := client.Get(ctx, config.GroupName(), loadBalancerName, probeName, nil)
+func GetLoadBalancerProbe(ctx context.Context, loadBalancerName string, probeName string) (*armnetwork.Probe, error) {
+	client := getLoadBalancerProbesClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, loadBalancerName, probeName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.Probe, nil
 }
 
 // Gets all the load balancer probes.
-func ListLoadBalancerProbe(ctx context.Context, loadBalancerName string) error {
-	client := getLoadBalancerProbesClient()
-	pager := client.List(config.GroupName(), loadBalancerName, nil)
+func ListLoadBalancerProbe(ctx context.Context, loadBalancerName string) ([]*armnetwork.Probe, error) {
+	client := getLoadBalancerProbesClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).GroupName, loadBalancerName, nil)
 
+	var results []*armnetwork.Probe
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().LoadBalancerProbeListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
-func getLoadBalancerNetworkInterfacesClient() armnetwork.LoadBalancerNetworkInterfacesClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getLoadBalancerNetworkInterfacesClient(ctx context.Context) armnetwork.LoadBalancerNetworkInterfacesClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewLoadBalancerNetworkInterfacesClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewLoadBalancerNetworkInterfacesClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Gets associated load balancer network interfaces.
-func ListLoadBalancerNetworkInterface(ctx context.Context, loadBalancerName string) error {
-	client := getLoadBalancerNetworkInterfacesClient()
-	pager := client.List(config.GroupName(), loadBalancerName, nil)
+func ListLoadBalancerNetworkInterface(ctx context.Context, loadBalancerName string) ([]*armnetwork.NetworkInterface, error) {
+	client := getLoadBalancerNetworkInterfacesClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).GroupName, loadBalancerName, nil)
 
+	var results []*armnetwork.NetworkInterface
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().NetworkInterfaceListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
@@ -11,15 +11,17 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/resourceid"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
 )
 
 func TestLoadBalancer(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	loadBalancerName := config.AppendRandomSuffix("loadbalancer")
 	publicIpAddressName := config.AppendRandomSuffix("pipaddress")
 	inboundNatRuleName := config.AppendRandomSuffix("inboundnatrule")
@@ -31,6 +33,7 @@ func TestLoadBalancer(t *testing.T) {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -57,7 +60,7 @@ func TestLoadBalancer(t *testing.T) {
 		t.Fatalf("failed to create public ip address: %+v", err)
 	}
 
-	loadBalancerUrl := "/subscriptions/" + config.SubscriptionID() + "/resourceGroups/" + config.GroupName() + "/providers/Microsoft.Network/loadBalancers/" + loadBalancerName
+	loadBalancerID := resourceid.ResourceGroup(config.SubscriptionID(), groupName).Resource("Microsoft.Network", "loadBalancers", loadBalancerName)
 	loadBalancerParameters := armnetwork.LoadBalancer{
 		Resource: armnetwork.Resource{
 			Location: to.StringPtr(config.Location()),
@@ -85,20 +88,20 @@ func TestLoadBalancer(t *testing.T) {
 					Name: &loadBalancingRuleName,
 					Properties: &armnetwork.LoadBalancingRulePropertiesFormat{
 						BackendAddressPool: &armnetwork.SubResource{
-							ID: to.StringPtr(loadBalancerUrl + "/backendAddressPools/" + backendAddressPoolName),
+							ID: to.StringPtr(loadBalancerID.Child("backendAddressPools", backendAddressPoolName).String()),
 						},
 						BackendPort:         to.Int32Ptr(80),
 						DisableOutboundSnat: to.BoolPtr(true),
 						EnableFloatingIP:    to.BoolPtr(true),
 						EnableTCPReset:      new(bool),
 						FrontendIPConfiguration: &armnetwork.SubResource{
-							ID: to.StringPtr(loadBalancerUrl + "/frontendIPConfigurations/" + frontendIpConfigurationName),
+							ID: to.StringPtr(loadBalancerID.Child("frontendIPConfigurations", frontendIpConfigurationName).String()),
 						},
 						FrontendPort:         to.Int32Ptr(80),
 						IdleTimeoutInMinutes: to.Int32Ptr(15),
 						LoadDistribution:     armnetwork.LoadDistributionDefault.ToPtr(),
 						Probe: &armnetwork.SubResource{
-							ID: to.StringPtr(loadBalancerUrl + "/probes/" + probeName),
+							ID: to.StringPtr(loadBalancerID.Child("probes", probeName).String()),
 						},
 						Protocol: armnetwork.TransportProtocolTCP.ToPtr(),
 					},
@@ -109,11 +112,11 @@ func TestLoadBalancer(t *testing.T) {
 					Name: &outBoundRuleName,
 					Properties: &armnetwork.OutboundRulePropertiesFormat{
 						BackendAddressPool: &armnetwork.SubResource{
-							ID: to.StringPtr(loadBalancerUrl + "/backendAddressPools/" + backendAddressPoolName),
+							ID: to.StringPtr(loadBalancerID.Child("backendAddressPools", backendAddressPoolName).String()),
 						},
 						FrontendIPConfigurations: []*armnetwork.SubResource{
 							{
-								ID: to.StringPtr(loadBalancerUrl + "/frontendIPConfigurations/" + frontendIpConfigurationName),
+								ID: to.StringPtr(loadBalancerID.Child("frontendIPConfigurations", frontendIpConfigurationName).String()),
 							},
 						},
 						Protocol: armnetwork.LoadBalancerOutboundRuleProtocolAll.ToPtr(),
@@ -157,94 +160,94 @@ func TestLoadBalancer(t *testing.T) {
 			Protocol:             armnetwork.TransportProtocolTCP.ToPtr(),
 		},
 	}
-	err = CreateInboundNatRule(ctx, loadBalancerName, inboundNatRuleName, inboundNatRuleParameters)
+	_, err = CreateInboundNatRule(ctx, loadBalancerName, inboundNatRuleName, inboundNatRuleParameters)
 	if err != nil {
 		t.Fatalf("failed to get load balancer inbound nat rule: %+v", err)
 	}
 
-	err = GetLoadBalancerFrontendIPConfiguration(ctx, loadBalancerName, frontendIpConfigurationName)
+	_, err = GetLoadBalancerFrontendIPConfiguration(ctx, loadBalancerName, frontendIpConfigurationName)
 	if err != nil {
 		t.Fatalf("failed to get load balancer frontend IP configuration: %+v", err)
 	}
 
-	err = GetLoadBalancerBackendAddressPool(ctx, loadBalancerName, backendAddressPoolName)
+	_, err = GetLoadBalancerBackendAddressPool(ctx, loadBalancerName, backendAddressPoolName)
 	if err != nil {
 		t.Fatalf("failed to get load balancer backend address pool: %+v", err)
 	}
 
-	err = GetLoadBalancerLoadBalancingRule(ctx, loadBalancerName, loadBalancingRuleName)
+	_, err = GetLoadBalancerLoadBalancingRule(ctx, loadBalancerName, loadBalancingRuleName)
 	if err != nil {
 		t.Fatalf("failed to get the specified load balancer load balancing rule: %+v", err)
 	}
 	t.Logf("got the specified load balancer load balancing rule")
 
-	err = GetLoadBalancerOutboundRule(ctx, loadBalancerName, outBoundRuleName)
+	_, err = GetLoadBalancerOutboundRule(ctx, loadBalancerName, outBoundRuleName)
 	if err != nil {
 		t.Fatalf("failed to get the specified load balancer outbound rule.: %+v", err)
 	}
 	t.Logf("got the specified load balancer outbound rule.")
 
-	err = ListLoadBalancerFrontendIPConfiguration(ctx, loadBalancerName)
+	_, err = ListLoadBalancerFrontendIPConfiguration(ctx, loadBalancerName)
 	if err != nil {
 		t.Fatalf("failed to get all the load balancer frontend IP configurations: %+v", err)
 	}
 	t.Logf("listed all the load balancer frontend IP configurations.")
 
-	err = GetLoadBalancerProbe(ctx, loadBalancerName, probeName)
+	_, err = GetLoadBalancerProbe(ctx, loadBalancerName, probeName)
 	if err != nil {
 		t.Fatalf("failed to get load balancer probe: %+v", err)
 	}
 	t.Logf("got load balancer probe")
 
-	err = ListLoadBalancerBackendAddressPool(ctx, loadBalancerName)
+	_, err = ListLoadBalancerBackendAddressPool(ctx, loadBalancerName)
 	if err != nil {
 		t.Fatalf("failed to get all the load balancer backed address pools: %+v", err)
 	}
 	t.Logf("listed all the load balancer backed address pools")
 
-	err = ListLoadBalancerLoadBalancingRule(ctx, loadBalancerName)
+	_, err = ListLoadBalancerLoadBalancingRule(ctx, loadBalancerName)
 	if err != nil {
 		t.Fatalf("failed to list all the load balancing rules in a load balancer: %+v", err)
 	}
 	t.Logf("listed all the load balancing rules in a load balancer")
 
-	err = ListLoadBalancerNetworkInterface(ctx, loadBalancerName)
+	_, err = ListLoadBalancerNetworkInterface(ctx, loadBalancerName)
 	if err != nil {
 		t.Fatalf("failed to list associated load balancer network interfaces: %+v", err)
 	}
 	t.Logf("listed associated load balancer network interfaces")
 
-	err = ListLoadBalancerNetworkInterface(ctx, loadBalancerName)
+	_, err = ListLoadBalancerNetworkInterface(ctx, loadBalancerName)
 	if err != nil {
 		t.Fatalf("failed to list associated load balancer network interfaces: %+v", err)
 	}
 	t.Logf("listed associated load balancer network interfaces")
 
-	err = ListLoadBalancerOutboundRule(ctx, loadBalancerName)
+	_, err = ListLoadBalancerOutboundRule(ctx, loadBalancerName)
 	if err != nil {
 		t.Fatalf("failed to list all the outbound rules in a load balancer: %+v", err)
 	}
 	t.Logf("listed all the outbound rules in a load balancer")
 
-	err = ListLoadBalancerProbe(ctx, loadBalancerName)
+	_, err = ListLoadBalancerProbe(ctx, loadBalancerName)
 	if err != nil {
 		t.Fatalf("failed to list load balancer probe: %+v", err)
 	}
 	t.Logf("listed load balancer probe")
 
-	err = GetLoadBalancer(ctx, loadBalancerName)
+	_, err = GetLoadBalancer(ctx, loadBalancerName)
 	if err != nil {
 		t.Fatalf("failed to get the specified load balancer in a specified resource group: %+v", err)
 	}
 	t.Logf("got the specified load balancer in a specified resource group")
 
-	err = ListLoadBalancer(ctx)
+	_, err = ListLoadBalancer(ctx)
 	if err != nil {
 		t.Fatalf("failed to list all the load balancers in a resource group: %+v", err)
 	}
 	t.Logf("listed all the load balancers in a resource group")
 
-	err = ListAllLoadBalancer(ctx)
+	_, err = ListAllLoadBalancer(ctx)
 	if err != nil {
 		t.Fatalf("failed to list all the load balancer in a subscription: %+v", err)
 	}
@@ -253,7 +256,7 @@ func TestLoadBalancer(t *testing.T) {
 	tagsObjectParameters := armnetwork.TagsObject{
 		Tags: map[string]*string{"tag1": to.StringPtr("value1"), "tag2": to.StringPtr("value2")},
 	}
-	err = UpdateLoadBalancerTags(ctx, loadBalancerName, tagsObjectParameters)
+	_, err = UpdateLoadBalancerTags(ctx, loadBalancerName, tagsObjectParameters)
 	if err != nil {
 		t.Fatalf("failed to update tags for load balancer: %+v", err)
 	}
@@ -11,26 +11,26 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getLocalNetworkGatewaysClient() armnetwork.LocalNetworkGatewaysClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getLocalNetworkGatewaysClient(ctx context.Context) armnetwork.LocalNetworkGatewaysClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewLocalNetworkGatewaysClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewLocalNetworkGatewaysClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Create LocalNetworkGateways
 func CreateLocalNetworkGateway(ctx context.Context, localNetworkGatewayName string, localNetworkGatewayParameters armnetwork.LocalNetworkGateway) (string, error) {
-	client := getLocalNetworkGatewaysClient()
+	client := getLocalNetworkGatewaysClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		localNetworkGatewayName,
 		localNetworkGatewayParameters,
 		nil,
@@ -40,7 +40,7 @@ func CreateLocalNetworkGateway(ctx context.Context, localNetworkGatewayName stri
 		return "", err
 	}
 
-	resp, err := poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return "", err
 	}
@@ -49,56 +49,55 @@ func CreateLocalNetworkGateway(ctx context.Context, localNetworkGatewayName stri
 }
 
 // Gets the specified local network gateway in a specified resource group.
-func GetLocalNetworkGateway(ctx context.Context, localNetworkGatewayName string) error {
-	client := getLocalNetworkGatewaysClient()
-	_, err := client.Get(ctx, config.GroupName(), localNetworkGatewayName, nil)
+func GetLocalNetworkGateway(ctx context.Context, localNetworkGatewayName string) (*armnetwork.LocalNetworkGateway, error) {
+	client := getLocalNetworkGatewaysClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, localNetworkGatewayName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.LocalNetworkGateway, nil
 }
 
 // Gets all the local network gateway in a subscription.
-func ListLocalNetworkGateway(ctx context.Context) error {
-	client := getLocalNetworkGatewaysClient()
-	pager := client.List(config.GroupName(), nil)
+func ListLocalNetworkGateway(ctx context.Context) ([]*armnetwork.LocalNetworkGateway, error) {
+	client := getLocalNetworkGatewaysClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).GroupName, nil)
 
+	var results []*armnetwork.LocalNetworkGateway
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().LocalNetworkGatewayListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Updates local network gateway tags.
-func UpdateLocalNetworkGatewayTags(ctx context.Context, localNetworkGatewayName string, tagsObjectParameters armnetwork.TagsObject) error {
-	client := getLocalNetworkGatewaysClient()
-	_, err := client.UpdateTags(
+func UpdateLocalNetworkGatewayTags(ctx context.Context, localNetworkGatewayName string, tagsObjectParameters armnetwork.TagsObject) (*armnetwork.LocalNetworkGateway, error) {
+	client := getLocalNetworkGatewaysClient(ctx)
+	resp, err := client.UpdateTags(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		localNetworkGatewayName,
 		tagsObjectParameters,
 		nil,
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.LocalNetworkGateway, nil
 }
 
 // Deletes the specified local network gateway.
 func DeleteLocalNetworkGateway(ctx context.Context, localNetworkGatewayName string) error {
-	client := getLocalNetworkGatewaysClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), localNetworkGatewayName, nil)
+	client := getLocalNetworkGatewaysClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, localNetworkGatewayName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
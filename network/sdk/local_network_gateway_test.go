@@ -11,19 +11,21 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
 )
 
 func TestLocalNetworkGateway(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	localNetworkGatewayName := config.AppendRandomSuffix("localnetworkgateway")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -48,13 +50,13 @@ func TestLocalNetworkGateway(t *testing.T) {
 	}
 	t.Logf("created local network gateway")
 
-	err = GetLocalNetworkGateway(ctx, localNetworkGatewayName)
+	_, err = GetLocalNetworkGateway(ctx, localNetworkGatewayName)
 	if err != nil {
 		t.Fatalf("failed to get local network gateway: %+v", err)
 	}
 	t.Logf("got local network gateway")
 
-	err = ListLocalNetworkGateway(ctx)
+	_, err = ListLocalNetworkGateway(ctx)
 	if err != nil {
 		t.Fatalf("failed to list local network gateway: %+v", err)
 	}
@@ -63,7 +65,7 @@ func TestLocalNetworkGateway(t *testing.T) {
 	tagsObjectParameters := armnetwork.TagsObject{
 		Tags: map[string]*string{"tag1": to.StringPtr("value1"), "tag2": to.StringPtr("value2")},
 	}
-	err = UpdateLocalNetworkGatewayTags(ctx, localNetworkGatewayName, tagsObjectParameters)
+	_, err = UpdateLocalNetworkGatewayTags(ctx, localNetworkGatewayName, tagsObjectParameters)
 	if err != nil {
 		t.Fatalf("failed to update tags for local network gateway: %+v", err)
 	}
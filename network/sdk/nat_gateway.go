@@ -11,109 +11,108 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getNatGatewayClient() armnetwork.NatGatewaysClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getNatGatewayClient(ctx context.Context) armnetwork.NatGatewaysClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewNatGatewaysClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewNatGatewaysClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Creates or updates a nat gateway.
-func CreateNatGateway(ctx context.Context, natGatewayName string, natGatewayParameters armnetwork.NatGateway) error {
-	client := getNatGatewayClient()
+func CreateNatGateway(ctx context.Context, natGatewayName string, natGatewayParameters armnetwork.NatGateway) (*armnetwork.NatGateway, error) {
+	client := getNatGatewayClient(ctx)
 
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		natGatewayName,
 		natGatewayParameters,
 		nil,
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.NatGateway, nil
 }
 
 // Gets the specified nat gateway in a specified resource group.
-func GetNatGateway(ctx context.Context, natGatewayName string) error {
-	client := getNatGatewayClient()
-	_, err := client.Get(ctx, config.GroupName(), natGatewayName, nil)
+func GetNatGateway(ctx context.Context, natGatewayName string) (*armnetwork.NatGateway, error) {
+	client := getNatGatewayClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, natGatewayName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.NatGateway, nil
 }
 
 // Gets all nat gateways in a resource group.
-func ListNatGateway(ctx context.Context) error {
-	client := getNatGatewayClient()
-	pager := client.List(config.GroupName(), nil)
+func ListNatGateway(ctx context.Context) ([]*armnetwork.NatGateway, error) {
+	client := getNatGatewayClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).GroupName, nil)
 
+	var results []*armnetwork.NatGateway
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().NatGatewayListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Gets all the Nat Gateways in a subscription.
-func ListAllNatGateway(ctx context.Context) error {
-	client := getNatGatewayClient()
+func ListAllNatGateway(ctx context.Context) ([]*armnetwork.NatGateway, error) {
+	client := getNatGatewayClient(ctx)
 	pager := client.ListAll(nil)
+
+	var results []*armnetwork.NatGateway
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().NatGatewayListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Updates nat gateway tags.
-func UpdateNatGateway(ctx context.Context, natGatewayName string, tagsObjectParameters armnetwork.TagsObject) error {
-	client := getNatGatewayClient()
-	_, err := client.UpdateTags(
+func UpdateNatGateway(ctx context.Context, natGatewayName string, tagsObjectParameters armnetwork.TagsObject) (*armnetwork.NatGateway, error) {
+	client := getNatGatewayClient(ctx)
+	resp, err := client.UpdateTags(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		natGatewayName,
 		tagsObjectParameters,
 		nil,
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.NatGateway, nil
 }
 
 // Deletes the specified nat gateway.
 func DeleteNatGatewayGroup(ctx context.Context, natGatewayName string) error {
-	client := getNatGatewayClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), natGatewayName, nil)
+	client := getNatGatewayClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, natGatewayName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -11,21 +11,23 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
 )
 
 func TestNatGateWay(t *testing.T) {
+	defer recording.Start(t)()
+
 	natGatewayName := config.AppendRandomSuffix("natgateway")
 	publicIpAddressName := config.AppendRandomSuffix("pipaddress")
 	prefixName := config.AppendRandomSuffix("pipprefix")
 
 	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
-
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -91,25 +93,25 @@ func TestNatGateWay(t *testing.T) {
 			Name: armnetwork.NatGatewaySKUNameStandard.ToPtr(),
 		},
 	}
-	err = CreateNatGateway(ctx, natGatewayName, natGatewayParameters)
+	_, err = CreateNatGateway(ctx, natGatewayName, natGatewayParameters)
 	if err != nil {
 		t.Fatalf("failed to create nat gateway: %+v", err)
 	}
 	t.Logf("created nat gateway")
 
-	err = GetNatGateway(ctx, natGatewayName)
+	_, err = GetNatGateway(ctx, natGatewayName)
 	if err != nil {
 		t.Fatalf("failed to get nat gateway: %+v", err)
 	}
 	t.Logf("got nat gateway")
 
-	err = ListNatGateway(ctx)
+	_, err = ListNatGateway(ctx)
 	if err != nil {
 		t.Fatalf("failed to list nat gateway: %+v", err)
 	}
 	t.Logf("listed nat gateway")
 
-	err = ListAllNatGateway(ctx)
+	_, err = ListAllNatGateway(ctx)
 	if err != nil {
 		t.Fatalf("failed to list all nat gateway: %+v", err)
 	}
@@ -118,7 +120,7 @@ func TestNatGateWay(t *testing.T) {
 	tagsObjectParameters := armnetwork.TagsObject{
 		Tags: map[string]*string{"tag1": to.StringPtr("value1"), "tag2": to.StringPtr("value2")},
 	}
-	err = UpdateNatGateway(ctx, natGatewayName, tagsObjectParameters)
+	_, err = UpdateNatGateway(ctx, natGatewayName, tagsObjectParameters)
 	if err != nil {
 		t.Fatalf("failed to update nat gateway: %+v", err)
 	}
@@ -9,33 +9,31 @@ import (
 	"context"
 	"log"
 
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
 func getOperationsClient() armnetwork.OperationsClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewOperationsClient(armcore.NewDefaultConnection(cred, nil))
+	client := armnetwork.NewOperationsClient(con)
 	return *client
 }
 
 // Lists all of the available REST API operations of the Microsoft.Search provider.
-func ListOperation(ctx context.Context) error {
+func ListOperation(ctx context.Context) ([]*armnetwork.Operation, error) {
 	client := getOperationsClient()
 	pager := client.List(nil)
 
+	var results []*armnetwork.Operation
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().OperationListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
@@ -9,13 +9,17 @@ import (
 	"context"
 	"testing"
 	"time"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 )
 
 func TestOperation(t *testing.T) {
+	defer recording.Start(t)()
+
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
 
-	err := ListOperation(ctx)
+	_, err := ListOperation(ctx)
 	if err != nil {
 		t.Fatalf("failed to list the available REST API operations of the Microsoft.Search provider: %+v", err)
 	}
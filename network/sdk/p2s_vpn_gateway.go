@@ -11,38 +11,38 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getP2sVpnGatewaysClient() armnetwork.P2SVPNGatewaysClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getP2sVpnGatewaysClient(ctx context.Context) armnetwork.P2SVPNGatewaysClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewP2SVPNGatewaysClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewP2SVPNGatewaysClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Creates a virtual wan p2s vpn gateway if it doesn't exist else updates the existing gateway.
-func CreateP2sVpnGateway(ctx context.Context, gatewayName string, p2SVPNGatewayParameters armnetwork.P2SVPNGateway) error {
-	client := getP2sVpnGatewaysClient()
+func CreateP2sVpnGateway(ctx context.Context, gatewayName string, p2SVPNGatewayParameters armnetwork.P2SVPNGateway) (*armnetwork.P2SVPNGateway, error) {
+	client := getP2sVpnGatewaysClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		gatewayName,
 		p2SVPNGatewayParameters,
 		nil,
 	)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.P2SVPNGateway, nil
 }
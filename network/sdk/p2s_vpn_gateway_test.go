@@ -11,15 +11,17 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/resourceid"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/go-autorest/autorest/to"
 )
 
 func TestP2sVpnGateway(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	vpnGatewayName := config.AppendRandomSuffix("vpngateway")
 	virtualWanName := config.AppendRandomSuffix("virtualwan")
 	virtualHubName := config.AppendRandomSuffix("virtualhub")
@@ -30,6 +32,7 @@ func TestP2sVpnGateway(t *testing.T) {
 	p2sConnectionConfigurationName := config.AppendRandomSuffix("p2sconnectionconfiguration")
 	ctx, cancel := context.WithTimeout(context.Background(), 6000*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -143,7 +146,7 @@ func TestP2sVpnGateway(t *testing.T) {
 			},
 		},
 	}
-	err = CreateVpnGateway(ctx, vpnGatewayName, vpnGatewayParameters)
+	_, err = CreateVpnGateway(ctx, vpnGatewayName, vpnGatewayParameters)
 	if err != nil {
 		t.Fatalf("failed to create vpn gateway: % +v", err)
 	}
@@ -174,7 +177,7 @@ func TestP2sVpnGateway(t *testing.T) {
 		Properties: &armnetwork.P2SVPNGatewayProperties{
 			P2SConnectionConfigurations: []*armnetwork.P2SConnectionConfiguration{{
 				SubResource: armnetwork.SubResource{
-					ID: to.StringPtr("/subscriptions/" + config.SubscriptionID() + "/resourceGroups/" + config.GroupName() + "/providers/Microsoft.Network/p2sVpnGateways/" + p2sVpnGatewayName + "/p2sConnectionConfigurations/" + p2sConnectionConfigurationName),
+					ID: to.StringPtr(resourceid.ResourceGroup(config.SubscriptionID(), groupName).Resource("Microsoft.Network", "p2sVpnGateways", p2sVpnGatewayName).Child("p2sConnectionConfigurations", p2sConnectionConfigurationName).String()),
 				},
 				Name: to.StringPtr("P2SConnectionConfig1"),
 				Properties: &armnetwork.P2SConnectionConfigurationProperties{
@@ -196,7 +199,7 @@ func TestP2sVpnGateway(t *testing.T) {
 			},
 		},
 	}
-	err = CreateP2sVpnGateway(ctx, vpnGatewayName, p2SVPNGatewayParameters)
+	_, err = CreateP2sVpnGateway(ctx, vpnGatewayName, p2SVPNGatewayParameters)
 	if err != nil {
 		t.Fatalf("failed to create p2s vpn gateway: % +v", err)
 	}
@@ -11,26 +11,26 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getPacketCapturesClient() armnetwork.PacketCapturesClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getPacketCapturesClient(ctx context.Context) armnetwork.PacketCapturesClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewPacketCapturesClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewPacketCapturesClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Create PacketCaptures
-func CreatePacketCapture(ctx context.Context, networkWatcherName string, packetCaptureName string, packetCaptureParameters armnetwork.PacketCapture) error {
-	client := getPacketCapturesClient()
+func CreatePacketCapture(ctx context.Context, networkWatcherName string, packetCaptureName string, packetCaptureParameters armnetwork.PacketCapture) (*armnetwork.PacketCaptureResult, error) {
+	client := getPacketCapturesClient(ctx)
 	poller, err := client.BeginCreate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		networkWatcherName,
 		packetCaptureName,
 		packetCaptureParameters,
@@ -38,61 +38,61 @@ func CreatePacketCapture(ctx context.Context, networkWatcherName string, packetC
 	)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.PacketCaptureResult, nil
 }
 
 // Gets the specified packet capture in a specified resource group.
-func GetPacketCapture(ctx context.Context, networkWatcherName string, packetCaptureName string) error {
-	client := getPacketCapturesClient()
-	_, err := client.Get(ctx, config.GroupName(), networkWatcherName, packetCaptureName, nil)
+func GetPacketCapture(ctx context.Context, networkWatcherName string, packetCaptureName string) (*armnetwork.PacketCaptureResult, error) {
+	client := getPacketCapturesClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, networkWatcherName, packetCaptureName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.PacketCaptureResult, nil
 }
 
 // Gets all the packet capture in a subscription.
-func ListPacketCapture(ctx context.Context, networkWatcherName string) error {
-	client := getPacketCapturesClient()
-	_, err := client.List(ctx, config.GroupName(), networkWatcherName, nil)
+func ListPacketCapture(ctx context.Context, networkWatcherName string) (*armnetwork.PacketCaptureListResult, error) {
+	client := getPacketCapturesClient(ctx)
+	resp, err := client.List(ctx, config.ScopeFrom(ctx).GroupName, networkWatcherName, nil)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.PacketCaptureListResult, nil
 }
 
 // Query the status of a running packet capture session.
-func GetPacketCaptureStatus(ctx context.Context, networkWatcherName string, packetCaptureName string) error {
-	client := getPacketCapturesClient()
-	resp, err := client.BeginGetStatus(ctx, config.GroupName(), networkWatcherName, packetCaptureName, nil)
+func GetPacketCaptureStatus(ctx context.Context, networkWatcherName string, packetCaptureName string) (*armnetwork.PacketCaptureQueryStatusResult, error) {
+	client := getPacketCapturesClient(ctx)
+	poller, err := client.BeginGetStatus(ctx, config.ScopeFrom(ctx).GroupName, networkWatcherName, packetCaptureName, nil)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.PacketCaptureQueryStatusResult, nil
 }
 
 // Stops a specified packet capture session.
 func StopPacketCapture(ctx context.Context, networkWatcherName string, packetCaptureName string) error {
-	client := getPacketCapturesClient()
-	resp, err := client.BeginStop(ctx, config.GroupName(), networkWatcherName, packetCaptureName, nil)
+	client := getPacketCapturesClient(ctx)
+	resp, err := client.BeginStop(ctx, config.ScopeFrom(ctx).GroupName, networkWatcherName, packetCaptureName, nil)
 
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -101,12 +101,12 @@ func StopPacketCapture(ctx context.Context, networkWatcherName string, packetCap
 
 // Deletes the specified packet capture.
 func DeletePacketCapture(ctx context.Context, networkWatcherName string, packetCaptureName string) error {
-	client := getPacketCapturesClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), networkWatcherName, packetCaptureName, nil)
+	client := getPacketCapturesClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, networkWatcherName, packetCaptureName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -12,22 +12,22 @@ import (
 
 	compute "github.com/Azure-Samples/azure-sdk-for-go-samples/compute/sdk"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	storage "github.com/Azure-Samples/azure-sdk-for-go-samples/storage/sdk"
 	"github.com/Azure/azure-sdk-for-go/sdk/compute/armcompute"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/armstorage"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
-	"github.com/marstr/randname"
 )
 
 func TestPacketCapture(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	packetCaptureName := config.AppendRandomSuffix("packetcapture")
 	networkWatcherName := config.AppendRandomSuffix("networkwatcher")
-	storageAccountName := randname.Prefixed{Prefix: "storageaccount", Acceptable: randname.LowercaseAlphabet, Len: 5}.Generate()
+	storageAccountName := config.AppendRandomLowercaseSuffix("storageaccount")
 	virtualMachineName := config.AppendRandomSuffix("virtualmachine")
 	ipConfigurationName := config.AppendRandomSuffix("ipconfiguration")
 	networkInterfaceName := config.AppendRandomSuffix("interface")
@@ -37,6 +37,7 @@ func TestPacketCapture(t *testing.T) {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -44,7 +45,7 @@ func TestPacketCapture(t *testing.T) {
 		t.Fatalf("failed to create group: %+v", err)
 	}
 
-	err = CreateNetworkWatcher(ctx, networkWatcherName)
+	_, err = CreateNetworkWatcher(ctx, networkWatcherName)
 	if err != nil {
 		t.Fatalf("failed to create network watcher: % +v", err)
 	}
@@ -179,7 +180,7 @@ func TestPacketCapture(t *testing.T) {
 			TypeHandlerVersion:      to.StringPtr("1.4"),
 		},
 	}
-	err = compute.CreateVirtualMachineExtension(ctx, virtualMachineName, virtualMachineExtensionName, virtualMachineExtensionParameters)
+	_, err = compute.CreateVirtualMachineExtension(ctx, virtualMachineName, virtualMachineExtensionName, virtualMachineExtensionParameters)
 	if err != nil {
 		t.Fatalf("failed to create virtual machine extension: % +v", err)
 	}
@@ -193,25 +194,25 @@ func TestPacketCapture(t *testing.T) {
 			Target: &vmId,
 		},
 	}
-	err = CreatePacketCapture(ctx, networkWatcherName, packetCaptureName, packetCaptureParameters)
+	_, err = CreatePacketCapture(ctx, networkWatcherName, packetCaptureName, packetCaptureParameters)
 	if err != nil {
 		t.Fatalf("failed to create packet capture: % +v", err)
 	}
 	t.Logf("created packet capture")
 
-	err = GetPacketCapture(ctx, networkWatcherName, packetCaptureName)
+	_, err = GetPacketCapture(ctx, networkWatcherName, packetCaptureName)
 	if err != nil {
 		t.Fatalf("failed to get packet capture: %+v", err)
 	}
 	t.Logf("got packet capture")
 
-	err = ListPacketCapture(ctx, networkWatcherName)
+	_, err = ListPacketCapture(ctx, networkWatcherName)
 	if err != nil {
 		t.Fatalf("failed to list packet capture: %+v", err)
 	}
 	t.Logf("listed packet capture")
 
-	err = GetPacketCaptureStatus(ctx, networkWatcherName, packetCaptureName)
+	_, err = GetPacketCaptureStatus(ctx, networkWatcherName, packetCaptureName)
 	if err != nil {
 		t.Fatalf("failed to get packet capture status session: %+v", err)
 	}
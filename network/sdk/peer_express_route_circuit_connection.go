@@ -10,45 +10,43 @@ import (
 	"log"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getPeerExpressRouteCircuitConnectionsClient() armnetwork.PeerExpressRouteCircuitConnectionsClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getPeerExpressRouteCircuitConnectionsClient(ctx context.Context) armnetwork.PeerExpressRouteCircuitConnectionsClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewPeerExpressRouteCircuitConnectionsClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewPeerExpressRouteCircuitConnectionsClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Gets the specified Peer Express Route Circuit Connection from the specified express route circuit.
 func GetPeerExpressRouteCircuitConnection(ctx context.Context, circuitName string, peeringName string,
-	connectionName string) error {
-	client := getPeerExpressRouteCircuitConnectionsClient()
-	_, err := client.Get(ctx, config.GroupName(), circuitName, peeringName,
+	connectionName string) (*armnetwork.PeerExpressRouteCircuitConnection, error) {
+	client := getPeerExpressRouteCircuitConnectionsClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, circuitName, peeringName,
 		connectionName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.PeerExpressRouteCircuitConnection, nil
 }
 
 // Gets all global reach peer connections associated with a private peering in an express route circuit.
-func ListPeerExpressRouteCircuitConnection(ctx context.Context, circuitName string, peeringName string) error {
-	client := getPeerExpressRouteCircuitConnectionsClient()
-	pager := client.List(config.GroupName(), circuitName, peeringName, nil)
+func ListPeerExpressRouteCircuitConnection(ctx context.Context, circuitName string, peeringName string) ([]*armnetwork.PeerExpressRouteCircuitConnection, error) {
+	client := getPeerExpressRouteCircuitConnectionsClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).GroupName, circuitName, peeringName, nil)
 
+	var results []*armnetwork.PeerExpressRouteCircuitConnection
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().PeerExpressRouteCircuitConnectionListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
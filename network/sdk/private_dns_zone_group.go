@@ -11,26 +11,26 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getPrivateDnsZoneGroupsClient() armnetwork.PrivateDNSZoneGroupsClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getPrivateDnsZoneGroupsClient(ctx context.Context) armnetwork.PrivateDNSZoneGroupsClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewPrivateDNSZoneGroupsClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewPrivateDNSZoneGroupsClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Creates or updates a private dns zone group in the specified private endpoint.
-func CreatePrivateDnsZoneGroup(ctx context.Context, privateEndpointName string, privateDnsZoneGroupName string, privateDNSZoneGroupParameters armnetwork.PrivateDNSZoneGroup) error {
-	client := getPrivateDnsZoneGroupsClient()
+func CreatePrivateDnsZoneGroup(ctx context.Context, privateEndpointName string, privateDnsZoneGroupName string, privateDNSZoneGroupParameters armnetwork.PrivateDNSZoneGroup) (*armnetwork.PrivateDNSZoneGroup, error) {
+	client := getPrivateDnsZoneGroupsClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		privateEndpointName,
 		privateDnsZoneGroupName,
 		privateDNSZoneGroupParameters,
@@ -38,51 +38,50 @@ func CreatePrivateDnsZoneGroup(ctx context.Context, privateEndpointName string,
 	)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.PrivateDNSZoneGroup, nil
 }
 
 // Gets the private dns zone group resource by specified private dns zone group name.
-func GetPrivateDnsZoneGroup(ctx context.Context, privateEndpointName string, privateDnsZoneGroupName string) error {
-	client := getPrivateDnsZoneGroupsClient()
-	_, err := client.Get(ctx, config.GroupName(), privateEndpointName, privateDnsZoneGroupName, nil)
+func GetPrivateDnsZoneGroup(ctx context.Context, privateEndpointName string, privateDnsZoneGroupName string) (*armnetwork.PrivateDNSZoneGroup, error) {
+	client := getPrivateDnsZoneGroupsClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, privateEndpointName, privateDnsZoneGroupName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.PrivateDNSZoneGroup, nil
 }
 
 // Gets all private dns zone groups in a private endpoint.
-func ListPrivateDnsZoneGroup(ctx context.Context, privateEndpointName string) error {
-	client := getPrivateDnsZoneGroupsClient()
-	pager := client.List(privateEndpointName, config.GroupName(), nil)
+func ListPrivateDnsZoneGroup(ctx context.Context, privateEndpointName string) ([]*armnetwork.PrivateDNSZoneGroup, error) {
+	client := getPrivateDnsZoneGroupsClient(ctx)
+	pager := client.List(privateEndpointName, config.ScopeFrom(ctx).GroupName, nil)
 
+	var results []*armnetwork.PrivateDNSZoneGroup
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().PrivateDNSZoneGroupListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Deletes the specified private dns zone group.
 func DeletePrivateDnsZoneGroup(ctx context.Context, privateEndpointName string, privateDnsZoneGroupName string) error {
-	client := getPrivateDnsZoneGroupsClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), privateEndpointName, privateDnsZoneGroupName, nil)
+	client := getPrivateDnsZoneGroupsClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, privateEndpointName, privateDnsZoneGroupName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
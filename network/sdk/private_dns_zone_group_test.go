@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
@@ -18,9 +19,9 @@ import (
 )
 
 func TestPrivateDnsZoneGroup(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	privateDnsZoneGroupName := config.AppendRandomSuffix("privatednszonegroup")
 	privateLinkServiceName := config.AppendRandomSuffix("privatelinkservice")
 	loadBalancerName := config.AppendRandomSuffix("loadbalancer")
@@ -33,6 +34,7 @@ func TestPrivateDnsZoneGroup(t *testing.T) {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -194,19 +196,19 @@ func TestPrivateDnsZoneGroup(t *testing.T) {
 			}},
 		},
 	}
-	err = CreatePrivateDnsZoneGroup(ctx, privateEndpointName, privateDnsZoneGroupName, privateDNSZoneGroupParameters)
+	_, err = CreatePrivateDnsZoneGroup(ctx, privateEndpointName, privateDnsZoneGroupName, privateDNSZoneGroupParameters)
 	if err != nil {
 		t.Fatalf("failed to create private dns zone group: % +v", err)
 	}
 	t.Logf("created private dns zone group")
 
-	err = GetPrivateDnsZoneGroup(ctx, privateEndpointName, privateDnsZoneGroupName)
+	_, err = GetPrivateDnsZoneGroup(ctx, privateEndpointName, privateDnsZoneGroupName)
 	if err != nil {
 		t.Fatalf("failed to get private dns zone group: %+v", err)
 	}
 	t.Logf("got private dns zone group")
 
-	err = ListPrivateDnsZoneGroup(ctx, privateEndpointName)
+	_, err = ListPrivateDnsZoneGroup(ctx, privateEndpointName)
 	if err != nil {
 		t.Fatalf("failed to list private dns zone group: %+v", err)
 	}
@@ -11,27 +11,27 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getPrivateEndpointsClient() armnetwork.PrivateEndpointsClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getPrivateEndpointsClient(ctx context.Context) armnetwork.PrivateEndpointsClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewPrivateEndpointsClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewPrivateEndpointsClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Create PrivateEndpoints
 func CreatePrivateEndpoint(ctx context.Context, privateEndpointName string, privateEndpointParameters armnetwork.PrivateEndpoint) (string, error) {
-	client := getPrivateEndpointsClient()
+	client := getPrivateEndpointsClient(ctx)
 
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		privateEndpointName,
 		privateEndpointParameters,
 		nil,
@@ -41,7 +41,7 @@ func CreatePrivateEndpoint(ctx context.Context, privateEndpointName string, priv
 		return "", err
 	}
 
-	resp, err := poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return "", err
 	}
@@ -49,56 +49,55 @@ func CreatePrivateEndpoint(ctx context.Context, privateEndpointName string, priv
 }
 
 // Gets the specified private endpoint in a specified resource group.
-func GetPrivateEndpoint(ctx context.Context, privateEndpointName string) error {
-	client := getPrivateEndpointsClient()
-	_, err := client.Get(ctx, config.GroupName(), privateEndpointName, nil)
+func GetPrivateEndpoint(ctx context.Context, privateEndpointName string) (*armnetwork.PrivateEndpoint, error) {
+	client := getPrivateEndpointsClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, privateEndpointName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.PrivateEndpoint, nil
 }
 
 // Gets all the private endpoint in a subscription.
-func ListPrivateEndpoint(ctx context.Context) error {
-	client := getPrivateEndpointsClient()
-	pager := client.List(config.GroupName(), nil)
+func ListPrivateEndpoint(ctx context.Context) ([]*armnetwork.PrivateEndpoint, error) {
+	client := getPrivateEndpointsClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).GroupName, nil)
 
+	var results []*armnetwork.PrivateEndpoint
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().PrivateEndpointListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Gets all the private endpoint in a subscription.
-func ListAllPrivateEndpointBySubscription(ctx context.Context) error {
-	client := getPrivateEndpointsClient()
+func ListAllPrivateEndpointBySubscription(ctx context.Context) ([]*armnetwork.PrivateEndpoint, error) {
+	client := getPrivateEndpointsClient(ctx)
 	pager := client.ListBySubscription(nil)
+
+	var results []*armnetwork.PrivateEndpoint
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().PrivateEndpointListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Deletes the specified private endpoint.
 func DeletePrivateEndpoint(ctx context.Context, privateEndpointName string) error {
-	client := getPrivateEndpointsClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), privateEndpointName, nil)
+	client := getPrivateEndpointsClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, privateEndpointName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
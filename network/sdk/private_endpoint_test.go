@@ -11,15 +11,16 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
 )
 
 func TestPrivateEndpoint(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	privateLinkServiceName := config.AppendRandomSuffix("privatelinkservice")
 	loadBalancerName := config.AppendRandomSuffix("loadbalancer")
 	virtualNetworkName := config.AppendRandomSuffix("virtualnetwork")
@@ -30,6 +31,7 @@ func TestPrivateEndpoint(t *testing.T) {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -171,19 +173,19 @@ func TestPrivateEndpoint(t *testing.T) {
 	}
 	t.Logf("created private endpoint")
 
-	err = GetPrivateEndpoint(ctx, privateEndpointName)
+	_, err = GetPrivateEndpoint(ctx, privateEndpointName)
 	if err != nil {
 		t.Fatalf("failed to get private endpoint: %+v", err)
 	}
 	t.Logf("got private endpoint")
 
-	err = ListPrivateEndpoint(ctx)
+	_, err = ListPrivateEndpoint(ctx)
 	if err != nil {
 		t.Fatalf("failed to list private endpoint: %+v", err)
 	}
 	t.Logf("listed private endpoint")
 
-	err = ListAllPrivateEndpointBySubscription(ctx)
+	_, err = ListAllPrivateEndpointBySubscription(ctx)
 	if err != nil {
 		t.Fatalf("failed to list all private endpoint: %+v", err)
 	}
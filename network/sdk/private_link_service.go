@@ -11,27 +11,27 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/go-autorest/autorest/to"
 )
 
-func getPrivateLinkServicesClient() armnetwork.PrivateLinkServicesClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getPrivateLinkServicesClient(ctx context.Context) armnetwork.PrivateLinkServicesClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewPrivateLinkServicesClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewPrivateLinkServicesClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Create PrivateLinkServices
 func CreatePrivateLinkService(ctx context.Context, privateLinkServiceName string, privateLinkServiceParameters armnetwork.PrivateLinkService) (string, error) {
-	client := getPrivateLinkServicesClient()
+	client := getPrivateLinkServicesClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		privateLinkServiceName,
 		privateLinkServiceParameters,
 		nil,
@@ -41,7 +41,7 @@ func CreatePrivateLinkService(ctx context.Context, privateLinkServiceName string
 		return "", err
 	}
 
-	resp, err := poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return "", err
 	}
@@ -50,11 +50,11 @@ func CreatePrivateLinkService(ctx context.Context, privateLinkServiceName string
 }
 
 // Updates private endpoint connection
-func UpdatePrivateEndpointConnection(ctx context.Context, privateLinkServiceName string, peConnectionName string) error {
-	client := getPrivateLinkServicesClient()
-	_, err := client.UpdatePrivateEndpointConnection(
+func UpdatePrivateEndpointConnection(ctx context.Context, privateLinkServiceName string, peConnectionName string) (*armnetwork.PrivateEndpointConnection, error) {
+	client := getPrivateLinkServicesClient(ctx)
+	resp, err := client.UpdatePrivateEndpointConnection(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		privateLinkServiceName,
 		peConnectionName,
 		armnetwork.PrivateEndpointConnection{
@@ -69,59 +69,57 @@ func UpdatePrivateEndpointConnection(ctx context.Context, privateLinkServiceName
 		nil,
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.PrivateEndpointConnection, nil
 }
 
 // Get the specific private end point connection by specific private link service in the resource group.
-func GetPrivateEndpointConnection(ctx context.Context, privateLinkServiceName string, peConnectionName string) error {
-	client := getPrivateLinkServicesClient()
-	_, err := client.GetPrivateEndpointConnection(ctx, config.GroupName(), privateLinkServiceName, peConnectionName, nil)
+func GetPrivateEndpointConnection(ctx context.Context, privateLinkServiceName string, peConnectionName string) (*armnetwork.PrivateEndpointConnection, error) {
+	client := getPrivateLinkServicesClient(ctx)
+	resp, err := client.GetPrivateEndpointConnection(ctx, config.ScopeFrom(ctx).GroupName, privateLinkServiceName, peConnectionName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.PrivateEndpointConnection, nil
 }
 
 // Gets all private end point connections for a specific private link service.
-func ListPrivateEndpointConnection(ctx context.Context, privateLinkServiceName string) error {
-	client := getPrivateLinkServicesClient()
-	pager := client.ListPrivateEndpointConnections(config.GroupName(), privateLinkServiceName, nil)
+func ListPrivateEndpointConnection(ctx context.Context, privateLinkServiceName string) ([]*armnetwork.PrivateEndpointConnection, error) {
+	client := getPrivateLinkServicesClient(ctx)
+	pager := client.ListPrivateEndpointConnections(config.ScopeFrom(ctx).GroupName, privateLinkServiceName, nil)
 
+	var results []*armnetwork.PrivateEndpointConnection
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().PrivateEndpointConnectionListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Returns all of the private link service ids that can be linked to a Private Endpoint with auto approved
-func ListAutoApprovedPrivateLinkServicesByResourceGroup(ctx context.Context) error {
-	client := getPrivateLinkServicesClient()
-	pager := client.ListAutoApprovedPrivateLinkServicesByResourceGroup(config.Location(), config.GroupName(), nil)
+func ListAutoApprovedPrivateLinkServicesByResourceGroup(ctx context.Context) ([]*armnetwork.AutoApprovedPrivateLinkService, error) {
+	client := getPrivateLinkServicesClient(ctx)
+	pager := client.ListAutoApprovedPrivateLinkServicesByResourceGroup(config.ScopeFrom(ctx).Location, config.ScopeFrom(ctx).GroupName, nil)
 
+	var results []*armnetwork.AutoApprovedPrivateLinkService
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().AutoApprovedPrivateLinkServicesResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Gets the specified private link service in a specified resource group.
 func GetPrivateLinkService(ctx context.Context, privateLinkServiceName string) (string, error) {
-	client := getPrivateLinkServicesClient()
-	result, err := client.Get(ctx, config.GroupName(), privateLinkServiceName, nil)
+	client := getPrivateLinkServicesClient(ctx)
+	result, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, privateLinkServiceName, nil)
 	if err != nil {
 		return "", err
 	}
@@ -133,47 +131,45 @@ func GetPrivateLinkService(ctx context.Context, privateLinkServiceName string) (
 }
 
 // Gets all private end point connections for a specific private link service.
-func ListPrivateEndpointConnections(ctx context.Context, privateLinkServiceName string) error {
-	client := getPrivateLinkServicesClient()
-	pager := client.ListPrivateEndpointConnections(config.GroupName(), privateLinkServiceName, nil)
+func ListPrivateEndpointConnections(ctx context.Context, privateLinkServiceName string) ([]*armnetwork.PrivateEndpointConnection, error) {
+	client := getPrivateLinkServicesClient(ctx)
+	pager := client.ListPrivateEndpointConnections(config.ScopeFrom(ctx).GroupName, privateLinkServiceName, nil)
 
+	var results []*armnetwork.PrivateEndpointConnection
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().PrivateEndpointConnectionListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Gets all private link service in a subscription.
-func ListBySubscription(ctx context.Context) error {
-	client := getPrivateLinkServicesClient()
+func ListBySubscription(ctx context.Context) ([]*armnetwork.PrivateLinkService, error) {
+	client := getPrivateLinkServicesClient(ctx)
 	pager := client.ListBySubscription(nil)
 
+	var results []*armnetwork.PrivateLinkService
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().PrivateLinkServiceListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Deletes the specified private link service.
 func BeginDeletePrivateEndpointConnection(ctx context.Context, privateLinkServiceName string, peConnectionName string) error {
-	client := getPrivateLinkServicesClient()
-	resp, err := client.BeginDeletePrivateEndpointConnection(ctx, config.GroupName(), privateLinkServiceName, peConnectionName, nil)
+	client := getPrivateLinkServicesClient(ctx)
+	resp, err := client.BeginDeletePrivateEndpointConnection(ctx, config.ScopeFrom(ctx).GroupName, privateLinkServiceName, peConnectionName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -182,12 +178,12 @@ func BeginDeletePrivateEndpointConnection(ctx context.Context, privateLinkServic
 
 // Deletes the specified private link service.
 func DeletePrivateLinkService(ctx context.Context, privateLinkServiceName string) error {
-	client := getPrivateLinkServicesClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), privateLinkServiceName, nil)
+	client := getPrivateLinkServicesClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, privateLinkServiceName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -11,15 +11,16 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
 )
 
 func TestPrivateLinkService(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	privateLinkServiceName := config.AppendRandomSuffix("privatelinkservice")
 	loadBalancerName := config.AppendRandomSuffix("loadbalancer")
 	virtualNetworkName := config.AppendRandomSuffix("virtualnetwork")
@@ -30,6 +31,7 @@ func TestPrivateLinkService(t *testing.T) {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -178,25 +180,25 @@ func TestPrivateLinkService(t *testing.T) {
 	}
 	t.Logf("got private link service")
 
-	err = UpdatePrivateEndpointConnection(ctx, privateLinkServiceName, peConnectionName)
+	_, err = UpdatePrivateEndpointConnection(ctx, privateLinkServiceName, peConnectionName)
 	if err != nil {
 		t.Fatalf("failed to update tags for private link service: %+v", err)
 	}
 	t.Logf("updated private link service tags")
 
-	err = GetPrivateEndpointConnection(ctx, privateLinkServiceName, peConnectionName)
+	_, err = GetPrivateEndpointConnection(ctx, privateLinkServiceName, peConnectionName)
 	if err != nil {
 		t.Fatalf("failed to get private endpoint connection: %+v", err)
 	}
 	t.Logf("got private endpoint connection")
 
-	err = ListPrivateEndpointConnections(ctx, privateLinkServiceName)
+	_, err = ListPrivateEndpointConnections(ctx, privateLinkServiceName)
 	if err != nil {
 		t.Fatalf("failed to list private endpoint connection: %+v", err)
 	}
 	t.Logf("listed private endpoint connection")
 
-	err = ListAutoApprovedPrivateLinkServicesByResourceGroup(ctx)
+	_, err = ListAutoApprovedPrivateLinkServicesByResourceGroup(ctx)
 	if err != nil {
 		t.Fatalf("failed to list approved private link services by resource group: %+v", err)
 	}
@@ -208,13 +210,13 @@ func TestPrivateLinkService(t *testing.T) {
 	}
 	t.Logf("got private link service")
 
-	err = ListPrivateEndpointConnections(ctx, privateLinkServiceName)
+	_, err = ListPrivateEndpointConnections(ctx, privateLinkServiceName)
 	if err != nil {
 		t.Fatalf("failed to list private endpoint connection: %+v", err)
 	}
 	t.Logf("listed private endpoint connection")
 
-	err = ListBySubscription(ctx)
+	_, err = ListBySubscription(ctx)
 	if err != nil {
 		t.Fatalf("failed to list private link services by subscription: %+v", err)
 	}
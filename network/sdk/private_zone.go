@@ -10,23 +10,25 @@ import (
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/services/privatedns/mgmt/2018-09-01/privatedns"
 )
 
-func getPrivateZonesClient() privatedns.PrivateZonesClient {
-	privateZonesClient := privatedns.NewPrivateZonesClient(config.SubscriptionID())
+func getPrivateZonesClient(ctx context.Context) privatedns.PrivateZonesClient {
+	privateZonesClient := privatedns.NewPrivateZonesClient(config.ScopeFrom(ctx).SubscriptionID)
 	a, _ := iam.GetResourceManagementAuthorizer()
 	privateZonesClient.Authorizer = a
 	privateZonesClient.AddToUserAgent(config.UserAgent())
+	privateZonesClient.Sender = recording.Sender()
 	return privateZonesClient
 }
 
 // creates or updates a Private DNS zone. Does not modify Links to virtual networks or DNS records
 func CreatePrivateZone(ctx context.Context, privateZoneName string, privateZoneParameters privatedns.PrivateZone) (privateZone privatedns.PrivateZone, err error) {
-	client := getPrivateZonesClient()
+	client := getPrivateZonesClient(ctx)
 	future, err := client.CreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		privateZoneName,
 		privateZoneParameters,
 		"",
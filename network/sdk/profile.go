@@ -11,105 +11,104 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getNetworkProfilesClient() armnetwork.NetworkProfilesClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getNetworkProfilesClient(ctx context.Context) armnetwork.NetworkProfilesClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewNetworkProfilesClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewNetworkProfilesClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Create NetworkProfiles
-func CreateNetworkProfile(ctx context.Context, networkProfileName string, networkProfileParameters armnetwork.NetworkProfile) error {
-	client := getNetworkProfilesClient()
-	_, err := client.CreateOrUpdate(
+func CreateNetworkProfile(ctx context.Context, networkProfileName string, networkProfileParameters armnetwork.NetworkProfile) (*armnetwork.NetworkProfile, error) {
+	client := getNetworkProfilesClient(ctx)
+	resp, err := client.CreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		networkProfileName,
 		networkProfileParameters,
 		nil,
 	)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return resp.NetworkProfile, nil
 }
 
 // Gets the specified network profile in a specified resource group.
-func GetNetworkProfile(ctx context.Context, networkProfileName string) error {
-	client := getNetworkProfilesClient()
-	_, err := client.Get(ctx, config.GroupName(), networkProfileName, nil)
+func GetNetworkProfile(ctx context.Context, networkProfileName string) (*armnetwork.NetworkProfile, error) {
+	client := getNetworkProfilesClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, networkProfileName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.NetworkProfile, nil
 }
 
 // Gets all the network profile in a subscription.
-func ListNetworkProfile(ctx context.Context) error {
-	client := getNetworkProfilesClient()
-	pager := client.List(config.GroupName(), nil)
+func ListNetworkProfile(ctx context.Context) ([]*armnetwork.NetworkProfile, error) {
+	client := getNetworkProfilesClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).GroupName, nil)
 
+	var results []*armnetwork.NetworkProfile
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().NetworkProfileListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Gets all the network profile in a subscription.
-func ListAllNetworkProfile(ctx context.Context) error {
-	client := getNetworkProfilesClient()
+func ListAllNetworkProfile(ctx context.Context) ([]*armnetwork.NetworkProfile, error) {
+	client := getNetworkProfilesClient(ctx)
 	pager := client.ListAll(nil)
+
+	var results []*armnetwork.NetworkProfile
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().NetworkProfileListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Updates network profile tags.
-func UpdateNetworkProfileTags(ctx context.Context, networkProfileName string, tagsObjectParameters armnetwork.TagsObject) error {
-	client := getNetworkProfilesClient()
-	_, err := client.UpdateTags(
+func UpdateNetworkProfileTags(ctx context.Context, networkProfileName string, tagsObjectParameters armnetwork.TagsObject) (*armnetwork.NetworkProfile, error) {
+	client := getNetworkProfilesClient(ctx)
+	resp, err := client.UpdateTags(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		networkProfileName,
 		tagsObjectParameters,
 		nil,
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.NetworkProfile, nil
 }
 
 // Deletes the specified network profile.
 func DeleteNetworkProfile(ctx context.Context, networkProfileName string) error {
-	client := getNetworkProfilesClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), networkProfileName, nil)
+	client := getNetworkProfilesClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, networkProfileName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
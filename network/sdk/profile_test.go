@@ -11,27 +11,28 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	storage "github.com/Azure-Samples/azure-sdk-for-go-samples/storage/sdk"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/armstorage"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
-	"github.com/marstr/randname"
 )
 
 func TestNetworkProfile(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	networkProfileName := config.AppendRandomSuffix("networkprofile")
 	virtualWanName := config.AppendRandomSuffix("virtualwan")
 	virtualHubName := config.AppendRandomSuffix("virtualhub")
-	storageAccountName := randname.Prefixed{Prefix: "storageaccount", Acceptable: randname.LowercaseAlphabet, Len: 5}.Generate()
+	storageAccountName := config.AppendRandomLowercaseSuffix("storageaccount")
 	virtualNetworkName := config.AppendRandomSuffix("virtualnetwork")
 	subnetName := config.AppendRandomSuffix("subnet")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 1000*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -136,25 +137,25 @@ func TestNetworkProfile(t *testing.T) {
 		},
 	}
 
-	err = CreateNetworkProfile(ctx, networkProfileName, networkProfileParameters)
+	_, err = CreateNetworkProfile(ctx, networkProfileName, networkProfileParameters)
 	if err != nil {
 		t.Fatalf("failed to create network profile: % +v", err)
 	}
 	t.Logf("created network profile")
 
-	err = GetNetworkProfile(ctx, networkProfileName)
+	_, err = GetNetworkProfile(ctx, networkProfileName)
 	if err != nil {
 		t.Fatalf("failed to get network profile: %+v", err)
 	}
 	t.Logf("got network profile")
 
-	err = ListNetworkProfile(ctx)
+	_, err = ListNetworkProfile(ctx)
 	if err != nil {
 		t.Fatalf("failed to list network profile: %+v", err)
 	}
 	t.Logf("listed network profile")
 
-	err = ListAllNetworkProfile(ctx)
+	_, err = ListAllNetworkProfile(ctx)
 	if err != nil {
 		t.Fatalf("failed to list all network profile: %+v", err)
 	}
@@ -163,7 +164,7 @@ func TestNetworkProfile(t *testing.T) {
 	tagsObjectParameters := armnetwork.TagsObject{
 		Tags: map[string]*string{"tag1": to.StringPtr("value1"), "tag2": to.StringPtr("value2")},
 	}
-	err = UpdateNetworkProfileTags(ctx, networkProfileName, tagsObjectParameters)
+	_, err = UpdateNetworkProfileTags(ctx, networkProfileName, tagsObjectParameters)
 	if err != nil {
 		t.Fatalf("failed to update tags for network profile: %+v", err)
 	}
@@ -11,26 +11,26 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getPublicIPAddressClient() armnetwork.PublicIPAddressesClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getPublicIPAddressClient(ctx context.Context) armnetwork.PublicIPAddressesClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewPublicIPAddressesClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewPublicIPAddressesClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Create public IP address
 func CreatePublicIPAddress(ctx context.Context, addressName string, publicIPAddress armnetwork.PublicIPAddress) (string, error) {
-	client := getPublicIPAddressClient()
+	client := getPublicIPAddressClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		addressName,
 		publicIPAddress,
 		nil,
@@ -40,7 +40,7 @@ func CreatePublicIPAddress(ctx context.Context, addressName string, publicIPAddr
 		return "", err
 	}
 
-	resp, err := poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return "", err
 	}
@@ -49,72 +49,71 @@ func CreatePublicIPAddress(ctx context.Context, addressName string, publicIPAddr
 }
 
 // Gets the specified public IP address in a specified resource group.
-func GetPublicIPAddress(ctx context.Context, addressName string) error {
-	client := getPublicIPAddressClient()
-	_, err := client.Get(ctx, config.GroupName(), addressName, nil)
+func GetPublicIPAddress(ctx context.Context, addressName string) (*armnetwork.PublicIPAddress, error) {
+	client := getPublicIPAddressClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, addressName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.PublicIPAddress, nil
 }
 
 // Gets all the public IP prefixes in a subscription.
-func ListPublicIPAddress(ctx context.Context) error {
-	client := getPublicIPAddressClient()
-	pager := client.List(config.GroupName(), nil)
+func ListPublicIPAddress(ctx context.Context) ([]*armnetwork.PublicIPAddress, error) {
+	client := getPublicIPAddressClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).GroupName, nil)
 
+	var results []*armnetwork.PublicIPAddress
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().PublicIPAddressListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Gets all the public IP addresses in a subscription.
-func ListAllPublicIPAddress(ctx context.Context) error {
-	client := getPublicIPAddressClient()
+func ListAllPublicIPAddress(ctx context.Context) ([]*armnetwork.PublicIPAddress, error) {
+	client := getPublicIPAddressClient(ctx)
 	pager := client.ListAll(nil)
+
+	var results []*armnetwork.PublicIPAddress
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().PublicIPAddressListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Updates public IP address tags.
-func UpdatePublicIPAddressTags(ctx context.Context, addressName string, tagsObjectParameters armnetwork.TagsObject) error {
-	client := getPublicIPAddressClient()
-	_, err := client.UpdateTags(
+func UpdatePublicIPAddressTags(ctx context.Context, addressName string, tagsObjectParameters armnetwork.TagsObject) (*armnetwork.PublicIPAddress, error) {
+	client := getPublicIPAddressClient(ctx)
+	resp, err := client.UpdateTags(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		addressName,
 		tagsObjectParameters,
 		nil,
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.PublicIPAddress, nil
 }
 
 // Deletes the specified public IP address.
 func DeletePublicIPAddress(ctx context.Context, addressName string) error {
-	client := getPublicIPAddressClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), addressName, nil)
+	client := getPublicIPAddressClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, addressName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
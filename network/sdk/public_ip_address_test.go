@@ -11,19 +11,21 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
 )
 
 func TestPublicIPAddress(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	publicIpAddressName := config.AppendRandomSuffix("pipaddress")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -52,19 +54,19 @@ func TestPublicIPAddress(t *testing.T) {
 	}
 	t.Logf("created public ip address")
 
-	err = GetPublicIPAddress(ctx, publicIpAddressName)
+	_, err = GetPublicIPAddress(ctx, publicIpAddressName)
 	if err != nil {
 		t.Fatalf("failed to get public ip address: %+v", err)
 	}
 	t.Logf("got public ip address")
 
-	err = ListPublicIPAddress(ctx)
+	_, err = ListPublicIPAddress(ctx)
 	if err != nil {
 		t.Fatalf("failed to list public ip address: %+v", err)
 	}
 	t.Logf("listed public ip address")
 
-	err = ListAllPublicIPAddress(ctx)
+	_, err = ListAllPublicIPAddress(ctx)
 	if err != nil {
 		t.Fatalf("failed to list all public ip address: %+v", err)
 	}
@@ -73,7 +75,7 @@ func TestPublicIPAddress(t *testing.T) {
 	tagsObjectParameters := armnetwork.TagsObject{
 		Tags: map[string]*string{"tag1": to.StringPtr("value1"), "tag2": to.StringPtr("value2")},
 	}
-	err = UpdatePublicIPAddressTags(ctx, publicIpAddressName, tagsObjectParameters)
+	_, err = UpdatePublicIPAddressTags(ctx, publicIpAddressName, tagsObjectParameters)
 	if err != nil {
 		t.Fatalf("failed to update tags for public ip address: %+v", err)
 	}
@@ -11,26 +11,26 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getPublicIPPrefixClient() armnetwork.PublicIPPrefixesClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getPublicIPPrefixClient(ctx context.Context) armnetwork.PublicIPPrefixesClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewPublicIPPrefixesClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewPublicIPPrefixesClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Create public IP prefix
 func CreatePublicIPPrefix(ctx context.Context, prefixName string, publicIPPrefixParameters armnetwork.PublicIPPrefix) (string, error) {
-	client := getPublicIPPrefixClient()
+	client := getPublicIPPrefixClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		prefixName,
 		publicIPPrefixParameters,
 		nil,
@@ -40,7 +40,7 @@ func CreatePublicIPPrefix(ctx context.Context, prefixName string, publicIPPrefix
 		return "", err
 	}
 
-	resp, err := poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return "", err
 	}
@@ -49,73 +49,71 @@ func CreatePublicIPPrefix(ctx context.Context, prefixName string, publicIPPrefix
 }
 
 // Gets the specified public IP prefix in a specified resource group.
-func GetPublicIPPrefix(ctx context.Context, prefixName string) error {
-	client := getPublicIPPrefixClient()
-	_, err := client.Get(ctx, config.GroupName(), prefixName, nil)
+func GetPublicIPPrefix(ctx context.Context, prefixName string) (*armnetwork.PublicIPPrefix, error) {
+	client := getPublicIPPrefixClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, prefixName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.PublicIPPrefix, nil
 }
 
 // Gets all public IP prefix in a resource group.
-func ListPublicIPPrefix(ctx context.Context) error {
-	client := getPublicIPPrefixClient()
-	pager := client.List(config.GroupName(), nil)
+func ListPublicIPPrefix(ctx context.Context) ([]*armnetwork.PublicIPPrefix, error) {
+	client := getPublicIPPrefixClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).GroupName, nil)
 
+	var results []*armnetwork.PublicIPPrefix
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().PublicIPPrefixListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Gets all the public IP prefix in a subscription.
-func ListAllPublicIPPrefix(ctx context.Context) error {
-	client := getPublicIPPrefixClient()
+func ListAllPublicIPPrefix(ctx context.Context) ([]*armnetwork.PublicIPPrefix, error) {
+	client := getPublicIPPrefixClient(ctx)
 	pager := client.ListAll(nil)
 
+	var results []*armnetwork.PublicIPPrefix
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().PublicIPPrefixListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Updates public IP prefix tags.
-func UpdatePublicIPPrefixTags(ctx context.Context, prefixName string, tagsObjectParameters armnetwork.TagsObject) error {
-	client := getPublicIPPrefixClient()
-	_, err := client.UpdateTags(
+func UpdatePublicIPPrefixTags(ctx context.Context, prefixName string, tagsObjectParameters armnetwork.TagsObject) (*armnetwork.PublicIPPrefix, error) {
+	client := getPublicIPPrefixClient(ctx)
+	resp, err := client.UpdateTags(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		prefixName,
 		tagsObjectParameters,
 		nil,
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.PublicIPPrefix, nil
 }
 
 // Deletes the specified public IP prefix.
 func DeletePublicIPPrefix(ctx context.Context, prefixName string) error {
-	client := getPublicIPPrefixClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), prefixName, nil)
+	client := getPublicIPPrefixClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, prefixName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
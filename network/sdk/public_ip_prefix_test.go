@@ -11,19 +11,21 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
 )
 
 func TestPublicIPPrefix(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	publicIpPrefixName := config.AppendRandomSuffix("pipperfix")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -50,19 +52,19 @@ func TestPublicIPPrefix(t *testing.T) {
 	}
 	t.Logf("created public ip prefix")
 
-	err = GetPublicIPPrefix(ctx, publicIpPrefixName)
+	_, err = GetPublicIPPrefix(ctx, publicIpPrefixName)
 	if err != nil {
 		t.Fatalf("failed to get public ip prefix: %+v", err)
 	}
 	t.Logf("got public ip prefix")
 
-	err = ListPublicIPPrefix(ctx)
+	_, err = ListPublicIPPrefix(ctx)
 	if err != nil {
 		t.Fatalf("failed to list public ip prefix: %+v", err)
 	}
 	t.Logf("listed public ip prefix")
 
-	err = ListAllPublicIPPrefix(ctx)
+	_, err = ListAllPublicIPPrefix(ctx)
 	if err != nil {
 		t.Fatalf("failed to list all public ip prefix: %+v", err)
 	}
@@ -71,7 +73,7 @@ func TestPublicIPPrefix(t *testing.T) {
 	tagsObjectParameters := armnetwork.TagsObject{
 		Tags: map[string]*string{"tag1": to.StringPtr("value1"), "tag2": to.StringPtr("value2")},
 	}
-	err = UpdatePublicIPPrefixTags(ctx, publicIpPrefixName, tagsObjectParameters)
+	_, err = UpdatePublicIPPrefixTags(ctx, publicIpPrefixName, tagsObjectParameters)
 	if err != nil {
 		t.Fatalf("failed to update tags for public ip prefix: %+v", err)
 	}
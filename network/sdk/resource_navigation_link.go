@@ -10,27 +10,26 @@ import (
 	"log"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getResourceNavigationLinksClient() armnetwork.ResourceNavigationLinksClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getResourceNavigationLinksClient(ctx context.Context) armnetwork.ResourceNavigationLinksClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewResourceNavigationLinksClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewResourceNavigationLinksClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Gets a list of resource navigation links for a subnet.
-func ListResourceNavigationLink(ctx context.Context, virtualNetworkName string, subnetName string) error {
-	client := getResourceNavigationLinksClient()
-	_, err := client.List(ctx, config.GroupName(), virtualNetworkName, subnetName, nil)
+func ListResourceNavigationLink(ctx context.Context, virtualNetworkName string, subnetName string) (*armnetwork.ResourceNavigationLinksListResult, error) {
+	client := getResourceNavigationLinksClient(ctx)
+	resp, err := client.List(ctx, config.ScopeFrom(ctx).GroupName, virtualNetworkName, subnetName, nil)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.ResourceNavigationLinksListResult, nil
 }
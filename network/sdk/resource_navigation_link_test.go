@@ -11,20 +11,22 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
 )
 
 func TestResourceNavigationLink(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	virtualNetworkName := config.AppendRandomSuffix("virtualnetwork")
 	subNetName := "Subnet"
 
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -58,7 +60,7 @@ func TestResourceNavigationLink(t *testing.T) {
 		t.Fatalf("failed to create sub net: % +v", err)
 	}
 
-	err = ListResourceNavigationLink(ctx, virtualNetworkName, subNetName)
+	_, err = ListResourceNavigationLink(ctx, virtualNetworkName, subNetName)
 	if err != nil {
 		t.Fatalf("failed to list resource navigation link: %+v", err)
 	}
@@ -11,26 +11,26 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getRoutesClient() armnetwork.RoutesClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getRoutesClient(ctx context.Context) armnetwork.RoutesClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewRoutesClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewRoutesClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Creates or updates a route in the specified route table.
-func CreateRoute(ctx context.Context, routeTableName string, routeName string, routeParameters armnetwork.Route) error {
-	client := getRoutesClient()
+func CreateRoute(ctx context.Context, routeTableName string, routeName string, routeParameters armnetwork.Route) (*armnetwork.Route, error) {
+	client := getRoutesClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		routeTableName,
 		routeName,
 		routeParameters,
@@ -38,51 +38,50 @@ func CreateRoute(ctx context.Context, routeTableName string, routeName string, r
 	)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.Route, nil
 }
 
 // Gets the specified route from a route table.
-func GetRoute(ctx context.Context, routeTableName string, routeName string) error {
-	client := getRoutesClient()
-	_, err := client.Get(ctx, config.GroupName(), routeTableName, routeName, nil)
+func GetRoute(ctx context.Context, routeTableName string, routeName string) (*armnetwork.Route, error) {
+	client := getRoutesClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, routeTableName, routeName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.Route, nil
 }
 
 // Gets all routes in a route table.
-func ListRoute(ctx context.Context, routeTableName string) error {
-	client := getRoutesClient()
-	pager := client.List(config.GroupName(), routeTableName, nil)
+func ListRoute(ctx context.Context, routeTableName string) ([]*armnetwork.Route, error) {
+	client := getRoutesClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).GroupName, routeTableName, nil)
 
+	var results []*armnetwork.Route
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().RouteListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Deletes the specified route from a route table.
 func DeleteRoute(ctx context.Context, routeTableName string, routeName string) error {
-	client := getRoutesClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), routeTableName, routeName, nil)
+	client := getRoutesClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, routeTableName, routeName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -11,109 +11,108 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getRouteFilterClient() armnetwork.RouteFiltersClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getRouteFilterClient(ctx context.Context) armnetwork.RouteFiltersClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewRouteFiltersClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewRouteFiltersClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Creates or updates a route filter in a specified resource group.
-func CreateRouteFilter(ctx context.Context, routeFilterName string, routeFilterParameters armnetwork.RouteFilter) error {
-	client := getRouteFilterClient()
+func CreateRouteFilter(ctx context.Context, routeFilterName string, routeFilterParameters armnetwork.RouteFilter) (*armnetwork.RouteFilter, error) {
+	client := getRouteFilterClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		routeFilterName,
 		routeFilterParameters,
 		nil,
 	)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.RouteFilter, nil
 }
 
 // Gets the specified route filter.
-func GetRouteFilter(ctx context.Context, routeFilterName string) error {
-	client := getRouteFilterClient()
-	_, err := client.Get(ctx, config.GroupName(), routeFilterName, nil)
+func GetRouteFilter(ctx context.Context, routeFilterName string) (*armnetwork.RouteFilter, error) {
+	client := getRouteFilterClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, routeFilterName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.RouteFilter, nil
 }
 
 // Gets all route filters in a subscription.
-func ListRouteFilter(ctx context.Context) error {
-	client := getRouteFilterClient()
+func ListRouteFilter(ctx context.Context) ([]*armnetwork.RouteFilter, error) {
+	client := getRouteFilterClient(ctx)
 	pager := client.List(nil)
 
+	var results []*armnetwork.RouteFilter
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().RouteFilterListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Gets all route filters in a resource group.
-func ListRouteFilterByResourceGroup(ctx context.Context) error {
-	client := getRouteFilterClient()
-	pager := client.ListByResourceGroup(config.GroupName(), nil)
+func ListRouteFilterByResourceGroup(ctx context.Context) ([]*armnetwork.RouteFilter, error) {
+	client := getRouteFilterClient(ctx)
+	pager := client.ListByResourceGroup(config.ScopeFrom(ctx).GroupName, nil)
+
+	var results []*armnetwork.RouteFilter
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().RouteFilterListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Updates route filter client tags.
-func UpdateRouteFilterTags(ctx context.Context, routeFilterName string, tagsObjectParameters armnetwork.TagsObject) error {
-	client := getRouteFilterClient()
-	_, err := client.UpdateTags(
+func UpdateRouteFilterTags(ctx context.Context, routeFilterName string, tagsObjectParameters armnetwork.TagsObject) (*armnetwork.RouteFilter, error) {
+	client := getRouteFilterClient(ctx)
+	resp, err := client.UpdateTags(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		routeFilterName,
 		tagsObjectParameters,
 		nil,
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.RouteFilter, nil
 }
 
 // Deletes the specified route filter client.
 func DeleteRouteFilter(ctx context.Context, routeFilterName string) error {
-	client := getRouteFilterClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), routeFilterName, nil)
+	client := getRouteFilterClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, routeFilterName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
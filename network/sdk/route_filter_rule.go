@@ -11,26 +11,26 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getRouteFilterRuleClient() armnetwork.RouteFilterRulesClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getRouteFilterRuleClient(ctx context.Context) armnetwork.RouteFilterRulesClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewRouteFilterRulesClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewRouteFilterRulesClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
-//Creates or updates a route in the specified route filter.
-func CreateRouteFilterRule(ctx context.Context, routeFilterName string, routeName string, routeFilterRuleParameters armnetwork.RouteFilterRule) error {
-	client := getRouteFilterRuleClient()
+// Creates or updates a route in the specified route filter.
+func CreateRouteFilterRule(ctx context.Context, routeFilterName string, routeName string, routeFilterRuleParameters armnetwork.RouteFilterRule) (*armnetwork.RouteFilterRule, error) {
+	client := getRouteFilterRuleClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		routeFilterName,
 		routeName,
 		routeFilterRuleParameters,
@@ -38,50 +38,50 @@ func CreateRouteFilterRule(ctx context.Context, routeFilterName string, routeNam
 	)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.RouteFilterRule, nil
 }
 
 // Gets the specified route filter rule.
-func GetRouteFilterRule(ctx context.Context, routeFilterName string, ruleName string) error {
-	client := getRouteFilterRuleClient()
-	_, err := client.Get(ctx, config.GroupName(), routeFilterName, ruleName, nil)
+func GetRouteFilterRule(ctx context.Context, routeFilterName string, ruleName string) (*armnetwork.RouteFilterRule, error) {
+	client := getRouteFilterRuleClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, routeFilterName, ruleName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.RouteFilterRule, nil
 }
 
 // Gets all RouteFilterRules in a route filter.
-func ListRouteFilterByRouteFilter(ctx context.Context, routeFilterName string) error {
-	client := getRouteFilterRuleClient()
-	pager := client.ListByRouteFilter(config.GroupName(), routeFilterName, nil)
+func ListRouteFilterByRouteFilter(ctx context.Context, routeFilterName string) ([]*armnetwork.RouteFilterRule, error) {
+	client := getRouteFilterRuleClient(ctx)
+	pager := client.ListByRouteFilter(config.ScopeFrom(ctx).GroupName, routeFilterName, nil)
+
+	var results []*armnetwork.RouteFilterRule
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().RouteFilterRuleListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Deletes the specified rule from a route filter.
 func DeleteRouteFilterRule(ctx context.Context, routeFilterName string, ruleName string) error {
-	client := getRouteFilterRuleClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), routeFilterName, ruleName, nil)
+	client := getRouteFilterRuleClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, routeFilterName, ruleName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
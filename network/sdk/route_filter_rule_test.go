@@ -11,20 +11,22 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
 )
 
 func TestRouteFilterRule(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	routeFilterName := config.AppendRandomSuffix("routefilter")
 	ruleName := config.AppendRandomSuffix("rule")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 1000*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -38,7 +40,7 @@ func TestRouteFilterRule(t *testing.T) {
 			Tags:     map[string]*string{"key1": to.StringPtr("value1")},
 		},
 	}
-	err = CreateRouteFilter(ctx, routeFilterName, routeFilterParameters)
+	_, err = CreateRouteFilter(ctx, routeFilterName, routeFilterParameters)
 	if err != nil {
 		t.Fatalf("failed to create route filter: %+v", err)
 	}
@@ -50,19 +52,19 @@ func TestRouteFilterRule(t *testing.T) {
 			RouteFilterRuleType: armnetwork.RouteFilterRuleTypeCommunity.ToPtr(),
 		},
 	}
-	err = CreateRouteFilterRule(ctx, routeFilterName, ruleName, routeFilterRuleParameters)
+	_, err = CreateRouteFilterRule(ctx, routeFilterName, ruleName, routeFilterRuleParameters)
 	if err != nil {
 		t.Fatalf("failed to create route filter rule: %+v", err)
 	}
 	t.Logf("created route filter rule")
 
-	err = GetRouteFilterRule(ctx, routeFilterName, ruleName)
+	_, err = GetRouteFilterRule(ctx, routeFilterName, ruleName)
 	if err != nil {
 		t.Fatalf("failed to get route filter rule: %+v", err)
 	}
 	t.Logf("got route filter rule")
 
-	err = ListRouteFilterByRouteFilter(ctx, routeFilterName)
+	_, err = ListRouteFilterByRouteFilter(ctx, routeFilterName)
 	if err != nil {
 		t.Fatalf("failed to list rule by route filter: %+v", err)
 	}
@@ -11,19 +11,21 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
 )
 
 func TestRouteFilter(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	routeFilterName := config.AppendRandomSuffix("routefilter")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -37,25 +39,25 @@ func TestRouteFilter(t *testing.T) {
 			Tags:     map[string]*string{"key1": to.StringPtr("value1")},
 		},
 	}
-	err = CreateRouteFilter(ctx, routeFilterName, routeFilterParameters)
+	_, err = CreateRouteFilter(ctx, routeFilterName, routeFilterParameters)
 	if err != nil {
 		t.Fatalf("failed to create route filter: %+v", err)
 	}
 	t.Logf("created route filter")
 
-	err = GetRouteFilter(ctx, routeFilterName)
+	_, err = GetRouteFilter(ctx, routeFilterName)
 	if err != nil {
 		t.Fatalf("failed to get route filter: %+v", err)
 	}
 	t.Logf("got route filter")
 
-	err = ListRouteFilter(ctx)
+	_, err = ListRouteFilter(ctx)
 	if err != nil {
 		t.Fatalf("failed to list route filter: %+v", err)
 	}
 	t.Logf("listed route filter")
 
-	err = ListRouteFilterByResourceGroup(ctx)
+	_, err = ListRouteFilterByResourceGroup(ctx)
 	if err != nil {
 		t.Fatalf("failed to list route filters by resource group: %+v", err)
 	}
@@ -64,7 +66,7 @@ func TestRouteFilter(t *testing.T) {
 	tagsObjectParameters := armnetwork.TagsObject{
 		Tags: map[string]*string{"tag1": to.StringPtr("value1"), "tag2": to.StringPtr("value2")},
 	}
-	err = UpdateRouteFilterTags(ctx, routeFilterName, tagsObjectParameters)
+	_, err = UpdateRouteFilterTags(ctx, routeFilterName, tagsObjectParameters)
 	if err != nil {
 		t.Fatalf("failed to update tags for route filter: %+v", err)
 	}
@@ -11,112 +11,111 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
 )
 
-func getRouteTablesClient() armnetwork.RouteTablesClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getRouteTablesClient(ctx context.Context) armnetwork.RouteTablesClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewRouteTablesClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewRouteTablesClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Create RouteTables
-func CreateRouteTable(ctx context.Context, routeTableName string) error {
-	client := getRouteTablesClient()
+func CreateRouteTable(ctx context.Context, routeTableName string) (*armnetwork.RouteTable, error) {
+	client := getRouteTablesClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		routeTableName,
 		armnetwork.RouteTable{
-			Resource: armnetwork.Resource{Location: to.StringPtr(config.Location())},
+			Resource: armnetwork.Resource{Location: to.StringPtr(config.ScopeFrom(ctx).Location)},
 		},
 		nil,
 	)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.RouteTable, nil
 }
 
 // Gets the specified route table in a specified resource group.
-func GetRouteTable(ctx context.Context, routeTableName string) error {
-	client := getRouteTablesClient()
-	_, err := client.Get(ctx, config.GroupName(), routeTableName, nil)
+func GetRouteTable(ctx context.Context, routeTableName string) (*armnetwork.RouteTable, error) {
+	client := getRouteTablesClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, routeTableName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.RouteTable, nil
 }
 
 // Gets all the route table in a subscription.
-func ListRouteTable(ctx context.Context) error {
-	client := getRouteTablesClient()
-	pager := client.List(config.GroupName(), nil)
+func ListRouteTable(ctx context.Context) ([]*armnetwork.RouteTable, error) {
+	client := getRouteTablesClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).GroupName, nil)
 
+	var results []*armnetwork.RouteTable
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().RouteTableListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Gets all the route table in a subscription.
-func ListAllRouteTable(ctx context.Context) error {
-	client := getRouteTablesClient()
+func ListAllRouteTable(ctx context.Context) ([]*armnetwork.RouteTable, error) {
+	client := getRouteTablesClient(ctx)
 	pager := client.ListAll(nil)
+
+	var results []*armnetwork.RouteTable
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().RouteTableListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Updates route table tags.
-func UpdateRouteTableTags(ctx context.Context, routeTableName string, tagsObjectParameters armnetwork.TagsObject) error {
-	client := getRouteTablesClient()
-	_, err := client.UpdateTags(
+func UpdateRouteTableTags(ctx context.Context, routeTableName string, tagsObjectParameters armnetwork.TagsObject) (*armnetwork.RouteTable, error) {
+	client := getRouteTablesClient(ctx)
+	resp, err := client.UpdateTags(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		routeTableName,
 		tagsObjectParameters,
 		nil,
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.RouteTable, nil
 }
 
 // Deletes the specified route table.
 func DeleteRouteTable(ctx context.Context, routeTableName string) error {
-	client := getRouteTablesClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), routeTableName, nil)
+	client := getRouteTablesClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, routeTableName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
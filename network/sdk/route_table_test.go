@@ -11,19 +11,21 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
 )
 
 func TestRouteTable(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	routeTableName := config.AppendRandomSuffix("routetable")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -31,25 +33,25 @@ func TestRouteTable(t *testing.T) {
 		t.Fatalf("failed to create group: %+v", err)
 	}
 
-	err = CreateRouteTable(ctx, routeTableName)
+	_, err = CreateRouteTable(ctx, routeTableName)
 	if err != nil {
 		t.Fatalf("failed to create route table: % +v", err)
 	}
 	t.Logf("created route table")
 
-	err = GetRouteTable(ctx, routeTableName)
+	_, err = GetRouteTable(ctx, routeTableName)
 	if err != nil {
 		t.Fatalf("failed to get route table: %+v", err)
 	}
 	t.Logf("got route table")
 
-	err = ListRouteTable(ctx)
+	_, err = ListRouteTable(ctx)
 	if err != nil {
 		t.Fatalf("failed to list route table: %+v", err)
 	}
 	t.Logf("listed route table")
 
-	err = ListAllRouteTable(ctx)
+	_, err = ListAllRouteTable(ctx)
 	if err != nil {
 		t.Fatalf("failed to list all route table: %+v", err)
 	}
@@ -58,7 +60,7 @@ func TestRouteTable(t *testing.T) {
 	tagsObjectParameters := armnetwork.TagsObject{
 		Tags: map[string]*string{"tag1": to.StringPtr("value1"), "tag2": to.StringPtr("value2")},
 	}
-	err = UpdateRouteTableTags(ctx, routeTableName, tagsObjectParameters)
+	_, err = UpdateRouteTableTags(ctx, routeTableName, tagsObjectParameters)
 	if err != nil {
 		t.Fatalf("failed to update tags for route table: %+v", err)
 	}
@@ -11,20 +11,22 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
 )
 
 func TestRoute(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	routeTableName := config.AppendRandomSuffix("routetable")
 	routeName := config.AppendRandomSuffix("route")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -32,7 +34,7 @@ func TestRoute(t *testing.T) {
 		t.Fatalf("failed to create group: %+v", err)
 	}
 
-	err = CreateRouteTable(ctx, routeTableName)
+	_, err = CreateRouteTable(ctx, routeTableName)
 	if err != nil {
 		t.Fatalf("failed to create route table: % +v", err)
 	}
@@ -44,19 +46,19 @@ func TestRoute(t *testing.T) {
 			NextHopType:   armnetwork.RouteNextHopTypeVirtualNetworkGateway.ToPtr(),
 		},
 	}
-	err = CreateRoute(ctx, routeTableName, routeName, routeParameters)
+	_, err = CreateRoute(ctx, routeTableName, routeName, routeParameters)
 	if err != nil {
 		t.Fatalf("failed to create route: % +v", err)
 	}
 	t.Logf("created route")
 
-	err = GetRoute(ctx, routeTableName, routeName)
+	_, err = GetRoute(ctx, routeTableName, routeName)
 	if err != nil {
 		t.Fatalf("failed to get route: %+v", err)
 	}
 	t.Logf("got route")
 
-	err = ListRoute(ctx, routeTableName)
+	_, err = ListRoute(ctx, routeTableName)
 	if err != nil {
 		t.Fatalf("failed to list route: %+v", err)
 	}
@@ -11,26 +11,26 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getNetworkSecurityGroupsClient() armnetwork.NetworkSecurityGroupsClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getNetworkSecurityGroupsClient(ctx context.Context) armnetwork.NetworkSecurityGroupsClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewNetworkSecurityGroupsClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewNetworkSecurityGroupsClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Create NetworkSecurityGroups
 func CreateNetworkSecurityGroup(ctx context.Context, networkSecurityGroupName string, networkSecurityGroupParameters armnetwork.NetworkSecurityGroup) (string, error) {
-	client := getNetworkSecurityGroupsClient()
+	client := getNetworkSecurityGroupsClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		networkSecurityGroupName,
 		networkSecurityGroupParameters,
 		nil,
@@ -40,7 +40,7 @@ func CreateNetworkSecurityGroup(ctx context.Context, networkSecurityGroupName st
 		return "", err
 	}
 
-	resp, err := poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return "", err
 	}
@@ -49,72 +49,71 @@ func CreateNetworkSecurityGroup(ctx context.Context, networkSecurityGroupName st
 }
 
 // Gets the specified network security group in a specified resource group.
-func GetNetworkSecurityGroup(ctx context.Context, networkSecurityGroupName string) error {
-	client := getNetworkSecurityGroupsClient()
-	_, err := client.Get(ctx, config.GroupName(), networkSecurityGroupName, nil)
+func GetNetworkSecurityGroup(ctx context.Context, networkSecurityGroupName string) (*armnetwork.NetworkSecurityGroup, error) {
+	client := getNetworkSecurityGroupsClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, networkSecurityGroupName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.NetworkSecurityGroup, nil
 }
 
 // Gets all the network security group in a subscription.
-func ListNetworkSecurityGroup(ctx context.Context) error {
-	client := getNetworkSecurityGroupsClient()
-	pager := client.List(config.GroupName(), nil)
+func ListNetworkSecurityGroup(ctx context.Context) ([]*armnetwork.NetworkSecurityGroup, error) {
+	client := getNetworkSecurityGroupsClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).GroupName, nil)
 
+	var results []*armnetwork.NetworkSecurityGroup
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().NetworkSecurityGroupListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Gets all the network security group in a subscription.
-func ListAllNetworkSecurityGroup(ctx context.Context) error {
-	client := getNetworkSecurityGroupsClient()
+func ListAllNetworkSecurityGroup(ctx context.Context) ([]*armnetwork.NetworkSecurityGroup, error) {
+	client := getNetworkSecurityGroupsClient(ctx)
 	pager := client.ListAll(nil)
+
+	var results []*armnetwork.NetworkSecurityGroup
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().NetworkSecurityGroupListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Updates network security group tags.
-func UpdateNetworkSecurityGroupTags(ctx context.Context, networkSecurityGroupName string, tagsObjectParameters armnetwork.TagsObject) error {
-	client := getNetworkSecurityGroupsClient()
-	_, err := client.UpdateTags(
+func UpdateNetworkSecurityGroupTags(ctx context.Context, networkSecurityGroupName string, tagsObjectParameters armnetwork.TagsObject) (*armnetwork.NetworkSecurityGroup, error) {
+	client := getNetworkSecurityGroupsClient(ctx)
+	resp, err := client.UpdateTags(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		networkSecurityGroupName,
 		tagsObjectParameters,
 		nil,
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.NetworkSecurityGroup, nil
 }
 
 // Deletes the specified network security group.
 func DeleteNetworkSecurityGroup(ctx context.Context, networkSecurityGroupName string) error {
-	client := getNetworkSecurityGroupsClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), networkSecurityGroupName, nil)
+	client := getNetworkSecurityGroupsClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, networkSecurityGroupName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -11,19 +11,21 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
 )
 
 func TestNetworkSecurityGroup(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	networkSecurityGroupName := config.AppendRandomSuffix("networksecuritygroup")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -42,19 +44,19 @@ func TestNetworkSecurityGroup(t *testing.T) {
 	}
 	t.Logf("created network security group")
 
-	err = GetNetworkSecurityGroup(ctx, networkSecurityGroupName)
+	_, err = GetNetworkSecurityGroup(ctx, networkSecurityGroupName)
 	if err != nil {
 		t.Fatalf("failed to get network security group: %+v", err)
 	}
 	t.Logf("got network security group")
 
-	err = ListNetworkSecurityGroup(ctx)
+	_, err = ListNetworkSecurityGroup(ctx)
 	if err != nil {
 		t.Fatalf("failed to list network security group: %+v", err)
 	}
 	t.Logf("listed network security group")
 
-	err = ListAllNetworkSecurityGroup(ctx)
+	_, err = ListAllNetworkSecurityGroup(ctx)
 	if err != nil {
 		t.Fatalf("failed to list all network security group: %+v", err)
 	}
@@ -63,7 +65,7 @@ func TestNetworkSecurityGroup(t *testing.T) {
 	tagsObjectParameters := armnetwork.TagsObject{
 		Tags: map[string]*string{"tag1": to.StringPtr("value1"), "tag2": to.StringPtr("value2")},
 	}
-	err = UpdateNetworkSecurityGroupTags(ctx, networkSecurityGroupName, tagsObjectParameters)
+	_, err = UpdateNetworkSecurityGroupTags(ctx, networkSecurityGroupName, tagsObjectParameters)
 	if err != nil {
 		t.Fatalf("failed to update tags for network security group: %+v", err)
 	}
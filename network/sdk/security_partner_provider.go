@@ -11,93 +11,92 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getSecurityPartnerProvidersClient() armnetwork.SecurityPartnerProvidersClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getSecurityPartnerProvidersClient(ctx context.Context) armnetwork.SecurityPartnerProvidersClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewSecurityPartnerProvidersClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewSecurityPartnerProvidersClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Creates or updates the specified Security Partner Provider
-func CreateSecurityPartnerProvider(ctx context.Context, securityPartnerProviderName string, securityPartnerProviderParameters armnetwork.SecurityPartnerProvider) error {
-	client := getSecurityPartnerProvidersClient()
+func CreateSecurityPartnerProvider(ctx context.Context, securityPartnerProviderName string, securityPartnerProviderParameters armnetwork.SecurityPartnerProvider) (*armnetwork.SecurityPartnerProvider, error) {
+	client := getSecurityPartnerProvidersClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		securityPartnerProviderName,
 		securityPartnerProviderParameters,
 		nil,
 	)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.SecurityPartnerProvider, nil
 }
 
 // Gets the specified Security Partner Provider.
-func GetSecurityPartnerProvider(ctx context.Context, securityPartnerProviderName string) error {
-	client := getSecurityPartnerProvidersClient()
-	_, err := client.Get(ctx, config.GroupName(), securityPartnerProviderName, nil)
+func GetSecurityPartnerProvider(ctx context.Context, securityPartnerProviderName string) (*armnetwork.SecurityPartnerProvider, error) {
+	client := getSecurityPartnerProvidersClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, securityPartnerProviderName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.SecurityPartnerProvider, nil
 }
 
 // Gets all the Security Partner Providers in a subscription.
-func ListSecurityPartnerProvider(ctx context.Context) error {
-	client := getSecurityPartnerProvidersClient()
+func ListSecurityPartnerProvider(ctx context.Context) ([]*armnetwork.SecurityPartnerProvider, error) {
+	client := getSecurityPartnerProvidersClient(ctx)
 	pager := client.List(nil)
 
+	var results []*armnetwork.SecurityPartnerProvider
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().SecurityPartnerProviderListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Updates tags of a Security Partner Provider resource
-func UpdateSecurityPartnerProviderTags(ctx context.Context, securityPartnerProviderName string, tagsObjectParameters armnetwork.TagsObject) error {
-	client := getSecurityPartnerProvidersClient()
-	_, err := client.UpdateTags(
+func UpdateSecurityPartnerProviderTags(ctx context.Context, securityPartnerProviderName string, tagsObjectParameters armnetwork.TagsObject) (*armnetwork.SecurityPartnerProvider, error) {
+	client := getSecurityPartnerProvidersClient(ctx)
+	resp, err := client.UpdateTags(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		securityPartnerProviderName,
 		tagsObjectParameters,
 		nil,
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.SecurityPartnerProvider, nil
 }
 
-//  Deletes the specified Security Partner Provider
+// Deletes the specified Security Partner Provider
 func DeleteSecurityPartnerProvider(ctx context.Context, securityPartnerProviderName string) error {
-	client := getSecurityPartnerProvidersClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), securityPartnerProviderName, nil)
+	client := getSecurityPartnerProvidersClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, securityPartnerProviderName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -105,17 +104,17 @@ func DeleteSecurityPartnerProvider(ctx context.Context, securityPartnerProviderN
 }
 
 // Lists all Security Partner Providers in a resource group.
-func ListSecurityPartnerProviderByResourceGroup(ctx context.Context) error {
-	client := getSecurityPartnerProvidersClient()
-	pager := client.ListByResourceGroup(config.GroupName(), nil)
+func ListSecurityPartnerProviderByResourceGroup(ctx context.Context) ([]*armnetwork.SecurityPartnerProvider, error) {
+	client := getSecurityPartnerProvidersClient(ctx)
+	pager := client.ListByResourceGroup(config.ScopeFrom(ctx).GroupName, nil)
+
+	var results []*armnetwork.SecurityPartnerProvider
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().SecurityPartnerProviderListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
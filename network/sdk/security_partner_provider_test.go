@@ -11,15 +11,16 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
 )
 
 func TestSecurityPartnerProvider(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	securityPartnerProviderName := config.AppendRandomSuffix("securitypartnerprovider")
 	virtualWanName := config.AppendRandomSuffix("virtualwan")
 	virtualHubName := config.AppendRandomSuffix("virtualhub")
@@ -29,6 +30,7 @@ func TestSecurityPartnerProvider(t *testing.T) {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3000*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -143,7 +145,7 @@ func TestSecurityPartnerProvider(t *testing.T) {
 			},
 		},
 	}
-	err = CreateVpnGateway(ctx, vpnGatewayName, vpnGatewayParameters)
+	_, err = CreateVpnGateway(ctx, vpnGatewayName, vpnGatewayParameters)
 	if err != nil {
 		t.Fatalf("failed to create vpn gateway: % +v", err)
 	}
@@ -160,25 +162,25 @@ func TestSecurityPartnerProvider(t *testing.T) {
 			},
 		},
 	}
-	err = CreateSecurityPartnerProvider(ctx, securityPartnerProviderName, securityPartnerProviderParameters)
+	_, err = CreateSecurityPartnerProvider(ctx, securityPartnerProviderName, securityPartnerProviderParameters)
 	if err != nil {
 		t.Fatalf("failed to create security partner provider: % +v", err)
 	}
 	t.Logf("created security partner provider")
 
-	err = GetSecurityPartnerProvider(ctx, securityPartnerProviderName)
+	_, err = GetSecurityPartnerProvider(ctx, securityPartnerProviderName)
 	if err != nil {
 		t.Fatalf("failed to get security partner provider: %+v", err)
 	}
 	t.Logf("got security partner provider")
 
-	err = ListSecurityPartnerProvider(ctx)
+	_, err = ListSecurityPartnerProvider(ctx)
 	if err != nil {
 		t.Fatalf("failed to list security partner provider: %+v", err)
 	}
 	t.Logf("listed security partner provider")
 
-	err = ListSecurityPartnerProviderByResourceGroup(ctx)
+	_, err = ListSecurityPartnerProviderByResourceGroup(ctx)
 	if err != nil {
 		t.Fatalf("failed to list security partner provider by resource group: %+v", err)
 	}
@@ -187,7 +189,7 @@ func TestSecurityPartnerProvider(t *testing.T) {
 	tagsObjectParameters := armnetwork.TagsObject{
 		Tags: map[string]*string{"tag1": to.StringPtr("value1"), "tag2": to.StringPtr("value2")},
 	}
-	err = UpdateSecurityPartnerProviderTags(ctx, securityPartnerProviderName, tagsObjectParameters)
+	_, err = UpdateSecurityPartnerProviderTags(ctx, securityPartnerProviderName, tagsObjectParameters)
 	if err != nil {
 		t.Fatalf("failed to update tags for security partner provider: %+v", err)
 	}
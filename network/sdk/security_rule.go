@@ -11,26 +11,26 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getSecurityRulesClient() armnetwork.SecurityRulesClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getSecurityRulesClient(ctx context.Context) armnetwork.SecurityRulesClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewSecurityRulesClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewSecurityRulesClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Create SecurityRules
-func CreateSecurityRule(ctx context.Context, networkSecurityGroupName string, securityRuleName string, securityRuleParameters armnetwork.SecurityRule) error {
-	client := getSecurityRulesClient()
+func CreateSecurityRule(ctx context.Context, networkSecurityGroupName string, securityRuleName string, securityRuleParameters armnetwork.SecurityRule) (*armnetwork.SecurityRule, error) {
+	client := getSecurityRulesClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		networkSecurityGroupName,
 		securityRuleName,
 		securityRuleParameters,
@@ -38,51 +38,50 @@ func CreateSecurityRule(ctx context.Context, networkSecurityGroupName string, se
 	)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.SecurityRule, nil
 }
 
-//  Get the specified network security rule.
-func GetSecurityRule(ctx context.Context, networkSecurityGroupName string, securityRuleName string) error {
-	client := getSecurityRulesClient()
-	_, err := client.Get(ctx, config.GroupName(), networkSecurityGroupName, securityRuleName, nil)
+// Get the specified network security rule.
+func GetSecurityRule(ctx context.Context, networkSecurityGroupName string, securityRuleName string) (*armnetwork.SecurityRule, error) {
+	client := getSecurityRulesClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, networkSecurityGroupName, securityRuleName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.SecurityRule, nil
 }
 
 // Gets all security rules in a network security group.
-func ListSecurityRule(ctx context.Context, networkSecurityGroupName string) error {
-	client := getSecurityRulesClient()
-	pager := client.List(config.GroupName(), networkSecurityGroupName, nil)
+func ListSecurityRule(ctx context.Context, networkSecurityGroupName string) ([]*armnetwork.SecurityRule, error) {
+	client := getSecurityRulesClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).GroupName, networkSecurityGroupName, nil)
 
+	var results []*armnetwork.SecurityRule
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().SecurityRuleListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Deletes the specified network security rule.
 func DeleteSecurityRule(ctx context.Context, networkSecurityGroupName string, securityRuleName string) error {
-	client := getSecurityRulesClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), networkSecurityGroupName, securityRuleName, nil)
+	client := getSecurityRulesClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, networkSecurityGroupName, securityRuleName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
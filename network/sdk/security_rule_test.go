@@ -11,20 +11,22 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/go-autorest/autorest/to"
 )
 
 func TestSecurityRule(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	securityRuleName := config.AppendRandomSuffix("securityrule")
 	networkSecurityGroupName := config.AppendRandomSuffix("networksecuritygroup")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -54,19 +56,19 @@ func TestSecurityRule(t *testing.T) {
 			SourcePortRange:          to.StringPtr("*"),
 		},
 	}
-	err = CreateSecurityRule(ctx, networkSecurityGroupName, securityRuleName, securityRuleParameters)
+	_, err = CreateSecurityRule(ctx, networkSecurityGroupName, securityRuleName, securityRuleParameters)
 	if err != nil {
 		t.Fatalf("failed to create security rule: % +v", err)
 	}
 	t.Logf("created security rule")
 
-	err = GetSecurityRule(ctx, networkSecurityGroupName, securityRuleName)
+	_, err = GetSecurityRule(ctx, networkSecurityGroupName, securityRuleName)
 	if err != nil {
 		t.Fatalf("failed to get security rule: %+v", err)
 	}
 	t.Logf("got security rule")
 
-	err = ListSecurityRule(ctx, networkSecurityGroupName)
+	_, err = ListSecurityRule(ctx, networkSecurityGroupName)
 	if err != nil {
 		t.Fatalf("failed to list security rule: %+v", err)
 	}
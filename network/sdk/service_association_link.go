@@ -10,28 +10,27 @@ import (
 	"log"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getServiceAssociationLinksClient() armnetwork.ServiceAssociationLinksClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getServiceAssociationLinksClient(ctx context.Context) armnetwork.ServiceAssociationLinksClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewServiceAssociationLinksClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewServiceAssociationLinksClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
-//  Gets a list of service association links for a subnet.
-func ListServiceAssociationLink(ctx context.Context, virtualNetworkName string, subnetName string) error {
-	client := getServiceAssociationLinksClient()
-	_, err := client.List(ctx, config.GroupName(), virtualNetworkName, subnetName, nil)
+// Gets a list of service association links for a subnet.
+func ListServiceAssociationLink(ctx context.Context, virtualNetworkName string, subnetName string) (*armnetwork.ServiceAssociationLinksListResult, error) {
+	client := getServiceAssociationLinksClient(ctx)
+	resp, err := client.List(ctx, config.ScopeFrom(ctx).GroupName, virtualNetworkName, subnetName, nil)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return resp.ServiceAssociationLinksListResult, nil
 }
@@ -11,98 +11,97 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
 )
 
-func getServiceEndpointPoliciesClient() armnetwork.ServiceEndpointPoliciesClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getServiceEndpointPoliciesClient(ctx context.Context) armnetwork.ServiceEndpointPoliciesClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewServiceEndpointPoliciesClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewServiceEndpointPoliciesClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
-//  Creates or updates a service Endpoint Policies.
-func CreateServiceEndpointPolicy(ctx context.Context, serviceEndpointPolicyName string) error {
-	client := getServiceEndpointPoliciesClient()
+// Creates or updates a service Endpoint Policies.
+func CreateServiceEndpointPolicy(ctx context.Context, serviceEndpointPolicyName string) (*armnetwork.ServiceEndpointPolicy, error) {
+	client := getServiceEndpointPoliciesClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		serviceEndpointPolicyName,
 		armnetwork.ServiceEndpointPolicy{
 			Resource: armnetwork.Resource{
-				Location: to.StringPtr(config.Location()),
+				Location: to.StringPtr(config.ScopeFrom(ctx).Location),
 			},
 		},
 		nil,
 	)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.ServiceEndpointPolicy, nil
 }
 
 // Gets the specified service endpoint policy in a specified resource group.
-func GetServiceEndpointPolicy(ctx context.Context, serviceEndpointPolicyName string) error {
-	client := getServiceEndpointPoliciesClient()
-	_, err := client.Get(ctx, config.GroupName(), serviceEndpointPolicyName, nil)
+func GetServiceEndpointPolicy(ctx context.Context, serviceEndpointPolicyName string) (*armnetwork.ServiceEndpointPolicy, error) {
+	client := getServiceEndpointPoliciesClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, serviceEndpointPolicyName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.ServiceEndpointPolicy, nil
 }
 
 // Gets all the service endpoint policies in a subscription.
-func ListServiceEndpointPolicy(ctx context.Context) error {
-	client := getServiceEndpointPoliciesClient()
+func ListServiceEndpointPolicy(ctx context.Context) ([]*armnetwork.ServiceEndpointPolicy, error) {
+	client := getServiceEndpointPoliciesClient(ctx)
 	pager := client.List(nil)
 
+	var results []*armnetwork.ServiceEndpointPolicy
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().ServiceEndpointPolicyListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Updates service endpoint policy tags.
-func UpdateServiceEndpointPolicyTags(ctx context.Context, serviceEndpointPolicyName string, tagsObjectParameters armnetwork.TagsObject) error {
-	client := getServiceEndpointPoliciesClient()
-	_, err := client.UpdateTags(
+func UpdateServiceEndpointPolicyTags(ctx context.Context, serviceEndpointPolicyName string, tagsObjectParameters armnetwork.TagsObject) (*armnetwork.ServiceEndpointPolicy, error) {
+	client := getServiceEndpointPoliciesClient(ctx)
+	resp, err := client.UpdateTags(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		serviceEndpointPolicyName,
 		tagsObjectParameters,
 		nil,
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.ServiceEndpointPolicy, nil
 }
 
 // Deletes the specified service endpoint policy.
 func DeleteServiceEndpointPolicy(ctx context.Context, serviceEndpointPolicyName string) error {
-	client := getServiceEndpointPoliciesClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), serviceEndpointPolicyName, nil)
+	client := getServiceEndpointPoliciesClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, serviceEndpointPolicyName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -110,17 +109,17 @@ func DeleteServiceEndpointPolicy(ctx context.Context, serviceEndpointPolicyName
 }
 
 // Gets all service endpoint policy in a resource group.
-func ListServiceEndpointPolicyByResourceGroup(ctx context.Context) error {
-	client := getServiceEndpointPoliciesClient()
-	pager := client.ListByResourceGroup(config.GroupName(), nil)
+func ListServiceEndpointPolicyByResourceGroup(ctx context.Context) ([]*armnetwork.ServiceEndpointPolicy, error) {
+	client := getServiceEndpointPoliciesClient(ctx)
+	pager := client.ListByResourceGroup(config.ScopeFrom(ctx).GroupName, nil)
+
+	var results []*armnetwork.ServiceEndpointPolicy
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().ServiceEndpointPolicyListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
@@ -11,27 +11,27 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getServiceEndpointPolicyDefinitionsClient() armnetwork.ServiceEndpointPolicyDefinitionsClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getServiceEndpointPolicyDefinitionsClient(ctx context.Context) armnetwork.ServiceEndpointPolicyDefinitionsClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewServiceEndpointPolicyDefinitionsClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewServiceEndpointPolicyDefinitionsClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Create ServiceEndpointPolicyDefinitions
-func CreateServiceEndpointPolicyDefinition(ctx context.Context, serviceEndpointPolicyName string, serviceEndpointPolicyDefinitionName string, serviceEndpointPolicyDefinitionParameters armnetwork.ServiceEndpointPolicyDefinition) error {
-	client := getServiceEndpointPolicyDefinitionsClient()
+func CreateServiceEndpointPolicyDefinition(ctx context.Context, serviceEndpointPolicyName string, serviceEndpointPolicyDefinitionName string, serviceEndpointPolicyDefinitionParameters armnetwork.ServiceEndpointPolicyDefinition) (*armnetwork.ServiceEndpointPolicyDefinition, error) {
+	client := getServiceEndpointPolicyDefinitionsClient(ctx)
 
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		serviceEndpointPolicyName,
 		serviceEndpointPolicyDefinitionName,
 		serviceEndpointPolicyDefinitionParameters,
@@ -39,34 +39,34 @@ func CreateServiceEndpointPolicyDefinition(ctx context.Context, serviceEndpointP
 	)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.ServiceEndpointPolicyDefinition, nil
 }
 
 // Gets the specified service endpoint policy definition in a specified resource group.
-func GetServiceEndpointPolicyDefinition(ctx context.Context, serviceEndpointPolicyName string, serviceEndpointPolicyDefinitionName string) error {
-	client := getServiceEndpointPolicyDefinitionsClient()
-	_, err := client.Get(ctx, config.GroupName(), serviceEndpointPolicyName, serviceEndpointPolicyDefinitionName, nil)
+func GetServiceEndpointPolicyDefinition(ctx context.Context, serviceEndpointPolicyName string, serviceEndpointPolicyDefinitionName string) (*armnetwork.ServiceEndpointPolicyDefinition, error) {
+	client := getServiceEndpointPolicyDefinitionsClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, serviceEndpointPolicyName, serviceEndpointPolicyDefinitionName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.ServiceEndpointPolicyDefinition, nil
 }
 
 // Deletes the specified service endpoint policy definition.
 func DeleteServiceEndpointPolicyDefinition(ctx context.Context, serviceEndpointPolicyName string, serviceEndpointPolicyDefinitionName string) error {
-	client := getServiceEndpointPolicyDefinitionsClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), serviceEndpointPolicyName, serviceEndpointPolicyDefinitionName, nil)
+	client := getServiceEndpointPolicyDefinitionsClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, serviceEndpointPolicyName, serviceEndpointPolicyDefinitionName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -74,17 +74,17 @@ func DeleteServiceEndpointPolicyDefinition(ctx context.Context, serviceEndpointP
 }
 
 // Gets all service endpoint policy definition in a resource group.
-func ListServiceEndpointPolicyDefinitionByResourceGroup(ctx context.Context, serviceEndpointPolicyName string) error {
-	client := getServiceEndpointPolicyDefinitionsClient()
-	pager := client.ListByResourceGroup(config.GroupName(), serviceEndpointPolicyName, nil)
+func ListServiceEndpointPolicyDefinitionByResourceGroup(ctx context.Context, serviceEndpointPolicyName string) ([]*armnetwork.ServiceEndpointPolicyDefinition, error) {
+	client := getServiceEndpointPolicyDefinitionsClient(ctx)
+	pager := client.ListByResourceGroup(config.ScopeFrom(ctx).GroupName, serviceEndpointPolicyName, nil)
+
+	var results []*armnetwork.ServiceEndpointPolicyDefinition
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().ServiceEndpointPolicyDefinitionListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
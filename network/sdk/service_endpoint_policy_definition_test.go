@@ -11,20 +11,23 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/resourceid"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
 )
 
 func TestServiceEndpointPolicyDefinition(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	serviceEndpointPolicyName := config.AppendRandomSuffix("serviceendpointpolicy")
 	serviceEndpointPolicyDefinitionName := config.AppendRandomSuffix("serviceendpointpolicydefinition")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -32,7 +35,7 @@ func TestServiceEndpointPolicyDefinition(t *testing.T) {
 		t.Fatalf("failed to create group: %+v", err)
 	}
 
-	err = CreateServiceEndpointPolicy(ctx, serviceEndpointPolicyName)
+	_, err = CreateServiceEndpointPolicy(ctx, serviceEndpointPolicyName)
 	if err != nil {
 		t.Fatalf("failed to create service endpoint policy: % +v", err)
 	}
@@ -42,22 +45,22 @@ func TestServiceEndpointPolicyDefinition(t *testing.T) {
 		Properties: &armnetwork.ServiceEndpointPolicyDefinitionPropertiesFormat{
 			Description:      to.StringPtr("Storage Service EndpointPolicy Definition"),
 			Service:          to.StringPtr("Microsoft.Storage"),
-			ServiceResources: []*string{to.StringPtr("/subscriptions/" + config.SubscriptionID() + "/resourceGroups/" + config.GroupName())},
+			ServiceResources: []*string{to.StringPtr(resourceid.ResourceGroup(config.SubscriptionID(), groupName).String())},
 		},
 	}
-	err = CreateServiceEndpointPolicyDefinition(ctx, serviceEndpointPolicyName, serviceEndpointPolicyDefinitionName, serviceEndpointPolicyDefinitionParameters)
+	_, err = CreateServiceEndpointPolicyDefinition(ctx, serviceEndpointPolicyName, serviceEndpointPolicyDefinitionName, serviceEndpointPolicyDefinitionParameters)
 	if err != nil {
 		t.Fatalf("failed to create service endpoint policy definition: % +v", err)
 	}
 	t.Logf("created service endpoint policy definition")
 
-	err = GetServiceEndpointPolicyDefinition(ctx, serviceEndpointPolicyName, serviceEndpointPolicyDefinitionName)
+	_, err = GetServiceEndpointPolicyDefinition(ctx, serviceEndpointPolicyName, serviceEndpointPolicyDefinitionName)
 	if err != nil {
 		t.Fatalf("failed to get service endpoint policy definition: %+v", err)
 	}
 	t.Logf("got service endpoint policy definition")
 
-	err = ListServiceEndpointPolicyDefinitionByResourceGroup(ctx, serviceEndpointPolicyName)
+	_, err = ListServiceEndpointPolicyDefinitionByResourceGroup(ctx, serviceEndpointPolicyName)
 	if err != nil {
 		t.Fatalf("failed to listservice endpoint policy definitionby resource group: %+v", err)
 	}
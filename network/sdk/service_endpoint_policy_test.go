@@ -11,19 +11,21 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
 )
 
 func TestServiceEndpointPolicy(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	serviceEndpointPolicyName := config.AppendRandomSuffix("serviceendpointpolicy")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -31,25 +33,25 @@ func TestServiceEndpointPolicy(t *testing.T) {
 		t.Fatalf("failed to create group: %+v", err)
 	}
 
-	err = CreateServiceEndpointPolicy(ctx, serviceEndpointPolicyName)
+	_, err = CreateServiceEndpointPolicy(ctx, serviceEndpointPolicyName)
 	if err != nil {
 		t.Fatalf("failed to create service endpoint policy: % +v", err)
 	}
 	t.Logf("created service endpoint policy")
 
-	err = GetServiceEndpointPolicy(ctx, serviceEndpointPolicyName)
+	_, err = GetServiceEndpointPolicy(ctx, serviceEndpointPolicyName)
 	if err != nil {
 		t.Fatalf("failed to get service endpoint policy: %+v", err)
 	}
 	t.Logf("got service endpoint policy")
 
-	err = ListServiceEndpointPolicyByResourceGroup(ctx)
+	_, err = ListServiceEndpointPolicyByResourceGroup(ctx)
 	if err != nil {
 		t.Fatalf("failed to listservice endpoint policy by resource group: %+v", err)
 	}
 	t.Logf("listedservice endpoint policy by resource group")
 
-	err = ListServiceEndpointPolicy(ctx)
+	_, err = ListServiceEndpointPolicy(ctx)
 	if err != nil {
 		t.Fatalf("failed to list service endpoint policy: %+v", err)
 	}
@@ -58,7 +60,7 @@ func TestServiceEndpointPolicy(t *testing.T) {
 	tagsObjectParameters := armnetwork.TagsObject{
 		Tags: map[string]*string{"tag1": to.StringPtr("value1"), "tag2": to.StringPtr("value2")},
 	}
-	err = UpdateServiceEndpointPolicyTags(ctx, serviceEndpointPolicyName, tagsObjectParameters)
+	_, err = UpdateServiceEndpointPolicyTags(ctx, serviceEndpointPolicyName, tagsObjectParameters)
 	if err != nil {
 		t.Fatalf("failed to update tags for service endpoint policy: %+v", err)
 	}
@@ -10,27 +10,26 @@ import (
 	"log"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getServiceTagsClient() armnetwork.ServiceTagsClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getServiceTagsClient(ctx context.Context) armnetwork.ServiceTagsClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewServiceTagsClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewServiceTagsClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
-//Gets a list of service tag information in a subscription.
-func ListServiceTags(ctx context.Context) error {
-	client := getServiceTagsClient()
-	_, err := client.List(ctx, config.Location(), nil)
+// Gets a list of service tag information in a subscription.
+func ListServiceTags(ctx context.Context) (*armnetwork.ServiceTagsListResult, error) {
+	client := getServiceTagsClient(ctx)
+	resp, err := client.List(ctx, config.ScopeFrom(ctx).Location, nil)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.ServiceTagsListResult, nil
 }
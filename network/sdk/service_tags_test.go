@@ -9,13 +9,17 @@ import (
 	"context"
 	"testing"
 	"time"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 )
 
 func TestServiceTags(t *testing.T) {
+	defer recording.Start(t)()
+
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
 
-	err := ListServiceTags(ctx)
+	_, err := ListServiceTags(ctx)
 	if err != nil {
 		t.Fatalf("failed to list service tags: %+v", err)
 	}
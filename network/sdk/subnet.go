@@ -11,26 +11,26 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getSubnetsClient() armnetwork.SubnetsClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getSubnetsClient(ctx context.Context) armnetwork.SubnetsClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewSubnetsClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewSubnetsClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Create SubNets
 func CreateSubnet(ctx context.Context, virtualNetworkName string, subnetName string, subnetParameters armnetwork.Subnet) (string, error) {
-	client := getSubnetsClient()
+	client := getSubnetsClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		virtualNetworkName,
 		subnetName,
 		subnetParameters,
@@ -41,7 +41,7 @@ func CreateSubnet(ctx context.Context, virtualNetworkName string, subnetName str
 		return "", err
 	}
 
-	resp, err := poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return "", err
 	}
@@ -10,33 +10,31 @@ import (
 	"log"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getUsagesClient() armnetwork.UsagesClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getUsagesClient(ctx context.Context) armnetwork.UsagesClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewUsagesClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewUsagesClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // List network usages for a subscription.
-func ListUsage(ctx context.Context) error {
-	client := getUsagesClient()
-	pager := client.List(config.Location(), nil)
+func ListUsage(ctx context.Context) ([]*armnetwork.Usage, error) {
+	client := getUsagesClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).Location, nil)
 
+	var results []*armnetwork.Usage
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().UsagesListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
@@ -9,19 +9,23 @@ import (
 	"context"
 	"testing"
 	"time"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 )
 
 func TestUsage(t *testing.T) {
+	defer recording.Start(t)()
+
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
 
-	err := ListUsage(ctx)
+	_, err := ListUsage(ctx)
 	if err != nil {
 		t.Fatalf("failed to list network usage: %+v", err)
 	}
 	t.Logf("list network usages for a subscription.")
 
-	err = ListUsage(ctx)
+	_, err = ListUsage(ctx)
 	if err != nil {
 		t.Fatalf("failed to list network usage: %+v", err)
 	}
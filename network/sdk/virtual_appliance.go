@@ -11,93 +11,92 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getNetworkVirtualAppliancesClient() armnetwork.NetworkVirtualAppliancesClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getNetworkVirtualAppliancesClient(ctx context.Context) armnetwork.NetworkVirtualAppliancesClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewNetworkVirtualAppliancesClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewNetworkVirtualAppliancesClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Creates or updates the specified Network Virtual Appliance.
-func CreateNetworkVirtualAppliance(ctx context.Context, networkVirtualApplianceName string, parameters armnetwork.NetworkVirtualAppliance) error {
-	client := getNetworkVirtualAppliancesClient()
+func CreateNetworkVirtualAppliance(ctx context.Context, networkVirtualApplianceName string, parameters armnetwork.NetworkVirtualAppliance) (*armnetwork.NetworkVirtualAppliance, error) {
+	client := getNetworkVirtualAppliancesClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		networkVirtualApplianceName,
 		parameters,
 		nil,
 	)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.NetworkVirtualAppliance, nil
 }
 
 // Gets the specified Network Virtual Appliance.
-func GetNetworkVirtualAppliance(ctx context.Context, networkVirtualApplianceName string) error {
-	client := getNetworkVirtualAppliancesClient()
-	_, err := client.Get(ctx, config.GroupName(), networkVirtualApplianceName, nil)
+func GetNetworkVirtualAppliance(ctx context.Context, networkVirtualApplianceName string) (*armnetwork.NetworkVirtualAppliance, error) {
+	client := getNetworkVirtualAppliancesClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, networkVirtualApplianceName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.NetworkVirtualAppliance, nil
 }
 
 // Gets all Network Virtual Appliances in a subscription.
-func ListNetworkVirtualAppliance(ctx context.Context) error {
-	client := getNetworkVirtualAppliancesClient()
+func ListNetworkVirtualAppliance(ctx context.Context) ([]*armnetwork.NetworkVirtualAppliance, error) {
+	client := getNetworkVirtualAppliancesClient(ctx)
 	pager := client.List(nil)
 
+	var results []*armnetwork.NetworkVirtualAppliance
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().NetworkVirtualApplianceListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Updates a Network Virtual Appliance.
-func UpdateNetworkVirtualApplianceTags(ctx context.Context, networkVirtualApplianceName string, tagsObjectParameters armnetwork.TagsObject) error {
-	client := getNetworkVirtualAppliancesClient()
-	_, err := client.UpdateTags(
+func UpdateNetworkVirtualApplianceTags(ctx context.Context, networkVirtualApplianceName string, tagsObjectParameters armnetwork.TagsObject) (*armnetwork.NetworkVirtualAppliance, error) {
+	client := getNetworkVirtualAppliancesClient(ctx)
+	resp, err := client.UpdateTags(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		networkVirtualApplianceName,
 		tagsObjectParameters,
 		nil,
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.NetworkVirtualAppliance, nil
 }
 
-//  Deletes the specified Network Virtual Appliance.
+// Deletes the specified Network Virtual Appliance.
 func DeleteNetworkVirtualAppliance(ctx context.Context, networkVirtualApplianceName string) error {
-	client := getNetworkVirtualAppliancesClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), networkVirtualApplianceName, nil)
+	client := getNetworkVirtualAppliancesClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, networkVirtualApplianceName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -105,17 +104,17 @@ func DeleteNetworkVirtualAppliance(ctx context.Context, networkVirtualApplianceN
 }
 
 // Lists all Network Virtual Appliances in a resource group.
-func ListNetworkVirtualApplianceByResourceGroup(ctx context.Context) error {
-	client := getNetworkVirtualAppliancesClient()
-	pager := client.ListByResourceGroup(config.GroupName(), nil)
+func ListNetworkVirtualApplianceByResourceGroup(ctx context.Context) ([]*armnetwork.NetworkVirtualAppliance, error) {
+	client := getNetworkVirtualAppliancesClient(ctx)
+	pager := client.ListByResourceGroup(config.ScopeFrom(ctx).GroupName, nil)
+
+	var results []*armnetwork.NetworkVirtualAppliance
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().NetworkVirtualApplianceListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
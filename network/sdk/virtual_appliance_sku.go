@@ -10,43 +10,41 @@ import (
 	"log"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getVirtualApplianceSkusClient() armnetwork.VirtualApplianceSKUsClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getVirtualApplianceSkusClient(ctx context.Context) armnetwork.VirtualApplianceSKUsClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewVirtualApplianceSKUsClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewVirtualApplianceSKUsClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Retrieves a single available sku for network virtual appliance.
-func GetVirtualApplianceSku(ctx context.Context, virtualApplianceSkuName string) error {
-	client := getVirtualApplianceSkusClient()
-	_, err := client.Get(ctx, virtualApplianceSkuName, nil)
+func GetVirtualApplianceSku(ctx context.Context, virtualApplianceSkuName string) (*armnetwork.NetworkVirtualApplianceSKU, error) {
+	client := getVirtualApplianceSkusClient(ctx)
+	resp, err := client.Get(ctx, virtualApplianceSkuName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.NetworkVirtualApplianceSKU, nil
 }
 
-//  List all SKUs available for a virtual appliance.
-func ListVirtualApplianceSku(ctx context.Context) error {
-	client := getVirtualApplianceSkusClient()
+// List all SKUs available for a virtual appliance.
+func ListVirtualApplianceSku(ctx context.Context) ([]*armnetwork.NetworkVirtualApplianceSKU, error) {
+	client := getVirtualApplianceSkusClient(ctx)
 	pager := client.List(nil)
 
+	var results []*armnetwork.NetworkVirtualApplianceSKU
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().NetworkVirtualApplianceSKUListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
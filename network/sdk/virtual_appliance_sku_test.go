@@ -11,9 +11,12 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 )
 
 func TestVirtualApplianceSku(t *testing.T) {
+	defer recording.Start(t)()
 
 	virtualApplianceSkuName := "ciscosdwan"
 
@@ -23,7 +26,7 @@ func TestVirtualApplianceSku(t *testing.T) {
 
 	// Cannot unmarshal string into Go struct field
 	// https://github.com/Azure/azure-sdk-for-go/issues/14769
-	err := GetVirtualApplianceSku(ctx, virtualApplianceSkuName)
+	_, err := GetVirtualApplianceSku(ctx, virtualApplianceSkuName)
 	if err != nil {
 		t.Fatalf("failed to get virtual appliance sku: %+v", err)
 	}
@@ -31,7 +34,7 @@ func TestVirtualApplianceSku(t *testing.T) {
 
 	// Cannot unmarshal string into Go struct field
 	// https://github.com/Azure/azure-sdk-for-go/issues/14769
-	err = ListVirtualApplianceSku(ctx)
+	_, err = ListVirtualApplianceSku(ctx)
 	if err != nil {
 		t.Fatalf("failed to list virtual appliance sku: %+v", err)
 	}
@@ -11,15 +11,16 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/go-autorest/autorest/to"
 )
 
 func TestNetworkVirtualAppliance(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	networkVirtualApplianceName := config.AppendRandomSuffix("networkvirtualappliance")
 
 	virtualWanName := config.AppendRandomSuffix("virtualwan")
@@ -27,6 +28,7 @@ func TestNetworkVirtualAppliance(t *testing.T) {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5000*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -91,25 +93,25 @@ func TestNetworkVirtualAppliance(t *testing.T) {
 		},
 	}
 
-	err = CreateNetworkVirtualAppliance(ctx, networkVirtualApplianceName, parametersNetworkVirtualAppliance)
+	_, err = CreateNetworkVirtualAppliance(ctx, networkVirtualApplianceName, parametersNetworkVirtualAppliance)
 	if err != nil {
 		t.Fatalf("failed to create network virtual appliance: % +v", err)
 	}
 	t.Logf("created network virtual appliance")
 
-	err = GetNetworkVirtualAppliance(ctx, networkVirtualApplianceName)
+	_, err = GetNetworkVirtualAppliance(ctx, networkVirtualApplianceName)
 	if err != nil {
 		t.Fatalf("failed to get network virtual appliance: %+v", err)
 	}
 	t.Logf("got network virtual appliance")
 
-	err = ListNetworkVirtualAppliance(ctx)
+	_, err = ListNetworkVirtualAppliance(ctx)
 	if err != nil {
 		t.Fatalf("failed to list network virtual appliance: %+v", err)
 	}
 	t.Logf("listed network virtual appliance")
 
-	err = ListNetworkVirtualApplianceByResourceGroup(ctx)
+	_, err = ListNetworkVirtualApplianceByResourceGroup(ctx)
 	if err != nil {
 		t.Fatalf("failed to lis tnetwork virtual appliance by resource group: %+v", err)
 	}
@@ -14,17 +14,17 @@ import (
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/helper/resource"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getVirtualHubsClient() armnetwork.VirtualHubsClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getVirtualHubsClient(ctx context.Context) armnetwork.VirtualHubsClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewVirtualHubsClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewVirtualHubsClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
@@ -74,11 +74,11 @@ func virtualHubUpdateRefreshFunc(ctx context.Context, client *armnetwork.Virtual
 
 // Create VirtualHubs
 func CreateVirtualHub(ctx context.Context, virtualHubName string, virtualHubParameters armnetwork.VirtualHub, isWaitfor bool) (string, error) {
-	client := getVirtualHubsClient()
+	client := getVirtualHubsClient(ctx)
 
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		virtualHubName,
 		virtualHubParameters,
 		nil,
@@ -89,7 +89,7 @@ func CreateVirtualHub(ctx context.Context, virtualHubName string, virtualHubPara
 	}
 
 	if isWaitfor {
-		_, err = poller.PollUntilDone(ctx, 30*time.Second)
+		_, err = poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 		if err != nil {
 			return "", err
 		}
@@ -98,25 +98,22 @@ func CreateVirtualHub(ctx context.Context, virtualHubName string, virtualHubPara
 		// https://github.com/Azure/azure-rest-api-specs/issues/10391
 		// As a workaround, we will poll the routing state and ensure it is "Provisioned".
 
-		// deadline is checked at the entry point of this function
-		timeout, _ := ctx.Deadline()
 		stateConf := &resource.StateChangeConf{
 			Pending:                   []string{"Provisioning"},
 			Target:                    []string{"Provisioned", "Failed", "None"},
-			Refresh:                   virtualHubCreateRefreshFunc(ctx, &client, config.GroupName(), virtualHubName),
-			PollInterval:              15 * time.Second,
+			Refresh:                   virtualHubCreateRefreshFunc(ctx, &client, config.ScopeFrom(ctx).GroupName, virtualHubName),
+			Backoff:                   resource.FixedBackoff(recording.PollingDelay(15 * time.Second)),
 			ContinuousTargetOccurence: 3,
-			Timeout:                   time.Until(timeout),
 		}
-		respRaw, err := stateConf.WaitForState()
+		respRaw, err := stateConf.WaitForStateContext(ctx)
 		if err != nil {
-			return "", fmt.Errorf("waiting for Virtual Hub %q (Host Group Name %q) provisioning route: %+v", virtualHubName, config.GroupName(), err)
+			return "", fmt.Errorf("waiting for Virtual Hub %q (Host Group Name %q) provisioning route: %+v", virtualHubName, config.ScopeFrom(ctx).GroupName, err)
 		}
 		response := respRaw.(armnetwork.VirtualHubResponse)
 
 		return *response.VirtualHub.ID, nil
 	} else {
-		resp, err := poller.PollUntilDone(ctx, 30*time.Second)
+		resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 		if err != nil {
 			return "", err
 		}
@@ -126,77 +123,73 @@ func CreateVirtualHub(ctx context.Context, virtualHubName string, virtualHubPara
 }
 
 // Retrieves the details of a VirtualHub
-func GetVirtualHub(ctx context.Context, virtualHubName string) error {
-	client := getVirtualHubsClient()
-	_, err := client.Get(ctx, config.GroupName(), virtualHubName, nil)
+func GetVirtualHub(ctx context.Context, virtualHubName string) (*armnetwork.VirtualHub, error) {
+	client := getVirtualHubsClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, virtualHubName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VirtualHub, nil
 }
 
 // Lists all the VirtualHubs in a subscription.
-func ListVirtualHub(ctx context.Context) error {
-	client := getVirtualHubsClient()
+func ListVirtualHub(ctx context.Context) ([]*armnetwork.VirtualHub, error) {
+	client := getVirtualHubsClient(ctx)
 	pager := client.List(nil)
 
+	var results []*armnetwork.VirtualHub
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().ListVirtualHubsResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
-//  Updates VirtualHub tags.
-func UpdateVirtualHubTags(ctx context.Context, virtualHubName string, tagsObjectParameters armnetwork.TagsObject) error {
-	client := getVirtualHubsClient()
+// Updates VirtualHub tags.
+func UpdateVirtualHubTags(ctx context.Context, virtualHubName string, tagsObjectParameters armnetwork.TagsObject) (*armnetwork.VirtualHub, error) {
+	client := getVirtualHubsClient(ctx)
 	_, err := client.UpdateTags(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		virtualHubName,
 		tagsObjectParameters,
 		nil,
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Hub returns state is "updating". This might cause deletion to fail.
 	// As a workaround, we will poll the hub state and ensure it is "Succeeded".
-	// deadline is checked at the entry point of this function
-	timeout, _ := ctx.Deadline()
 	stateConf := &resource.StateChangeConf{
 		Pending:                   []string{"Updating"},
 		Target:                    []string{"Succeeded", "Failed", "None"},
-		Refresh:                   virtualHubUpdateRefreshFunc(ctx, &client, config.GroupName(), virtualHubName),
-		PollInterval:              15 * time.Second,
+		Refresh:                   virtualHubUpdateRefreshFunc(ctx, &client, config.ScopeFrom(ctx).GroupName, virtualHubName),
+		Backoff:                   resource.FixedBackoff(recording.PollingDelay(15 * time.Second)),
 		ContinuousTargetOccurence: 3,
-		Timeout:                   time.Until(timeout),
 	}
-	respRaw, err := stateConf.WaitForState()
+	respRaw, err := stateConf.WaitForStateContext(ctx)
 	if err != nil {
-		return fmt.Errorf("waiting for Virtual Hub %q (Host Group Name %q) update: %+v", virtualHubName, config.GroupName(), err)
+		return nil, fmt.Errorf("waiting for Virtual Hub %q (Host Group Name %q) update: %+v", virtualHubName, config.ScopeFrom(ctx).GroupName, err)
 	}
 	response := respRaw.(armnetwork.VirtualHubResponse)
 	if response.VirtualHub.ID == nil {
-		return fmt.Errorf("cannot read Virtual Hub %q (Resource Group %q) ID", virtualHubName, config.GroupName())
+		return nil, fmt.Errorf("cannot read Virtual Hub %q (Resource Group %q) ID", virtualHubName, config.ScopeFrom(ctx).GroupName)
 	}
-	return nil
+	return response.VirtualHub, nil
 }
 
 // Deletes a VirtualHub.
 func DeleteVirtualHub(ctx context.Context, virtualHubName string) error {
-	client := getVirtualHubsClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), virtualHubName, nil)
+	client := getVirtualHubsClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, virtualHubName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -204,17 +197,17 @@ func DeleteVirtualHub(ctx context.Context, virtualHubName string) error {
 }
 
 // Lists all the VirtualHubs in a resource group.
-func ListVirtualHubByResourceGroup(ctx context.Context) error {
-	client := getVirtualHubsClient()
-	pager := client.ListByResourceGroup(config.GroupName(), nil)
+func ListVirtualHubByResourceGroup(ctx context.Context) ([]*armnetwork.VirtualHub, error) {
+	client := getVirtualHubsClient(ctx)
+	pager := client.ListByResourceGroup(config.ScopeFrom(ctx).GroupName, nil)
+
+	var results []*armnetwork.VirtualHub
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().ListVirtualHubsResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
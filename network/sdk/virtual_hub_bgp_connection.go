@@ -11,26 +11,26 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getVirtualHubBgpConnectionClient() armnetwork.VirtualHubBgpConnectionClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getVirtualHubBgpConnectionClient(ctx context.Context) armnetwork.VirtualHubBgpConnectionClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewVirtualHubBgpConnectionClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewVirtualHubBgpConnectionClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Creates a VirtualHubBgpConnection resource if it doesn't exist else updates the existing VirtualHubBgpConnection.
-func CreateVirtualHubBgpConnection(ctx context.Context, virtualHubName string, connectionName string, bgpConnectionParameters armnetwork.BgpConnection) error {
-	client := getVirtualHubBgpConnectionClient()
+func CreateVirtualHubBgpConnection(ctx context.Context, virtualHubName string, connectionName string, bgpConnectionParameters armnetwork.BgpConnection) (*armnetwork.BgpConnection, error) {
+	client := getVirtualHubBgpConnectionClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		virtualHubName,
 		connectionName,
 		bgpConnectionParameters,
@@ -38,34 +38,34 @@ func CreateVirtualHubBgpConnection(ctx context.Context, virtualHubName string, c
 	)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.BgpConnection, nil
 }
 
 // Retrieves the details of a Virtual Hub Bgp Connection.
-func GetVirtualHubBgpConnection(ctx context.Context, virtualHubName string, connectionName string) error {
-	client := getVirtualHubBgpConnectionClient()
-	_, err := client.Get(ctx, config.GroupName(), virtualHubName, connectionName, nil)
+func GetVirtualHubBgpConnection(ctx context.Context, virtualHubName string, connectionName string) (*armnetwork.BgpConnection, error) {
+	client := getVirtualHubBgpConnectionClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, virtualHubName, connectionName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.BgpConnection, nil
 }
 
-//  Deletes a VirtualHubBgpConnection.
+// Deletes a VirtualHubBgpConnection.
 func DeleteVirtualHubBgpConnection(ctx context.Context, virtualHubName string, connectionName string) error {
-	client := getVirtualHubBgpConnectionClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), virtualHubName, connectionName, nil)
+	client := getVirtualHubBgpConnectionClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, virtualHubName, connectionName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -11,15 +11,16 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
 )
 
 func TestVirtualHubBgpConnection(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	virtualHubBgpConnectionName := config.AppendRandomSuffix("virtualhubbgpconnection")
 	ipConfigName := config.AppendRandomSuffix("ipconfiguration")
 	virtualHubName := config.AppendRandomSuffix("virtualhub")
@@ -29,6 +30,7 @@ func TestVirtualHubBgpConnection(t *testing.T) {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 2000*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -111,7 +113,7 @@ func TestVirtualHubBgpConnection(t *testing.T) {
 			},
 		},
 	}
-	err = CreateVirtualHubIp(ctx, virtualHubName, ipConfigName, hubIPConfigurationParameters)
+	_, err = CreateVirtualHubIp(ctx, virtualHubName, ipConfigName, hubIPConfigurationParameters)
 	if err != nil {
 		t.Fatalf("failed to create virtual hub ip: % +v", err)
 	}
@@ -122,13 +124,13 @@ func TestVirtualHubBgpConnection(t *testing.T) {
 			PeerIP:  to.StringPtr("169.254.21.5"),
 		},
 	}
-	err = CreateVirtualHubBgpConnection(ctx, virtualHubName, virtualHubBgpConnectionName, bgpConnectionParameters)
+	_, err = CreateVirtualHubBgpConnection(ctx, virtualHubName, virtualHubBgpConnectionName, bgpConnectionParameters)
 	if err != nil {
 		t.Fatalf("failed to create virtual hub bgp connection: % +v", err)
 	}
 	t.Logf("created virtual hub bgp connection")
 
-	err = GetVirtualHubBgpConnection(ctx, virtualHubName, virtualHubBgpConnectionName)
+	_, err = GetVirtualHubBgpConnection(ctx, virtualHubName, virtualHubBgpConnectionName)
 	if err != nil {
 		t.Fatalf("failed to get virtual hub bgp connection: %+v", err)
 	}
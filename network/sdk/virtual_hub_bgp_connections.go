@@ -8,57 +8,65 @@ package network
 import (
 	"context"
 	"log"
+	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getVirtualHubBgpConnectionsClient() armnetwork.VirtualHubBgpConnectionsClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getVirtualHubBgpConnectionsClient(ctx context.Context) armnetwork.VirtualHubBgpConnectionsClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewVirtualHubBgpConnectionsClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewVirtualHubBgpConnectionsClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Retrieves the details of all VirtualHubBgpConnections.
-func ListVirtualHubBgpConnection(ctx context.Context, virtualHubName string) error {
-	client := getVirtualHubBgpConnectionsClient()
-	pager := client.List(config.GroupName(), virtualHubName, nil)
+func ListVirtualHubBgpConnection(ctx context.Context, virtualHubName string) ([]*armnetwork.BgpConnection, error) {
+	client := getVirtualHubBgpConnectionsClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).GroupName, virtualHubName, nil)
 
+	var results []*armnetwork.BgpConnection
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().ListVirtualHubBgpConnectionResults.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Retrieves a list of routes the virtual hub bgp connection is advertising to the specified peer.
-func ListVirtualHubBgpConnectionAdvertisedRoute(ctx context.Context, hubName string, connectionName string) error {
-	client := getVirtualHubBgpConnectionsClient()
-	_, err := client.BeginListAdvertisedRoutes(ctx, config.GroupName(), hubName, connectionName, nil)
+func ListVirtualHubBgpConnectionAdvertisedRoute(ctx context.Context, hubName string, connectionName string) ([]*armnetwork.PeerRoute, error) {
+	client := getVirtualHubBgpConnectionsClient(ctx)
+	poller, err := client.BeginListAdvertisedRoutes(ctx, config.ScopeFrom(ctx).GroupName, hubName, connectionName, nil)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
+	if err != nil {
+		return nil, err
+	}
+	return resp.PeerRouteList.Value, nil
 }
 
 // Retrieves a list of routes the virtual hub bgp connection has learned.
-func ListVirtualHubBgpConnectionLearnedRoutes(ctx context.Context, hubName string, connectionName string) error {
-	client := getVirtualHubBgpConnectionsClient()
-	_, err := client.BeginListLearnedRoutes(ctx, config.GroupName(), hubName, connectionName, nil)
+func ListVirtualHubBgpConnectionLearnedRoutes(ctx context.Context, hubName string, connectionName string) ([]*armnetwork.PeerRoute, error) {
+	client := getVirtualHubBgpConnectionsClient(ctx)
+	poller, err := client.BeginListLearnedRoutes(ctx, config.ScopeFrom(ctx).GroupName, hubName, connectionName, nil)
 
 	if err != nil {
-		return err
+		return nil, err
+	}
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	return resp.PeerRouteList.Value, nil
 }
@@ -11,26 +11,26 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getVirtualHubIpsClient() armnetwork.VirtualHubIPConfigurationClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getVirtualHubIpsClient(ctx context.Context) armnetwork.VirtualHubIPConfigurationClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewVirtualHubIPConfigurationClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewVirtualHubIPConfigurationClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Creates a VirtualHubIpConfiguration resource if it doesn't exist else updates the existing VirtualHubIpConfiguration.
-func CreateVirtualHubIp(ctx context.Context, virtualHubName string, ipConfigName string, hubIPConfigurationParameters armnetwork.HubIPConfiguration) error {
-	client := getVirtualHubIpsClient()
+func CreateVirtualHubIp(ctx context.Context, virtualHubName string, ipConfigName string, hubIPConfigurationParameters armnetwork.HubIPConfiguration) (*armnetwork.HubIPConfiguration, error) {
+	client := getVirtualHubIpsClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		virtualHubName,
 		ipConfigName,
 		hubIPConfigurationParameters,
@@ -38,12 +38,12 @@ func CreateVirtualHubIp(ctx context.Context, virtualHubName string, ipConfigName
 	)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.HubIPConfiguration, nil
 }
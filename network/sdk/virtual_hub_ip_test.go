@@ -11,15 +11,16 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/go-autorest/autorest/to"
 )
 
 func TestVirtualHubIp(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	ipConfigName := config.AppendRandomSuffix("ipconfiguration")
 	virtualHubName := config.AppendRandomSuffix("virtualhub")
 	publicIpAddressName := config.AppendRandomSuffix("pipaddress")
@@ -28,6 +29,7 @@ func TestVirtualHubIp(t *testing.T) {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 2000*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -110,7 +112,7 @@ func TestVirtualHubIp(t *testing.T) {
 			},
 		},
 	}
-	err = CreateVirtualHubIp(ctx, virtualHubName, ipConfigName, hubIPConfigurationParameters)
+	_, err = CreateVirtualHubIp(ctx, virtualHubName, ipConfigName, hubIPConfigurationParameters)
 	if err != nil {
 		t.Fatalf("failed to create virtual hub ip: % +v", err)
 	}
@@ -10,33 +10,31 @@ import (
 	"log"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getVirtualHubRouteTableV2sClient() armnetwork.VirtualHubRouteTableV2SClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getVirtualHubRouteTableV2sClient(ctx context.Context) armnetwork.VirtualHubRouteTableV2SClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewVirtualHubRouteTableV2SClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewVirtualHubRouteTableV2SClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Retrieves the details of all VirtualHubRouteTableV2s.
-func ListVirtualHubRouteTableV2(ctx context.Context, virtualHubName string) error {
-	client := getVirtualHubRouteTableV2sClient()
-	pager := client.List(config.GroupName(), virtualHubName, nil)
+func ListVirtualHubRouteTableV2(ctx context.Context, virtualHubName string) ([]*armnetwork.VirtualHubRouteTableV2, error) {
+	client := getVirtualHubRouteTableV2sClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).GroupName, virtualHubName, nil)
 
+	var results []*armnetwork.VirtualHubRouteTableV2
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().ListVirtualHubRouteTableV2SResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
@@ -11,20 +11,22 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
 )
 
 func TestVirtualHubRouteTableV2(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	virtualHubName := config.AppendRandomSuffix("virtualhub")
 	virtualWanName := config.AppendRandomSuffix("virtualwan")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -65,7 +67,7 @@ func TestVirtualHubRouteTableV2(t *testing.T) {
 		t.Fatalf("failed to create virtual hub: % +v", err)
 	}
 
-	err = ListVirtualHubRouteTableV2(ctx, virtualHubName)
+	_, err = ListVirtualHubRouteTableV2(ctx, virtualHubName)
 	if err != nil {
 		t.Fatalf("failed to list virtual hub route table v2: %+v", err)
 	}
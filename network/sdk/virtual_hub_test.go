@@ -11,20 +11,22 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
 )
 
 func TestVirtualHub(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	virtualWanName := config.AppendRandomSuffix("virtualwan")
 	virtualHubName := config.AppendRandomSuffix("virtualhub")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3000*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -67,19 +69,19 @@ func TestVirtualHub(t *testing.T) {
 	}
 	t.Logf("created virtual hub")
 
-	err = GetVirtualHub(ctx, virtualHubName)
+	_, err = GetVirtualHub(ctx, virtualHubName)
 	if err != nil {
 		t.Fatalf("failed to get virtual hub: %+v", err)
 	}
 	t.Logf("got virtual hub")
 
-	err = ListVirtualHub(ctx)
+	_, err = ListVirtualHub(ctx)
 	if err != nil {
 		t.Fatalf("failed to list virtual hub: %+v", err)
 	}
 	t.Logf("listed virtual hub")
 
-	err = ListVirtualHubByResourceGroup(ctx)
+	_, err = ListVirtualHubByResourceGroup(ctx)
 	if err != nil {
 		t.Fatalf("failed to list virtual hub by resource group: %+v", err)
 	}
@@ -88,7 +90,7 @@ func TestVirtualHub(t *testing.T) {
 	tagsObjectParameters := armnetwork.TagsObject{
 		Tags: map[string]*string{"key1": to.StringPtr("value1"), "key2": to.StringPtr("value2")},
 	}
-	err = UpdateVirtualHubTags(ctx, virtualHubName, tagsObjectParameters)
+	_, err = UpdateVirtualHubTags(ctx, virtualHubName, tagsObjectParameters)
 	if err != nil {
 		t.Fatalf("failed to update tags for virtual hub: %+v", err)
 	}
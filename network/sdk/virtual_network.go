@@ -11,26 +11,26 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getVirtualNetworksClient() armnetwork.VirtualNetworksClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getVirtualNetworksClient(ctx context.Context) armnetwork.VirtualNetworksClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewVirtualNetworksClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewVirtualNetworksClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Creates or updates a virtual network in the specified resource group
 func CreateVirtualNetwork(ctx context.Context, virtualNetworkName string, virtualNetworkParameters armnetwork.VirtualNetwork) (string, error) {
-	client := getVirtualNetworksClient()
+	client := getVirtualNetworksClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		virtualNetworkName,
 		virtualNetworkParameters,
 		nil,
@@ -40,7 +40,7 @@ func CreateVirtualNetwork(ctx context.Context, virtualNetworkName string, virtua
 		return "", err
 	}
 
-	resp, err := poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return "", err
 	}
@@ -49,99 +49,97 @@ func CreateVirtualNetwork(ctx context.Context, virtualNetworkName string, virtua
 }
 
 // Checks whether a private IP address is available for use.
-func CheckIPAddressAvailability(ctx context.Context, virtualNetworkName string, ipAddress string) error {
-	client := getVirtualNetworksClient()
-	_, err := client.CheckIPAddressAvailability(ctx, config.GroupName(), virtualNetworkName, ipAddress, nil)
+func CheckIPAddressAvailability(ctx context.Context, virtualNetworkName string, ipAddress string) (*armnetwork.IPAddressAvailabilityResult, error) {
+	client := getVirtualNetworksClient(ctx)
+	resp, err := client.CheckIPAddressAvailability(ctx, config.ScopeFrom(ctx).GroupName, virtualNetworkName, ipAddress, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.IPAddressAvailabilityResult, nil
 }
 
 // Lists usage stats.
-func ListUsageVirtualNetwork(ctx context.Context, virtualNetworkName string) error {
-	client := getVirtualNetworksClient()
-	pager := client.ListUsage(config.GroupName(), virtualNetworkName, nil)
+func ListUsageVirtualNetwork(ctx context.Context, virtualNetworkName string) ([]*armnetwork.VirtualNetworkUsage, error) {
+	client := getVirtualNetworksClient(ctx)
+	pager := client.ListUsage(config.ScopeFrom(ctx).GroupName, virtualNetworkName, nil)
 
+	var results []*armnetwork.VirtualNetworkUsage
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().VirtualNetworkListUsageResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Gets the specified virtual network in a specified resource group.
-func GetVirtualNetwork(ctx context.Context, virtualNetworkName string) error {
-	client := getVirtualNetworksClient()
-	_, err := client.Get(ctx, config.GroupName(), virtualNetworkName, nil)
+func GetVirtualNetwork(ctx context.Context, virtualNetworkName string) (*armnetwork.VirtualNetwork, error) {
+	client := getVirtualNetworksClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, virtualNetworkName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VirtualNetwork, nil
 }
 
 // Gets all the virtual network in a subscription.
-func ListVirtualNetwork(ctx context.Context) error {
-	client := getVirtualNetworksClient()
-	pager := client.List(config.GroupName(), nil)
+func ListVirtualNetwork(ctx context.Context) ([]*armnetwork.VirtualNetwork, error) {
+	client := getVirtualNetworksClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).GroupName, nil)
 
+	var results []*armnetwork.VirtualNetwork
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().VirtualNetworkListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Gets all the virtual network in a subscription.
-func ListAllVirtualNetwork(ctx context.Context) error {
-	client := getVirtualNetworksClient()
+func ListAllVirtualNetwork(ctx context.Context) ([]*armnetwork.VirtualNetwork, error) {
+	client := getVirtualNetworksClient(ctx)
 	pager := client.ListAll(nil)
+
+	var results []*armnetwork.VirtualNetwork
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().VirtualNetworkListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Updates virtual network tags.
-func UpdateVirtualNetworkTags(ctx context.Context, virtualNetworkName string, tagsObjectParameters armnetwork.TagsObject) error {
-	client := getVirtualNetworksClient()
-	_, err := client.UpdateTags(
+func UpdateVirtualNetworkTags(ctx context.Context, virtualNetworkName string, tagsObjectParameters armnetwork.TagsObject) (*armnetwork.VirtualNetwork, error) {
+	client := getVirtualNetworksClient(ctx)
+	resp, err := client.UpdateTags(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		virtualNetworkName,
 		tagsObjectParameters,
 		nil,
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VirtualNetwork, nil
 }
 
 // Deletes the specified virtual network.
 func DeleteVirtualNetwork(ctx context.Context, virtualNetworkName string) error {
-	client := getVirtualNetworksClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), virtualNetworkName, nil)
+	client := getVirtualNetworksClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, virtualNetworkName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
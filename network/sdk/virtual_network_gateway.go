@@ -11,26 +11,26 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getVirtualNetworkGatewaysClient() armnetwork.VirtualNetworkGatewaysClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getVirtualNetworkGatewaysClient(ctx context.Context) armnetwork.VirtualNetworkGatewaysClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewVirtualNetworkGatewaysClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewVirtualNetworkGatewaysClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Creates or updates a virtual network gateway in the specified resource group.
 func CreateVirtualNetworkGateway(ctx context.Context, virtualNetworkGatewayName string, virtualNetworkGateway armnetwork.VirtualNetworkGateway) (string, error) {
-	client := getVirtualNetworkGatewaysClient()
+	client := getVirtualNetworkGatewaysClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		virtualNetworkGatewayName,
 		virtualNetworkGateway,
 		nil,
@@ -40,7 +40,7 @@ func CreateVirtualNetworkGateway(ctx context.Context, virtualNetworkGatewayName
 		return "", err
 	}
 
-	resp, err := poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return "", err
 	}
@@ -49,142 +49,140 @@ func CreateVirtualNetworkGateway(ctx context.Context, virtualNetworkGatewayName
 }
 
 // Gets all the connections in a virtual network gateway.
-func ListVirtualNetworkGatewayConnections(ctx context.Context, virtualNetworkGatewayName string) error {
-	client := getVirtualNetworkGatewaysClient()
-	pager := client.ListConnections(config.GroupName(), virtualNetworkGatewayName, nil)
+func ListVirtualNetworkGatewayConnections(ctx context.Context, virtualNetworkGatewayName string) ([]*armnetwork.VirtualNetworkGatewayConnectionListEntity, error) {
+	client := getVirtualNetworkGatewaysClient(ctx)
+	pager := client.ListConnections(config.ScopeFrom(ctx).GroupName, virtualNetworkGatewayName, nil)
 
+	var results []*armnetwork.VirtualNetworkGatewayConnectionListEntity
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().VirtualNetworkGatewayListConnectionsResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Gets the specified virtual network gateway in a specified resource group.
-func GetVirtualNetworkGateway(ctx context.Context, virtualNetworkGatewayName string) error {
-	client := getVirtualNetworkGatewaysClient()
-	_, err := client.Get(ctx, config.GroupName(), virtualNetworkGatewayName, nil)
+func GetVirtualNetworkGateway(ctx context.Context, virtualNetworkGatewayName string) (*armnetwork.VirtualNetworkGateway, error) {
+	client := getVirtualNetworkGatewaysClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, virtualNetworkGatewayName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VirtualNetworkGateway, nil
 }
 
 // Gets all virtual network gateways by resource group.
-func ListVirtualNetworkGateway(ctx context.Context) error {
-	client := getVirtualNetworkGatewaysClient()
-	pager := client.List(config.GroupName(), nil)
+func ListVirtualNetworkGateway(ctx context.Context) ([]*armnetwork.VirtualNetworkGateway, error) {
+	client := getVirtualNetworkGatewaysClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).GroupName, nil)
 
+	var results []*armnetwork.VirtualNetworkGateway
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().VirtualNetworkGatewayListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // This operation retrieves a list of routes the virtual network gateway is advertising to the specified peer.
-func BeginGetVirtualNetworkGatewayAdvertisedRoute(ctx context.Context, virtualNetworkGatewayName string, peer string) error {
-	client := getVirtualNetworkGatewaysClient()
-	poller, err := client.BeginGetAdvertisedRoutes(ctx, config.GroupName(), virtualNetworkGatewayName, peer, nil)
+func BeginGetVirtualNetworkGatewayAdvertisedRoute(ctx context.Context, virtualNetworkGatewayName string, peer string) (*armnetwork.GatewayRouteListResult, error) {
+	client := getVirtualNetworkGatewaysClient(ctx)
+	poller, err := client.BeginGetAdvertisedRoutes(ctx, config.ScopeFrom(ctx).GroupName, virtualNetworkGatewayName, peer, nil)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.GatewayRouteListResult, nil
 }
 
 // The GetBgpPeerStatus operation retrieves the status of all BGP peers.
-func BeginGetVirtualNetworkGatewayBgpPeerStatus(ctx context.Context, virtualNetworkGatewayName string) error {
-	client := getVirtualNetworkGatewaysClient()
-	poller, err := client.BeginGetBgpPeerStatus(ctx, config.GroupName(), virtualNetworkGatewayName, nil)
+func BeginGetVirtualNetworkGatewayBgpPeerStatus(ctx context.Context, virtualNetworkGatewayName string) (*armnetwork.BgpPeerStatusListResult, error) {
+	client := getVirtualNetworkGatewaysClient(ctx)
+	poller, err := client.BeginGetBgpPeerStatus(ctx, config.ScopeFrom(ctx).GroupName, virtualNetworkGatewayName, nil)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.BgpPeerStatusListResult, nil
 }
 
 // This operation retrieves a list of routes the virtual network gateway has learned, including routes learned from BGP peers.
-func BeginGetVirtualNetworkGatewayLearnedRoutes(ctx context.Context, virtualNetworkGatewayName string) error {
-	client := getVirtualNetworkGatewaysClient()
-	poller, err := client.BeginGetLearnedRoutes(ctx, config.GroupName(), virtualNetworkGatewayName, nil)
+func BeginGetVirtualNetworkGatewayLearnedRoutes(ctx context.Context, virtualNetworkGatewayName string) (*armnetwork.GatewayRouteListResult, error) {
+	client := getVirtualNetworkGatewaysClient(ctx)
+	poller, err := client.BeginGetLearnedRoutes(ctx, config.ScopeFrom(ctx).GroupName, virtualNetworkGatewayName, nil)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.GatewayRouteListResult, nil
 }
 
 // Resets the primary of the virtual network gateway in the specified resource group.
-func BeginVirtualNetworkGatewayReset(ctx context.Context, virtualNetworkGatewayName string) error {
-	client := getVirtualNetworkGatewaysClient()
-	poller, err := client.BeginReset(ctx, config.GroupName(), virtualNetworkGatewayName, nil)
+func BeginVirtualNetworkGatewayReset(ctx context.Context, virtualNetworkGatewayName string) (*armnetwork.VirtualNetworkGateway, error) {
+	client := getVirtualNetworkGatewaysClient(ctx)
+	poller, err := client.BeginReset(ctx, config.ScopeFrom(ctx).GroupName, virtualNetworkGatewayName, nil)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VirtualNetworkGateway, nil
 }
 
 // Updates a virtual network gateway tags.
-func UpdateVirtualNetworkGatewayTags(ctx context.Context, virtualNetworkGatewayName string, tagsObjectParameters armnetwork.TagsObject) error {
-	client := getVirtualNetworkGatewaysClient()
+func UpdateVirtualNetworkGatewayTags(ctx context.Context, virtualNetworkGatewayName string, tagsObjectParameters armnetwork.TagsObject) (*armnetwork.VirtualNetworkGateway, error) {
+	client := getVirtualNetworkGatewaysClient(ctx)
 	poller, err := client.BeginUpdateTags(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		virtualNetworkGatewayName,
 		tagsObjectParameters,
 		nil,
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VirtualNetworkGateway, nil
 }
 
 // Deletes the specified virtual network gateway.
 func DeleteVirtualNetworkGateway(ctx context.Context, virtualNetworkGatewayName string) error {
-	client := getVirtualNetworkGatewaysClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), virtualNetworkGatewayName, nil)
+	client := getVirtualNetworkGatewaysClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, virtualNetworkGatewayName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -11,147 +11,146 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
 )
 
-func getVirtualNetworkGatewayConnectionsClient() armnetwork.VirtualNetworkGatewayConnectionsClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getVirtualNetworkGatewayConnectionsClient(ctx context.Context) armnetwork.VirtualNetworkGatewayConnectionsClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewVirtualNetworkGatewayConnectionsClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewVirtualNetworkGatewayConnectionsClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Create VirtualNetworkGatewayConnections
-func CreateVirtualNetworkGatewayConnection(ctx context.Context, virtualNetworkGatewayConnectionName string, gatewayConnectionParameters armnetwork.VirtualNetworkGatewayConnection) error {
-	client := getVirtualNetworkGatewayConnectionsClient()
+func CreateVirtualNetworkGatewayConnection(ctx context.Context, virtualNetworkGatewayConnectionName string, gatewayConnectionParameters armnetwork.VirtualNetworkGatewayConnection) (*armnetwork.VirtualNetworkGatewayConnection, error) {
+	client := getVirtualNetworkGatewayConnectionsClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		virtualNetworkGatewayConnectionName,
 		gatewayConnectionParameters,
 		nil,
 	)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VirtualNetworkGatewayConnection, nil
 }
 
 // BeginSetSharedKey - The Put VirtualNetworkGatewayConnectionSharedKey operation sets the virtual network gateway connection shared key for passed virtual
 // network gateway connection in the specified resource group through
 // Network resource provider.
-func BeginSetVirtualNetworkGatewayConnectionSharedKey(ctx context.Context, virtualNetworkGatewayConnectionName string) error {
-	client := getVirtualNetworkGatewayConnectionsClient()
-	poller, err := client.BeginSetSharedKey(ctx, config.GroupName(), virtualNetworkGatewayConnectionName,
+func BeginSetVirtualNetworkGatewayConnectionSharedKey(ctx context.Context, virtualNetworkGatewayConnectionName string) (*armnetwork.ConnectionSharedKey, error) {
+	client := getVirtualNetworkGatewayConnectionsClient(ctx)
+	poller, err := client.BeginSetSharedKey(ctx, config.ScopeFrom(ctx).GroupName, virtualNetworkGatewayConnectionName,
 		armnetwork.ConnectionSharedKey{Value: to.StringPtr("AzureAbc124")}, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.ConnectionSharedKey, nil
 }
 
 // GetSharedKey - The Get VirtualNetworkGatewayConnectionSharedKey operation retrieves information about the specified virtual network gateway connection
 // shared key through Network resource provider.
-func GetVirtualNetworkGatewayConnectionSharedKey(ctx context.Context, virtualNetworkGatewayConnectionName string) error {
-	client := getVirtualNetworkGatewayConnectionsClient()
-	_, err := client.GetSharedKey(ctx, config.GroupName(), virtualNetworkGatewayConnectionName, nil)
+func GetVirtualNetworkGatewayConnectionSharedKey(ctx context.Context, virtualNetworkGatewayConnectionName string) (*armnetwork.ConnectionSharedKey, error) {
+	client := getVirtualNetworkGatewayConnectionsClient(ctx)
+	resp, err := client.GetSharedKey(ctx, config.ScopeFrom(ctx).GroupName, virtualNetworkGatewayConnectionName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.ConnectionSharedKey, nil
 }
 
 // Gets the specified virtual network gateway connection in a specified resource group.
-func GetVirtualNetworkGatewayConnection(ctx context.Context, virtualNetworkGatewayConnectionName string) error {
-	client := getVirtualNetworkGatewayConnectionsClient()
-	_, err := client.Get(ctx, config.GroupName(), virtualNetworkGatewayConnectionName, nil)
+func GetVirtualNetworkGatewayConnection(ctx context.Context, virtualNetworkGatewayConnectionName string) (*armnetwork.VirtualNetworkGatewayConnection, error) {
+	client := getVirtualNetworkGatewayConnectionsClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, virtualNetworkGatewayConnectionName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VirtualNetworkGatewayConnection, nil
 }
 
 // Gets all the virtual network gateway connection in a subscription.
-func ListVirtualNetworkGatewayConnection(ctx context.Context) error {
-	client := getVirtualNetworkGatewayConnectionsClient()
-	pager := client.List(config.GroupName(), nil)
+func ListVirtualNetworkGatewayConnection(ctx context.Context) ([]*armnetwork.VirtualNetworkGatewayConnection, error) {
+	client := getVirtualNetworkGatewayConnectionsClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).GroupName, nil)
 
+	var results []*armnetwork.VirtualNetworkGatewayConnection
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().VirtualNetworkGatewayConnectionListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // BeginResetSharedKey - The VirtualNetworkGatewayConnectionResetSharedKey operation resets the virtual network gateway connection shared key for passed
 // virtual network gateway connection in the specified resource group
 // through Network resource provider.
-func BeginResetVirtualNetworkGatewayConnectionSharedKey(ctx context.Context, virtualNetworkGatewayConnectionName string) error {
-	client := getVirtualNetworkGatewayConnectionsClient()
-	poller, err := client.BeginResetSharedKey(ctx, config.GroupName(), virtualNetworkGatewayConnectionName, armnetwork.ConnectionResetSharedKey{
+func BeginResetVirtualNetworkGatewayConnectionSharedKey(ctx context.Context, virtualNetworkGatewayConnectionName string) (*armnetwork.ConnectionResetSharedKey, error) {
+	client := getVirtualNetworkGatewayConnectionsClient(ctx)
+	poller, err := client.BeginResetSharedKey(ctx, config.ScopeFrom(ctx).GroupName, virtualNetworkGatewayConnectionName, armnetwork.ConnectionResetSharedKey{
 		KeyLength: to.Int32Ptr(128),
 	}, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.ConnectionResetSharedKey, nil
 }
 
 // Updates virtual network gateway connection tags.
-func UpdateVirtualNetworkGatewayConnectionTags(ctx context.Context, virtualNetworkGatewayConnectionName string, tagsObjectParameters armnetwork.TagsObject) error {
-	client := getVirtualNetworkGatewayConnectionsClient()
+func UpdateVirtualNetworkGatewayConnectionTags(ctx context.Context, virtualNetworkGatewayConnectionName string, tagsObjectParameters armnetwork.TagsObject) (*armnetwork.VirtualNetworkGatewayConnection, error) {
+	client := getVirtualNetworkGatewayConnectionsClient(ctx)
 	poller, err := client.BeginUpdateTags(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		virtualNetworkGatewayConnectionName,
 		tagsObjectParameters,
 		nil,
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VirtualNetworkGatewayConnection, nil
 }
 
 // Deletes the specified virtual network gateway connection.
 func DeleteVirtualNetworkGatewayConnection(ctx context.Context, virtualNetworkGatewayConnectionName string) error {
-	client := getVirtualNetworkGatewayConnectionsClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), virtualNetworkGatewayConnectionName, nil)
+	client := getVirtualNetworkGatewayConnectionsClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, virtualNetworkGatewayConnectionName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
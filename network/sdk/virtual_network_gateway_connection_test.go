@@ -11,15 +11,16 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
 )
 
 func TestVirtualNetworkGatewayConnection(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	virtualNetworkGatewayConnectionName := config.AppendRandomSuffix("virtualnetworkgatewayconnection")
 	localNetworkGatewayName := config.AppendRandomSuffix("localvirtualnetworkgateway")
 	virtualNetworkGatewayName := config.AppendRandomSuffix("virtualnetworkgateway")
@@ -30,6 +31,7 @@ func TestVirtualNetworkGatewayConnection(t *testing.T) {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5000*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -203,31 +205,31 @@ func TestVirtualNetworkGatewayConnection(t *testing.T) {
 		},
 	}
 
-	err = CreateVirtualNetworkGatewayConnection(ctx, virtualNetworkGatewayConnectionName, gatewayConnectionParameters)
+	_, err = CreateVirtualNetworkGatewayConnection(ctx, virtualNetworkGatewayConnectionName, gatewayConnectionParameters)
 	if err != nil {
 		t.Fatalf("failed to create virtual network gateway connection: % +v", err)
 	}
 	t.Logf("created virtual network gateway connection")
 
-	err = BeginSetVirtualNetworkGatewayConnectionSharedKey(ctx, virtualNetworkGatewayConnectionName)
+	_, err = BeginSetVirtualNetworkGatewayConnectionSharedKey(ctx, virtualNetworkGatewayConnectionName)
 	if err != nil {
 		t.Fatalf("failed to set the virtual network gateway connection shared key: %+v", err)
 	}
 	t.Logf("set the virtual network gateway connection shared key")
 
-	err = GetVirtualNetworkGatewayConnectionSharedKey(ctx, virtualNetworkGatewayConnectionName)
+	_, err = GetVirtualNetworkGatewayConnectionSharedKey(ctx, virtualNetworkGatewayConnectionName)
 	if err != nil {
 		t.Fatalf("failed to get the virtual network gateway connection shared key: %+v", err)
 	}
 	t.Logf("got the virtual network gateway connection shared key")
 
-	err = GetVirtualNetworkGatewayConnection(ctx, virtualNetworkGatewayConnectionName)
+	_, err = GetVirtualNetworkGatewayConnection(ctx, virtualNetworkGatewayConnectionName)
 	if err != nil {
 		t.Fatalf("failed to get virtual network gateway connection: %+v", err)
 	}
 	t.Logf("got virtual network gateway connection")
 
-	err = ListVirtualNetworkGatewayConnection(ctx)
+	_, err = ListVirtualNetworkGatewayConnection(ctx)
 	if err != nil {
 		t.Fatalf("failed to list virtual network gateway connection: %+v", err)
 	}
@@ -237,7 +239,7 @@ func TestVirtualNetworkGatewayConnection(t *testing.T) {
 	// have filed issue: https://github.com/Azure/azure-rest-api-specs/issues/14473
 	time.Sleep(time.Duration(60) * time.Second)
 
-	err = BeginResetVirtualNetworkGatewayConnectionSharedKey(ctx, virtualNetworkGatewayConnectionName)
+	_, err = BeginResetVirtualNetworkGatewayConnectionSharedKey(ctx, virtualNetworkGatewayConnectionName)
 	if err != nil {
 		t.Fatalf("failed to begin reset the virtual network gateway connection shared key: %+v", err)
 	}
@@ -250,7 +252,7 @@ func TestVirtualNetworkGatewayConnection(t *testing.T) {
 	tagsObjectParameters := armnetwork.TagsObject{
 		Tags: map[string]*string{"tag1": to.StringPtr("value1"), "tag2": to.StringPtr("value2")},
 	}
-	err = UpdateVirtualNetworkGatewayConnectionTags(ctx, virtualNetworkGatewayConnectionName, tagsObjectParameters)
+	_, err = UpdateVirtualNetworkGatewayConnectionTags(ctx, virtualNetworkGatewayConnectionName, tagsObjectParameters)
 	if err != nil {
 		t.Fatalf("failed to update tags for virtual network gateway connection: %+v", err)
 	}
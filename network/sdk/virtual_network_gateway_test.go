@@ -11,15 +11,16 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
 )
 
 func TestVirtualNetworkGateway(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	virtualNetworkGatewayName := config.AppendRandomSuffix("virtualnetworkgateway")
 	publicIpAddressName := config.AppendRandomSuffix("pipaddress")
 	virtualNetworkName := config.AppendRandomSuffix("virtualnetwork")
@@ -28,6 +29,7 @@ func TestVirtualNetworkGateway(t *testing.T) {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5000*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -123,44 +125,44 @@ func TestVirtualNetworkGateway(t *testing.T) {
 	}
 	t.Logf("created virtual network gateway")
 
-	err = ListVirtualNetworkGatewayConnections(ctx, virtualNetworkGatewayName)
+	_, err = ListVirtualNetworkGatewayConnections(ctx, virtualNetworkGatewayName)
 	if err != nil {
 		t.Fatalf("failed to list virtual network gateway connection: %+v", err)
 	}
 	t.Logf("got virtual network gateway connection")
 
-	err = GetVirtualNetworkGateway(ctx, virtualNetworkGatewayName)
+	_, err = GetVirtualNetworkGateway(ctx, virtualNetworkGatewayName)
 	if err != nil {
 		t.Fatalf("failed to get virtual network gateway: %+v", err)
 	}
 	t.Logf("got virtual network gateway")
 
-	err = ListVirtualNetworkGateway(ctx)
+	_, err = ListVirtualNetworkGateway(ctx)
 	if err != nil {
 		t.Fatalf("failed to list virtual network gateway: %+v", err)
 	}
 	t.Logf("listed virtual network gateway")
 
 	peer := "10.0.0.2"
-	err = BeginGetVirtualNetworkGatewayAdvertisedRoute(ctx, virtualNetworkGatewayName, peer)
+	_, err = BeginGetVirtualNetworkGatewayAdvertisedRoute(ctx, virtualNetworkGatewayName, peer)
 	if err != nil {
 		t.Fatalf("failed to begin get virtual network gateway advertised route: %+v", err)
 	}
 	t.Logf("began get virtual network gateway advertised route")
 
-	err = BeginGetVirtualNetworkGatewayBgpPeerStatus(ctx, virtualNetworkGatewayName)
+	_, err = BeginGetVirtualNetworkGatewayBgpPeerStatus(ctx, virtualNetworkGatewayName)
 	if err != nil {
 		t.Fatalf("failed to begin get bgp peer status: %+v", err)
 	}
 	t.Logf("began get virtual network gateway bgp peer status")
 
-	err = BeginGetVirtualNetworkGatewayLearnedRoutes(ctx, virtualNetworkGatewayName)
+	_, err = BeginGetVirtualNetworkGatewayLearnedRoutes(ctx, virtualNetworkGatewayName)
 	if err != nil {
 		t.Fatalf("failed to begin get learned route: %+v", err)
 	}
 	t.Logf("began get virtual network gateway learned route")
 
-	err = BeginVirtualNetworkGatewayReset(ctx, virtualNetworkGatewayName)
+	_, err = BeginVirtualNetworkGatewayReset(ctx, virtualNetworkGatewayName)
 	if err != nil {
 		t.Fatalf("failed to begin reset: %+v", err)
 	}
@@ -169,7 +171,7 @@ func TestVirtualNetworkGateway(t *testing.T) {
 	tagsObjectParameters := armnetwork.TagsObject{
 		Tags: map[string]*string{"tag1": to.StringPtr("value1"), "tag2": to.StringPtr("value2")},
 	}
-	err = UpdateVirtualNetworkGatewayTags(ctx, virtualNetworkGatewayName, tagsObjectParameters)
+	_, err = UpdateVirtualNetworkGatewayTags(ctx, virtualNetworkGatewayName, tagsObjectParameters)
 	if err != nil {
 		t.Fatalf("failed to update tags for virtual network gateway: %+v", err)
 	}
@@ -11,26 +11,26 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getVirtualNetworkPeeringsClient() armnetwork.VirtualNetworkPeeringsClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getVirtualNetworkPeeringsClient(ctx context.Context) armnetwork.VirtualNetworkPeeringsClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewVirtualNetworkPeeringsClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewVirtualNetworkPeeringsClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Create VirtualNetworkPeerings
-func CreateVirtualNetworkPeering(ctx context.Context, virtualNetworkName string, virtualNetworkPeeringName string, virtualNetworkPeeringParameters armnetwork.VirtualNetworkPeering) error {
-	client := getVirtualNetworkPeeringsClient()
+func CreateVirtualNetworkPeering(ctx context.Context, virtualNetworkName string, virtualNetworkPeeringName string, virtualNetworkPeeringParameters armnetwork.VirtualNetworkPeering) (*armnetwork.VirtualNetworkPeering, error) {
+	client := getVirtualNetworkPeeringsClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		virtualNetworkName,
 		virtualNetworkPeeringName,
 		virtualNetworkPeeringParameters,
@@ -38,51 +38,50 @@ func CreateVirtualNetworkPeering(ctx context.Context, virtualNetworkName string,
 	)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VirtualNetworkPeering, nil
 }
 
-//  Gets the specified virtual network peering.
-func GetVirtualNetworkPeering(ctx context.Context, virtualNetworkName string, virtualNetworkPeeringName string) error {
-	client := getVirtualNetworkPeeringsClient()
-	_, err := client.Get(ctx, config.GroupName(), virtualNetworkName, virtualNetworkPeeringName, nil)
+// Gets the specified virtual network peering.
+func GetVirtualNetworkPeering(ctx context.Context, virtualNetworkName string, virtualNetworkPeeringName string) (*armnetwork.VirtualNetworkPeering, error) {
+	client := getVirtualNetworkPeeringsClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, virtualNetworkName, virtualNetworkPeeringName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VirtualNetworkPeering, nil
 }
 
 // Gets all virtual network peerings in a virtual network.
-func ListVirtualNetworkPeering(ctx context.Context, virtualNetworkName string) error {
-	client := getVirtualNetworkPeeringsClient()
-	pager := client.List(config.GroupName(), virtualNetworkName, nil)
+func ListVirtualNetworkPeering(ctx context.Context, virtualNetworkName string) ([]*armnetwork.VirtualNetworkPeering, error) {
+	client := getVirtualNetworkPeeringsClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).GroupName, virtualNetworkName, nil)
 
+	var results []*armnetwork.VirtualNetworkPeering
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().VirtualNetworkPeeringListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Deletes the specified virtual network peering.
 func DeleteVirtualNetworkPeering(ctx context.Context, virtualNetworkName string, virtualNetworkPeeringName string) error {
-	client := getVirtualNetworkPeeringsClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), virtualNetworkName, virtualNetworkPeeringName, nil)
+	client := getVirtualNetworkPeeringsClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, virtualNetworkName, virtualNetworkPeeringName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
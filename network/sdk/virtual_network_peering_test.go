@@ -11,15 +11,16 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
 )
 
 func TestVirtualNetworkPeering(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	virtualNetworkPeeringName := config.AppendRandomSuffix("virtualnetworkpeering")
 	virtualNetworkName := config.AppendRandomSuffix("virtualnetwork")
 	remoteVirtualNetworkName := config.AppendRandomSuffix("remotevirtualnetwork")
@@ -27,6 +28,7 @@ func TestVirtualNetworkPeering(t *testing.T) {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -88,19 +90,19 @@ func TestVirtualNetworkPeering(t *testing.T) {
 			UseRemoteGateways: to.BoolPtr(false),
 		},
 	}
-	err = CreateVirtualNetworkPeering(ctx, virtualNetworkName, virtualNetworkPeeringName, virtualNetworkPeeringParameters)
+	_, err = CreateVirtualNetworkPeering(ctx, virtualNetworkName, virtualNetworkPeeringName, virtualNetworkPeeringParameters)
 	if err != nil {
 		t.Fatalf("failed to create virtual network peering: % +v", err)
 	}
 	t.Logf("created virtual network peering")
 
-	err = GetVirtualNetworkPeering(ctx, virtualNetworkName, virtualNetworkPeeringName)
+	_, err = GetVirtualNetworkPeering(ctx, virtualNetworkName, virtualNetworkPeeringName)
 	if err != nil {
 		t.Fatalf("failed to get virtual network peering: %+v", err)
 	}
 	t.Logf("got virtual network peering")
 
-	err = ListVirtualNetworkPeering(ctx, virtualNetworkName)
+	_, err = ListVirtualNetworkPeering(ctx, virtualNetworkName)
 	if err != nil {
 		t.Fatalf("failed to list virtual network peering: %+v", err)
 	}
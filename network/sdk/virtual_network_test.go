@@ -11,20 +11,22 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
 )
 
 func TestVirtualNetwork(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	virtualNetworkName := config.AppendRandomSuffix("virtualnetwork")
 	subNetName := config.AppendRandomSuffix("subnet")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -61,31 +63,37 @@ func TestVirtualNetwork(t *testing.T) {
 	}
 
 	ipAddress := "10.0.1.4"
-	err = CheckIPAddressAvailability(ctx, virtualNetworkName, ipAddress)
+	_, err = CheckIPAddressAvailability(ctx, virtualNetworkName, ipAddress)
 	if err != nil {
 		t.Fatalf("failed to check ip address availability: %+v", err)
 	}
 	t.Logf("checked ip address availability")
 
-	err = ListUsageVirtualNetwork(ctx, virtualNetworkName)
+	_, err = ListUsageVirtualNetwork(ctx, virtualNetworkName)
 	if err != nil {
 		t.Fatalf("failed to list usage virtual network: %+v", err)
 	}
 	t.Logf("listed usage virtual network")
 
-	err = GetVirtualNetwork(ctx, virtualNetworkName)
+	virtualNetwork, err := GetVirtualNetwork(ctx, virtualNetworkName)
 	if err != nil {
 		t.Fatalf("failed to get virtual network: %+v", err)
 	}
+	if *virtualNetwork.Name != virtualNetworkName {
+		t.Fatalf("expected virtual network %s, got %s", virtualNetworkName, *virtualNetwork.Name)
+	}
 	t.Logf("got virtual network")
 
-	err = ListVirtualNetwork(ctx)
+	virtualNetworks, err := ListVirtualNetwork(ctx)
 	if err != nil {
 		t.Fatalf("failed to list virtual network: %+v", err)
 	}
+	if len(virtualNetworks) != 1 {
+		t.Fatalf("expected 1 virtual network, got %d", len(virtualNetworks))
+	}
 	t.Logf("listed virtual network")
 
-	err = ListAllVirtualNetwork(ctx)
+	_, err = ListAllVirtualNetwork(ctx)
 	if err != nil {
 		t.Fatalf("failed to list all virtual network: %+v", err)
 	}
@@ -94,7 +102,7 @@ func TestVirtualNetwork(t *testing.T) {
 	tagsObjectParameters := armnetwork.TagsObject{
 		Tags: map[string]*string{"tag1": to.StringPtr("value1"), "tag2": to.StringPtr("value2")},
 	}
-	err = UpdateVirtualNetworkTags(ctx, virtualNetworkName, tagsObjectParameters)
+	_, err = UpdateVirtualNetworkTags(ctx, virtualNetworkName, tagsObjectParameters)
 	if err != nil {
 		t.Fatalf("failed to update tags for virtual network: %+v", err)
 	}
@@ -11,26 +11,26 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getVirtualWansClient() armnetwork.VirtualWansClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getVirtualWansClient(ctx context.Context) armnetwork.VirtualWansClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewVirtualWansClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewVirtualWansClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Create VirtualWans
 func CreateVirtualWan(ctx context.Context, virtualWanName string, virtualWANParameters armnetwork.VirtualWAN) (string, error) {
-	client := getVirtualWansClient()
+	client := getVirtualWansClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		virtualWanName,
 		virtualWANParameters,
 		nil,
@@ -40,7 +40,7 @@ func CreateVirtualWan(ctx context.Context, virtualWanName string, virtualWANPara
 		return "", err
 	}
 
-	resp, err := poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return "", err
 	}
@@ -49,56 +49,55 @@ func CreateVirtualWan(ctx context.Context, virtualWanName string, virtualWANPara
 }
 
 // Gets the specified virtual wan in a specified resource group.
-func GetVirtualWan(ctx context.Context, virtualWanName string) error {
-	client := getVirtualWansClient()
-	_, err := client.Get(ctx, config.GroupName(), virtualWanName, nil)
+func GetVirtualWan(ctx context.Context, virtualWanName string) (*armnetwork.VirtualWAN, error) {
+	client := getVirtualWansClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, virtualWanName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VirtualWAN, nil
 }
 
 // Gets all the virtual wan in a subscription.
-func ListVirtualWan(ctx context.Context) error {
-	client := getVirtualWansClient()
+func ListVirtualWan(ctx context.Context) ([]*armnetwork.VirtualWAN, error) {
+	client := getVirtualWansClient(ctx)
 	pager := client.List(nil)
 
+	var results []*armnetwork.VirtualWAN
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().ListVirtualWANsResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Updates virtual wan tags.
-func UpdateVirtualWanTags(ctx context.Context, virtualWanName string, tagsObjectParameters armnetwork.TagsObject) error {
-	client := getVirtualWansClient()
-	_, err := client.UpdateTags(
+func UpdateVirtualWanTags(ctx context.Context, virtualWanName string, tagsObjectParameters armnetwork.TagsObject) (*armnetwork.VirtualWAN, error) {
+	client := getVirtualWansClient(ctx)
+	resp, err := client.UpdateTags(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		virtualWanName,
 		tagsObjectParameters,
 		nil,
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VirtualWAN, nil
 }
 
 // Deletes the specified virtual wan.
 func DeleteVirtualWan(ctx context.Context, virtualWanName string) error {
-	client := getVirtualWansClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), virtualWanName, nil)
+	client := getVirtualWansClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, virtualWanName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -106,17 +105,17 @@ func DeleteVirtualWan(ctx context.Context, virtualWanName string) error {
 }
 
 // Gets all virtual wan in a resource group.
-func ListVirtualWanByResourceGroup(ctx context.Context) error {
-	client := getVirtualWansClient()
-	pager := client.ListByResourceGroup(config.GroupName(), nil)
+func ListVirtualWanByResourceGroup(ctx context.Context) ([]*armnetwork.VirtualWAN, error) {
+	client := getVirtualWansClient(ctx)
+	pager := client.ListByResourceGroup(config.ScopeFrom(ctx).GroupName, nil)
+
+	var results []*armnetwork.VirtualWAN
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().ListVirtualWANsResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
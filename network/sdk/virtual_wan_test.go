@@ -11,19 +11,21 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
 )
 
 func TestVirtualWan(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	virtualWanName := config.AppendRandomSuffix("virtualwan")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -47,19 +49,19 @@ func TestVirtualWan(t *testing.T) {
 	}
 	t.Logf("created virtual wan")
 
-	err = GetVirtualWan(ctx, virtualWanName)
+	_, err = GetVirtualWan(ctx, virtualWanName)
 	if err != nil {
 		t.Fatalf("failed to get virtual wan: %+v", err)
 	}
 	t.Logf("got virtual wan")
 
-	err = ListVirtualWan(ctx)
+	_, err = ListVirtualWan(ctx)
 	if err != nil {
 		t.Fatalf("failed to list virtual wan: %+v", err)
 	}
 	t.Logf("listed virtual wan")
 
-	err = ListVirtualWanByResourceGroup(ctx)
+	_, err = ListVirtualWanByResourceGroup(ctx)
 	if err != nil {
 		t.Fatalf("failed to list virtual wan by resource group: %+v", err)
 	}
@@ -68,7 +70,7 @@ func TestVirtualWan(t *testing.T) {
 	tagsObjectParameters := armnetwork.TagsObject{
 		Tags: map[string]*string{"key1": to.StringPtr("value1"), "key2": to.StringPtr("value2")},
 	}
-	err = UpdateVirtualWanTags(ctx, virtualWanName, tagsObjectParameters)
+	_, err = UpdateVirtualWanTags(ctx, virtualWanName, tagsObjectParameters)
 	if err != nil {
 		t.Fatalf("failed to update tags for virtual wan: %+v", err)
 	}
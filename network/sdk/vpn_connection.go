@@ -11,26 +11,26 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getVPNConnectionsClient() armnetwork.VPNConnectionsClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getVPNConnectionsClient(ctx context.Context) armnetwork.VPNConnectionsClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewVPNConnectionsClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewVPNConnectionsClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Creates a vpn connection to a scalable vpn gateway if it doesn't exist else updates the existing connection
-func CreateVpnConnection(ctx context.Context, gatewayName string, connectionName string, vpnConnectionParameters armnetwork.VPNConnection) error {
-	client := getVPNConnectionsClient()
+func CreateVpnConnection(ctx context.Context, gatewayName string, connectionName string, vpnConnectionParameters armnetwork.VPNConnection) (*armnetwork.VPNConnection, error) {
+	client := getVPNConnectionsClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		gatewayName,
 		connectionName,
 		vpnConnectionParameters,
@@ -38,34 +38,34 @@ func CreateVpnConnection(ctx context.Context, gatewayName string, connectionName
 	)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VPNConnection, nil
 }
 
 // Retrieves the details of a vpn connection.
-func GetVpnConnection(ctx context.Context, gatewayName string, connectionName string) error {
-	client := getVPNConnectionsClient()
-	_, err := client.Get(ctx, config.GroupName(), gatewayName, connectionName, nil)
+func GetVpnConnection(ctx context.Context, gatewayName string, connectionName string) (*armnetwork.VPNConnection, error) {
+	client := getVPNConnectionsClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, gatewayName, connectionName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VPNConnection, nil
 }
 
-//  Deletes a vpn connection.
+// Deletes a vpn connection.
 func DeleteVpnConnection(ctx context.Context, gatewayName string, connectionName string) error {
-	client := getVPNConnectionsClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), gatewayName, connectionName, nil)
+	client := getVPNConnectionsClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, gatewayName, connectionName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -73,17 +73,17 @@ func DeleteVpnConnection(ctx context.Context, gatewayName string, connectionName
 }
 
 // Retrieves all vpn connections for a particular virtual wan vpn gateway.
-func ListVpnConnectionByVpnGateway(ctx context.Context, gatewayName string) error {
-	client := getVPNConnectionsClient()
-	pager := client.ListByVPNGateway(config.GroupName(), gatewayName, nil)
+func ListVpnConnectionByVpnGateway(ctx context.Context, gatewayName string) ([]*armnetwork.VPNConnection, error) {
+	client := getVPNConnectionsClient(ctx)
+	pager := client.ListByVPNGateway(config.ScopeFrom(ctx).GroupName, gatewayName, nil)
+
+	var results []*armnetwork.VPNConnection
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().ListVPNConnectionsResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
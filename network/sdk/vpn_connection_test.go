@@ -11,15 +11,16 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
 )
 
 func TestVpnConnection(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	vpnConnectionName := config.AppendRandomSuffix("vpnconnection")
 	vpnGatewayName := config.AppendRandomSuffix("vpngateway")
 	virtualWanName := config.AppendRandomSuffix("virtualwan")
@@ -29,6 +30,7 @@ func TestVpnConnection(t *testing.T) {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3000*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -142,7 +144,7 @@ func TestVpnConnection(t *testing.T) {
 			},
 		},
 	}
-	err = CreateVpnGateway(ctx, vpnGatewayName, vpnGatewayParameters)
+	_, err = CreateVpnGateway(ctx, vpnGatewayName, vpnGatewayParameters)
 	if err != nil {
 		t.Fatalf("failed to create vpn gateway: % +v", err)
 	}
@@ -166,19 +168,19 @@ func TestVpnConnection(t *testing.T) {
 			}},
 		},
 	}
-	err = CreateVpnConnection(ctx, vpnGatewayName, vpnConnectionName, vpnConnectionParameters)
+	_, err = CreateVpnConnection(ctx, vpnGatewayName, vpnConnectionName, vpnConnectionParameters)
 	if err != nil {
 		t.Fatalf("failed to create vpn connection: % +v", err)
 	}
 	t.Logf("created vpn connection")
 
-	err = GetVpnConnection(ctx, vpnGatewayName, vpnConnectionName)
+	_, err = GetVpnConnection(ctx, vpnGatewayName, vpnConnectionName)
 	if err != nil {
 		t.Fatalf("failed to get vpn connection: %+v", err)
 	}
 	t.Logf("got vpn connection")
 
-	err = ListVpnConnectionByVpnGateway(ctx, vpnGatewayName)
+	_, err = ListVpnConnectionByVpnGateway(ctx, vpnGatewayName)
 	if err != nil {
 		t.Fatalf("failed to list vpn connection by resource group: %+v", err)
 	}
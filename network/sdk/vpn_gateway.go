@@ -11,77 +11,76 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getVpnGatewaysClient() armnetwork.VPNGatewaysClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getVpnGatewaysClient(ctx context.Context) armnetwork.VPNGatewaysClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewVPNGatewaysClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewVPNGatewaysClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Creates a virtual wan vpn gateway if it doesn't exist else updates the existing gateway
-func CreateVpnGateway(ctx context.Context, vpnGatewayName string, vpnGatewayParameters armnetwork.VPNGateway) error {
-	client := getVpnGatewaysClient()
+func CreateVpnGateway(ctx context.Context, vpnGatewayName string, vpnGatewayParameters armnetwork.VPNGateway) (*armnetwork.VPNGateway, error) {
+	client := getVpnGatewaysClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		vpnGatewayName,
 		vpnGatewayParameters,
 		nil,
 	)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VPNGateway, nil
 }
 
 // Retrieves the details of a virtual wan vpn gateway.
-func GetVpnGateway(ctx context.Context, vpnGatewayName string) error {
-	client := getVpnGatewaysClient()
-	_, err := client.Get(ctx, config.GroupName(), vpnGatewayName, nil)
+func GetVpnGateway(ctx context.Context, vpnGatewayName string) (*armnetwork.VPNGateway, error) {
+	client := getVpnGatewaysClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, vpnGatewayName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VPNGateway, nil
 }
 
 // Lists all the VpnGateways in a subscription.
-func ListVpnGateway(ctx context.Context) error {
-	client := getVpnGatewaysClient()
+func ListVpnGateway(ctx context.Context) ([]*armnetwork.VPNGateway, error) {
+	client := getVpnGatewaysClient(ctx)
 	pager := client.List(nil)
 
+	var results []*armnetwork.VPNGateway
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().ListVPNGatewaysResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Deletes a virtual wan vpn gatewa.
 func DeleteVpnGateway(ctx context.Context, vpnGatewayName string) error {
-	client := getVpnGatewaysClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), vpnGatewayName, nil)
+	client := getVpnGatewaysClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, vpnGatewayName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -89,32 +88,32 @@ func DeleteVpnGateway(ctx context.Context, vpnGatewayName string) error {
 }
 
 // Gets all vpn gateway in a resource group.
-func ListVpnGatewayByResourceGroup(ctx context.Context) error {
-	client := getVpnGatewaysClient()
-	pager := client.ListByResourceGroup(config.GroupName(), nil)
+func ListVpnGatewayByResourceGroup(ctx context.Context) ([]*armnetwork.VPNGateway, error) {
+	client := getVpnGatewaysClient(ctx)
+	pager := client.ListByResourceGroup(config.ScopeFrom(ctx).GroupName, nil)
+
+	var results []*armnetwork.VPNGateway
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().ListVPNGatewaysResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Resets the primary of the vpn gateway in the specified resource group.
-func ResetVpnGateway(ctx context.Context, vpnGatewayName string) error {
-	client := getVpnGatewaysClient()
-	poller, err := client.BeginReset(ctx, config.GroupName(), vpnGatewayName, nil)
+func ResetVpnGateway(ctx context.Context, vpnGatewayName string) (*armnetwork.VPNGateway, error) {
+	client := getVpnGatewaysClient(ctx)
+	poller, err := client.BeginReset(ctx, config.ScopeFrom(ctx).GroupName, vpnGatewayName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VPNGateway, nil
 }
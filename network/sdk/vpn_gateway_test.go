@@ -11,15 +11,16 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
 )
 
 func TestVpnGateway(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	vpnGatewayName := config.AppendRandomSuffix("vpngateway")
 	virtualWanName := config.AppendRandomSuffix("virtualwan")
 	virtualHubName := config.AppendRandomSuffix("virtualhub")
@@ -28,6 +29,7 @@ func TestVpnGateway(t *testing.T) {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 4000*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -141,31 +143,31 @@ func TestVpnGateway(t *testing.T) {
 			},
 		},
 	}
-	err = CreateVpnGateway(ctx, vpnGatewayName, vpnGatewayParameters)
+	_, err = CreateVpnGateway(ctx, vpnGatewayName, vpnGatewayParameters)
 	if err != nil {
 		t.Fatalf("failed to create vpn gateway: % +v", err)
 	}
 	t.Logf("created vpn gateway")
 
-	err = GetVpnGateway(ctx, vpnGatewayName)
+	_, err = GetVpnGateway(ctx, vpnGatewayName)
 	if err != nil {
 		t.Fatalf("failed to get vpn gateway: %+v", err)
 	}
 	t.Logf("got vpn gateway")
 
-	err = ListVpnGateway(ctx)
+	_, err = ListVpnGateway(ctx)
 	if err != nil {
 		t.Fatalf("failed to list vpn gateway: %+v", err)
 	}
 	t.Logf("listed vpn gateway")
 
-	err = ListVpnGatewayByResourceGroup(ctx)
+	_, err = ListVpnGatewayByResourceGroup(ctx)
 	if err != nil {
 		t.Fatalf("failed to list vpn gateway by resource group: %+v", err)
 	}
 	t.Logf("listed vpn gateway by resource group")
 
-	err = ResetVpnGateway(ctx, vpnGatewayName)
+	_, err = ResetVpnGateway(ctx, vpnGatewayName)
 	if err != nil {
 		t.Fatalf("failed to reset gateway by resource group: %+v", err)
 	}
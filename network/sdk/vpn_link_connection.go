@@ -10,32 +10,31 @@ import (
 	"log"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getVPNLinkConnectionsClient() armnetwork.VPNLinkConnectionsClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getVPNLinkConnectionsClient(ctx context.Context) armnetwork.VPNLinkConnectionsClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewVPNLinkConnectionsClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewVPNLinkConnectionsClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
-//  Retrieves all vpn site link connections for a particular virtual wan vpn gateway vpn connection.
-func ListVpnLinkConnectionByVpnConnection(ctx context.Context, gatewayName string, connectionName string) error {
-	client := getVPNLinkConnectionsClient()
-	pager := client.ListByVPNConnection(config.GroupName(), gatewayName, connectionName, nil)
+// Retrieves all vpn site link connections for a particular virtual wan vpn gateway vpn connection.
+func ListVpnLinkConnectionByVpnConnection(ctx context.Context, gatewayName string, connectionName string) ([]*armnetwork.VPNSiteLinkConnection, error) {
+	client := getVPNLinkConnectionsClient(ctx)
+	pager := client.ListByVPNConnection(config.ScopeFrom(ctx).GroupName, gatewayName, connectionName, nil)
+
+	var results []*armnetwork.VPNSiteLinkConnection
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().ListVPNSiteLinkConnectionsResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
@@ -11,26 +11,26 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getVpnServerConfigurationsClient() armnetwork.VPNServerConfigurationsClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getVpnServerConfigurationsClient(ctx context.Context) armnetwork.VPNServerConfigurationsClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewVPNServerConfigurationsClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewVPNServerConfigurationsClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Creates a VpnServerConfiguration resource if it doesn't exist else updates the existing VpnServerConfiguration.
 func CreateVpnServerConfiguration(ctx context.Context, vpnServerConfigurationName string, vpnServerConfigurationParameters armnetwork.VPNServerConfiguration) (string, error) {
-	client := getVpnServerConfigurationsClient()
+	client := getVpnServerConfigurationsClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		vpnServerConfigurationName,
 		vpnServerConfigurationParameters,
 		nil,
@@ -40,7 +40,7 @@ func CreateVpnServerConfiguration(ctx context.Context, vpnServerConfigurationNam
 		return "", err
 	}
 
-	resp, err := poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return "", err
 	}
@@ -49,56 +49,55 @@ func CreateVpnServerConfiguration(ctx context.Context, vpnServerConfigurationNam
 }
 
 // Gets the specified vpn server configuration in a specified resource group.
-func GetVpnServerConfiguration(ctx context.Context, vpnServerConfigurationName string) error {
-	client := getVpnServerConfigurationsClient()
-	_, err := client.Get(ctx, config.GroupName(), vpnServerConfigurationName, nil)
+func GetVpnServerConfiguration(ctx context.Context, vpnServerConfigurationName string) (*armnetwork.VPNServerConfiguration, error) {
+	client := getVpnServerConfigurationsClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, vpnServerConfigurationName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VPNServerConfiguration, nil
 }
 
 // Gets all the vpn server configuration in a subscription.
-func ListVpnServerConfiguration(ctx context.Context) error {
-	client := getVpnServerConfigurationsClient()
+func ListVpnServerConfiguration(ctx context.Context) ([]*armnetwork.VPNServerConfiguration, error) {
+	client := getVpnServerConfigurationsClient(ctx)
 	pager := client.List(nil)
 
+	var results []*armnetwork.VPNServerConfiguration
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().ListVPNServerConfigurationsResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Updates vpn server configuration tags.
-func UpdateVpnServerConfigurationTags(ctx context.Context, vpnServerConfigurationName string, vpnServerConfigurationParameters armnetwork.TagsObject) error {
-	client := getVpnServerConfigurationsClient()
-	_, err := client.UpdateTags(
+func UpdateVpnServerConfigurationTags(ctx context.Context, vpnServerConfigurationName string, vpnServerConfigurationParameters armnetwork.TagsObject) (*armnetwork.VPNServerConfiguration, error) {
+	client := getVpnServerConfigurationsClient(ctx)
+	resp, err := client.UpdateTags(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		vpnServerConfigurationName,
 		vpnServerConfigurationParameters,
 		nil,
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VPNServerConfiguration, nil
 }
 
 // Deletes the specified vpn server configuration.
 func DeleteVpnServerConfiguration(ctx context.Context, vpnServerConfigurationName string) error {
-	client := getVpnServerConfigurationsClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), vpnServerConfigurationName, nil)
+	client := getVpnServerConfigurationsClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, vpnServerConfigurationName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -106,17 +105,17 @@ func DeleteVpnServerConfiguration(ctx context.Context, vpnServerConfigurationNam
 }
 
 // Gets all vpn server configuration in a resource group.
-func ListVpnServerConfigurationByResourceGroup(ctx context.Context) error {
-	client := getVpnServerConfigurationsClient()
-	pager := client.ListByResourceGroup(config.GroupName(), nil)
+func ListVpnServerConfigurationByResourceGroup(ctx context.Context) ([]*armnetwork.VPNServerConfiguration, error) {
+	client := getVpnServerConfigurationsClient(ctx)
+	pager := client.ListByResourceGroup(config.ScopeFrom(ctx).GroupName, nil)
+
+	var results []*armnetwork.VPNServerConfiguration
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().ListVPNServerConfigurationsResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
@@ -11,19 +11,21 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/go-autorest/autorest/to"
 )
 
 func TestVpnServerConfiguration(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	vpnServerConfigurationName := config.AppendRandomSuffix("vpnserverconfiguration")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -50,19 +52,19 @@ func TestVpnServerConfiguration(t *testing.T) {
 	}
 	t.Logf("created vpn server configuration")
 
-	err = GetVpnServerConfiguration(ctx, vpnServerConfigurationName)
+	_, err = GetVpnServerConfiguration(ctx, vpnServerConfigurationName)
 	if err != nil {
 		t.Fatalf("failed to get vpn server configuration: %+v", err)
 	}
 	t.Logf("got vpn server configuration")
 
-	err = ListVpnServerConfiguration(ctx)
+	_, err = ListVpnServerConfiguration(ctx)
 	if err != nil {
 		t.Fatalf("failed to list vpn server configuration: %+v", err)
 	}
 	t.Logf("listed vpn server configuration")
 
-	err = ListVpnServerConfigurationByResourceGroup(ctx)
+	_, err = ListVpnServerConfigurationByResourceGroup(ctx)
 	if err != nil {
 		t.Fatalf("failed to listvpn server configuration by resource group: %+v", err)
 	}
@@ -71,7 +73,7 @@ func TestVpnServerConfiguration(t *testing.T) {
 	tagsObjectParameters := armnetwork.TagsObject{
 		Tags: map[string]*string{"tag1": to.StringPtr("value1"), "tag2": to.StringPtr("value2")},
 	}
-	err = UpdateVpnServerConfigurationTags(ctx, vpnServerConfigurationName, tagsObjectParameters)
+	_, err = UpdateVpnServerConfigurationTags(ctx, vpnServerConfigurationName, tagsObjectParameters)
 	if err != nil {
 		t.Fatalf("failed to update tags for vpn server configuration: %+v", err)
 	}
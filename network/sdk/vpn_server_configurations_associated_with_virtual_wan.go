@@ -11,33 +11,33 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getVpnServerConfigurationsAssociatedWithVirtualWansClient() armnetwork.VPNServerConfigurationsAssociatedWithVirtualWanClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getVpnServerConfigurationsAssociatedWithVirtualWansClient(ctx context.Context) armnetwork.VPNServerConfigurationsAssociatedWithVirtualWanClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewVPNServerConfigurationsAssociatedWithVirtualWanClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewVPNServerConfigurationsAssociatedWithVirtualWanClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Gives the list of VpnServerConfigurations associated with Virtual Wan in a resource group.
-func ListVpnServerConfigurationsAssociatedWithVirtualWan(ctx context.Context, virtualWANName string) error {
-	client := getVpnServerConfigurationsAssociatedWithVirtualWansClient()
-	poller, err := client.BeginList(ctx, config.GroupName(), virtualWANName, nil)
+func ListVpnServerConfigurationsAssociatedWithVirtualWan(ctx context.Context, virtualWANName string) (*armnetwork.VPNServerConfigurationsResponse, error) {
+	client := getVpnServerConfigurationsAssociatedWithVirtualWansClient(ctx)
+	poller, err := client.BeginList(ctx, config.ScopeFrom(ctx).GroupName, virtualWANName, nil)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return resp.VPNServerConfigurationsResponse, nil
 }
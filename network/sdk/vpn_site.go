@@ -11,26 +11,26 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getVpnSitesClient() armnetwork.VPNSitesClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getVpnSitesClient(ctx context.Context) armnetwork.VPNSitesClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewVPNSitesClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewVPNSitesClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Creates a VpnSite resource if it doesn't exist else updates the existing VpnSite.
 func CreateVpnSite(ctx context.Context, vpnSiteName string, vpnSiteParameters armnetwork.VPNSite) (string, error) {
-	client := getVpnSitesClient()
+	client := getVpnSitesClient(ctx)
 	poller, err := client.BeginCreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		vpnSiteName,
 		vpnSiteParameters,
 		nil,
@@ -40,7 +40,7 @@ func CreateVpnSite(ctx context.Context, vpnSiteName string, vpnSiteParameters ar
 		return "", err
 	}
 
-	resp, err := poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return "", err
 	}
@@ -48,46 +48,45 @@ func CreateVpnSite(ctx context.Context, vpnSiteName string, vpnSiteParameters ar
 }
 
 // Lists all the VpnSites in a subscription.
-func ListVpnSite(ctx context.Context) error {
-	client := getVpnSitesClient()
+func ListVpnSite(ctx context.Context) ([]*armnetwork.VPNSite, error) {
+	client := getVpnSitesClient(ctx)
 	pager := client.List(nil)
 
+	var results []*armnetwork.VPNSite
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().ListVPNSitesResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Updates vpn site tags.
-func UpdateVpnSiteTags(ctx context.Context, vpnSiteName string, tagsObjectParameters armnetwork.TagsObject) error {
-	client := getVpnSitesClient()
-	_, err := client.UpdateTags(
+func UpdateVpnSiteTags(ctx context.Context, vpnSiteName string, tagsObjectParameters armnetwork.TagsObject) (*armnetwork.VPNSite, error) {
+	client := getVpnSitesClient(ctx)
+	resp, err := client.UpdateTags(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		vpnSiteName,
 		tagsObjectParameters,
 		nil,
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VPNSite, nil
 }
 
 // Deletes a VpnSite
 func DeleteVpnSite(ctx context.Context, vpnSiteName string) error {
-	client := getVpnSitesClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), vpnSiteName, nil)
+	client := getVpnSitesClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, vpnSiteName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -95,17 +94,17 @@ func DeleteVpnSite(ctx context.Context, vpnSiteName string) error {
 }
 
 // Lists all the vpnSites in a resource group.
-func ListVpnSiteByResourceGroup(ctx context.Context) error {
-	client := getVpnSitesClient()
-	pager := client.ListByResourceGroup(config.GroupName(), nil)
+func ListVpnSiteByResourceGroup(ctx context.Context) ([]*armnetwork.VPNSite, error) {
+	client := getVpnSitesClient(ctx)
+	pager := client.ListByResourceGroup(config.ScopeFrom(ctx).GroupName, nil)
+
+	var results []*armnetwork.VPNSite
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().ListVPNSitesResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
@@ -10,44 +10,42 @@ import (
 	"log"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getVpnSiteLinksClient() armnetwork.VPNSiteLinksClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getVpnSiteLinksClient(ctx context.Context) armnetwork.VPNSiteLinksClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewVPNSiteLinksClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewVPNSiteLinksClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Lists all the vpnSiteLinks in a resource group for a vpn site.
-func ListVpnSiteLinkByVPNSite(ctx context.Context, vpnSiteName string) error {
-	client := getVpnSiteLinksClient()
-	pager := client.ListByVPNSite(config.GroupName(), vpnSiteName, nil)
+func ListVpnSiteLinkByVPNSite(ctx context.Context, vpnSiteName string) ([]*armnetwork.VPNSiteLink, error) {
+	client := getVpnSiteLinksClient(ctx)
+	pager := client.ListByVPNSite(config.ScopeFrom(ctx).GroupName, vpnSiteName, nil)
 
+	var results []*armnetwork.VPNSiteLink
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().ListVPNSiteLinksResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Retrieves the details of a VPN site link
-func GetVpnSiteLink(ctx context.Context, vpnSiteName string, vpnSiteLinkName string) error {
-	client := getVpnSiteLinksClient()
-	_, err := client.Get(ctx, config.GroupName(), vpnSiteName, vpnSiteLinkName, nil)
+func GetVpnSiteLink(ctx context.Context, vpnSiteName string, vpnSiteLinkName string) (*armnetwork.VPNSiteLink, error) {
+	client := getVpnSiteLinksClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, vpnSiteName, vpnSiteLinkName, nil)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VPNSiteLink, nil
 }
@@ -11,21 +11,23 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
 )
 
 func TestVpnSiteLink(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	vpnSiteName := config.AppendRandomSuffix("vpnsite")
 	virtualWanName := config.AppendRandomSuffix("virtualwan")
 	vpnSiteLinkName := "vpnSiteLink1"
 
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -82,13 +84,13 @@ func TestVpnSiteLink(t *testing.T) {
 		t.Fatalf("failed to create vpn site: % +v", err)
 	}
 
-	err = ListVpnSiteLinkByVPNSite(ctx, vpnSiteName)
+	_, err = ListVpnSiteLinkByVPNSite(ctx, vpnSiteName)
 	if err != nil {
 		t.Fatalf("failed to list all the vpnSiteLinks in a resource group for a vpn site: %+v", err)
 	}
 	t.Logf("listed list all the vpnSiteLinks in a resource group for a vpn site")
 
-	err = GetVpnSiteLink(ctx, vpnSiteName, vpnSiteLinkName)
+	_, err = GetVpnSiteLink(ctx, vpnSiteName, vpnSiteLinkName)
 	if err != nil {
 		t.Fatalf("failed to retrieve the details of a VPN site link: %+v", err)
 	}
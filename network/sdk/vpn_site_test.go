@@ -11,20 +11,22 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
 )
 
 func TestVpnSite(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	vpnSiteName := config.AppendRandomSuffix("vpnsite")
 	virtualWanName := config.AppendRandomSuffix("virtualwan")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -82,13 +84,13 @@ func TestVpnSite(t *testing.T) {
 	}
 	t.Logf("created vpn site")
 
-	err = ListVpnSite(ctx)
+	_, err = ListVpnSite(ctx)
 	if err != nil {
 		t.Fatalf("failed to list vpn site: %+v", err)
 	}
 	t.Logf("listed vpn site")
 
-	err = ListVpnSiteByResourceGroup(ctx)
+	_, err = ListVpnSiteByResourceGroup(ctx)
 	if err != nil {
 		t.Fatalf("failed to listvpn site by resource group: %+v", err)
 	}
@@ -97,7 +99,7 @@ func TestVpnSite(t *testing.T) {
 	tagsObjectParameters := armnetwork.TagsObject{
 		Tags: map[string]*string{"tag1": to.StringPtr("value1"), "tag2": to.StringPtr("value2")},
 	}
-	err = UpdateVpnSiteTags(ctx, vpnSiteName, tagsObjectParameters)
+	_, err = UpdateVpnSiteTags(ctx, vpnSiteName, tagsObjectParameters)
 	if err != nil {
 		t.Fatalf("failed to update tags for vpn site: %+v", err)
 	}
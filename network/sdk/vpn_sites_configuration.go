@@ -11,30 +11,30 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getVpnSitesConfigurationsClient() armnetwork.VPNSitesConfigurationClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getVpnSitesConfigurationsClient(ctx context.Context) armnetwork.VPNSitesConfigurationClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewVPNSitesConfigurationClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewVPNSitesConfigurationClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Gives the sas-url to download the configurations for vpn-sites in a resource group.
 func DownloadVpnSitesConfiguration(ctx context.Context, virtualWANName string, request armnetwork.GetVPNSitesConfigurationRequest) error {
-	client := getVpnSitesConfigurationsClient()
-	poller, err := client.BeginDownload(ctx, config.GroupName(), virtualWANName, request, nil)
+	client := getVpnSitesConfigurationsClient(ctx)
+	poller, err := client.BeginDownload(ctx, config.ScopeFrom(ctx).GroupName, virtualWANName, request, nil)
 
 	if err != nil {
 		return nil
 	}
 
-	_, err = poller.PollUntilDone(ctx, 300*time.Second)
+	_, err = poller.PollUntilDone(ctx, recording.PollingDelay(300*time.Second))
 	if err != nil {
 		return err
 	}
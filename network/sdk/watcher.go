@@ -11,51 +11,51 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
 )
 
-func getNetworkWatchersClient() armnetwork.NetworkWatchersClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getNetworkWatchersClient(ctx context.Context) armnetwork.NetworkWatchersClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewNetworkWatchersClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewNetworkWatchersClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Create NetworkWatchers
-func CreateNetworkWatcher(ctx context.Context, networkWatcherName string) error {
-	client := getNetworkWatchersClient()
-	_, err := client.CreateOrUpdate(
+func CreateNetworkWatcher(ctx context.Context, networkWatcherName string) (*armnetwork.NetworkWatcher, error) {
+	client := getNetworkWatchersClient(ctx)
+	resp, err := client.CreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		networkWatcherName,
 		armnetwork.NetworkWatcher{
 			Resource: armnetwork.Resource{
-				Location: to.StringPtr(config.Location()),
+				Location: to.StringPtr(config.ScopeFrom(ctx).Location),
 			},
 		},
 		nil,
 	)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return resp.NetworkWatcher, nil
 }
 
 // Deletes the specified network watcher resource.
 func DeleteNetworkWatcher(ctx context.Context, networkWatcherName string) error {
-	client := getNetworkWatchersClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), networkWatcherName, nil)
+	client := getNetworkWatchersClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, networkWatcherName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
@@ -63,36 +63,36 @@ func DeleteNetworkWatcher(ctx context.Context, networkWatcherName string) error
 }
 
 // Gets all network watchers by resource group.
-func ListNetworkWatcher(ctx context.Context) error {
-	client := getNetworkWatchersClient()
-	_, err := client.List(ctx, config.GroupName(), nil)
+func ListNetworkWatcher(ctx context.Context) (*armnetwork.NetworkWatcherListResult, error) {
+	client := getNetworkWatchersClient(ctx)
+	resp, err := client.List(ctx, config.ScopeFrom(ctx).GroupName, nil)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return resp.NetworkWatcherListResult, nil
 }
 
 // Gets all network watchers by subscription.
-func ListAllNetworkWatcher(ctx context.Context) error {
-	client := getNetworkWatchersClient()
-	_, err := client.ListAll(ctx, nil)
+func ListAllNetworkWatcher(ctx context.Context) (*armnetwork.NetworkWatcherListResult, error) {
+	client := getNetworkWatchersClient(ctx)
+	resp, err := client.ListAll(ctx, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return resp.NetworkWatcherListResult, nil
 }
 
-//  Gets the specified network watcher by resource group.
-func GetNetworkWatcher(ctx context.Context, networkWatcherName string) error {
-	client := getNetworkWatchersClient()
-	_, err := client.Get(ctx, config.GroupName(), networkWatcherName, nil)
+// Gets the specified network watcher by resource group.
+func GetNetworkWatcher(ctx context.Context, networkWatcherName string) (*armnetwork.NetworkWatcher, error) {
+	client := getNetworkWatchersClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, networkWatcherName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.NetworkWatcher, nil
 }
 
 // Gets Network Configuration Diagnostic data to help customers understand and debug network behavior. It provides
@@ -100,203 +100,203 @@ func GetNetworkWatcher(ctx context.Context, networkWatcherName string) error {
 // the result of evaluating these rules. Customers must provide details of a flow like source, destination, protocol, etc. The API returns whether traffic
 // was allowed or denied, the rules evaluated for
 // the specified flow and the evaluation results.
-func GetNetworkConfigurationDiagnostic(ctx context.Context, networkWatcherName string, networkConfigurationDiagnosticParameters armnetwork.NetworkConfigurationDiagnosticParameters) error {
-	client := getNetworkWatchersClient()
+func GetNetworkConfigurationDiagnostic(ctx context.Context, networkWatcherName string, networkConfigurationDiagnosticParameters armnetwork.NetworkConfigurationDiagnosticParameters) (*armnetwork.NetworkConfigurationDiagnosticResponse, error) {
+	client := getNetworkWatchersClient(ctx)
 	poller, err := client.BeginGetNetworkConfigurationDiagnostic(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		networkWatcherName,
 		networkConfigurationDiagnosticParameters,
 		nil)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.NetworkConfigurationDiagnosticResponse, nil
 }
 
 // Gets the configured and effective security group rules on the specified VM.
-func GetNetworkVMSecurityRules(ctx context.Context, networkWatcherName string, securityGroupViewParametersParameters armnetwork.SecurityGroupViewParameters) error {
-	client := getNetworkWatchersClient()
+func GetNetworkVMSecurityRules(ctx context.Context, networkWatcherName string, securityGroupViewParametersParameters armnetwork.SecurityGroupViewParameters) (*armnetwork.SecurityGroupViewResult, error) {
+	client := getNetworkWatchersClient(ctx)
 	poller, err := client.BeginGetVMSecurityRules(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		networkWatcherName,
 		securityGroupViewParametersParameters,
 		nil)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.SecurityGroupViewResult, nil
 }
 
 // Verifies the possibility of establishing a direct TCP connection from a virtual machine to a given endpoint including another
 // VM or an arbitrary remote server.
-func CheckNetworkConnectivity(ctx context.Context, networkWatcherName string, connectivityParametersParameters armnetwork.ConnectivityParameters) error {
-	client := getNetworkWatchersClient()
+func CheckNetworkConnectivity(ctx context.Context, networkWatcherName string, connectivityParametersParameters armnetwork.ConnectivityParameters) (*armnetwork.ConnectivityInformation, error) {
+	client := getNetworkWatchersClient(ctx)
 	poller, err := client.BeginCheckConnectivity(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		networkWatcherName,
 		connectivityParametersParameters,
 		nil)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.ConnectivityInformation, nil
 }
 
 // Gets the current network topology by resource group.
-func GetNetworkTopology(ctx context.Context, networkWatcherName string, topologyParametersParameters armnetwork.TopologyParameters) error {
-	client := getNetworkWatchersClient()
-	_, err := client.GetTopology(
+func GetNetworkTopology(ctx context.Context, networkWatcherName string, topologyParametersParameters armnetwork.TopologyParameters) (*armnetwork.Topology, error) {
+	client := getNetworkWatchersClient(ctx)
+	resp, err := client.GetTopology(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		networkWatcherName,
 		topologyParametersParameters,
 		nil)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return resp.Topology, nil
 }
 
-//Verify IP flow from the specified VM to a location given the currently configured NSG rules.
-func VerifyNetworkWatcherIPFlow(ctx context.Context, networkWatcherName string, verificationIPFlowParametersParameters armnetwork.VerificationIPFlowParameters) error {
-	client := getNetworkWatchersClient()
+// Verify IP flow from the specified VM to a location given the currently configured NSG rules.
+func VerifyNetworkWatcherIPFlow(ctx context.Context, networkWatcherName string, verificationIPFlowParametersParameters armnetwork.VerificationIPFlowParameters) (*armnetwork.VerificationIPFlowResult, error) {
+	client := getNetworkWatchersClient(ctx)
 	poller, err := client.BeginVerifyIPFlow(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		networkWatcherName,
 		verificationIPFlowParametersParameters,
 		nil)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.VerificationIPFlowResult, nil
 }
 
-//  Initiate troubleshooting on a specified resource.
-func GetNetworkWatcherTroubleshooting(ctx context.Context, networkWatcherName string, troubleShootingParParameters armnetwork.TroubleshootingParameters) error {
-	client := getNetworkWatchersClient()
+// Initiate troubleshooting on a specified resource.
+func GetNetworkWatcherTroubleshooting(ctx context.Context, networkWatcherName string, troubleShootingParParameters armnetwork.TroubleshootingParameters) (*armnetwork.TroubleshootingResult, error) {
+	client := getNetworkWatchersClient(ctx)
 	poller, err := client.BeginGetTroubleshooting(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		networkWatcherName,
 		troubleShootingParParameters,
 		nil)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.TroubleshootingResult, nil
 }
 
-//   Get the last completed troubleshooting result on a specified resource.
-func GetNetworkWatcherTroubleshootingResult(ctx context.Context, networkWatcherName string, queryTroubleshootingParameters armnetwork.QueryTroubleshootingParameters) error {
-	client := getNetworkWatchersClient()
+// Get the last completed troubleshooting result on a specified resource.
+func GetNetworkWatcherTroubleshootingResult(ctx context.Context, networkWatcherName string, queryTroubleshootingParameters armnetwork.QueryTroubleshootingParameters) (*armnetwork.TroubleshootingResult, error) {
+	client := getNetworkWatchersClient(ctx)
 	poller, err := client.BeginGetTroubleshootingResult(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		networkWatcherName,
 		queryTroubleshootingParameters,
 		nil)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.TroubleshootingResult, nil
 }
 
-//  Queries status of flow log and traffic analytics (optional) on a specified resource.
-func GetNetworkWatcherFlowLogStatus(ctx context.Context, networkWatcherName string, flowLogStatusParametersParameters armnetwork.FlowLogStatusParameters) error {
-	client := getNetworkWatchersClient()
+// Queries status of flow log and traffic analytics (optional) on a specified resource.
+func GetNetworkWatcherFlowLogStatus(ctx context.Context, networkWatcherName string, flowLogStatusParametersParameters armnetwork.FlowLogStatusParameters) (*armnetwork.FlowLogInformation, error) {
+	client := getNetworkWatchersClient(ctx)
 	poller, err := client.BeginGetFlowLogStatus(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		networkWatcherName,
 		flowLogStatusParametersParameters,
 		nil)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.FlowLogInformation, nil
 }
 
-//  Configures flow log and traffic analytics (optional) on a specified resource.
-func SetNetworkWatcherFlowLogConfiguration(ctx context.Context, networkWatcherName string, flowLogInformationParameters armnetwork.FlowLogInformation) error {
-	client := getNetworkWatchersClient()
+// Configures flow log and traffic analytics (optional) on a specified resource.
+func SetNetworkWatcherFlowLogConfiguration(ctx context.Context, networkWatcherName string, flowLogInformationParameters armnetwork.FlowLogInformation) (*armnetwork.FlowLogInformation, error) {
+	client := getNetworkWatchersClient(ctx)
 	poller, err := client.BeginSetFlowLogConfiguration(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		networkWatcherName,
 		flowLogInformationParameters,
 		nil)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.FlowLogInformation, nil
 }
 
 // Updates a network watcher tags.
-func UpdateNetworkWatcherTags(ctx context.Context, networkWatcherName string, tagsObjectParameters armnetwork.TagsObject) error {
-	client := getNetworkWatchersClient()
-	_, err := client.UpdateTags(
+func UpdateNetworkWatcherTags(ctx context.Context, networkWatcherName string, tagsObjectParameters armnetwork.TagsObject) (*armnetwork.NetworkWatcher, error) {
+	client := getNetworkWatchersClient(ctx)
+	resp, err := client.UpdateTags(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		networkWatcherName,
 		tagsObjectParameters,
 		nil,
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.NetworkWatcher, nil
 }
@@ -12,19 +12,19 @@ import (
 
 	compute "github.com/Azure-Samples/azure-sdk-for-go-samples/compute/sdk"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	storage "github.com/Azure-Samples/azure-sdk-for-go-samples/storage/sdk"
 	"github.com/Azure/azure-sdk-for-go/sdk/compute/armcompute"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/armstorage"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
-	"github.com/marstr/randname"
 )
 
 func TestNetworkWatcher(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	networkWatcherName := config.AppendRandomSuffix("networkwatcher")
 	virtualMachineName := config.AppendRandomSuffix("virtualmachine")
 	virtualNetworkNameGateway := config.AppendRandomSuffix("virtualnetwork")
@@ -33,7 +33,7 @@ func TestNetworkWatcher(t *testing.T) {
 	publicIpAddressName := config.AppendRandomSuffix("publicipaddress")
 	subnetGatewayName := "GatewaySubnet"
 	subnetName := config.AppendRandomSuffix("subnet")
-	storageAccountName := randname.Prefixed{Prefix: "storageaccount", Acceptable: randname.LowercaseAlphabet, Len: 5}.Generate()
+	storageAccountName := config.AppendRandomLowercaseSuffix("storageaccount")
 	ipConfigurationName := config.AppendRandomSuffix("ipconfiguration")
 	networkInterfaceName := config.AppendRandomSuffix("interface")
 	networkSecurityGroupName := config.AppendRandomSuffix("networksecuritygroup")
@@ -41,6 +41,7 @@ func TestNetworkWatcher(t *testing.T) {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10000*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -48,7 +49,7 @@ func TestNetworkWatcher(t *testing.T) {
 		t.Fatalf("failed to create group: %+v", err)
 	}
 
-	err = CreateNetworkWatcher(ctx, networkWatcherName)
+	_, err = CreateNetworkWatcher(ctx, networkWatcherName)
 	if err != nil {
 		t.Fatalf("failed to create network watcher: % +v", err)
 	}
@@ -268,7 +269,7 @@ func TestNetworkWatcher(t *testing.T) {
 			TypeHandlerVersion:      to.StringPtr("1.4"),
 		},
 	}
-	err = compute.CreateVirtualMachineExtension(ctx, virtualMachineName, virtualMachineExtensionName, virtualMachineExtensionParameters)
+	_, err = compute.CreateVirtualMachineExtension(ctx, virtualMachineName, virtualMachineExtensionName, virtualMachineExtensionParameters)
 	if err != nil {
 		t.Fatalf("failed to create virtual machine extension: % +v", err)
 	}
@@ -290,7 +291,7 @@ func TestNetworkWatcher(t *testing.T) {
 		},
 		TargetResourceID: &gatewayId,
 	}
-	err = GetNetworkWatcherTroubleshooting(ctx, networkWatcherName, troubleshootingParameters)
+	_, err = GetNetworkWatcherTroubleshooting(ctx, networkWatcherName, troubleshootingParameters)
 	if err != nil {
 		t.Fatalf("failed to get network watcher troubleshooting: % +v", err)
 	}
@@ -299,7 +300,7 @@ func TestNetworkWatcher(t *testing.T) {
 	queryTroubleshootingParameters := armnetwork.QueryTroubleshootingParameters{
 		TargetResourceID: &gatewayId,
 	}
-	err = GetNetworkWatcherTroubleshootingResult(ctx, networkWatcherName, queryTroubleshootingParameters)
+	_, err = GetNetworkWatcherTroubleshootingResult(ctx, networkWatcherName, queryTroubleshootingParameters)
 	if err != nil {
 		t.Fatalf("failed to get network watcher troubleshooting result: % +v", err)
 	}
@@ -314,7 +315,7 @@ func TestNetworkWatcher(t *testing.T) {
 		RemotePort:       to.StringPtr("80"),
 		TargetResourceID: &vmId,
 	}
-	err = VerifyNetworkWatcherIPFlow(ctx, networkWatcherName, verificationIPFlowParameters)
+	_, err = VerifyNetworkWatcherIPFlow(ctx, networkWatcherName, verificationIPFlowParameters)
 	if err != nil {
 		t.Fatalf("failed to verify network watcher ip flow: %+v", err)
 	}
@@ -323,7 +324,7 @@ func TestNetworkWatcher(t *testing.T) {
 	flowLogStatusParameters := armnetwork.FlowLogStatusParameters{
 		TargetResourceID: &securityGroupId,
 	}
-	err = GetNetworkWatcherFlowLogStatus(ctx, networkWatcherName, flowLogStatusParameters)
+	_, err = GetNetworkWatcherFlowLogStatus(ctx, networkWatcherName, flowLogStatusParameters)
 	if err != nil {
 		t.Fatalf("failed to get network watcher flow log status: %+v", err)
 	}
@@ -336,7 +337,7 @@ func TestNetworkWatcher(t *testing.T) {
 		},
 		TargetResourceID: &securityGroupId,
 	}
-	err = SetNetworkWatcherFlowLogConfiguration(ctx, networkWatcherName, flowLogInformationParameters)
+	_, err = SetNetworkWatcherFlowLogConfiguration(ctx, networkWatcherName, flowLogInformationParameters)
 	if err != nil {
 		t.Fatalf("failed to set network watcher Configure flow log: %+v", err)
 	}
@@ -352,7 +353,7 @@ func TestNetworkWatcher(t *testing.T) {
 		}},
 		TargetResourceID: &vmId,
 	}
-	err = GetNetworkConfigurationDiagnostic(ctx, networkWatcherName, networkConfigurationDiagnosticParameters)
+	_, err = GetNetworkConfigurationDiagnostic(ctx, networkWatcherName, networkConfigurationDiagnosticParameters)
 	if err != nil {
 		t.Fatalf("failed to get network configuration diagnostic: %+v", err)
 	}
@@ -361,7 +362,7 @@ func TestNetworkWatcher(t *testing.T) {
 	securityGroupViewParameters := armnetwork.SecurityGroupViewParameters{
 		TargetResourceID: &vmId,
 	}
-	err = GetNetworkVMSecurityRules(ctx, networkWatcherName, securityGroupViewParameters)
+	_, err = GetNetworkVMSecurityRules(ctx, networkWatcherName, securityGroupViewParameters)
 	if err != nil {
 		t.Fatalf("failed to get network security group view: %+v", err)
 	}
@@ -378,34 +379,34 @@ func TestNetworkWatcher(t *testing.T) {
 			ResourceID: &vmId,
 		},
 	}
-	err = CheckNetworkConnectivity(ctx, networkWatcherName, connectivityParameters)
+	_, err = CheckNetworkConnectivity(ctx, networkWatcherName, connectivityParameters)
 	if err != nil {
 		t.Fatalf("failed to check connectivity: %+v", err)
 	}
 	t.Logf("checked connectivity")
 
 	topologyParameters := armnetwork.TopologyParameters{
-		TargetResourceGroupName: to.StringPtr(config.GroupName()),
+		TargetResourceGroupName: to.StringPtr(groupName),
 	}
-	err = GetNetworkTopology(ctx, networkWatcherName, topologyParameters)
+	_, err = GetNetworkTopology(ctx, networkWatcherName, topologyParameters)
 	if err != nil {
 		t.Fatalf("failed to get Topology: %+v", err)
 	}
 	t.Logf("got Topology")
 
-	err = ListNetworkWatcher(ctx)
+	_, err = ListNetworkWatcher(ctx)
 	if err != nil {
 		t.Fatalf("failed to list network watcher: %+v", err)
 	}
 	t.Logf("listed network watcher")
 
-	err = ListAllNetworkWatcher(ctx)
+	_, err = ListAllNetworkWatcher(ctx)
 	if err != nil {
 		t.Fatalf("failed to list all network watcher: %+v", err)
 	}
 	t.Logf("listed all network watcher")
 
-	err = GetNetworkWatcher(ctx, networkWatcherName)
+	_, err = GetNetworkWatcher(ctx, networkWatcherName)
 	if err != nil {
 		t.Fatalf("failed to get network watcher: %+v", err)
 	}
@@ -414,7 +415,7 @@ func TestNetworkWatcher(t *testing.T) {
 	tagsObjectParameters := armnetwork.TagsObject{
 		Tags: map[string]*string{"tag1": to.StringPtr("value1"), "tag2": to.StringPtr("value2")},
 	}
-	err = UpdateNetworkWatcherTags(ctx, networkWatcherName, tagsObjectParameters)
+	_, err = UpdateNetworkWatcherTags(ctx, networkWatcherName, tagsObjectParameters)
 	if err != nil {
 		t.Fatalf("failed to update tags for network watcher: %+v", err)
 	}
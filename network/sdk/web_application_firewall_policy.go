@@ -11,91 +11,89 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 )
 
-func getWpFirewallPoliciesClient() armnetwork.WebApplicationFirewallPoliciesClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getWpFirewallPoliciesClient(ctx context.Context) armnetwork.WebApplicationFirewallPoliciesClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armnetwork.NewWebApplicationFirewallPoliciesClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armnetwork.NewWebApplicationFirewallPoliciesClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Creates or update policy with specified rule set name within a resource group.
 func CreateWebApplicationFirewallPolicy(ctx context.Context, firewallPolicyName string,
-	webApplicationFirewallPolicyParameters armnetwork.WebApplicationFirewallPolicy) error {
-	client := getWpFirewallPoliciesClient()
-	_, err := client.CreateOrUpdate(
+	webApplicationFirewallPolicyParameters armnetwork.WebApplicationFirewallPolicy) (*armnetwork.WebApplicationFirewallPolicy, error) {
+	client := getWpFirewallPoliciesClient(ctx)
+	resp, err := client.CreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		firewallPolicyName,
 		webApplicationFirewallPolicyParameters,
 		nil,
 	)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return resp.WebApplicationFirewallPolicy, nil
 }
 
 // Gets the specified Firewall Policy.
-func GetWebApplicationFirewallPolicy(ctx context.Context, firewallPolicyName string) error {
-	client := getWpFirewallPoliciesClient()
-	_, err := client.Get(ctx, config.GroupName(), firewallPolicyName, nil)
+func GetWebApplicationFirewallPolicy(ctx context.Context, firewallPolicyName string) (*armnetwork.WebApplicationFirewallPolicy, error) {
+	client := getWpFirewallPoliciesClient(ctx)
+	resp, err := client.Get(ctx, config.ScopeFrom(ctx).GroupName, firewallPolicyName, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return resp.WebApplicationFirewallPolicy, nil
 }
 
 // Lists all Firewall Policies in a resource group.
-func ListWebApplicationFirewallPolicy(ctx context.Context) error {
-	client := getWpFirewallPoliciesClient()
-	pager := client.List(config.GroupName(), nil)
+func ListWebApplicationFirewallPolicy(ctx context.Context) ([]*armnetwork.WebApplicationFirewallPolicy, error) {
+	client := getWpFirewallPoliciesClient(ctx)
+	pager := client.List(config.ScopeFrom(ctx).GroupName, nil)
 
+	var results []*armnetwork.WebApplicationFirewallPolicy
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().WebApplicationFirewallPolicyListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Gets all the Firewall Policies in a subscription.
-func ListAllWebApplicationFirewallPolicy(ctx context.Context) error {
-	client := getWpFirewallPoliciesClient()
+func ListAllWebApplicationFirewallPolicy(ctx context.Context) ([]*armnetwork.WebApplicationFirewallPolicy, error) {
+	client := getWpFirewallPoliciesClient(ctx)
 	pager := client.ListAll(nil)
 
+	var results []*armnetwork.WebApplicationFirewallPolicy
 	for pager.NextPage(ctx) {
-		if pager.Err() != nil {
-			return pager.Err()
-		}
+		results = append(results, pager.PageResponse().WebApplicationFirewallPolicyListResult.Value...)
 	}
 
 	if pager.Err() != nil {
-		return pager.Err()
+		return nil, pager.Err()
 	}
-	return nil
+	return results, nil
 }
 
 // Deletes the specified Firewall Policy.
 func DeleteWebApplicationFirewallPolicy(ctx context.Context, firewallPolicyName string) error {
-	client := getWpFirewallPoliciesClient()
-	resp, err := client.BeginDelete(ctx, config.GroupName(), firewallPolicyName, nil)
+	client := getWpFirewallPoliciesClient(ctx)
+	resp, err := client.BeginDelete(ctx, config.ScopeFrom(ctx).GroupName, firewallPolicyName, nil)
 	if err != nil {
 		return err
 	}
-	_, err = resp.PollUntilDone(ctx, 30*time.Second)
+	_, err = resp.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return err
 	}
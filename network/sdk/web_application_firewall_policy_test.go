@@ -11,19 +11,21 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/to"
 )
 
 func TestWebApplicationFirewallPolicy(t *testing.T) {
-	groupName := config.GenerateGroupName("network")
-	config.SetGroupName(groupName)
+	defer recording.Start(t)()
 
+	groupName := config.GenerateGroupName("network")
 	firewallPolicyName := config.AppendRandomSuffix("wafpolicy")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
+	ctx = config.WithScope(ctx, config.NewScope(groupName))
 	defer resources.Cleanup(ctx)
 
 	_, err := resources.CreateGroup(ctx, groupName)
@@ -46,25 +48,25 @@ func TestWebApplicationFirewallPolicy(t *testing.T) {
 			},
 		},
 	}
-	err = CreateWebApplicationFirewallPolicy(ctx, firewallPolicyName, webApplicationFirewallPolicyParameters)
+	_, err = CreateWebApplicationFirewallPolicy(ctx, firewallPolicyName, webApplicationFirewallPolicyParameters)
 	if err != nil {
 		t.Fatalf("failed to create web application firewall policy: %+v", err)
 	}
 	t.Logf("created web application firewall policy")
 
-	err = GetWebApplicationFirewallPolicy(ctx, firewallPolicyName)
+	_, err = GetWebApplicationFirewallPolicy(ctx, firewallPolicyName)
 	if err != nil {
 		t.Fatalf("failed to get web application firewall policy: %+v", err)
 	}
 	t.Logf("got web application firewall policy")
 
-	err = ListWebApplicationFirewallPolicy(ctx)
+	_, err = ListWebApplicationFirewallPolicy(ctx)
 	if err != nil {
 		t.Fatalf("failed to list web application firewall policy: %+v", err)
 	}
 	t.Logf("listed web application firewall policy")
 
-	err = ListAllWebApplicationFirewallPolicy(ctx)
+	_, err = ListAllWebApplicationFirewallPolicy(ctx)
 	if err != nil {
 		t.Fatalf("failed to list all web application firewall policy: %+v", err)
 	}
@@ -11,27 +11,29 @@ import (
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2019-11-01/network"
 	"github.com/Azure/go-autorest/autorest/to"
 )
 
-func getNsgClient() network.SecurityGroupsClient {
-	nsgClient := network.NewSecurityGroupsClient(config.SubscriptionID())
+func getNsgClient(ctx context.Context) network.SecurityGroupsClient {
+	nsgClient := network.NewSecurityGroupsClient(config.ScopeFrom(ctx).SubscriptionID)
 	a, _ := iam.GetResourceManagementAuthorizer()
 	nsgClient.Authorizer = a
 	nsgClient.AddToUserAgent(config.UserAgent())
+	nsgClient.Sender = recording.Sender()
 	return nsgClient
 }
 
 // CreateNetworkSecurityGroup creates a new network security group with rules set for allowing SSH and HTTPS use
 func CreateNetworkSecurityGroup(ctx context.Context, nsgName string) (nsg network.SecurityGroup, err error) {
-	nsgClient := getNsgClient()
+	nsgClient := getNsgClient(ctx)
 	future, err := nsgClient.CreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		nsgName,
 		network.SecurityGroup{
-			Location: to.StringPtr(config.Location()),
+			Location: to.StringPtr(config.ScopeFrom(ctx).Location),
 			SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
 				SecurityRules: &[]network.SecurityRule{
 					{
@@ -79,13 +81,13 @@ func CreateNetworkSecurityGroup(ctx context.Context, nsgName string) (nsg networ
 
 // CreateSimpleNetworkSecurityGroup creates a new network security group, without rules (rules can be set later)
 func CreateSimpleNetworkSecurityGroup(ctx context.Context, nsgName string) (nsg network.SecurityGroup, err error) {
-	nsgClient := getNsgClient()
+	nsgClient := getNsgClient(ctx)
 	future, err := nsgClient.CreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		nsgName,
 		network.SecurityGroup{
-			Location: to.StringPtr(config.Location()),
+			Location: to.StringPtr(config.ScopeFrom(ctx).Location),
 		},
 	)
 
@@ -103,36 +105,37 @@ func CreateSimpleNetworkSecurityGroup(ctx context.Context, nsgName string) (nsg
 
 // DeleteNetworkSecurityGroup deletes an existing network security group
 func DeleteNetworkSecurityGroup(ctx context.Context, nsgName string) (result network.SecurityGroupsDeleteFuture, err error) {
-	nsgClient := getNsgClient()
-	return nsgClient.Delete(ctx, config.GroupName(), nsgName)
+	nsgClient := getNsgClient(ctx)
+	return nsgClient.Delete(ctx, config.ScopeFrom(ctx).GroupName, nsgName)
 }
 
 // GetNetworkSecurityGroup returns an existing network security group
 func GetNetworkSecurityGroup(ctx context.Context, nsgName string) (network.SecurityGroup, error) {
-	nsgClient := getNsgClient()
-	return nsgClient.Get(ctx, config.GroupName(), nsgName, "")
+	nsgClient := getNsgClient(ctx)
+	return nsgClient.Get(ctx, config.ScopeFrom(ctx).GroupName, nsgName, "")
 }
 
 // Network security group rules
 
-func getSecurityRulesClient() network.SecurityRulesClient {
-	rulesClient := network.NewSecurityRulesClient(config.SubscriptionID())
+func getSecurityRulesClient(ctx context.Context) network.SecurityRulesClient {
+	rulesClient := network.NewSecurityRulesClient(config.ScopeFrom(ctx).SubscriptionID)
 	a, _ := iam.GetResourceManagementAuthorizer()
 	rulesClient.Authorizer = a
 	rulesClient.AddToUserAgent(config.UserAgent())
+	rulesClient.Sender = recording.Sender()
 	return rulesClient
 }
 
 // CreateSSHRule creates an inbound network security rule that allows using port 22
 func CreateSSHRule(ctx context.Context, nsgName string) (rule network.SecurityRule, err error) {
-	rulesClient := getSecurityRulesClient()
+	rulesClient := getSecurityRulesClient(ctx)
 	future, err := rulesClient.CreateOrUpdate(ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		nsgName,
 		"ALLOW-SSH",
 		network.SecurityRule{
 			SecurityRulePropertiesFormat: &network.SecurityRulePropertiesFormat{
-				Access: network.SecurityRuleAccessAllow,
+				Access:                   network.SecurityRuleAccessAllow,
 				DestinationAddressPrefix: to.StringPtr("*"),
 				DestinationPortRange:     to.StringPtr("22"),
 				Direction:                network.SecurityRuleDirectionInbound,
@@ -157,14 +160,14 @@ func CreateSSHRule(ctx context.Context, nsgName string) (rule network.SecurityRu
 
 // CreateHTTPRule creates an inbound network security rule that allows using port 80
 func CreateHTTPRule(ctx context.Context, nsgName string) (rule network.SecurityRule, err error) {
-	rulesClient := getSecurityRulesClient()
+	rulesClient := getSecurityRulesClient(ctx)
 	future, err := rulesClient.CreateOrUpdate(ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		nsgName,
 		"ALLOW-HTTP",
 		network.SecurityRule{
 			SecurityRulePropertiesFormat: &network.SecurityRulePropertiesFormat{
-				Access: network.SecurityRuleAccessAllow,
+				Access:                   network.SecurityRuleAccessAllow,
 				DestinationAddressPrefix: to.StringPtr("*"),
 				DestinationPortRange:     to.StringPtr("80"),
 				Direction:                network.SecurityRuleDirectionInbound,
@@ -189,14 +192,14 @@ func CreateHTTPRule(ctx context.Context, nsgName string) (rule network.SecurityR
 
 // CreateSQLRule creates an inbound network security rule that allows using port 1433
 func CreateSQLRule(ctx context.Context, nsgName, frontEndAddressPrefix string) (rule network.SecurityRule, err error) {
-	rulesClient := getSecurityRulesClient()
+	rulesClient := getSecurityRulesClient(ctx)
 	future, err := rulesClient.CreateOrUpdate(ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		nsgName,
 		"ALLOW-SQL",
 		network.SecurityRule{
 			SecurityRulePropertiesFormat: &network.SecurityRulePropertiesFormat{
-				Access: network.SecurityRuleAccessAllow,
+				Access:                   network.SecurityRuleAccessAllow,
 				DestinationAddressPrefix: to.StringPtr("*"),
 				DestinationPortRange:     to.StringPtr("1433"),
 				Direction:                network.SecurityRuleDirectionInbound,
@@ -221,14 +224,14 @@ func CreateSQLRule(ctx context.Context, nsgName, frontEndAddressPrefix string) (
 
 // CreateDenyOutRule creates an network security rule that denies outbound traffic
 func CreateDenyOutRule(ctx context.Context, nsgName string) (rule network.SecurityRule, err error) {
-	rulesClient := getSecurityRulesClient()
+	rulesClient := getSecurityRulesClient(ctx)
 	future, err := rulesClient.CreateOrUpdate(ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		nsgName,
 		"DENY-OUT",
 		network.SecurityRule{
 			SecurityRulePropertiesFormat: &network.SecurityRulePropertiesFormat{
-				Access: network.SecurityRuleAccessDeny,
+				Access:                   network.SecurityRuleAccessDeny,
 				DestinationAddressPrefix: to.StringPtr("*"),
 				DestinationPortRange:     to.StringPtr("*"),
 				Direction:                network.SecurityRuleDirectionOutbound,
@@ -11,25 +11,27 @@ import (
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2019-11-01/network"
 	"github.com/Azure/go-autorest/autorest/to"
 )
 
-func getSubnetsClient() network.SubnetsClient {
-	subnetsClient := network.NewSubnetsClient(config.SubscriptionID())
+func getSubnetsClient(ctx context.Context) network.SubnetsClient {
+	subnetsClient := network.NewSubnetsClient(config.ScopeFrom(ctx).SubscriptionID)
 	auth, _ := iam.GetResourceManagementAuthorizer()
 	subnetsClient.Authorizer = auth
 	subnetsClient.AddToUserAgent(config.UserAgent())
+	subnetsClient.Sender = recording.Sender()
 	return subnetsClient
 }
 
 // CreateVirtualNetworkSubnet creates a subnet in an existing vnet
 func CreateVirtualNetworkSubnet(ctx context.Context, vnetName, subnetName string) (subnet network.Subnet, err error) {
-	subnetsClient := getSubnetsClient()
+	subnetsClient := getSubnetsClient(ctx)
 
 	future, err := subnetsClient.CreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		vnetName,
 		subnetName,
 		network.Subnet{
@@ -56,10 +58,10 @@ func CreateSubnetWithNetworkSecurityGroup(ctx context.Context, vnetName, subnetN
 		return subnet, fmt.Errorf("cannot get nsg: %v", err)
 	}
 
-	subnetsClient := getSubnetsClient()
+	subnetsClient := getSubnetsClient(ctx)
 	future, err := subnetsClient.CreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		vnetName,
 		subnetName,
 		network.Subnet{
@@ -85,6 +87,6 @@ func DeleteVirtualNetworkSubnet() {}
 
 // GetVirtualNetworkSubnet returns an existing subnet from a virtual network
 func GetVirtualNetworkSubnet(ctx context.Context, vnetName string, subnetName string) (network.Subnet, error) {
-	subnetsClient := getSubnetsClient()
-	return subnetsClient.Get(ctx, config.GroupName(), vnetName, subnetName, "")
+	subnetsClient := getSubnetsClient(ctx)
+	return subnetsClient.Get(ctx, config.ScopeFrom(ctx).GroupName, vnetName, subnetName, "")
 }
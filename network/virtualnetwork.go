@@ -11,27 +11,29 @@ import (
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2019-11-01/network"
 	"github.com/Azure/go-autorest/autorest/to"
 )
 
-func getVnetClient() network.VirtualNetworksClient {
-	vnetClient := network.NewVirtualNetworksClient(config.SubscriptionID())
+func getVnetClient(ctx context.Context) network.VirtualNetworksClient {
+	vnetClient := network.NewVirtualNetworksClient(config.ScopeFrom(ctx).SubscriptionID)
 	a, _ := iam.GetResourceManagementAuthorizer()
 	vnetClient.Authorizer = a
 	vnetClient.AddToUserAgent(config.UserAgent())
+	vnetClient.Sender = recording.Sender()
 	return vnetClient
 }
 
 // CreateVirtualNetwork creates a virtual network
 func CreateVirtualNetwork(ctx context.Context, vnetName string) (vnet network.VirtualNetwork, err error) {
-	vnetClient := getVnetClient()
+	vnetClient := getVnetClient(ctx)
 	future, err := vnetClient.CreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		vnetName,
 		network.VirtualNetwork{
-			Location: to.StringPtr(config.Location()),
+			Location: to.StringPtr(config.ScopeFrom(ctx).Location),
 			VirtualNetworkPropertiesFormat: &network.VirtualNetworkPropertiesFormat{
 				AddressSpace: &network.AddressSpace{
 					AddressPrefixes: &[]string{"10.0.0.0/8"},
@@ -53,13 +55,13 @@ func CreateVirtualNetwork(ctx context.Context, vnetName string) (vnet network.Vi
 
 // CreateVirtualNetworkAndSubnets creates a virtual network with two subnets
 func CreateVirtualNetworkAndSubnets(ctx context.Context, vnetName, subnet1Name, subnet2Name string) (vnet network.VirtualNetwork, err error) {
-	vnetClient := getVnetClient()
+	vnetClient := getVnetClient(ctx)
 	future, err := vnetClient.CreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		vnetName,
 		network.VirtualNetwork{
-			Location: to.StringPtr(config.Location()),
+			Location: to.StringPtr(config.ScopeFrom(ctx).Location),
 			VirtualNetworkPropertiesFormat: &network.VirtualNetworkPropertiesFormat{
 				AddressSpace: &network.AddressSpace{
 					AddressPrefixes: &[]string{"10.0.0.0/8"},
@@ -95,6 +97,6 @@ func CreateVirtualNetworkAndSubnets(ctx context.Context, vnetName, subnet1Name,
 
 // DeleteVirtualNetwork deletes a virtual network given an existing virtual network
 func DeleteVirtualNetwork(ctx context.Context, vnetName string) (result network.VirtualNetworksDeleteFuture, err error) {
-	vnetClient := getVnetClient()
-	return vnetClient.Delete(ctx, config.GroupName(), vnetName)
+	vnetClient := getVnetClient(ctx)
+	return vnetClient.Delete(ctx, config.ScopeFrom(ctx).GroupName, vnetName)
 }
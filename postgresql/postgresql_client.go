@@ -6,23 +6,25 @@ import (
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	flexibleservers "github.com/Azure/azure-sdk-for-go/services/preview/postgresql/mgmt/2020-02-14-preview/postgresqlflexibleservers"
 	"github.com/Azure/go-autorest/autorest"
 	"github.com/Azure/go-autorest/autorest/to"
 )
 
 // GetServersClient returns
-func getServersClient() flexibleservers.ServersClient {
-	serversClient := flexibleservers.NewServersClient(config.SubscriptionID())
+func getServersClient(ctx context.Context) flexibleservers.ServersClient {
+	serversClient := flexibleservers.NewServersClient(config.ScopeFrom(ctx).SubscriptionID)
 	a, _ := iam.GetResourceManagementAuthorizer()
 	serversClient.Authorizer = a
 	serversClient.AddToUserAgent(config.UserAgent())
+	serversClient.Sender = recording.Sender()
 	return serversClient
 }
 
 // CreateServer creates a new PostgreSQL Server
 func CreateServer(ctx context.Context, resourceGroup, serverName, dbLogin, dbPassword string) (server flexibleservers.Server, err error) {
-	serversClient := getServersClient()
+	serversClient := getServersClient(ctx)
 
 	// Create the server
 	future, err := serversClient.Create(
@@ -30,7 +32,7 @@ func CreateServer(ctx context.Context, resourceGroup, serverName, dbLogin, dbPas
 		resourceGroup,
 		serverName,
 		flexibleservers.Server{
-			Location: to.StringPtr(config.Location()),
+			Location: to.StringPtr(config.ScopeFrom(ctx).Location),
 			Sku: &flexibleservers.Sku{
 				Name: to.StringPtr("Standard_D4s_v3"),
 				Tier: "GeneralPurpose",
@@ -58,7 +60,7 @@ func CreateServer(ctx context.Context, resourceGroup, serverName, dbLogin, dbPas
 
 // UpdateServerStorageCapacity given the server name and the new storage capacity it updates the server's storage capacity.
 func UpdateServerStorageCapacity(ctx context.Context, resourceGroup, serverName string, storageCapacity int32) (server flexibleservers.Server, err error) {
-	serversClient := getServersClient()
+	serversClient := getServersClient(ctx)
 
 	future, err := serversClient.Update(
 		ctx,
@@ -85,7 +87,7 @@ func UpdateServerStorageCapacity(ctx context.Context, resourceGroup, serverName
 
 // DeleteServer deletes the PostgreSQL server.
 func DeleteServer(ctx context.Context, resourceGroup, serverName string) (resp autorest.Response, err error) {
-	serversClient := getServersClient()
+	serversClient := getServersClient(ctx)
 
 	future, err := serversClient.Delete(ctx, resourceGroup, serverName)
 	if err != nil {
@@ -100,17 +102,18 @@ func DeleteServer(ctx context.Context, resourceGroup, serverName string) (resp a
 }
 
 // GetFwRulesClient returns the FirewallClient
-func getFwRulesClient() flexibleservers.FirewallRulesClient {
-	fwrClient := flexibleservers.NewFirewallRulesClient(config.SubscriptionID())
+func getFwRulesClient(ctx context.Context) flexibleservers.FirewallRulesClient {
+	fwrClient := flexibleservers.NewFirewallRulesClient(config.ScopeFrom(ctx).SubscriptionID)
 	a, _ := iam.GetResourceManagementAuthorizer()
 	fwrClient.Authorizer = a
 	fwrClient.AddToUserAgent(config.UserAgent())
+	fwrClient.Sender = recording.Sender()
 	return fwrClient
 }
 
 // CreateOrUpdateFirewallRule given the firewallname and new properties it updates the firewall rule.
 func CreateOrUpdateFirewallRule(ctx context.Context, resourceGroup, serverName, firewallRuleName, startIPAddr, endIPAddr string) (rule flexibleservers.FirewallRule, err error) {
-	fwrClient := getFwRulesClient()
+	fwrClient := getFwRulesClient(ctx)
 
 	future, err := fwrClient.CreateOrUpdate(
 		ctx,
@@ -135,23 +138,24 @@ func CreateOrUpdateFirewallRule(ctx context.Context, resourceGroup, serverName,
 }
 
 // GetConfigurationsClient creates and returns the configuration client for the server.
-func getConfigurationsClient() flexibleservers.ConfigurationsClient {
-	configClient := flexibleservers.NewConfigurationsClient(config.SubscriptionID())
+func getConfigurationsClient(ctx context.Context) flexibleservers.ConfigurationsClient {
+	configClient := flexibleservers.NewConfigurationsClient(config.ScopeFrom(ctx).SubscriptionID)
 	a, _ := iam.GetResourceManagementAuthorizer()
 	configClient.Authorizer = a
 	configClient.AddToUserAgent(config.UserAgent())
+	configClient.Sender = recording.Sender()
 	return configClient
 }
 
 // GetConfiguration given the server name and configuration name it returns the configuration.
 func GetConfiguration(ctx context.Context, resourceGroup, serverName, configurationName string) (flexibleservers.Configuration, error) {
-	configClient := getConfigurationsClient()
+	configClient := getConfigurationsClient(ctx)
 	return configClient.Get(ctx, resourceGroup, serverName, configurationName)
 }
 
 // UpdateConfiguration given the name of the configuation and the configuration object it updates the configuration for the given server.
 func UpdateConfiguration(ctx context.Context, resourceGroup, serverName string, configurationName string, configuration flexibleservers.Configuration) (updatedConfig flexibleservers.Configuration, err error) {
-	configClient := getConfigurationsClient()
+	configClient := getConfigurationsClient(ctx)
 
 	future, err := configClient.Update(ctx, resourceGroup, serverName, configurationName, configuration)
 	if err != nil {
@@ -1,157 +1,437 @@
+// Command deploy-vm deploys a Linux VM from an ARM template into a resource
+// group of its own, and removes it again:
+//
+//	go run . up -location westus2 -ssh-key ~/.ssh/id_rsa.pub
+//	go run . status
+//	go run . ssh-info
+//	go run . down
+//
+// `up` records what it created in a state file, `deploy-vm.json` by default,
+// which the other commands read. Every command prints its result to stdout
+// as JSON; progress and errors go to stderr.
 package main
 
 import (
 	"context"
+	"crypto/rand"
 	"encoding/json"
+	"flag"
+	"fmt"
 	"io/ioutil"
 	"log"
+	"math/big"
+	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
-	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2017-09-01/network"
-	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2017-05-10/resources"
-	"github.com/Azure/go-autorest/autorest"
-	"github.com/Azure/go-autorest/autorest/azure"
-	"github.com/Azure/go-autorest/autorest/azure/auth"
-	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/compute"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/parameters"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/network"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
 )
 
 const (
-	resourceGroupName     = "GoVMQuickstart"
-	resourceGroupLocation = "eastus"
-
 	deploymentName = "VMDeployQuickstart"
-	templateFile   = "vm-quickstart-template.json"
-	parametersFile = "vm-quickstart-params.json"
+	defaultImage   = "Canonical:UbuntuServer:18.04-LTS:latest"
 )
 
-// Information loaded from the authorization file to identify the client
-type clientInfo struct {
-	SubscriptionID string
-	VMPassword     string
+// commands are the subcommands by name.
+var commands = map[string]func(args []string) error{
+	"up":       up,
+	"status":   status,
+	"ssh-info": sshInfo,
+	"down":     down,
 }
 
-var (
-	ctx        = context.Background()
-	clientData clientInfo
-	authorizer autorest.Authorizer
-)
-
-// Authenticate with the Azure services using file-based authentication
-func init() {
-	var err error
-	authorizer, err = auth.NewAuthorizerFromFile(azure.PublicCloud.ResourceManagerEndpoint)
-	if err != nil {
-		log.Fatalf("Failed to get OAuth config: %v", err)
+func main() {
+	if len(os.Args) < 2 || commands[os.Args[1]] == nil {
+		fmt.Fprintf(os.Stderr, "usage: %s up|status|ssh-info|down [flags]\n", filepath.Base(os.Args[0]))
+		os.Exit(2)
 	}
+	if err := config.ParseEnvironment(); err != nil {
+		log.Fatalf("failed to parse environment: %v\n", err)
+	}
+	if err := commands[os.Args[1]](os.Args[2:]); err != nil {
+		log.Fatalf("%s: %v\n", os.Args[1], err)
+	}
+}
 
-	authInfo, err := readJSON(os.Getenv("AZURE_AUTH_LOCATION"))
-	if err != nil {
-		log.Fatalf("Failed to read JSON: %+v", err)
+// commonFlags are the flags of every command.
+type commonFlags struct {
+	stateFile string
+	timeout   time.Duration
+}
+
+// newFlagSet returns the flags of a command, including the configuration
+// flags such as -location and -subscription.
+func newFlagSet(name string, timeout time.Duration) (*flag.FlagSet, *commonFlags) {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	common := &commonFlags{}
+	fs.StringVar(&common.stateFile, "state", "deploy-vm.json", "File recording what `up` created.")
+	fs.DurationVar(&common.timeout, "timeout", timeout, "How long to wait for the command.")
+	if err := config.AddFlagsTo(fs); err != nil {
+		log.Fatalf("failed to add flags: %v\n", err)
 	}
-	clientData.SubscriptionID = (*authInfo)["subscriptionId"].(string)
-	clientData.VMPassword = (*authInfo)["clientSecret"].(string)
+	return fs, common
 }
 
-func main() {
-	group, err := createGroup()
+// scope returns a context for the resources recorded in s.
+func (s *state) scope(ctx context.Context) context.Context {
+	return config.WithScope(ctx, config.Scope{
+		SubscriptionID: s.SubscriptionID,
+		GroupName:      s.ResourceGroup,
+		Location:       s.Location,
+	})
+}
+
+func writeJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// up creates a resource group and deploys the VM template into it.
+func up(args []string) error {
+	fs, common := newFlagSet("up", time.Hour)
+	var size, image, sshKeyFile, prefix, templateFile, parametersFile string
+	fs.StringVar(&size, "size", "Standard_B1s", "Size of the VM.")
+	fs.StringVar(&image, "image", defaultImage, "Image of the VM, as publisher:offer:sku:version.")
+	fs.StringVar(&sshKeyFile, "ssh-key", "", "SSH public key file to log in with. A random password is generated if it's not set.")
+	fs.StringVar(&prefix, "prefix", "quickstart", "Prefix of the names of the resource group and resources.")
+	fs.StringVar(&templateFile, "template", "vm-quickstart-template.json", "Template to deploy.")
+	fs.StringVar(&parametersFile, "parameters", "vm-quickstart-params.json", "Parameters of the template.")
+	fs.Parse(args)
+
+	if _, err := os.Stat(common.stateFile); err == nil {
+		return fmt.Errorf("state file %s exists, run `down` first or pass another -state", common.stateFile)
+	}
+	imageParts := strings.Split(image, ":")
+	if len(imageParts) != 4 {
+		return fmt.Errorf("expected an image like %s, got %q", defaultImage, image)
+	}
+
+	s := &state{
+		SubscriptionID: config.SubscriptionID(),
+		ResourceGroup:  config.AppendRandomSuffix(prefix + "-"),
+		Location:       config.Location(),
+		RunID:          config.RunID(),
+		DeploymentName: deploymentName,
+		VMName:         prefix + "-vm",
+		PublicIPName:   prefix + "-ip",
+		Size:           size,
+		Image:          image,
+		CreatedOn:      time.Now().UTC(),
+	}
+	secret := ""
+	if sshKeyFile != "" {
+		key, err := readPublicKey(sshKeyFile)
+		if err != nil {
+			return err
+		}
+		secret = key
+		if s.SSHKeyFile, err = filepath.Abs(sshKeyFile); err != nil {
+			return err
+		}
+	} else {
+		password, err := generatePassword()
+		if err != nil {
+			return err
+		}
+		secret, s.Password = password, password
+	}
+
+	template, err := parameters.ReadTemplate(templateFile)
+	if err != nil {
+		return err
+	}
+	params, err := parameters.ReadFile(parametersFile)
+	if err != nil {
+		return err
+	}
+	err = params.Override(template,
+		"virtualMachines_QuickstartVM_name="+s.VMName,
+		"publicIPAddresses_QuickstartVM_ip_name="+s.PublicIPName,
+		"networkInterfaces_quickstartvm_name="+prefix+"-nic",
+		"networkSecurityGroups_QuickstartVM_nsg_name="+prefix+"-nsg",
+		"virtualNetworks_GoQSVM_vnet_name="+prefix+"-vnet",
+		"vm_size="+size,
+		"image_publisher="+imageParts[0],
+		"image_offer="+imageParts[1],
+		"image_sku="+imageParts[2],
+		"image_version="+imageParts[3],
+	)
 	if err != nil {
-		log.Fatalf("failed to create group: %v", err)
+		return err
+	}
+	// the secret isn't parsed from an assignment, as keys contain `=`
+	params["vm_password_or_key"] = parameters.Parameter{Value: secret}
+	params["authentication_type"] = parameters.Parameter{Value: "sshPublicKey"}
+	if s.Password != "" {
+		params["authentication_type"] = parameters.Parameter{Value: "password"}
+	}
+	if err := params.Validate(template); err != nil {
+		return err
+	}
+	if user, ok := params["vm_user"].Value.(string); ok {
+		s.Username = user
 	}
-	log.Printf("Created group: %v", *group.Name)
 
-	log.Printf("Starting deployment: %s", deploymentName)
-	result, err := createDeployment()
+	ctx, cancel := context.WithTimeout(context.Background(), common.timeout)
+	defer cancel()
+	ctx = s.scope(ctx)
+
+	requirements := resources.RequirementsFromTemplate(template.Content, params.Map())
+	if err := resources.Preflight(ctx, requirements, &resources.PreflightOptions{Register: true}); err != nil {
+		return err
+	}
+	if _, err := resources.CreateGroup(ctx, s.ResourceGroup); err != nil {
+		return fmt.Errorf("cannot create group %s: %v", s.ResourceGroup, err)
+	}
+	if err := s.write(common.stateFile); err != nil {
+		return fmt.Errorf("cannot write state file %s: %v; delete group %s by hand", common.stateFile, err, s.ResourceGroup)
+	}
+
+	log.Printf("starting deployment %s in group %s\n", deploymentName, s.ResourceGroup)
+	_, err = resources.CreateDeployment(ctx, deploymentName, template.Content, params.Map(), &resources.DeploymentOptions{
+		Progress: func(p resources.OperationProgress) {
+			log.Printf("%s %s: %s\n", p.ResourceType, p.ResourceName, p.ProvisioningState)
+		},
+	})
 	if err != nil {
-		log.Fatalf("Failed to deploy: %v", err)
+		return fmt.Errorf("%v\nrun `down` to delete what was created", err)
 	}
-	if result.Name != nil {
-		log.Printf("Completed deployment %v: %v", deploymentName, *result.Properties.ProvisioningState)
-	} else {
-		log.Printf("Completed deployment %v (no data returned to SDK)", deploymentName)
+
+	info, err := newSSHInfo(ctx, s)
+	if err != nil {
+		return err
 	}
-	getLogin()
+	return writeJSON(struct {
+		ResourceGroup string      `json:"resourceGroup"`
+		Location      string      `json:"location"`
+		VMName        string      `json:"vmName"`
+		SSH           *sshDetails `json:"ssh"`
+	}{s.ResourceGroup, s.Location, s.VMName, info})
 }
 
-// Create a resource group for the deployment.
-func createGroup() (group resources.Group, err error) {
-	groupsClient := resources.NewGroupsClient(clientData.SubscriptionID)
-	groupsClient.Authorizer = authorizer
-
-	return groupsClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		resources.Group{
-			Location: to.StringPtr(resourceGroupLocation)})
+// statusResult is the output of `status`. States are empty for resources
+// which don't exist (yet).
+type statusResult struct {
+	ResourceGroup       string `json:"resourceGroup"`
+	GroupState          string `json:"groupState"`
+	DeploymentState     string `json:"deploymentState,omitempty"`
+	VMProvisioningState string `json:"vmProvisioningState,omitempty"`
+	PowerState          string `json:"powerState,omitempty"`
+	PublicIPAddress     string `json:"publicIpAddress,omitempty"`
 }
 
-// Create the deployment
-func createDeployment() (deployment resources.DeploymentExtended, err error) {
-	template, err := readJSON(templateFile)
+// status reports the state of the group, deployment and VM created by `up`.
+func status(args []string) error {
+	fs, common := newFlagSet("status", 2*time.Minute)
+	fs.Parse(args)
+	s, err := readState(common.stateFile)
 	if err != nil {
-		return
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), common.timeout)
+	defer cancel()
+	ctx = s.scope(ctx)
+
+	result := statusResult{ResourceGroup: s.ResourceGroup, GroupState: "NotFound"}
+	group, err := resources.GetGroup(ctx)
+	if group.StatusCode == http.StatusNotFound {
+		return writeJSON(result)
 	}
-	params, err := readJSON(parametersFile)
 	if err != nil {
-		return
+		return fmt.Errorf("cannot get group %s: %v", s.ResourceGroup, err)
+	}
+	if group.Properties != nil && group.Properties.ProvisioningState != nil {
+		result.GroupState = *group.Properties.ProvisioningState
 	}
-	(*params)["vm_password"] = map[string]string{
-		"value": clientData.VMPassword,
+
+	deployment, err := resources.GetDeployment(ctx, s.DeploymentName)
+	if err != nil && deployment.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("cannot get deployment %s: %v", s.DeploymentName, err)
+	}
+	if err == nil && deployment.Properties != nil {
+		result.DeploymentState = string(deployment.Properties.ProvisioningState)
 	}
 
-	deploymentsClient := resources.NewDeploymentsClient(clientData.SubscriptionID)
-	deploymentsClient.Authorizer = authorizer
+	vm, err := compute.GetVM(ctx, s.VMName)
+	if err != nil && vm.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("cannot get VM %s: %v", s.VMName, err)
+	}
+	if err == nil && vm.VirtualMachineProperties != nil {
+		if vm.ProvisioningState != nil {
+			result.VMProvisioningState = *vm.ProvisioningState
+		}
+		if vm.InstanceView != nil && vm.InstanceView.Statuses != nil {
+			for _, status := range *vm.InstanceView.Statuses {
+				if status.Code != nil && strings.HasPrefix(*status.Code, "PowerState/") {
+					result.PowerState = strings.TrimPrefix(*status.Code, "PowerState/")
+				}
+			}
+		}
+	}
 
-	deploymentFuture, err := deploymentsClient.CreateOrUpdate(
-		ctx,
-		resourceGroupName,
-		deploymentName,
-		resources.Deployment{
-			Properties: &resources.DeploymentProperties{
-				Template:   template,
-				Parameters: params,
-				Mode:       resources.Incremental,
-			},
-		},
-	)
+	ip, err := network.GetPublicIP(ctx, s.PublicIPName)
+	if err != nil && ip.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("cannot get public IP %s: %v", s.PublicIPName, err)
+	}
+	if err == nil && ip.PublicIPAddressPropertiesFormat != nil && ip.IPAddress != nil {
+		result.PublicIPAddress = *ip.IPAddress
+	}
+	return writeJSON(result)
+}
+
+// sshDetails tells how to log in to the VM.
+type sshDetails struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	Username string `json:"username"`
+	// Password is set for VMs created without a key, IdentityFile for
+	// those created with one whose private key is next to the public key.
+	Password     string `json:"password,omitempty"`
+	IdentityFile string `json:"identityFile,omitempty"`
+	Command      string `json:"command"`
+}
+
+// sshInfo prints how to log in to the VM created by `up`.
+func sshInfo(args []string) error {
+	fs, common := newFlagSet("ssh-info", 2*time.Minute)
+	fs.Parse(args)
+	s, err := readState(common.stateFile)
 	if err != nil {
-		return
+		return err
 	}
-	err = deploymentFuture.FutureAPI.WaitForCompletionRef(ctx, deploymentsClient.BaseClient.Client)
+	ctx, cancel := context.WithTimeout(context.Background(), common.timeout)
+	defer cancel()
+
+	info, err := newSSHInfo(s.scope(ctx), s)
 	if err != nil {
-		return
+		return err
 	}
-	return deploymentFuture.Result(deploymentsClient)
+	return writeJSON(info)
 }
 
-// Get login information by querying the deployed public IP resource.
-func getLogin() {
-	params, err := readJSON(parametersFile)
+func newSSHInfo(ctx context.Context, s *state) (*sshDetails, error) {
+	ip, err := network.GetPublicIP(ctx, s.PublicIPName)
 	if err != nil {
-		log.Fatalf("Unable to read parameters. Get login information with `az network public-ip list -g %s", resourceGroupName)
+		return nil, fmt.Errorf("cannot get public IP %s: %v", s.PublicIPName, err)
+	}
+	if ip.PublicIPAddressPropertiesFormat == nil || ip.IPAddress == nil {
+		return nil, fmt.Errorf("public IP %s has no address yet, is the VM running?", s.PublicIPName)
 	}
+	info := &sshDetails{
+		Host:     *ip.IPAddress,
+		Port:     22,
+		Username: s.Username,
+		Password: s.Password,
+		Command:  fmt.Sprintf("ssh %s@%s", s.Username, *ip.IPAddress),
+	}
+	if s.SSHKeyFile != "" {
+		privateKey := strings.TrimSuffix(s.SSHKeyFile, ".pub")
+		if _, err := os.Stat(privateKey); err == nil && privateKey != s.SSHKeyFile {
+			info.IdentityFile = privateKey
+			info.Command = fmt.Sprintf("ssh -i %s %s@%s", privateKey, s.Username, *ip.IPAddress)
+		}
+	}
+	return info, nil
+}
 
-	addressClient := network.NewPublicIPAddressesClient(clientData.SubscriptionID)
-	addressClient.Authorizer = authorizer
-	ipName := (*params)["publicIPAddresses_QuickstartVM_ip_name"].(map[string]interface{})
-	ipAddress, err := addressClient.Get(ctx, resourceGroupName, ipName["value"].(string), "")
+// down deletes the resource group created by `up`, then the state file.
+// It refuses to delete a group which another run created.
+func down(args []string) error {
+	fs, common := newFlagSet("down", time.Hour)
+	var force bool
+	fs.BoolVar(&force, "force", false, "Delete the group even if its run ID tag doesn't match the state file.")
+	fs.Parse(args)
+	s, err := readState(common.stateFile)
 	if err != nil {
-		log.Fatalf("Unable to get IP information. Try using `az network public-ip list -g %s", resourceGroupName)
+		return err
 	}
+	ctx, cancel := context.WithTimeout(context.Background(), common.timeout)
+	defer cancel()
+	ctx = s.scope(ctx)
 
-	vmUser := (*params)["vm_user"].(map[string]interface{})
+	deleted := false
+	group, err := resources.GetGroup(ctx)
+	switch {
+	case group.StatusCode == http.StatusNotFound:
+		log.Printf("group %s is already gone\n", s.ResourceGroup)
+	case err != nil:
+		return fmt.Errorf("cannot get group %s: %v", s.ResourceGroup, err)
+	default:
+		runID := ""
+		if tag := group.Tags[resources.TagRunID]; tag != nil {
+			runID = *tag
+		}
+		if runID != s.RunID && !force {
+			return fmt.Errorf("group %s has run ID %q rather than %q, so it wasn't created by this `up`; pass -force to delete it anyway",
+				s.ResourceGroup, runID, s.RunID)
+		}
+		log.Printf("deleting group %s\n", s.ResourceGroup)
+		if err := resources.DeleteGroups(ctx, []string{s.ResourceGroup}, 1).Err(); err != nil {
+			return err
+		}
+		deleted = true
+	}
 
-	log.Printf("Log in with ssh: %s@%s, password: %s",
-		vmUser["value"].(string),
-		*ipAddress.PublicIPAddressPropertiesFormat.IPAddress,
-		clientData.VMPassword)
+	if err := os.Remove(common.stateFile); err != nil {
+		return err
+	}
+	return writeJSON(struct {
+		ResourceGroup string `json:"resourceGroup"`
+		Deleted       bool   `json:"deleted"`
+	}{s.ResourceGroup, deleted})
 }
 
-func readJSON(path string) (*map[string]interface{}, error) {
+// readPublicKey reads an OpenSSH public key file, such as `~/.ssh/id_rsa.pub`.
+func readPublicKey(path string) (string, error) {
 	data, err := ioutil.ReadFile(path)
 	if err != nil {
-		log.Fatalf("failed to read file: %v", err)
+		return "", err
+	}
+	key := strings.TrimSpace(string(data))
+	if strings.HasPrefix(key, "-----BEGIN") {
+		return "", fmt.Errorf("%s is a private key, pass the public key, e.g. %s.pub", path, path)
+	}
+	if !strings.HasPrefix(key, "ssh-") && !strings.HasPrefix(key, "ecdsa-") {
+		return "", fmt.Errorf("%s isn't an OpenSSH public key", path)
+	}
+	return key, nil
+}
+
+// generatePassword returns a random password meeting Azure's complexity
+// rules for VMs: 12 to 72 characters from at least three of lowercase,
+// uppercase, digits and symbols. It uses all four.
+func generatePassword() (string, error) {
+	classes := []string{
+		"abcdefghijkmnopqrstuvwxyz",
+		"ABCDEFGHJKLMNPQRSTUVWXYZ",
+		"23456789",
+		"!@#%^*-_=+",
+	}
+	const length = 24
+	password := make([]byte, length)
+	for i := range password {
+		class := classes[i%len(classes)]
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(class))))
+		if err != nil {
+			return "", err
+		}
+		password[i] = class[n.Int64()]
+	}
+	// shuffle, so the classes don't follow a pattern
+	for i := len(password) - 1; i > 0; i-- {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return "", err
+		}
+		j := n.Int64()
+		password[i], password[j] = password[j], password[i]
 	}
-	contents := make(map[string]interface{})
-	_ = json.Unmarshal(data, &contents)
-	return &contents, nil
+	return string(password), nil
 }
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// state records what `up` created, so the other commands act on exactly
+// those resources. It's written as soon as the group exists, so `down` can
+// also clean up after a failed deployment.
+type state struct {
+	SubscriptionID string    `json:"subscriptionId"`
+	ResourceGroup  string    `json:"resourceGroup"`
+	Location       string    `json:"location"`
+	RunID          string    `json:"runId"`
+	DeploymentName string    `json:"deploymentName"`
+	VMName         string    `json:"vmName"`
+	PublicIPName   string    `json:"publicIpName"`
+	Username       string    `json:"username"`
+	Size           string    `json:"size"`
+	Image          string    `json:"image"`
+	CreatedOn      time.Time `json:"createdOn"`
+	// Password is set for VMs which log in with a generated password,
+	// SSHKeyFile for those which log in with a key.
+	Password   string `json:"password,omitempty"`
+	SSHKeyFile string `json:"sshKeyFile,omitempty"`
+}
+
+// readState reads the state file written by `up`.
+func readState(path string) (*state, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no state file %s, run `up` first", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var s state
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("cannot parse state file %s: %v", path, err)
+	}
+	return &s, nil
+}
+
+// write saves the state through a temporary file, readable only by the
+// user as it may hold the VM's password.
+func (s *state) write(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(filepath.Dir(path), ".deploy-vm-*")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(data, '\n'))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
@@ -0,0 +1,292 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
+	"github.com/Azure/go-autorest/autorest/azure"
+)
+
+// apiVersionCacheTTL is how long cached API versions are used before the
+// provider is asked again, so new versions are picked up eventually.
+const apiVersionCacheTTL = 7 * 24 * time.Hour
+
+// cachedProvider holds the API versions of a provider's resource types, by
+// lowercase type name, newest first.
+type cachedProvider struct {
+	Fetched time.Time           `json:"fetched"`
+	Types   map[string][]string `json:"types"`
+}
+
+// apiVersionCache caches the API versions of resource types by Resource
+// Manager endpoint, as clouds support different versions, then by lowercase
+// provider namespace. It's persisted to a file so later runs needn't ask
+// the providers again.
+type apiVersionCache struct {
+	mu        sync.Mutex
+	path      string
+	providers map[string]map[string]cachedProvider
+	// fetch gets the resource types of a namespace from its provider.
+	fetch func(ctx context.Context, namespace string) (map[string][]string, error)
+}
+
+var (
+	apiVersions     *apiVersionCache
+	apiVersionsOnce sync.Once
+)
+
+// defaultAPIVersionCache returns the cache at `apiVersionCachePath()`,
+// loading it on first use.
+func defaultAPIVersionCache() *apiVersionCache {
+	apiVersionsOnce.Do(func() {
+		apiVersions = newAPIVersionCache(apiVersionCachePath())
+	})
+	return apiVersions
+}
+
+// apiVersionCachePath returns the file to persist the cache to, or "" to
+// only keep it in memory. Recorded tests always ask the providers, so their
+// requests are the same on every run.
+func apiVersionCachePath() string {
+	if recording.GetMode() != recording.Live {
+		return ""
+	}
+	switch path := config.APIVersionCache(); path {
+	case "off":
+		return ""
+	case "":
+		dir, err := os.UserCacheDir()
+		if err != nil {
+			return ""
+		}
+		return filepath.Join(dir, "azure-sdk-for-go-samples", "api-versions.json")
+	default:
+		return path
+	}
+}
+
+// newAPIVersionCache returns a cache persisted to path, or kept in memory
+// if path is "". A missing or corrupt file is ignored, the versions are
+// fetched again.
+func newAPIVersionCache(path string) *apiVersionCache {
+	c := &apiVersionCache{path: path, providers: map[string]map[string]cachedProvider{}, fetch: fetchAPIVersions}
+	if path == "" {
+		return c
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return c
+	}
+	if err := json.Unmarshal(data, &c.providers); err != nil {
+		log.Printf("ignoring corrupt API version cache %s: %v\n", path, err)
+		c.providers = map[string]map[string]cachedProvider{}
+	}
+	return c
+}
+
+// save writes the cache file through a temporary file, so concurrent runs
+// don't read a partial file. Failures only cost a refetch next time.
+func (c *apiVersionCache) save() {
+	if c.path == "" {
+		return
+	}
+	data, err := json.MarshalIndent(c.providers, "", "  ")
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(c.path), 0700)
+	}
+	if err == nil {
+		var f *os.File
+		if f, err = ioutil.TempFile(filepath.Dir(c.path), ".api-versions-*"); err == nil {
+			_, err = f.Write(data)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err == nil {
+				err = os.Rename(f.Name(), c.path)
+			}
+			if err != nil {
+				os.Remove(f.Name())
+			}
+		}
+	}
+	if err != nil {
+		log.Printf("cannot save API version cache %s: %v\n", c.path, err)
+	}
+}
+
+// provider returns the cached resource types of a namespace, fetching them
+// if they're missing or stale.
+func (c *apiVersionCache) provider(ctx context.Context, endpoint, namespace string) (map[string][]string, error) {
+	c.mu.Lock()
+	cached, ok := c.providers[endpoint][namespace]
+	c.mu.Unlock()
+	if ok && time.Since(cached.Fetched) < apiVersionCacheTTL {
+		return cached.Types, nil
+	}
+
+	// fetch without holding the lock, so lookups of other types aren't held
+	// up by the request
+	types, err := c.fetch(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// another lookup may have fetched the provider meanwhile
+	if cached, ok := c.providers[endpoint][namespace]; ok && time.Since(cached.Fetched) < apiVersionCacheTTL {
+		return cached.Types, nil
+	}
+	if c.providers[endpoint] == nil {
+		c.providers[endpoint] = map[string]cachedProvider{}
+	}
+	c.providers[endpoint][namespace] = cachedProvider{Fetched: time.Now().UTC(), Types: types}
+	c.save()
+	return types, nil
+}
+
+// fetchAPIVersions gets the API versions of each resource type of a
+// namespace from its provider, by lowercase type name, newest first.
+func fetchAPIVersions(ctx context.Context, namespace string) (map[string][]string, error) {
+	provider, err := getProviderClient(ctx).Get(ctx, namespace, "")
+	if err != nil {
+		return nil, fmt.Errorf("cannot get provider %s: %v", namespace, err)
+	}
+	types := map[string][]string{}
+	if provider.ResourceTypes != nil {
+		for _, t := range *provider.ResourceTypes {
+			if t.ResourceType == nil || t.APIVersions == nil {
+				continue
+			}
+			versions := append([]string(nil), *t.APIVersions...)
+			// API versions are dates, optionally with a suffix like -preview
+			sort.Sort(sort.Reverse(sort.StringSlice(versions)))
+			types[strings.ToLower(*t.ResourceType)] = versions
+		}
+	}
+	return types, nil
+}
+
+// ListAPIVersions returns the API versions the provider supports for a
+// resource type such as `Microsoft.Network/publicIPAddresses` or
+// `Microsoft.Compute/virtualMachines/extensions`, newest first. Versions
+// are cached, see `config.APIVersionCache()`.
+func ListAPIVersions(ctx context.Context, resourceType string) ([]string, error) {
+	i := strings.Index(resourceType, "/")
+	if i < 1 || i == len(resourceType)-1 {
+		return nil, fmt.Errorf("expected a resource type like Microsoft.Network/publicIPAddresses, got %q", resourceType)
+	}
+	namespace, typeName := strings.ToLower(resourceType[:i]), strings.ToLower(resourceType[i+1:])
+	env, err := config.Environment()
+	if err != nil {
+		return nil, err
+	}
+	types, err := defaultAPIVersionCache().provider(ctx, env.ResourceManagerEndpoint, namespace)
+	if err != nil {
+		return nil, err
+	}
+	versions, ok := types[typeName]
+	if !ok {
+		return nil, fmt.Errorf("provider %s has no resource type %s", namespace, typeName)
+	}
+	return versions, nil
+}
+
+// LatestAPIVersion returns the API version to use for a resource type: its
+// newest stable version or, if it has none and
+// `config.AllowPreviewAPIVersions()`, its newest preview version.
+func LatestAPIVersion(ctx context.Context, resourceType string) (string, error) {
+	candidates, err := apiVersionCandidates(ctx, resourceType)
+	if err != nil {
+		return "", err
+	}
+	return candidates[0], nil
+}
+
+// apiVersionCandidates returns the API versions to try for a resource type
+// in turn: the stable versions newest first then, if
+// `config.AllowPreviewAPIVersions()`, the previews newest first.
+func apiVersionCandidates(ctx context.Context, resourceType string) ([]string, error) {
+	versions, err := ListAPIVersions(ctx, resourceType)
+	if err != nil {
+		return nil, err
+	}
+	candidates := orderAPIVersions(versions, config.AllowPreviewAPIVersions())
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("%s has no stable API version, only %s; set AZURE_SAMPLES_ALLOW_PREVIEW_API_VERSIONS to use a preview",
+			resourceType, strings.Join(versions, ", "))
+	}
+	return candidates, nil
+}
+
+// orderAPIVersions returns the stable versions, newest first, followed by
+// the previews if they're allowed.
+func orderAPIVersions(versions []string, allowPreviews bool) []string {
+	var stable, previews []string
+	for _, v := range versions {
+		if isStable(v) {
+			stable = append(stable, v)
+		} else {
+			previews = append(previews, v)
+		}
+	}
+	if allowPreviews {
+		return append(stable, previews...)
+	}
+	return stable
+}
+
+// withAPIVersions calls f with each API version candidate of a resource type
+// until the service accepts the version. A version can be listed by the
+// provider but not yet be available in every region.
+func withAPIVersions(ctx context.Context, resourceType string, f func(apiVersion string) error) error {
+	candidates, err := apiVersionCandidates(ctx, resourceType)
+	if err != nil {
+		return err
+	}
+	for _, apiVersion := range candidates {
+		err = f(apiVersion)
+		if !isAPIVersionError(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// isAPIVersionError reports whether the service rejected a request's API
+// version.
+func isAPIVersionError(err error) bool {
+	var requestErr *azure.RequestError
+	if !errors.As(err, &requestErr) || requestErr.ServiceError == nil {
+		return false
+	}
+	switch requestErr.ServiceError.Code {
+	case "NoRegisteredProviderFound", "InvalidApiVersionParameter":
+		return true
+	default:
+		return false
+	}
+}
+
+// isStable reports whether a version is a plain date like 2020-06-01.
+func isStable(version string) bool {
+	_, err := time.Parse("2006-01-02", version)
+	return err == nil
+}
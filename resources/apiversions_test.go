@@ -0,0 +1,96 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package resources
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOrderAPIVersions(t *testing.T) {
+	versions := []string{"2021-03-01-preview", "2020-11-01", "2020-08-01-beta", "2020-06-01"}
+	if got, want := strings.Join(orderAPIVersions(versions, false), ","), "2020-11-01,2020-06-01"; got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+	if got, want := strings.Join(orderAPIVersions(versions, true), ","), "2020-11-01,2020-06-01,2021-03-01-preview,2020-08-01-beta"; got != want {
+		t.Errorf("expected %s with previews, got %s", want, got)
+	}
+	if got := orderAPIVersions(versions[:1], false); len(got) != 0 {
+		t.Errorf("expected no stable versions, got %v", got)
+	}
+}
+
+func TestAPIVersionCache(t *testing.T) {
+	const endpoint = "https://management.azure.com/"
+	dir, err := ioutil.TempDir("", "resources")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "cache", "api-versions.json")
+	saved := newAPIVersionCache(path)
+	saved.providers[endpoint] = map[string]cachedProvider{"microsoft.network": {
+		Fetched: time.Now().UTC(),
+		Types:   map[string][]string{"publicipaddresses": {"2020-11-01", "2020-08-01"}},
+	}}
+	saved.save()
+
+	// a fresh entry is served without asking the provider
+	types, err := newAPIVersionCache(path).provider(context.Background(), endpoint, "microsoft.network")
+	if err != nil {
+		t.Fatalf("expected the cached provider, got %v", err)
+	}
+	if got := strings.Join(types["publicipaddresses"], ","); got != "2020-11-01,2020-08-01" {
+		t.Errorf("expected the cached versions, got %s", got)
+	}
+}
+
+func TestAPIVersionCacheDoesNotBlockOtherProviders(t *testing.T) {
+	const endpoint = "https://management.azure.com/"
+	c := newAPIVersionCache("")
+	c.providers[endpoint] = map[string]cachedProvider{"microsoft.network": {
+		Fetched: time.Now().UTC(),
+		Types:   map[string][]string{"publicipaddresses": {"2020-11-01"}},
+	}}
+	fetching, release := make(chan struct{}), make(chan struct{})
+	c.fetch = func(ctx context.Context, namespace string) (map[string][]string, error) {
+		close(fetching)
+		<-release
+		return map[string][]string{"virtualmachines": {"2021-03-01"}}, nil
+	}
+
+	slowDone := make(chan error)
+	go func() {
+		_, err := c.provider(context.Background(), endpoint, "microsoft.compute")
+		slowDone <- err
+	}()
+	// the compute provider is being fetched, which mustn't hold up cache hits
+	<-fetching
+	if _, err := c.provider(context.Background(), endpoint, "microsoft.network"); err != nil {
+		t.Errorf("expected the cached provider, got %v", err)
+	}
+	close(release)
+	if err := <-slowDone; err != nil {
+		t.Fatalf("failed to fetch the provider: %v", err)
+	}
+	if got := c.providers[endpoint]["microsoft.compute"].Types["virtualmachines"]; len(got) != 1 {
+		t.Errorf("expected the fetched provider to be cached, got %v", got)
+	}
+}
+
+func TestResourceTypeFilter(t *testing.T) {
+	if got, want := resourceTypeFilter("Microsoft.Network/publicIPAddresses"), "resourceType eq 'Microsoft.Network/publicIPAddresses'"; got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+	if got, want := resourceTypeFilter("Microsoft.Web/sites' or name eq 'x"), "resourceType eq 'Microsoft.Web/sites'' or name eq ''x'"; got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
@@ -7,9 +7,31 @@ package resources
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"os"
+	"os/user"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2020-06-01/resources"
+	"github.com/Azure/go-autorest/autorest/to"
+)
+
+// Tags set on the resource groups samples create, so `tools/cleanup` can
+// find groups which were left behind.
+const (
+	// TagCreatedBy is the user and host which created the group.
+	TagCreatedBy = "samples-created-by"
+	// TagRunID is the `config.RunID()` of the run which created the group.
+	TagRunID = "samples-run-id"
+	// TagCreatedOn is when the group was created, in RFC 3339 format.
+	TagCreatedOn = "samples-created-on"
+	// TagExpiresOn is when the group may be deleted, in RFC 3339 format.
+	TagExpiresOn = "samples-expires-on"
 )
 
 // Cleanup deletes the resource group created for the sample
@@ -19,5 +41,191 @@ func Cleanup(ctx context.Context) {
 		return
 	}
 	log.Println("deleting resources")
-	_, _ = DeleteGroup(ctx, config.GroupName())
+	_, _ = DeleteGroup(ctx, config.ScopeFrom(ctx).GroupName)
+}
+
+// groupTags returns the tags for a group created at now.
+func groupTags(now time.Time) map[string]*string {
+	now = now.UTC()
+	return map[string]*string{
+		TagCreatedBy: to.StringPtr(creator()),
+		TagRunID:     to.StringPtr(config.RunID()),
+		TagCreatedOn: to.StringPtr(now.Format(time.RFC3339)),
+		TagExpiresOn: to.StringPtr(now.Add(config.ResourceTTL()).Format(time.RFC3339)),
+	}
+}
+
+// creator returns user@host for the current user.
+func creator() string {
+	name := os.Getenv("USER")
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	host, _ := os.Hostname()
+	return fmt.Sprintf("%s@%s", name, host)
+}
+
+// GroupFilter selects resource groups to clean up. A group must match every
+// field which is set.
+type GroupFilter struct {
+	// Prefix the group's name must start with, ignoring case.
+	Prefix string
+	// Location the group must be in.
+	Location string
+	// Tags the group must have. An empty value matches any value.
+	Tags map[string]string
+	// OlderThan selects groups created longer ago, according to their
+	// TagCreatedOn tag. Groups without the tag don't match.
+	OlderThan time.Duration
+	// Expired selects groups whose TagExpiresOn tag is in the past.
+	Expired bool
+}
+
+// IsEmpty reports whether the filter would select every group.
+func (f GroupFilter) IsEmpty() bool {
+	return f.Prefix == "" && f.Location == "" && len(f.Tags) == 0 && f.OlderThan == 0 && !f.Expired
+}
+
+// Matches reports whether the filter selects group at time now.
+func (f GroupFilter) Matches(group resources.Group, now time.Time) bool {
+	if f.Prefix != "" && !strings.HasPrefix(strings.ToLower(to.String(group.Name)), strings.ToLower(f.Prefix)) {
+		return false
+	}
+	if f.Location != "" && !strings.EqualFold(normalizeLocation(to.String(group.Location)), normalizeLocation(f.Location)) {
+		return false
+	}
+	for key, want := range f.Tags {
+		got, ok := tagValue(group.Tags, key)
+		if !ok || (want != "" && got != want) {
+			return false
+		}
+	}
+	if f.OlderThan > 0 {
+		created, ok := tagTime(group.Tags, TagCreatedOn)
+		if !ok || now.Sub(created) < f.OlderThan {
+			return false
+		}
+	}
+	if f.Expired {
+		expires, ok := tagTime(group.Tags, TagExpiresOn)
+		if !ok || now.Before(expires) {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeLocation turns display names like "West US 2" into names like
+// "westus2".
+func normalizeLocation(location string) string {
+	return strings.ReplaceAll(strings.ToLower(location), " ", "")
+}
+
+// tagValue looks up a tag, ignoring the case of its name like ARM does.
+func tagValue(tags map[string]*string, key string) (string, bool) {
+	for k, v := range tags {
+		if strings.EqualFold(k, key) {
+			return to.String(v), true
+		}
+	}
+	return "", false
+}
+
+func tagTime(tags map[string]*string, key string) (time.Time, bool) {
+	v, ok := tagValue(tags, key)
+	if !ok {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	return t, err == nil
+}
+
+// FindGroups lists the resource groups in the subscription selected by
+// filter, skipping those already being deleted.
+func FindGroups(ctx context.Context, filter GroupFilter) ([]resources.Group, error) {
+	now := time.Now()
+	var groups []resources.Group
+	list, err := ListGroups(ctx)
+	for ; err == nil && list.NotDone(); err = list.NextWithContext(ctx) {
+		group := list.Value()
+		if group.Properties != nil && strings.EqualFold(to.String(group.Properties.ProvisioningState), "Deleting") {
+			continue
+		}
+		if filter.Matches(group, now) {
+			groups = append(groups, group)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot list resource groups: %v", err)
+	}
+	return groups, nil
+}
+
+// DeleteReport is the outcome of DeleteGroups.
+type DeleteReport struct {
+	// Deleted are the names of the groups which were deleted, sorted.
+	Deleted []string
+	// Failed holds the error for each group which couldn't be deleted.
+	Failed map[string]error
+}
+
+// Err returns an error listing every group which couldn't be deleted, or
+// nil.
+func (r *DeleteReport) Err() error {
+	if len(r.Failed) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(r.Failed))
+	for name := range r.Failed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	fmt.Fprintf(&b, "failed to delete %d of %d resource groups:", len(r.Failed), len(r.Failed)+len(r.Deleted))
+	for _, name := range names {
+		fmt.Fprintf(&b, "\n  %s: %v", name, r.Failed[name])
+	}
+	return fmt.Errorf("%s", b.String())
+}
+
+// DeleteGroups deletes the named resource groups, at most concurrency at a
+// time, and waits for the deletions to finish. Failures don't stop the other
+// deletions, they're collected in the report.
+func DeleteGroups(ctx context.Context, groupNames []string, concurrency int) *DeleteReport {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	report := &DeleteReport{Failed: map[string]error{}}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, concurrency)
+	for _, name := range groupNames {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			err := deleteGroupAndWait(ctx, name)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				report.Failed[name] = err
+			} else {
+				report.Deleted = append(report.Deleted, name)
+			}
+		}(name)
+	}
+	wg.Wait()
+	sort.Strings(report.Deleted)
+	return report
+}
+
+func deleteGroupAndWait(ctx context.Context, groupName string) error {
+	groupsClient := getGroupsClient(ctx)
+	future, err := groupsClient.Delete(ctx, groupName, "")
+	if err != nil {
+		return err
+	}
+	return future.WaitForCompletionRef(ctx, groupsClient.Client)
 }
@@ -0,0 +1,75 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package resources
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2020-06-01/resources"
+	"github.com/Azure/go-autorest/autorest/to"
+)
+
+func TestGroupFilter(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	group := resources.Group{
+		Name:     to.StringPtr("az-samples-go-Groups-abcde"),
+		Location: to.StringPtr("westus2"),
+		Tags: map[string]*string{
+			TagRunID:     to.StringPtr("run-abcde"),
+			TagCreatedOn: to.StringPtr("2021-06-01T00:00:00Z"),
+			TagExpiresOn: to.StringPtr("2021-06-02T00:00:00Z"),
+		},
+	}
+	untagged := resources.Group{Name: to.StringPtr("az-samples-go-manual"), Location: to.StringPtr("eastus")}
+
+	tests := []struct {
+		name          string
+		filter        GroupFilter
+		matchTagged   bool
+		matchUntagged bool
+	}{
+		{"prefix ignores case", GroupFilter{Prefix: "AZ-SAMPLES-GO"}, true, true},
+		{"other prefix", GroupFilter{Prefix: "other"}, false, false},
+		{"location display name", GroupFilter{Location: "West US 2"}, true, false},
+		{"tag value", GroupFilter{Tags: map[string]string{TagRunID: "run-abcde"}}, true, false},
+		{"other tag value", GroupFilter{Tags: map[string]string{TagRunID: "run-fghij"}}, false, false},
+		{"any tag value", GroupFilter{Tags: map[string]string{"Samples-Run-ID": ""}}, true, false},
+		{"older", GroupFilter{OlderThan: 6 * time.Hour}, true, false},
+		{"not old enough", GroupFilter{OlderThan: 24 * time.Hour}, false, false},
+		{"not expired", GroupFilter{Expired: true}, false, false},
+		{"every field", GroupFilter{Prefix: "az-samples-go", Location: "westus2", OlderThan: time.Hour}, true, false},
+	}
+	for _, test := range tests {
+		if got := test.filter.Matches(group, now); got != test.matchTagged {
+			t.Errorf("%s: expected %v for the tagged group, got %v", test.name, test.matchTagged, got)
+		}
+		if got := test.filter.Matches(untagged, now); got != test.matchUntagged {
+			t.Errorf("%s: expected %v for the untagged group, got %v", test.name, test.matchUntagged, got)
+		}
+	}
+
+	if !(GroupFilter{Expired: true}).Matches(group, now.Add(24*time.Hour)) {
+		t.Errorf("expected the group to have expired a day later")
+	}
+	if !(GroupFilter{}).IsEmpty() || (GroupFilter{Expired: true}).IsEmpty() {
+		t.Errorf("expected only the zero filter to be empty")
+	}
+}
+
+func TestDeleteReport(t *testing.T) {
+	report := &DeleteReport{Deleted: []string{"a"}, Failed: map[string]error{}}
+	if err := report.Err(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	report.Failed["c"] = errors.New("conflict")
+	report.Failed["b"] = errors.New("forbidden")
+	want := "failed to delete 2 of 3 resource groups:\n  b: forbidden\n  c: conflict"
+	if err := report.Err(); err == nil || err.Error() != want {
+		t.Errorf("expected %q, got %v", want, err)
+	}
+}
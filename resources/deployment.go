@@ -7,34 +7,75 @@ package resources
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
-	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2019-05-01/resources"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2020-06-01/resources"
 )
 
-func getDeploymentsClient() resources.DeploymentsClient {
-	deployClient := resources.NewDeploymentsClient(config.SubscriptionID())
+func getDeploymentsClient(ctx context.Context) resources.DeploymentsClient {
+	deployClient := resources.NewDeploymentsClient(config.ScopeFrom(ctx).SubscriptionID)
 	a, _ := iam.GetResourceManagementAuthorizer()
 	deployClient.Authorizer = a
 	deployClient.AddToUserAgent(config.UserAgent())
+	deployClient.Sender = recording.Sender()
 	return deployClient
 }
 
+// ErrConfirmationRequired is returned for Complete mode deployments without
+// a confirmation, and for deployments the confirmation turned down.
+var ErrConfirmationRequired = errors.New("complete mode deployments must be confirmed")
+
+// DeploymentOptions configures CreateDeployment. The zero value deploys in
+// Incremental mode.
+type DeploymentOptions struct {
+	// Mode is Incremental, which leaves resources missing from the template
+	// alone, or Complete, which deletes them.
+	Mode resources.DeploymentMode
+	// Confirm is required in Complete mode. It's passed the changes the
+	// deployment would make, including the resources it would delete, and
+	// the deployment goes ahead only if it returns true.
+	Confirm func(ChangeSet) bool
+	// Progress, if set, is called while the deployment runs each time the
+	// provisioning state of one of its resources changes.
+	Progress func(OperationProgress)
+}
+
 // CreateDeployment creates a template deployment using the
-// referenced JSON files for the template and its parameters
-func CreateDeployment(ctx context.Context, deploymentName string, template, params *map[string]interface{}) (de resources.DeploymentExtended, err error) {
-	deployClient := getDeploymentsClient()
+// referenced JSON files for the template and its parameters.
+// options may be nil for an Incremental mode deployment. If the deployment
+// fails the error is a *DeploymentError naming the resources which failed.
+func CreateDeployment(ctx context.Context, deploymentName string, template, params *map[string]interface{}, options *DeploymentOptions) (de resources.DeploymentExtended, err error) {
+	mode := resources.DeploymentModeIncremental
+	if options != nil && options.Mode != "" {
+		mode = options.Mode
+	}
+	if mode == resources.DeploymentModeComplete {
+		if options.Confirm == nil {
+			return de, ErrConfirmationRequired
+		}
+		changes, err := PreviewDeployment(ctx, deploymentName, template, params, mode)
+		if err != nil {
+			return de, err
+		}
+		if !options.Confirm(changes) {
+			return de, ErrConfirmationRequired
+		}
+	}
+
+	deployClient := getDeploymentsClient(ctx)
 	future, err := deployClient.CreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		deploymentName,
 		resources.Deployment{
 			Properties: &resources.DeploymentProperties{
 				Template:   template,
 				Parameters: params,
-				Mode:       resources.Incremental,
+				Mode:       mode,
 			},
 		},
 	)
@@ -42,26 +83,90 @@ func CreateDeployment(ctx context.Context, deploymentName string, template, para
 		return de, fmt.Errorf("cannot create deployment: %v", err)
 	}
 
-	err = future.WaitForCompletionRef(ctx, deployClient.Client)
+	if options != nil && options.Progress != nil {
+		err = waitForDeployment(ctx, &future, deployClient, deploymentName, options.Progress)
+	} else {
+		err = future.WaitForCompletionRef(ctx, deployClient.Client)
+	}
+	if err == nil {
+		de, err = future.Result(deployClient)
+	}
+	if err != nil && ctx.Err() == nil {
+		// explain which resources broke rather than just that it failed
+		err = GetDeploymentError(ctx, deploymentName, err)
+	}
 	if err != nil {
-		return de, fmt.Errorf("cannot get the create deployment future respone: %v", err)
+		return de, fmt.Errorf("cannot get the create deployment future response: %w", err)
 	}
+	return de, nil
+}
 
-	return future.Result(deployClient)
+// PreviewDeployment runs the what-if operation to predict which resources a
+// deployment in the given mode would create, modify or delete, without
+// changing anything. Print the result for a human-readable diff.
+func PreviewDeployment(ctx context.Context, deploymentName string, template, params *map[string]interface{}, mode resources.DeploymentMode) (ChangeSet, error) {
+	deployClient := getDeploymentsClient(ctx)
+	future, err := deployClient.WhatIf(
+		ctx,
+		config.ScopeFrom(ctx).GroupName,
+		deploymentName,
+		resources.DeploymentWhatIf{
+			Properties: &resources.DeploymentWhatIfProperties{
+				Template:   template,
+				Parameters: params,
+				Mode:       mode,
+				WhatIfSettings: &resources.DeploymentWhatIfSettings{
+					ResultFormat: resources.WhatIfResultFormatFullResourcePayloads,
+				},
+			},
+		},
+	)
+	if err != nil {
+		return ChangeSet{}, fmt.Errorf("cannot preview deployment: %v", err)
+	}
+
+	err = future.WaitForCompletionRef(ctx, deployClient.Client)
+	if err != nil {
+		return ChangeSet{}, fmt.Errorf("cannot get the preview deployment future response: %v", err)
+	}
+	result, err := future.Result(deployClient)
+	if err != nil {
+		return ChangeSet{}, fmt.Errorf("cannot get the preview deployment result: %v", err)
+	}
+	if result.Error != nil && result.Error.Message != nil {
+		return ChangeSet{}, fmt.Errorf("cannot preview deployment: %s", *result.Error.Message)
+	}
+	return newChangeSet(result), nil
 }
 
 // ValidateDeployment validates the template deployments and their
 // parameters are correct and will produce a successful deployment.GetResource
 func ValidateDeployment(ctx context.Context, deploymentName string, template, params *map[string]interface{}) (valid resources.DeploymentValidateResult, err error) {
-	deployClient := getDeploymentsClient()
-	return deployClient.Validate(ctx,
-		config.GroupName(),
+	deployClient := getDeploymentsClient(ctx)
+	future, err := deployClient.Validate(ctx,
+		config.ScopeFrom(ctx).GroupName,
 		deploymentName,
 		resources.Deployment{
 			Properties: &resources.DeploymentProperties{
 				Template:   template,
 				Parameters: params,
-				Mode:       resources.Incremental,
+				Mode:       resources.DeploymentModeIncremental,
 			},
 		})
+	if err != nil {
+		return valid, fmt.Errorf("cannot validate deployment: %v", err)
+	}
+
+	err = future.WaitForCompletionRef(ctx, deployClient.Client)
+	if err != nil {
+		return valid, fmt.Errorf("cannot get the validate deployment future response: %v", err)
+	}
+	return future.Result(deployClient)
+}
+
+// GetDeployment gets a deployment in the resource group of ctx, including
+// its provisioning state and outputs.
+func GetDeployment(ctx context.Context, deploymentName string) (resources.DeploymentExtended, error) {
+	deployClient := getDeploymentsClient(ctx)
+	return deployClient.Get(ctx, config.ScopeFrom(ctx).GroupName, deploymentName)
 }
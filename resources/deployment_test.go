@@ -14,6 +14,7 @@ import (
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/util"
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2020-06-01/resources"
 )
 
 func Example_createTemplateDeployment() {
@@ -42,13 +43,29 @@ func Example_createTemplateDeployment() {
 		return
 	}
 
+	err = Preflight(ctx, RequirementsFromTemplate(template, params), &PreflightOptions{Register: true})
+	if err != nil {
+		util.LogAndPanic(err)
+	}
+
 	_, err = ValidateDeployment(ctx, deployName, template, params)
 	if err != nil {
 		util.LogAndPanic(err)
 	}
 	util.PrintAndLog("validated VM template deployment")
 
-	_, err = CreateDeployment(ctx, deployName, template, params)
+	changes, err := PreviewDeployment(ctx, deployName, template, params, resources.DeploymentModeIncremental)
+	if err != nil {
+		util.LogAndPanic(err)
+	}
+	log.Print(changes)
+	util.PrintAndLog("previewed VM template deployment")
+
+	_, err = CreateDeployment(ctx, deployName, template, params, &DeploymentOptions{
+		Progress: func(p OperationProgress) {
+			log.Printf("%s %s: %s after %v", p.ResourceType, p.ResourceName, p.ProvisioningState, p.Duration)
+		},
+	})
 	if err != nil {
 		util.LogAndPanic(err)
 	}
@@ -61,8 +78,7 @@ func Example_createTemplateDeployment() {
 	r, err := GetResource(ctx,
 		"Microsoft.Network",
 		"publicIPAddresses",
-		ipName,
-		"2018-01-01")
+		ipName)
 	if err != nil {
 		util.LogAndPanic(err)
 	}
@@ -75,6 +91,7 @@ func Example_createTemplateDeployment() {
 
 	// Output:
 	// validated VM template deployment
+	// previewed VM template deployment
 	// created VM template deployment
 	// got public IP info via get generic resource
 }
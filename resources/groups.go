@@ -9,30 +9,32 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"strings"
 	"sync"
+	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
-	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2019-05-01/resources"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2020-06-01/resources"
 	"github.com/Azure/go-autorest/autorest/azure"
 	"github.com/Azure/go-autorest/autorest/azure/auth"
 	"github.com/Azure/go-autorest/autorest/to"
 )
 
-func getGroupsClient() resources.GroupsClient {
-	groupsClient := resources.NewGroupsClient(config.SubscriptionID())
+func getGroupsClient(ctx context.Context) resources.GroupsClient {
+	groupsClient := resources.NewGroupsClient(config.ScopeFrom(ctx).SubscriptionID)
 	a, err := iam.GetResourceManagementAuthorizer()
 	if err != nil {
 		log.Fatalf("failed to initialize authorizer: %v\n", err)
 	}
 	groupsClient.Authorizer = a
 	groupsClient.AddToUserAgent(config.UserAgent())
+	groupsClient.Sender = recording.Sender()
 	return groupsClient
 }
 
-func getGroupsClientWithAuthFile() resources.GroupsClient {
-	groupsClient := resources.NewGroupsClient(config.SubscriptionID())
+func getGroupsClientWithAuthFile(ctx context.Context) resources.GroupsClient {
+	groupsClient := resources.NewGroupsClient(config.ScopeFrom(ctx).SubscriptionID)
 	// requires env var AZURE_AUTH_LOCATION set to output of
 	// `az ad sp create-for-rbac --sdk-auth`
 	a, err := auth.NewAuthorizerFromFile(azure.PublicCloud.ResourceManagerEndpoint)
@@ -41,82 +43,89 @@ func getGroupsClientWithAuthFile() resources.GroupsClient {
 	}
 	groupsClient.Authorizer = a
 	groupsClient.AddToUserAgent(config.UserAgent())
+	groupsClient.Sender = recording.Sender()
 	return groupsClient
 }
 
-// CreateGroup creates a new resource group named by env var
+// CreateGroup creates a new resource group named by env var. It is tagged
+// with its creator, run ID and expiry for `tools/cleanup`.
 func CreateGroup(ctx context.Context, groupName string) (resources.Group, error) {
-	groupsClient := getGroupsClient()
-	log.Println(fmt.Sprintf("creating resource group '%s' on location: %v", groupName, config.Location()))
+	groupsClient := getGroupsClient(ctx)
+	log.Println(fmt.Sprintf("creating resource group '%s' on location: %v", groupName, config.ScopeFrom(ctx).Location))
 	return groupsClient.CreateOrUpdate(
 		ctx,
 		groupName,
 		resources.Group{
-			Location: to.StringPtr(config.Location()),
+			Location: to.StringPtr(config.ScopeFrom(ctx).Location),
+			Tags:     groupTags(time.Now()),
 		})
 }
 
 // CreateGroupWithAuthFile creates a new resource group. The client authorizer
 // is set up based on an auth file created using the Azure CLI.
 func CreateGroupWithAuthFile(ctx context.Context, groupName string) (resources.Group, error) {
-	groupsClient := getGroupsClientWithAuthFile()
-	log.Println(fmt.Sprintf("creating resource group '%s' on location: %v", groupName, config.Location()))
+	groupsClient := getGroupsClientWithAuthFile(ctx)
+	log.Println(fmt.Sprintf("creating resource group '%s' on location: %v", groupName, config.ScopeFrom(ctx).Location))
 	return groupsClient.CreateOrUpdate(
 		ctx,
 		groupName,
 		resources.Group{
-			Location: to.StringPtr(config.Location()),
+			Location: to.StringPtr(config.ScopeFrom(ctx).Location),
+			Tags:     groupTags(time.Now()),
 		})
 }
 
 // DeleteGroup removes the resource group named by env var
 func DeleteGroup(ctx context.Context, groupName string) (result resources.GroupsDeleteFuture, err error) {
-	groupsClient := getGroupsClient()
-	return groupsClient.Delete(ctx, groupName)
+	groupsClient := getGroupsClient(ctx)
+	return groupsClient.Delete(ctx, groupName, "")
 }
 
 // ListGroups gets an interator that gets all resource groups in the subscription
 func ListGroups(ctx context.Context) (resources.GroupListResultIterator, error) {
-	groupsClient := getGroupsClient()
+	groupsClient := getGroupsClient(ctx)
 	return groupsClient.ListComplete(ctx, "", nil)
 }
 
 // GetGroup gets info on the resource group in use
 func GetGroup(ctx context.Context) (resources.Group, error) {
-	groupsClient := getGroupsClient()
-	return groupsClient.Get(ctx, config.GroupName())
+	groupsClient := getGroupsClient(ctx)
+	return groupsClient.Get(ctx, config.ScopeFrom(ctx).GroupName)
 }
 
 // DeleteAllGroupsWithPrefix deletes all rescource groups that start with a certain prefix
+//
+// Deprecated: use DeleteGroups.
 func DeleteAllGroupsWithPrefix(ctx context.Context, prefix string) (futures []resources.GroupsDeleteFuture, groups []string) {
 	if config.KeepResources() {
 		log.Println("keeping resource groups")
 		return
 	}
-	for list, err := ListGroups(ctx); list.NotDone(); err = list.NextWithContext(ctx) {
+	found, err := FindGroups(ctx, GroupFilter{Prefix: prefix})
+	if err != nil {
+		log.Fatalf("got error: %s", err)
+	}
+	for _, group := range found {
+		rgName := to.String(group.Name)
+		fmt.Printf("deleting group '%s'\n", rgName)
+		future, err := DeleteGroup(ctx, rgName)
 		if err != nil {
 			log.Fatalf("got error: %s", err)
 		}
-		rgName := *list.Value().Name
-		if strings.HasPrefix(rgName, prefix) {
-			fmt.Printf("deleting group '%s'\n", rgName)
-			future, err := DeleteGroup(ctx, rgName)
-			if err != nil {
-				log.Fatalf("got error: %s", err)
-			}
-			futures = append(futures, future)
-			groups = append(groups, rgName)
-		}
+		futures = append(futures, future)
+		groups = append(groups, rgName)
 	}
 	return
 }
 
 // WaitForDeleteCompletion concurrently waits for delete group operations to finish
+//
+// Deprecated: use DeleteGroups.
 func WaitForDeleteCompletion(ctx context.Context, wg *sync.WaitGroup, futures []resources.GroupsDeleteFuture, groups []string) {
 	for i, f := range futures {
 		wg.Add(1)
 		go func(ctx context.Context, future resources.GroupsDeleteFuture, rg string) {
-			err := future.WaitForCompletionRef(ctx, getGroupsClient().Client)
+			err := future.WaitForCompletionRef(ctx, getGroupsClient(ctx).Client)
 			if err != nil {
 				log.Fatalf("got error: %s", err)
 			} else {
@@ -7,49 +7,57 @@ package resources
 
 import (
 	"context"
-	"log"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
-	"github.com/Azure/azure-sdk-for-go/profiles/2017-03-09/resources/mgmt/resources"
-	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/hybrid"
+	"github.com/Azure/azure-sdk-for-go/profiles/2019-03-01/resources/mgmt/resources"
 	"github.com/Azure/go-autorest/autorest/to"
 )
 
-func getGroupsClient(activeDirectoryEndpoint, tokenAudience string) resources.GroupsClient {
-	token, err := iam.GetResourceManagementTokenHybrid(
-		activeDirectoryEndpoint, tokenAudience)
-	if err != nil {
-		log.Fatalf("failed to get token: %v\n", err)
-	}
-
-	groupsClient := resources.NewGroupsClientWithBaseURI(
-		config.Environment().ResourceManagerEndpoint,
-		config.SubscriptionID())
-	groupsClient.Authorizer = autorest.NewBearerAuthorizer(token)
-	groupsClient.AddToUserAgent(config.UserAgent())
+func getGroupsClient(ctx context.Context) resources.GroupsClient {
+	groupsClient := resources.NewGroupsClientWithBaseURI(hybrid.Endpoint(ctx))
+	hybrid.Configure(&groupsClient.Client)
 	return groupsClient
 }
 
 // CreateGroup creates a new resource group named by env var
 func CreateGroup(ctx context.Context) (resources.Group, error) {
-	groupClient := getGroupsClient(
-		config.Environment().ActiveDirectoryEndpoint,
-		config.Environment().TokenAudience)
+	groupClient := getGroupsClient(ctx)
 
 	return groupClient.CreateOrUpdate(ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		resources.Group{
-			Location: to.StringPtr(config.Location()),
+			Location: to.StringPtr(config.ScopeFrom(ctx).Location),
 		},
 	)
 }
 
 // DeleteGroup removes the resource group named by env var
 func DeleteGroup(ctx context.Context) (result resources.GroupsDeleteFuture, err error) {
-	groupsClient := getGroupsClient(
-		config.Environment().ActiveDirectoryEndpoint,
-		config.Environment().TokenAudience)
+	groupsClient := getGroupsClient(ctx)
 
-	return groupsClient.Delete(ctx, config.GroupName())
+	return groupsClient.Delete(ctx, config.ScopeFrom(ctx).GroupName)
+}
+
+// GetGroup gets info on the resource group in use
+func GetGroup(ctx context.Context) (resources.Group, error) {
+	groupsClient := getGroupsClient(ctx)
+	return groupsClient.Get(ctx, config.ScopeFrom(ctx).GroupName)
+}
+
+// ListGroups gets an iterator over the resource groups in the subscription
+func ListGroups(ctx context.Context) (resources.GroupListResultIterator, error) {
+	groupsClient := getGroupsClient(ctx)
+	return groupsClient.ListComplete(ctx, "", nil)
+}
+
+// UpdateGroupTags replaces the tags of the resource group in use
+func UpdateGroupTags(ctx context.Context, tags map[string]*string) (resources.Group, error) {
+	groupsClient := getGroupsClient(ctx)
+	return groupsClient.Update(ctx,
+		config.ScopeFrom(ctx).GroupName,
+		resources.GroupPatchable{
+			Tags: tags,
+		},
+	)
 }
@@ -0,0 +1,166 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package resources
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/resourceid"
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2020-06-01/resources"
+	"github.com/Azure/go-autorest/autorest/to"
+)
+
+// InventoryItem describes one resource in an inventory.
+type InventoryItem struct {
+	ID            string            `json:"id"`
+	Name          string            `json:"name"`
+	Type          string            `json:"type"`
+	ResourceGroup string            `json:"resourceGroup"`
+	Location      string            `json:"location"`
+	Tags          map[string]string `json:"tags,omitempty"`
+	CreatedTime   *time.Time        `json:"createdTime,omitempty"`
+	SKU           string            `json:"sku,omitempty"`
+	// APIVersion is the newest stable API version of the resource's type,
+	// to get the resource with, or empty if it couldn't be resolved.
+	APIVersion string `json:"apiVersion,omitempty"`
+	// APIVersionError is why APIVersion couldn't be resolved.
+	APIVersionError string `json:"apiVersionError,omitempty"`
+}
+
+// Inventory lists resources, sorted by ID.
+type Inventory []InventoryItem
+
+// ListInventory lists every resource in the named group, or in the whole
+// subscription of ctx if groupName is empty, through the generic resources
+// client.
+func ListInventory(ctx context.Context, groupName string) (Inventory, error) {
+	resourcesClient := getResourcesClient(ctx)
+	// createdTime is only returned when expanded
+	const expand = "createdTime,changedTime,provisioningState"
+	var list resources.ListResultIterator
+	var err error
+	if groupName == "" {
+		list, err = resourcesClient.ListComplete(ctx, "", expand, nil)
+	} else {
+		list, err = resourcesClient.ListByResourceGroupComplete(ctx, groupName, "", expand, nil)
+	}
+	var inventory Inventory
+	for ; err == nil && list.NotDone(); err = list.NextWithContext(ctx) {
+		inventory = append(inventory, newInventoryItem(list.Value()))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot list resources: %v", err)
+	}
+
+	for i, item := range inventory {
+		// types without a stable version are listed without one
+		apiVersion, err := LatestAPIVersion(ctx, item.Type)
+		if err != nil {
+			inventory[i].APIVersionError = err.Error()
+			continue
+		}
+		inventory[i].APIVersion = apiVersion
+	}
+	sort.Slice(inventory, func(i, j int) bool {
+		return strings.ToLower(inventory[i].ID) < strings.ToLower(inventory[j].ID)
+	})
+	return inventory, nil
+}
+
+func newInventoryItem(r resources.GenericResourceExpanded) InventoryItem {
+	item := InventoryItem{
+		ID:       to.String(r.ID),
+		Name:     to.String(r.Name),
+		Type:     to.String(r.Type),
+		Location: to.String(r.Location),
+	}
+	if id, err := resourceid.Parse(item.ID); err == nil {
+		item.ResourceGroup = id.ResourceGroupName
+	}
+	if len(r.Tags) > 0 {
+		item.Tags = map[string]string{}
+		for k, v := range r.Tags {
+			item.Tags[k] = to.String(v)
+		}
+	}
+	if r.CreatedTime != nil {
+		created := r.CreatedTime.ToTime().UTC()
+		item.CreatedTime = &created
+	}
+	if r.Sku != nil {
+		item.SKU = to.String(r.Sku.Name)
+		if tier := to.String(r.Sku.Tier); tier != "" && tier != item.SKU {
+			item.SKU = fmt.Sprintf("%s (%s)", item.SKU, tier)
+		}
+	}
+	return item
+}
+
+// inventoryColumns are the columns of the table and CSV formats.
+var inventoryColumns = []string{"NAME", "TYPE", "GROUP", "LOCATION", "SKU", "CREATED", "API VERSION", "TAGS"}
+
+func (item InventoryItem) columns() []string {
+	created := ""
+	if item.CreatedTime != nil {
+		created = item.CreatedTime.Format(time.RFC3339)
+	}
+	tags := make([]string, 0, len(item.Tags))
+	for k, v := range item.Tags {
+		tags = append(tags, k+"="+v)
+	}
+	sort.Strings(tags)
+	return []string{item.Name, item.Type, item.ResourceGroup, item.Location, item.SKU, created, item.APIVersion, strings.Join(tags, ";")}
+}
+
+// Fprint writes the inventory to w as a table.
+func (inv Inventory) Fprint(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(inventoryColumns, "\t"))
+	for _, item := range inv {
+		columns := item.columns()
+		for i, c := range columns {
+			if c == "" {
+				columns[i] = "-"
+			}
+		}
+		fmt.Fprintln(tw, strings.Join(columns, "\t"))
+	}
+	return tw.Flush()
+}
+
+// WriteJSON writes the inventory to w as a JSON array.
+func (inv Inventory) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if inv == nil {
+		inv = Inventory{}
+	}
+	return encoder.Encode(inv)
+}
+
+// WriteCSV writes the inventory to w as CSV with a header row and the
+// resource ID as the last column.
+func (inv Inventory) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(append(append([]string(nil), inventoryColumns...), "ID")); err != nil {
+		return err
+	}
+	for _, item := range inv {
+		if err := cw.Write(append(item.columns(), item.ID)); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
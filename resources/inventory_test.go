@@ -0,0 +1,71 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package resources
+
+import (
+	"os"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2020-06-01/resources"
+	"github.com/Azure/go-autorest/autorest/date"
+	"github.com/Azure/go-autorest/autorest/to"
+)
+
+func exampleInventory() Inventory {
+	created := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	return Inventory{
+		newInventoryItem(resources.GenericResourceExpanded{
+			ID:          to.StringPtr("/subscriptions/sub/resourceGroups/samples/providers/Microsoft.Network/publicIPAddresses/vm-ip"),
+			Name:        to.StringPtr("vm-ip"),
+			Type:        to.StringPtr("Microsoft.Network/publicIPAddresses"),
+			Location:    to.StringPtr("westus2"),
+			Sku:         &resources.Sku{Name: to.StringPtr("Basic"), Tier: to.StringPtr("Regional")},
+			CreatedTime: &date.Time{Time: created},
+			Tags:        map[string]*string{"env": to.StringPtr("test"), "app": to.StringPtr("vm")},
+		}),
+		newInventoryItem(resources.GenericResourceExpanded{
+			ID:       to.StringPtr("/subscriptions/sub/resourceGroups/samples/providers/Microsoft.Storage/storageAccounts/samplestore"),
+			Name:     to.StringPtr("samplestore"),
+			Type:     to.StringPtr("Microsoft.Storage/storageAccounts"),
+			Location: to.StringPtr("westus2"),
+			Sku:      &resources.Sku{Name: to.StringPtr("Standard_LRS")},
+		}),
+	}
+}
+
+func Example_printInventory() {
+	inventory := exampleInventory()
+	inventory[0].APIVersion = "2020-11-01"
+	_ = inventory.Fprint(os.Stdout)
+	_ = inventory.WriteCSV(os.Stdout)
+
+	// Output:
+	// NAME         TYPE                                 GROUP    LOCATION  SKU               CREATED               API VERSION  TAGS
+	// vm-ip        Microsoft.Network/publicIPAddresses  samples  westus2   Basic (Regional)  2021-06-01T12:00:00Z  2020-11-01   app=vm;env=test
+	// samplestore  Microsoft.Storage/storageAccounts    samples  westus2   Standard_LRS      -                     -            -
+	// NAME,TYPE,GROUP,LOCATION,SKU,CREATED,API VERSION,TAGS,ID
+	// vm-ip,Microsoft.Network/publicIPAddresses,samples,westus2,Basic (Regional),2021-06-01T12:00:00Z,2020-11-01,app=vm;env=test,/subscriptions/sub/resourceGroups/samples/providers/Microsoft.Network/publicIPAddresses/vm-ip
+	// samplestore,Microsoft.Storage/storageAccounts,samples,westus2,Standard_LRS,,,,/subscriptions/sub/resourceGroups/samples/providers/Microsoft.Storage/storageAccounts/samplestore
+}
+
+func Example_writeInventoryJSON() {
+	inventory := exampleInventory()[1:]
+	inventory[0].APIVersionError = "Microsoft.Storage/storageAccounts has no stable API version, only 2021-09-01-preview"
+	_ = inventory.WriteJSON(os.Stdout)
+
+	// Output:
+	// [
+	//   {
+	//     "id": "/subscriptions/sub/resourceGroups/samples/providers/Microsoft.Storage/storageAccounts/samplestore",
+	//     "name": "samplestore",
+	//     "type": "Microsoft.Storage/storageAccounts",
+	//     "resourceGroup": "samples",
+	//     "location": "westus2",
+	//     "sku": "Standard_LRS",
+	//     "apiVersionError": "Microsoft.Storage/storageAccounts has no stable API version, only 2021-09-01-preview"
+	//   }
+	// ]
+}
@@ -0,0 +1,267 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package resources
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/resourceid"
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2020-06-01/resources"
+	"github.com/Azure/go-autorest/autorest/to"
+)
+
+const deploymentResourceType = "Microsoft.Resources/deployments"
+
+func getDeploymentOperationsClient(subscriptionID string) resources.DeploymentOperationsClient {
+	operationsClient := resources.NewDeploymentOperationsClient(subscriptionID)
+	a, _ := iam.GetResourceManagementAuthorizer()
+	operationsClient.Authorizer = a
+	operationsClient.AddToUserAgent(config.UserAgent())
+	operationsClient.Sender = recording.Sender()
+	return operationsClient
+}
+
+// OperationProgress reports the state of the deployment of one resource.
+type OperationProgress struct {
+	OperationID  string
+	ResourceID   string
+	ResourceType string
+	ResourceName string
+	// ProvisioningState is e.g. Running, Succeeded or Failed.
+	ProvisioningState string
+	Duration          time.Duration
+	// StatusCode and StatusMessage are the resource provider's response,
+	// the message is only set for errors.
+	StatusCode    string
+	StatusMessage string
+}
+
+func newOperationProgress(op resources.DeploymentOperation) OperationProgress {
+	var p OperationProgress
+	if op.OperationID != nil {
+		p.OperationID = *op.OperationID
+	}
+	props := op.Properties
+	if props == nil {
+		return p
+	}
+	if target := props.TargetResource; target != nil {
+		p.ResourceID = to.String(target.ID)
+		p.ResourceType = to.String(target.ResourceType)
+		p.ResourceName = to.String(target.ResourceName)
+	}
+	p.ProvisioningState = to.String(props.ProvisioningState)
+	if props.Duration != nil {
+		p.Duration, _ = parseISO8601Duration(*props.Duration)
+	}
+	p.StatusCode = to.String(props.StatusCode)
+	if props.StatusMessage != nil && props.StatusMessage.Error != nil {
+		p.StatusMessage = to.String(props.StatusMessage.Error.Message)
+	}
+	return p
+}
+
+// waitForDeployment polls a deployment until it's done, calling progress
+// each time the state of one of its operations changes.
+func waitForDeployment(ctx context.Context, future *resources.DeploymentsCreateOrUpdateFuture, client resources.DeploymentsClient, deploymentName string, progress func(OperationProgress)) error {
+	scope := config.ScopeFrom(ctx)
+	operationsClient := getDeploymentOperationsClient(scope.SubscriptionID)
+	reported := map[string]string{}
+	report := func() {
+		ops, err := operationsClient.ListComplete(ctx, scope.GroupName, deploymentName, nil)
+		// the deployment may not list operations yet, try again next time
+		for ; err == nil && ops.NotDone(); err = ops.NextWithContext(ctx) {
+			p := newOperationProgress(ops.Value())
+			if reported[p.OperationID] != p.ProvisioningState {
+				reported[p.OperationID] = p.ProvisioningState
+				progress(p)
+			}
+		}
+	}
+
+	for {
+		done, err := future.DoneWithContext(ctx, client)
+		if err != nil {
+			return err
+		}
+		report()
+		if done {
+			return nil
+		}
+		delay, ok := future.GetPollingDelay()
+		if !ok {
+			delay = client.PollingDelay
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(recording.PollingDelay(delay)):
+		}
+	}
+}
+
+// DeploymentError explains why a deployment failed, down to the resources
+// which failed in it and in the deployments nested in it.
+type DeploymentError struct {
+	Deployment string
+	// Failures are the failed operations of the deployment.
+	Failures []OperationFailure
+	// Err is the error the deployment itself reported.
+	Err error
+}
+
+// OperationFailure is a failed deployment operation. If it deployed a
+// nested deployment, Nested lists the failures in that deployment.
+type OperationFailure struct {
+	ResourceID   string
+	ResourceType string
+	ResourceName string
+	Code         string
+	Message      string
+	Nested       []OperationFailure
+}
+
+func (e *DeploymentError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "deployment %s failed: %v", e.Deployment, e.Err)
+	var write func(failures []OperationFailure, indent string)
+	write = func(failures []OperationFailure, indent string) {
+		for _, f := range failures {
+			fmt.Fprintf(&b, "\n%s%s %s: %s", indent, f.ResourceType, f.ResourceName, f.Code)
+			if f.Message != "" {
+				fmt.Fprintf(&b, ": %s", f.Message)
+			}
+			write(f.Nested, indent+"  ")
+		}
+	}
+	write(e.Failures, "  ")
+	return b.String()
+}
+
+// Unwrap returns the error the deployment reported.
+func (e *DeploymentError) Unwrap() error {
+	return e.Err
+}
+
+// RootCauses returns the failed operations which didn't fail because of a
+// nested deployment, i.e. the resources which actually broke.
+func (e *DeploymentError) RootCauses() []OperationFailure {
+	var causes []OperationFailure
+	var walk func(failures []OperationFailure)
+	walk = func(failures []OperationFailure) {
+		for _, f := range failures {
+			if len(f.Nested) > 0 {
+				walk(f.Nested)
+			} else {
+				causes = append(causes, f)
+			}
+		}
+	}
+	walk(e.Failures)
+	return causes
+}
+
+// GetDeploymentError lists the operations of a failed deployment in the
+// group of ctx, following nested deployments, and explains the failure as a
+// *DeploymentError wrapping cause.
+func GetDeploymentError(ctx context.Context, deploymentName string, cause error) error {
+	scope := config.ScopeFrom(ctx)
+	failures, err := operationFailures(ctx, scope.SubscriptionID, scope.GroupName, deploymentName, 0)
+	if err != nil {
+		return fmt.Errorf("%v (cannot list deployment operations: %v)", cause, err)
+	}
+	return &DeploymentError{Deployment: deploymentName, Failures: failures, Err: cause}
+}
+
+// maxNestedDeployments bounds how deep operationFailures follows nested
+// deployments.
+const maxNestedDeployments = 10
+
+func operationFailures(ctx context.Context, subscriptionID, groupName, deploymentName string, depth int) ([]OperationFailure, error) {
+	operationsClient := getDeploymentOperationsClient(subscriptionID)
+	ops, err := operationsClient.ListComplete(ctx, groupName, deploymentName, nil)
+	var failures []OperationFailure
+	for ; err == nil && ops.NotDone(); err = ops.NextWithContext(ctx) {
+		op := ops.Value()
+		if op.Properties == nil || !strings.EqualFold(to.String(op.Properties.ProvisioningState), "Failed") {
+			continue
+		}
+		p := newOperationProgress(op)
+		f := OperationFailure{
+			ResourceID:   p.ResourceID,
+			ResourceType: p.ResourceType,
+			ResourceName: p.ResourceName,
+			Message:      p.StatusMessage,
+		}
+		if msg := op.Properties.StatusMessage; msg != nil && msg.Error != nil {
+			f.Code, f.Message = innermostError(*msg.Error)
+		}
+		if strings.EqualFold(f.ResourceType, deploymentResourceType) && depth < maxNestedDeployments {
+			// nested deployments may target another group or subscription
+			nestedSubscription, nestedGroup := subscriptionID, groupName
+			if id, err := resourceid.Parse(f.ResourceID); err == nil && id.ResourceGroupName != "" {
+				nestedSubscription, nestedGroup = id.SubscriptionID, id.ResourceGroupName
+			}
+			f.Nested, err = operationFailures(ctx, nestedSubscription, nestedGroup, f.ResourceName, depth+1)
+			if err != nil {
+				return nil, err
+			}
+		}
+		failures = append(failures, f)
+	}
+	return failures, err
+}
+
+// innermostError returns the code and message of the innermost error
+// details, which name the actual problem rather than e.g. "DeploymentFailed".
+// The messages of sibling details are joined.
+func innermostError(e resources.ErrorResponse) (code, message string) {
+	if e.Details == nil || len(*e.Details) == 0 {
+		return to.String(e.Code), to.String(e.Message)
+	}
+	var messages []string
+	for _, detail := range *e.Details {
+		detailCode, detailMessage := innermostError(detail)
+		if code == "" {
+			code = detailCode
+		}
+		if detailMessage != "" {
+			messages = append(messages, detailMessage)
+		}
+	}
+	return code, strings.Join(messages, "; ")
+}
+
+var iso8601Duration = regexp.MustCompile(`^P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+
+// parseISO8601Duration parses durations like `PT1M30.5S`, as reported by
+// deployment operations.
+func parseISO8601Duration(s string) (time.Duration, error) {
+	m := iso8601Duration.FindStringSubmatch(s)
+	if m == nil {
+		return 0, fmt.Errorf("invalid ISO 8601 duration %q", s)
+	}
+	var d time.Duration
+	for i, unit := range []time.Duration{24 * time.Hour, time.Hour, time.Minute, time.Second} {
+		if m[i+1] == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(m[i+1], 64)
+		if err != nil {
+			return 0, err
+		}
+		d += time.Duration(v * float64(unit))
+	}
+	return d, nil
+}
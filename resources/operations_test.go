@@ -0,0 +1,85 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package resources
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2020-06-01/resources"
+	"github.com/Azure/go-autorest/autorest/to"
+)
+
+func Example_deploymentError() {
+	err := &DeploymentError{
+		Deployment: "VMdeploy",
+		Err:        errors.New("Code=\"DeploymentFailed\""),
+		Failures: []OperationFailure{
+			{
+				ResourceType: "Microsoft.Resources/deployments",
+				ResourceName: "network",
+				Code:         "DeploymentFailed",
+				Nested: []OperationFailure{
+					{
+						ResourceType: "Microsoft.Network/virtualNetworks",
+						ResourceName: "vnet",
+						Code:         "InvalidAddressPrefix",
+						Message:      "Address prefix 10.0.0.0/33 is invalid.",
+					},
+				},
+			},
+		},
+	}
+	fmt.Println(err)
+	for _, cause := range err.RootCauses() {
+		fmt.Println("root cause:", cause.ResourceName, cause.Code)
+	}
+
+	// Output:
+	// deployment VMdeploy failed: Code="DeploymentFailed"
+	//   Microsoft.Resources/deployments network: DeploymentFailed
+	//     Microsoft.Network/virtualNetworks vnet: InvalidAddressPrefix: Address prefix 10.0.0.0/33 is invalid.
+	// root cause: vnet InvalidAddressPrefix
+}
+
+func TestInnermostError(t *testing.T) {
+	code, message := innermostError(resources.ErrorResponse{
+		Code:    to.StringPtr("DeploymentFailed"),
+		Message: to.StringPtr("At least one resource deployment operation failed."),
+		Details: &[]resources.ErrorResponse{
+			{Code: to.StringPtr("Conflict"), Details: &[]resources.ErrorResponse{
+				{Code: to.StringPtr("SkuNotAvailable"), Message: to.StringPtr("The requested size is not available.")},
+			}},
+			{Code: to.StringPtr("QuotaExceeded"), Message: to.StringPtr("Quota exceeded.")},
+		},
+	})
+	if code != "SkuNotAvailable" {
+		t.Errorf("expected the innermost code, got %q", code)
+	}
+	if message != "The requested size is not available.; Quota exceeded." {
+		t.Errorf("expected the innermost messages, got %q", message)
+	}
+}
+
+func TestParseISO8601Duration(t *testing.T) {
+	cases := map[string]time.Duration{
+		"PT1M30.5S": 90*time.Second + 500*time.Millisecond,
+		"PT2H":      2 * time.Hour,
+		"P1DT1S":    24*time.Hour + time.Second,
+		"PT0.123S":  123 * time.Millisecond,
+	}
+	for s, want := range cases {
+		got, err := parseISO8601Duration(s)
+		if err != nil || got != want {
+			t.Errorf("%s: expected %v, got %v, %v", s, want, got, err)
+		}
+	}
+	if _, err := parseISO8601Duration("90s"); err == nil {
+		t.Errorf("expected an error for a Go duration")
+	}
+}
@@ -0,0 +1,435 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package resources
+
+import (
+	"context"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/helper/resource"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-07-01/compute"
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2020-06-01/resources"
+	"github.com/Azure/go-autorest/autorest/to"
+)
+
+// Requirements are what a sample needs from the subscription before it can
+// run.
+type Requirements struct {
+	// Namespaces of the resource providers which must be registered, e.g.
+	// `Microsoft.Network`.
+	Namespaces []string
+	// ResourceTypes which must be available in the location, e.g.
+	// `Microsoft.Network/publicIPAddresses`.
+	ResourceTypes []string
+	// VMSizes which must be available to the subscription in the location,
+	// e.g. `Standard_B1s`.
+	VMSizes []string
+}
+
+// Merge returns the requirements of both r and other, without duplicates.
+func (r Requirements) Merge(other Requirements) Requirements {
+	return Requirements{
+		Namespaces:    union(r.Namespaces, other.Namespaces),
+		ResourceTypes: union(r.ResourceTypes, other.ResourceTypes),
+		VMSizes:       union(r.VMSizes, other.VMSizes),
+	}
+}
+
+// union returns the strings of a and b sorted, dropping those which only
+// differ in case.
+func union(a, b []string) []string {
+	seen := map[string]bool{}
+	var all []string
+	for _, s := range append(append([]string(nil), a...), b...) {
+		if key := strings.ToLower(s); !seen[key] {
+			seen[key] = true
+			all = append(all, s)
+		}
+	}
+	sort.Strings(all)
+	return all
+}
+
+// sdkNamespaces maps the services of the SDK's management packages to the
+// namespaces of their resource providers. Services whose providers are
+// always registered, like resources and authorization, are left out.
+var sdkNamespaces = map[string]string{
+	"batch":             "Microsoft.Batch",
+	"cdn":               "Microsoft.Cdn",
+	"cognitiveservices": "Microsoft.CognitiveServices",
+	"communication":     "Microsoft.Communication",
+	"compute":           "Microsoft.Compute",
+	"containerinstance": "Microsoft.ContainerInstance",
+	"containerregistry": "Microsoft.ContainerRegistry",
+	"containerservice":  "Microsoft.ContainerService",
+	"cosmos-db":         "Microsoft.DocumentDB",
+	"dns":               "Microsoft.Network",
+	"eventhub":          "Microsoft.EventHub",
+	"hdinsight":         "Microsoft.HDInsight",
+	"keyvault":          "Microsoft.KeyVault",
+	"monitor":           "Microsoft.Insights",
+	"msi":               "Microsoft.ManagedIdentity",
+	"mysql":             "Microsoft.DBforMySQL",
+	"network":           "Microsoft.Network",
+	"postgresql":        "Microsoft.DBforPostgreSQL",
+	"privatedns":        "Microsoft.Network",
+	"servicebus":        "Microsoft.ServiceBus",
+	"sql":               "Microsoft.Sql",
+	"storage":           "Microsoft.Storage",
+	"web":               "Microsoft.Web",
+}
+
+// sdkPackage matches the import paths of the SDK's management packages,
+// e.g. `.../services/network/mgmt/2019-11-01/network` and
+// `.../sdk/network/armnetwork`.
+var sdkPackage = regexp.MustCompile(`^github\.com/Azure/azure-sdk-for-go/(?:services/(?:preview/)?([a-z0-9-]+)/mgmt/|sdk/([a-z0-9]+)/arm[a-z0-9]+$)`)
+
+// RequirementsFromPackage returns the provider namespaces a Go package
+// needs, from the SDK management packages its files, including tests,
+// import.
+func RequirementsFromPackage(dir string) (Requirements, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return Requirements{}, err
+	}
+	var namespaces []string
+	fset := token.NewFileSet()
+	for _, file := range files {
+		f, err := parser.ParseFile(fset, file, nil, parser.ImportsOnly)
+		if err != nil {
+			return Requirements{}, err
+		}
+		for _, spec := range f.Imports {
+			path, _ := strconv.Unquote(spec.Path.Value)
+			m := sdkPackage.FindStringSubmatch(path)
+			if m == nil {
+				continue
+			}
+			if namespace, ok := sdkNamespaces[m[1]+m[2]]; ok {
+				namespaces = append(namespaces, namespace)
+			}
+		}
+	}
+	return Requirements{Namespaces: union(namespaces, nil)}, nil
+}
+
+// parameterExpression matches template values which are just a parameter.
+var parameterExpression = regexp.MustCompile(`^\[parameters\('([^']+)'\)\]$`)
+
+// RequirementsFromTemplate returns the provider namespaces, resource types
+// and VM sizes a deployment template needs, including those of its inline
+// nested deployments. VM sizes given as a parameter are looked up in params,
+// in the form deployments take them, or else in the parameter's default.
+func RequirementsFromTemplate(template, params *map[string]interface{}) Requirements {
+	var r Requirements
+	var values map[string]interface{}
+	if params != nil {
+		values = *params
+	}
+	if template != nil {
+		r.addTemplate(*template, values)
+	}
+	return r.Merge(Requirements{})
+}
+
+func (r *Requirements) addTemplate(template, params map[string]interface{}) {
+	definitions, _ := template["parameters"].(map[string]interface{})
+	resolve := func(value interface{}) (string, bool) {
+		s, ok := value.(string)
+		if !ok {
+			return "", false
+		}
+		m := parameterExpression.FindStringSubmatch(s)
+		if m == nil {
+			// other expressions can't be evaluated here
+			return s, !strings.HasPrefix(s, "[")
+		}
+		if param, ok := lookupFold(params, m[1]).(map[string]interface{}); ok {
+			s, ok := param["value"].(string)
+			return s, ok
+		}
+		if def, ok := lookupFold(definitions, m[1]).(map[string]interface{}); ok {
+			s, ok := def["defaultValue"].(string)
+			return s, ok && !strings.HasPrefix(s, "[")
+		}
+		return "", false
+	}
+
+	var walk func(items []interface{}, parentType string)
+	walk = func(items []interface{}, parentType string) {
+		for _, res := range items {
+			resource, ok := res.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			resourceType, _ := resource["type"].(string)
+			if parentType != "" && !strings.Contains(resourceType, ".") {
+				// nested resources may give their type relative to the parent
+				resourceType = parentType + "/" + resourceType
+			}
+			if resourceType == "" {
+				continue
+			}
+			namespace := strings.SplitN(resourceType, "/", 2)[0]
+			if !strings.EqualFold(namespace, "Microsoft.Resources") {
+				r.Namespaces = append(r.Namespaces, namespace)
+				r.ResourceTypes = append(r.ResourceTypes, resourceType)
+			}
+
+			properties, _ := resource["properties"].(map[string]interface{})
+			switch strings.ToLower(resourceType) {
+			case "microsoft.compute/virtualmachines":
+				hardware, _ := properties["hardwareProfile"].(map[string]interface{})
+				if size, ok := resolve(hardware["vmSize"]); ok {
+					r.VMSizes = append(r.VMSizes, size)
+				}
+			case "microsoft.resources/deployments":
+				// only inline templates, linked ones aren't fetched
+				if nested, ok := properties["template"].(map[string]interface{}); ok {
+					nestedParams, _ := properties["parameters"].(map[string]interface{})
+					r.addTemplate(nested, nestedParams)
+				}
+			}
+			if children, ok := resource["resources"].([]interface{}); ok {
+				walk(children, resourceType)
+			}
+		}
+	}
+	items, _ := template["resources"].([]interface{})
+	walk(items, "")
+}
+
+func lookupFold(m map[string]interface{}, key string) interface{} {
+	for k, v := range m {
+		if strings.EqualFold(k, key) {
+			return v
+		}
+	}
+	return nil
+}
+
+// PreflightOptions configure Preflight.
+type PreflightOptions struct {
+	// Location to check availability in, `config.Location()` if empty.
+	Location string
+	// Register registers providers which aren't registered yet and waits
+	// until they are, rather than reporting them.
+	Register bool
+}
+
+// PreflightError lists every unmet requirement Preflight found.
+type PreflightError struct {
+	Location string
+	Problems []string
+}
+
+func (e *PreflightError) Error() string {
+	return fmt.Sprintf("subscription isn't ready for the sample in %s:\n  %s", e.Location, strings.Join(e.Problems, "\n  "))
+}
+
+// Preflight checks that the subscription of ctx meets the requirements of a
+// sample before it runs, so it fails at once with a clear message rather
+// than halfway through. Providers must be registered, optionally by
+// Preflight itself, and resource types and VM sizes must be available in
+// the location. Every unmet requirement is reported at once, as a
+// *PreflightError.
+func Preflight(ctx context.Context, req Requirements, options *PreflightOptions) error {
+	if options == nil {
+		options = &PreflightOptions{}
+	}
+	location := options.Location
+	if location == "" {
+		location = config.ScopeFrom(ctx).Location
+	}
+	req = req.Merge(Requirements{})
+	for _, resourceType := range req.ResourceTypes {
+		req.Namespaces = union(req.Namespaces, []string{strings.SplitN(resourceType, "/", 2)[0]})
+	}
+
+	var problems []string
+	providers := map[string]resources.Provider{}
+	for _, namespace := range req.Namespaces {
+		provider, err := checkProvider(ctx, namespace, options.Register)
+		if err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		providers[strings.ToLower(namespace)] = provider
+	}
+
+	for _, resourceType := range req.ResourceTypes {
+		i := strings.Index(resourceType, "/")
+		if i < 1 {
+			problems = append(problems, fmt.Sprintf("%q isn't a resource type", resourceType))
+			continue
+		}
+		provider, ok := providers[strings.ToLower(resourceType[:i])]
+		if !ok {
+			// the provider's problem is already reported
+			continue
+		}
+		if problem := checkLocation(provider, resourceType[i+1:], location); problem != "" {
+			problems = append(problems, fmt.Sprintf("%s %s", resourceType, problem))
+		}
+	}
+
+	if len(req.VMSizes) > 0 {
+		sizeProblems, err := checkVMSizes(ctx, req.VMSizes, location)
+		if err != nil {
+			return err
+		}
+		problems = append(problems, sizeProblems...)
+	}
+
+	if len(problems) > 0 {
+		return &PreflightError{Location: location, Problems: problems}
+	}
+	return nil
+}
+
+// checkProvider returns the provider of namespace if it's registered,
+// registering it and waiting first if register is set.
+func checkProvider(ctx context.Context, namespace string, register bool) (resources.Provider, error) {
+	providerClient := getProviderClient(ctx)
+	provider, err := providerClient.Get(ctx, namespace, "")
+	if err != nil {
+		return provider, fmt.Errorf("cannot get provider %s: %v", namespace, err)
+	}
+	state := to.String(provider.RegistrationState)
+	if strings.EqualFold(state, "Registered") {
+		return provider, nil
+	}
+	if !register {
+		return provider, fmt.Errorf("provider %s is %s, register it with `az provider register --namespace %s`", namespace, state, namespace)
+	}
+
+	if !strings.EqualFold(state, "Registering") {
+		if _, err := providerClient.Register(ctx, namespace); err != nil {
+			return provider, fmt.Errorf("cannot register provider %s: %v", namespace, err)
+		}
+	}
+	return waitForRegistration(ctx, namespace, func() (resources.Provider, error) {
+		return providerClient.Get(ctx, namespace, "")
+	}, nil)
+}
+
+// waitForRegistration refreshes a provider until it's registered. States
+// other than Registering and NotRegistered, which it reports for a while
+// after Register, end the wait early. A nil clock is the real one.
+func waitForRegistration(ctx context.Context, namespace string, refresh func() (resources.Provider, error), clock resource.Clock) (resources.Provider, error) {
+	stateConf := &resource.StateChangeConf{
+		Pending: []string{"Registering", "NotRegistered"},
+		Target:  []string{"Registered"},
+		Refresh: func() (interface{}, string, error) {
+			provider, err := refresh()
+			if err != nil {
+				return nil, "", fmt.Errorf("cannot get provider %s: %v", namespace, err)
+			}
+			return provider, to.String(provider.RegistrationState), nil
+		},
+		Backoff: resource.ExponentialBackoff(recording.PollingDelay(5*time.Second), recording.PollingDelay(time.Minute), 0.2),
+		Clock:   clock,
+	}
+	res, err := stateConf.WaitForStateContext(ctx)
+	provider, _ := res.(resources.Provider)
+	if err != nil {
+		return provider, fmt.Errorf("cannot wait for provider %s to register: %v", namespace, err)
+	}
+	return provider, nil
+}
+
+// checkLocation returns why a provider's resource type can't be used in
+// location, or "".
+func checkLocation(provider resources.Provider, typeName, location string) string {
+	if provider.ResourceTypes == nil {
+		return ""
+	}
+	for _, t := range *provider.ResourceTypes {
+		if !strings.EqualFold(to.String(t.ResourceType), typeName) {
+			continue
+		}
+		// types without locations, like global ones, are available anywhere
+		if t.Locations == nil || len(*t.Locations) == 0 {
+			return ""
+		}
+		for _, l := range *t.Locations {
+			if normalizeLocation(l) == normalizeLocation(location) {
+				return ""
+			}
+		}
+		return fmt.Sprintf("isn't available in %s", location)
+	}
+	return "isn't a resource type of its provider"
+}
+
+func getResourceSkusClient(ctx context.Context) compute.ResourceSkusClient {
+	skusClient := compute.NewResourceSkusClient(config.ScopeFrom(ctx).SubscriptionID)
+	a, _ := iam.GetResourceManagementAuthorizer()
+	skusClient.Authorizer = a
+	skusClient.AddToUserAgent(config.UserAgent())
+	skusClient.Sender = recording.Sender()
+	return skusClient
+}
+
+// checkVMSizes returns why VM sizes can't be used in location.
+func checkVMSizes(ctx context.Context, sizes []string, location string) ([]string, error) {
+	skusClient := getResourceSkusClient(ctx)
+	found := map[string]string{}
+	filter := fmt.Sprintf("location eq '%s'", normalizeLocation(location))
+	list, err := skusClient.ListComplete(ctx, filter)
+	for ; err == nil && list.NotDone(); err = list.NextWithContext(ctx) {
+		sku := list.Value()
+		if !strings.EqualFold(to.String(sku.ResourceType), "virtualMachines") {
+			continue
+		}
+		found[strings.ToLower(to.String(sku.Name))] = skuRestriction(sku, location)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot list VM sizes: %v", err)
+	}
+
+	var problems []string
+	for _, size := range sizes {
+		restriction, ok := found[strings.ToLower(size)]
+		switch {
+		case !ok:
+			problems = append(problems, fmt.Sprintf("VM size %s isn't available in %s", size, location))
+		case restriction != "":
+			problems = append(problems, fmt.Sprintf("VM size %s is restricted in %s: %s", size, location, restriction))
+		}
+	}
+	return problems, nil
+}
+
+// skuRestriction returns why a SKU can't be used in location, or "". Zone
+// restrictions are ignored, the SKU is still usable without zones.
+func skuRestriction(sku compute.ResourceSku, location string) string {
+	if sku.Restrictions == nil {
+		return ""
+	}
+	for _, r := range *sku.Restrictions {
+		if r.Type != compute.Location || r.Values == nil {
+			continue
+		}
+		for _, v := range *r.Values {
+			if normalizeLocation(v) == normalizeLocation(location) {
+				return string(r.ReasonCode)
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,172 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package resources
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/helper/resource"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/util"
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-07-01/compute"
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2020-06-01/resources"
+	"github.com/Azure/go-autorest/autorest/to"
+)
+
+func TestRequirementsFromTemplate(t *testing.T) {
+	template, err := util.ReadJSON(filepath.Join("testdata", "template.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := RequirementsFromTemplate(template, nil)
+	if want := []string{"Microsoft.Compute", "Microsoft.Network"}; !reflect.DeepEqual(r.Namespaces, want) {
+		t.Errorf("expected namespaces %v, got %v", want, r.Namespaces)
+	}
+	if want := []string{"Standard_B1s"}; !reflect.DeepEqual(r.VMSizes, want) {
+		t.Errorf("expected VM sizes %v, got %v", want, r.VMSizes)
+	}
+
+	nested := map[string]interface{}{
+		"parameters": map[string]interface{}{
+			"size": map[string]interface{}{"type": "string", "defaultValue": "Standard_B2s"},
+		},
+		"resources": []interface{}{
+			map[string]interface{}{
+				"type": "Microsoft.Resources/deployments",
+				"properties": map[string]interface{}{
+					"template": map[string]interface{}{
+						"resources": []interface{}{
+							map[string]interface{}{
+								"type":       "Microsoft.Compute/virtualMachines",
+								"properties": map[string]interface{}{"hardwareProfile": map[string]interface{}{"vmSize": "[parameters('size')]"}},
+								"resources":  []interface{}{map[string]interface{}{"type": "extensions"}},
+							},
+						},
+						"parameters": map[string]interface{}{"size": map[string]interface{}{"type": "string"}},
+					},
+					"parameters": map[string]interface{}{"size": map[string]interface{}{"value": "Standard_D2s_v3"}},
+				},
+			},
+			map[string]interface{}{
+				"type":       "Microsoft.Compute/virtualMachines",
+				"properties": map[string]interface{}{"hardwareProfile": map[string]interface{}{"vmSize": "[parameters('Size')]"}},
+			},
+			map[string]interface{}{
+				"type":       "Microsoft.Compute/virtualMachines",
+				"properties": map[string]interface{}{"hardwareProfile": map[string]interface{}{"vmSize": "[variables('size')]"}},
+			},
+		},
+	}
+	r = RequirementsFromTemplate(&nested, nil)
+	if want := []string{"Microsoft.Compute/virtualMachines", "Microsoft.Compute/virtualMachines/extensions"}; !reflect.DeepEqual(r.ResourceTypes, want) {
+		t.Errorf("expected resource types %v, got %v", want, r.ResourceTypes)
+	}
+	if want := []string{"Standard_B2s", "Standard_D2s_v3"}; !reflect.DeepEqual(r.VMSizes, want) {
+		t.Errorf("expected VM sizes %v, got %v", want, r.VMSizes)
+	}
+}
+
+func TestRequirementsFromPackage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "resources")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	source := `package sample
+
+import (
+	"github.com/Azure/azure-sdk-for-go/sdk/network/armnetwork"
+	"github.com/Azure/azure-sdk-for-go/services/batch/2018-12-01.8.0/batch"
+	batchmgmt "github.com/Azure/azure-sdk-for-go/services/batch/mgmt/2017-09-01/batch"
+	"github.com/Azure/azure-sdk-for-go/services/preview/dns/mgmt/2018-03-01-preview/dns"
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2020-06-01/resources"
+)
+`
+	if err := ioutil.WriteFile(filepath.Join(dir, "sample.go"), []byte(source), 0600); err != nil {
+		t.Fatal(err)
+	}
+	r, err := RequirementsFromPackage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"Microsoft.Batch", "Microsoft.Network"}; !reflect.DeepEqual(r.Namespaces, want) {
+		t.Errorf("expected namespaces %v, got %v", want, r.Namespaces)
+	}
+}
+
+func TestCheckAvailability(t *testing.T) {
+	provider := resources.Provider{ResourceTypes: &[]resources.ProviderResourceType{
+		{ResourceType: to.StringPtr("publicIPAddresses"), Locations: &[]string{"West US 2", "East US"}},
+		{ResourceType: to.StringPtr("dnszones"), Locations: &[]string{}},
+	}}
+	for typeName, want := range map[string]string{
+		"publicIPAddresses": "",
+		"dnsZones":          "",
+		"loadBalancers":     "isn't a resource type of its provider",
+	} {
+		if got := checkLocation(provider, typeName, "westus2"); got != want {
+			t.Errorf("%s: expected %q, got %q", typeName, want, got)
+		}
+	}
+	if got := checkLocation(provider, "publicIPAddresses", "northeurope"); !strings.Contains(got, "isn't available") {
+		t.Errorf("expected publicIPAddresses to be unavailable in northeurope, got %q", got)
+	}
+
+	sku := compute.ResourceSku{Restrictions: &[]compute.ResourceSkuRestrictions{
+		{Type: compute.Zone, Values: &[]string{"westus2"}},
+		{Type: compute.Location, Values: &[]string{"eastus"}, ReasonCode: compute.NotAvailableForSubscription},
+	}}
+	if got := skuRestriction(sku, "westus2"); got != "" {
+		t.Errorf("expected zone restrictions to be ignored, got %q", got)
+	}
+	if got := skuRestriction(sku, "East US"); got != string(compute.NotAvailableForSubscription) {
+		t.Errorf("expected the SKU to be restricted in East US, got %q", got)
+	}
+}
+
+func TestWaitForRegistration(t *testing.T) {
+	cases := []struct {
+		name    string
+		states  []string
+		refresh int
+		err     string
+	}{
+		{name: "registers", states: []string{"NotRegistered", "Registering", "Registering", "Registered"}, refresh: 4},
+		{name: "unregistering", states: []string{"Registering", "Unregistering", "Registered"}, refresh: 2, err: "unexpected state 'Unregistering'"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			refresh := 0
+			clock := resource.NewFakeClock(time.Now())
+			provider, err := waitForRegistration(context.Background(), "Microsoft.Network", func() (resources.Provider, error) {
+				state := c.states[refresh]
+				refresh++
+				return resources.Provider{RegistrationState: to.StringPtr(state)}, nil
+			}, clock)
+			if c.err == "" && err != nil {
+				t.Fatalf("failed to wait for the provider: %v", err)
+			}
+			if c.err != "" && (err == nil || !strings.Contains(err.Error(), c.err)) {
+				t.Fatalf("expected an error containing %q, got %v", c.err, err)
+			}
+			if refresh != c.refresh {
+				t.Errorf("expected %d refreshes, got %d", c.refresh, refresh)
+			}
+			if waits := clock.Waits(); len(waits) != refresh-1 {
+				t.Errorf("expected a wait between each refresh, got %v", waits)
+			}
+			if c.err == "" && to.String(provider.RegistrationState) != "Registered" {
+				t.Errorf("expected the registered provider, got %s", to.String(provider.RegistrationState))
+			}
+		})
+	}
+}
@@ -10,19 +10,21 @@ import (
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
-	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2019-05-01/resources"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2020-06-01/resources"
 )
 
-func getProviderClient() resources.ProvidersClient {
-	providerClient := resources.NewProvidersClient(config.SubscriptionID())
+func getProviderClient(ctx context.Context) resources.ProvidersClient {
+	providerClient := resources.NewProvidersClient(config.ScopeFrom(ctx).SubscriptionID)
 	a, _ := iam.GetResourceManagementAuthorizer()
 	providerClient.Authorizer = a
 	providerClient.AddToUserAgent(config.UserAgent())
+	providerClient.Sender = recording.Sender()
 	return providerClient
 }
 
 // RegisterProvider registers an azure resource provider for the subscription
 func RegisterProvider(ctx context.Context, provider string) (resources.Provider, error) {
-	providerClient := getProviderClient()
+	providerClient := getProviderClient(ctx)
 	return providerClient.Register(ctx, provider)
 }
@@ -7,20 +7,25 @@ package resources
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
-	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2019-05-01/resources"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/resourceid"
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2020-06-01/resources"
 	"github.com/Azure/go-autorest/autorest"
 )
 
-func getResourcesClient() resources.Client {
-	resourcesClient := resources.NewClient(config.SubscriptionID())
+func getResourcesClient(ctx context.Context) resources.Client {
+	resourcesClient := resources.NewClient(config.ScopeFrom(ctx).SubscriptionID)
 	a, _ := iam.GetResourceManagementAuthorizer()
 	resourcesClient.Authorizer = a
 	resourcesClient.AddToUserAgent(config.UserAgent())
+	resourcesClient.Sender = recording.Sender()
 	return resourcesClient
 }
 
@@ -44,21 +49,101 @@ func WithAPIVersion(apiVersion string) autorest.PrepareDecorator {
 	}
 }
 
-// GetResource gets a resource, the generic way.
-// The API version parameter overrides the API version in
-// the SDK, this is needed because not all resources are
-// supported on all API versions.
-func GetResource(ctx context.Context, resourceProvider, resourceType, resourceName, apiVersion string) (resources.GenericResource, error) {
-	resourcesClient := getResourcesClient()
-	resourcesClient.RequestInspector = WithAPIVersion(apiVersion)
+// GetResource gets a resource, the generic way, in the group of ctx. The
+// resource type's newest stable API version is used, as not all resources are
+// supported on the API version of the SDK; see LatestAPIVersion.
+func GetResource(ctx context.Context, resourceProvider, resourceType, resourceName string) (resource resources.GenericResource, err error) {
+	resourcesClient := getResourcesClient(ctx)
+	err = withAPIVersions(ctx, resourceProvider+"/"+resourceType, func(apiVersion string) error {
+		resource, err = resourcesClient.Get(
+			ctx,
+			config.ScopeFrom(ctx).GroupName,
+			resourceProvider,
+			"",
+			resourceType,
+			resourceName,
+			apiVersion,
+		)
+		return err
+	})
+	return resource, err
+}
+
+// GetResourceByID gets a resource by its full ID, like GetResource.
+func GetResourceByID(ctx context.Context, resourceID string) (resource resources.GenericResource, err error) {
+	id, err := resourceid.Parse(resourceID)
+	if err != nil {
+		return resource, err
+	}
+	resourcesClient := getResourcesClient(ctx)
+	err = withAPIVersions(ctx, id.ResourceType.String(), func(apiVersion string) error {
+		resource, err = resourcesClient.GetByID(ctx, resourceID, apiVersion)
+		return err
+	})
+	return resource, err
+}
+
+// UpdateResource patches the resource with the given ID, e.g. its tags, and
+// waits for the update to finish.
+func UpdateResource(ctx context.Context, resourceID string, update resources.GenericResource) (resource resources.GenericResource, err error) {
+	id, err := resourceid.Parse(resourceID)
+	if err != nil {
+		return resource, err
+	}
+	resourcesClient := getResourcesClient(ctx)
+	err = withAPIVersions(ctx, id.ResourceType.String(), func(apiVersion string) error {
+		future, err := resourcesClient.UpdateByID(ctx, resourceID, apiVersion, update)
+		if err != nil {
+			return err
+		}
+		if err := future.WaitForCompletionRef(ctx, resourcesClient.Client); err != nil {
+			return err
+		}
+		resource, err = future.Result(resourcesClient)
+		return err
+	})
+	return resource, err
+}
+
+// DeleteResource deletes the resource with the given ID and waits for the
+// deletion to finish.
+func DeleteResource(ctx context.Context, resourceID string) error {
+	id, err := resourceid.Parse(resourceID)
+	if err != nil {
+		return err
+	}
+	resourcesClient := getResourcesClient(ctx)
+	return withAPIVersions(ctx, id.ResourceType.String(), func(apiVersion string) error {
+		future, err := resourcesClient.DeleteByID(ctx, resourceID, apiVersion)
+		if err != nil {
+			return err
+		}
+		return future.WaitForCompletionRef(ctx, resourcesClient.Client)
+	})
+}
+
+// ListResourcesByType lists the resources of a type such as
+// `Microsoft.Network/publicIPAddresses` in the subscription of ctx.
+func ListResourcesByType(ctx context.Context, resourceType string) ([]resources.GenericResourceExpanded, error) {
+	// fail early on misspelled types, which would just list nothing
+	if _, err := ListAPIVersions(ctx, resourceType); err != nil {
+		return nil, err
+	}
+	resourcesClient := getResourcesClient(ctx)
+	filter := resourceTypeFilter(resourceType)
+	var found []resources.GenericResourceExpanded
+	list, err := resourcesClient.ListComplete(ctx, filter, "", nil)
+	for ; err == nil && list.NotDone(); err = list.NextWithContext(ctx) {
+		found = append(found, list.Value())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return found, nil
+}
 
-	return resourcesClient.Get(
-		ctx,
-		config.GroupName(),
-		resourceProvider,
-		"",
-		resourceType,
-		resourceName,
-		apiVersion,
-	)
+// resourceTypeFilter returns the OData filter selecting resources of a type.
+// Quotes in OData string literals are doubled.
+func resourceTypeFilter(resourceType string) string {
+	return fmt.Sprintf("resourceType eq '%s'", strings.ReplaceAll(resourceType, "'", "''"))
 }
@@ -0,0 +1,190 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package resources
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2020-06-01/resources"
+)
+
+// ChangeSet is what a deployment would change, as predicted by the ARM
+// what-if operation.
+type ChangeSet struct {
+	Changes []ResourceChange
+}
+
+// ResourceChange is the predicted change to one resource.
+type ResourceChange struct {
+	ResourceID string
+	// ChangeType is one of Create, Delete, Modify, Deploy (changes ARM
+	// can't predict), NoChange or Ignore (resources the template doesn't
+	// mention and Incremental mode leaves alone).
+	ChangeType resources.ChangeType
+	// Properties lists the changed properties of modified resources.
+	Properties []PropertyChange
+}
+
+// PropertyChange is the predicted change to one property of a resource.
+// Nested changes are flattened, so Path is e.g. `properties.addressSpace.addressPrefixes[0]`.
+type PropertyChange struct {
+	Path       string
+	ChangeType resources.PropertyChangeType
+	Before     interface{}
+	After      interface{}
+}
+
+// newChangeSet converts the result of the what-if operation.
+func newChangeSet(result resources.WhatIfOperationResult) ChangeSet {
+	var cs ChangeSet
+	if result.WhatIfOperationProperties == nil || result.Changes == nil {
+		return cs
+	}
+	for _, change := range *result.Changes {
+		rc := ResourceChange{ChangeType: change.ChangeType}
+		if change.ResourceID != nil {
+			rc.ResourceID = *change.ResourceID
+		}
+		if change.Delta != nil {
+			rc.Properties = flattenPropertyChanges("", *change.Delta)
+		}
+		cs.Changes = append(cs.Changes, rc)
+	}
+	return cs
+}
+
+func flattenPropertyChanges(parent string, changes []resources.WhatIfPropertyChange) []PropertyChange {
+	var flat []PropertyChange
+	for _, change := range changes {
+		path := parent
+		if change.Path != nil {
+			switch {
+			case parent == "":
+				path = *change.Path
+			case isIndex(*change.Path):
+				path = fmt.Sprintf("%s[%s]", parent, *change.Path)
+			default:
+				path = parent + "." + *change.Path
+			}
+		}
+		if change.Children != nil && len(*change.Children) > 0 {
+			flat = append(flat, flattenPropertyChanges(path, *change.Children)...)
+			continue
+		}
+		flat = append(flat, PropertyChange{
+			Path:       path,
+			ChangeType: change.PropertyChangeType,
+			Before:     change.Before,
+			After:      change.After,
+		})
+	}
+	return flat
+}
+
+func isIndex(path string) bool {
+	if path == "" {
+		return false
+	}
+	for _, r := range path {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Filter returns the changes of the given types, e.g. the resources a
+// Complete mode deployment would delete.
+func (cs ChangeSet) Filter(changeTypes ...resources.ChangeType) []ResourceChange {
+	var filtered []ResourceChange
+	for _, change := range cs.Changes {
+		for _, changeType := range changeTypes {
+			if change.ChangeType == changeType {
+				filtered = append(filtered, change)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// changeSymbols prefix each resource and property in the diff, like the
+// Azure CLI's `az deployment group what-if`.
+var changeSymbols = map[string]string{
+	string(resources.ChangeTypeCreate):        "+",
+	string(resources.ChangeTypeDelete):        "-",
+	string(resources.ChangeTypeModify):        "~",
+	string(resources.ChangeTypeDeploy):        "!",
+	string(resources.ChangeTypeNoChange):      "=",
+	string(resources.ChangeTypeIgnore):        "*",
+	string(resources.PropertyChangeTypeArray): "~",
+}
+
+// Fprint writes a human-readable diff of the change set to w, resources
+// sorted by ID.
+func (cs ChangeSet) Fprint(w io.Writer) error {
+	changes := append([]ResourceChange(nil), cs.Changes...)
+	sort.SliceStable(changes, func(i, j int) bool {
+		return strings.ToLower(changes[i].ResourceID) < strings.ToLower(changes[j].ResourceID)
+	})
+
+	var b strings.Builder
+	counts := map[resources.ChangeType]int{}
+	for _, change := range changes {
+		counts[change.ChangeType]++
+		fmt.Fprintf(&b, "  %s %s\n", changeSymbols[string(change.ChangeType)], change.ResourceID)
+		for _, property := range change.Properties {
+			switch property.ChangeType {
+			case resources.PropertyChangeTypeCreate:
+				fmt.Fprintf(&b, "      + %s: %s\n", property.Path, formatValue(property.After))
+			case resources.PropertyChangeTypeDelete:
+				fmt.Fprintf(&b, "      - %s: %s\n", property.Path, formatValue(property.Before))
+			default:
+				fmt.Fprintf(&b, "      %s %s: %s => %s\n", changeSymbols[string(property.ChangeType)],
+					property.Path, formatValue(property.Before), formatValue(property.After))
+			}
+		}
+	}
+
+	var summary []string
+	for _, changeType := range []resources.ChangeType{
+		resources.ChangeTypeCreate, resources.ChangeTypeModify, resources.ChangeTypeDelete,
+		resources.ChangeTypeDeploy, resources.ChangeTypeNoChange, resources.ChangeTypeIgnore,
+	} {
+		if n := counts[changeType]; n > 0 && changeType == resources.ChangeTypeNoChange {
+			summary = append(summary, fmt.Sprintf("%d unchanged", n))
+		} else if n > 0 {
+			summary = append(summary, fmt.Sprintf("%d to %s", n, strings.ToLower(string(changeType))))
+		}
+	}
+	if len(summary) == 0 {
+		summary = append(summary, "no changes")
+	}
+	_, err := fmt.Fprintf(w, "Resource changes: %s.\n%s", strings.Join(summary, ", "), b.String())
+	return err
+}
+
+// String returns the diff written by Fprint.
+func (cs ChangeSet) String() string {
+	var b strings.Builder
+	cs.Fprint(&b)
+	return b.String()
+}
+
+func formatValue(v interface{}) string {
+	if v == nil {
+		return "null"
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
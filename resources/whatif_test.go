@@ -0,0 +1,57 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package resources
+
+import (
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2020-06-01/resources"
+	"github.com/Azure/go-autorest/autorest/to"
+)
+
+func Example_printChangeSet() {
+	group := "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/az-samples-go"
+	changes := newChangeSet(resources.WhatIfOperationResult{
+		WhatIfOperationProperties: &resources.WhatIfOperationProperties{
+			Changes: &[]resources.WhatIfChange{
+				{
+					ResourceID: to.StringPtr(group + "/providers/Microsoft.Network/virtualNetworks/vnet"),
+					ChangeType: resources.ChangeTypeModify,
+					Delta: &[]resources.WhatIfPropertyChange{
+						{
+							Path:               to.StringPtr("properties.addressSpace.addressPrefixes"),
+							PropertyChangeType: resources.PropertyChangeTypeArray,
+							Children: &[]resources.WhatIfPropertyChange{
+								{Path: to.StringPtr("0"), PropertyChangeType: resources.PropertyChangeTypeModify, Before: "10.0.0.0/16", After: "10.1.0.0/16"},
+							},
+						},
+						{Path: to.StringPtr("tags.env"), PropertyChangeType: resources.PropertyChangeTypeCreate, After: "prod"},
+					},
+				},
+				{
+					ResourceID: to.StringPtr(group + "/providers/Microsoft.Compute/virtualMachines/vm"),
+					ChangeType: resources.ChangeTypeDelete,
+				},
+				{
+					ResourceID: to.StringPtr(group + "/providers/Microsoft.Network/publicIPAddresses/ip"),
+					ChangeType: resources.ChangeTypeCreate,
+				},
+			},
+		},
+	})
+
+	fmt.Print(changes)
+	fmt.Println(len(changes.Filter(resources.ChangeTypeDelete)), "to delete")
+
+	// Output:
+	// Resource changes: 1 to create, 1 to modify, 1 to delete.
+	//   - /subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/az-samples-go/providers/Microsoft.Compute/virtualMachines/vm
+	//   + /subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/az-samples-go/providers/Microsoft.Network/publicIPAddresses/ip
+	//   ~ /subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/az-samples-go/providers/Microsoft.Network/virtualNetworks/vnet
+	//       ~ properties.addressSpace.addressPrefixes[0]: "10.0.0.0/16" => "10.1.0.0/16"
+	//       + tags.env: "prod"
+	// 1 to delete
+}
@@ -10,28 +10,30 @@ import (
 	"fmt"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/services/preview/sql/mgmt/2015-05-01-preview/sql"
 	"github.com/Azure/go-autorest/autorest"
 	"github.com/Azure/go-autorest/autorest/to"
 )
 
-func getServersClient() sql.ServersClient {
-	serversClient := sql.NewServersClient(config.SubscriptionID())
+func getServersClient(ctx context.Context) sql.ServersClient {
+	serversClient := sql.NewServersClient(config.ScopeFrom(ctx).SubscriptionID)
 	a, _ := iam.GetResourceManagementAuthorizer()
 	serversClient.Authorizer = a
 	serversClient.AddToUserAgent(config.UserAgent())
+	serversClient.Sender = recording.Sender()
 	return serversClient
 }
 
 // CreateServer creates a new SQL Server
 func CreateServer(ctx context.Context, serverName, dbLogin, dbPassword string) (server sql.Server, err error) {
-	serversClient := getServersClient()
+	serversClient := getServersClient(ctx)
 	future, err := serversClient.CreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		serverName,
 		sql.Server{
-			Location: to.StringPtr(config.Location()),
+			Location: to.StringPtr(config.ScopeFrom(ctx).Location),
 			ServerProperties: &sql.ServerProperties{
 				AdministratorLogin:         to.StringPtr(dbLogin),
 				AdministratorLoginPassword: to.StringPtr(dbPassword),
@@ -52,24 +54,25 @@ func CreateServer(ctx context.Context, serverName, dbLogin, dbPassword string) (
 
 // Databases
 
-func getDbClient() sql.DatabasesClient {
-	dbClient := sql.NewDatabasesClient(config.SubscriptionID())
+func getDbClient(ctx context.Context) sql.DatabasesClient {
+	dbClient := sql.NewDatabasesClient(config.ScopeFrom(ctx).SubscriptionID)
 	a, _ := iam.GetResourceManagementAuthorizer()
 	dbClient.Authorizer = a
 	dbClient.AddToUserAgent(config.UserAgent())
+	dbClient.Sender = recording.Sender()
 	return dbClient
 }
 
 // CreateDB creates a new SQL Database on a given server
 func CreateDB(ctx context.Context, serverName, dbName string) (db sql.Database, err error) {
-	dbClient := getDbClient()
+	dbClient := getDbClient(ctx)
 	future, err := dbClient.CreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		serverName,
 		dbName,
 		sql.Database{
-			Location: to.StringPtr(config.Location()),
+			Location: to.StringPtr(config.ScopeFrom(ctx).Location),
 		})
 	if err != nil {
 		return db, fmt.Errorf("cannot create sql database: %v", err)
@@ -85,10 +88,10 @@ func CreateDB(ctx context.Context, serverName, dbName string) (db sql.Database,
 
 // DeleteDB deletes an existing database from a server
 func DeleteDB(ctx context.Context, serverName, dbName string) (autorest.Response, error) {
-	dbClient := getDbClient()
+	dbClient := getDbClient(ctx)
 	return dbClient.Delete(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		serverName,
 		dbName,
 	)
@@ -96,21 +99,22 @@ func DeleteDB(ctx context.Context, serverName, dbName string) (autorest.Response
 
 // Firewall rukes
 
-func getFwRulesClient() sql.FirewallRulesClient {
-	fwrClient := sql.NewFirewallRulesClient(config.SubscriptionID())
+func getFwRulesClient(ctx context.Context) sql.FirewallRulesClient {
+	fwrClient := sql.NewFirewallRulesClient(config.ScopeFrom(ctx).SubscriptionID)
 	a, _ := iam.GetResourceManagementAuthorizer()
 	fwrClient.Authorizer = a
 	fwrClient.AddToUserAgent(config.UserAgent())
+	fwrClient.Sender = recording.Sender()
 	return fwrClient
 }
 
 // CreateFirewallRules creates new firewall rules for a given server
 func CreateFirewallRules(ctx context.Context, serverName string) error {
-	fwrClient := getFwRulesClient()
+	fwrClient := getFwRulesClient(ctx)
 
 	_, err := fwrClient.CreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		serverName,
 		"unsafe open to world",
 		sql.FirewallRule{
@@ -126,7 +130,7 @@ func CreateFirewallRules(ctx context.Context, serverName string) error {
 
 	_, err = fwrClient.CreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		serverName,
 		"open to Azure internal",
 		sql.FirewallRule{
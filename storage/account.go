@@ -8,11 +8,11 @@ package storage
 import (
 	"context"
 	"fmt"
-	"log"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
-	"github.com/Azure/azure-sdk-for-go/services/storage/mgmt/2019-06-01/storage"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
+	"github.com/Azure/azure-sdk-for-go/services/storage/mgmt/2021-02-01/storage"
 	"github.com/Azure/go-autorest/autorest"
 	"github.com/Azure/go-autorest/autorest/to"
 )
@@ -23,35 +23,29 @@ var (
 	testAccountGroupName string
 )
 
-func getStorageAccountsClient() storage.AccountsClient {
-	storageAccountsClient := storage.NewAccountsClient(config.SubscriptionID())
+func getStorageAccountsClient(ctx context.Context) storage.AccountsClient {
+	storageAccountsClient := storage.NewAccountsClient(config.ScopeFrom(ctx).SubscriptionID)
 	auth, _ := iam.GetResourceManagementAuthorizer()
 	storageAccountsClient.Authorizer = auth
 	storageAccountsClient.AddToUserAgent(config.UserAgent())
+	storageAccountsClient.Sender = recording.Sender()
 	return storageAccountsClient
 }
 
-func getUsageClient() storage.UsagesClient {
-	usageClient := storage.NewUsagesClient(config.SubscriptionID())
+func getUsageClient(ctx context.Context) storage.UsagesClient {
+	usageClient := storage.NewUsagesClient(config.ScopeFrom(ctx).SubscriptionID)
 	auth, _ := iam.GetResourceManagementAuthorizer()
 	usageClient.Authorizer = auth
 	usageClient.AddToUserAgent(config.UserAgent())
+	usageClient.Sender = recording.Sender()
 	return usageClient
 }
 
-func getAccountPrimaryKey(ctx context.Context, accountName, accountGroupName string) string {
-	response, err := GetAccountKeys(ctx, accountName, accountGroupName)
-	if err != nil {
-		log.Fatalf("failed to list keys: %v", err)
-	}
-	return *(((*response.Keys)[0]).Value)
-}
-
 // CreateStorageAccount starts creation of a new storage account and waits for
 // the account to be created.
 func CreateStorageAccount(ctx context.Context, accountName, accountGroupName string) (storage.Account, error) {
 	var s storage.Account
-	storageAccountsClient := getStorageAccountsClient()
+	storageAccountsClient := getStorageAccountsClient(ctx)
 
 	result, err := storageAccountsClient.CheckNameAvailability(
 		ctx,
@@ -75,8 +69,8 @@ func CreateStorageAccount(ctx context.Context, accountName, accountGroupName str
 		accountName,
 		storage.AccountCreateParameters{
 			Sku: &storage.Sku{
-				Name: storage.StandardLRS},
-			Kind:                              storage.Storage,
+				Name: storage.SkuNameStandardLRS},
+			Kind:                              storage.KindStorageV2,
 			Location:                          to.StringPtr(config.DefaultLocation()),
 			AccountPropertiesCreateParameters: &storage.AccountPropertiesCreateParameters{},
 		})
@@ -95,20 +89,20 @@ func CreateStorageAccount(ctx context.Context, accountName, accountGroupName str
 
 // GetStorageAccount gets details on the specified storage account
 func GetStorageAccount(ctx context.Context, accountName, accountGroupName string) (storage.Account, error) {
-	storageAccountsClient := getStorageAccountsClient()
+	storageAccountsClient := getStorageAccountsClient(ctx)
 	return storageAccountsClient.GetProperties(ctx, accountGroupName, accountName, storage.AccountExpandBlobRestoreStatus)
 }
 
 // DeleteStorageAccount deletes an existing storate account
 func DeleteStorageAccount(ctx context.Context, accountName, accountGroupName string) (autorest.Response, error) {
-	storageAccountsClient := getStorageAccountsClient()
+	storageAccountsClient := getStorageAccountsClient(ctx)
 	return storageAccountsClient.Delete(ctx, accountGroupName, accountName)
 }
 
 // CheckAccountNameAvailability checks if the storage account name is available.
 // Storage account names must be unique across Azure and meet other requirements.
 func CheckAccountNameAvailability(ctx context.Context, accountName string) (storage.CheckNameAvailabilityResult, error) {
-	storageAccountsClient := getStorageAccountsClient()
+	storageAccountsClient := getStorageAccountsClient(ctx)
 	result, err := storageAccountsClient.CheckNameAvailability(
 		ctx,
 		storage.AccountCheckNameAvailabilityParameters{
@@ -119,21 +113,21 @@ func CheckAccountNameAvailability(ctx context.Context, accountName string) (stor
 }
 
 // ListAccountsByResourceGroup lists storage accounts by resource group.
-func ListAccountsByResourceGroup(ctx context.Context, groupName string) (storage.AccountListResult, error) {
-	storageAccountsClient := getStorageAccountsClient()
-	return storageAccountsClient.ListByResourceGroup(ctx, groupName)
+func ListAccountsByResourceGroup(ctx context.Context, groupName string) (storage.AccountListResultIterator, error) {
+	storageAccountsClient := getStorageAccountsClient(ctx)
+	return storageAccountsClient.ListByResourceGroupComplete(ctx, groupName)
 }
 
 // ListAccountsBySubscription lists storage accounts by subscription.
 func ListAccountsBySubscription(ctx context.Context) (storage.AccountListResultIterator, error) {
-	storageAccountsClient := getStorageAccountsClient()
+	storageAccountsClient := getStorageAccountsClient(ctx)
 	return storageAccountsClient.ListComplete(ctx)
 }
 
 // GetAccountKeys gets the storage account keys
 func GetAccountKeys(ctx context.Context, accountName, accountGroupName string) (storage.AccountListKeysResult, error) {
-	accountsClient := getStorageAccountsClient()
-	return accountsClient.ListKeys(ctx, accountGroupName, accountName, storage.Kerb)
+	accountsClient := getStorageAccountsClient(ctx)
+	return accountsClient.ListKeys(ctx, accountGroupName, accountName, storage.ListKeyExpandKerb)
 }
 
 // RegenerateAccountKey regenerates the selected storage account key. `key` can be 0 or 1.
@@ -143,7 +137,9 @@ func RegenerateAccountKey(ctx context.Context, accountName, accountGroupName str
 	if err != nil {
 		return list, err
 	}
-	accountsClient := getStorageAccountsClient()
+	// the blob helpers get the new key on their next request
+	defer keyCache.forget(ctx, accountName, accountGroupName)
+	accountsClient := getStorageAccountsClient(ctx)
 	return accountsClient.RegenerateKey(
 		ctx,
 		accountGroupName,
@@ -155,7 +151,7 @@ func RegenerateAccountKey(ctx context.Context, accountName, accountGroupName str
 
 // UpdateAccount updates a storage account by adding tags
 func UpdateAccount(ctx context.Context, accountName, accountGroupName string) (storage.Account, error) {
-	accountsClient := getStorageAccountsClient()
+	accountsClient := getStorageAccountsClient(ctx)
 	return accountsClient.Update(
 		ctx,
 		accountGroupName,
@@ -169,6 +165,6 @@ func UpdateAccount(ctx context.Context, accountName, accountGroupName string) (s
 
 // ListUsage gets the usage count and limits for the resources in the subscription based on location
 func ListUsage(ctx context.Context, location string) (storage.UsageListResult, error) {
-	usageClient := getUsageClient()
+	usageClient := getUsageClient(ctx)
 	return usageClient.ListByLocation(ctx, location)
 }
@@ -29,7 +29,11 @@ func Example_storageAccountOperations() {
 		util.LogAndPanic(err)
 	}
 
-	_, err = resources.RegisterProvider(ctx, "Microsoft.Storage")
+	requirements, err := resources.RequirementsFromPackage(".")
+	if err != nil {
+		util.LogAndPanic(err)
+	}
+	err = resources.Preflight(ctx, requirements, &resources.PreflightOptions{Register: true})
 	if err != nil {
 		util.LogAndPanic(err)
 	}
@@ -7,30 +7,104 @@ package storage
 
 import (
 	"context"
+	"fmt"
 	"io/ioutil"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
-	"github.com/Azure/azure-sdk-for-go/services/storage/mgmt/2019-06-01/storage"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
+	"github.com/Azure/azure-sdk-for-go/services/storage/mgmt/2021-02-01/storage"
 	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/Azure/go-autorest/autorest/to"
 )
 
-func getBlobClient() storage.BlobServicesClient {
-	blobClient := storage.NewBlobServicesClient(config.SubscriptionID())
+// soft deleted blobs and containers can be kept for 1 to 365 days
+const maxSoftDeleteDays = 365
+
+func getBlobClient(ctx context.Context) storage.BlobServicesClient {
+	blobClient := storage.NewBlobServicesClient(config.ScopeFrom(ctx).SubscriptionID)
 	auth, _ := iam.GetResourceManagementAuthorizer()
 	blobClient.Authorizer = auth
 	blobClient.AddToUserAgent(config.UserAgent())
+	blobClient.Sender = recording.Sender()
 	return blobClient
 }
 
-func getObjRepClient() storage.ObjectReplicationPoliciesClient {
-	objRepClient := storage.NewObjectReplicationPoliciesClient(config.SubscriptionID())
+func getBlobContainersClient(ctx context.Context) storage.BlobContainersClient {
+	containersClient := storage.NewBlobContainersClient(config.ScopeFrom(ctx).SubscriptionID)
+	auth, _ := iam.GetResourceManagementAuthorizer()
+	containersClient.Authorizer = auth
+	containersClient.AddToUserAgent(config.UserAgent())
+	containersClient.Sender = recording.Sender()
+	return containersClient
+}
+
+func getObjRepClient(ctx context.Context) storage.ObjectReplicationPoliciesClient {
+	objRepClient := storage.NewObjectReplicationPoliciesClient(config.ScopeFrom(ctx).SubscriptionID)
 	auth, _ := iam.GetResourceManagementAuthorizer()
 	objRepClient.Authorizer = auth
 	objRepClient.AddToUserAgent(config.UserAgent())
+	objRepClient.Sender = recording.Sender()
 	return objRepClient
 }
 
+// updateBlobServiceProperties applies update to the blob service properties
+// of an account.
+func updateBlobServiceProperties(ctx context.Context, accountName, accountGroupName string, update func(*storage.BlobServicePropertiesProperties)) (storage.BlobServiceProperties, error) {
+	blobClient := getBlobClient(ctx)
+	props, err := blobClient.GetServiceProperties(ctx, accountGroupName, accountName)
+	if err != nil {
+		return props, err
+	}
+	if props.BlobServicePropertiesProperties == nil {
+		props.BlobServicePropertiesProperties = &storage.BlobServicePropertiesProperties{}
+	}
+	update(props.BlobServicePropertiesProperties)
+	return blobClient.SetServiceProperties(ctx, accountGroupName, accountName, props)
+}
+
+// SetBlobVersioning turns blob versioning of an account on or off. While
+// it's on, overwriting or deleting a blob keeps its previous version.
+func SetBlobVersioning(ctx context.Context, accountName, accountGroupName string, enabled bool) (storage.BlobServiceProperties, error) {
+	return updateBlobServiceProperties(ctx, accountName, accountGroupName, func(p *storage.BlobServicePropertiesProperties) {
+		p.IsVersioningEnabled = to.BoolPtr(enabled)
+	})
+}
+
+// SetBlobSoftDelete keeps deleted blobs of an account for days, so they can
+// be undeleted, or stops keeping them if days is 0.
+func SetBlobSoftDelete(ctx context.Context, accountName, accountGroupName string, days int32) (storage.BlobServiceProperties, error) {
+	policy, err := deleteRetentionPolicy(days)
+	if err != nil {
+		return storage.BlobServiceProperties{}, err
+	}
+	return updateBlobServiceProperties(ctx, accountName, accountGroupName, func(p *storage.BlobServicePropertiesProperties) {
+		p.DeleteRetentionPolicy = policy
+	})
+}
+
+// SetContainerSoftDelete keeps deleted containers of an account for days,
+// or stops keeping them if days is 0.
+func SetContainerSoftDelete(ctx context.Context, accountName, accountGroupName string, days int32) (storage.BlobServiceProperties, error) {
+	policy, err := deleteRetentionPolicy(days)
+	if err != nil {
+		return storage.BlobServiceProperties{}, err
+	}
+	return updateBlobServiceProperties(ctx, accountName, accountGroupName, func(p *storage.BlobServicePropertiesProperties) {
+		p.ContainerDeleteRetentionPolicy = policy
+	})
+}
+
+func deleteRetentionPolicy(days int32) (*storage.DeleteRetentionPolicy, error) {
+	if days < 0 || days > maxSoftDeleteDays {
+		return nil, fmt.Errorf("soft delete retention must be 1 to %d days, or 0 to disable it, not %d", maxSoftDeleteDays, days)
+	}
+	if days == 0 {
+		return &storage.DeleteRetentionPolicy{Enabled: to.BoolPtr(false)}, nil
+	}
+	return &storage.DeleteRetentionPolicy{Enabled: to.BoolPtr(true), Days: to.Int32Ptr(days)}, nil
+}
+
 func getBlobURL(ctx context.Context, accountName, accountGroupName, containerName, blobName string) azblob.BlobURL {
 	container := getContainerURL(ctx, accountName, accountGroupName, containerName)
 	blob := container.NewBlobURL(blobName)
@@ -7,32 +7,35 @@ package storage
 
 import (
 	"context"
+	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/util"
-	"github.com/Azure/azure-sdk-for-go/services/storage/mgmt/2019-06-01/storage"
+	"github.com/Azure/azure-sdk-for-go/services/storage/mgmt/2021-02-01/storage"
 	"github.com/Azure/go-autorest/autorest/to"
 )
 
 func Example_blobSetServiceProperties() {
 	// retrieves the current blob services settings and modifies them
-	blobClient := getBlobClient()
-	props, err := blobClient.GetServiceProperties(context.Background(), testAccountGroupName, testAccountName)
+	ctx := context.Background()
+	blobClient := getBlobClient(ctx)
+	props, err := blobClient.GetServiceProperties(ctx, testAccountGroupName, testAccountName)
 	if err != nil {
 		util.LogAndPanic(err)
 	}
 	// enable blob versioning
 	props.IsVersioningEnabled = to.BoolPtr(true)
-	_, err = blobClient.SetServiceProperties(context.Background(), testAccountGroupName, testAccountName, props)
+	_, err = blobClient.SetServiceProperties(ctx, testAccountGroupName, testAccountName, props)
 	if err != nil {
 		util.LogAndPanic(err)
 	}
 }
 
 func Example_blobObjectReplicationPolicy() {
+	ctx := context.Background()
 	// create two object replication policies on a blob storage account.
 	// each rule applies to separate source/destination containers.
-	objRepClient := getObjRepClient()
-	policy, err := objRepClient.CreateOrUpdate(context.Background(), testAccountGroupName, testAccountName, "default", storage.ObjectReplicationPolicy{
+	objRepClient := getObjRepClient(ctx)
+	policy, err := objRepClient.CreateOrUpdate(ctx, testAccountGroupName, testAccountName, "default", storage.ObjectReplicationPolicy{
 		ObjectReplicationPolicyProperties: &storage.ObjectReplicationPolicyProperties{
 			SourceAccount:      to.StringPtr("source-account"),
 			DestinationAccount: to.StringPtr("destination-account"),
@@ -64,3 +67,110 @@ func Example_blobObjectReplicationPolicy() {
 	// display the ID of the policy that was created
 	util.PrintAndLog(*policy.PolicyID)
 }
+
+func Example_blobObjectReplication() {
+	ctx, cancel := context.WithTimeout(context.Background(), 1200*time.Second)
+	defer cancel()
+
+	// replicate a container of the test account to a second account
+	src := ReplicationAccount{Name: testAccountName, GroupName: testAccountGroupName}
+	dst := ReplicationAccount{Name: generateName("gosdksamplesdst"), GroupName: testAccountGroupName}
+	_, err := CreateStorageAccount(ctx, dst.Name, dst.GroupName)
+	if err != nil {
+		util.LogAndPanic(err)
+	}
+	defer DeleteStorageAccount(ctx, dst.Name, dst.GroupName)
+	util.PrintAndLog("created destination account")
+
+	srcContainer, dstContainer := generateName("test-orsrc"), generateName("test-ordst")
+	if _, err = CreateContainer(ctx, src.Name, src.GroupName, srcContainer); err != nil {
+		util.LogAndPanic(err)
+	}
+	if _, err = CreateContainer(ctx, dst.Name, dst.GroupName, dstContainer); err != nil {
+		util.LogAndPanic(err)
+	}
+	util.PrintAndLog("created containers")
+
+	r, err := SetupObjectReplication(ctx, src, dst, []ReplicationRule{{
+		SourceContainer:      srcContainer,
+		DestinationContainer: dstContainer,
+		// only replicate blobs with the prefix "foo"
+		Prefixes: []string{"foo"},
+	}})
+	if err != nil {
+		util.LogAndPanic(err)
+	}
+	util.PrintAndLog("set up object replication")
+
+	if _, err = CreateBlockBlob(ctx, src.Name, src.GroupName, srcContainer, "foo-blob"); err != nil {
+		util.LogAndPanic(err)
+	}
+	// replication is asynchronous, so the blob is usually still pending
+	report, err := GetReplicationReport(ctx, r)
+	if err != nil {
+		util.LogAndPanic(err)
+	}
+	if err = report.Err(); err != nil {
+		util.LogAndPanic(err)
+	}
+	util.PrintAndLog("got replication report")
+
+	if err = TeardownObjectReplication(ctx, r); err != nil {
+		util.LogAndPanic(err)
+	}
+	util.PrintAndLog("tore down object replication")
+
+	// Output:
+	// created destination account
+	// created containers
+	// set up object replication
+	// got replication report
+	// tore down object replication
+}
+
+func Example_blobDataProtection() {
+	ctx := context.Background()
+	_, err := SetBlobVersioning(ctx, testAccountName, testAccountGroupName, true)
+	if err != nil {
+		util.LogAndPanic(err)
+	}
+	util.PrintAndLog("enabled blob versioning")
+
+	_, err = SetBlobSoftDelete(ctx, testAccountName, testAccountGroupName, 7)
+	if err != nil {
+		util.LogAndPanic(err)
+	}
+	util.PrintAndLog("enabled blob soft delete")
+
+	// tier logs down as they age, and clean up old versions and snapshots
+	_, err = SetLifecyclePolicy(ctx, testAccountName, testAccountGroupName, []LifecycleRule{
+		{
+			Name:             "logs",
+			Prefixes:         []string{"logs/"},
+			CoolAfterDays:    to.IntPtr(30),
+			ArchiveAfterDays: to.IntPtr(90),
+			DeleteAfterDays:  to.IntPtr(365),
+		},
+		{
+			Name:                     "history",
+			DeleteSnapshotsAfterDays: to.IntPtr(30),
+			DeleteVersionsAfterDays:  to.IntPtr(30),
+		},
+	})
+	if err != nil {
+		util.LogAndPanic(err)
+	}
+	util.PrintAndLog("set lifecycle policy")
+
+	_, err = DeleteLifecyclePolicy(ctx, testAccountName, testAccountGroupName)
+	if err != nil {
+		util.LogAndPanic(err)
+	}
+	util.PrintAndLog("deleted lifecycle policy")
+
+	// Output:
+	// enabled blob versioning
+	// enabled blob soft delete
+	// set lifecycle policy
+	// deleted lifecycle policy
+}
@@ -7,8 +7,7 @@ package storage
 
 import (
 	"context"
-	"encoding/base64"
-	"strconv"
+	"crypto/md5"
 	"strings"
 
 	"github.com/Azure/azure-storage-blob-go/azblob"
@@ -38,11 +37,12 @@ func CreateBlockBlob(ctx context.Context, accountName, accountGroupName, contain
 	return b, err
 }
 
-// PutBlockOnBlob adds a block to a block blob. It does not commit the block.
+// PutBlockOnBlob adds a block to a block blob at position blockNum. It does
+// not commit the block.
 func PutBlockOnBlob(ctx context.Context, accountName, accountGroupName, containerName, blobName, message string, blockNum int) error {
 	b := getBlockBlobURL(ctx, accountName, accountGroupName, containerName, blobName)
-	id := base64.StdEncoding.EncodeToString([]byte(strconv.Itoa(blockNum)))
-	_, err := b.StageBlock(ctx, id, strings.NewReader(message), azblob.LeaseAccessConditions{}, nil)
+	sum := md5.Sum([]byte(message))
+	_, err := b.StageBlock(ctx, blockID(blockNum, sum[:]), strings.NewReader(message), azblob.LeaseAccessConditions{}, sum[:])
 	return err
 }
 
@@ -52,7 +52,8 @@ func GetUncommitedBlocks(ctx context.Context, accountName, accountGroupName, con
 	return b.GetBlockList(ctx, azblob.BlockListUncommitted, azblob.LeaseAccessConditions{})
 }
 
-// CommitBlocks commits the uncommitted blocks to the blob
+// CommitBlocks commits the uncommitted blocks to the blob, in the order of
+// the positions they were put at.
 func CommitBlocks(ctx context.Context, accountName, accountGroupName, containerName, blobName string) error {
 	b := getBlockBlobURL(ctx, accountName, accountGroupName, containerName, blobName)
 	list, err := GetUncommitedBlocks(ctx, accountName, accountGroupName, containerName, blobName)
@@ -64,6 +65,7 @@ func CommitBlocks(ctx context.Context, accountName, accountGroupName, containerN
 	for _, u := range list.UncommittedBlocks {
 		IDs = append(IDs, u.Name)
 	}
+	sortBlockIDs(IDs)
 
 	_, err = b.CommitBlockList(ctx, IDs, azblob.BlobHTTPHeaders{}, azblob.Metadata{}, azblob.BlobAccessConditions{})
 	return err
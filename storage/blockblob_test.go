@@ -6,8 +6,12 @@
 package storage
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/util"
@@ -77,3 +81,50 @@ func Example_blockBlobOperations() {
 	// downloaded blob
 	// HelloWorld!HelloGalaxy!
 }
+
+func Example_uploadAndDownloadFile() {
+	var accountName = testAccountName
+	var accountGroupName = testAccountGroupName
+	var containerName = generateName("test-transferc")
+	var blobName = generateName("test-transfer")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	defer cancel()
+
+	dir, err := ioutil.TempDir("", "transfer")
+	if err != nil {
+		util.LogAndPanic(err)
+	}
+	defer os.RemoveAll(dir)
+	src := filepath.Join(dir, "src.bin")
+	err = ioutil.WriteFile(src, bytes.Repeat([]byte("Azure-Samples "), 1<<20), 0600)
+	if err != nil {
+		util.LogAndPanic(err)
+	}
+
+	_, err = CreateContainer(ctx, accountName, accountGroupName, containerName)
+	if err != nil {
+		util.LogAndPanic(err)
+	}
+	util.PrintAndLog("created container")
+
+	// 14 MiB in blocks of 4 MiB
+	o := TransferOptions{BlockSize: 4 * 1024 * 1024}
+	err = UploadFile(ctx, accountName, accountGroupName, containerName, blobName, src, o)
+	if err != nil {
+		util.LogAndPanic(err)
+	}
+	util.PrintAndLog("uploaded file")
+
+	dst := filepath.Join(dir, "dst.bin")
+	err = DownloadToFile(ctx, accountName, accountGroupName, containerName, blobName, dst, o)
+	if err != nil {
+		util.LogAndPanic(err)
+	}
+	util.PrintAndLog("downloaded file")
+
+	// Output:
+	// created container
+	// uploaded file
+	// downloaded file
+}
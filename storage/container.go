@@ -10,7 +10,6 @@ import (
 	"fmt"
 	"net/url"
 
-	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
 	"github.com/Azure/azure-storage-blob-go/azblob"
 )
 
@@ -18,26 +17,24 @@ var (
 	blobFormatString = `https://%s.blob.core.windows.net`
 )
 
+// getContainerURL returns the URL of a container, authenticated as selected
+// by SetBlobAuth.
 func getContainerURL(ctx context.Context, accountName, accountGroupName, containerName string) azblob.ContainerURL {
-	key := getAccountPrimaryKey(ctx, accountName, accountGroupName)
-	c, _ := azblob.NewSharedKeyCredential(accountName, key)
-	p := azblob.NewPipeline(c, azblob.PipelineOptions{
-		Telemetry: azblob.TelemetryOptions{Value: config.UserAgent()},
-	})
 	u, _ := url.Parse(fmt.Sprintf(blobFormatString, accountName))
-	service := azblob.NewServiceURL(*u, p)
+	service := azblob.NewServiceURL(*u, newBlobPipeline(accountName, accountGroupName))
 	container := service.NewContainerURL(containerName)
 	return container
 }
 
-// CreateContainer creates a new container with the specified name in the specified account
+// CreateContainer creates a new private container with the specified name in
+// the specified account. Share its blobs with SAS tokens, see ServiceSAS.
 func CreateContainer(ctx context.Context, accountName, accountGroupName, containerName string) (azblob.ContainerURL, error) {
 	c := getContainerURL(ctx, accountName, accountGroupName, containerName)
 
 	_, err := c.Create(
 		ctx,
 		azblob.Metadata{},
-		azblob.PublicAccessContainer)
+		azblob.PublicAccessNone)
 	return c, err
 }
 
@@ -57,7 +54,8 @@ func DeleteContainer(ctx context.Context, accountName, accountGroupName, contain
 	return err
 }
 
-// ListBlobs lists blobs on the specified container
+// ListBlobs lists the first page of blobs, and their snapshots, in the
+// specified container. ListAllBlobs follows every page.
 func ListBlobs(ctx context.Context, accountName, accountGroupName, containerName string) (*azblob.ListBlobsFlatSegmentResponse, error) {
 	c := getContainerURL(ctx, accountName, accountGroupName, containerName)
 	return c.ListBlobsFlatSegment(
@@ -69,3 +67,19 @@ func ListBlobs(ctx context.Context, accountName, accountGroupName, containerName
 			},
 		})
 }
+
+// ListAllBlobs lists the blobs in the specified container whose names start
+// with prefix, following every page of the listing.
+func ListAllBlobs(ctx context.Context, accountName, accountGroupName, containerName, prefix string) ([]azblob.BlobItem, error) {
+	c := getContainerURL(ctx, accountName, accountGroupName, containerName)
+	var blobs []azblob.BlobItem
+	for marker := (azblob.Marker{}); marker.NotDone(); {
+		resp, err := c.ListBlobsFlatSegment(ctx, marker, azblob.ListBlobsSegmentOptions{Prefix: prefix})
+		if err != nil {
+			return nil, err
+		}
+		blobs = append(blobs, resp.Segment.BlobItems...)
+		marker = resp.NextMarker
+	}
+	return blobs, nil
+}
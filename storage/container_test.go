@@ -8,9 +8,11 @@ package storage
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/util"
+	"github.com/Azure/azure-storage-blob-go/azblob"
 )
 
 func Example_containerAndBlobs() {
@@ -50,3 +52,93 @@ func Example_containerAndBlobs() {
 	// created test-blob2
 	// listed 3 blobs
 }
+
+func Example_containerSAS() {
+	var accountName = testAccountName
+	var accountGroupName = testAccountGroupName
+	var containerName = generateName("test-sasc")
+	var err error
+
+	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	defer cancel()
+
+	_, err = CreateContainer(ctx, accountName, accountGroupName, containerName)
+	if err != nil {
+		util.LogAndPanic(err)
+	}
+	util.PrintAndLog("created private container")
+
+	sasURL, err := ServiceSAS(ctx, accountName, accountGroupName, containerName, "", SASOptions{
+		Permissions: "rcwl",
+		Expiry:      time.Now().Add(time.Hour),
+	})
+	if err != nil {
+		util.LogAndPanic(err)
+	}
+	util.PrintAndLog("issued container SAS")
+
+	containerURL, err := ContainerFromSAS(sasURL)
+	if err != nil {
+		util.LogAndPanic(err)
+	}
+	_, err = containerURL.NewBlockBlobURL("test-blob").Upload(
+		ctx, strings.NewReader("shared with a SAS"), azblob.BlobHTTPHeaders{}, azblob.Metadata{}, azblob.BlobAccessConditions{})
+	if err != nil {
+		util.LogAndPanic(err)
+	}
+	util.PrintAndLog("uploaded blob with SAS")
+
+	// Output:
+	// created private container
+	// issued container SAS
+	// uploaded blob with SAS
+}
+
+func Example_containerImmutability() {
+	var accountName = testAccountName
+	var accountGroupName = testAccountGroupName
+	var containerName = generateName("test-worm")
+	var err error
+
+	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
+	defer cancel()
+
+	_, err = CreateContainer(ctx, accountName, accountGroupName, containerName)
+	if err != nil {
+		util.LogAndPanic(err)
+	}
+	util.PrintAndLog("created container")
+
+	// unlocked, so the sample can remove it again; LockContainerRetention
+	// would make it permanent
+	_, err = SetContainerRetention(ctx, accountName, accountGroupName, containerName, 1, false)
+	if err != nil {
+		util.LogAndPanic(err)
+	}
+	util.PrintAndLog("set retention policy")
+
+	_, err = SetLegalHold(ctx, accountName, accountGroupName, containerName, []string{"case123"})
+	if err != nil {
+		util.LogAndPanic(err)
+	}
+	util.PrintAndLog("set legal hold")
+
+	_, err = ClearLegalHold(ctx, accountName, accountGroupName, containerName, []string{"case123"})
+	if err != nil {
+		util.LogAndPanic(err)
+	}
+	util.PrintAndLog("cleared legal hold")
+
+	_, err = DeleteContainerRetention(ctx, accountName, accountGroupName, containerName)
+	if err != nil {
+		util.LogAndPanic(err)
+	}
+	util.PrintAndLog("deleted retention policy")
+
+	// Output:
+	// created container
+	// set retention policy
+	// set legal hold
+	// cleared legal hold
+	// deleted retention policy
+}
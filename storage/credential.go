@@ -0,0 +1,134 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure/azure-pipeline-go/pipeline"
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/Azure/go-autorest/autorest"
+)
+
+// BlobAuth selects how the blob helpers authenticate to storage accounts.
+type BlobAuth int
+
+const (
+	// BlobAuthSharedKey signs requests with the account's primary key. The
+	// key is listed with the management API once per account and cached.
+	BlobAuthSharedKey BlobAuth = iota
+	// BlobAuthAAD sends Azure AD bearer tokens of the configured identity,
+	// see `iam.GetStorageAuthorizer`. No account key is used, but the
+	// identity needs a data role such as Storage Blob Data Contributor.
+	BlobAuthAAD
+)
+
+var blobAuth = BlobAuthSharedKey
+
+// SetBlobAuth selects how the blob helpers authenticate from now on.
+func SetBlobAuth(auth BlobAuth) {
+	blobAuth = auth
+}
+
+// accountKeys caches the primary keys of storage accounts.
+type accountKeys struct {
+	mu   sync.Mutex
+	keys map[string]string
+	// list returns the primary key of an account.
+	list func(ctx context.Context, accountName, accountGroupName string) (string, error)
+}
+
+var keyCache = &accountKeys{keys: map[string]string{}, list: listPrimaryKey}
+
+func listPrimaryKey(ctx context.Context, accountName, accountGroupName string) (string, error) {
+	response, err := GetAccountKeys(ctx, accountName, accountGroupName)
+	if err != nil {
+		return "", fmt.Errorf("failed to list keys of storage account %s: %v", accountName, err)
+	}
+	if response.Keys == nil || len(*response.Keys) == 0 || (*response.Keys)[0].Value == nil {
+		return "", fmt.Errorf("storage account %s has no keys", accountName)
+	}
+	return *(*response.Keys)[0].Value, nil
+}
+
+func cacheKey(ctx context.Context, accountName, accountGroupName string) string {
+	return config.ScopeFrom(ctx).SubscriptionID + "/" + accountGroupName + "/" + accountName
+}
+
+// get returns the cached primary key of an account, listing it on first use.
+func (c *accountKeys) get(ctx context.Context, accountName, accountGroupName string) (string, error) {
+	k := cacheKey(ctx, accountName, accountGroupName)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if key, ok := c.keys[k]; ok {
+		return key, nil
+	}
+	key, err := c.list(ctx, accountName, accountGroupName)
+	if err != nil {
+		return "", err
+	}
+	c.keys[k] = key
+	return key, nil
+}
+
+// forget drops the cached key of an account, e.g. after it's regenerated.
+func (c *accountKeys) forget(ctx context.Context, accountName, accountGroupName string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.keys, cacheKey(ctx, accountName, accountGroupName))
+}
+
+// sharedKeyCredential returns a credential with the account's primary key.
+func sharedKeyCredential(ctx context.Context, accountName, accountGroupName string) (*azblob.SharedKeyCredential, error) {
+	key, err := keyCache.get(ctx, accountName, accountGroupName)
+	if err != nil {
+		return nil, err
+	}
+	return azblob.NewSharedKeyCredential(accountName, key)
+}
+
+// newBlobPipeline returns the pipeline of the blob helpers. It's the one of
+// `azblob.NewPipeline`, except that the credential is only resolved when a
+// request is sent, so failures to get it are returned by the operation.
+func newBlobPipeline(accountName, accountGroupName string) pipeline.Pipeline {
+	return pipeline.NewPipeline([]pipeline.Factory{
+		azblob.NewTelemetryPolicyFactory(azblob.TelemetryOptions{Value: config.UserAgent()}),
+		azblob.NewUniqueRequestIDPolicyFactory(),
+		azblob.NewRetryPolicyFactory(azblob.RetryOptions{}),
+		// the credential goes close to the wire to sign what the others set
+		credentialPolicyFactory(accountName, accountGroupName),
+		pipeline.MethodFactoryMarker(),
+		azblob.NewRequestLogPolicyFactory(azblob.RequestLogOptions{}),
+	}, pipeline.Options{})
+}
+
+func credentialPolicyFactory(accountName, accountGroupName string) pipeline.Factory {
+	return pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
+		return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+			if blobAuth == BlobAuthAAD {
+				a, err := iam.GetStorageAuthorizer()
+				if err != nil {
+					return nil, err
+				}
+				r, err := autorest.Prepare(request.Request, a.WithAuthorization())
+				if err != nil {
+					return nil, err
+				}
+				return next.Do(ctx, pipeline.Request{Request: r})
+			}
+
+			c, err := sharedKeyCredential(ctx, accountName, accountGroupName)
+			if err != nil {
+				return nil, err
+			}
+			return c.New(next, po).Do(ctx, request)
+		}
+	})
+}
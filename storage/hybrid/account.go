@@ -3,11 +3,10 @@ package storage
 import (
 	"context"
 	"fmt"
-	"log"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
-	"github.com/Azure/azure-sdk-for-go/profiles/2017-03-09/storage/mgmt/storage"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/hybrid"
+	"github.com/Azure/azure-sdk-for-go/profiles/2019-03-01/storage/mgmt/storage"
 	"github.com/Azure/go-autorest/autorest"
 	"github.com/Azure/go-autorest/autorest/to"
 )
@@ -16,21 +15,15 @@ const (
 	errorPrefix = "Cannot create storage account, reason: %v"
 )
 
-func getStorageAccountsClient(activeDirectoryEndpoint, tokenAudience string) storage.AccountsClient {
-	token, err := iam.GetResourceManagementTokenHybrid(activeDirectoryEndpoint, tokenAudience)
-	if err != nil {
-		log.Fatal(fmt.Sprintf(errorPrefix, fmt.Sprintf("Cannot generate token. Error details: %v.", err)))
-	}
-	storageAccountsClient := storage.NewAccountsClientWithBaseURI(config.Environment().ResourceManagerEndpoint, config.SubscriptionID())
-	storageAccountsClient.Authorizer = autorest.NewBearerAuthorizer(token)
-	storageAccountsClient.AddToUserAgent(config.UserAgent())
+func getStorageAccountsClient(ctx context.Context) storage.AccountsClient {
+	storageAccountsClient := storage.NewAccountsClientWithBaseURI(hybrid.Endpoint(ctx))
+	hybrid.Configure(&storageAccountsClient.Client)
 	return storageAccountsClient
 }
 
 // CreateStorageAccount creates a new storage account.
 func CreateStorageAccount(ctx context.Context, accountName string) (s storage.Account, err error) {
-	environment := config.Environment()
-	storageAccountsClient := getStorageAccountsClient(environment.ActiveDirectoryEndpoint, environment.TokenAudience)
+	storageAccountsClient := getStorageAccountsClient(ctx)
 	result, err := storageAccountsClient.CheckNameAvailability(
 		ctx,
 		storage.AccountCheckNameAvailabilityParameters{
@@ -45,13 +38,13 @@ func CreateStorageAccount(ctx context.Context, accountName string) (s storage.Ac
 	}
 	future, err := storageAccountsClient.Create(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		accountName,
 		storage.AccountCreateParameters{
 			Sku: &storage.Sku{
 				Name: storage.StandardLRS},
-			Kind:     storage.Storage,
-			Location: to.StringPtr(config.Location()),
+			Kind:                              storage.Storage,
+			Location:                          to.StringPtr(config.ScopeFrom(ctx).Location),
 			AccountPropertiesCreateParameters: &storage.AccountPropertiesCreateParameters{},
 		})
 	if err != nil {
@@ -63,3 +56,39 @@ func CreateStorageAccount(ctx context.Context, accountName string) (s storage.Ac
 	}
 	return future.Result(storageAccountsClient)
 }
+
+// GetStorageAccount gets the properties of a storage account
+func GetStorageAccount(ctx context.Context, accountName string) (storage.Account, error) {
+	storageAccountsClient := getStorageAccountsClient(ctx)
+	return storageAccountsClient.GetProperties(ctx, config.ScopeFrom(ctx).GroupName, accountName)
+}
+
+// ListStorageAccounts lists the storage accounts in the resource group
+func ListStorageAccounts(ctx context.Context) (storage.AccountListResult, error) {
+	storageAccountsClient := getStorageAccountsClient(ctx)
+	return storageAccountsClient.ListByResourceGroup(ctx, config.ScopeFrom(ctx).GroupName)
+}
+
+// UpdateStorageAccountTags replaces the tags of a storage account
+func UpdateStorageAccountTags(ctx context.Context, accountName string, tags map[string]*string) (storage.Account, error) {
+	storageAccountsClient := getStorageAccountsClient(ctx)
+	return storageAccountsClient.Update(
+		ctx,
+		config.ScopeFrom(ctx).GroupName,
+		accountName,
+		storage.AccountUpdateParameters{
+			Tags: tags,
+		})
+}
+
+// GetAccountKeys gets the access keys of a storage account
+func GetAccountKeys(ctx context.Context, accountName string) (storage.AccountListKeysResult, error) {
+	storageAccountsClient := getStorageAccountsClient(ctx)
+	return storageAccountsClient.ListKeys(ctx, config.ScopeFrom(ctx).GroupName, accountName)
+}
+
+// DeleteStorageAccount deletes a storage account
+func DeleteStorageAccount(ctx context.Context, accountName string) (autorest.Response, error) {
+	storageAccountsClient := getStorageAccountsClient(ctx)
+	return storageAccountsClient.Delete(ctx, config.ScopeFrom(ctx).GroupName, accountName)
+}
@@ -0,0 +1,154 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/services/storage/mgmt/2021-02-01/storage"
+	"github.com/Azure/go-autorest/autorest/to"
+)
+
+// time-based retention can be 1 to 146000 days, about 400 years
+const maxRetentionDays = 146000
+
+func retentionPolicy(days int32, allowProtectedAppendWrites bool) (*storage.ImmutabilityPolicy, error) {
+	if days < 1 || days > maxRetentionDays {
+		return nil, fmt.Errorf("retention must be 1 to %d days, not %d", maxRetentionDays, days)
+	}
+	return &storage.ImmutabilityPolicy{
+		ImmutabilityPolicyProperty: &storage.ImmutabilityPolicyProperty{
+			ImmutabilityPeriodSinceCreationInDays: to.Int32Ptr(days),
+			AllowProtectedAppendWrites:            to.BoolPtr(allowProtectedAppendWrites),
+		},
+	}, nil
+}
+
+// SetContainerRetention sets an unlocked time-based retention policy on a
+// container: its blobs can't be modified or deleted until they're days
+// old. allowProtectedAppendWrites still allows appending to append blobs.
+// While unlocked the policy can be changed or deleted, which makes it fit
+// for testing only; lock it with LockContainerRetention.
+func SetContainerRetention(ctx context.Context, accountName, accountGroupName, containerName string, days int32, allowProtectedAppendWrites bool) (storage.ImmutabilityPolicy, error) {
+	policy, err := retentionPolicy(days, allowProtectedAppendWrites)
+	if err != nil {
+		return storage.ImmutabilityPolicy{}, err
+	}
+	containersClient := getBlobContainersClient(ctx)
+	return containersClient.CreateOrUpdateImmutabilityPolicy(ctx, accountGroupName, accountName, containerName, policy, "")
+}
+
+// GetContainerRetention gets the time-based retention policy of a
+// container.
+func GetContainerRetention(ctx context.Context, accountName, accountGroupName, containerName string) (storage.ImmutabilityPolicy, error) {
+	containersClient := getBlobContainersClient(ctx)
+	return containersClient.GetImmutabilityPolicy(ctx, accountGroupName, accountName, containerName, "")
+}
+
+// currentRetentionEtag returns the etag of the retention policy of a
+// container, needed to change it.
+func currentRetentionEtag(ctx context.Context, accountName, accountGroupName, containerName string) (string, error) {
+	policy, err := GetContainerRetention(ctx, accountName, accountGroupName, containerName)
+	if err != nil {
+		return "", err
+	}
+	if policy.Etag == nil {
+		return "", fmt.Errorf("retention policy of container %s has no etag", containerName)
+	}
+	return *policy.Etag, nil
+}
+
+// LockContainerRetention locks the retention policy of a container. This
+// can't be undone: the policy can then only be extended, and the container
+// and its account can't be deleted while it holds blobs under retention.
+func LockContainerRetention(ctx context.Context, accountName, accountGroupName, containerName string) (storage.ImmutabilityPolicy, error) {
+	etag, err := currentRetentionEtag(ctx, accountName, accountGroupName, containerName)
+	if err != nil {
+		return storage.ImmutabilityPolicy{}, err
+	}
+	containersClient := getBlobContainersClient(ctx)
+	return containersClient.LockImmutabilityPolicy(ctx, accountGroupName, accountName, containerName, etag)
+}
+
+// ExtendContainerRetention extends the locked retention policy of a
+// container to days.
+func ExtendContainerRetention(ctx context.Context, accountName, accountGroupName, containerName string, days int32, allowProtectedAppendWrites bool) (storage.ImmutabilityPolicy, error) {
+	policy, err := retentionPolicy(days, allowProtectedAppendWrites)
+	if err != nil {
+		return storage.ImmutabilityPolicy{}, err
+	}
+	current, err := GetContainerRetention(ctx, accountName, accountGroupName, containerName)
+	if err != nil {
+		return storage.ImmutabilityPolicy{}, err
+	}
+	if current.ImmutabilityPolicyProperty != nil && current.ImmutabilityPeriodSinceCreationInDays != nil &&
+		*current.ImmutabilityPeriodSinceCreationInDays > days {
+		return storage.ImmutabilityPolicy{}, fmt.Errorf("retention of container %s is %d days, it can't be shortened to %d",
+			containerName, *current.ImmutabilityPeriodSinceCreationInDays, days)
+	}
+	if current.Etag == nil {
+		return storage.ImmutabilityPolicy{}, fmt.Errorf("retention policy of container %s has no etag", containerName)
+	}
+	containersClient := getBlobContainersClient(ctx)
+	return containersClient.ExtendImmutabilityPolicy(ctx, accountGroupName, accountName, containerName, *current.Etag, policy)
+}
+
+// DeleteContainerRetention deletes the retention policy of a container,
+// which must be unlocked.
+func DeleteContainerRetention(ctx context.Context, accountName, accountGroupName, containerName string) (storage.ImmutabilityPolicy, error) {
+	etag, err := currentRetentionEtag(ctx, accountName, accountGroupName, containerName)
+	if err != nil {
+		return storage.ImmutabilityPolicy{}, err
+	}
+	containersClient := getBlobContainersClient(ctx)
+	return containersClient.DeleteImmutabilityPolicy(ctx, accountGroupName, accountName, containerName, etag)
+}
+
+// legal hold tags are 3 to 23 letters and digits
+const minLegalHoldTagLength, maxLegalHoldTagLength = 3, 23
+
+func validateLegalHoldTags(tags []string) error {
+	for _, tag := range tags {
+		if len(tag) < minLegalHoldTagLength || len(tag) > maxLegalHoldTagLength {
+			return fmt.Errorf("legal hold tag %q must be %d to %d characters", tag, minLegalHoldTagLength, maxLegalHoldTagLength)
+		}
+		for _, c := range tag {
+			if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9') {
+				return fmt.Errorf("legal hold tag %q must be letters and digits only", tag)
+			}
+		}
+	}
+	return nil
+}
+
+// SetLegalHold puts a legal hold with tags on a container: its blobs can't
+// be modified or deleted until every tag is cleared. Tags are letters and
+// digits, 3 to 23 characters.
+func SetLegalHold(ctx context.Context, accountName, accountGroupName, containerName string, tags []string) (storage.LegalHold, error) {
+	if len(tags) == 0 {
+		return storage.LegalHold{}, errors.New("legal hold needs at least one tag")
+	}
+	if err := validateLegalHoldTags(tags); err != nil {
+		return storage.LegalHold{}, err
+	}
+	containersClient := getBlobContainersClient(ctx)
+	return containersClient.SetLegalHold(ctx, accountGroupName, accountName, containerName, storage.LegalHold{Tags: &tags})
+}
+
+// ClearLegalHold clears tags of the legal hold of a container. The hold is
+// lifted once no tags are left.
+func ClearLegalHold(ctx context.Context, accountName, accountGroupName, containerName string, tags []string) (storage.LegalHold, error) {
+	if len(tags) == 0 {
+		return storage.LegalHold{}, errors.New("specify the legal hold tags to clear")
+	}
+	if err := validateLegalHoldTags(tags); err != nil {
+		return storage.LegalHold{}, err
+	}
+	containersClient := getBlobContainersClient(ctx)
+	return containersClient.ClearLegalHold(ctx, accountGroupName, accountName, containerName, storage.LegalHold{Tags: &tags})
+}
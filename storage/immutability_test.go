@@ -0,0 +1,27 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package storage
+
+import "testing"
+
+func TestValidateLegalHoldTags(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		tags []string
+		ok   bool
+	}{
+		{"valid", []string{"case123", "Audit2021", "abc", "abcdefghijklmnopqrstuvw"}, true},
+		{"too short", []string{"ab"}, false},
+		{"too long", []string{"abcdefghijklmnopqrstuvwx"}, false},
+		{"punctuation", []string{"case-123"}, false},
+		{"non-ASCII", []string{"caseé12"}, false},
+		{"one bad tag", []string{"case123", "x"}, false},
+	} {
+		if err := validateLegalHoldTags(test.tags); (err == nil) != test.ok {
+			t.Errorf("%s: got error %v", test.name, err)
+		}
+	}
+}
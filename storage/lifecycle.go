@@ -0,0 +1,420 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
+	"github.com/Azure/azure-sdk-for-go/services/storage/mgmt/2021-02-01/storage"
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
+)
+
+const (
+	// an account has a single management policy, named default
+	managementPolicyName = "default"
+	maxLifecycleRules    = 100
+	maxLifecycleTags     = 10
+	blobTypeBlock        = "blockBlob"
+	blobTypeAppend       = "appendBlob"
+)
+
+func getManagementPoliciesClient(ctx context.Context) storage.ManagementPoliciesClient {
+	policiesClient := storage.NewManagementPoliciesClient(config.ScopeFrom(ctx).SubscriptionID)
+	auth, _ := iam.GetResourceManagementAuthorizer()
+	policiesClient.Authorizer = auth
+	policiesClient.AddToUserAgent(config.UserAgent())
+	policiesClient.Sender = recording.Sender()
+	return policiesClient
+}
+
+// LifecycleRule is a rule of a storage account's management policy. Blobs
+// matching all of its filters are moved to cooler tiers and deleted when
+// they get older than the given days. Day counts are nil for actions the
+// rule doesn't take; 0 acts on blobs as soon as the policy runs, daily.
+type LifecycleRule struct {
+	// Name is letters and digits, unique in the policy.
+	Name string
+	// Disabled rules are kept in the policy but not run.
+	Disabled bool
+
+	// Prefixes are "container/blob prefix" paths of the blobs the rule
+	// applies to, all blobs if empty.
+	Prefixes []string
+	// BlobTypes are blockBlob and appendBlob; blockBlob if empty.
+	BlobTypes []string
+	// Tags are blob index tags the blobs must all have.
+	Tags map[string]string
+
+	// CoolAfterDays, ArchiveAfterDays and DeleteAfterDays act on current
+	// blobs that weren't modified for longer.
+	CoolAfterDays    *int
+	ArchiveAfterDays *int
+	DeleteAfterDays  *int
+	// DeleteSnapshotsAfterDays deletes snapshots older than that.
+	DeleteSnapshotsAfterDays *int
+	// DeleteVersionsAfterDays deletes previous versions older than that,
+	// see SetBlobVersioning.
+	DeleteVersionsAfterDays *int
+}
+
+func (r LifecycleRule) blobTypes() []string {
+	if len(r.BlobTypes) == 0 {
+		return []string{blobTypeBlock}
+	}
+	return r.BlobTypes
+}
+
+// Validate checks r is accepted by the service and that each of its actions
+// can run: as the cheapest action due wins, tiering a blob after it's due to
+// be deleted or archived never happens.
+func (r LifecycleRule) Validate() error {
+	if r.Name == "" {
+		return errors.New("lifecycle rule has no name")
+	}
+	for _, c := range r.Name {
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9') {
+			return fmt.Errorf("lifecycle rule %s: name must be letters and digits only", r.Name)
+		}
+	}
+	fail := func(format string, a ...interface{}) error {
+		return fmt.Errorf("lifecycle rule %s: %s", r.Name, fmt.Sprintf(format, a...))
+	}
+
+	if r.CoolAfterDays == nil && r.ArchiveAfterDays == nil && r.DeleteAfterDays == nil &&
+		r.DeleteSnapshotsAfterDays == nil && r.DeleteVersionsAfterDays == nil {
+		return fail("has no actions")
+	}
+	for name, days := range map[string]*int{
+		"cool":             r.CoolAfterDays,
+		"archive":          r.ArchiveAfterDays,
+		"delete":           r.DeleteAfterDays,
+		"delete snapshots": r.DeleteSnapshotsAfterDays,
+		"delete versions":  r.DeleteVersionsAfterDays,
+	} {
+		if days != nil && *days < 0 {
+			return fail("%s after %d days", name, *days)
+		}
+	}
+	if r.CoolAfterDays != nil && r.ArchiveAfterDays != nil && *r.CoolAfterDays >= *r.ArchiveAfterDays {
+		return fail("blobs are archived after %d days, so never cooled after %d", *r.ArchiveAfterDays, *r.CoolAfterDays)
+	}
+	if r.DeleteAfterDays != nil {
+		for name, days := range map[string]*int{"cooled": r.CoolAfterDays, "archived": r.ArchiveAfterDays} {
+			if days != nil && *days >= *r.DeleteAfterDays {
+				return fail("blobs are deleted after %d days, so never %s after %d", *r.DeleteAfterDays, name, *days)
+			}
+		}
+	}
+
+	for _, t := range r.blobTypes() {
+		switch t {
+		case blobTypeBlock:
+		case blobTypeAppend:
+			if r.CoolAfterDays != nil || r.ArchiveAfterDays != nil {
+				return fail("append blobs can only be deleted, not tiered")
+			}
+		default:
+			return fail("unknown blob type %q", t)
+		}
+	}
+	for _, p := range r.Prefixes {
+		if p == "" || strings.HasPrefix(p, "/") {
+			return fail("prefix %q must start with a container name", p)
+		}
+	}
+	if len(r.Tags) > maxLifecycleTags {
+		return fail("has %d tag filters, at most %d are allowed", len(r.Tags), maxLifecycleTags)
+	}
+	return nil
+}
+
+// LifecyclePolicy validates rules and returns the management policy made of
+// them.
+func LifecyclePolicy(rules []LifecycleRule) (storage.ManagementPolicy, error) {
+	if len(rules) == 0 {
+		return storage.ManagementPolicy{}, errors.New("lifecycle policy has no rules")
+	}
+	if len(rules) > maxLifecycleRules {
+		return storage.ManagementPolicy{}, fmt.Errorf("lifecycle policy has %d rules, at most %d are allowed", len(rules), maxLifecycleRules)
+	}
+	names := map[string]bool{}
+	var policyRules []storage.ManagementPolicyRule
+	for _, r := range rules {
+		if err := r.Validate(); err != nil {
+			return storage.ManagementPolicy{}, err
+		}
+		if names[r.Name] {
+			return storage.ManagementPolicy{}, fmt.Errorf("lifecycle rule %s is defined twice", r.Name)
+		}
+		names[r.Name] = true
+		policyRules = append(policyRules, r.managementPolicyRule())
+	}
+	return storage.ManagementPolicy{
+		ManagementPolicyProperties: &storage.ManagementPolicyProperties{
+			Policy: &storage.ManagementPolicySchema{Rules: &policyRules},
+		},
+	}, nil
+}
+
+func (r LifecycleRule) managementPolicyRule() storage.ManagementPolicyRule {
+	filters := &storage.ManagementPolicyFilter{
+		BlobTypes: to.StringSlicePtr(r.blobTypes()),
+	}
+	if len(r.Prefixes) > 0 {
+		filters.PrefixMatch = to.StringSlicePtr(r.Prefixes)
+	}
+	if len(r.Tags) > 0 {
+		var tags []storage.TagFilter
+		for _, name := range sortedKeys(r.Tags) {
+			tags = append(tags, storage.TagFilter{
+				Name:  to.StringPtr(name),
+				Op:    to.StringPtr("=="),
+				Value: to.StringPtr(r.Tags[name]),
+			})
+		}
+		filters.BlobIndexMatch = &tags
+	}
+
+	actions := &storage.ManagementPolicyAction{}
+	if r.CoolAfterDays != nil || r.ArchiveAfterDays != nil || r.DeleteAfterDays != nil {
+		actions.BaseBlob = &storage.ManagementPolicyBaseBlob{
+			TierToCool:    afterModification(r.CoolAfterDays),
+			TierToArchive: afterModification(r.ArchiveAfterDays),
+			Delete:        afterModification(r.DeleteAfterDays),
+		}
+	}
+	if r.DeleteSnapshotsAfterDays != nil {
+		actions.Snapshot = &storage.ManagementPolicySnapShot{Delete: afterCreation(r.DeleteSnapshotsAfterDays)}
+	}
+	if r.DeleteVersionsAfterDays != nil {
+		actions.Version = &storage.ManagementPolicyVersion{Delete: afterCreation(r.DeleteVersionsAfterDays)}
+	}
+
+	return storage.ManagementPolicyRule{
+		Name:    to.StringPtr(r.Name),
+		Enabled: to.BoolPtr(!r.Disabled),
+		Type:    to.StringPtr("Lifecycle"),
+		Definition: &storage.ManagementPolicyDefinition{
+			Actions: actions,
+			Filters: filters,
+		},
+	}
+}
+
+func afterModification(days *int) *storage.DateAfterModification {
+	if days == nil {
+		return nil
+	}
+	return &storage.DateAfterModification{DaysAfterModificationGreaterThan: to.Float64Ptr(float64(*days))}
+}
+
+func afterCreation(days *int) *storage.DateAfterCreation {
+	if days == nil {
+		return nil
+	}
+	return &storage.DateAfterCreation{DaysAfterCreationGreaterThan: to.Float64Ptr(float64(*days))}
+}
+
+func sortedKeys(m map[string]string) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// SetLifecyclePolicy replaces the management policy of a storage account
+// with one made of rules. Check what it would do first with
+// EvaluateLifecycle.
+func SetLifecyclePolicy(ctx context.Context, accountName, accountGroupName string, rules []LifecycleRule) (storage.ManagementPolicy, error) {
+	policy, err := LifecyclePolicy(rules)
+	if err != nil {
+		return policy, err
+	}
+	policiesClient := getManagementPoliciesClient(ctx)
+	return policiesClient.CreateOrUpdate(ctx, accountGroupName, accountName, policy)
+}
+
+// GetLifecyclePolicy gets the management policy of a storage account.
+func GetLifecyclePolicy(ctx context.Context, accountName, accountGroupName string) (storage.ManagementPolicy, error) {
+	policiesClient := getManagementPoliciesClient(ctx)
+	return policiesClient.Get(ctx, accountGroupName, accountName)
+}
+
+// DeleteLifecyclePolicy deletes the management policy of a storage account.
+func DeleteLifecyclePolicy(ctx context.Context, accountName, accountGroupName string) (autorest.Response, error) {
+	policiesClient := getManagementPoliciesClient(ctx)
+	return policiesClient.Delete(ctx, accountGroupName, accountName)
+}
+
+// LifecycleBlob is a blob, snapshot or previous version as seen by a
+// lifecycle policy.
+type LifecycleBlob struct {
+	ContainerName string
+	Name          string
+	// Type is blockBlob, appendBlob or pageBlob.
+	Type string
+	// Tier is the access tier, Hot, Cool or Archive.
+	Tier         string
+	LastModified time.Time
+	// Snapshot is the time of a snapshot, zero for a blob.
+	Snapshot time.Time
+	// VersionID identifies a previous version, empty for the current one.
+	VersionID string
+	// CreationTime is when a version was created.
+	CreationTime time.Time
+	Tags         map[string]string
+}
+
+// LifecycleBlobs converts a listing of a container, e.g. by ListAllBlobs,
+// for EvaluateLifecycle. The listing has no versions or index tags; add
+// them to the result to simulate rules using them.
+func LifecycleBlobs(containerName string, items []azblob.BlobItem) ([]LifecycleBlob, error) {
+	var blobs []LifecycleBlob
+	for _, item := range items {
+		b := LifecycleBlob{
+			ContainerName: containerName,
+			Name:          item.Name,
+			Type:          lifecycleBlobType(item.Properties.BlobType),
+			Tier:          string(item.Properties.AccessTier),
+			LastModified:  item.Properties.LastModified,
+		}
+		if item.Snapshot != "" {
+			t, err := time.Parse(azblob.SnapshotTimeFormat, item.Snapshot)
+			if err != nil {
+				return nil, fmt.Errorf("blob %s has invalid snapshot %q: %v", item.Name, item.Snapshot, err)
+			}
+			b.Snapshot = t
+		}
+		blobs = append(blobs, b)
+	}
+	return blobs, nil
+}
+
+func lifecycleBlobType(t azblob.BlobType) string {
+	switch t {
+	case azblob.BlobBlockBlob:
+		return blobTypeBlock
+	case azblob.BlobAppendBlob:
+		return blobTypeAppend
+	case azblob.BlobPageBlob:
+		return "pageBlob"
+	}
+	return string(t)
+}
+
+// LifecycleActionType is what a lifecycle policy does to a blob.
+type LifecycleActionType string
+
+// Lifecycle action types, from the most to the least expensive.
+const (
+	LifecycleTierToCool    LifecycleActionType = "tierToCool"
+	LifecycleTierToArchive LifecycleActionType = "tierToArchive"
+	LifecycleDelete        LifecycleActionType = "delete"
+)
+
+// LifecycleAction is an action a lifecycle policy takes on a blob.
+type LifecycleAction struct {
+	Blob   LifecycleBlob
+	Action LifecycleActionType
+	// Rule is the name of the rule causing the action.
+	Rule string
+}
+
+// EvaluateLifecycle simulates a run of a policy made of rules at now and
+// returns what it does to blobs, in their order. Like the service, when
+// several actions are due for a blob it takes the cheapest, deleting before
+// archiving before cooling, and it doesn't move blobs to the tier they're
+// in or a warmer one.
+func EvaluateLifecycle(rules []LifecycleRule, blobs []LifecycleBlob, now time.Time) ([]LifecycleAction, error) {
+	if _, err := LifecyclePolicy(rules); err != nil {
+		return nil, err
+	}
+	var actions []LifecycleAction
+	for _, b := range blobs {
+		var best *LifecycleAction
+		for _, r := range rules {
+			if r.Disabled || !r.matches(b) {
+				continue
+			}
+			a, ok := r.due(b, now)
+			if ok && (best == nil || actionCost(a) < actionCost(best.Action)) {
+				best = &LifecycleAction{Blob: b, Action: a, Rule: r.Name}
+			}
+		}
+		if best != nil {
+			actions = append(actions, *best)
+		}
+	}
+	return actions, nil
+}
+
+func actionCost(a LifecycleActionType) int {
+	switch a {
+	case LifecycleDelete:
+		return 0
+	case LifecycleTierToArchive:
+		return 1
+	}
+	return 2
+}
+
+func (r LifecycleRule) matches(b LifecycleBlob) bool {
+	typeOK := false
+	for _, t := range r.blobTypes() {
+		typeOK = typeOK || t == b.Type
+	}
+	if !typeOK {
+		return false
+	}
+	if len(r.Prefixes) > 0 {
+		path := b.ContainerName + "/" + b.Name
+		prefixOK := false
+		for _, p := range r.Prefixes {
+			prefixOK = prefixOK || strings.HasPrefix(path, p)
+		}
+		if !prefixOK {
+			return false
+		}
+	}
+	for name, value := range r.Tags {
+		if v, ok := b.Tags[name]; !ok || v != value {
+			return false
+		}
+	}
+	return true
+}
+
+// due returns the cheapest action of r due for b at now.
+func (r LifecycleRule) due(b LifecycleBlob, now time.Time) (LifecycleActionType, bool) {
+	older := func(t time.Time, days *int) bool {
+		return days != nil && now.Sub(t).Hours()/24 > float64(*days)
+	}
+	switch {
+	case !b.Snapshot.IsZero():
+		return LifecycleDelete, older(b.Snapshot, r.DeleteSnapshotsAfterDays)
+	case b.VersionID != "":
+		return LifecycleDelete, older(b.CreationTime, r.DeleteVersionsAfterDays)
+	case older(b.LastModified, r.DeleteAfterDays):
+		return LifecycleDelete, true
+	case older(b.LastModified, r.ArchiveAfterDays) && b.Tier != string(azblob.AccessTierArchive):
+		return LifecycleTierToArchive, true
+	case older(b.LastModified, r.CoolAfterDays) && b.Tier != string(azblob.AccessTierCool) && b.Tier != string(azblob.AccessTierArchive):
+		return LifecycleTierToCool, true
+	}
+	return "", false
+}
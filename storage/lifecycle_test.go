@@ -0,0 +1,178 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package storage
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/Azure/go-autorest/autorest/to"
+)
+
+func TestLifecycleRuleValidate(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		r    LifecycleRule
+		ok   bool
+	}{
+		{"tiering", LifecycleRule{Name: "logs", CoolAfterDays: to.IntPtr(30), ArchiveAfterDays: to.IntPtr(90), DeleteAfterDays: to.IntPtr(365)}, true},
+		{"versions only", LifecycleRule{Name: "versions", DeleteVersionsAfterDays: to.IntPtr(0)}, true},
+		{"append blobs deleted", LifecycleRule{Name: "append", BlobTypes: []string{"appendBlob"}, DeleteAfterDays: to.IntPtr(7)}, true},
+		{"no name", LifecycleRule{DeleteAfterDays: to.IntPtr(1)}, false},
+		{"bad name", LifecycleRule{Name: "old-logs", DeleteAfterDays: to.IntPtr(1)}, false},
+		{"no actions", LifecycleRule{Name: "noop", Prefixes: []string{"logs/"}}, false},
+		{"negative days", LifecycleRule{Name: "neg", DeleteSnapshotsAfterDays: to.IntPtr(-1)}, false},
+		{"archived before cooled", LifecycleRule{Name: "order", CoolAfterDays: to.IntPtr(90), ArchiveAfterDays: to.IntPtr(30)}, false},
+		{"deleted before archived", LifecycleRule{Name: "order", ArchiveAfterDays: to.IntPtr(30), DeleteAfterDays: to.IntPtr(30)}, false},
+		{"append blobs tiered", LifecycleRule{Name: "append", BlobTypes: []string{"appendBlob"}, CoolAfterDays: to.IntPtr(7)}, false},
+		{"page blobs", LifecycleRule{Name: "pages", BlobTypes: []string{"pageBlob"}, DeleteAfterDays: to.IntPtr(7)}, false},
+		{"prefix without container", LifecycleRule{Name: "prefix", Prefixes: []string{"/logs"}, DeleteAfterDays: to.IntPtr(7)}, false},
+	} {
+		if err := test.r.Validate(); (err == nil) != test.ok {
+			t.Errorf("%s: got error %v", test.name, err)
+		}
+	}
+}
+
+func TestLifecyclePolicy(t *testing.T) {
+	policy, err := LifecyclePolicy([]LifecycleRule{{
+		Name:                    "logs",
+		Prefixes:                []string{"logs/app"},
+		Tags:                    map[string]string{"project": "samples"},
+		CoolAfterDays:           to.IntPtr(30),
+		DeleteAfterDays:         to.IntPtr(365),
+		DeleteVersionsAfterDays: to.IntPtr(7),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := json.Marshal(policy.Policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"rules":[{"enabled":true,"name":"logs","type":"Lifecycle","definition":{` +
+		`"actions":{"baseBlob":{"tierToCool":{"daysAfterModificationGreaterThan":30},"delete":{"daysAfterModificationGreaterThan":365}},` +
+		`"version":{"delete":{"daysAfterCreationGreaterThan":7}}},` +
+		`"filters":{"prefixMatch":["logs/app"],"blobTypes":["blockBlob"],"blobIndexMatch":[{"name":"project","op":"==","value":"samples"}]}}}]}`
+	if string(got) != want {
+		t.Errorf("got policy\n%s\nwant\n%s", got, want)
+	}
+
+	rule := LifecycleRule{Name: "twice", DeleteAfterDays: to.IntPtr(1)}
+	if _, err := LifecyclePolicy([]LifecycleRule{rule, rule}); err == nil {
+		t.Error("got no error for a policy with a rule defined twice")
+	}
+	if _, err := LifecyclePolicy(nil); err == nil {
+		t.Error("got no error for a policy without rules")
+	}
+}
+
+func TestEvaluateLifecycle(t *testing.T) {
+	now := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	daysAgo := func(days int) time.Time { return now.Add(-time.Duration(days) * 24 * time.Hour) }
+	rules := []LifecycleRule{
+		{
+			Name:             "logs",
+			Prefixes:         []string{"logs/"},
+			CoolAfterDays:    to.IntPtr(30),
+			ArchiveAfterDays: to.IntPtr(90),
+			DeleteAfterDays:  to.IntPtr(365),
+		},
+		{
+			Name:            "tmp",
+			Tags:            map[string]string{"retention": "short"},
+			DeleteAfterDays: to.IntPtr(1),
+		},
+		{
+			Name:                     "history",
+			DeleteSnapshotsAfterDays: to.IntPtr(7),
+			DeleteVersionsAfterDays:  to.IntPtr(7),
+		},
+		{
+			Name:            "disabled",
+			Disabled:        true,
+			DeleteAfterDays: to.IntPtr(0),
+		},
+	}
+	blobs := []LifecycleBlob{
+		{ContainerName: "logs", Name: "new.log", Type: "blockBlob", Tier: "Hot", LastModified: daysAgo(1)},
+		{ContainerName: "logs", Name: "month.log", Type: "blockBlob", Tier: "Hot", LastModified: daysAgo(31)},
+		{ContainerName: "logs", Name: "cooled.log", Type: "blockBlob", Tier: "Cool", LastModified: daysAgo(40)},
+		{ContainerName: "logs", Name: "quarter.log", Type: "blockBlob", Tier: "Hot", LastModified: daysAgo(91)},
+		{ContainerName: "logs", Name: "archived.log", Type: "blockBlob", Tier: "Archive", LastModified: daysAgo(200)},
+		{ContainerName: "logs", Name: "year.log", Type: "blockBlob", Tier: "Archive", LastModified: daysAgo(366)},
+		{ContainerName: "logs", Name: "append.log", Type: "appendBlob", LastModified: daysAgo(400)},
+		{ContainerName: "data", Name: "month.csv", Type: "blockBlob", Tier: "Hot", LastModified: daysAgo(31)},
+		{ContainerName: "logs", Name: "tagged.log", Type: "blockBlob", Tier: "Hot", LastModified: daysAgo(31), Tags: map[string]string{"retention": "short"}},
+		{ContainerName: "data", Name: "a.csv", Type: "blockBlob", Snapshot: daysAgo(8), LastModified: daysAgo(8)},
+		{ContainerName: "data", Name: "a.csv", Type: "blockBlob", Snapshot: daysAgo(6), LastModified: daysAgo(6)},
+		{ContainerName: "data", Name: "a.csv", Type: "blockBlob", VersionID: "v1", CreationTime: daysAgo(10), LastModified: daysAgo(10)},
+	}
+
+	actions, err := EvaluateLifecycle(rules, blobs, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	type action struct {
+		Blob, Action, Rule string
+	}
+	var got []action
+	for _, a := range actions {
+		name := a.Blob.ContainerName + "/" + a.Blob.Name
+		if a.Blob.VersionID != "" {
+			name += "@" + a.Blob.VersionID
+		}
+		if !a.Blob.Snapshot.IsZero() {
+			name += "@snapshot"
+		}
+		got = append(got, action{name, string(a.Action), a.Rule})
+	}
+	want := []action{
+		{"logs/month.log", "tierToCool", "logs"},
+		{"logs/quarter.log", "tierToArchive", "logs"},
+		{"logs/year.log", "delete", "logs"},
+		{"logs/tagged.log", "delete", "tmp"},
+		{"data/a.csv@snapshot", "delete", "history"},
+		{"data/a.csv@v1", "delete", "history"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got actions\n%v\nwant\n%v", got, want)
+	}
+
+	if _, err := EvaluateLifecycle([]LifecycleRule{{Name: "invalid"}}, blobs, now); err == nil {
+		t.Error("got no error evaluating an invalid policy")
+	}
+}
+
+func TestLifecycleBlobs(t *testing.T) {
+	modified := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	snapshot := time.Date(2020, 6, 2, 3, 4, 5, 600000000, time.UTC)
+	items := []azblob.BlobItem{
+		{Name: "a.txt", Properties: azblob.BlobProperties{BlobType: azblob.BlobBlockBlob, AccessTier: azblob.AccessTierCool, LastModified: modified}},
+		{Name: "a.txt", Snapshot: snapshot.Format(azblob.SnapshotTimeFormat), Properties: azblob.BlobProperties{BlobType: azblob.BlobBlockBlob, LastModified: modified}},
+		{Name: "b.log", Properties: azblob.BlobProperties{BlobType: azblob.BlobAppendBlob, LastModified: modified}},
+	}
+	blobs, err := LifecycleBlobs("c", items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []LifecycleBlob{
+		{ContainerName: "c", Name: "a.txt", Type: "blockBlob", Tier: "Cool", LastModified: modified},
+		{ContainerName: "c", Name: "a.txt", Type: "blockBlob", LastModified: modified, Snapshot: snapshot},
+		{ContainerName: "c", Name: "b.log", Type: "appendBlob", LastModified: modified},
+	}
+	if !reflect.DeepEqual(blobs, want) {
+		t.Errorf("got blobs\n%+v\nwant\n%+v", blobs, want)
+	}
+
+	items[1].Snapshot = "yesterday"
+	if _, err := LifecycleBlobs("c", items); err == nil {
+		t.Error("got no error for an invalid snapshot")
+	}
+}
@@ -0,0 +1,329 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-pipeline-go/pipeline"
+	"github.com/Azure/azure-sdk-for-go/services/storage/mgmt/2021-02-01/storage"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
+)
+
+const (
+	// a policy created on the destination account gets its ID from it
+	newReplicationPolicyID = "default"
+	// objectReplicationVersion is the first service version returning the
+	// replication status of blobs.
+	objectReplicationVersion = "2019-12-12"
+	replicationTimeFormat    = "2006-01-02T15:04:05Z"
+)
+
+// ReplicationAccount is a storage account taking part in object
+// replication.
+type ReplicationAccount struct {
+	Name      string
+	GroupName string
+}
+
+// ReplicationRule copies the blobs of a source container to a destination
+// container.
+type ReplicationRule struct {
+	// RuleID is set by the destination account when the policy is set up.
+	RuleID               string
+	SourceContainer      string
+	DestinationContainer string
+	// Prefixes, if set, only replicate blobs whose names start with one.
+	Prefixes []string
+	// MinCreationTime, if set, also replicates blobs which existed before
+	// the policy, if they were created after it. Otherwise only new blobs
+	// are replicated.
+	MinCreationTime time.Time
+}
+
+// ObjectReplication is an object replication policy set up between two
+// accounts by SetupObjectReplication.
+type ObjectReplication struct {
+	Source      ReplicationAccount
+	Destination ReplicationAccount
+	// PolicyID is the ID of the policy on both accounts.
+	PolicyID string
+	Rules    []ReplicationRule
+}
+
+// objectReplicationPolicies is the part of
+// storage.ObjectReplicationPoliciesClient used to set up replication.
+type objectReplicationPolicies interface {
+	CreateOrUpdate(ctx context.Context, resourceGroupName, accountName, objectReplicationPolicyID string, properties storage.ObjectReplicationPolicy) (storage.ObjectReplicationPolicy, error)
+	Delete(ctx context.Context, resourceGroupName, accountName, objectReplicationPolicyID string) (autorest.Response, error)
+}
+
+func validateReplicationRules(rules []ReplicationRule) error {
+	if len(rules) == 0 {
+		return errors.New("object replication needs at least one rule")
+	}
+	pairs := map[string]bool{}
+	for _, r := range rules {
+		if r.SourceContainer == "" || r.DestinationContainer == "" {
+			return errors.New("object replication rules need a source and a destination container")
+		}
+		pair := r.SourceContainer + "/" + r.DestinationContainer
+		if pairs[pair] {
+			return fmt.Errorf("containers %s and %s are in more than one rule", r.SourceContainer, r.DestinationContainer)
+		}
+		pairs[pair] = true
+	}
+	return nil
+}
+
+// replicationPolicy returns the policy to put on an account. The
+// destination's has no IDs yet, the source's has those the destination
+// returned.
+func replicationPolicy(src, dst ReplicationAccount, rules []ReplicationRule) storage.ObjectReplicationPolicy {
+	var policyRules []storage.ObjectReplicationPolicyRule
+	for _, r := range rules {
+		rule := storage.ObjectReplicationPolicyRule{
+			SourceContainer:      to.StringPtr(r.SourceContainer),
+			DestinationContainer: to.StringPtr(r.DestinationContainer),
+		}
+		if r.RuleID != "" {
+			rule.RuleID = to.StringPtr(r.RuleID)
+		}
+		if len(r.Prefixes) > 0 || !r.MinCreationTime.IsZero() {
+			rule.Filters = &storage.ObjectReplicationPolicyFilter{}
+			if len(r.Prefixes) > 0 {
+				rule.Filters.PrefixMatch = to.StringSlicePtr(r.Prefixes)
+			}
+			if !r.MinCreationTime.IsZero() {
+				rule.Filters.MinCreationTime = to.StringPtr(r.MinCreationTime.UTC().Format(replicationTimeFormat))
+			}
+		}
+		policyRules = append(policyRules, rule)
+	}
+	return storage.ObjectReplicationPolicy{
+		ObjectReplicationPolicyProperties: &storage.ObjectReplicationPolicyProperties{
+			SourceAccount:      to.StringPtr(src.Name),
+			DestinationAccount: to.StringPtr(dst.Name),
+			Rules:              &policyRules,
+		},
+	}
+}
+
+// withRuleIDs returns rules with the IDs the destination gave them in
+// created, matched by their containers.
+func withRuleIDs(rules []ReplicationRule, created storage.ObjectReplicationPolicy) ([]ReplicationRule, error) {
+	ids := map[string]string{}
+	if created.ObjectReplicationPolicyProperties != nil && created.Rules != nil {
+		for _, r := range *created.Rules {
+			if r.RuleID != nil && r.SourceContainer != nil && r.DestinationContainer != nil {
+				ids[*r.SourceContainer+"/"+*r.DestinationContainer] = *r.RuleID
+			}
+		}
+	}
+	withIDs := make([]ReplicationRule, len(rules))
+	for i, r := range rules {
+		id, ok := ids[r.SourceContainer+"/"+r.DestinationContainer]
+		if !ok {
+			return nil, fmt.Errorf("destination returned no rule ID for containers %s and %s", r.SourceContainer, r.DestinationContainer)
+		}
+		r.RuleID = id
+		withIDs[i] = r
+	}
+	return withIDs, nil
+}
+
+// SetupObjectReplication replicates blobs of containers of src to
+// containers of dst asynchronously, as set by rules. It turns on versioning
+// and the change feed on both accounts, which replication needs, then sets
+// the policy on dst, which gives it and its rules IDs, and then the same
+// policy on src. The containers must exist; the destination ones become
+// read-only. Remove the policy with TeardownObjectReplication.
+func SetupObjectReplication(ctx context.Context, src, dst ReplicationAccount, rules []ReplicationRule) (ObjectReplication, error) {
+	if err := validateReplicationRules(rules); err != nil {
+		return ObjectReplication{}, err
+	}
+	for _, a := range []ReplicationAccount{src, dst} {
+		_, err := updateBlobServiceProperties(ctx, a.Name, a.GroupName, func(p *storage.BlobServicePropertiesProperties) {
+			p.IsVersioningEnabled = to.BoolPtr(true)
+			p.ChangeFeed = &storage.ChangeFeed{Enabled: to.BoolPtr(true)}
+		})
+		if err != nil {
+			return ObjectReplication{}, fmt.Errorf("failed to enable versioning and change feed on %s: %v", a.Name, err)
+		}
+	}
+	return setupObjectReplication(ctx, getObjRepClient(ctx), src, dst, rules)
+}
+
+func setupObjectReplication(ctx context.Context, policies objectReplicationPolicies, src, dst ReplicationAccount, rules []ReplicationRule) (ObjectReplication, error) {
+	created, err := policies.CreateOrUpdate(ctx, dst.GroupName, dst.Name, newReplicationPolicyID, replicationPolicy(src, dst, rules))
+	if err != nil {
+		return ObjectReplication{}, fmt.Errorf("failed to create replication policy on destination %s: %v", dst.Name, err)
+	}
+	if created.ObjectReplicationPolicyProperties == nil || created.PolicyID == nil {
+		return ObjectReplication{}, fmt.Errorf("destination %s returned no replication policy ID", dst.Name)
+	}
+	r := ObjectReplication{Source: src, Destination: dst, PolicyID: *created.PolicyID}
+
+	// undo the destination's policy if the source's can't be set, so
+	// setting up again starts afresh
+	rollback := func(err error) (ObjectReplication, error) {
+		if _, deleteErr := policies.Delete(ctx, dst.GroupName, dst.Name, r.PolicyID); deleteErr != nil {
+			return ObjectReplication{}, fmt.Errorf("%v; also failed to delete replication policy %s on destination %s: %v", err, r.PolicyID, dst.Name, deleteErr)
+		}
+		return ObjectReplication{}, err
+	}
+	r.Rules, err = withRuleIDs(rules, created)
+	if err != nil {
+		return rollback(err)
+	}
+	_, err = policies.CreateOrUpdate(ctx, src.GroupName, src.Name, r.PolicyID, replicationPolicy(src, dst, r.Rules))
+	if err != nil {
+		return rollback(fmt.Errorf("failed to create replication policy on source %s: %v", src.Name, err))
+	}
+	return r, nil
+}
+
+// TeardownObjectReplication stops replication set up by
+// SetupObjectReplication by deleting its policy, on the source first so
+// nothing is replicated to a destination without the policy. Versioning and
+// the change feed stay on. Policies already deleted are skipped, so it can
+// be run again after a partial failure.
+func TeardownObjectReplication(ctx context.Context, r ObjectReplication) error {
+	return teardownObjectReplication(ctx, getObjRepClient(ctx), r)
+}
+
+func teardownObjectReplication(ctx context.Context, policies objectReplicationPolicies, r ObjectReplication) error {
+	for _, a := range []ReplicationAccount{r.Source, r.Destination} {
+		resp, err := policies.Delete(ctx, a.GroupName, a.Name, r.PolicyID)
+		if err != nil && (resp.Response == nil || resp.StatusCode != http.StatusNotFound) {
+			return fmt.Errorf("failed to delete replication policy %s on %s: %v", r.PolicyID, a.Name, err)
+		}
+	}
+	return nil
+}
+
+// ReplicationStatus is the replication status of a source blob.
+type ReplicationStatus string
+
+// Replication statuses.
+const (
+	// ReplicationPending blobs weren't replicated yet.
+	ReplicationPending  ReplicationStatus = "pending"
+	ReplicationComplete ReplicationStatus = "complete"
+	ReplicationFailed   ReplicationStatus = "failed"
+)
+
+// replicationStatus reads the status of a blob for a rule from the headers
+// of its properties.
+func replicationStatus(header http.Header, policyID, ruleID string) ReplicationStatus {
+	switch s := ReplicationStatus(strings.ToLower(header.Get("x-ms-or-" + policyID + "_" + ruleID))); s {
+	case ReplicationComplete, ReplicationFailed:
+		return s
+	}
+	return ReplicationPending
+}
+
+// ReplicationReport is the replication status of the source blobs of an
+// object replication, by "container/blob" name.
+type ReplicationReport struct {
+	Complete []string
+	Pending  []string
+	// Failed holds why each blob which failed wasn't replicated, or its
+	// status couldn't be read.
+	Failed map[string]error
+}
+
+// Err returns an error listing every blob which failed, or nil.
+func (r *ReplicationReport) Err() error {
+	if len(r.Failed) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(r.Failed))
+	for name := range r.Failed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	fmt.Fprintf(&b, "failed %d of %d blob replications:", len(r.Failed), len(r.Failed)+len(r.Complete)+len(r.Pending))
+	for _, name := range names {
+		fmt.Fprintf(&b, "\n  %s: %v", name, r.Failed[name])
+	}
+	return fmt.Errorf("%s", b.String())
+}
+
+// GetReplicationReport gets the replication status of each source blob of
+// r matching its rules' prefixes.
+func GetReplicationReport(ctx context.Context, r ObjectReplication) (*ReplicationReport, error) {
+	report := &ReplicationReport{Failed: map[string]error{}}
+	for _, rule := range r.Rules {
+		prefixes := rule.Prefixes
+		if len(prefixes) == 0 {
+			prefixes = []string{""}
+		}
+		// prefixes may overlap, so blobs are only reported once
+		seen := map[string]bool{}
+		for _, prefix := range prefixes {
+			items, err := ListAllBlobs(ctx, r.Source.Name, r.Source.GroupName, rule.SourceContainer, prefix)
+			if err != nil {
+				return nil, err
+			}
+			for _, item := range items {
+				if seen[item.Name] {
+					continue
+				}
+				seen[item.Name] = true
+				name := rule.SourceContainer + "/" + item.Name
+				header, err := blobPropertiesHeader(ctx, r.Source, rule.SourceContainer, item.Name)
+				if err != nil {
+					report.Failed[name] = err
+					continue
+				}
+				switch replicationStatus(header, r.PolicyID, rule.RuleID) {
+				case ReplicationComplete:
+					report.Complete = append(report.Complete, name)
+				case ReplicationFailed:
+					report.Failed[name] = fmt.Errorf("replication to %s/%s failed", r.Destination.Name, rule.DestinationContainer)
+				default:
+					report.Pending = append(report.Pending, name)
+				}
+			}
+		}
+	}
+	return report, nil
+}
+
+// blobPropertiesHeader gets the properties of a blob as headers. The blob
+// SDK in use predates replication statuses, so they're requested directly
+// with a newer service version.
+func blobPropertiesHeader(ctx context.Context, a ReplicationAccount, containerName, blobName string) (http.Header, error) {
+	u, err := url.Parse(sasURL(a.Name, containerName, blobName, ""))
+	if err != nil {
+		return nil, err
+	}
+	req, err := pipeline.NewRequest(http.MethodHead, *u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("x-ms-version", objectReplicationVersion)
+	resp, err := newBlobPipeline(a.Name, a.GroupName).Do(ctx, nil, req)
+	if err != nil {
+		return nil, err
+	}
+	r := resp.Response()
+	r.Body.Close()
+	if r.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("cannot get properties of blob %s: %s", blobName, r.Status)
+	}
+	return r.Header, nil
+}
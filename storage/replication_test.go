@@ -0,0 +1,159 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package storage
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/storage/mgmt/2021-02-01/storage"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
+)
+
+// fakeReplicationPolicies plays the service: policies created on the
+// destination get IDs, and calls are recorded.
+type fakeReplicationPolicies struct {
+	calls     []string
+	created   map[string]storage.ObjectReplicationPolicy
+	failOn    string
+	notFounds map[string]bool
+}
+
+func (f *fakeReplicationPolicies) CreateOrUpdate(ctx context.Context, resourceGroupName, accountName, policyID string, policy storage.ObjectReplicationPolicy) (storage.ObjectReplicationPolicy, error) {
+	f.calls = append(f.calls, "create "+accountName+" "+policyID)
+	if f.failOn == accountName {
+		return storage.ObjectReplicationPolicy{}, errors.New("InvalidRequest")
+	}
+	if f.created == nil {
+		f.created = map[string]storage.ObjectReplicationPolicy{}
+	}
+	f.created[accountName] = policy
+	if *policy.DestinationAccount == accountName {
+		p := *policy.ObjectReplicationPolicyProperties
+		p.PolicyID = to.StringPtr("policy-1")
+		var rules []storage.ObjectReplicationPolicyRule
+		for i, r := range *p.Rules {
+			r.RuleID = to.StringPtr("rule-" + string(rune('a'+i)))
+			rules = append(rules, r)
+		}
+		p.Rules = &rules
+		return storage.ObjectReplicationPolicy{ObjectReplicationPolicyProperties: &p}, nil
+	}
+	return policy, nil
+}
+
+func (f *fakeReplicationPolicies) Delete(ctx context.Context, resourceGroupName, accountName, policyID string) (autorest.Response, error) {
+	f.calls = append(f.calls, "delete "+accountName+" "+policyID)
+	if f.notFounds[accountName] {
+		return autorest.Response{Response: &http.Response{StatusCode: http.StatusNotFound}}, errors.New("ResourceNotFound")
+	}
+	return autorest.Response{Response: &http.Response{StatusCode: http.StatusOK}}, nil
+}
+
+var (
+	testReplicationSource      = ReplicationAccount{Name: "src", GroupName: "src-group"}
+	testReplicationDestination = ReplicationAccount{Name: "dst", GroupName: "dst-group"}
+)
+
+func TestSetupObjectReplication(t *testing.T) {
+	f := &fakeReplicationPolicies{}
+	rules := []ReplicationRule{
+		{SourceContainer: "logs", DestinationContainer: "logs-copy", Prefixes: []string{"app/"}},
+		{SourceContainer: "data", DestinationContainer: "data-copy", MinCreationTime: time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	r, err := setupObjectReplication(context.Background(), f, testReplicationSource, testReplicationDestination, rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"create dst default", "create src policy-1"}; !reflect.DeepEqual(f.calls, want) {
+		t.Errorf("got calls %q, want %q", f.calls, want)
+	}
+	if r.PolicyID != "policy-1" || r.Rules[0].RuleID != "rule-a" || r.Rules[1].RuleID != "rule-b" {
+		t.Errorf("got replication %+v, want the destination's IDs", r)
+	}
+
+	src := f.created["src"]
+	for i, rule := range *src.Rules {
+		if rule.RuleID == nil || *rule.RuleID != r.Rules[i].RuleID {
+			t.Errorf("source rule %d has ID %v, want %s", i, rule.RuleID, r.Rules[i].RuleID)
+		}
+	}
+	dst := f.created["dst"]
+	if rule := (*dst.Rules)[0]; rule.RuleID != nil || !reflect.DeepEqual(*rule.Filters.PrefixMatch, []string{"app/"}) {
+		t.Errorf("got destination rule %+v, want no ID and the prefix filter", rule)
+	}
+	if got := *(*dst.Rules)[1].Filters.MinCreationTime; got != "2020-06-01T00:00:00Z" {
+		t.Errorf("got min creation time %s", got)
+	}
+}
+
+func TestSetupObjectReplicationRollsBack(t *testing.T) {
+	f := &fakeReplicationPolicies{failOn: "src"}
+	rules := []ReplicationRule{{SourceContainer: "logs", DestinationContainer: "logs-copy"}}
+
+	if _, err := setupObjectReplication(context.Background(), f, testReplicationSource, testReplicationDestination, rules); err == nil {
+		t.Fatal("got no error when the source policy failed")
+	}
+	want := []string{"create dst default", "create src policy-1", "delete dst policy-1"}
+	if !reflect.DeepEqual(f.calls, want) {
+		t.Errorf("got calls %q, want %q", f.calls, want)
+	}
+}
+
+func TestTeardownObjectReplication(t *testing.T) {
+	r := ObjectReplication{Source: testReplicationSource, Destination: testReplicationDestination, PolicyID: "policy-1"}
+	f := &fakeReplicationPolicies{notFounds: map[string]bool{"src": true}}
+
+	if err := teardownObjectReplication(context.Background(), f, r); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"delete src policy-1", "delete dst policy-1"}; !reflect.DeepEqual(f.calls, want) {
+		t.Errorf("got calls %q, want %q", f.calls, want)
+	}
+}
+
+func TestValidateReplicationRules(t *testing.T) {
+	for _, rules := range [][]ReplicationRule{
+		nil,
+		{{SourceContainer: "logs"}},
+		{{SourceContainer: "logs", DestinationContainer: "copy"}, {SourceContainer: "logs", DestinationContainer: "copy"}},
+	} {
+		if err := validateReplicationRules(rules); err == nil {
+			t.Errorf("got no error for rules %+v", rules)
+		}
+	}
+}
+
+func TestReplicationStatus(t *testing.T) {
+	header := http.Header{}
+	header.Set("x-ms-or-policy-1_rule-a", "complete")
+	header.Set("x-ms-or-policy-1_rule-b", "Failed")
+	for ruleID, want := range map[string]ReplicationStatus{
+		"rule-a": ReplicationComplete,
+		"rule-b": ReplicationFailed,
+		"rule-c": ReplicationPending,
+	} {
+		if got := replicationStatus(header, "policy-1", ruleID); got != want {
+			t.Errorf("got status %s for %s, want %s", got, ruleID, want)
+		}
+	}
+
+	report := &ReplicationReport{
+		Complete: []string{"logs/a"},
+		Pending:  []string{"logs/b"},
+		Failed:   map[string]error{"logs/c": errors.New("replication to dst/copy failed")},
+	}
+	want := "failed 1 of 3 blob replications:\n  logs/c: replication to dst/copy failed"
+	if err := report.Err(); err == nil || err.Error() != want {
+		t.Errorf("got %v, want %q", err, want)
+	}
+}
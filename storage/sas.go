@@ -0,0 +1,343 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/Azure/go-autorest/autorest"
+)
+
+const (
+	// userDelegationVersion is the first service version with user
+	// delegation keys and SAS.
+	userDelegationVersion = "2018-11-09"
+	// maxUserDelegationLifetime is how long a user delegation key, and so
+	// its SAS, can be valid at most.
+	maxUserDelegationLifetime = 7 * 24 * time.Hour
+	sasTimeFormat             = "2006-01-02T15:04:05Z"
+)
+
+// SASOptions are the constraints of a SAS token. Permissions and Expiry are
+// required; tokens are only accepted over HTTPS.
+type SASOptions struct {
+	// Permissions are the letters of the operations allowed, e.g. "r" to
+	// read or "rcw" to also create and write, as defined by
+	// azblob.BlobSASPermissions, ContainerSASPermissions and
+	// AccountSASPermissions.
+	Permissions string
+	// Start is when the token becomes valid, immediately if zero.
+	Start time.Time
+	// Expiry is when the token stops being valid.
+	Expiry time.Time
+	// IPRange, if set, is the addresses the token is accepted from.
+	IPRange azblob.IPRange
+}
+
+func (o SASOptions) validate(now time.Time) error {
+	if o.Permissions == "" {
+		return errors.New("SAS permissions must be set")
+	}
+	if o.Expiry.IsZero() {
+		return errors.New("SAS expiry must be set")
+	}
+	if !o.Expiry.After(now) {
+		return fmt.Errorf("SAS expiry %v is in the past", o.Expiry)
+	}
+	if !o.Start.IsZero() && !o.Start.Before(o.Expiry) {
+		return fmt.Errorf("SAS start %v isn't before its expiry %v", o.Start, o.Expiry)
+	}
+	return nil
+}
+
+// ServiceSAS returns the URL of a blob with a SAS token signed with the
+// account key, or of a container if blobName is empty.
+func ServiceSAS(ctx context.Context, accountName, accountGroupName, containerName, blobName string, o SASOptions) (string, error) {
+	if err := o.validate(time.Now()); err != nil {
+		return "", err
+	}
+	permissions, err := servicePermissions(blobName, o.Permissions)
+	if err != nil {
+		return "", err
+	}
+	c, err := sharedKeyCredential(ctx, accountName, accountGroupName)
+	if err != nil {
+		return "", err
+	}
+
+	q, err := azblob.BlobSASSignatureValues{
+		Protocol:      azblob.SASProtocolHTTPS,
+		StartTime:     o.Start,
+		ExpiryTime:    o.Expiry,
+		Permissions:   permissions,
+		IPRange:       o.IPRange,
+		ContainerName: containerName,
+		BlobName:      blobName,
+	}.NewSASQueryParameters(c)
+	if err != nil {
+		return "", err
+	}
+	return sasURL(accountName, containerName, blobName, q.Encode()), nil
+}
+
+// AccountSAS returns the URL of an account's blob service with a SAS token
+// signed with the account key. resourceTypes are the letters of the kinds
+// of resources the token is valid for, see azblob.AccountSASResourceTypes:
+// "s" for the service, "c" for containers and "o" for blobs.
+func AccountSAS(ctx context.Context, accountName, accountGroupName, resourceTypes string, o SASOptions) (string, error) {
+	if err := o.validate(time.Now()); err != nil {
+		return "", err
+	}
+	var permissions azblob.AccountSASPermissions
+	if err := permissions.Parse(o.Permissions); err != nil {
+		return "", err
+	}
+	var types azblob.AccountSASResourceTypes
+	if err := types.Parse(resourceTypes); err != nil {
+		return "", err
+	}
+	c, err := sharedKeyCredential(ctx, accountName, accountGroupName)
+	if err != nil {
+		return "", err
+	}
+
+	q, err := azblob.AccountSASSignatureValues{
+		Protocol:      azblob.SASProtocolHTTPS,
+		StartTime:     o.Start,
+		ExpiryTime:    o.Expiry,
+		Permissions:   permissions.String(),
+		IPRange:       o.IPRange,
+		Services:      azblob.AccountSASServices{Blob: true}.String(),
+		ResourceTypes: types.String(),
+	}.NewSASQueryParameters(c)
+	if err != nil {
+		return "", err
+	}
+	return sasURL(accountName, "", "", q.Encode()), nil
+}
+
+// UserDelegationSAS returns the URL of a blob, or of a container if
+// blobName is empty, with a SAS token signed with a user delegation key.
+// The key is issued to the configured identity, see
+// `iam.GetStorageAuthorizer`, so no account key is needed; the token only
+// grants what both it and the identity's data roles allow. It can be valid
+// for at most 7 days.
+func UserDelegationSAS(ctx context.Context, accountName, containerName, blobName string, o SASOptions) (string, error) {
+	now := time.Now()
+	if err := o.validate(now); err != nil {
+		return "", err
+	}
+	if o.Expiry.After(now.Add(maxUserDelegationLifetime)) {
+		return "", fmt.Errorf("user delegation SAS can be valid for at most %v", maxUserDelegationLifetime)
+	}
+	permissions, err := servicePermissions(blobName, o.Permissions)
+	if err != nil {
+		return "", err
+	}
+	o.Permissions = permissions
+
+	keyStart := o.Start
+	if keyStart.IsZero() {
+		keyStart = now
+	}
+	key, err := getUserDelegationKey(ctx, accountName, keyStart, o.Expiry)
+	if err != nil {
+		return "", err
+	}
+	q, err := signUserDelegationSAS(accountName, containerName, blobName, key, o)
+	if err != nil {
+		return "", err
+	}
+	return sasURL(accountName, containerName, blobName, q.Encode()), nil
+}
+
+// ContainerFromSAS returns a container URL which authenticates with the SAS
+// token of sasURL, e.g. as returned by ServiceSAS for a container, so the
+// blob helpers' operations can be used without the account key.
+func ContainerFromSAS(sasURL string) (azblob.ContainerURL, error) {
+	u, err := url.Parse(sasURL)
+	if err != nil {
+		return azblob.ContainerURL{}, err
+	}
+	p := azblob.NewPipeline(azblob.NewAnonymousCredential(), azblob.PipelineOptions{
+		Telemetry: azblob.TelemetryOptions{Value: config.UserAgent()},
+	})
+	return azblob.NewContainerURL(*u, p), nil
+}
+
+// servicePermissions checks and orders the permissions of a SAS for a blob,
+// or a container if blobName is empty.
+func servicePermissions(blobName, permissions string) (string, error) {
+	if blobName == "" {
+		var p azblob.ContainerSASPermissions
+		if err := p.Parse(permissions); err != nil {
+			return "", err
+		}
+		return p.String(), nil
+	}
+	var p azblob.BlobSASPermissions
+	if err := p.Parse(permissions); err != nil {
+		return "", err
+	}
+	return p.String(), nil
+}
+
+func sasURL(accountName, containerName, blobName, query string) string {
+	u, _ := url.Parse(fmt.Sprintf(blobFormatString, accountName))
+	if containerName != "" {
+		u.Path = "/" + containerName
+		if blobName != "" {
+			u.Path += "/" + blobName
+		}
+	}
+	u.RawQuery = query
+	return u.String()
+}
+
+// userDelegationKey is a key to sign SAS tokens with, issued to an Azure AD
+// identity.
+type userDelegationKey struct {
+	SignedOid     string `xml:"SignedOid"`
+	SignedTid     string `xml:"SignedTid"`
+	SignedStart   string `xml:"SignedStart"`
+	SignedExpiry  string `xml:"SignedExpiry"`
+	SignedService string `xml:"SignedService"`
+	SignedVersion string `xml:"SignedVersion"`
+	Value         string `xml:"Value"`
+}
+
+// getUserDelegationKey gets a user delegation key valid from start to
+// expiry. The blob SDK in use predates them, so it's requested directly.
+func getUserDelegationKey(ctx context.Context, accountName string, start, expiry time.Time) (userDelegationKey, error) {
+	var key userDelegationKey
+	a, err := iam.GetStorageAuthorizer()
+	if err != nil {
+		return key, err
+	}
+
+	body, err := xml.Marshal(struct {
+		XMLName xml.Name `xml:"KeyInfo"`
+		Start   string   `xml:"Start"`
+		Expiry  string   `xml:"Expiry"`
+	}{
+		Start:  start.UTC().Format(sasTimeFormat),
+		Expiry: expiry.UTC().Format(sasTimeFormat),
+	})
+	if err != nil {
+		return key, err
+	}
+	req, err := http.NewRequest(http.MethodPost, sasURL(accountName, "", "", "restype=service&comp=userdelegationkey"), bytes.NewReader(body))
+	if err != nil {
+		return key, err
+	}
+	req, err = autorest.Prepare(req.WithContext(ctx),
+		autorest.WithHeader("x-ms-version", userDelegationVersion),
+		autorest.WithHeader("Content-Type", "application/xml"),
+		a.WithAuthorization())
+	if err != nil {
+		return key, err
+	}
+
+	resp, err := recording.Sender().Do(req)
+	if err != nil {
+		return key, fmt.Errorf("cannot get user delegation key: %v", err)
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return key, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return key, fmt.Errorf("cannot get user delegation key: %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	err = xml.Unmarshal(data, &key)
+	return key, err
+}
+
+// signUserDelegationSAS returns the query of a user delegation SAS for a
+// blob, or a container if blobName is empty. o.Permissions must be ordered.
+func signUserDelegationSAS(accountName, containerName, blobName string, key userDelegationKey, o SASOptions) (url.Values, error) {
+	resource := "c"
+	canonicalName := "/blob/" + accountName + "/" + containerName
+	if blobName != "" {
+		resource = "b"
+		canonicalName += "/" + blobName
+	}
+	var start string
+	if !o.Start.IsZero() {
+		start = o.Start.UTC().Format(sasTimeFormat)
+	}
+	expiry := o.Expiry.UTC().Format(sasTimeFormat)
+	ipRange := o.IPRange.String()
+	protocol := string(azblob.SASProtocolHTTPS)
+
+	// https://docs.microsoft.com/rest/api/storageservices/create-user-delegation-sas
+	stringToSign := strings.Join([]string{
+		o.Permissions,
+		start,
+		expiry,
+		canonicalName,
+		key.SignedOid,
+		key.SignedTid,
+		key.SignedStart,
+		key.SignedExpiry,
+		key.SignedService,
+		key.SignedVersion,
+		ipRange,
+		protocol,
+		userDelegationVersion,
+		resource,
+		"", // snapshot time
+		"", // rscc
+		"", // rscd
+		"", // rsce
+		"", // rscl
+		"", // rsct
+	}, "\n")
+	secret, err := base64.StdEncoding.DecodeString(key.Value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user delegation key: %v", err)
+	}
+	h := hmac.New(sha256.New, secret)
+	h.Write([]byte(stringToSign))
+
+	q := url.Values{}
+	q.Set("sv", userDelegationVersion)
+	q.Set("sr", resource)
+	q.Set("sp", o.Permissions)
+	if start != "" {
+		q.Set("st", start)
+	}
+	q.Set("se", expiry)
+	if ipRange != "" {
+		q.Set("sip", ipRange)
+	}
+	q.Set("spr", protocol)
+	q.Set("skoid", key.SignedOid)
+	q.Set("sktid", key.SignedTid)
+	q.Set("skt", key.SignedStart)
+	q.Set("ske", key.SignedExpiry)
+	q.Set("sks", key.SignedService)
+	q.Set("skv", key.SignedVersion)
+	q.Set("sig", base64.StdEncoding.EncodeToString(h.Sum(nil)))
+	return q, nil
+}
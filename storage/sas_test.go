@@ -0,0 +1,193 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package storage
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+)
+
+// withFakeKeys makes the key cache list key for every account. It returns
+// how often it was listed and a func restoring the cache.
+func withFakeKeys(key string) (*int, func()) {
+	listed := new(int)
+	saved := keyCache
+	keyCache = &accountKeys{
+		keys: map[string]string{},
+		list: func(ctx context.Context, accountName, accountGroupName string) (string, error) {
+			*listed++
+			return key, nil
+		},
+	}
+	return listed, func() { keyCache = saved }
+}
+
+func TestAccountKeysCache(t *testing.T) {
+	listed, restore := withFakeKeys("a2V5")
+	defer restore()
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		key, err := keyCache.get(ctx, "account1", "group1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if key != "a2V5" {
+			t.Errorf("got key %q, want a2V5", key)
+		}
+	}
+	if *listed != 1 {
+		t.Errorf("listed keys %d times for one account, want once", *listed)
+	}
+
+	if _, err := keyCache.get(ctx, "account2", "group1"); err != nil {
+		t.Fatal(err)
+	}
+	keyCache.forget(ctx, "account1", "group1")
+	if _, err := keyCache.get(ctx, "account1", "group1"); err != nil {
+		t.Fatal(err)
+	}
+	if *listed != 3 {
+		t.Errorf("listed keys %d times, want 3 for another account and a forgotten key", *listed)
+	}
+}
+
+func TestAccountKeysCacheErrors(t *testing.T) {
+	calls := 0
+	c := &accountKeys{
+		keys: map[string]string{},
+		list: func(ctx context.Context, accountName, accountGroupName string) (string, error) {
+			calls++
+			return "", errors.New("AuthorizationFailed")
+		},
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := c.get(context.Background(), "account1", "group1"); err == nil {
+			t.Error("got no error from a failed listing")
+		}
+	}
+	if calls != 2 {
+		t.Errorf("listed keys %d times, want failures not to be cached", calls)
+	}
+}
+
+func TestSASOptionsValidate(t *testing.T) {
+	now := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	for _, test := range []struct {
+		name string
+		o    SASOptions
+		ok   bool
+	}{
+		{"valid", SASOptions{Permissions: "r", Expiry: now.Add(time.Hour)}, true},
+		{"no permissions", SASOptions{Expiry: now.Add(time.Hour)}, false},
+		{"no expiry", SASOptions{Permissions: "r"}, false},
+		{"expired", SASOptions{Permissions: "r", Expiry: now.Add(-time.Hour)}, false},
+		{"start after expiry", SASOptions{Permissions: "r", Start: now.Add(2 * time.Hour), Expiry: now.Add(time.Hour)}, false},
+	} {
+		if err := test.o.validate(now); (err == nil) != test.ok {
+			t.Errorf("%s: got error %v", test.name, err)
+		}
+	}
+}
+
+func TestServiceSAS(t *testing.T) {
+	_, restore := withFakeKeys("a2V5")
+	defer restore()
+	ctx := context.Background()
+	o := SASOptions{
+		Permissions: "wr",
+		Expiry:      time.Now().Add(time.Hour),
+		IPRange:     azblob.IPRange{Start: net.ParseIP("10.0.0.1"), End: net.ParseIP("10.0.0.9")},
+	}
+
+	s, err := ServiceSAS(ctx, "account1", "group1", "container1", "dir/a.txt", o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "https://account1.blob.core.windows.net/container1/dir/a.txt"; !strings.HasPrefix(s, want+"?") {
+		t.Errorf("got URL %s, want one of %s", s, want)
+	}
+	q := u.Query()
+	for param, want := range map[string]string{"sp": "rw", "sr": "b", "spr": "https", "sip": "10.0.0.1-10.0.0.9"} {
+		if got := q.Get(param); got != want {
+			t.Errorf("got %s=%q, want %q", param, got, want)
+		}
+	}
+	if q.Get("sig") == "" {
+		t.Error("SAS isn't signed")
+	}
+
+	if _, err := ServiceSAS(ctx, "account1", "group1", "container1", "", SASOptions{Permissions: "x", Expiry: o.Expiry}); err == nil {
+		t.Error("got no error for an invalid container permission")
+	}
+}
+
+func TestAccountSAS(t *testing.T) {
+	_, restore := withFakeKeys("a2V5")
+	defer restore()
+	s, err := AccountSAS(context.Background(), "account1", "group1", "co", SASOptions{Permissions: "rl", Expiry: time.Now().Add(time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	for param, want := range map[string]string{"ss": "b", "srt": "co", "sp": "rl", "spr": "https"} {
+		if got := q.Get(param); got != want {
+			t.Errorf("got %s=%q, want %q", param, got, want)
+		}
+	}
+}
+
+func TestSignUserDelegationSAS(t *testing.T) {
+	key := userDelegationKey{
+		SignedOid:     "oid",
+		SignedTid:     "tid",
+		SignedStart:   "2020-06-01T00:00:00Z",
+		SignedExpiry:  "2020-06-02T00:00:00Z",
+		SignedService: "b",
+		SignedVersion: "2018-11-09",
+		Value:         "c2VjcmV0LWtleS12YWx1ZQ==",
+	}
+	o := SASOptions{
+		Permissions: "rw",
+		Start:       time.Date(2020, 6, 1, 1, 0, 0, 0, time.UTC),
+		Expiry:      time.Date(2020, 6, 1, 2, 0, 0, 0, time.UTC),
+		IPRange:     azblob.IPRange{Start: net.ParseIP("10.0.0.1"), End: net.ParseIP("10.0.0.9")},
+	}
+
+	q, err := signUserDelegationSAS("acct", "cont", "dir/a.txt", key, o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "se=2020-06-01T02%3A00%3A00Z&sig=zGcRZQQA67FWDmhFU8g90QlXMBt2SbpwE2w%2F9msjOFU%3D&sip=10.0.0.1-10.0.0.9" +
+		"&ske=2020-06-02T00%3A00%3A00Z&skoid=oid&sks=b&skt=2020-06-01T00%3A00%3A00Z&sktid=tid&skv=2018-11-09" +
+		"&sp=rw&spr=https&sr=b&st=2020-06-01T01%3A00%3A00Z&sv=2018-11-09"
+	if got := q.Encode(); got != want {
+		t.Errorf("got SAS\n%s\nwant\n%s", got, want)
+	}
+
+	q, err = signUserDelegationSAS("acct", "cont", "", key, SASOptions{Permissions: "rl", Expiry: o.Expiry})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Get("sr") != "c" || q.Get("st") != "" || q.Get("sip") != "" {
+		t.Errorf("got container SAS %s, want sr=c without st and sip", q.Encode())
+	}
+}
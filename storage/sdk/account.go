@@ -11,26 +11,26 @@ import (
 	"time"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/armstorage"
 )
 
-func getStorageAccountsClient() armstorage.StorageAccountsClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getStorageAccountsClient(ctx context.Context) armstorage.StorageAccountsClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armstorage.NewStorageAccountsClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armstorage.NewStorageAccountsClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Create StorageAccounts
 func CreateStorageAccount(ctx context.Context, storageAccountName string, storageAccountCreateParametersParameters armstorage.StorageAccountCreateParameters) (string, error) {
-	client := getStorageAccountsClient()
+	client := getStorageAccountsClient(ctx)
 	poller, err := client.BeginCreate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		storageAccountName,
 		storageAccountCreateParametersParameters,
 		nil,
@@ -40,7 +40,7 @@ func CreateStorageAccount(ctx context.Context, storageAccountName string, storag
 		return "", err
 	}
 
-	resp, err := poller.PollUntilDone(ctx, 30*time.Second)
+	resp, err := poller.PollUntilDone(ctx, recording.PollingDelay(30*time.Second))
 	if err != nil {
 		return "", err
 	}
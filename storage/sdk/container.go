@@ -10,27 +10,27 @@ import (
 	"log"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
-	"github.com/Azure/azure-sdk-for-go/sdk/armcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/resourceid"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/armstorage"
 )
 
-func getBlobContainersClient() armstorage.BlobContainersClient {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func getBlobContainersClient(ctx context.Context) armstorage.BlobContainersClient {
+	con, err := iam.GetConnection()
 	if err != nil {
-		log.Fatalf("failed to obtain a credential: %v", err)
+		log.Fatalf("failed to obtain a connection: %v", err)
 	}
-	client := armstorage.NewBlobContainersClient(armcore.NewDefaultConnection(cred, nil), config.SubscriptionID())
+	client := armstorage.NewBlobContainersClient(con, config.ScopeFrom(ctx).SubscriptionID)
 	return *client
 }
 
 // Creates a new container under the specified account as described by request body. The container resource includes metadata and properties for
 // that container. It does not include a list of the blobs contained by the container.
 func CreateBlobContainer(ctx context.Context, accountName string, containerName string, blobContainerParameters armstorage.BlobContainer) (string, error) {
-	client := getBlobContainersClient()
+	client := getBlobContainersClient(ctx)
 	resp, err := client.Create(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		accountName,
 		containerName,
 		blobContainerParameters,
@@ -42,7 +42,11 @@ func CreateBlobContainer(ctx context.Context, accountName string, containerName
 	}
 
 	if resp.BlobContainer.ID == nil {
-		return resp.RawResponse.Request.URL.Path, nil
+		scope := config.ScopeFrom(ctx)
+		return resourceid.ResourceGroup(scope.SubscriptionID, scope.GroupName).
+			Resource("Microsoft.Storage", "storageAccounts", accountName).
+			Child("blobServices", "default").
+			Child("containers", containerName).String(), nil
 	}
 	return *resp.BlobContainer.ID, nil
 }
@@ -12,6 +12,7 @@ import (
 	"testing"
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 )
 
 // TestMain sets up the environment and initiates tests.
@@ -27,5 +28,13 @@ func TestMain(m *testing.M) {
 
 	flag.Parse()
 
+	// recordings don't need credentials, otherwise fail before any test
+	// runs when settings are missing
+	if recording.GetMode() != recording.Playback {
+		if err := config.Validate(); err != nil {
+			log.Fatalf("%v\n", err)
+		}
+	}
+
 	os.Exit(m.Run())
 }
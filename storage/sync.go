@@ -0,0 +1,294 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+)
+
+// SyncAction is what a SyncStep does to a blob.
+type SyncAction string
+
+const (
+	// SyncUpload uploads a local file which is new or changed.
+	SyncUpload SyncAction = "upload"
+	// SyncDelete deletes a blob with no local file.
+	SyncDelete SyncAction = "delete"
+)
+
+// SyncStep is a step of a sync plan.
+type SyncStep struct {
+	Action   SyncAction
+	BlobName string
+	// Path is the local file uploaded, empty for deletions.
+	Path string
+	// Size is the size of the file uploaded or the blob deleted.
+	Size int64
+	// Reason says why the step is needed, e.g. "new" or "size differs".
+	Reason string
+}
+
+// SyncOptions selects what PlanSync compares and tunes Sync.
+type SyncOptions struct {
+	// Prefix is the virtual directory of the container which mirrors the
+	// local directory, the whole container if empty.
+	Prefix string
+	// Include and Exclude are glob patterns, as understood by path.Match,
+	// which are matched against the slash-separated path of each file
+	// relative to the local directory, and against its base name. If
+	// Include is set only matching files are synced; files matching Exclude
+	// never are. Blobs are selected the same way by their name below Prefix.
+	Include []string
+	Exclude []string
+	// Delete deletes selected blobs which have no local file.
+	Delete bool
+	// Transfer tunes the uploads.
+	Transfer TransferOptions
+	// OnStep, if set, is called after each step Sync runs, with the step's
+	// error if it failed.
+	OnStep func(step SyncStep, err error)
+}
+
+func (o SyncOptions) prefix() string {
+	if o.Prefix == "" || strings.HasSuffix(o.Prefix, "/") {
+		return o.Prefix
+	}
+	return o.Prefix + "/"
+}
+
+func (o SyncOptions) selects(rel string) bool {
+	if len(o.Include) > 0 && !matchAny(o.Include, rel) {
+		return false
+	}
+	return !matchAny(o.Exclude, rel)
+}
+
+func matchAny(patterns []string, rel string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, rel); ok {
+			return true
+		}
+		if ok, _ := path.Match(p, path.Base(rel)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// localFile is a file below the directory being synced.
+type localFile struct {
+	// Rel is the slash-separated path relative to the directory.
+	Rel     string
+	Path    string
+	Size    int64
+	ModTime time.Time
+}
+
+// PlanSync compares the local directory dir with the blobs below
+// o.Prefix in a container and returns the steps which make the container
+// match the directory, uploads first, without changing anything. A file
+// is uploaded if it has no blob or their sizes differ. If the file was
+// modified after the blob, it's also uploaded unless its MD5 sum equals the
+// blob's Content-MD5.
+func PlanSync(ctx context.Context, accountName, accountGroupName, containerName, dir string, o SyncOptions) ([]SyncStep, error) {
+	for _, p := range append(append([]string{}, o.Include...), o.Exclude...) {
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %v", p, err)
+		}
+	}
+
+	files, err := walkDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	blobs, err := ListAllBlobs(ctx, accountName, accountGroupName, containerName, o.prefix())
+	if err != nil {
+		return nil, fmt.Errorf("cannot list blobs: %v", err)
+	}
+	return planSync(files, blobs, o, fileMD5)
+}
+
+func planSync(files []localFile, blobs []azblob.BlobItem, o SyncOptions, md5Of func(path string) ([]byte, error)) ([]SyncStep, error) {
+	prefix := o.prefix()
+	remote := make(map[string]azblob.BlobItem, len(blobs))
+	for _, blob := range blobs {
+		remote[strings.TrimPrefix(blob.Name, prefix)] = blob
+	}
+
+	var uploads, deletes []SyncStep
+	local := make(map[string]bool, len(files))
+	for _, f := range files {
+		if !o.selects(f.Rel) {
+			continue
+		}
+		local[f.Rel] = true
+		reason := "new"
+		if blob, ok := remote[f.Rel]; ok {
+			var err error
+			reason, err = changed(f, blob, md5Of)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if reason != "" {
+			uploads = append(uploads, SyncStep{
+				Action:   SyncUpload,
+				BlobName: prefix + f.Rel,
+				Path:     f.Path,
+				Size:     f.Size,
+				Reason:   reason,
+			})
+		}
+	}
+
+	if o.Delete {
+		for rel, blob := range remote {
+			if local[rel] || !o.selects(rel) {
+				continue
+			}
+			var size int64
+			if blob.Properties.ContentLength != nil {
+				size = *blob.Properties.ContentLength
+			}
+			deletes = append(deletes, SyncStep{
+				Action:   SyncDelete,
+				BlobName: blob.Name,
+				Size:     size,
+				Reason:   "no local file",
+			})
+		}
+	}
+
+	for _, steps := range [][]SyncStep{uploads, deletes} {
+		sort.Slice(steps, func(i, j int) bool { return steps[i].BlobName < steps[j].BlobName })
+	}
+	return append(uploads, deletes...), nil
+}
+
+// changed returns why f must be uploaded over blob, or "" if it needn't.
+func changed(f localFile, blob azblob.BlobItem, md5Of func(path string) ([]byte, error)) (string, error) {
+	if blob.Properties.ContentLength == nil || *blob.Properties.ContentLength != f.Size {
+		return "size differs", nil
+	}
+	if !f.ModTime.After(blob.Properties.LastModified) {
+		return "", nil
+	}
+	if len(blob.Properties.ContentMD5) == 0 {
+		return "modified", nil
+	}
+	sum, err := md5Of(f.Path)
+	if err != nil {
+		return "", err
+	}
+	if !bytes.Equal(sum, blob.Properties.ContentMD5) {
+		return "content differs", nil
+	}
+	return "", nil
+}
+
+// walkDir lists the regular files below dir.
+func walkDir(dir string) ([]localFile, error) {
+	var files []localFile
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, localFile{
+			Rel:     filepath.ToSlash(rel),
+			Path:    p,
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		})
+		return nil
+	})
+	return files, err
+}
+
+func fileMD5(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	h := md5.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// SyncReport is the outcome of Sync.
+type SyncReport struct {
+	// Done are the steps which succeeded, in the order they ran.
+	Done []SyncStep
+	// Failed holds the error of each step which failed, by blob name.
+	Failed map[string]error
+}
+
+// Err returns an error listing every step which failed, or nil.
+func (r *SyncReport) Err() error {
+	if len(r.Failed) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(r.Failed))
+	for name := range r.Failed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	fmt.Fprintf(&b, "failed %d of %d sync steps:", len(r.Failed), len(r.Failed)+len(r.Done))
+	for _, name := range names {
+		fmt.Fprintf(&b, "\n  %s: %v", name, r.Failed[name])
+	}
+	return fmt.Errorf("%s", b.String())
+}
+
+// Sync runs the steps of a plan made by PlanSync, one at a time. Failures
+// don't stop the other steps, they're collected in the report.
+func Sync(ctx context.Context, accountName, accountGroupName, containerName string, steps []SyncStep, o SyncOptions) *SyncReport {
+	report := &SyncReport{Failed: map[string]error{}}
+	for _, step := range steps {
+		var err error
+		switch step.Action {
+		case SyncUpload:
+			err = UploadFile(ctx, accountName, accountGroupName, containerName, step.BlobName, step.Path, o.Transfer)
+		case SyncDelete:
+			b := getBlobURL(ctx, accountName, accountGroupName, containerName, step.BlobName)
+			_, err = b.Delete(ctx, azblob.DeleteSnapshotsOptionInclude, azblob.BlobAccessConditions{})
+		default:
+			err = fmt.Errorf("unknown action %q", step.Action)
+		}
+
+		if err != nil {
+			report.Failed[step.BlobName] = err
+		} else {
+			report.Done = append(report.Done, step)
+		}
+		if o.OnStep != nil {
+			o.OnStep(step, err)
+		}
+	}
+	return report
+}
@@ -0,0 +1,169 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package storage
+
+import (
+	"crypto/md5"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+)
+
+func blobItem(name string, size int64, lastModified time.Time, contentMD5 []byte) azblob.BlobItem {
+	return azblob.BlobItem{
+		Name: name,
+		Properties: azblob.BlobProperties{
+			ContentLength: &size,
+			LastModified:  lastModified,
+			ContentMD5:    contentMD5,
+		},
+	}
+}
+
+func TestPlanSync(t *testing.T) {
+	uploaded := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	before, after := uploaded.Add(-time.Hour), uploaded.Add(time.Hour)
+	sameSum := md5.Sum([]byte("same"))
+	otherSum := md5.Sum([]byte("other"))
+	sums := map[string][]byte{
+		"/d/touched.txt": sameSum[:],
+		"/d/edited.txt":  otherSum[:],
+	}
+	md5Of := func(path string) ([]byte, error) {
+		sum, ok := sums[path]
+		if !ok {
+			t.Errorf("hashed %s, which needn't be", path)
+		}
+		return sum, nil
+	}
+
+	files := []localFile{
+		{Rel: "new.txt", Path: "/d/new.txt", Size: 1, ModTime: before},
+		{Rel: "same.txt", Path: "/d/same.txt", Size: 4, ModTime: before},
+		{Rel: "resized.txt", Path: "/d/resized.txt", Size: 5, ModTime: before},
+		{Rel: "touched.txt", Path: "/d/touched.txt", Size: 4, ModTime: after},
+		{Rel: "edited.txt", Path: "/d/edited.txt", Size: 4, ModTime: after},
+		{Rel: "nosum.txt", Path: "/d/nosum.txt", Size: 4, ModTime: after},
+		{Rel: "sub/deep.txt", Path: "/d/sub/deep.txt", Size: 2, ModTime: before},
+	}
+	blobs := []azblob.BlobItem{
+		blobItem("backup/same.txt", 4, uploaded, sameSum[:]),
+		blobItem("backup/resized.txt", 4, uploaded, sameSum[:]),
+		blobItem("backup/touched.txt", 4, uploaded, sameSum[:]),
+		blobItem("backup/edited.txt", 4, uploaded, sameSum[:]),
+		blobItem("backup/nosum.txt", 4, uploaded, nil),
+		blobItem("backup/gone.txt", 3, uploaded, nil),
+	}
+
+	steps, err := planSync(files, blobs, SyncOptions{Prefix: "backup", Delete: true}, md5Of)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []SyncStep{
+		{Action: SyncUpload, BlobName: "backup/edited.txt", Path: "/d/edited.txt", Size: 4, Reason: "content differs"},
+		{Action: SyncUpload, BlobName: "backup/new.txt", Path: "/d/new.txt", Size: 1, Reason: "new"},
+		{Action: SyncUpload, BlobName: "backup/nosum.txt", Path: "/d/nosum.txt", Size: 4, Reason: "modified"},
+		{Action: SyncUpload, BlobName: "backup/resized.txt", Path: "/d/resized.txt", Size: 5, Reason: "size differs"},
+		{Action: SyncUpload, BlobName: "backup/sub/deep.txt", Path: "/d/sub/deep.txt", Size: 2, Reason: "new"},
+		{Action: SyncDelete, BlobName: "backup/gone.txt", Size: 3, Reason: "no local file"},
+	}
+	if !reflect.DeepEqual(steps, want) {
+		t.Errorf("got plan\n%+v\nwant\n%+v", steps, want)
+	}
+
+	steps, err = planSync(files, blobs, SyncOptions{Prefix: "backup/"}, md5Of)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(steps) != len(want)-1 {
+		t.Errorf("got %d steps without Delete, want %d", len(steps), len(want)-1)
+	}
+}
+
+func TestPlanSyncFilters(t *testing.T) {
+	now := time.Now()
+	files := []localFile{
+		{Rel: "a.go", Path: "a.go", ModTime: now},
+		{Rel: "a_test.go", Path: "a_test.go", ModTime: now},
+		{Rel: "docs/b.md", Path: "docs/b.md", ModTime: now},
+		{Rel: "vendor/c.go", Path: "vendor/c.go", ModTime: now},
+	}
+	blobs := []azblob.BlobItem{
+		blobItem("old.go", 1, now, nil),
+		blobItem("old.md", 1, now, nil),
+		blobItem("vendor/old.go", 1, now, nil),
+	}
+	o := SyncOptions{
+		Include: []string{"*.go"},
+		Exclude: []string{"*_test.go", "vendor/*"},
+		Delete:  true,
+	}
+	steps, err := planSync(files, blobs, o, func(string) ([]byte, error) { return nil, errors.New("unexpected") })
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, step := range steps {
+		got = append(got, string(step.Action)+" "+step.BlobName)
+	}
+	want := []string{"upload a.go", "delete old.go"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got steps %q, want %q", got, want)
+	}
+}
+
+func TestWalkDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sync")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, name := range []string{"a.txt", filepath.Join("sub", "b.txt")} {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(p, []byte(name), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := walkDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rels []string
+	for _, f := range files {
+		rels = append(rels, f.Rel)
+	}
+	if want := []string{"a.txt", "sub/b.txt"}; !reflect.DeepEqual(rels, want) {
+		t.Errorf("got files %q, want %q", rels, want)
+	}
+}
+
+func TestSyncReportErr(t *testing.T) {
+	r := &SyncReport{
+		Done:   []SyncStep{{BlobName: "a"}},
+		Failed: map[string]error{"c": errors.New("timeout"), "b": errors.New("forbidden")},
+	}
+	err := r.Err()
+	if err == nil {
+		t.Fatal("got no error for a report with failures")
+	}
+	want := "failed 2 of 3 sync steps:\n  b: forbidden\n  c: timeout"
+	if err.Error() != want {
+		t.Errorf("got %q, want %q", err, want)
+	}
+	if (&SyncReport{Done: r.Done}).Err() != nil {
+		t.Error("got an error for a report without failures")
+	}
+}
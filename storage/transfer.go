@@ -0,0 +1,419 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+)
+
+const (
+	defaultBlockSize   = 8 * 1024 * 1024
+	defaultParallelism = 4
+	defaultMaxRetries  = 3
+)
+
+// retryDelay is the wait before the first retry of a block or range; it
+// doubles with each further retry.
+var retryDelay = time.Second
+
+// TransferOptions tunes UploadFile, UploadStream and DownloadToFile. The
+// zero value uses the defaults.
+type TransferOptions struct {
+	// BlockSize is the size in bytes of the blocks uploaded and the ranges
+	// downloaded, 8 MiB by default. Blocks can be at most 100 MiB and a blob
+	// at most 50,000 blocks.
+	BlockSize int64
+	// Parallelism is the number of blocks or ranges transferred at once, 4
+	// by default. As many blocks are held in memory.
+	Parallelism int
+	// MaxRetries is how often a failed block or range is retried, 3 by
+	// default.
+	MaxRetries int
+	// ContentType is set on uploaded blobs. UploadFile guesses it from the
+	// file's extension if it's empty.
+	ContentType string
+	// Progress, if set, is called with the total number of bytes
+	// transferred after each block or range.
+	Progress func(bytesTransferred int64)
+}
+
+func (o TransferOptions) withDefaults() TransferOptions {
+	if o.BlockSize <= 0 {
+		o.BlockSize = defaultBlockSize
+	}
+	if o.Parallelism <= 0 {
+		o.Parallelism = defaultParallelism
+	}
+	if o.MaxRetries <= 0 {
+		o.MaxRetries = defaultMaxRetries
+	}
+	return o
+}
+
+// blockID returns the ID of the block at index with the MD5 sum md5Sum.
+// All IDs of a blob must be the same length, hence the zero padding, and
+// the padding also makes them sort in index order. The sum makes a staged
+// block reusable only by a later upload of the same data.
+func blockID(index int, md5Sum []byte) string {
+	return base64.StdEncoding.EncodeToString(
+		[]byte(fmt.Sprintf("%05d-%x", index, md5Sum)))
+}
+
+// blockBlob is the part of azblob.BlockBlobURL used to upload blocks.
+type blockBlob interface {
+	StageBlock(ctx context.Context, base64BlockID string, body io.ReadSeeker, ac azblob.LeaseAccessConditions, transactionalMD5 []byte) (*azblob.BlockBlobStageBlockResponse, error)
+	GetBlockList(ctx context.Context, listType azblob.BlockListType, ac azblob.LeaseAccessConditions) (*azblob.BlockList, error)
+	CommitBlockList(ctx context.Context, base64BlockIDs []string, h azblob.BlobHTTPHeaders, metadata azblob.Metadata, ac azblob.BlobAccessConditions) (*azblob.BlockBlobCommitBlockListResponse, error)
+}
+
+// UploadFile uploads the file at path to a block blob, see UploadStream.
+func UploadFile(ctx context.Context, accountName, accountGroupName, containerName, blobName, path string, o TransferOptions) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	o = o.withDefaults()
+	if blocks := (info.Size() + o.BlockSize - 1) / o.BlockSize; blocks > azblob.BlockBlobMaxBlocks {
+		return fmt.Errorf("%s needs %d blocks of %d bytes, more than the %d a blob can have", path, blocks, o.BlockSize, azblob.BlockBlobMaxBlocks)
+	}
+	if o.ContentType == "" {
+		o.ContentType = mime.TypeByExtension(filepath.Ext(path))
+	}
+
+	b := getBlockBlobURL(ctx, accountName, accountGroupName, containerName, blobName)
+	return uploadBlocks(ctx, b, f, o)
+}
+
+// UploadStream uploads everything read from r to a block blob, replacing
+// it if it exists. The data is staged in blocks of o.BlockSize, several at
+// a time, each with its MD5 sum, and failed blocks are retried. The blob's
+// Content-MD5 is set to the sum of the whole data.
+//
+// Blocks already staged by an interrupted upload of the same data aren't
+// staged again, so an upload can be resumed by calling UploadStream again.
+func UploadStream(ctx context.Context, accountName, accountGroupName, containerName, blobName string, r io.Reader, o TransferOptions) error {
+	b := getBlockBlobURL(ctx, accountName, accountGroupName, containerName, blobName)
+	return uploadBlocks(ctx, b, r, o.withDefaults())
+}
+
+func uploadBlocks(ctx context.Context, b blockBlob, r io.Reader, o TransferOptions) error {
+	staged, err := uncommittedBlocks(ctx, b)
+	if err != nil {
+		return fmt.Errorf("cannot list uncommitted blocks: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+		progress = newProgress(o.Progress)
+	)
+	fail := func(err error) {
+		mu.Lock()
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+		mu.Unlock()
+	}
+
+	// buffers holds a buffer, allocated on first use, for each block which
+	// may be in flight.
+	buffers := make(chan []byte, o.Parallelism)
+	for i := 0; i < o.Parallelism; i++ {
+		buffers <- nil
+	}
+
+	blobMD5 := md5.New()
+	var ids []string
+	for index := 0; ; index++ {
+		var buf []byte
+		select {
+		case buf = <-buffers:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		if buf == nil {
+			buf = make([]byte, o.BlockSize)
+		}
+
+		n, readErr := io.ReadFull(r, buf)
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil && readErr != io.ErrUnexpectedEOF {
+			fail(fmt.Errorf("cannot read block %d: %v", index, readErr))
+			break
+		}
+		if index == azblob.BlockBlobMaxBlocks {
+			fail(fmt.Errorf("data is larger than %d blocks of %d bytes", azblob.BlockBlobMaxBlocks, o.BlockSize))
+			break
+		}
+
+		block := buf[:n]
+		blobMD5.Write(block)
+		sum := md5.Sum(block)
+		id := blockID(index, sum[:])
+		ids = append(ids, id)
+
+		if size, ok := staged[id]; ok && int64(size) == int64(n) {
+			progress.add(int64(n))
+			buffers <- buf
+		} else {
+			wg.Add(1)
+			go func(index int) {
+				defer wg.Done()
+				defer func() { buffers <- buf }()
+				err := withRetries(ctx, o.MaxRetries, func() error {
+					_, err := b.StageBlock(ctx, id, bytes.NewReader(block), azblob.LeaseAccessConditions{}, sum[:])
+					return err
+				})
+				if err != nil {
+					fail(fmt.Errorf("cannot stage block %d: %v", index, err))
+					return
+				}
+				progress.add(int64(len(block)))
+			}(index)
+		}
+
+		if readErr == io.ErrUnexpectedEOF {
+			break
+		}
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	_, err = b.CommitBlockList(
+		ctx,
+		ids,
+		azblob.BlobHTTPHeaders{
+			ContentType: o.ContentType,
+			ContentMD5:  blobMD5.Sum(nil),
+		},
+		azblob.Metadata{},
+		azblob.BlobAccessConditions{})
+	if err != nil {
+		return fmt.Errorf("cannot commit %d blocks: %v", len(ids), err)
+	}
+	return nil
+}
+
+// uncommittedBlocks returns the sizes of the uncommitted blocks of b by
+// their IDs. A blob which doesn't exist yet has none.
+func uncommittedBlocks(ctx context.Context, b blockBlob) (map[string]int32, error) {
+	list, err := b.GetBlockList(ctx, azblob.BlockListUncommitted, azblob.LeaseAccessConditions{})
+	if stgErr, ok := err.(azblob.StorageError); ok && stgErr.ServiceCode() == azblob.ServiceCodeBlobNotFound {
+		return map[string]int32{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	staged := make(map[string]int32, len(list.UncommittedBlocks))
+	for _, block := range list.UncommittedBlocks {
+		staged[block.Name] = block.Size
+	}
+	return staged, nil
+}
+
+// sortBlockIDs sorts block IDs created by blockID in index order.
+func sortBlockIDs(ids []string) {
+	decoded := make(map[string]string, len(ids))
+	for _, id := range ids {
+		d, _ := base64.StdEncoding.DecodeString(id)
+		decoded[id] = string(d)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return decoded[ids[i]] < decoded[ids[j]]
+	})
+}
+
+// DownloadToFile downloads a blob to the file at path, which is created or
+// truncated. Ranges of o.BlockSize are downloaded several at a time and
+// failed ranges are retried. A change to the blob during the download fails
+// it, and if the blob has a Content-MD5 the file is checked against it.
+func DownloadToFile(ctx context.Context, accountName, accountGroupName, containerName, blobName, path string, o TransferOptions) error {
+	o = o.withDefaults()
+	b := getBlobURL(ctx, accountName, accountGroupName, containerName, blobName)
+
+	props, err := b.GetProperties(ctx, azblob.BlobAccessConditions{})
+	if err != nil {
+		return err
+	}
+	ac := azblob.BlobAccessConditions{
+		ModifiedAccessConditions: azblob.ModifiedAccessConditions{IfMatch: props.ETag()},
+	}
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	err = downloadRanges(ctx, f, props.ContentLength(), o, func(ctx context.Context, offset, count int64) (io.ReadCloser, error) {
+		resp, err := b.Download(ctx, offset, count, ac, false)
+		if err != nil {
+			return nil, err
+		}
+		return resp.Body(azblob.RetryReaderOptions{MaxRetryRequests: o.MaxRetries}), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if want := props.ContentMD5(); len(want) > 0 {
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		h := md5.New()
+		if _, err := io.Copy(h, f); err != nil {
+			return err
+		}
+		if got := h.Sum(nil); !bytes.Equal(got, want) {
+			return fmt.Errorf("MD5 of %s is %x, the blob's Content-MD5 is %x", path, got, want)
+		}
+	}
+	return f.Close()
+}
+
+// downloadRanges writes size bytes, fetched in ranges by get, to w.
+func downloadRanges(ctx context.Context, w io.WriterAt, size int64, o TransferOptions, get func(ctx context.Context, offset, count int64) (io.ReadCloser, error)) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+		progress = newProgress(o.Progress)
+	)
+	buffers := make(chan []byte, o.Parallelism)
+	for i := 0; i < o.Parallelism; i++ {
+		buffers <- nil
+	}
+
+	for offset := int64(0); offset < size; offset += o.BlockSize {
+		var buf []byte
+		select {
+		case buf = <-buffers:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		if buf == nil {
+			buf = make([]byte, o.BlockSize)
+		}
+		count := o.BlockSize
+		if offset+count > size {
+			count = size - offset
+		}
+
+		wg.Add(1)
+		go func(offset int64, chunk []byte) {
+			defer wg.Done()
+			defer func() { buffers <- buf }()
+			err := withRetries(ctx, o.MaxRetries, func() error {
+				body, err := get(ctx, offset, int64(len(chunk)))
+				if err != nil {
+					return err
+				}
+				defer body.Close()
+				if _, err := io.ReadFull(body, chunk); err != nil {
+					return err
+				}
+				_, err = w.WriteAt(chunk, offset)
+				return err
+			})
+			if err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = fmt.Errorf("cannot download bytes %d-%d: %v", offset, offset+int64(len(chunk))-1, err)
+					cancel()
+				}
+				mu.Unlock()
+				return
+			}
+			progress.add(int64(len(chunk)))
+		}(offset, buf[:count])
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// withRetries calls f until it succeeds, at most 1+retries times, waiting
+// longer between each call.
+func withRetries(ctx context.Context, retries int, f func() error) error {
+	delay := retryDelay
+	for attempt := 0; ; attempt++ {
+		err := f()
+		if err == nil || attempt == retries || ctx.Err() != nil {
+			return err
+		}
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return err
+		}
+		delay *= 2
+	}
+}
+
+// progress adds up the bytes transferred by concurrent blocks and reports
+// the total.
+type progress struct {
+	mu     sync.Mutex
+	total  int64
+	report func(int64)
+}
+
+func newProgress(report func(int64)) *progress {
+	return &progress{report: report}
+}
+
+func (p *progress) add(n int64) {
+	if p.report == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.total += n
+	p.report(p.total)
+}
@@ -0,0 +1,267 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+)
+
+// fakeBlockBlob stages and commits blocks in memory.
+type fakeBlockBlob struct {
+	mu          sync.Mutex
+	uncommitted map[string][]byte
+	committed   []byte
+	contentMD5  []byte
+	staged      []string
+	// failures is how often staging each block ID fails before it works.
+	failures map[string]int
+}
+
+func newFakeBlockBlob() *fakeBlockBlob {
+	return &fakeBlockBlob{uncommitted: map[string][]byte{}, failures: map[string]int{}}
+}
+
+func (b *fakeBlockBlob) StageBlock(ctx context.Context, id string, body io.ReadSeeker, ac azblob.LeaseAccessConditions, transactionalMD5 []byte) (*azblob.BlockBlobStageBlockResponse, error) {
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures[id] > 0 {
+		b.failures[id]--
+		return nil, errors.New("connection reset")
+	}
+	if sum := md5.Sum(data); !bytes.Equal(sum[:], transactionalMD5) {
+		return nil, errors.New("MD5 mismatch")
+	}
+	b.uncommitted[id] = data
+	b.staged = append(b.staged, id)
+	return &azblob.BlockBlobStageBlockResponse{}, nil
+}
+
+func (b *fakeBlockBlob) GetBlockList(ctx context.Context, listType azblob.BlockListType, ac azblob.LeaseAccessConditions) (*azblob.BlockList, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	list := &azblob.BlockList{}
+	for id, data := range b.uncommitted {
+		list.UncommittedBlocks = append(list.UncommittedBlocks, azblob.Block{Name: id, Size: int32(len(data))})
+	}
+	return list, nil
+}
+
+func (b *fakeBlockBlob) CommitBlockList(ctx context.Context, ids []string, h azblob.BlobHTTPHeaders, metadata azblob.Metadata, ac azblob.BlobAccessConditions) (*azblob.BlockBlobCommitBlockListResponse, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var blob []byte
+	for _, id := range ids {
+		data, ok := b.uncommitted[id]
+		if !ok {
+			return nil, errors.New("InvalidBlockList")
+		}
+		blob = append(blob, data...)
+	}
+	b.committed = blob
+	b.contentMD5 = h.ContentMD5
+	b.uncommitted = map[string][]byte{}
+	return &azblob.BlockBlobCommitBlockListResponse{}, nil
+}
+
+func randomData(t *testing.T, n int) []byte {
+	data := make([]byte, n)
+	if _, err := rand.New(rand.NewSource(int64(n))).Read(data); err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestBlockID(t *testing.T) {
+	sum := md5.Sum([]byte("block"))
+	first := blockID(0, sum[:])
+	ids := []string{}
+	for _, i := range []int{10, 9, 49999, 100, 0, 1} {
+		id := blockID(i, sum[:])
+		if len(id) != len(first) {
+			t.Errorf("ID of block %d is %d characters long, that of block 0 %d", i, len(id), len(first))
+		}
+		ids = append(ids, id)
+	}
+
+	sortBlockIDs(ids)
+	for i, want := range []int{0, 1, 9, 10, 100, 49999} {
+		if ids[i] != blockID(want, sum[:]) {
+			d, _ := base64.StdEncoding.DecodeString(ids[i])
+			t.Errorf("sorted ID %d is %s, want the one of block %d", i, d, want)
+		}
+	}
+}
+
+func TestUploadBlocks(t *testing.T) {
+	data := randomData(t, 10*1024+7)
+	b := newFakeBlockBlob()
+	var reported int64
+	o := TransferOptions{BlockSize: 1024, Parallelism: 3, Progress: func(n int64) { reported = n }}
+
+	if err := uploadBlocks(context.Background(), b, bytes.NewReader(data), o.withDefaults()); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b.committed, data) {
+		t.Errorf("committed %d bytes which differ from the %d uploaded", len(b.committed), len(data))
+	}
+	if len(b.staged) != 11 {
+		t.Errorf("staged %d blocks, want 11", len(b.staged))
+	}
+	if sum := md5.Sum(data); !bytes.Equal(b.contentMD5, sum[:]) {
+		t.Errorf("Content-MD5 is %x, want %x", b.contentMD5, sum)
+	}
+	if reported != int64(len(data)) {
+		t.Errorf("reported %d bytes transferred, want %d", reported, len(data))
+	}
+}
+
+func TestUploadBlocksEmpty(t *testing.T) {
+	b := newFakeBlockBlob()
+	if err := uploadBlocks(context.Background(), b, bytes.NewReader(nil), TransferOptions{}.withDefaults()); err != nil {
+		t.Fatal(err)
+	}
+	if len(b.staged) != 0 || len(b.committed) != 0 {
+		t.Errorf("staged %d blocks and committed %d bytes, want none", len(b.staged), len(b.committed))
+	}
+}
+
+func TestUploadBlocksRetries(t *testing.T) {
+	defer func(d time.Duration) { retryDelay = d }(retryDelay)
+	retryDelay = 0
+
+	data := randomData(t, 3000)
+	sum := md5.Sum(data[1024:2048])
+	b := newFakeBlockBlob()
+	b.failures[blockID(1, sum[:])] = 2
+	o := TransferOptions{BlockSize: 1024, MaxRetries: 2}
+
+	if err := uploadBlocks(context.Background(), b, bytes.NewReader(data), o.withDefaults()); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b.committed, data) {
+		t.Error("committed data differs from the uploaded")
+	}
+
+	b = newFakeBlockBlob()
+	b.failures[blockID(1, sum[:])] = 3
+	if err := uploadBlocks(context.Background(), b, bytes.NewReader(data), o.withDefaults()); err == nil {
+		t.Error("upload succeeded although a block failed more often than retried")
+	}
+	if b.committed != nil {
+		t.Error("committed blocks although a block failed")
+	}
+}
+
+func TestUploadBlocksResumes(t *testing.T) {
+	data := randomData(t, 4096)
+	o := TransferOptions{BlockSize: 1024}.withDefaults()
+
+	// an interrupted upload staged the first two blocks, and a block of
+	// other data
+	b := newFakeBlockBlob()
+	for i := 0; i < 2; i++ {
+		block := data[i*1024 : (i+1)*1024]
+		sum := md5.Sum(block)
+		b.uncommitted[blockID(i, sum[:])] = block
+	}
+	other := []byte("other data")
+	otherSum := md5.Sum(other)
+	b.uncommitted[blockID(2, otherSum[:])] = other
+
+	if err := uploadBlocks(context.Background(), b, bytes.NewReader(data), o); err != nil {
+		t.Fatal(err)
+	}
+	if len(b.staged) != 2 {
+		t.Errorf("staged %d blocks, want the 2 not staged before", len(b.staged))
+	}
+	if !bytes.Equal(b.committed, data) {
+		t.Error("committed data differs from the uploaded")
+	}
+}
+
+// writerAt is an in-memory io.WriterAt.
+type writerAt struct {
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (w *writerAt) WriteAt(p []byte, off int64) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	copy(w.buf[off:], p)
+	return len(p), nil
+}
+
+func TestDownloadRanges(t *testing.T) {
+	defer func(d time.Duration) { retryDelay = d }(retryDelay)
+	retryDelay = 0
+
+	data := randomData(t, 5000)
+	var mu sync.Mutex
+	failed := map[int64]bool{}
+	get := func(ctx context.Context, offset, count int64) (io.ReadCloser, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		// each range fails once
+		if !failed[offset] {
+			failed[offset] = true
+			return nil, errors.New("connection reset")
+		}
+		return ioutil.NopCloser(bytes.NewReader(data[offset : offset+count])), nil
+	}
+
+	w := &writerAt{buf: make([]byte, len(data))}
+	o := TransferOptions{BlockSize: 1000, Parallelism: 2}.withDefaults()
+	if err := downloadRanges(context.Background(), w, int64(len(data)), o, get); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(w.buf, data) {
+		t.Error("downloaded data differs from the blob")
+	}
+	if len(failed) != 5 {
+		t.Errorf("requested %d ranges, want 5", len(failed))
+	}
+}
+
+func TestDownloadRangesFails(t *testing.T) {
+	defer func(d time.Duration) { retryDelay = d }(retryDelay)
+	retryDelay = 0
+
+	get := func(ctx context.Context, offset, count int64) (io.ReadCloser, error) {
+		if offset == 2000 {
+			return nil, errors.New("connection reset")
+		}
+		return ioutil.NopCloser(bytes.NewReader(make([]byte, count))), nil
+	}
+	f, err := ioutil.TempFile("", "download")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	o := TransferOptions{BlockSize: 1000}.withDefaults()
+	if err := downloadRanges(context.Background(), f, 5000, o, get); err == nil {
+		t.Error("download succeeded although a range failed")
+	}
+}
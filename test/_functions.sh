@@ -26,6 +26,15 @@ function convert_path_to_package {
 }
 
 
+# uses_recordings succeeds if the tests of the package at ${path} replay
+# recorded traffic, that is call recording.Start. Other packages still run
+# their live setup in playback mode, so they can't be tested offline.
+function uses_recordings {
+    path=$1
+
+    grep -q "recording\.Start" ${path}/*_test.go 2> /dev/null
+}
+
 # install_dependencies installs dependencies
 function install_dependencies {
     base_path=$1
@@ -0,0 +1,107 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// Command blobsync makes a container, or a virtual directory of it, match a
+// local directory. New and changed files are uploaded, and with -delete
+// blobs without a local file are deleted:
+//
+//	go run ./tools/blobsync -account mysamples -account-group samples-rg -container site -dir ./public -dry-run
+//	go run ./tools/blobsync -account mysamples -account-group samples-rg -container backups -prefix nightly -dir ./out -exclude '*.tmp' -delete
+//
+// Files are compared with blobs by size, last-modified time and Content-MD5,
+// see `storage.PlanSync`.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/storage"
+)
+
+// patternFlags collects repeated glob pattern flags.
+type patternFlags []string
+
+func (p *patternFlags) String() string {
+	return strings.Join(*p, ",")
+}
+
+func (p *patternFlags) Set(value string) error {
+	*p = append(*p, value)
+	return nil
+}
+
+func main() {
+	var o storage.SyncOptions
+	var accountName, accountGroupName, containerName, dir string
+	var dryRun bool
+	var timeout time.Duration
+	flag.StringVar(&accountName, "account", "", "Name of the storage account.")
+	flag.StringVar(&accountGroupName, "account-group", "", "Resource group of the storage account.")
+	flag.StringVar(&containerName, "container", "", "Container to sync to.")
+	flag.StringVar(&dir, "dir", ".", "Local directory to sync from.")
+	flag.StringVar(&o.Prefix, "prefix", "", "Virtual directory of the container to sync to, the whole container if empty.")
+	flag.Var((*patternFlags)(&o.Include), "include", "Sync only files matching this glob, e.g. '*.html'. May be repeated.")
+	flag.Var((*patternFlags)(&o.Exclude), "exclude", "Don't sync files matching this glob. May be repeated.")
+	flag.BoolVar(&o.Delete, "delete", false, "Delete blobs which have no local file.")
+	flag.BoolVar(&dryRun, "dry-run", false, "Print the plan without changing anything.")
+	flag.Int64Var(&o.Transfer.BlockSize, "block-size", 8*1024*1024, "Size in bytes of the blocks uploaded.")
+	flag.IntVar(&o.Transfer.Parallelism, "parallelism", 4, "How many blocks of a file to upload at a time.")
+	flag.DurationVar(&timeout, "timeout", time.Hour, "How long to wait for the sync.")
+
+	if err := config.ParseEnvironment(); err != nil {
+		log.Fatalf("failed to parse environment: %v\n", err)
+	}
+	if err := config.AddFlags(); err != nil {
+		log.Fatalf("failed to add flags: %v\n", err)
+	}
+	flag.Parse()
+	if accountName == "" || accountGroupName == "" || containerName == "" {
+		log.Fatalf("specify -account, -account-group and -container")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	steps, err := storage.PlanSync(ctx, accountName, accountGroupName, containerName, dir, o)
+	if err != nil {
+		log.Fatalf("%v\n", err)
+	}
+	if len(steps) == 0 {
+		fmt.Println("container is up to date")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ACTION\tBLOB\tSIZE\tREASON")
+	for _, step := range steps {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", step.Action, step.BlobName, step.Size, step.Reason)
+	}
+	w.Flush()
+
+	if dryRun {
+		fmt.Printf("dry run: would run %d steps\n", len(steps))
+		return
+	}
+
+	fmt.Printf("running %d steps\n", len(steps))
+	o.OnStep = func(step storage.SyncStep, err error) {
+		if err == nil {
+			fmt.Printf("%s %s: done\n", step.Action, step.BlobName)
+		}
+	}
+	report := storage.Sync(ctx, accountName, accountGroupName, containerName, steps, o)
+	if err := report.Err(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
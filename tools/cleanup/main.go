@@ -0,0 +1,132 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// Command cleanup deletes resource groups left behind by the samples,
+// selected by name prefix, location, tags and age:
+//
+//	go run ./tools/cleanup -prefix az-samples-go -expired -dry-run
+//	go run ./tools/cleanup -tag samples-run-id=run-abcde
+//	go run ./tools/cleanup -older-than 72h -group-location westus2
+//
+// Groups created by `resources.CreateGroup` are tagged with their creator,
+// run ID, creation and expiry time; see the `resources.Tag*` constants.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
+	armresources "github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2020-06-01/resources"
+)
+
+// tagFlags collects repeated `-tag key=value` flags.
+type tagFlags map[string]string
+
+func (t tagFlags) String() string {
+	pairs := make([]string, 0, len(t))
+	for k, v := range t {
+		pairs = append(pairs, k+"="+v)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (t tagFlags) Set(value string) error {
+	i := strings.Index(value, "=")
+	if i == 0 {
+		return fmt.Errorf("expected key=value or key, got %q", value)
+	}
+	if i < 0 {
+		t[value] = ""
+		return nil
+	}
+	t[value[:i]] = value[i+1:]
+	return nil
+}
+
+func main() {
+	tags := tagFlags{}
+	var filter resources.GroupFilter
+	var dryRun bool
+	var concurrency int
+	var timeout time.Duration
+	flag.StringVar(&filter.Prefix, "prefix", "", "Select groups whose name starts with this prefix.")
+	flag.StringVar(&filter.Location, "group-location", "", "Select groups in this location.")
+	flag.Var(tags, "tag", "Select groups with this tag, as key=value or just key. May be repeated.")
+	flag.DurationVar(&filter.OlderThan, "older-than", 0, "Select groups created longer ago than this, e.g. 72h.")
+	flag.BoolVar(&filter.Expired, "expired", false, "Select groups whose expiry tag is in the past.")
+	flag.BoolVar(&dryRun, "dry-run", false, "List the selected groups without deleting them.")
+	flag.IntVar(&concurrency, "concurrency", 5, "How many groups to delete at a time.")
+	flag.DurationVar(&timeout, "timeout", time.Hour, "How long to wait for the deletions.")
+
+	if err := config.ParseEnvironment(); err != nil {
+		log.Fatalf("failed to parse environment: %v\n", err)
+	}
+	if err := config.AddFlags(); err != nil {
+		log.Fatalf("failed to add flags: %v\n", err)
+	}
+	flag.Parse()
+	if len(tags) > 0 {
+		filter.Tags = tags
+	}
+	if filter.IsEmpty() {
+		log.Fatalf("refusing to select every resource group, specify -prefix, -group-location, -tag, -older-than or -expired")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	groups, err := resources.FindGroups(ctx, filter)
+	if err != nil {
+		log.Fatalf("%v\n", err)
+	}
+	if len(groups) == 0 {
+		fmt.Println("no resource groups selected")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tLOCATION\tCREATED BY\tRUN ID\tEXPIRES ON")
+	names := make([]string, len(groups))
+	for i, group := range groups {
+		names[i] = *group.Name
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", *group.Name, value(group.Location),
+			tag(group, resources.TagCreatedBy), tag(group, resources.TagRunID), tag(group, resources.TagExpiresOn))
+	}
+	w.Flush()
+
+	if dryRun {
+		fmt.Printf("dry run: would delete %d resource groups\n", len(names))
+		return
+	}
+
+	fmt.Printf("deleting %d resource groups\n", len(names))
+	report := resources.DeleteGroups(ctx, names, concurrency)
+	for _, name := range report.Deleted {
+		fmt.Printf("deleted %s\n", name)
+	}
+	if err := report.Err(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func tag(group armresources.Group, key string) string {
+	return value(group.Tags[key])
+}
+
+func value(s *string) string {
+	if s == nil {
+		return "-"
+	}
+	return *s
+}
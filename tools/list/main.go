@@ -0,0 +1,74 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// Command list prints an inventory of the resources in a subscription or
+// resource group, with each resource type's newest stable API version:
+//
+//	go run ./tools/list
+//	go run ./tools/list -group az-samples-go-Groups-abcde -format json
+//	go run ./tools/list -format csv > inventory.csv
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/resources"
+)
+
+func main() {
+	var groupName, format string
+	var timeout time.Duration
+	flag.StringVar(&groupName, "group", "", "List the resources in this group rather than the whole subscription.")
+	flag.StringVar(&format, "format", "table", "Output format: table, json or csv.")
+	flag.DurationVar(&timeout, "timeout", 10*time.Minute, "How long to wait for the listing.")
+
+	if err := config.ParseEnvironment(); err != nil {
+		log.Fatalf("failed to parse environment: %v\n", err)
+	}
+	if err := config.AddFlags(); err != nil {
+		log.Fatalf("failed to add flags: %v\n", err)
+	}
+	flag.Parse()
+
+	var write func(resources.Inventory) error
+	switch format {
+	case "table":
+		write = func(inv resources.Inventory) error { return inv.Fprint(os.Stdout) }
+	case "json":
+		write = func(inv resources.Inventory) error { return inv.WriteJSON(os.Stdout) }
+	case "csv":
+		write = func(inv resources.Inventory) error { return inv.WriteCSV(os.Stdout) }
+	default:
+		log.Fatalf("unknown format %q, expected table, json or csv", format)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	inventory, err := resources.ListInventory(ctx, groupName)
+	if err != nil {
+		log.Fatalf("%v\n", err)
+	}
+	if err := write(inventory); err != nil {
+		log.Fatalf("failed to write inventory: %v\n", err)
+	}
+	// report each type whose API version couldn't be resolved once
+	reported := map[string]bool{}
+	for _, item := range inventory {
+		if item.APIVersionError != "" && !reported[item.Type] {
+			reported[item.Type] = true
+			fmt.Fprintf(os.Stderr, "no API version for %s: %s\n", item.Type, item.APIVersionError)
+		}
+	}
+	if format == "table" {
+		fmt.Fprintf(os.Stderr, "%d resources\n", len(inventory))
+	}
+}
@@ -5,32 +5,34 @@ import (
 
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/config"
 	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/iam"
+	"github.com/Azure-Samples/azure-sdk-for-go-samples/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/services/web/mgmt/2016-09-01/web"
 	"github.com/Azure/go-autorest/autorest/to"
 )
 
-func getWebAppsClient() (client web.AppsClient, err error) {
-	client = web.NewAppsClient(config.SubscriptionID())
+func getWebAppsClient(ctx context.Context) (client web.AppsClient, err error) {
+	client = web.NewAppsClient(config.ScopeFrom(ctx).SubscriptionID)
 	client.Authorizer, err = iam.GetResourceManagementAuthorizer()
 	if err != nil {
 		return
 	}
 	client.AddToUserAgent(config.UserAgent())
+	client.Sender = recording.Sender()
 	return
 }
 
 // CreateWebApp creates a blank web app with specified name
 func CreateWebApp(ctx context.Context, name string) (webSite web.Site, err error) {
-	client, err := getWebAppsClient()
+	client, err := getWebAppsClient(ctx)
 	if err != nil {
 		return
 	}
 	future, err := client.CreateOrUpdate(
 		ctx,
-		config.GroupName(),
+		config.ScopeFrom(ctx).GroupName,
 		name,
 		web.Site{
-			Location:       to.StringPtr(config.Location()),
+			Location:       to.StringPtr(config.ScopeFrom(ctx).Location),
 			SiteProperties: &web.SiteProperties{},
 		})
 	if err != nil {
@@ -47,10 +49,10 @@ func CreateWebApp(ctx context.Context, name string) (webSite web.Site, err error
 
 // GetAppConfiguration returns web app configuration info.
 func GetAppConfiguration(ctx context.Context, name string) (createdConfig web.SiteConfigResource, err error) {
-	client, err := getWebAppsClient()
+	client, err := getWebAppsClient(ctx)
 	if err != nil {
 		return
 	}
-	createdConfig, err = client.GetConfiguration(ctx, config.GroupName(), name)
+	createdConfig, err = client.GetConfiguration(ctx, config.ScopeFrom(ctx).GroupName, name)
 	return
 }